@@ -0,0 +1,39 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cascmd
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	casURLFlagName  = "cas-url"
+	casURLEnvKey    = "ORB_CLI_CAS_URL"
+	casURLFlagUsage = "The URL of the CAS endpoint, for example https://orb.domain1.com/cas." +
+		" Alternatively, this can be set with the following environment variable: " + casURLEnvKey
+)
+
+// GetCmd returns the Cobra CAS command.
+func GetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "cas",
+		Short:        "Exports and imports CAS content.",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return errors.New("expecting subcommand export or import")
+		},
+	}
+
+	cmd.AddCommand(
+		newExportCmd(),
+		newImportCmd(&mongoDBProvider{}),
+	)
+
+	return cmd
+}
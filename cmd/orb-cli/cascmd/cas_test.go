@@ -0,0 +1,386 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cascmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	"github.com/trustbloc/orb/pkg/cas/car"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	"github.com/trustbloc/orb/pkg/multihash"
+)
+
+const flag = "--"
+
+func TestCASCmd(t *testing.T) {
+	t.Run("No subcommand", func(t *testing.T) {
+		err := GetCmd().Execute()
+		require.EqualError(t, err, "expecting subcommand export or import")
+	})
+}
+
+func TestExportCmd(t *testing.T) {
+	t.Run("Missing CAS URL", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{"export"})
+
+		err := cmd.Execute()
+		require.EqualError(t, err,
+			"Neither cas-url (command line flag) nor ORB_CLI_CAS_URL (environment variable) have been set.")
+	})
+
+	t.Run("Invalid CAS URL", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{"export", flag + casURLFlagName, ":invalid"})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid CAS URL")
+	})
+
+	t.Run("Missing anchor", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{"export", flag + casURLFlagName, "https://orb.domain1.com/cas"})
+
+		err := cmd.Execute()
+		require.EqualError(t, err, "either anchor or outbox-url must be set")
+	})
+
+	t.Run("Invalid outbox URL", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{
+			"export", flag + casURLFlagName, "https://orb.domain1.com/cas",
+			flag + outboxURLFlagName, ":invalid",
+			flag + outputFlagName, filepath.Join(t.TempDir(), "anchors.car"),
+		})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid outbox URL")
+	})
+
+	t.Run("Anchors from outbox", func(t *testing.T) {
+		const anchorHL = "hl:uEiD2k2kSGESB9e3UwwTOJ8WhqCeAT8fZpwjtvOHVLmXCoA"
+
+		anchorURL, err := url.Parse(anchorHL)
+		require.NoError(t, err)
+
+		create := vocab.NewCreateActivity(
+			vocab.NewObjectProperty(vocab.WithAnchorEvent(vocab.NewAnchorEvent(nil, vocab.WithURL(anchorURL)))),
+		)
+
+		var serv *httptest.Server
+
+		serv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var respBytes []byte
+
+			switch r.URL.Path {
+			case "/outbox":
+				first, e := url.Parse(serv.URL + "/outbox/page1")
+				require.NoError(t, e)
+
+				respBytes, e = json.Marshal(vocab.NewOrderedCollection(nil, vocab.WithFirst(first)))
+				require.NoError(t, e)
+			case "/outbox/page1":
+				var e error
+
+				respBytes, e = json.Marshal(vocab.NewOrderedCollectionPage(
+					[]*vocab.ObjectProperty{
+						vocab.NewObjectProperty(vocab.WithActivity(create)),
+						vocab.NewObjectProperty(vocab.WithActivity(vocab.NewFollowActivity(nil))),
+					},
+				))
+				require.NoError(t, e)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+
+			_, e := w.Write(respBytes)
+			require.NoError(t, e)
+		}))
+		defer serv.Close()
+
+		out := &bytes.Buffer{}
+
+		cmd := GetCmd()
+		cmd.SetOut(out)
+		cmd.SetArgs([]string{
+			"export", flag + casURLFlagName, serv.URL + "/cas",
+			flag + outboxURLFlagName, serv.URL + "/outbox",
+			flag + outputFlagName, filepath.Join(t.TempDir(), "anchors.car"),
+		})
+
+		require.NoError(t, cmd.Execute())
+		require.Contains(t, out.String(), anchorHL)
+	})
+
+	t.Run("Outbox error", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer serv.Close()
+
+		cmd := GetCmd()
+		cmd.SetArgs([]string{
+			"export", flag + casURLFlagName, serv.URL + "/cas",
+			flag + outboxURLFlagName, serv.URL + "/outbox",
+			flag + outputFlagName, filepath.Join(t.TempDir(), "anchors.car"),
+		})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get published anchors")
+	})
+
+	t.Run("Missing output", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{
+			"export", flag + casURLFlagName, "https://orb.domain1.com/cas",
+			flag + anchorFlagName, "hl:uEiD2k2kSGESB9e3UwwTOJ8WhqCeAT8fZpwjtvOHVLmXCoA",
+		})
+
+		err := cmd.Execute()
+		require.EqualError(t, err,
+			"Neither output (command line flag) nor ORB_CLI_OUTPUT (environment variable) have been set.")
+	})
+
+	t.Run("CAS server error", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer serv.Close()
+
+		cmd := GetCmd()
+		cmd.SetArgs([]string{
+			"export", flag + casURLFlagName, serv.URL + "/cas",
+			flag + anchorFlagName, "hl:uEiD2k2kSGESB9e3UwwTOJ8WhqCeAT8fZpwjtvOHVLmXCoA",
+			flag + outputFlagName, filepath.Join(t.TempDir(), "anchors.car"),
+		})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "status '500'")
+	})
+
+	t.Run("Anchor not found", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "Bearer ADMIN_TOKEN", r.Header.Get("Authorization"))
+
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer serv.Close()
+
+		output := filepath.Join(t.TempDir(), "anchors.car")
+		out := &bytes.Buffer{}
+
+		cmd := GetCmd()
+		cmd.SetOut(out)
+		cmd.SetArgs([]string{
+			"export", flag + casURLFlagName, serv.URL + "/cas",
+			flag + anchorFlagName, "hl:uEiD2k2kSGESB9e3UwwTOJ8WhqCeAT8fZpwjtvOHVLmXCoA",
+			flag + outputFlagName, output,
+			flag + "auth-token", "ADMIN_TOKEN",
+		})
+
+		require.NoError(t, cmd.Execute())
+		require.Contains(t, out.String(), `"missing"`)
+		require.Contains(t, out.String(), "hl:uEiD2k2kSGESB9e3UwwTOJ8WhqCeAT8fZpwjtvOHVLmXCoA")
+
+		f, err := os.Open(output) //nolint:gosec
+		require.NoError(t, err)
+
+		defer func() {
+			require.NoError(t, f.Close())
+		}()
+
+		r, err := car.NewReader(f)
+		require.NoError(t, err)
+		require.Len(t, r.Roots(), 1)
+	})
+}
+
+func TestWebCASReader(t *testing.T) {
+	content := []byte("content")
+
+	hash, err := hashlink.New().CreateResourceHash(content)
+	require.NoError(t, err)
+
+	serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/"+hash) {
+			_, err := w.Write(content)
+			require.NoError(t, err)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer serv.Close()
+
+	r := &webCASReader{httpClient: http.DefaultClient, casURL: serv.URL + "/cas"}
+
+	c, err := r.Read(hash)
+	require.NoError(t, err)
+	require.Equal(t, content, c)
+
+	_, err = r.Read("xxx")
+	require.True(t, errors.Is(err, orberrors.ErrContentNotFound))
+}
+
+func TestImportCmd(t *testing.T) {
+	content := []byte("content")
+
+	hash, err := hashlink.New().CreateResourceHash(content)
+	require.NoError(t, err)
+
+	cid, err := multihash.ToV1CID(hash)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+
+	w, err := car.NewWriter(buf, cid)
+	require.NoError(t, err)
+	require.NoError(t, w.Put(cid, content))
+
+	input := filepath.Join(t.TempDir(), "anchors.car")
+	require.NoError(t, os.WriteFile(input, buf.Bytes(), 0o600))
+
+	t.Run("Success", func(t *testing.T) {
+		p := &mockProvider{provider: mem.NewProvider()}
+		out := &bytes.Buffer{}
+
+		cmd := newImportCmd(p)
+		cmd.SetOut(out)
+		cmd.SetArgs([]string{
+			flag + inputFlagName, input,
+			flag + databaseURLFlagName, "mongodb://localhost:27017",
+			flag + casURLFlagName, "https://orb.domain1.com/cas",
+		})
+
+		require.NoError(t, cmd.Execute())
+		require.Contains(t, out.String(), `"imported": 1`)
+
+		store, err := p.provider.OpenStore("cas")
+		require.NoError(t, err)
+
+		value, err := store.Get(hash)
+		require.NoError(t, err)
+		require.Equal(t, content, value)
+	})
+
+	t.Run("Missing input", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{"import"})
+
+		err := cmd.Execute()
+		require.EqualError(t, err,
+			"Neither input (command line flag) nor ORB_CLI_INPUT (environment variable) have been set.")
+	})
+
+	t.Run("Missing database URL", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{"import", flag + inputFlagName, input})
+
+		err := cmd.Execute()
+		require.EqualError(t, err,
+			"Neither database-url (command line flag) nor ORB_CLI_DATABASE_URL (environment variable) have been set.")
+	})
+
+	t.Run("Invalid CAS URL", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{
+			"import", flag + inputFlagName, input,
+			flag + databaseURLFlagName, "mongodb://localhost:27017",
+			flag + casURLFlagName, ":invalid",
+		})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid CAS URL")
+	})
+
+	t.Run("Input file not found", func(t *testing.T) {
+		cmd := newImportCmd(&mockProvider{provider: mem.NewProvider()})
+		cmd.SetArgs([]string{
+			flag + inputFlagName, filepath.Join(t.TempDir(), "xxx.car"),
+			flag + databaseURLFlagName, "mongodb://localhost:27017",
+			flag + casURLFlagName, "https://orb.domain1.com/cas",
+		})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "open input file")
+	})
+
+	t.Run("Storage provider error", func(t *testing.T) {
+		cmd := newImportCmd(&mockProvider{err: errors.New("injected provider error")})
+		cmd.SetArgs([]string{
+			flag + inputFlagName, input,
+			flag + databaseURLFlagName, "mongodb://localhost:27017",
+			flag + casURLFlagName, "https://orb.domain1.com/cas",
+		})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected provider error")
+	})
+
+	t.Run("Invalid archive", func(t *testing.T) {
+		invalidInput := filepath.Join(t.TempDir(), "invalid.car")
+		require.NoError(t, os.WriteFile(invalidInput, []byte("invalid"), 0o600))
+
+		cmd := newImportCmd(&mockProvider{provider: mem.NewProvider()})
+		cmd.SetArgs([]string{
+			flag + inputFlagName, invalidInput,
+			flag + databaseURLFlagName, "mongodb://localhost:27017",
+			flag + casURLFlagName, "https://orb.domain1.com/cas",
+		})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "import:")
+	})
+}
+
+type mockProvider struct {
+	provider storage.Provider
+	err      error
+}
+
+func (m *mockProvider) Get(string, string) (storage.Provider, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &nonClosingProvider{Provider: m.provider}, nil
+}
+
+// nonClosingProvider doesn't close the underlying in-memory provider so that its contents may be checked.
+type nonClosingProvider struct {
+	storage.Provider
+}
+
+func (p *nonClosingProvider) Close() error {
+	return nil
+}
@@ -0,0 +1,226 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cascmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/trustbloc/orb/cmd/orb-cli/common"
+	"github.com/trustbloc/orb/internal/pkg/cmdutil"
+	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	"github.com/trustbloc/orb/pkg/anchor/anchorlinkset"
+	"github.com/trustbloc/orb/pkg/anchor/anchorlinkset/generator"
+	"github.com/trustbloc/orb/pkg/cas/car"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+)
+
+var logger = log.New("orb-cli")
+
+const (
+	anchorFlagName  = "anchor"
+	anchorEnvKey    = "ORB_CLI_ANCHOR"
+	anchorFlagUsage = "The hashlink (or hash) of an anchor linkset from which to start the export. " +
+		"All anchors that precede the given anchors in the anchor graph are also exported. " +
+		"This flag may be repeated. If neither this flag nor outbox-url is set then the export fails." +
+		" Alternatively, this can be set with the following environment variable (comma-separated): " + anchorEnvKey
+
+	outboxURLFlagName  = "outbox-url"
+	outboxURLEnvKey    = "ORB_CLI_OUTBOX_URL"
+	outboxURLFlagUsage = "The URL of the Orb service outbox, for example https://orb.domain1.com/services/orb/outbox. " +
+		"If set then the export starts from every anchor published by the service, i.e. the entire anchor graph " +
+		"of the service is exported." +
+		" Alternatively, this can be set with the following environment variable: " + outboxURLEnvKey
+
+	outputFlagName  = "output"
+	outputEnvKey    = "ORB_CLI_OUTPUT"
+	outputFlagUsage = "The path of the CAR file to write." +
+		" Alternatively, this can be set with the following environment variable: " + outputEnvKey
+)
+
+func newExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Exports the anchor graph to a CAR file.",
+		Long: `Exports the given anchors (or all anchors published in a service outbox), all of the anchors that ` +
+			`precede them in the anchor graph, and the Sidetree batch files referenced by each anchor from a CAS ` +
+			`endpoint to a CAR (content-addressed archive) file. For example: cas export --cas-url ` +
+			`https://orb.domain1.com/cas --outbox-url https://orb.domain1.com/services/orb/outbox --output anchors.car`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeExport(cmd)
+		},
+	}
+
+	common.AddCommonFlags(cmd)
+
+	cmd.Flags().StringP(casURLFlagName, "", "", casURLFlagUsage)
+	cmd.Flags().StringArrayP(anchorFlagName, "", nil, anchorFlagUsage)
+	cmd.Flags().StringP(outboxURLFlagName, "", "", outboxURLFlagUsage)
+	cmd.Flags().StringP(outputFlagName, "", "", outputFlagUsage)
+
+	return cmd
+}
+
+func executeExport(cmd *cobra.Command) error {
+	casURL, err := cmdutil.GetUserSetVarFromString(cmd, casURLFlagName, casURLEnvKey, false)
+	if err != nil {
+		return err
+	}
+
+	if _, err := url.ParseRequestURI(casURL); err != nil {
+		return fmt.Errorf("invalid CAS URL %s: %w", casURL, err)
+	}
+
+	anchors := cmdutil.GetUserSetOptionalVarFromArrayString(cmd, anchorFlagName, anchorEnvKey)
+	outboxURL := cmdutil.GetUserSetOptionalVarFromString(cmd, outboxURLFlagName, outboxURLEnvKey)
+
+	if len(anchors) == 0 && outboxURL == "" {
+		return fmt.Errorf("either %s or %s must be set", anchorFlagName, outboxURLFlagName)
+	}
+
+	output, err := cmdutil.GetUserSetVarFromString(cmd, outputFlagName, outputEnvKey, false)
+	if err != nil {
+		return err
+	}
+
+	httpClient, err := common.NewHTTPClient(cmd)
+	if err != nil {
+		return fmt.Errorf("new HTTP client: %w", err)
+	}
+
+	if outboxURL != "" {
+		if _, err := url.ParseRequestURI(outboxURL); err != nil {
+			return fmt.Errorf("invalid outbox URL %s: %w", outboxURL, err)
+		}
+
+		published, err := getPublishedAnchors(cmd, outboxURL)
+		if err != nil {
+			return err
+		}
+
+		anchors = append(anchors, published...)
+	}
+
+	f, err := os.Create(output) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("create output file: %w", err)
+	}
+
+	exporter := car.NewExporter(
+		&webCASReader{
+			httpClient: httpClient,
+			casURL:     strings.TrimSuffix(casURL, "/"),
+			authToken:  cmdutil.GetUserSetOptionalVarFromString(cmd, common.AuthTokenFlagName, common.AuthTokenEnvKey),
+		},
+		anchorlinkset.NewBuilder(generator.NewRegistry()),
+	)
+
+	report, err := exporter.Export(f, anchors...)
+
+	if closeErr := f.Close(); closeErr != nil && err == nil {
+		err = fmt.Errorf("close output file: %w", closeErr)
+	}
+
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+
+	return printReport(cmd, report)
+}
+
+// getPublishedAnchors returns the hashlinks of all anchors published in the given outbox.
+func getPublishedAnchors(cmd *cobra.Command, outboxURL string) ([]string, error) {
+	var anchors []string
+
+	err := common.ForEachOutboxActivity(cmd, outboxURL, func(activity *vocab.ActivityType) error {
+		if !activity.Type().Is(vocab.TypeCreate) || activity.Object().AnchorEvent() == nil {
+			return nil
+		}
+
+		for _, u := range activity.Object().AnchorEvent().URL() {
+			anchors = append(anchors, u.String())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get published anchors: %w", err)
+	}
+
+	if len(anchors) == 0 {
+		return nil, fmt.Errorf("no anchors were published in outbox %s", outboxURL)
+	}
+
+	return anchors, nil
+}
+
+type webCASReader struct {
+	httpClient *http.Client
+	casURL     string
+	authToken  string
+}
+
+// Read reads the content for the given hash from the CAS endpoint. ErrContentNotFound is returned
+// if the endpoint responds with status 404.
+func (r *webCASReader) Read(hash string) ([]byte, error) {
+	u := r.casURL + "/" + hash
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, u, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+
+	if r.authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+r.authToken)
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send request to %s: %w", u, err)
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.CloseResponseBodyError(logger, err)
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response from %s: %w", u, err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return body, nil
+	case http.StatusNotFound:
+		return nil, orberrors.ErrContentNotFound
+	default:
+		return nil, fmt.Errorf("got unexpected response from %s status '%d' body %s", u, resp.StatusCode, body)
+	}
+}
+
+func printReport(cmd *cobra.Command, report interface{}) error {
+	reportBytes, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal report: %w", err)
+	}
+
+	common.Println(cmd.OutOrStdout(), string(reportBytes))
+
+	return nil
+}
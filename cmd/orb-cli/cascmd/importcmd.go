@@ -0,0 +1,129 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cascmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+
+	ariesmongodbstorage "github.com/hyperledger/aries-framework-go-ext/component/storage/mongodb"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/spf13/cobra"
+
+	"github.com/trustbloc/orb/internal/pkg/cmdutil"
+	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/cas/car"
+	"github.com/trustbloc/orb/pkg/observability/metrics/noop"
+	casstore "github.com/trustbloc/orb/pkg/store/cas"
+)
+
+const (
+	inputFlagName  = "input"
+	inputEnvKey    = "ORB_CLI_INPUT"
+	inputFlagUsage = "The path of the CAR file to import." +
+		" Alternatively, this can be set with the following environment variable: " + inputEnvKey
+
+	databaseURLFlagName  = "database-url"
+	databaseURLEnvKey    = "ORB_CLI_DATABASE_URL"
+	databaseURLFlagUsage = "The URL (MongoDB connection string) of the database that holds the local CAS." +
+		" Alternatively, this can be set with the following environment variable: " + databaseURLEnvKey
+
+	databasePrefixFlagName  = "database-prefix"
+	databasePrefixEnvKey    = "ORB_CLI_DATABASE_PREFIX"
+	databasePrefixFlagUsage = "An optional prefix to be used when creating and retrieving underlying databases." +
+		" This must match the prefix used by the Orb server." +
+		" Alternatively, this can be set with the following environment variable: " + databasePrefixEnvKey
+)
+
+type storageProvider interface {
+	Get(databaseURL, databasePrefix string) (storage.Provider, error)
+}
+
+type mongoDBProvider struct{}
+
+func (p *mongoDBProvider) Get(databaseURL, databasePrefix string) (storage.Provider, error) {
+	return ariesmongodbstorage.NewProvider(databaseURL, ariesmongodbstorage.WithDBPrefix(databasePrefix))
+}
+
+func newImportCmd(provider storageProvider) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Imports a CAR file into the local CAS.",
+		Long: `Imports the blocks of a CAR (content-addressed archive) file into the local CAS of an Orb server. ` +
+			`The hash of every block is verified against its CID before it is written. For example: cas import ` +
+			`--input anchors.car --database-url mongodb://localhost:27017 --cas-url https://orb.domain1.com/cas`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeImport(cmd, provider)
+		},
+	}
+
+	cmd.Flags().StringP(inputFlagName, "", "", inputFlagUsage)
+	cmd.Flags().StringP(databaseURLFlagName, "", "", databaseURLFlagUsage)
+	cmd.Flags().StringP(databasePrefixFlagName, "", "", databasePrefixFlagUsage)
+	cmd.Flags().StringP(casURLFlagName, "", "", casURLFlagUsage)
+
+	return cmd
+}
+
+func executeImport(cmd *cobra.Command, provider storageProvider) error {
+	input, err := cmdutil.GetUserSetVarFromString(cmd, inputFlagName, inputEnvKey, false)
+	if err != nil {
+		return err
+	}
+
+	databaseURL, err := cmdutil.GetUserSetVarFromString(cmd, databaseURLFlagName, databaseURLEnvKey, false)
+	if err != nil {
+		return err
+	}
+
+	casURL, err := cmdutil.GetUserSetVarFromString(cmd, casURLFlagName, casURLEnvKey, false)
+	if err != nil {
+		return err
+	}
+
+	if _, err := url.ParseRequestURI(casURL); err != nil {
+		return fmt.Errorf("invalid CAS URL %s: %w", casURL, err)
+	}
+
+	databasePrefix := cmdutil.GetUserSetOptionalVarFromString(cmd, databasePrefixFlagName, databasePrefixEnvKey)
+
+	f, err := os.Open(input) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("open input file: %w", err)
+	}
+
+	defer func() {
+		if err := f.Close(); err != nil {
+			logger.Warn("Error closing input file", log.WithError(err))
+		}
+	}()
+
+	p, err := provider.Get(databaseURL, databasePrefix)
+	if err != nil {
+		return fmt.Errorf("create storage provider: %w", err)
+	}
+
+	defer func() {
+		if err := p.Close(); err != nil {
+			logger.Warn("Error closing storage provider", log.WithError(err))
+		}
+	}()
+
+	cas, err := casstore.New(p, casURL, nil, noop.GetMetrics(), 0)
+	if err != nil {
+		return fmt.Errorf("create local CAS: %w", err)
+	}
+
+	report, err := car.NewImporter(cas).Import(f)
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+
+	return printReport(cmd, report)
+}
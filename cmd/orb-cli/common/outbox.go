@@ -0,0 +1,63 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/spf13/cobra"
+
+	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+)
+
+// ForEachOutboxActivity pages through the outbox at the given URL and invokes the given function
+// for each activity.
+func ForEachOutboxActivity(cmd *cobra.Command, outboxURL string, handle func(activity *vocab.ActivityType) error) error {
+	respBytes, err := SendHTTPRequest(cmd, nil, http.MethodGet, outboxURL)
+	if err != nil {
+		return fmt.Errorf("get outbox: %w", err)
+	}
+
+	outbox := &vocab.OrderedCollectionType{}
+
+	if err := json.Unmarshal(respBytes, outbox); err != nil {
+		return fmt.Errorf("unmarshal outbox: %w", err)
+	}
+
+	for nextPage := outbox.First(); nextPage != nil; {
+		respBytes, err := SendHTTPRequest(cmd, nil, http.MethodGet, nextPage.String())
+		if err != nil {
+			return fmt.Errorf("get outbox page: %w", err)
+		}
+
+		page := &vocab.OrderedCollectionPageType{}
+
+		if err := json.Unmarshal(respBytes, page); err != nil {
+			return fmt.Errorf("unmarshal outbox page: %w", err)
+		}
+
+		for _, item := range page.Items() {
+			if item.Activity() == nil {
+				continue
+			}
+
+			if err := handle(item.Activity()); err != nil {
+				return err
+			}
+		}
+
+		if next := page.Next(); next == nil || next.String() == nextPage.String() {
+			nextPage = nil
+		} else {
+			nextPage = next
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,254 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dbcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/trustbloc/orb/cmd/orb-cli/common"
+	"github.com/trustbloc/orb/internal/pkg/cmdutil"
+	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/maintenance"
+	"github.com/trustbloc/orb/pkg/store/backup"
+	"github.com/trustbloc/orb/pkg/store/migrate"
+)
+
+const (
+	outputFlagName  = "output"
+	outputEnvKey    = "ORB_CLI_OUTPUT"
+	outputFlagUsage = "The path of the backup archive to create." +
+		" Alternatively, this can be set with the following environment variable: " + outputEnvKey
+
+	maintenanceURLFlagName  = "maintenance-url"
+	maintenanceURLEnvKey    = "ORB_CLI_MAINTENANCE_URL"
+	maintenanceURLFlagUsage = "The maintenance URL of an Orb server instance which is quiesced while the backup " +
+		"is taken, for example https://orb.domain1.com/maintenance. This flag must be repeated for each instance " +
+		"that uses the database. The backup fails if an instance is using the database (i.e. the instance " +
+		"updated its operation queue task within the last minute) but wasn't quiesced. If not specified then " +
+		"all Orb servers must be stopped before the backup is taken." +
+		" Alternatively, this can be set with the following environment variable (comma-separated): " +
+		maintenanceURLEnvKey
+
+	quiesceTimeoutFlagName  = "quiesce-timeout"
+	quiesceTimeoutEnvKey    = "ORB_CLI_QUIESCE_TIMEOUT"
+	quiesceTimeoutFlagUsage = "The maximum amount of time that the Orb servers remain quiesced. A server " +
+		"automatically resumes after this timeout, even if the backup hasn't completed. Defaults to 30m." +
+		" Alternatively, this can be set with the following environment variable: " + quiesceTimeoutEnvKey
+)
+
+const (
+	defaultQuiesceTimeout = 30 * time.Minute
+
+	// activeInstanceMaxAge is the maximum age of an instance's operation queue task for the instance to be
+	// considered active. Instances update their task every 10s (by default).
+	activeInstanceMaxAge = time.Minute
+)
+
+func newBackupCmd(opener databaseOpener) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Takes a snapshot of all Orb stores.",
+		Long: `Takes a snapshot of all Orb stores (including the local CAS) and writes it to a gzipped tar archive ` +
+			`along with a manifest. If maintenance URLs are provided then the Orb servers are quiesced (the ` +
+			`operation queue, batch writer, observer and ActivityPub service are paused) while the snapshot is ` +
+			`taken and resumed afterward. Every instance that is using the database must be quiesced (or stopped), ` +
+			`otherwise the backup fails. ` +
+			`For example: db backup --from mongodb://localhost:27017 --output orb-backup.tar.gz ` +
+			`--maintenance-url https://orb.domain1.com/maintenance --auth-token ADMIN_TOKEN`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeBackup(cmd, opener)
+		},
+	}
+
+	common.AddCommonFlags(cmd)
+
+	cmd.Flags().StringP(fromFlagName, "", "", fromFlagUsage)
+	cmd.Flags().StringP(fromDatabasePrefixFlagName, "", "", fromDatabasePrefixFlagUsage)
+	cmd.Flags().StringP(outputFlagName, "", "", outputFlagUsage)
+	cmd.Flags().StringArrayP(maintenanceURLFlagName, "", nil, maintenanceURLFlagUsage)
+	cmd.Flags().StringP(quiesceTimeoutFlagName, "", "", quiesceTimeoutFlagUsage)
+	cmd.Flags().StringArrayP(namespaceFlagName, "", nil, namespaceFlagUsage)
+
+	return cmd
+}
+
+func executeBackup(cmd *cobra.Command, opener databaseOpener) error {
+	from, err := cmdutil.GetUserSetVarFromString(cmd, fromFlagName, fromEnvKey, false)
+	if err != nil {
+		return err
+	}
+
+	output, err := cmdutil.GetUserSetVarFromString(cmd, outputFlagName, outputEnvKey, false)
+	if err != nil {
+		return err
+	}
+
+	quiesceTimeout, err := common.GetDuration(cmd, quiesceTimeoutFlagName, quiesceTimeoutEnvKey,
+		defaultQuiesceTimeout)
+	if err != nil {
+		return err
+	}
+
+	namespaces, err := getNamespaces(cmd)
+	if err != nil {
+		return err
+	}
+
+	opts := []backup.Option{backup.WithNamespaces(namespaces...)}
+
+	maintenanceURLs := cmdutil.GetUserSetOptionalVarFromArrayString(cmd, maintenanceURLFlagName, maintenanceURLEnvKey)
+	if len(maintenanceURLs) > 0 {
+		opts = append(opts, backup.WithQuiesced())
+	}
+
+	source, err := opener.Open(from, cmdutil.GetUserSetOptionalVarFromString(cmd, fromDatabasePrefixFlagName,
+		fromDatabasePrefixEnvKey))
+	if err != nil {
+		return fmt.Errorf("open source database: %w", err)
+	}
+
+	defer closeDatabase(source)
+
+	quiescedInstances, resume, err := quiesce(cmd, maintenanceURLs, quiesceTimeout)
+	if err != nil {
+		return err
+	}
+
+	defer resume()
+
+	if err := checkInstancesQuiesced(source, quiescedInstances); err != nil {
+		return err
+	}
+
+	manifest, err := writeBackup(output, func(f *os.File) (*backup.Manifest, error) {
+		return backup.Backup(source, f, opts...)
+	})
+	if err != nil {
+		return err
+	}
+
+	return printJSON(cmd, manifest)
+}
+
+// writeBackup writes the backup to a temporary file which is renamed to the given path only if the backup
+// succeeds, so that a partial archive is never left behind.
+func writeBackup(path string, write func(f *os.File) (*backup.Manifest, error)) (*backup.Manifest, error) {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-")
+	if err != nil {
+		return nil, fmt.Errorf("create backup file: %w", err)
+	}
+
+	tmpPath := f.Name()
+
+	defer func() {
+		if _, err := os.Stat(tmpPath); err == nil {
+			if err := os.Remove(tmpPath); err != nil {
+				logger.Warn("Error removing temporary backup file", log.WithError(err))
+			}
+		}
+	}()
+
+	manifest, err := write(f)
+	if err != nil {
+		_ = f.Close() //nolint:errcheck
+
+		return nil, fmt.Errorf("backup: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("close backup file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return nil, fmt.Errorf("rename backup file: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// checkInstancesQuiesced returns an error if a server instance is using the database but wasn't quiesced.
+func checkInstancesQuiesced(source migrate.Database, quiescedInstances map[string]struct{}) error {
+	activeInstances, err := backup.ActiveInstances(source, activeInstanceMaxAge)
+	if err != nil {
+		return fmt.Errorf("get active server instances: %w", err)
+	}
+
+	var notQuiesced []string
+
+	for _, instanceID := range activeInstances {
+		if _, ok := quiescedInstances[instanceID]; !ok {
+			notQuiesced = append(notQuiesced, instanceID)
+		}
+	}
+
+	if len(notQuiesced) > 0 {
+		return fmt.Errorf("server instances %s are using the database but weren't quiesced: a maintenance URL "+
+			"must be provided for every server instance", notQuiesced)
+	}
+
+	return nil
+}
+
+// quiesce quiesces the Orb servers at the given maintenance URLs and returns the IDs of the quiesced server
+// instances along with a function which resumes the servers. If a server fails to quiesce then the servers
+// that were already quiesced are resumed.
+func quiesce(cmd *cobra.Command, maintenanceURLs []string,
+	timeout time.Duration) (map[string]struct{}, func(), error) {
+	reqBytes, err := json.Marshal(map[string]string{"timeout": timeout.String()})
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal quiesce request: %w", err)
+	}
+
+	instanceIDs := make(map[string]struct{})
+
+	var quiesced []string
+
+	resume := func() {
+		for _, u := range quiesced {
+			if _, err := common.SendHTTPRequest(cmd, nil, http.MethodPost, maintenanceEndpoint(u, "resume")); err != nil {
+				logger.Error("Error resuming server. The server will resume automatically after the quiesce timeout.",
+					log.WithURLString(u), log.WithError(err))
+			}
+		}
+	}
+
+	for _, u := range maintenanceURLs {
+		respBytes, err := common.SendHTTPRequest(cmd, reqBytes, http.MethodPost, maintenanceEndpoint(u, "quiesce"))
+		if err != nil {
+			resume()
+
+			return nil, nil, fmt.Errorf("quiesce server: %w", err)
+		}
+
+		quiesced = append(quiesced, u)
+
+		status := &maintenance.Status{}
+
+		if err := json.Unmarshal(respBytes, status); err != nil {
+			resume()
+
+			return nil, nil, fmt.Errorf("unmarshal quiesce status from [%s]: %w", u, err)
+		}
+
+		instanceIDs[status.InstanceID] = struct{}{}
+	}
+
+	return instanceIDs, resume, nil
+}
+
+func maintenanceEndpoint(maintenanceURL, action string) string {
+	return strings.TrimSuffix(maintenanceURL, "/") + "/" + action
+}
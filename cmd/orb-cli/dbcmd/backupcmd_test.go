@@ -0,0 +1,407 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dbcmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/cmd/orb-cli/common"
+	"github.com/trustbloc/orb/pkg/store/backup"
+)
+
+func TestBackupRestoreCmd(t *testing.T) {
+	opener := newMockOpener()
+
+	populate(t, opener.get(sourceURL), "operation", 3)
+	populate(t, opener.get(sourceURL), "cas", 2)
+	addInstance(t, opener.get(sourceURL), "instance1", time.Now())
+	addInstance(t, opener.get(sourceURL), "instance2", time.Now())
+	addInstance(t, opener.get(sourceURL), "stopped-instance", time.Now().Add(-time.Hour))
+
+	serv1 := newMockMaintenanceServer(http.StatusOK, "instance1")
+	defer serv1.Close()
+
+	serv2 := newMockMaintenanceServer(http.StatusOK, "instance2")
+	defer serv2.Close()
+
+	archivePath := filepath.Join(t.TempDir(), "orb-backup.tar.gz")
+
+	t.Run("Backup", func(t *testing.T) {
+		cmd := newBackupCmd(opener)
+
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+		cmd.SetArgs([]string{
+			flag + fromFlagName, sourceURL, flag + outputFlagName, archivePath,
+			flag + namespaceFlagName, "operation", flag + namespaceFlagName, "cas",
+			flag + maintenanceURLFlagName, serv1.URL + "/maintenance",
+			flag + maintenanceURLFlagName, serv2.URL + "/maintenance/",
+			flag + quiesceTimeoutFlagName, "10m",
+			flag + common.AuthTokenFlagName, "ADMIN_TOKEN",
+		})
+
+		require.NoError(t, cmd.Execute())
+
+		manifest := &backup.Manifest{}
+		require.NoError(t, json.Unmarshal(out.Bytes(), manifest))
+		require.True(t, manifest.Quiesced)
+		require.Len(t, manifest.Namespaces, 2)
+		require.Equal(t, 3, manifest.Namespaces[0].Count)
+		require.Equal(t, 2, manifest.Namespaces[1].Count)
+
+		require.FileExists(t, archivePath)
+
+		for _, serv := range []*mockMaintenanceServer{serv1, serv2} {
+			require.Equal(t, []string{"/maintenance/quiesce", "/maintenance/resume"}, serv.paths())
+			require.Equal(t, `{"timeout":"10m0s"}`, serv.bodies()[0])
+			require.Equal(t, "Bearer ADMIN_TOKEN", serv.authHeader())
+		}
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		cmd := newRestoreCmd(opener)
+
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+		cmd.SetArgs([]string{
+			flag + toFlagName, targetURL, flag + inputFlagName, archivePath, flag + batchSizeFlagName, "2",
+		})
+
+		require.NoError(t, cmd.Execute())
+
+		report := &backup.Report{}
+		require.NoError(t, json.Unmarshal(out.Bytes(), report))
+		require.Len(t, report.Namespaces, 2)
+		require.Equal(t, 3, report.Namespaces[0].Restored)
+		require.True(t, report.Namespaces[0].Verified)
+		require.Equal(t, 2, report.Namespaces[1].Restored)
+		require.True(t, report.Namespaces[1].Verified)
+	})
+
+	t.Run("Restore verification failed", func(t *testing.T) {
+		// Restore to a database which already contains other entries.
+		populate(t, opener.get("mongodb://other"), "cas", 5)
+
+		cmd := newRestoreCmd(opener)
+
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+		cmd.SetArgs([]string{flag + toFlagName, "mongodb://other", flag + inputFlagName, archivePath})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "verification failed")
+		require.Contains(t, out.String(), `"verified": false`)
+	})
+}
+
+func TestBackupCmd_Error(t *testing.T) {
+	t.Run("Missing from", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{"backup"})
+
+		err := cmd.Execute()
+		require.EqualError(t, err,
+			"Neither from (command line flag) nor ORB_CLI_FROM (environment variable) have been set.")
+	})
+
+	t.Run("Missing output", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{"backup", flag + fromFlagName, sourceURL})
+
+		err := cmd.Execute()
+		require.EqualError(t, err,
+			"Neither output (command line flag) nor ORB_CLI_OUTPUT (environment variable) have been set.")
+	})
+
+	t.Run("Invalid quiesce timeout", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{
+			"backup", flag + fromFlagName, sourceURL, flag + outputFlagName, "backup.tar.gz",
+			flag + quiesceTimeoutFlagName, "xxx",
+		})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid value [xxx]")
+	})
+
+	t.Run("Unknown namespace", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{
+			"backup", flag + fromFlagName, sourceURL, flag + outputFlagName, "backup.tar.gz",
+			flag + namespaceFlagName, "xxx",
+		})
+
+		err := cmd.Execute()
+		require.EqualError(t, err, "unknown namespace: xxx")
+	})
+
+	t.Run("Open source error", func(t *testing.T) {
+		opener := newMockOpener()
+		opener.errs[sourceURL] = errors.New("injected open error")
+
+		cmd := newBackupCmd(opener)
+		cmd.SetArgs([]string{flag + fromFlagName, sourceURL, flag + outputFlagName, "backup.tar.gz"})
+
+		err := cmd.Execute()
+		require.EqualError(t, err, "open source database: injected open error")
+	})
+
+	t.Run("Quiesce error", func(t *testing.T) {
+		serv1 := newMockMaintenanceServer(http.StatusOK, "instance1")
+		defer serv1.Close()
+
+		serv2 := newMockMaintenanceServer(http.StatusInternalServerError, "instance2")
+		defer serv2.Close()
+
+		archivePath := filepath.Join(t.TempDir(), "orb-backup.tar.gz")
+
+		cmd := newBackupCmd(newMockOpener())
+		cmd.SetArgs([]string{
+			flag + fromFlagName, sourceURL, flag + outputFlagName, archivePath,
+			flag + maintenanceURLFlagName, serv1.URL + "/maintenance",
+			flag + maintenanceURLFlagName, serv2.URL + "/maintenance",
+		})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "quiesce server")
+
+		// The first server must have been resumed.
+		require.Equal(t, []string{"/maintenance/quiesce", "/maintenance/resume"}, serv1.paths())
+		require.NoFileExists(t, archivePath)
+	})
+
+	t.Run("Instance not quiesced", func(t *testing.T) {
+		opener := newMockOpener()
+		addInstance(t, opener.get(sourceURL), "instance1", time.Now())
+		addInstance(t, opener.get(sourceURL), "instance2", time.Now())
+
+		serv := newMockMaintenanceServer(http.StatusOK, "instance1")
+		defer serv.Close()
+
+		archivePath := filepath.Join(t.TempDir(), "orb-backup.tar.gz")
+
+		cmd := newBackupCmd(opener)
+		cmd.SetArgs([]string{
+			flag + fromFlagName, sourceURL, flag + outputFlagName, archivePath,
+			flag + maintenanceURLFlagName, serv.URL + "/maintenance",
+		})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "server instances [instance2] are using the database but weren't quiesced")
+
+		// The server must be resumed.
+		require.Equal(t, []string{"/maintenance/quiesce", "/maintenance/resume"}, serv.paths())
+		require.NoFileExists(t, archivePath)
+	})
+
+	t.Run("Instance running without maintenance URL", func(t *testing.T) {
+		opener := newMockOpener()
+		addInstance(t, opener.get(sourceURL), "instance1", time.Now())
+
+		cmd := newBackupCmd(opener)
+		cmd.SetArgs([]string{
+			flag + fromFlagName, sourceURL, flag + outputFlagName, filepath.Join(t.TempDir(), "orb-backup.tar.gz"),
+		})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "server instances [instance1] are using the database but weren't quiesced")
+	})
+
+	t.Run("Invalid quiesce status", func(t *testing.T) {
+		serv := newMockMaintenanceServer(http.StatusOK, "")
+		defer serv.Close()
+
+		cmd := newBackupCmd(newMockOpener())
+		cmd.SetArgs([]string{
+			flag + fromFlagName, sourceURL, flag + outputFlagName, filepath.Join(t.TempDir(), "orb-backup.tar.gz"),
+			flag + maintenanceURLFlagName, serv.URL + "/maintenance",
+		})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal quiesce status")
+		require.Equal(t, []string{"/maintenance/quiesce", "/maintenance/resume"}, serv.paths())
+	})
+
+	t.Run("Backup error", func(t *testing.T) {
+		serv := newMockMaintenanceServer(http.StatusOK, "instance1")
+		defer serv.Close()
+
+		archivePath := filepath.Join(t.TempDir(), "xxx", "orb-backup.tar.gz")
+
+		cmd := newBackupCmd(newMockOpener())
+		cmd.SetArgs([]string{
+			flag + fromFlagName, sourceURL, flag + outputFlagName, archivePath,
+			flag + maintenanceURLFlagName, serv.URL + "/maintenance",
+		})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "create backup file")
+
+		// The server must be resumed.
+		require.Equal(t, []string{"/maintenance/quiesce", "/maintenance/resume"}, serv.paths())
+	})
+}
+
+func TestRestoreCmd_Error(t *testing.T) {
+	t.Run("Missing to", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{"restore"})
+
+		err := cmd.Execute()
+		require.EqualError(t, err,
+			"Neither to (command line flag) nor ORB_CLI_TO (environment variable) have been set.")
+	})
+
+	t.Run("Missing input", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{"restore", flag + toFlagName, targetURL})
+
+		err := cmd.Execute()
+		require.EqualError(t, err,
+			"Neither input (command line flag) nor ORB_CLI_INPUT (environment variable) have been set.")
+	})
+
+	t.Run("Invalid batch size", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{
+			"restore", flag + toFlagName, targetURL, flag + inputFlagName, "backup.tar.gz",
+			flag + batchSizeFlagName, "xxx",
+		})
+
+		err := cmd.Execute()
+		require.EqualError(t, err, "invalid value for batch-size: xxx")
+	})
+
+	t.Run("Unknown namespace", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{
+			"restore", flag + toFlagName, targetURL, flag + inputFlagName, "backup.tar.gz",
+			flag + namespaceFlagName, "xxx",
+		})
+
+		err := cmd.Execute()
+		require.EqualError(t, err, "unknown namespace: xxx")
+	})
+
+	t.Run("Input not found", func(t *testing.T) {
+		cmd := newRestoreCmd(newMockOpener())
+		cmd.SetArgs([]string{flag + toFlagName, targetURL, flag + inputFlagName, filepath.Join(t.TempDir(), "xxx")})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "open backup file")
+	})
+
+	t.Run("Invalid archive", func(t *testing.T) {
+		archivePath := filepath.Join(t.TempDir(), "orb-backup.tar.gz")
+		require.NoError(t, os.WriteFile(archivePath, []byte("xxx"), 0o600))
+
+		cmd := newRestoreCmd(newMockOpener())
+		cmd.SetArgs([]string{flag + toFlagName, targetURL, flag + inputFlagName, archivePath})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "restore: open archive")
+	})
+
+	t.Run("Open target error", func(t *testing.T) {
+		archivePath := filepath.Join(t.TempDir(), "orb-backup.tar.gz")
+		require.NoError(t, os.WriteFile(archivePath, []byte("xxx"), 0o600))
+
+		opener := newMockOpener()
+		opener.errs[targetURL] = errors.New("injected open error")
+
+		cmd := newRestoreCmd(opener)
+		cmd.SetArgs([]string{flag + toFlagName, targetURL, flag + inputFlagName, archivePath})
+
+		err := cmd.Execute()
+		require.EqualError(t, err, "open target database: injected open error")
+	})
+}
+
+type mockMaintenanceServer struct {
+	*httptest.Server
+
+	mutex    sync.Mutex
+	requests []string
+	body     []string
+	auth     string
+}
+
+func newMockMaintenanceServer(status int, instanceID string) *mockMaintenanceServer {
+	s := &mockMaintenanceServer{}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body) //nolint:errcheck
+
+		s.mutex.Lock()
+		s.requests = append(s.requests, r.URL.Path)
+		s.body = append(s.body, string(body))
+		s.auth = r.Header.Get("Authorization")
+		s.mutex.Unlock()
+
+		w.WriteHeader(status)
+
+		// An empty instance ID results in an invalid (empty) response.
+		if status == http.StatusOK && instanceID != "" {
+			_, _ = fmt.Fprintf(w, `{"instanceID":"%s","quiesced":true}`, instanceID) //nolint:errcheck
+		}
+	}))
+
+	return s
+}
+
+func addInstance(t *testing.T, db *mockDatabase, instanceID string, updated time.Time) {
+	t.Helper()
+
+	s, err := db.OpenStore("operation-queue")
+	require.NoError(t, err)
+
+	require.NoError(t, s.Put(instanceID,
+		[]byte(fmt.Sprintf(`{"taskID":"%s","updatedTime":%d}`, instanceID, updated.Unix()))))
+}
+
+func (s *mockMaintenanceServer) paths() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.requests
+}
+
+func (s *mockMaintenanceServer) bodies() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.body
+}
+
+func (s *mockMaintenanceServer) authHeader() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.auth
+}
@@ -0,0 +1,202 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dbcmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	_ "github.com/go-kivik/couchdb/v3" // The CouchDB driver.
+	"github.com/go-kivik/kivik/v3"
+	ariescouchdbstorage "github.com/hyperledger/aries-framework-go-ext/component/storage/couchdb"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/store/migrate"
+)
+
+const (
+	couchDBScheme  = "couchdb"
+	couchDBSScheme = "couchdbs"
+
+	designDocPrefix = "_design/"
+)
+
+// couchDBDocument is the format of the documents written by the Aries CouchDB storage provider.
+type couchDBDocument struct {
+	Value []byte                 `json:"value,omitempty"`
+	Tags  map[string]interface{} `json:"tags,omitempty"`
+}
+
+// couchDBDatabase scans CouchDB databases in the order of their document IDs.
+type couchDBDatabase struct {
+	storage.Provider
+
+	client *kivik.Client
+	prefix string
+}
+
+func newCouchDBDatabase(u *url.URL, databasePrefix string) (*couchDBDatabase, error) {
+	hostURL := *u
+
+	if u.Scheme == couchDBSScheme {
+		hostURL.Scheme = "https"
+	} else {
+		hostURL.Scheme = "http"
+	}
+
+	p, err := ariescouchdbstorage.NewProvider(hostURL.String(), ariescouchdbstorage.WithDBPrefix(databasePrefix))
+	if err != nil {
+		return nil, fmt.Errorf("create CouchDB storage provider: %w", err)
+	}
+
+	client, err := kivik.New("couch", hostURL.String())
+	if err != nil {
+		return nil, fmt.Errorf("create CouchDB client: %w", err)
+	}
+
+	return &couchDBDatabase{
+		Provider: p,
+		client:   client,
+		prefix:   databasePrefix,
+	}, nil
+}
+
+// Scan invokes the given function for each document in the database whose ID is greater than afterKey.
+// Design documents are skipped.
+func (db *couchDBDatabase) Scan(ns *migrate.Namespace, afterKey string, handle func(entry *migrate.Entry) error) error {
+	ctx := context.Background()
+
+	d, ok, err := db.getDB(ctx, ns.Name)
+	if err != nil || !ok {
+		return err
+	}
+
+	opts := kivik.Options{"include_docs": true}
+	if afterKey != "" {
+		opts["startkey"] = afterKey
+	}
+
+	rows, err := d.AllDocs(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("get all documents in [%s]: %w", ns.Name, err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Warn("Error closing rows", log.WithError(err))
+		}
+	}()
+
+	for rows.Next() {
+		key := rows.ID()
+
+		if key == afterKey || strings.HasPrefix(key, designDocPrefix) {
+			continue
+		}
+
+		doc := &couchDBDocument{}
+
+		if err := rows.ScanDoc(doc); err != nil {
+			return fmt.Errorf("scan document [%s] in [%s]: %w", key, ns.Name, err)
+		}
+
+		tags, err := toTags(doc.Tags)
+		if err != nil {
+			return fmt.Errorf("tags of document [%s] in [%s]: %w", key, ns.Name, err)
+		}
+
+		if err := handle(&migrate.Entry{Key: key, Value: doc.Value, Tags: tags}); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("get all documents in [%s]: %w", ns.Name, err)
+	}
+
+	return nil
+}
+
+// Count returns the number of documents in the database, excluding design documents.
+func (db *couchDBDatabase) Count(ns *migrate.Namespace) (int, error) {
+	ctx := context.Background()
+
+	d, ok, err := db.getDB(ctx, ns.Name)
+	if err != nil || !ok {
+		return 0, err
+	}
+
+	stats, err := d.Stats(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("get stats of [%s]: %w", ns.Name, err)
+	}
+
+	rows, err := d.AllDocs(ctx, kivik.Options{"startkey": designDocPrefix, "endkey": designDocPrefix + "\ufff0"})
+	if err != nil {
+		return 0, fmt.Errorf("get design documents in [%s]: %w", ns.Name, err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Warn("Error closing rows", log.WithError(err))
+		}
+	}()
+
+	count := int(stats.DocCount)
+
+	for rows.Next() {
+		count--
+	}
+
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("get design documents in [%s]: %w", ns.Name, err)
+	}
+
+	return count, nil
+}
+
+// getDB returns the database for the given store name. False is returned if the database doesn't exist.
+func (db *couchDBDatabase) getDB(ctx context.Context, name string) (*kivik.DB, bool, error) {
+	// The Aries CouchDB storage provider uses lower-case database names.
+	dbName := strings.ToLower(db.prefix + name)
+
+	exists, err := db.client.DBExists(ctx, dbName)
+	if err != nil {
+		return nil, false, fmt.Errorf("check if database [%s] exists: %w", dbName, err)
+	}
+
+	if !exists {
+		return nil, false, nil
+	}
+
+	d := db.client.DB(ctx, dbName)
+	if err := d.Err(); err != nil {
+		return nil, false, fmt.Errorf("get database [%s]: %w", dbName, err)
+	}
+
+	return d, true, nil
+}
+
+// toTags converts the tags of a CouchDB document. Numeric tag values are held as numbers by the Aries
+// CouchDB storage provider.
+func toTags(tagMap map[string]interface{}) ([]storage.Tag, error) {
+	var tags []storage.Tag
+
+	for name, v := range tagMap {
+		value, err := toTagValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("tag [%s]: %w", name, err)
+		}
+
+		tags = append(tags, storage.Tag{Name: name, Value: value})
+	}
+
+	return tags, nil
+}
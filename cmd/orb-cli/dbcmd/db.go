@@ -0,0 +1,33 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dbcmd
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+)
+
+// GetCmd returns the Cobra db command.
+func GetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "db",
+		Short:        "Manages Orb databases.",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return errors.New("expecting subcommand migrate, backup or restore")
+		},
+	}
+
+	cmd.AddCommand(
+		newMigrateCmd(&databaseProvider{}),
+		newBackupCmd(&databaseProvider{}),
+		newRestoreCmd(&databaseProvider{}),
+	)
+
+	return cmd
+}
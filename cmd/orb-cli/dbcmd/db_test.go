@@ -0,0 +1,543 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dbcmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/store/migrate"
+)
+
+const (
+	flag = "--"
+
+	sourceURL = "couchdb://localhost:5984"
+	targetURL = "mongodb://localhost:27017"
+)
+
+func TestDBCmd(t *testing.T) {
+	t.Run("No subcommand", func(t *testing.T) {
+		err := GetCmd().Execute()
+		require.EqualError(t, err, "expecting subcommand migrate, backup or restore")
+	})
+}
+
+func TestMigrateCmd(t *testing.T) {
+	t.Run("Missing from", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{"migrate"})
+
+		err := cmd.Execute()
+		require.EqualError(t, err,
+			"Neither from (command line flag) nor ORB_CLI_FROM (environment variable) have been set.")
+	})
+
+	t.Run("Missing to", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{"migrate", flag + fromFlagName, sourceURL})
+
+		err := cmd.Execute()
+		require.EqualError(t, err,
+			"Neither to (command line flag) nor ORB_CLI_TO (environment variable) have been set.")
+	})
+
+	t.Run("Same source and target", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{"migrate", flag + fromFlagName, sourceURL, flag + toFlagName, sourceURL})
+
+		err := cmd.Execute()
+		require.EqualError(t, err, "the source and target databases must be different")
+	})
+
+	t.Run("Unknown namespace", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{
+			"migrate", flag + fromFlagName, sourceURL, flag + toFlagName, targetURL,
+			flag + namespaceFlagName, "xxx",
+		})
+
+		err := cmd.Execute()
+		require.EqualError(t, err, "unknown namespace: xxx")
+	})
+
+	t.Run("Invalid batch size", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{
+			"migrate", flag + fromFlagName, sourceURL, flag + toFlagName, targetURL,
+			flag + batchSizeFlagName, "0",
+		})
+
+		err := cmd.Execute()
+		require.EqualError(t, err, "invalid value for batch-size: 0")
+	})
+
+	t.Run("Invalid restart", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{
+			"migrate", flag + fromFlagName, sourceURL, flag + toFlagName, targetURL,
+			flag + restartFlagName, "xxx",
+		})
+
+		err := cmd.Execute()
+		require.EqualError(t, err, "invalid value for restart: xxx")
+	})
+
+	t.Run("Unsupported scheme", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{"migrate", flag + fromFlagName, "mysql://localhost", flag + toFlagName, targetURL})
+
+		err := cmd.Execute()
+		require.EqualError(t, err, "open source database: unsupported database URL scheme [mysql]")
+	})
+
+	t.Run("Invalid URL", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{"migrate", flag + fromFlagName, ":invalid", flag + toFlagName, targetURL})
+
+		err := cmd.Execute()
+		require.EqualError(t, err, "open source database: invalid database URL")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		opener := newMockOpener()
+
+		populate(t, opener.get(sourceURL), "operation", 3)
+		populate(t, opener.get(sourceURL), "cas", 2)
+
+		cmd := newMigrateCmd(opener)
+
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+		cmd.SetArgs([]string{
+			flag + fromFlagName, sourceURL, flag + toFlagName, targetURL,
+			flag + namespaceFlagName, "operation", flag + namespaceFlagName, "cas",
+			flag + batchSizeFlagName, "2", flag + restartFlagName, "true",
+		})
+
+		require.NoError(t, cmd.Execute())
+
+		report := &migrate.Report{}
+		require.NoError(t, json.Unmarshal(out.Bytes(), report))
+		require.Len(t, report.Namespaces, 2)
+		require.Equal(t, 3, report.Namespaces[0].Migrated)
+		require.True(t, report.Namespaces[0].Verified)
+		require.Equal(t, 2, report.Namespaces[1].Migrated)
+		require.True(t, report.Namespaces[1].Verified)
+	})
+
+	t.Run("Verification failed", func(t *testing.T) {
+		opener := newMockOpener()
+
+		populate(t, opener.get(targetURL), "cas", 1)
+
+		cmd := newMigrateCmd(opener)
+
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+		cmd.SetArgs([]string{
+			flag + fromFlagName, sourceURL, flag + toFlagName, targetURL, flag + namespaceFlagName, "cas",
+		})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "verification failed")
+		require.Contains(t, out.String(), `"verified": false`)
+	})
+
+	t.Run("Open target error", func(t *testing.T) {
+		opener := newMockOpener()
+		opener.errs[targetURL] = errors.New("injected open error")
+
+		cmd := newMigrateCmd(opener)
+		cmd.SetArgs([]string{flag + fromFlagName, sourceURL, flag + toFlagName, targetURL})
+
+		err := cmd.Execute()
+		require.EqualError(t, err, "open target database: injected open error")
+	})
+}
+
+func TestNewEntryFromDocument(t *testing.T) {
+	ns, ok := migrate.FindNamespace("anchor-status")
+	require.True(t, ok)
+
+	t.Run("Success", func(t *testing.T) {
+		entry, err := newEntryFromDocument(ns, map[string]interface{}{
+			"_id":        "key1",
+			"anchorID":   "anchor1",
+			"status":     "completed",
+			"expiryTime": int64(1666000000),
+			"other":      "value",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "key1", entry.Key)
+		require.JSONEq(t,
+			`{"anchorID":"anchor1","status":"completed","expiryTime":1666000000,"other":"value"}`,
+			string(entry.Value))
+		require.ElementsMatch(t, []storage.Tag{
+			{Name: "anchorID", Value: "anchor1"},
+			{Name: "status", Value: "completed"},
+			{Name: "expiryTime", Value: "1666000000"},
+		}, entry.Tags)
+	})
+
+	t.Run("No ID", func(t *testing.T) {
+		_, err := newEntryFromDocument(ns, map[string]interface{}{"anchorID": "anchor1"})
+		require.EqualError(t, err, "document has no string _id field")
+	})
+
+	t.Run("Unsupported tag value", func(t *testing.T) {
+		_, err := newEntryFromDocument(ns, map[string]interface{}{"_id": "key1", "status": []string{"x"}})
+		require.EqualError(t, err, "tag [status] of document [key1]: unsupported tag value type []string")
+	})
+}
+
+func TestToTagValue(t *testing.T) {
+	for _, tc := range []struct {
+		value    interface{}
+		expected string
+	}{
+		{value: "value", expected: "value"},
+		{value: int32(12), expected: "12"},
+		{value: int64(1666000000), expected: "1666000000"},
+		{value: float64(1666000000), expected: "1666000000"},
+		{value: 1.5, expected: "1.5"},
+		{value: true, expected: "true"},
+	} {
+		v, err := toTagValue(tc.value)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, v)
+	}
+}
+
+func TestCouchDBDatabase(t *testing.T) {
+	ns := &migrate.Namespace{Name: "operation"}
+
+	serv := httptest.NewServer(newMockCouchDBHandler(map[string][]mockCouchDBRow{
+		"orb_operation": {
+			{ID: "_design/AriesStorageDesignDocument"},
+			{ID: "key1", Doc: &couchDBDocument{
+				Value: []byte(`{"value":1}`),
+				Tags:  map[string]interface{}{"uniqueSuffix": "suffix1", "expiryTime": float64(1666000000)},
+			}},
+			{ID: "key2", Doc: &couchDBDocument{Value: []byte(`{"value":2}`)}},
+			{ID: "key3", Doc: &couchDBDocument{Value: []byte(`{"value":3}`)}},
+		},
+	}))
+	defer serv.Close()
+
+	u, err := url.Parse(strings.Replace(serv.URL, "http", couchDBScheme, 1))
+	require.NoError(t, err)
+
+	db, err := newCouchDBDatabase(u, "orb_")
+	require.NoError(t, err)
+
+	t.Run("Scan", func(t *testing.T) {
+		var entries []*migrate.Entry
+
+		require.NoError(t, db.Scan(ns, "", func(entry *migrate.Entry) error {
+			entries = append(entries, entry)
+
+			return nil
+		}))
+
+		require.Len(t, entries, 3)
+		require.Equal(t, "key1", entries[0].Key)
+		require.Equal(t, `{"value":1}`, string(entries[0].Value))
+		require.ElementsMatch(t, []storage.Tag{
+			{Name: "uniqueSuffix", Value: "suffix1"},
+			{Name: "expiryTime", Value: "1666000000"},
+		}, entries[0].Tags)
+	})
+
+	t.Run("Scan after key", func(t *testing.T) {
+		var keys []string
+
+		require.NoError(t, db.Scan(ns, "key1", func(entry *migrate.Entry) error {
+			keys = append(keys, entry.Key)
+
+			return nil
+		}))
+
+		require.Equal(t, []string{"key2", "key3"}, keys)
+	})
+
+	t.Run("Scan handler error", func(t *testing.T) {
+		errExpected := errors.New("injected handler error")
+
+		err := db.Scan(ns, "", func(entry *migrate.Entry) error {
+			return errExpected
+		})
+		require.True(t, errors.Is(err, errExpected))
+	})
+
+	t.Run("Count", func(t *testing.T) {
+		count, err := db.Count(ns)
+		require.NoError(t, err)
+		require.Equal(t, 3, count)
+	})
+
+	t.Run("Database doesn't exist", func(t *testing.T) {
+		missing := &migrate.Namespace{Name: "missing"}
+
+		require.NoError(t, db.Scan(missing, "", func(entry *migrate.Entry) error {
+			return errors.New("unexpected entry")
+		}))
+
+		count, err := db.Count(missing)
+		require.NoError(t, err)
+		require.Zero(t, count)
+	})
+}
+
+type mockCouchDBRow struct {
+	ID  string
+	Doc *couchDBDocument
+}
+
+// newMockCouchDBHandler returns a handler which implements the subset of the CouchDB API that is used
+// by the CouchDB database.
+func newMockCouchDBHandler(dbs map[string][]mockCouchDBRow) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+		if parts[0] == "_users" {
+			w.WriteHeader(http.StatusOK)
+
+			return
+		}
+
+		rows, ok := dbs[parts[0]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.Method == http.MethodHead:
+			w.WriteHeader(http.StatusOK)
+		case len(parts) == 1:
+			fmt.Fprintf(w, `{"db_name":%q,"doc_count":%d}`, parts[0], len(rows))
+		default:
+			writeAllDocs(w, r, rows)
+		}
+	})
+}
+
+func writeAllDocs(w http.ResponseWriter, r *http.Request, rows []mockCouchDBRow) {
+	var startKey, endKey string
+
+	if v := r.URL.Query().Get("startkey"); v != "" {
+		_ = json.Unmarshal([]byte(v), &startKey) //nolint:errcheck
+	}
+
+	if v := r.URL.Query().Get("endkey"); v != "" {
+		_ = json.Unmarshal([]byte(v), &endKey) //nolint:errcheck
+	}
+
+	includeDocs := r.URL.Query().Get("include_docs") == "true"
+
+	type row struct {
+		ID    string                 `json:"id"`
+		Key   string                 `json:"key"`
+		Value map[string]interface{} `json:"value"`
+		Doc   *couchDBDocument       `json:"doc,omitempty"`
+	}
+
+	var result []row
+
+	for _, rw := range rows {
+		if rw.ID < startKey || (endKey != "" && rw.ID > endKey) {
+			continue
+		}
+
+		rr := row{ID: rw.ID, Key: rw.ID, Value: map[string]interface{}{"rev": "1-x"}}
+
+		if includeDocs {
+			rr.Doc = rw.Doc
+			if rr.Doc == nil {
+				rr.Doc = &couchDBDocument{}
+			}
+		}
+
+		result = append(result, rr)
+	}
+
+	resultBytes, _ := json.Marshal(map[string]interface{}{ //nolint:errchkjson
+		"total_rows": len(rows),
+		"offset":     0,
+		"rows":       result,
+	})
+
+	_, _ = w.Write(resultBytes) //nolint:errcheck
+}
+
+type mockOpener struct {
+	mutex sync.Mutex
+	dbs   map[string]*mockDatabase
+	errs  map[string]error
+}
+
+func newMockOpener() *mockOpener {
+	return &mockOpener{
+		dbs:  make(map[string]*mockDatabase),
+		errs: make(map[string]error),
+	}
+}
+
+func (o *mockOpener) Open(databaseURL, _ string) (migrate.Database, error) {
+	if err := o.errs[databaseURL]; err != nil {
+		return nil, err
+	}
+
+	return o.get(databaseURL), nil
+}
+
+func (o *mockOpener) get(databaseURL string) *mockDatabase {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	db, ok := o.dbs[databaseURL]
+	if !ok {
+		db = &mockDatabase{Provider: mem.NewProvider(), keys: make(map[string]map[string]struct{})}
+		o.dbs[databaseURL] = db
+	}
+
+	return db
+}
+
+func populate(t *testing.T, db *mockDatabase, name string, n int) {
+	t.Helper()
+
+	s, err := db.OpenStore(name)
+	require.NoError(t, err)
+
+	for i := 0; i < n; i++ {
+		require.NoError(t, s.Put(fmt.Sprintf("key-%d", i), []byte(fmt.Sprintf(`{"value":%d}`, i))))
+	}
+}
+
+// mockDatabase is an in-memory database which keeps track of the keys in each store so that the
+// stores may be scanned.
+type mockDatabase struct {
+	storage.Provider
+
+	mutex sync.Mutex
+	keys  map[string]map[string]struct{}
+}
+
+func (db *mockDatabase) OpenStore(name string) (storage.Store, error) {
+	s, err := db.Provider.OpenStore(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mockStore{Store: s, db: db, name: name}, nil
+}
+
+func (db *mockDatabase) Scan(ns *migrate.Namespace, afterKey string, handle func(entry *migrate.Entry) error) error {
+	s, err := db.Provider.OpenStore(ns.Name)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range db.sortedKeys(ns.Name) {
+		if key <= afterKey {
+			continue
+		}
+
+		value, err := s.Get(key)
+		if err != nil {
+			return err
+		}
+
+		if err := handle(&migrate.Entry{Key: key, Value: value}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (db *mockDatabase) Count(ns *migrate.Namespace) (int, error) {
+	return len(db.sortedKeys(ns.Name)), nil
+}
+
+func (db *mockDatabase) sortedKeys(name string) []string {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	var keys []string
+
+	for key := range db.keys[name] {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (db *mockDatabase) addKeys(name string, keys ...string) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	m, ok := db.keys[name]
+	if !ok {
+		m = make(map[string]struct{})
+		db.keys[name] = m
+	}
+
+	for _, key := range keys {
+		m[key] = struct{}{}
+	}
+}
+
+type mockStore struct {
+	storage.Store
+
+	db   *mockDatabase
+	name string
+}
+
+func (s *mockStore) Put(key string, value []byte, tags ...storage.Tag) error {
+	if err := s.Store.Put(key, value, tags...); err != nil {
+		return err
+	}
+
+	s.db.addKeys(s.name, key)
+
+	return nil
+}
+
+func (s *mockStore) Batch(operations []storage.Operation) error {
+	if err := s.Store.Batch(operations); err != nil {
+		return err
+	}
+
+	for _, op := range operations {
+		s.db.addKeys(s.name, op.Key)
+	}
+
+	return nil
+}
@@ -0,0 +1,205 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dbcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	ariesmongodbstorage "github.com/hyperledger/aries-framework-go-ext/component/storage/mongodb"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"go.mongodb.org/mongo-driver/bson"
+	mongooptions "go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/store/migrate"
+)
+
+const (
+	mongoDBScheme    = "mongodb"
+	mongoDBSRVScheme = "mongodb+srv"
+
+	idField = "_id"
+)
+
+type mongoDBStore interface {
+	QueryCustom(filter interface{}, options ...*mongooptions.FindOptions) (ariesmongodbstorage.Iterator, error)
+}
+
+// mongoDBDatabase scans MongoDB collections in the order of their IDs.
+type mongoDBDatabase struct {
+	*ariesmongodbstorage.Provider
+}
+
+func newMongoDBDatabase(connString, databasePrefix string) (*mongoDBDatabase, error) {
+	p, err := ariesmongodbstorage.NewProvider(connString, ariesmongodbstorage.WithDBPrefix(databasePrefix))
+	if err != nil {
+		return nil, fmt.Errorf("create MongoDB storage provider: %w", err)
+	}
+
+	return &mongoDBDatabase{Provider: p}, nil
+}
+
+// Scan invokes the given function for each entry in the collection whose ID is greater than afterKey.
+func (db *mongoDBDatabase) Scan(ns *migrate.Namespace, afterKey string, handle func(entry *migrate.Entry) error) error {
+	s, err := db.openStore(ns.Name)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.D{}
+	if afterKey != "" {
+		filter = bson.D{{Key: idField, Value: bson.D{{Key: "$gt", Value: afterKey}}}}
+	}
+
+	it, err := s.QueryCustom(filter, mongooptions.Find().SetSort(bson.D{{Key: idField, Value: 1}}))
+	if err != nil {
+		return fmt.Errorf("query [%s]: %w", ns.Name, err)
+	}
+
+	defer func() {
+		if err := it.Close(); err != nil {
+			logger.Warn("Error closing iterator", log.WithError(err))
+		}
+	}()
+
+	for {
+		ok, err := it.Next()
+		if err != nil {
+			return fmt.Errorf("next entry in [%s]: %w", ns.Name, err)
+		}
+
+		if !ok {
+			return nil
+		}
+
+		entry, err := getMongoDBEntry(ns, it)
+		if err != nil {
+			return fmt.Errorf("get entry from [%s]: %w", ns.Name, err)
+		}
+
+		if err := handle(entry); err != nil {
+			return err
+		}
+	}
+}
+
+// Count returns the number of documents in the collection.
+func (db *mongoDBDatabase) Count(ns *migrate.Namespace) (int, error) {
+	s, err := db.openStore(ns.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	it, err := s.QueryCustom(bson.D{})
+	if err != nil {
+		return 0, fmt.Errorf("query [%s]: %w", ns.Name, err)
+	}
+
+	defer func() {
+		if err := it.Close(); err != nil {
+			logger.Warn("Error closing iterator", log.WithError(err))
+		}
+	}()
+
+	return it.TotalItems()
+}
+
+func (db *mongoDBDatabase) openStore(name string) (mongoDBStore, error) {
+	s, err := db.OpenStore(name)
+	if err != nil {
+		return nil, fmt.Errorf("open store [%s]: %w", name, err)
+	}
+
+	ms, ok := s.(mongoDBStore)
+	if !ok {
+		return nil, fmt.Errorf("store [%s] is not a MongoDB store", name)
+	}
+
+	return ms, nil
+}
+
+// getMongoDBEntry returns the entry for the current document of the iterator. Native stores hold documents in the
+// Aries format (with the tags in a separate field) whereas Orb stores hold the value as the document itself, in
+// which case the tags are taken from the top-level fields of the document.
+func getMongoDBEntry(ns *migrate.Namespace, it ariesmongodbstorage.Iterator) (*migrate.Entry, error) {
+	if ns.Native {
+		key, err := it.Key()
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := it.Value()
+		if err != nil {
+			return nil, err
+		}
+
+		tags, err := it.Tags()
+		if err != nil {
+			return nil, err
+		}
+
+		return &migrate.Entry{Key: key, Value: value, Tags: tags}, nil
+	}
+
+	doc, err := it.ValueAsRawMap()
+	if err != nil {
+		return nil, err
+	}
+
+	return newEntryFromDocument(ns, doc)
+}
+
+func newEntryFromDocument(ns *migrate.Namespace, doc map[string]interface{}) (*migrate.Entry, error) {
+	key, ok := doc[idField].(string)
+	if !ok {
+		return nil, fmt.Errorf("document has no string %s field", idField)
+	}
+
+	delete(doc, idField)
+
+	value, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("marshal document [%s]: %w", key, err)
+	}
+
+	var tags []storage.Tag
+
+	for _, name := range ns.TagNames() {
+		v, ok := doc[name]
+		if !ok {
+			continue
+		}
+
+		tagValue, err := toTagValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("tag [%s] of document [%s]: %w", name, key, err)
+		}
+
+		tags = append(tags, storage.Tag{Name: name, Value: tagValue})
+	}
+
+	return &migrate.Entry{Key: key, Value: value, Tags: tags}, nil
+}
+
+func toTagValue(v interface{}) (string, error) {
+	switch value := v.(type) {
+	case string:
+		return value, nil
+	case int32:
+		return strconv.FormatInt(int64(value), 10), nil
+	case int64:
+		return strconv.FormatInt(value, 10), nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	default:
+		return "", fmt.Errorf("unsupported tag value type %T", v)
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dbcmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/trustbloc/orb/internal/pkg/cmdutil"
+	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/store/backup"
+)
+
+const (
+	inputFlagName  = "input"
+	inputEnvKey    = "ORB_CLI_INPUT"
+	inputFlagUsage = "The path of the backup archive to restore." +
+		" Alternatively, this can be set with the following environment variable: " + inputEnvKey
+)
+
+func newRestoreCmd(opener databaseOpener) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Restores all Orb stores from a backup archive.",
+		Long: `Restores all Orb stores from a backup archive (created with the backup command) to an empty database. ` +
+			`The indexes of each store are rebuilt and the number of entries in each store is verified against the ` +
+			`manifest. Anchor synchronization resumes from the point at which the snapshot was taken when the Orb ` +
+			`server is started. The Orb server must be stopped during the restore. For example: db restore ` +
+			`--to mongodb://localhost:27017 --input orb-backup.tar.gz`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeRestore(cmd, opener)
+		},
+	}
+
+	cmd.Flags().StringP(toFlagName, "", "", toFlagUsage)
+	cmd.Flags().StringP(toDatabasePrefixFlagName, "", "", toDatabasePrefixFlagUsage)
+	cmd.Flags().StringP(inputFlagName, "", "", inputFlagUsage)
+	cmd.Flags().StringArrayP(namespaceFlagName, "", nil, namespaceFlagUsage)
+	cmd.Flags().StringP(batchSizeFlagName, "", "", batchSizeFlagUsage)
+
+	return cmd
+}
+
+func executeRestore(cmd *cobra.Command, opener databaseOpener) error {
+	to, err := cmdutil.GetUserSetVarFromString(cmd, toFlagName, toEnvKey, false)
+	if err != nil {
+		return err
+	}
+
+	input, err := cmdutil.GetUserSetVarFromString(cmd, inputFlagName, inputEnvKey, false)
+	if err != nil {
+		return err
+	}
+
+	opts, err := getRestoreOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(filepath.Clean(input))
+	if err != nil {
+		return fmt.Errorf("open backup file: %w", err)
+	}
+
+	defer func() {
+		if err := f.Close(); err != nil {
+			logger.Warn("Error closing backup file", log.WithError(err))
+		}
+	}()
+
+	target, err := opener.Open(to, cmdutil.GetUserSetOptionalVarFromString(cmd, toDatabasePrefixFlagName,
+		toDatabasePrefixEnvKey))
+	if err != nil {
+		return fmt.Errorf("open target database: %w", err)
+	}
+
+	defer closeDatabase(target)
+
+	report, err := backup.Restore(f, target, opts...)
+	if err != nil {
+		if errors.Is(err, backup.ErrVerificationFailed) {
+			if e := printJSON(cmd, report); e != nil {
+				logger.Warn("Error printing report", log.WithError(e))
+			}
+		}
+
+		return fmt.Errorf("restore: %w", err)
+	}
+
+	return printJSON(cmd, report)
+}
+
+func getRestoreOptions(cmd *cobra.Command) ([]backup.Option, error) {
+	namespaces, err := getNamespaces(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []backup.Option{backup.WithNamespaces(namespaces...)}
+
+	batchSizeStr := cmdutil.GetUserSetOptionalVarFromString(cmd, batchSizeFlagName, batchSizeEnvKey)
+	if batchSizeStr != "" {
+		batchSize, err := strconv.Atoi(batchSizeStr)
+		if err != nil || batchSize <= 0 {
+			return nil, fmt.Errorf("invalid value for %s: %s", batchSizeFlagName, batchSizeStr)
+		}
+
+		opts = append(opts, backup.WithBatchSize(batchSize))
+	}
+
+	return opts, nil
+}
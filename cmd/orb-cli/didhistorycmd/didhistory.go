@@ -0,0 +1,80 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didhistorycmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/trustbloc/orb/cmd/orb-cli/common"
+	"github.com/trustbloc/orb/internal/pkg/cmdutil"
+)
+
+const (
+	urlFlagName  = "url"
+	urlFlagUsage = "The URL of the DID identifiers REST endpoint, e.g. https://orb.domain1.com/sidetree/v1/identifiers." +
+		" Alternatively, this can be set with the following environment variable: " + urlEnvKey
+	urlEnvKey = "ORB_CLI_URL"
+
+	didURIFlagName  = "did-uri"
+	didURIFlagUsage = "The DID for which to retrieve the history." +
+		" Alternatively, this can be set with the following environment variable: " + didURIEnvKey
+	didURIEnvKey = "ORB_CLI_DID_URI"
+)
+
+// GetDIDHistoryCmd returns the Cobra DID history command.
+func GetDIDHistoryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Retrieves the operation history of a DID.",
+		Long: "Retrieves the published operations of a DID, in the order in which they were anchored, along with " +
+			"the anchor hashlink, anchor origin, witness proofs and VCT log entries of each operation. For example: " +
+			"history --url https://orb.domain1.com/sidetree/v1/identifiers --did-uri did:orb:uAAA:EiDJpL...",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeGet(cmd)
+		},
+	}
+
+	common.AddCommonFlags(cmd)
+
+	cmd.Flags().StringP(urlFlagName, "", "", urlFlagUsage)
+	cmd.Flags().StringP(didURIFlagName, "", "", didURIFlagUsage)
+
+	return cmd
+}
+
+func executeGet(cmd *cobra.Command) error {
+	u, err := cmdutil.GetUserSetVarFromString(cmd, urlFlagName, urlEnvKey, false)
+	if err != nil {
+		return err
+	}
+
+	_, err = url.Parse(u)
+	if err != nil {
+		return fmt.Errorf("invalid URL %s: %w", u, err)
+	}
+
+	did, err := cmdutil.GetUserSetVarFromString(cmd, didURIFlagName, didURIEnvKey, false)
+	if err != nil {
+		return err
+	}
+
+	resp, err := common.SendHTTPRequest(cmd, nil, http.MethodGet,
+		fmt.Sprintf("%s/%s/history", strings.TrimSuffix(u, "/"), url.PathEscape(did)))
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(resp))
+
+	return nil
+}
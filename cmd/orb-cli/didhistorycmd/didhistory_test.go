@@ -0,0 +1,106 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didhistorycmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	flag    = "--"
+	testDID = "did:orb:uAAA:EiDJpL-xeSE4kVgoGjaQm_OX5y5Dy3Nfr1Ld7cdMZwqb0A"
+)
+
+func TestDIDHistoryCmd(t *testing.T) {
+	t.Run("test missing url arg", func(t *testing.T) {
+		cmd := GetDIDHistoryCmd()
+		cmd.SetArgs(didURIArg(testDID))
+
+		err := cmd.Execute()
+
+		require.Error(t, err)
+		require.Equal(t,
+			"Neither url (command line flag) nor ORB_CLI_URL (environment variable) have been set.",
+			err.Error())
+	})
+
+	t.Run("test invalid url arg", func(t *testing.T) {
+		cmd := GetDIDHistoryCmd()
+
+		args := urlArg(":invalid")
+		args = append(args, didURIArg(testDID)...)
+		cmd.SetArgs(args)
+
+		err := cmd.Execute()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid URL")
+	})
+
+	t.Run("test missing did-uri arg", func(t *testing.T) {
+		cmd := GetDIDHistoryCmd()
+		cmd.SetArgs(urlArg("https://orb.domain1.com/sidetree/v1/identifiers"))
+
+		err := cmd.Execute()
+
+		require.Error(t, err)
+		require.Equal(t,
+			"Neither did-uri (command line flag) nor ORB_CLI_DID_URI (environment variable) have been set.",
+			err.Error())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodGet, r.Method)
+			require.Equal(t, "/sidetree/v1/identifiers/"+testDID+"/history", r.URL.Path)
+
+			_, err := fmt.Fprintf(w, `{"id":"%s","operations":[]}`, testDID)
+			require.NoError(t, err)
+		}))
+		defer serv.Close()
+
+		cmd := GetDIDHistoryCmd()
+
+		args := urlArg(serv.URL + "/sidetree/v1/identifiers/")
+		args = append(args, didURIArg(testDID)...)
+		cmd.SetArgs(args)
+
+		err := cmd.Execute()
+
+		require.NoError(t, err)
+	})
+
+	t.Run("error - not found", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer serv.Close()
+
+		cmd := GetDIDHistoryCmd()
+
+		args := urlArg(serv.URL)
+		args = append(args, didURIArg(testDID)...)
+		cmd.SetArgs(args)
+
+		err := cmd.Execute()
+
+		require.Error(t, err)
+	})
+}
+
+func urlArg(value string) []string {
+	return []string{flag + urlFlagName, value}
+}
+
+func didURIArg(value string) []string {
+	return []string{flag + didURIFlagName, value}
+}
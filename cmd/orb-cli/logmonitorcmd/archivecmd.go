@@ -0,0 +1,177 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package logmonitorcmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/spf13/cobra"
+
+	"github.com/trustbloc/orb/cmd/orb-cli/common"
+	"github.com/trustbloc/orb/internal/pkg/cmdutil"
+)
+
+const (
+	archiveURLFlagUsage = "The URL of the log monitor archives REST endpoint." +
+		" Alternatively, this can be set with the following environment variable: " + urlEnvKey
+
+	archiveLogFlagUsage = "The URI of the log." +
+		" Alternatively, this can be set with the following environment variable: " + logsEnvKey
+
+	locationFlagName  = "location"
+	locationFlagUsage = "The location of the archive to import (as returned by the archive list command)." +
+		" Alternatively, this can be set with the following environment variable: " + locationEnvKey
+	locationEnvKey = "ORB_CLI_ARCHIVE_LOCATION"
+
+	importedEntriesPath = "/entries"
+)
+
+func newArchiveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "archive",
+		Short:        "Manages archives of compacted log entries.",
+		Long:         "Lists the archives of compacted log entries and imports archived entries back into the store.",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return errors.New("expecting subcommand list, import, or entries")
+		},
+	}
+
+	cmd.AddCommand(
+		newArchiveListCmd(),
+		newArchiveImportCmd(),
+		newArchiveEntriesCmd(),
+	)
+
+	return cmd
+}
+
+func newArchiveListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "list",
+		Short:        "Lists the archives of compacted log entries for a log.",
+		Long:         "Lists the archives of compacted log entries for a log.",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeArchiveGet(cmd, "")
+		},
+	}
+
+	addArchiveFlags(cmd)
+	cmd.Flags().StringP(logFlagName, "", "", archiveLogFlagUsage)
+
+	return cmd
+}
+
+func newArchiveEntriesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "entries",
+		Short:        "Lists the log entries that were imported from archives for a log.",
+		Long:         "Lists the log entries that were imported from archives for a log.",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeArchiveGet(cmd, importedEntriesPath)
+		},
+	}
+
+	addArchiveFlags(cmd)
+	cmd.Flags().StringP(logFlagName, "", "", archiveLogFlagUsage)
+
+	return cmd
+}
+
+func newArchiveImportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "import",
+		Short:        "Imports the entries of an archive into the log entry store.",
+		Long:         "Imports the entries of an archive into the log entry store (for audits).",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeArchiveImport(cmd)
+		},
+	}
+
+	addArchiveFlags(cmd)
+	cmd.Flags().StringP(locationFlagName, "", "", locationFlagUsage)
+
+	return cmd
+}
+
+func addArchiveFlags(cmd *cobra.Command) {
+	common.AddCommonFlags(cmd)
+	cmd.Flags().StringP(urlFlagName, "", "", archiveURLFlagUsage)
+}
+
+func executeArchiveGet(cmd *cobra.Command, path string) error {
+	u, err := getArchiveURL(cmd)
+	if err != nil {
+		return err
+	}
+
+	logURL, err := cmdutil.GetUserSetVarFromString(cmd, logFlagName, logsEnvKey, false)
+	if err != nil {
+		return err
+	}
+
+	resp, err := common.SendHTTPRequest(cmd, nil, http.MethodGet,
+		fmt.Sprintf("%s%s?log=%s", u, path, url.QueryEscape(logURL)))
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(resp))
+
+	return nil
+}
+
+func executeArchiveImport(cmd *cobra.Command) error {
+	u, err := getArchiveURL(cmd)
+	if err != nil {
+		return err
+	}
+
+	location, err := cmdutil.GetUserSetVarFromString(cmd, locationFlagName, locationEnvKey, false)
+	if err != nil {
+		return err
+	}
+
+	reqBytes, err := json.Marshal(&importArchiveRequest{Location: location})
+	if err != nil {
+		return err
+	}
+
+	_, err = common.SendHTTPRequest(cmd, reqBytes, http.MethodPost, u)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("archive successfully imported.")
+
+	return nil
+}
+
+func getArchiveURL(cmd *cobra.Command) (string, error) {
+	u, err := cmdutil.GetUserSetVarFromString(cmd, urlFlagName, urlEnvKey, false)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = url.Parse(u)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %s: %w", u, err)
+	}
+
+	return u, nil
+}
+
+type importArchiveRequest struct {
+	Location string `json:"location"`
+}
@@ -0,0 +1,197 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package logmonitorcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchiveCmd(t *testing.T) {
+	t.Run("test missing subcommand", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{"archive"})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "expecting subcommand list, import, or entries")
+	})
+}
+
+func TestArchiveListCmd(t *testing.T) {
+	t.Run("test missing url arg", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{"archive", "list"})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Equal(t,
+			"Neither url (command line flag) nor ORB_CLI_URL (environment variable) have been set.",
+			err.Error())
+	})
+
+	t.Run("test invalid url arg", func(t *testing.T) {
+		cmd := GetCmd()
+
+		args := []string{"archive", "list"}
+		args = append(args, urlArg(":invalid")...)
+		cmd.SetArgs(args)
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid URL")
+	})
+
+	t.Run("test missing log arg", func(t *testing.T) {
+		cmd := GetCmd()
+
+		args := []string{"archive", "list"}
+		args = append(args, urlArg("localhost:8080")...)
+		cmd.SetArgs(args)
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Equal(t,
+			"Neither log (command line flag) nor ORB_CLI_LOG (environment variable) have been set.",
+			err.Error())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/log-monitor/archives", r.URL.Path)
+			require.Equal(t, testLog, r.URL.Query().Get("log"))
+
+			_, err := fmt.Fprint(w, "[]")
+			require.NoError(t, err)
+		}))
+		defer serv.Close()
+
+		cmd := GetCmd()
+
+		args := []string{"archive", "list"}
+		args = append(args, urlArg(serv.URL+"/log-monitor/archives")...)
+		args = append(args, logArg(testLog)...)
+		cmd.SetArgs(args)
+
+		err := cmd.Execute()
+		require.NoError(t, err)
+	})
+}
+
+func TestArchiveEntriesCmd(t *testing.T) {
+	t.Run("test missing log arg", func(t *testing.T) {
+		cmd := GetCmd()
+
+		args := []string{"archive", "entries"}
+		args = append(args, urlArg("localhost:8080")...)
+		cmd.SetArgs(args)
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Equal(t,
+			"Neither log (command line flag) nor ORB_CLI_LOG (environment variable) have been set.",
+			err.Error())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/log-monitor/archives/entries", r.URL.Path)
+			require.Equal(t, testLog, r.URL.Query().Get("log"))
+
+			_, err := fmt.Fprint(w, "[]")
+			require.NoError(t, err)
+		}))
+		defer serv.Close()
+
+		cmd := GetCmd()
+
+		args := []string{"archive", "entries"}
+		args = append(args, urlArg(serv.URL+"/log-monitor/archives")...)
+		args = append(args, logArg(testLog)...)
+		cmd.SetArgs(args)
+
+		err := cmd.Execute()
+		require.NoError(t, err)
+	})
+}
+
+func TestArchiveImportCmd(t *testing.T) {
+	t.Run("test missing url arg", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{"archive", "import"})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Equal(t,
+			"Neither url (command line flag) nor ORB_CLI_URL (environment variable) have been set.",
+			err.Error())
+	})
+
+	t.Run("test missing location arg", func(t *testing.T) {
+		cmd := GetCmd()
+
+		args := []string{"archive", "import"}
+		args = append(args, urlArg("localhost:8080")...)
+		cmd.SetArgs(args)
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Equal(t,
+			"Neither location (command line flag) nor ORB_CLI_ARCHIVE_LOCATION (environment variable) have been set.",
+			err.Error())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodPost, r.Method)
+
+			reqBytes, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+
+			req := &importArchiveRequest{}
+			require.NoError(t, json.Unmarshal(reqBytes, req))
+			require.Equal(t, "archive-1", req.Location)
+		}))
+		defer serv.Close()
+
+		cmd := GetCmd()
+
+		args := []string{"archive", "import"}
+		args = append(args, urlArg(serv.URL+"/log-monitor/archives")...)
+		args = append(args, locationArg("archive-1")...)
+		cmd.SetArgs(args)
+
+		err := cmd.Execute()
+		require.NoError(t, err)
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer serv.Close()
+
+		cmd := GetCmd()
+
+		args := []string{"archive", "import"}
+		args = append(args, urlArg(serv.URL)...)
+		args = append(args, locationArg("archive-1")...)
+		cmd.SetArgs(args)
+
+		err := cmd.Execute()
+		require.Error(t, err)
+	})
+}
+
+func locationArg(value string) []string {
+	return []string{flag + locationFlagName, value}
+}
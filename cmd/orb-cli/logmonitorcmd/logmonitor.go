@@ -37,7 +37,7 @@ func GetCmd() *cobra.Command {
 		Long:         "Manages activating/deactivating logs for monitoring.",
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return errors.New("expecting subcommand activate, deactivate, or get")
+			return errors.New("expecting subcommand activate, deactivate, get, or archive")
 		},
 	}
 
@@ -45,6 +45,7 @@ func GetCmd() *cobra.Command {
 		newActivateCmd(),
 		newDeactivateCmd(),
 		newGetCmd(),
+		newArchiveCmd(),
 	)
 
 	return cmd
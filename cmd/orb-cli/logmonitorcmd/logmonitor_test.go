@@ -16,6 +16,6 @@ func TestLogMonitorCmd(t *testing.T) {
 	t.Run("test missing subcommand", func(t *testing.T) {
 		err := GetCmd().Execute()
 		require.Error(t, err)
-		require.Contains(t, err.Error(), "expecting subcommand activate, deactivate, or get")
+		require.Contains(t, err.Error(), "expecting subcommand activate, deactivate, get, or archive")
 	})
 }
@@ -11,16 +11,22 @@ import (
 
 	"github.com/trustbloc/orb/cmd/orb-cli/acceptlistcmd"
 	"github.com/trustbloc/orb/cmd/orb-cli/allowedoriginscmd"
+	"github.com/trustbloc/orb/cmd/orb-cli/cascmd"
 	"github.com/trustbloc/orb/cmd/orb-cli/createdidcmd"
+	"github.com/trustbloc/orb/cmd/orb-cli/dbcmd"
 	"github.com/trustbloc/orb/cmd/orb-cli/deactivatedidcmd"
+	"github.com/trustbloc/orb/cmd/orb-cli/didhistorycmd"
 	"github.com/trustbloc/orb/cmd/orb-cli/followcmd"
 	"github.com/trustbloc/orb/cmd/orb-cli/ipfskeygencmd"
 	"github.com/trustbloc/orb/cmd/orb-cli/ipnshostmetagencmd"
 	"github.com/trustbloc/orb/cmd/orb-cli/ipnshostmetauploadcmd"
 	"github.com/trustbloc/orb/cmd/orb-cli/logcmd"
 	"github.com/trustbloc/orb/cmd/orb-cli/logmonitorcmd"
+	"github.com/trustbloc/orb/cmd/orb-cli/mqcmd"
 	"github.com/trustbloc/orb/cmd/orb-cli/policycmd"
+	"github.com/trustbloc/orb/cmd/orb-cli/proofmonitorcmd"
 	"github.com/trustbloc/orb/cmd/orb-cli/recoverdidcmd"
+	"github.com/trustbloc/orb/cmd/orb-cli/reindexcmd"
 	"github.com/trustbloc/orb/cmd/orb-cli/resolvedidcmd"
 	"github.com/trustbloc/orb/cmd/orb-cli/updatedidcmd"
 	"github.com/trustbloc/orb/cmd/orb-cli/vctcmd"
@@ -61,6 +67,7 @@ func main() {
 	didCmd.AddCommand(recoverdidcmd.GetRecoverDIDCmd())
 	didCmd.AddCommand(deactivatedidcmd.GetDeactivateDIDCmd())
 	didCmd.AddCommand(resolvedidcmd.GetResolveDIDCmd())
+	didCmd.AddCommand(didhistorycmd.GetDIDHistoryCmd())
 
 	rootCmd.AddCommand(didCmd)
 	rootCmd.AddCommand(ipfsCmd)
@@ -71,11 +78,20 @@ func main() {
 
 	rootCmd.AddCommand(logmonitorcmd.GetCmd())
 	rootCmd.AddCommand(logcmd.GetCmd())
+	rootCmd.AddCommand(proofmonitorcmd.GetCmd())
 
 	rootCmd.AddCommand(vctcmd.GetCmd())
 
 	rootCmd.AddCommand(allowedoriginscmd.GetCmd())
 
+	rootCmd.AddCommand(cascmd.GetCmd())
+
+	rootCmd.AddCommand(dbcmd.GetCmd())
+
+	rootCmd.AddCommand(reindexcmd.GetCmd())
+
+	rootCmd.AddCommand(mqcmd.GetCmd())
+
 	if err := rootCmd.Execute(); err != nil {
 		logger.Fatal("Failed to run orb-cli", log.WithError(err))
 	}
@@ -0,0 +1,30 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mqcmd
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+)
+
+// GetCmd returns the Cobra message queue command.
+func GetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "mq",
+		Short:        "Manages the message queue of an Orb server.",
+		Long:         "Manages the message queue of an Orb server.",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return errors.New("expecting subcommand undeliverable")
+		},
+	}
+
+	cmd.AddCommand(newUndeliverableCmd())
+
+	return cmd
+}
@@ -0,0 +1,237 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mqcmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	flag = "--"
+
+	undeliverablePath = "/mq/undeliverable"
+	topic             = "orb.operation"
+)
+
+func TestMQCmd(t *testing.T) {
+	cmd := GetCmd()
+
+	err := cmd.Execute()
+	require.EqualError(t, err, "expecting subcommand undeliverable")
+
+	cmd = GetCmd()
+	cmd.SetArgs([]string{"undeliverable"})
+
+	err = cmd.Execute()
+	require.EqualError(t, err, "expecting subcommand list, requeue or drop")
+}
+
+func TestListCmd(t *testing.T) {
+	t.Run("Missing url", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{"undeliverable", "list"})
+
+		err := cmd.Execute()
+		require.EqualError(t, err,
+			"Neither url (command line flag) nor ORB_CLI_URL (environment variable) have been set.")
+	})
+
+	t.Run("Invalid url", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{"undeliverable", "list", flag + urlFlagName, ":invalid"})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid URL")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		serv := newMockServer()
+		defer serv.Close()
+
+		cmd := GetCmd()
+
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+		cmd.SetArgs([]string{
+			"undeliverable", "list", flag + urlFlagName, serv.URL + undeliverablePath, flag + topicFlagName, topic,
+		})
+
+		require.NoError(t, cmd.Execute())
+		require.Contains(t, out.String(), `"failureCount":3`)
+
+		path, query, _ := serv.lastRequest()
+		require.Equal(t, undeliverablePath, path)
+		require.Equal(t, "topic="+topic, query)
+	})
+
+	t.Run("Server error", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer serv.Close()
+
+		cmd := GetCmd()
+		cmd.SetArgs([]string{"undeliverable", "list", flag + urlFlagName, serv.URL + undeliverablePath})
+
+		require.Error(t, cmd.Execute())
+	})
+}
+
+func TestUpdateCmd(t *testing.T) {
+	t.Run("Requeue by ID", func(t *testing.T) {
+		serv := newMockServer()
+		defer serv.Close()
+
+		cmd := GetCmd()
+
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+		cmd.SetArgs([]string{
+			"undeliverable", "requeue", flag + urlFlagName, serv.URL + undeliverablePath,
+			flag + idFlagName, "id1", flag + idFlagName, "id2",
+		})
+
+		require.NoError(t, cmd.Execute())
+		require.Contains(t, out.String(), `"ids"`)
+
+		path, _, body := serv.lastRequest()
+		require.Equal(t, undeliverablePath+requeuePath, path)
+
+		request := &updateRequest{}
+		require.NoError(t, json.Unmarshal([]byte(body), request))
+		require.Equal(t, []string{"id1", "id2"}, request.IDs)
+		require.False(t, request.All)
+	})
+
+	t.Run("Drop all of topic", func(t *testing.T) {
+		serv := newMockServer()
+		defer serv.Close()
+
+		cmd := GetCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetArgs([]string{
+			"undeliverable", "drop", flag + urlFlagName, serv.URL + undeliverablePath + "/",
+			flag + allFlagName, "true", flag + topicFlagName, topic,
+		})
+
+		require.NoError(t, cmd.Execute())
+
+		path, _, body := serv.lastRequest()
+		require.Equal(t, undeliverablePath+dropPath, path)
+
+		request := &updateRequest{}
+		require.NoError(t, json.Unmarshal([]byte(body), request))
+		require.Empty(t, request.IDs)
+		require.True(t, request.All)
+		require.Equal(t, topic, request.Topic)
+	})
+
+	t.Run("Missing url", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{"undeliverable", "requeue", flag + allFlagName, "true"})
+
+		err := cmd.Execute()
+		require.EqualError(t, err,
+			"Neither url (command line flag) nor ORB_CLI_URL (environment variable) have been set.")
+	})
+
+	t.Run("Invalid all", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{
+			"undeliverable", "requeue", flag + urlFlagName, "https://orb.domain1.com" + undeliverablePath,
+			flag + allFlagName, "xxx",
+		})
+
+		err := cmd.Execute()
+		require.EqualError(t, err, "invalid value for all: xxx")
+	})
+
+	t.Run("Neither ID nor all", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{
+			"undeliverable", "drop", flag + urlFlagName, "https://orb.domain1.com" + undeliverablePath,
+		})
+
+		err := cmd.Execute()
+		require.EqualError(t, err, "either id or all must be specified")
+	})
+
+	t.Run("Both ID and all", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{
+			"undeliverable", "drop", flag + urlFlagName, "https://orb.domain1.com" + undeliverablePath,
+			flag + idFlagName, "id1", flag + allFlagName, "true",
+		})
+
+		err := cmd.Execute()
+		require.EqualError(t, err, "id and all are mutually exclusive")
+	})
+
+	t.Run("Server error", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer serv.Close()
+
+		cmd := GetCmd()
+		cmd.SetArgs([]string{
+			"undeliverable", "requeue", flag + urlFlagName, serv.URL + undeliverablePath, flag + allFlagName, "true",
+		})
+
+		require.Error(t, cmd.Execute())
+	})
+}
+
+type mockServer struct {
+	*httptest.Server
+
+	mutex sync.Mutex
+	path  string
+	query string
+	body  string
+}
+
+func newMockServer() *mockServer {
+	s := &mockServer{}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body) //nolint:errcheck
+
+		s.mutex.Lock()
+		s.path = r.URL.Path
+		s.query = r.URL.RawQuery
+		s.body = string(body)
+		s.mutex.Unlock()
+
+		if r.Method == http.MethodGet {
+			fmt.Fprint(w, `[{"id":"id1","topic":"orb.operation","failureCount":3}]`)
+
+			return
+		}
+
+		fmt.Fprint(w, `{"ids":["id1"]}`)
+	}))
+
+	return s
+}
+
+func (s *mockServer) lastRequest() (path, query, body string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.path, s.query, s.body
+}
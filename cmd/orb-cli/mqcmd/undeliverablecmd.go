@@ -0,0 +1,218 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mqcmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/trustbloc/orb/cmd/orb-cli/common"
+	"github.com/trustbloc/orb/internal/pkg/cmdutil"
+)
+
+const (
+	urlFlagName  = "url"
+	urlFlagUsage = "The URL of the undeliverable messages REST endpoint, for example " +
+		"https://orb.domain1.com/mq/undeliverable." +
+		" Alternatively, this can be set with the following environment variable: " + urlEnvKey
+	urlEnvKey = "ORB_CLI_URL"
+
+	topicFlagName  = "topic"
+	topicEnvKey    = "ORB_CLI_TOPIC"
+	topicFlagUsage = "The topic to which the messages were originally published, for example orb.operation." +
+		" If specified then only the messages of the given topic are processed." +
+		" Alternatively, this can be set with the following environment variable: " + topicEnvKey
+
+	idFlagName  = "id"
+	idEnvKey    = "ORB_CLI_ID"
+	idFlagUsage = "The ID of a message. This flag may be repeated." +
+		" Alternatively, this can be set with the following environment variable (comma-separated): " + idEnvKey
+
+	allFlagName  = "all"
+	allEnvKey    = "ORB_CLI_ALL"
+	allFlagUsage = "If true then all messages (of the given topic, if specified) are processed." +
+		" Alternatively, this can be set with the following environment variable: " + allEnvKey
+)
+
+const (
+	requeuePath = "/requeue"
+	dropPath    = "/drop"
+)
+
+func newUndeliverableCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "undeliverable",
+		Short: "Manages messages which could not be delivered after the maximum number of attempts.",
+		Long: "Manages messages which could not be delivered after the maximum number of attempts. Undeliverable " +
+			"messages may be listed and then either requeued to their original topic or dropped.",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return errors.New("expecting subcommand list, requeue or drop")
+		},
+	}
+
+	cmd.AddCommand(
+		newListCmd(),
+		newUpdateCmd("requeue", requeuePath,
+			"Requeues undeliverable messages to the topic to which they were originally published.",
+			"Requeues undeliverable messages to the topic to which they were originally published. For example: "+
+				"mq undeliverable requeue --url https://orb.domain1.com/mq/undeliverable --topic orb.operation "+
+				"--all true --auth-token ADMIN_TOKEN",
+		),
+		newUpdateCmd("drop", dropPath,
+			"Drops undeliverable messages.",
+			"Permanently deletes undeliverable messages. For example: "+
+				"mq undeliverable drop --url https://orb.domain1.com/mq/undeliverable --id MESSAGE_ID "+
+				"--auth-token ADMIN_TOKEN",
+		),
+	)
+
+	return cmd
+}
+
+func newListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Lists undeliverable messages.",
+		Long: "Lists undeliverable messages along with their original topic and failure count. For example: " +
+			"mq undeliverable list --url https://orb.domain1.com/mq/undeliverable --topic orb.operation " +
+			"--auth-token ADMIN_TOKEN",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeList(cmd)
+		},
+	}
+
+	common.AddCommonFlags(cmd)
+
+	cmd.Flags().StringP(urlFlagName, "", "", urlFlagUsage)
+	cmd.Flags().StringP(topicFlagName, "", "", topicFlagUsage)
+
+	return cmd
+}
+
+func newUpdateCmd(use, path, short, long string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          use,
+		Short:        short,
+		Long:         long,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeUpdate(cmd, path)
+		},
+	}
+
+	common.AddCommonFlags(cmd)
+
+	cmd.Flags().StringP(urlFlagName, "", "", urlFlagUsage)
+	cmd.Flags().StringP(topicFlagName, "", "", topicFlagUsage)
+	cmd.Flags().StringArrayP(idFlagName, "", nil, idFlagUsage)
+	cmd.Flags().StringP(allFlagName, "", "", allFlagUsage)
+
+	return cmd
+}
+
+func executeList(cmd *cobra.Command) error {
+	u, err := getURL(cmd)
+	if err != nil {
+		return err
+	}
+
+	if topic := cmdutil.GetUserSetOptionalVarFromString(cmd, topicFlagName, topicEnvKey); topic != "" {
+		u = fmt.Sprintf("%s?%s=%s", u, topicFlagName, url.QueryEscape(topic))
+	}
+
+	resp, err := common.SendHTTPRequest(cmd, nil, http.MethodGet, u)
+	if err != nil {
+		return err
+	}
+
+	common.Println(cmd.OutOrStdout(), string(resp))
+
+	return nil
+}
+
+func executeUpdate(cmd *cobra.Command, path string) error {
+	u, err := getURL(cmd)
+	if err != nil {
+		return err
+	}
+
+	all, err := getBool(cmd, allFlagName, allEnvKey)
+	if err != nil {
+		return err
+	}
+
+	ids := cmdutil.GetUserSetOptionalVarFromArrayString(cmd, idFlagName, idEnvKey)
+
+	if len(ids) == 0 && !all {
+		return fmt.Errorf("either %s or %s must be specified", idFlagName, allFlagName)
+	}
+
+	if len(ids) > 0 && all {
+		return fmt.Errorf("%s and %s are mutually exclusive", idFlagName, allFlagName)
+	}
+
+	reqBytes, err := json.Marshal(&updateRequest{
+		IDs:   ids,
+		All:   all,
+		Topic: cmdutil.GetUserSetOptionalVarFromString(cmd, topicFlagName, topicEnvKey),
+	})
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+
+	resp, err := common.SendHTTPRequest(cmd, reqBytes, http.MethodPost, strings.TrimSuffix(u, "/")+path)
+	if err != nil {
+		return err
+	}
+
+	common.Println(cmd.OutOrStdout(), string(resp))
+
+	return nil
+}
+
+func getURL(cmd *cobra.Command) (string, error) {
+	u, err := cmdutil.GetUserSetVarFromString(cmd, urlFlagName, urlEnvKey, false)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = url.Parse(u)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %s: %w", u, err)
+	}
+
+	return u, nil
+}
+
+func getBool(cmd *cobra.Command, flagName, envKey string) (bool, error) {
+	value := cmdutil.GetUserSetOptionalVarFromString(cmd, flagName, envKey)
+	if value == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value for %s: %s", flagName, value)
+	}
+
+	return b, nil
+}
+
+type updateRequest struct {
+	IDs   []string `json:"ids,omitempty"`
+	All   bool     `json:"all,omitempty"`
+	Topic string   `json:"topic,omitempty"`
+}
@@ -0,0 +1,45 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package proofmonitorcmd
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	urlFlagName  = "url"
+	urlFlagUsage = "The URL of the proof monitor REST endpoint." +
+		" Alternatively, this can be set with the following environment variable: " + urlEnvKey
+	urlEnvKey = "ORB_CLI_URL"
+
+	statusFlagName  = "status"
+	statusFlagUsage = "Filter watched credentials by status: pending, overdue or failed." +
+		" If not set then credentials with any status are returned." +
+		" Alternatively, this can be set with the following environment variable: " + statusEnvKey
+	statusEnvKey = "ORB_CLI_STATUS"
+)
+
+// GetCmd returns the Cobra proofmonitor command.
+func GetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "proofmonitor",
+		Short:        "Retrieves the status of credentials waiting to be included in a VCT log.",
+		Long:         "Retrieves the status of credentials waiting to be included in a VCT log.",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return errors.New("expecting subcommand get")
+		},
+	}
+
+	cmd.AddCommand(
+		newGetCmd(),
+	)
+
+	return cmd
+}
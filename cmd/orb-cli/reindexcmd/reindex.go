@@ -0,0 +1,41 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package reindexcmd
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	urlFlagName  = "url"
+	urlFlagUsage = "The URL of the reindex REST endpoint, for example https://orb.domain1.com/reindex." +
+		" Alternatively, this can be set with the following environment variable: " + urlEnvKey
+	urlEnvKey = "ORB_CLI_URL"
+)
+
+// GetCmd returns the Cobra reindex command.
+func GetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reindex",
+		Short: "Rebuilds the DID state of an Orb server from the local anchor graph.",
+		Long: "Rebuilds the operation store and DID anchor store of an Orb server by walking the local anchor " +
+			"graph from the latest anchors and replaying each anchor. Divergences from the existing data are reported.",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return errors.New("expecting subcommand start or status")
+		},
+	}
+
+	cmd.AddCommand(
+		newStartCmd(),
+		newStatusCmd(),
+	)
+
+	return cmd
+}
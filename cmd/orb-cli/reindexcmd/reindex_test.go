@@ -0,0 +1,237 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package reindexcmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/anchor/reindex"
+)
+
+const (
+	flag = "--"
+
+	anchorHL = "hl:uEiBGozN2uP1HBNNZtL-oeg2ifE0NuKY8Bg3miVMJtVZvYQ:uoQ-BeEtodHRwczovL29yYi5kb21haW4xLmNvbS9jYXM"
+)
+
+func TestReindexCmd(t *testing.T) {
+	cmd := GetCmd()
+
+	err := cmd.Execute()
+	require.EqualError(t, err, "expecting subcommand start or status")
+}
+
+func TestStartCmd(t *testing.T) {
+	t.Run("Missing url", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{"start"})
+
+		err := cmd.Execute()
+		require.EqualError(t, err,
+			"Neither url (command line flag) nor ORB_CLI_URL (environment variable) have been set.")
+	})
+
+	t.Run("Invalid url", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{"start", flag + urlFlagName, ":invalid"})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid URL")
+	})
+
+	t.Run("Invalid dry-run", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{"start", flag + urlFlagName, "https://orb.domain1.com/reindex", flag + dryRunFlagName, "xxx"})
+
+		err := cmd.Execute()
+		require.EqualError(t, err, "invalid value for dry-run: xxx")
+	})
+
+	t.Run("Invalid wait", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{"start", flag + urlFlagName, "https://orb.domain1.com/reindex", flag + waitFlagName, "xxx"})
+
+		err := cmd.Execute()
+		require.EqualError(t, err, "invalid value for wait: xxx")
+	})
+
+	t.Run("Invalid poll interval", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{
+			"start", flag + urlFlagName, "https://orb.domain1.com/reindex", flag + pollIntervalFlagName, "xxx",
+		})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid value [xxx]")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		serv := newMockReindexServer(0, reindex.StateCompleted)
+		defer serv.Close()
+
+		cmd := GetCmd()
+
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+		cmd.SetArgs([]string{
+			"start", flag + urlFlagName, serv.URL + "/reindex", flag + anchorFlagName, anchorHL,
+			flag + dryRunFlagName, "true",
+		})
+
+		require.NoError(t, cmd.Execute())
+		require.Contains(t, out.String(), `"state":"running"`)
+
+		request := &startRequest{}
+		require.NoError(t, json.Unmarshal([]byte(serv.startRequest()), request))
+		require.Equal(t, []string{anchorHL}, request.Anchors)
+		require.True(t, request.DryRun)
+	})
+
+	t.Run("Wait for completion", func(t *testing.T) {
+		serv := newMockReindexServer(2, reindex.StateCompleted)
+		defer serv.Close()
+
+		cmd := GetCmd()
+
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+		cmd.SetArgs([]string{
+			"start", flag + urlFlagName, serv.URL + "/reindex", flag + waitFlagName, "true",
+			flag + pollIntervalFlagName, "10ms",
+		})
+
+		require.NoError(t, cmd.Execute())
+
+		status := &reindex.Status{}
+		require.NoError(t, json.Unmarshal(out.Bytes(), status))
+		require.Equal(t, reindex.StateCompleted, status.State)
+		require.Equal(t, 3, status.Report.Anchors)
+	})
+
+	t.Run("Reindex failed", func(t *testing.T) {
+		serv := newMockReindexServer(0, reindex.StateFailed)
+		defer serv.Close()
+
+		cmd := GetCmd()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetArgs([]string{"start", flag + urlFlagName, serv.URL + "/reindex", flag + waitFlagName, "true"})
+
+		err := cmd.Execute()
+		require.EqualError(t, err, "reindex failed: injected reindex error")
+	})
+
+	t.Run("Server error", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusConflict)
+		}))
+		defer serv.Close()
+
+		cmd := GetCmd()
+		cmd.SetArgs([]string{"start", flag + urlFlagName, serv.URL + "/reindex"})
+
+		require.Error(t, cmd.Execute())
+	})
+}
+
+func TestStatusCmd(t *testing.T) {
+	t.Run("Missing url", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{"status"})
+
+		err := cmd.Execute()
+		require.EqualError(t, err,
+			"Neither url (command line flag) nor ORB_CLI_URL (environment variable) have been set.")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		serv := newMockReindexServer(0, reindex.StateCompleted)
+		defer serv.Close()
+
+		cmd := GetCmd()
+
+		out := &bytes.Buffer{}
+		cmd.SetOut(out)
+		cmd.SetArgs([]string{"status", flag + urlFlagName, serv.URL + "/reindex"})
+
+		require.NoError(t, cmd.Execute())
+		require.Contains(t, out.String(), `"state":"completed"`)
+	})
+
+	t.Run("Server error", func(t *testing.T) {
+		serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer serv.Close()
+
+		cmd := GetCmd()
+		cmd.SetArgs([]string{"status", flag + urlFlagName, serv.URL + "/reindex"})
+
+		require.Error(t, cmd.Execute())
+	})
+}
+
+type mockReindexServer struct {
+	*httptest.Server
+
+	mutex   sync.Mutex
+	request string
+}
+
+// newMockReindexServer returns a mock server which reports that the reindex is running for the
+// given number of status requests and then reports the given final state.
+func newMockReindexServer(runningCount int, finalState reindex.State) *mockReindexServer {
+	s := &mockReindexServer{}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			body, _ := io.ReadAll(r.Body) //nolint:errcheck
+
+			s.mutex.Lock()
+			s.request = string(body)
+			s.mutex.Unlock()
+
+			fmt.Fprint(w, `{"state":"running"}`)
+
+			return
+		}
+
+		if runningCount > 0 {
+			runningCount--
+
+			fmt.Fprint(w, `{"state":"running"}`)
+
+			return
+		}
+
+		switch finalState {
+		case reindex.StateFailed:
+			fmt.Fprint(w, `{"state":"failed","error":"injected reindex error"}`)
+		default:
+			fmt.Fprint(w, `{"state":"completed","report":{"anchors":3}}`)
+		}
+	}))
+
+	return s
+}
+
+func (s *mockReindexServer) startRequest() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.request
+}
@@ -0,0 +1,162 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package reindexcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/trustbloc/orb/cmd/orb-cli/common"
+	"github.com/trustbloc/orb/internal/pkg/cmdutil"
+	"github.com/trustbloc/orb/pkg/anchor/reindex"
+)
+
+const (
+	anchorFlagName  = "anchor"
+	anchorEnvKey    = "ORB_CLI_ANCHOR"
+	anchorFlagUsage = "The hashlink of an anchor from which to start walking the anchor graph. This flag may be " +
+		"repeated. If not specified then all anchors that were processed by the server are used." +
+		" Alternatively, this can be set with the following environment variable (comma-separated): " + anchorEnvKey
+
+	dryRunFlagName  = "dry-run"
+	dryRunEnvKey    = "ORB_CLI_DRY_RUN"
+	dryRunFlagUsage = "If true then divergences from the existing data are reported but not repaired." +
+		" Alternatively, this can be set with the following environment variable: " + dryRunEnvKey
+
+	waitFlagName  = "wait"
+	waitEnvKey    = "ORB_CLI_WAIT"
+	waitFlagUsage = "If true then the command waits for the reindex to complete and prints the report." +
+		" Alternatively, this can be set with the following environment variable: " + waitEnvKey
+
+	pollIntervalFlagName  = "poll-interval"
+	pollIntervalEnvKey    = "ORB_CLI_POLL_INTERVAL"
+	pollIntervalFlagUsage = "The interval at which the status of the reindex is polled when waiting for the " +
+		"reindex to complete. Defaults to 5s." +
+		" Alternatively, this can be set with the following environment variable: " + pollIntervalEnvKey
+)
+
+const defaultPollInterval = 5 * time.Second
+
+func newStartCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "start",
+		Short: "Starts a reindex of the DID state from the local anchor graph.",
+		Long: "Starts a reindex of the DID state from the local anchor graph. The reindex runs in the background " +
+			"on the server. For example: reindex start --url https://orb.domain1.com/reindex --dry-run true " +
+			"--wait true --auth-token ADMIN_TOKEN",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeStart(cmd)
+		},
+	}
+
+	common.AddCommonFlags(cmd)
+
+	cmd.Flags().StringP(urlFlagName, "", "", urlFlagUsage)
+	cmd.Flags().StringArrayP(anchorFlagName, "", nil, anchorFlagUsage)
+	cmd.Flags().StringP(dryRunFlagName, "", "", dryRunFlagUsage)
+	cmd.Flags().StringP(waitFlagName, "", "", waitFlagUsage)
+	cmd.Flags().StringP(pollIntervalFlagName, "", "", pollIntervalFlagUsage)
+
+	return cmd
+}
+
+func executeStart(cmd *cobra.Command) error {
+	u, err := getURL(cmd)
+	if err != nil {
+		return err
+	}
+
+	dryRun, err := getBool(cmd, dryRunFlagName, dryRunEnvKey)
+	if err != nil {
+		return err
+	}
+
+	wait, err := getBool(cmd, waitFlagName, waitEnvKey)
+	if err != nil {
+		return err
+	}
+
+	pollInterval, err := common.GetDuration(cmd, pollIntervalFlagName, pollIntervalEnvKey, defaultPollInterval)
+	if err != nil {
+		return err
+	}
+
+	reqBytes, err := json.Marshal(&startRequest{
+		Anchors: cmdutil.GetUserSetOptionalVarFromArrayString(cmd, anchorFlagName, anchorEnvKey),
+		DryRun:  dryRun,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal reindex request: %w", err)
+	}
+
+	resp, err := common.SendHTTPRequest(cmd, reqBytes, http.MethodPost, u)
+	if err != nil {
+		return err
+	}
+
+	if !wait {
+		common.Println(cmd.OutOrStdout(), string(resp))
+
+		return nil
+	}
+
+	return waitForCompletion(cmd, u, pollInterval)
+}
+
+func waitForCompletion(cmd *cobra.Command, u string, pollInterval time.Duration) error {
+	for {
+		resp, err := common.SendHTTPRequest(cmd, nil, http.MethodGet, u)
+		if err != nil {
+			return err
+		}
+
+		status := &reindex.Status{}
+
+		if err := json.Unmarshal(resp, status); err != nil {
+			return fmt.Errorf("unmarshal reindex status: %w", err)
+		}
+
+		if status.State == reindex.StateRunning {
+			time.Sleep(pollInterval)
+
+			continue
+		}
+
+		common.Println(cmd.OutOrStdout(), string(resp))
+
+		if status.State == reindex.StateFailed {
+			return fmt.Errorf("reindex failed: %s", status.Error)
+		}
+
+		return nil
+	}
+}
+
+func getBool(cmd *cobra.Command, flagName, envKey string) (bool, error) {
+	value := cmdutil.GetUserSetOptionalVarFromString(cmd, flagName, envKey)
+	if value == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value for %s: %s", flagName, value)
+	}
+
+	return b, nil
+}
+
+type startRequest struct {
+	Anchors []string `json:"anchors,omitempty"`
+	DryRun  bool     `json:"dryRun,omitempty"`
+}
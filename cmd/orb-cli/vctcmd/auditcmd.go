@@ -0,0 +1,609 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vctcmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	jsonld "github.com/piprate/json-gold/ld"
+	"github.com/spf13/cobra"
+	"github.com/transparency-dev/merkle/rfc6962"
+	"github.com/trustbloc/vct/pkg/client/vct"
+	"github.com/trustbloc/vct/pkg/controller/command"
+
+	"github.com/trustbloc/orb/cmd/orb-cli/common"
+	"github.com/trustbloc/orb/internal/pkg/cmdutil"
+	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	"github.com/trustbloc/orb/pkg/anchor/util"
+	"github.com/trustbloc/orb/pkg/linkset"
+	"github.com/trustbloc/orb/pkg/store/logmonitor"
+	"github.com/trustbloc/orb/pkg/vct/logmonitoring"
+	"github.com/trustbloc/orb/pkg/vct/logmonitoring/verifier"
+)
+
+const (
+	logFlagName  = "log"
+	logEnvKey    = "ORB_CLI_VCT_LOG"
+	logFlagUsage = "The URL of the VCT log to audit." +
+		" Alternatively, this can be set with the following environment variable: " + logEnvKey
+
+	logMonitorURLFlagName  = "log-monitor-url"
+	logMonitorURLEnvKey    = "ORB_CLI_LOG_MONITOR_URL"
+	logMonitorURLFlagUsage = "The URL of the Orb log monitor endpoint, for example https://orb.domain1.com/log-monitor. " +
+		"If set then the STH stored by the log monitor is checked for consistency against the log." +
+		" Alternatively, this can be set with the following environment variable: " + logMonitorURLEnvKey
+
+	outboxURLFlagName  = "outbox-url"
+	outboxURLEnvKey    = "ORB_CLI_OUTBOX_URL"
+	outboxURLFlagUsage = "The URL of the Orb service outbox, for example https://orb.domain1.com/services/orb/outbox. " +
+		"If set then every anchor credential published by the service (with a proof from the given log) " +
+		"is checked for inclusion in the log." +
+		" Alternatively, this can be set with the following environment variable: " + outboxURLEnvKey
+
+	maxGetEntriesRangeFlagName  = "max-get-entries-range"
+	maxGetEntriesRangeEnvKey    = "ORB_CLI_MAX_GET_ENTRIES_RANGE"
+	maxGetEntriesRangeFlagUsage = "The maximum number of entries retrieved from the VCT log in one request. " +
+		"Defaults to 1000." +
+		" Alternatively, this can be set with the following environment variable: " + maxGetEntriesRangeEnvKey
+)
+
+// VCT limits the maximum number of entries returned by get-entries to 1000.
+const defaultMaxGetEntriesRange = 1000
+
+func newAuditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Audits a VCT log.",
+		Long: `Downloads all entries of a VCT log, rebuilds the Merkle tree and verifies the signed tree head. ` +
+			`Optionally checks the STH stored by the Orb log monitor for consistency and verifies that all ` +
+			`anchor credentials published by an Orb service are included in the log. For example: vct audit ` +
+			`--log https://vct.example.com/maple2022 --log-monitor-url https://orb.domain1.com/log-monitor ` +
+			`--outbox-url https://orb.domain1.com/services/orb/outbox`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeAudit(cmd)
+		},
+	}
+
+	addAuditFlags(cmd)
+
+	return cmd
+}
+
+type auditArgs struct {
+	logURL             string
+	logMonitorURL      string
+	outboxURL          string
+	authToken          string
+	maxGetEntriesRange int
+	verbose            bool
+}
+
+func executeAudit(cmd *cobra.Command) error {
+	args, err := getAuditArgs(cmd)
+	if err != nil {
+		return err
+	}
+
+	httpClient, err := common.NewHTTPClient(cmd)
+	if err != nil {
+		return fmt.Errorf("new HTTP client: %w", err)
+	}
+
+	vctClient := vct.New(args.logURL,
+		vct.WithHTTPClient(httpClient),
+		vct.WithAuthReadToken(args.authToken),
+	)
+
+	a := &auditor{
+		cmd:       cmd,
+		args:      args,
+		vctClient: vctClient,
+		verifier:  verifier.New(),
+	}
+
+	report := a.audit()
+
+	reportBytes, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal audit report: %w", err)
+	}
+
+	common.Println(cmd.OutOrStdout(), string(reportBytes))
+
+	if !report.Passed {
+		return errors.New("audit failed")
+	}
+
+	return nil
+}
+
+type logVerifier interface {
+	VerifyConsistencyProof(snapshot1, snapshot2 int64, root1, root2 []byte, proof [][]byte) error
+	GetRootHashFromEntries(entries []*command.LeafEntry) ([]byte, error)
+}
+
+type auditor struct {
+	cmd       *cobra.Command
+	args      *auditArgs
+	vctClient *vct.Client
+	verifier  logVerifier
+}
+
+func (a *auditor) audit() *auditReport {
+	report := &auditReport{Log: a.args.logURL}
+
+	sth, pubKey, err := a.getSTH(report)
+	if err != nil {
+		report.addError(err)
+
+		return report
+	}
+
+	entries, err := a.getAllEntries(sth.TreeSize)
+	if err != nil {
+		report.addError(fmt.Errorf("get entries: %w", err))
+
+		return report
+	}
+
+	report.EntriesRetrieved = len(entries)
+
+	if err := a.verifyTree(entries, sth, report); err != nil {
+		report.addError(err)
+	}
+
+	if a.args.logMonitorURL != "" {
+		report.StoredState = a.checkStoredState(entries, sth, pubKey)
+
+		if report.StoredState.Error != "" {
+			report.addError(errors.New(report.StoredState.Error))
+		}
+	}
+
+	if a.args.outboxURL != "" {
+		report.Credentials = a.checkCredentials(entries)
+
+		if report.Credentials.Error != "" {
+			report.addError(errors.New(report.Credentials.Error))
+		} else if len(report.Credentials.Missing) > 0 {
+			report.addError(fmt.Errorf("%d anchor credential(s) not found in log", len(report.Credentials.Missing)))
+		}
+	}
+
+	report.Passed = len(report.Errors) == 0
+
+	return report
+}
+
+func (a *auditor) getSTH(report *auditReport) (*command.GetSTHResponse, []byte, error) {
+	sth, err := a.vctClient.GetSTH(context.Background())
+	if err != nil {
+		return nil, nil, fmt.Errorf("get STH: %w", err)
+	}
+
+	report.TreeSize = sth.TreeSize
+	report.Timestamp = sth.Timestamp
+	report.RootHash = sth.SHA256RootHash
+
+	a.printf("Retrieved STH from %s - Tree size: %d\n", a.args.logURL, sth.TreeSize)
+
+	pubKey, err := logmonitoring.GetPublicKey(a.vctClient)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get public key: %w", err)
+	}
+
+	if err := logmonitoring.VerifySTHSignature(sth, pubKey); err != nil {
+		return nil, nil, fmt.Errorf("verify STH signature: %w", err)
+	}
+
+	report.STHSignatureValid = true
+
+	return sth, pubKey, nil
+}
+
+// getAllEntries pages through get-entries (the log returns at most maxGetEntriesRange entries per request).
+func (a *auditor) getAllEntries(treeSize uint64) ([]*command.LeafEntry, error) {
+	var allEntries []*command.LeafEntry
+
+	for start := uint64(0); start < treeSize; {
+		end := start + uint64(a.args.maxGetEntriesRange) - 1
+		if end >= treeSize {
+			end = treeSize - 1
+		}
+
+		resp, err := a.vctClient.GetEntries(context.Background(), start, end)
+		if err != nil {
+			return nil, fmt.Errorf("get entries for range[%d-%d]: %w", start, end, err)
+		}
+
+		if len(resp.Entries) == 0 {
+			return nil, fmt.Errorf("no entries returned for range[%d-%d]", start, end)
+		}
+
+		for i := range resp.Entries {
+			allEntries = append(allEntries, &resp.Entries[i])
+		}
+
+		a.printf("... retrieved entries %d-%d\n", start, start+uint64(len(resp.Entries))-1)
+
+		start += uint64(len(resp.Entries))
+	}
+
+	return allEntries, nil
+}
+
+func (a *auditor) verifyTree(entries []*command.LeafEntry, sth *command.GetSTHResponse, report *auditReport) error {
+	if sth.TreeSize == 0 {
+		report.RootHashMatches = true
+
+		return nil
+	}
+
+	root, err := a.verifier.GetRootHashFromEntries(entries)
+	if err != nil {
+		return fmt.Errorf("get root hash from entries: %w", err)
+	}
+
+	report.ComputedRootHash = root
+
+	if !bytes.Equal(root, sth.SHA256RootHash) {
+		return errors.New("root hash computed from log entries does not match STH root hash")
+	}
+
+	report.RootHashMatches = true
+
+	return nil
+}
+
+func (a *auditor) checkStoredState(entries []*command.LeafEntry, sth *command.GetSTHResponse,
+	pubKey []byte) *storedStateReport {
+	report := &storedStateReport{}
+
+	lm, active, err := a.getStoredLogMonitor()
+	if err != nil {
+		report.Error = fmt.Sprintf("get stored log monitor state: %s", err)
+
+		return report
+	}
+
+	if lm == nil {
+		report.Error = "log is not monitored by the log monitor"
+
+		return report
+	}
+
+	report.Found = true
+	report.Active = active
+
+	if lm.STH == nil {
+		// The log monitor hasn't checked the log yet.
+		report.Consistent = true
+
+		return report
+	}
+
+	report.TreeSize = lm.STH.TreeSize
+	report.RootHash = lm.STH.SHA256RootHash
+	report.PublicKeyMatches = bytes.Equal(lm.PubKey, pubKey)
+
+	if err := logmonitoring.VerifySTHSignature(lm.STH, pubKey); err != nil {
+		report.Error = fmt.Sprintf("verify stored STH signature: %s", err)
+
+		return report
+	}
+
+	report.SignatureValid = true
+
+	if lm.STH.TreeSize > sth.TreeSize {
+		report.Error = fmt.Sprintf("stored tree size %d is greater than log tree size %d", lm.STH.TreeSize, sth.TreeSize)
+
+		return report
+	}
+
+	if lm.STH.TreeSize > 0 {
+		root, err := a.verifier.GetRootHashFromEntries(entries[:lm.STH.TreeSize])
+		if err != nil {
+			report.Error = fmt.Sprintf("get root hash from entries: %s", err)
+
+			return report
+		}
+
+		if !bytes.Equal(root, lm.STH.SHA256RootHash) {
+			report.Error = "stored STH root hash does not match root hash computed from log entries"
+
+			return report
+		}
+
+		if lm.STH.TreeSize < sth.TreeSize {
+			if err := a.verifyConsistencyProof(lm.STH, sth); err != nil {
+				report.Error = err.Error()
+
+				return report
+			}
+		}
+	}
+
+	report.Consistent = true
+
+	return report
+}
+
+func (a *auditor) verifyConsistencyProof(storedSTH, sth *command.GetSTHResponse) error {
+	resp, err := a.vctClient.GetSTHConsistency(context.Background(), storedSTH.TreeSize, sth.TreeSize)
+	if err != nil {
+		return fmt.Errorf("get STH consistency: %w", err)
+	}
+
+	err = a.verifier.VerifyConsistencyProof(int64(storedSTH.TreeSize), int64(sth.TreeSize),
+		storedSTH.SHA256RootHash, sth.SHA256RootHash, resp.Consistency)
+	if err != nil {
+		return fmt.Errorf("verify consistency proof: %w", err)
+	}
+
+	return nil
+}
+
+func (a *auditor) getStoredLogMonitor() (*logmonitor.LogMonitor, bool, error) {
+	for _, status := range []string{"active", "inactive"} {
+		respBytes, err := common.SendHTTPRequest(a.cmd, nil, http.MethodGet,
+			fmt.Sprintf("%s?status=%s", a.args.logMonitorURL, status))
+		if err != nil {
+			if strings.Contains(err.Error(), fmt.Sprintf("status '%d'", http.StatusNotFound)) {
+				continue
+			}
+
+			return nil, false, err
+		}
+
+		resp := &logMonitorResponse{}
+
+		if err := json.Unmarshal(respBytes, resp); err != nil {
+			return nil, false, fmt.Errorf("unmarshal log monitor response: %w", err)
+		}
+
+		for _, lm := range append(resp.Active, resp.Inactive...) {
+			if sameLog(lm.Log, a.args.logURL) {
+				return lm, status == "active", nil
+			}
+		}
+	}
+
+	return nil, false, nil
+}
+
+func (a *auditor) checkCredentials(entries []*command.LeafEntry) *credentialsReport {
+	report := &credentialsReport{}
+
+	docLoader, err := newDocumentLoader()
+	if err != nil {
+		report.Error = fmt.Sprintf("new document loader: %s", err)
+
+		return report
+	}
+
+	leafHashes := make(map[string]struct{}, len(entries))
+
+	for _, entry := range entries {
+		leafHashes[base64.StdEncoding.EncodeToString(rfc6962.DefaultHasher.HashLeaf(entry.LeafInput))] = struct{}{}
+	}
+
+	err = a.forEachPublishedCredential(docLoader, func(anchor string, vc *verifiable.Credential) error {
+		return a.checkCredential(anchor, vc, leafHashes, docLoader, report)
+	})
+	if err != nil {
+		report.Error = fmt.Sprintf("check published anchor credentials: %s", err)
+	}
+
+	return report
+}
+
+func (a *auditor) checkCredential(anchor string, vc *verifiable.Credential, leafHashes map[string]struct{},
+	docLoader jsonld.DocumentLoader, report *credentialsReport) error {
+	vcBytes, err := json.Marshal(vc)
+	if err != nil {
+		return fmt.Errorf("marshal VC: %w", err)
+	}
+
+	for _, proof := range vc.Proofs {
+		domain, created, err := getVCParameters(proof)
+		if err != nil || !sameLog(domain, a.args.logURL) {
+			continue
+		}
+
+		report.Checked++
+
+		leafHash, err := vct.CalculateLeafHash(uint64(created.UnixNano()/int64(time.Millisecond)), vcBytes, docLoader)
+		if err != nil {
+			return fmt.Errorf("calculate leaf hash for VC [%s]: %w", vc.ID, err)
+		}
+
+		if _, ok := leafHashes[leafHash]; ok {
+			report.Found++
+
+			continue
+		}
+
+		a.printf("... anchor credential [%s] was NOT found in the log\n", vc.ID)
+
+		report.Missing = append(report.Missing, &missingCredential{
+			ID:     vc.ID,
+			Anchor: anchor,
+		})
+	}
+
+	return nil
+}
+
+// forEachPublishedCredential pages through the service outbox and invokes the given function
+// for the anchor credential of each 'Create' activity.
+func (a *auditor) forEachPublishedCredential(docLoader jsonld.DocumentLoader,
+	handle func(anchor string, vc *verifiable.Credential) error) error {
+	return common.ForEachOutboxActivity(a.cmd, a.args.outboxURL, func(activity *vocab.ActivityType) error {
+		anchorLinkset, ok, err := getAnchorLinkset(activity)
+		if err != nil || !ok {
+			return err
+		}
+
+		vc, err := util.VerifiableCredentialFromAnchorLink(anchorLinkset.Link(),
+			verifiable.WithDisabledProofCheck(),
+			verifiable.WithJSONLDDocumentLoader(docLoader),
+		)
+		if err != nil {
+			return fmt.Errorf("get verifiable credential from anchor link: %w", err)
+		}
+
+		return handle(anchorLinkset.Link().Anchor().String(), vc)
+	})
+}
+
+func getAnchorLinkset(activity *vocab.ActivityType) (*linkset.Linkset, bool, error) {
+	if activity == nil || !activity.Type().Is(vocab.TypeCreate) {
+		return nil, false, nil
+	}
+
+	anchorEvent := activity.Object().AnchorEvent()
+	if anchorEvent == nil || anchorEvent.Object() == nil || anchorEvent.Object().Document() == nil {
+		return nil, false, nil
+	}
+
+	linksetBytes, err := json.Marshal(anchorEvent.Object().Document())
+	if err != nil {
+		return nil, false, fmt.Errorf("marshal anchor linkset: %w", err)
+	}
+
+	anchorLinkset := &linkset.Linkset{}
+
+	if err := json.Unmarshal(linksetBytes, anchorLinkset); err != nil {
+		return nil, false, fmt.Errorf("unmarshal anchor linkset: %w", err)
+	}
+
+	if anchorLinkset.Link() == nil {
+		return nil, false, nil
+	}
+
+	return anchorLinkset, true, nil
+}
+
+func (a *auditor) printf(msg string, args ...interface{}) {
+	if a.args.verbose {
+		common.Printf(a.cmd.ErrOrStderr(), msg, args...)
+	}
+}
+
+func sameLog(log1, log2 string) bool {
+	return strings.TrimSuffix(log1, "/") == strings.TrimSuffix(log2, "/")
+}
+
+func addAuditFlags(cmd *cobra.Command) {
+	common.AddCommonFlags(cmd)
+
+	cmd.Flags().StringP(logFlagName, "", "", logFlagUsage)
+	cmd.Flags().StringP(logMonitorURLFlagName, "", "", logMonitorURLFlagUsage)
+	cmd.Flags().StringP(outboxURLFlagName, "", "", outboxURLFlagUsage)
+	cmd.Flags().StringP(maxGetEntriesRangeFlagName, "", "", maxGetEntriesRangeFlagUsage)
+	cmd.Flags().StringP(vctAuthTokenFlagName, "", "", vctAuthTokenFlagUsage)
+	cmd.Flags().StringP(verboseFlagName, "", "", verboseFlagUsage)
+}
+
+func getAuditArgs(cmd *cobra.Command) (*auditArgs, error) {
+	logURL, err := cmdutil.GetUserSetVarFromString(cmd, logFlagName, logEnvKey, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := url.ParseRequestURI(logURL); err != nil {
+		return nil, fmt.Errorf("invalid log URL %s: %w", logURL, err)
+	}
+
+	args := &auditArgs{
+		logURL:             logURL,
+		logMonitorURL:      cmdutil.GetUserSetOptionalVarFromString(cmd, logMonitorURLFlagName, logMonitorURLEnvKey),
+		outboxURL:          cmdutil.GetUserSetOptionalVarFromString(cmd, outboxURLFlagName, outboxURLEnvKey),
+		authToken:          cmdutil.GetUserSetOptionalVarFromString(cmd, vctAuthTokenFlagName, vctAuthTokenEnvKey),
+		maxGetEntriesRange: defaultMaxGetEntriesRange,
+	}
+
+	maxGetEntriesRangeStr := cmdutil.GetUserSetOptionalVarFromString(cmd, maxGetEntriesRangeFlagName,
+		maxGetEntriesRangeEnvKey)
+	if maxGetEntriesRangeStr != "" {
+		maxRange, err := strconv.Atoi(maxGetEntriesRangeStr)
+		if err != nil || maxRange <= 0 || maxRange > defaultMaxGetEntriesRange {
+			return nil, fmt.Errorf("invalid value for %s: %s", maxGetEntriesRangeFlagName, maxGetEntriesRangeStr)
+		}
+
+		args.maxGetEntriesRange = maxRange
+	}
+
+	verboseStr := cmdutil.GetUserSetOptionalVarFromString(cmd, verboseFlagName, verboseEnvKey)
+	if verboseStr != "" {
+		args.verbose, err = strconv.ParseBool(verboseStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %s", verboseFlagName, verboseStr)
+		}
+	}
+
+	return args, nil
+}
+
+type auditReport struct {
+	Log               string             `json:"log"`
+	TreeSize          uint64             `json:"treeSize"`
+	Timestamp         uint64             `json:"timestamp"`
+	RootHash          []byte             `json:"rootHash,omitempty"`
+	STHSignatureValid bool               `json:"sthSignatureValid"`
+	EntriesRetrieved  int                `json:"entriesRetrieved"`
+	ComputedRootHash  []byte             `json:"computedRootHash,omitempty"`
+	RootHashMatches   bool               `json:"rootHashMatches"`
+	StoredState       *storedStateReport `json:"storedState,omitempty"`
+	Credentials       *credentialsReport `json:"credentials,omitempty"`
+	Passed            bool               `json:"passed"`
+	Errors            []string           `json:"errors,omitempty"`
+}
+
+func (r *auditReport) addError(err error) {
+	r.Errors = append(r.Errors, err.Error())
+}
+
+type storedStateReport struct {
+	Found            bool   `json:"found"`
+	Active           bool   `json:"active"`
+	TreeSize         uint64 `json:"treeSize"`
+	RootHash         []byte `json:"rootHash,omitempty"`
+	SignatureValid   bool   `json:"signatureValid"`
+	PublicKeyMatches bool   `json:"publicKeyMatches"`
+	Consistent       bool   `json:"consistent"`
+	Error            string `json:"error,omitempty"`
+}
+
+type credentialsReport struct {
+	Checked int                  `json:"checked"`
+	Found   int                  `json:"found"`
+	Missing []*missingCredential `json:"missing,omitempty"`
+	Error   string               `json:"error,omitempty"`
+}
+
+type missingCredential struct {
+	ID     string `json:"id"`
+	Anchor string `json:"anchor"`
+}
+
+type logMonitorResponse struct {
+	Active   []*logmonitor.LogMonitor `json:"active,omitempty"`
+	Inactive []*logmonitor.LogMonitor `json:"inactive,omitempty"`
+}
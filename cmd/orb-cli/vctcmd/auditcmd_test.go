@@ -0,0 +1,555 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vctcmd
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/canonicalizer"
+	"github.com/trustbloc/vct/pkg/client/vct"
+	"github.com/trustbloc/vct/pkg/controller/command"
+
+	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	"github.com/trustbloc/orb/pkg/anchor/util"
+	"github.com/trustbloc/orb/pkg/linkset"
+	"github.com/trustbloc/orb/pkg/store/logmonitor"
+	"github.com/trustbloc/orb/pkg/vct/logmonitoring/verifier"
+)
+
+const (
+	getSTHPath            = "/v1/get-sth"
+	getEntriesPath        = "/v1/get-entries"
+	getSTHConsistencyPath = "/v1/get-sth-consistency"
+	webfingerPath         = "/.well-known/webfinger"
+
+	testVCTDomain = "http://orb.vct:8077/maple2020"
+)
+
+func TestAuditCmd(t *testing.T) {
+	t.Run("test missing log arg", func(t *testing.T) {
+		cmd := GetCmd()
+		cmd.SetArgs([]string{"audit"})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Equal(t,
+			"Neither log (command line flag) nor ORB_CLI_VCT_LOG (environment variable) have been set.",
+			err.Error())
+	})
+
+	t.Run("test invalid log arg", func(t *testing.T) {
+		cmd := GetCmd()
+
+		args := []string{"audit"}
+		args = append(args, logArg(":invalid")...)
+		cmd.SetArgs(args)
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid log URL")
+	})
+
+	t.Run("test invalid max get entries range arg", func(t *testing.T) {
+		cmd := GetCmd()
+
+		args := []string{"audit"}
+		args = append(args, logArg("https://vct.com/log")...)
+		args = append(args, flag+maxGetEntriesRangeFlagName, "1001")
+		cmd.SetArgs(args)
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid value for max-get-entries-range")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		vctServ := newMockVCTServer(t, newTestSTH(t, testEntries[:4]), testEntries[:4])
+		defer vctServ.Close()
+
+		cmd := GetCmd()
+
+		out := bytes.NewBuffer(nil)
+		cmd.SetOut(out)
+
+		args := []string{"audit"}
+		args = append(args, logArg(vctServ.URL)...)
+		args = append(args, flag+maxGetEntriesRangeFlagName, "3")
+		args = append(args, verboseArg(true)...)
+		cmd.SetArgs(args)
+
+		require.NoError(t, cmd.Execute())
+
+		report := &auditReport{}
+		require.NoError(t, json.Unmarshal(out.Bytes(), report))
+		require.True(t, report.Passed)
+		require.True(t, report.STHSignatureValid)
+		require.True(t, report.RootHashMatches)
+		require.Equal(t, 4, report.EntriesRetrieved)
+		require.Equal(t, uint64(4), report.TreeSize)
+	})
+
+	t.Run("root hash mismatch", func(t *testing.T) {
+		vctServ := newMockVCTServer(t, newTestSTH(t, testEntries[:4]), testEntries[1:5])
+		defer vctServ.Close()
+
+		cmd := GetCmd()
+
+		out := bytes.NewBuffer(nil)
+		cmd.SetOut(out)
+
+		args := []string{"audit"}
+		args = append(args, logArg(vctServ.URL)...)
+		cmd.SetArgs(args)
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "audit failed")
+		require.Contains(t, out.String(), "does not match STH root hash")
+	})
+
+	t.Run("invalid STH signature", func(t *testing.T) {
+		vctServ := newMockVCTServer(t, newTestSTH(t, testEntries[:4]), testEntries[:4])
+		defer vctServ.Close()
+
+		vctServ.publicKey = base64.StdEncoding.EncodeToString(newTestPublicKey(t))
+
+		cmd := GetCmd()
+
+		out := bytes.NewBuffer(nil)
+		cmd.SetOut(out)
+
+		args := []string{"audit"}
+		args = append(args, logArg(vctServ.URL)...)
+		cmd.SetArgs(args)
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, out.String(), "verify STH signature")
+	})
+
+	t.Run("stored state", func(t *testing.T) {
+		vctServ := newMockVCTServer(t, newTestSTH(t, testEntries), testEntries)
+		defer vctServ.Close()
+
+		storedSTH := newTestSTH(t, testEntries[:4])
+
+		monitorServ := newMockLogMonitorServer(t, &logmonitor.LogMonitor{Log: vctServ.URL, STH: storedSTH})
+		defer monitorServ.Close()
+
+		cmd := newAuditCmd()
+
+		out := bytes.NewBuffer(nil)
+		cmd.SetOut(out)
+
+		args := []string{}
+		args = append(args, logArg(vctServ.URL)...)
+		args = append(args, flag+logMonitorURLFlagName, monitorServ.URL)
+		cmd.SetArgs(args)
+
+		// The mock VCT server doesn't return a valid consistency proof.
+		err := cmd.Execute()
+		require.Error(t, err)
+
+		report := &auditReport{}
+		require.NoError(t, json.Unmarshal(out.Bytes(), report))
+		require.True(t, report.RootHashMatches)
+		require.NotNil(t, report.StoredState)
+		require.True(t, report.StoredState.Found)
+		require.True(t, report.StoredState.Active)
+		require.True(t, report.StoredState.SignatureValid)
+		require.Equal(t, uint64(4), report.StoredState.TreeSize)
+		require.Contains(t, report.StoredState.Error, "verify consistency proof")
+	})
+}
+
+func TestAuditor_CheckStoredState(t *testing.T) {
+	storedSTH := newTestSTH(t, testEntries[:4])
+	sth := newTestSTH(t, testEntries)
+
+	vctServ := newMockVCTServer(t, newTestSTH(t, testEntries), testEntries)
+	defer vctServ.Close()
+
+	t.Run("consistent", func(t *testing.T) {
+		monitorServ := newMockLogMonitorServer(t, &logmonitor.LogMonitor{Log: vctServ.URL, STH: storedSTH})
+		defer monitorServ.Close()
+
+		a := newTestAuditor(t, vctServ.URL, monitorServ.URL, "")
+		a.verifier = &mockLogVerifier{LogVerifier: verifier.New()}
+
+		report := a.checkStoredState(testEntryPtrs(testEntries), sth, testPublicKey)
+		require.Empty(t, report.Error)
+		require.True(t, report.Consistent)
+	})
+
+	t.Run("stored tree size greater than log tree size", func(t *testing.T) {
+		monitorServ := newMockLogMonitorServer(t, &logmonitor.LogMonitor{Log: vctServ.URL, STH: sth})
+		defer monitorServ.Close()
+
+		a := newTestAuditor(t, vctServ.URL, monitorServ.URL, "")
+
+		report := a.checkStoredState(testEntryPtrs(testEntries[:4]), storedSTH, testPublicKey)
+		require.Contains(t, report.Error, "is greater than log tree size")
+		require.False(t, report.Consistent)
+	})
+
+	t.Run("stored root hash mismatch", func(t *testing.T) {
+		monitorServ := newMockLogMonitorServer(t, &logmonitor.LogMonitor{Log: vctServ.URL, STH: storedSTH})
+		defer monitorServ.Close()
+
+		a := newTestAuditor(t, vctServ.URL, monitorServ.URL, "")
+
+		report := a.checkStoredState(testEntryPtrs(testEntries[1:]), sth, testPublicKey)
+		require.Contains(t, report.Error, "stored STH root hash does not match")
+	})
+
+	t.Run("log not monitored", func(t *testing.T) {
+		monitorServ := newMockLogMonitorServer(t)
+		defer monitorServ.Close()
+
+		a := newTestAuditor(t, vctServ.URL, monitorServ.URL, "")
+
+		report := a.checkStoredState(testEntryPtrs(testEntries), sth, testPublicKey)
+		require.False(t, report.Found)
+		require.Contains(t, report.Error, "log is not monitored")
+	})
+
+	t.Run("log monitor error", func(t *testing.T) {
+		monitorServ := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer monitorServ.Close()
+
+		a := newTestAuditor(t, vctServ.URL, monitorServ.URL, "")
+
+		report := a.checkStoredState(testEntryPtrs(testEntries), sth, testPublicKey)
+		require.Contains(t, report.Error, "get stored log monitor state")
+	})
+}
+
+func TestAuditor_CheckCredentials(t *testing.T) {
+	leafInput := getTestLeafInput(t)
+
+	outboxServ := newMockOutboxServer(t)
+	defer outboxServ.Close()
+
+	t.Run("found", func(t *testing.T) {
+		a := newTestAuditor(t, testVCTDomain, "", outboxServ.URL)
+
+		report := a.checkCredentials(testEntryPtrs(append(testEntries, command.LeafEntry{LeafInput: leafInput})))
+		require.Empty(t, report.Error)
+		require.Equal(t, 1, report.Checked)
+		require.Equal(t, 1, report.Found)
+		require.Empty(t, report.Missing)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		a := newTestAuditor(t, testVCTDomain, "", outboxServ.URL)
+
+		report := a.checkCredentials(testEntryPtrs(testEntries))
+		require.Empty(t, report.Error)
+		require.Equal(t, 1, report.Checked)
+		require.Equal(t, 0, report.Found)
+		require.Len(t, report.Missing, 1)
+		require.Equal(t, "https://orb2.domain1.com/vc/19148c22-9088-4652-bcfa-fcea1279f072", report.Missing[0].ID)
+	})
+
+	t.Run("other log", func(t *testing.T) {
+		a := newTestAuditor(t, "https://vct.other.com/log", "", outboxServ.URL)
+
+		report := a.checkCredentials(testEntryPtrs(testEntries))
+		require.Empty(t, report.Error)
+		require.Equal(t, 0, report.Checked)
+	})
+
+	t.Run("outbox error", func(t *testing.T) {
+		a := newTestAuditor(t, testVCTDomain, "", outboxServ.URL+"/invalid")
+
+		report := a.checkCredentials(testEntryPtrs(testEntries))
+		require.Contains(t, report.Error, "get outbox")
+	})
+}
+
+func newTestAuditor(t *testing.T, logURL, logMonitorURL, outboxURL string) *auditor {
+	t.Helper()
+
+	cmd := newAuditCmd()
+
+	args := []string{}
+	args = append(args, logArg(logURL)...)
+	cmd.SetArgs(args)
+	require.NoError(t, cmd.ParseFlags(args))
+
+	return &auditor{
+		cmd: cmd,
+		args: &auditArgs{
+			logURL:             logURL,
+			logMonitorURL:      logMonitorURL,
+			outboxURL:          outboxURL,
+			maxGetEntriesRange: defaultMaxGetEntriesRange,
+		},
+		vctClient: vct.New(logURL),
+		verifier:  verifier.New(),
+	}
+}
+
+func getTestLeafInput(t *testing.T) []byte {
+	t.Helper()
+
+	docLoader, err := newDocumentLoader()
+	require.NoError(t, err)
+
+	ls := &linkset.Linkset{}
+	require.NoError(t, json.Unmarshal([]byte(anchorLinkset), ls))
+
+	vc, err := util.VerifiableCredentialFromAnchorLink(ls.Link(),
+		verifiable.WithDisabledProofCheck(),
+		verifiable.WithJSONLDDocumentLoader(docLoader),
+	)
+	require.NoError(t, err)
+
+	vcBytes, err := json.Marshal(vc)
+	require.NoError(t, err)
+
+	_, created, err := getVCParameters(vc.Proofs[0])
+	require.NoError(t, err)
+
+	leaf, err := command.CreateLeaf(uint64(created.UnixNano()/int64(time.Millisecond)), vcBytes, docLoader)
+	require.NoError(t, err)
+
+	leafInput, err := canonicalizer.MarshalCanonical(leaf)
+	require.NoError(t, err)
+
+	return leafInput
+}
+
+type mockVCTServer struct {
+	*httptest.Server
+
+	publicKey string
+}
+
+func newMockVCTServer(t *testing.T, sth *command.GetSTHResponse, entries []command.LeafEntry) *mockVCTServer {
+	t.Helper()
+
+	s := &mockVCTServer{publicKey: base64.StdEncoding.EncodeToString(testPublicKey)}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var respBytes []byte
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, getSTHPath):
+			respBytes = mustMarshal(t, sth)
+		case strings.HasSuffix(r.URL.Path, webfingerPath):
+			respBytes = mustMarshal(t, command.WebFingerResponse{
+				Properties: map[string]interface{}{
+					command.PublicKeyType: s.publicKey,
+				},
+			})
+		case strings.HasSuffix(r.URL.Path, getEntriesPath):
+			start, err := strconv.Atoi(r.URL.Query().Get("start"))
+			require.NoError(t, err)
+
+			end, err := strconv.Atoi(r.URL.Query().Get("end"))
+			require.NoError(t, err)
+
+			respBytes = mustMarshal(t, command.GetEntriesResponse{Entries: entries[start : end+1]})
+		case strings.HasSuffix(r.URL.Path, getSTHConsistencyPath):
+			respBytes = []byte(`{}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		_, err := w.Write(respBytes)
+		require.NoError(t, err)
+	}))
+
+	return s
+}
+
+func newMockLogMonitorServer(t *testing.T, logs ...*logmonitor.LogMonitor) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("status") != "active" || len(logs) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		_, err := w.Write(mustMarshal(t, &logMonitorResponse{Active: logs}))
+		require.NoError(t, err)
+	}))
+}
+
+func newMockOutboxServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	doc, err := vocab.UnmarshalToDoc([]byte(anchorLinkset))
+	require.NoError(t, err)
+
+	create := vocab.NewCreateActivity(
+		vocab.NewObjectProperty(vocab.WithAnchorEvent(
+			vocab.NewAnchorEvent(vocab.NewObjectProperty(vocab.WithDocument(doc))),
+		)),
+	)
+
+	var serv *httptest.Server
+
+	serv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var respBytes []byte
+
+		switch r.URL.Path {
+		case "/":
+			first, err := url.Parse(serv.URL + "/page1")
+			require.NoError(t, err)
+
+			respBytes = mustMarshal(t, vocab.NewOrderedCollection(nil, vocab.WithFirst(first)))
+		case "/page1":
+			next, err := url.Parse(serv.URL + "/page2")
+			require.NoError(t, err)
+
+			respBytes = mustMarshal(t, vocab.NewOrderedCollectionPage(
+				[]*vocab.ObjectProperty{vocab.NewObjectProperty(vocab.WithActivity(create))},
+				vocab.WithNext(next),
+			))
+		case "/page2":
+			respBytes = mustMarshal(t, vocab.NewOrderedCollectionPage(
+				[]*vocab.ObjectProperty{vocab.NewObjectProperty(vocab.WithActivity(vocab.NewFollowActivity(nil)))},
+			))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		_, err := w.Write(respBytes)
+		require.NoError(t, err)
+	}))
+
+	return serv
+}
+
+type mockLogVerifier struct {
+	*verifier.LogVerifier
+}
+
+func (m *mockLogVerifier) VerifyConsistencyProof(_, _ int64, _, _ []byte, _ [][]byte) error {
+	return nil
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	t.Helper()
+
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	return b
+}
+
+func testEntryPtrs(entries []command.LeafEntry) []*command.LeafEntry {
+	ptrs := make([]*command.LeafEntry, len(entries))
+
+	for i := range entries {
+		ptrs[i] = &entries[i]
+	}
+
+	return ptrs
+}
+
+func logArg(value string) []string {
+	return []string{flag + logFlagName, value}
+}
+
+var testEntries = []command.LeafEntry{
+	{LeafInput: []byte("leafInput-0")},
+	{LeafInput: []byte("leafInput-1")},
+	{LeafInput: []byte("leafInput-2")},
+	{LeafInput: []byte("leafInput-3")},
+	{LeafInput: []byte("leafInput-4")},
+}
+
+var testPrivateKey, testPublicKey = func() (*ecdsa.PrivateKey, []byte) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+
+	publicKey, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		panic(err)
+	}
+
+	return privateKey, publicKey
+}()
+
+func newTestPublicKey(t *testing.T) []byte {
+	t.Helper()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	publicKey, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	require.NoError(t, err)
+
+	return publicKey
+}
+
+// newTestSTH returns a signed tree head for the given entries.
+func newTestSTH(t *testing.T, entries []command.LeafEntry) *command.GetSTHResponse {
+	t.Helper()
+
+	root, err := verifier.New().GetRootHashFromEntries(testEntryPtrs(entries))
+	require.NoError(t, err)
+
+	sth := &command.GetSTHResponse{
+		TreeSize:       uint64(len(entries)),
+		Timestamp:      uint64(time.Now().UnixNano() / int64(time.Millisecond)),
+		SHA256RootHash: root,
+	}
+
+	data, err := canonicalizer.MarshalCanonical(command.TreeHeadSignature{
+		Version:        command.V1,
+		SignatureType:  command.TreeHeadSignatureType,
+		Timestamp:      sth.Timestamp,
+		TreeSize:       sth.TreeSize,
+		SHA256RootHash: sth.SHA256RootHash,
+	})
+	require.NoError(t, err)
+
+	digest := sha256.Sum256(data)
+
+	signature, err := ecdsa.SignASN1(rand.Reader, testPrivateKey, digest[:])
+	require.NoError(t, err)
+
+	sth.TreeHeadSignature = mustMarshal(t, &command.DigitallySigned{
+		Algorithm: command.SignatureAndHashAlgorithm{
+			Signature: command.ECDSASignature,
+			Type:      kms.ECDSAP256TypeDER,
+		},
+		Signature: signature,
+	})
+
+	return sth
+}
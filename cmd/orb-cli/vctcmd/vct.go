@@ -41,12 +41,13 @@ func GetCmd() *cobra.Command {
 		Short:        "Examines the VCT log.",
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return errors.New("expecting subcommand: verify")
+			return errors.New("expecting subcommand: verify or audit")
 		},
 	}
 
 	cmd.AddCommand(
 		newVerifyCmd(&clientProvider{}),
+		newAuditCmd(),
 	)
 
 	return cmd
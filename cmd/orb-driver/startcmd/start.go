@@ -65,6 +65,13 @@ const (
 	sidetreeTokenEnvKey    = "ORB_DRIVER_SIDETREE_TOKEN" //nolint: gosec
 	sidetreeTokenFlagUsage = "The sidetree token." +
 		" Alternatively, this can be set with the following environment variable: " + sidetreeTokenEnvKey
+
+	didResolutionStrictFlagName  = "did-resolution-strict"
+	didResolutionStrictEnvKey    = "ORB_DRIVER_DID_RESOLUTION_STRICT"
+	didResolutionStrictFlagUsage = "Strictly conform to the W3C DID Resolution HTTP(S) binding, i.e. a request for " +
+		"application/did+ld+json returns the DID document only and a deactivated DID returns status 410." +
+		" Possible values [true] [false]. Defaults to false if not set." +
+		" Alternatively, this can be set with the following environment variable: " + didResolutionStrictEnvKey
 )
 
 const (
@@ -104,6 +111,7 @@ type parameters struct {
 	tlsCertificate             string
 	tlsKey                     string
 	verifyResolutionResultType orb.VerifyResolutionResultType
+	didResolutionStrict        bool
 }
 
 // GetStartCmd returns the Cobra start command.
@@ -156,6 +164,11 @@ func getParameters(cmd *cobra.Command) (*parameters, error) {
 		return nil, err
 	}
 
+	didResolutionStrict, err := getDIDResolutionStrict(cmd)
+	if err != nil {
+		return nil, err
+	}
+
 	return &parameters{
 		hostURL:                    hostURL,
 		tlsSystemCertPool:          tlsSystemCertPool,
@@ -165,9 +178,26 @@ func getParameters(cmd *cobra.Command) (*parameters, error) {
 		tlsCertificate:             tlsCertificate,
 		tlsKey:                     tlsKey,
 		verifyResolutionResultType: verifyResolutionResultType,
+		didResolutionStrict:        didResolutionStrict,
 	}, nil
 }
 
+func getDIDResolutionStrict(cmd *cobra.Command) (bool, error) {
+	strictString := cmdutil.GetUserSetOptionalVarFromString(cmd, didResolutionStrictFlagName,
+		didResolutionStrictEnvKey)
+
+	if strictString == "" {
+		return false, nil
+	}
+
+	strict, err := strconv.ParseBool(strictString)
+	if err != nil {
+		return false, fmt.Errorf("invalid value for %s: %w", didResolutionStrictFlagName, err)
+	}
+
+	return strict, nil
+}
+
 func getVerifyResolutionResultType(cmd *cobra.Command) (orb.VerifyResolutionResultType, error) {
 	verifyTypeString, err := cmdutil.GetUserSetVarFromString(cmd, verifyTypeFlagName,
 		verifyTypeEnvKey, false)
@@ -218,6 +248,7 @@ func createFlags(startCmd *cobra.Command) {
 	startCmd.Flags().StringP(tlsCertificateFlagName, "", "", tlsCertificateFlagUsage)
 	startCmd.Flags().StringP(tlsKeyFlagName, "", "", tlsKeyFlagUsage)
 	startCmd.Flags().StringP(verifyTypeFlagName, "", "", verifyTypeFlagUsage)
+	startCmd.Flags().StringP(didResolutionStrictFlagName, "", "", didResolutionStrictFlagUsage)
 }
 
 func startDriver(parameters *parameters) error {
@@ -243,7 +274,8 @@ func startDriver(parameters *parameters) error {
 
 	// create driver rest api
 	endpointDiscoveryOp := driverrest.New(&driverrest.Config{
-		OrbVDR: orbVDR,
+		OrbVDR:     orbVDR,
+		StrictMode: parameters.didResolutionStrict,
 	})
 
 	handlers := make([]restcommon.HTTPHandler, 0)
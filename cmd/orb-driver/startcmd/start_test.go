@@ -56,6 +56,23 @@ func TestTLSSystemCertPoolInvalidArgsEnvVar(t *testing.T) {
 	require.Contains(t, err.Error(), "invalid syntax")
 }
 
+func TestDIDResolutionStrictInvalidArgsEnvVar(t *testing.T) {
+	startCmd := GetStartCmd()
+
+	require.NoError(t, os.Setenv(hostURLEnvKey, "localhost:8080"))
+	require.NoError(t, os.Setenv(tlsSystemCertPoolEnvKey, "true"))
+	require.NoError(t, os.Setenv(verifyTypeEnvKey, verifyTypeNone))
+	require.NoError(t, os.Setenv(didResolutionStrictEnvKey, "wrongvalue"))
+
+	defer func() {
+		require.NoError(t, os.Unsetenv(didResolutionStrictEnvKey))
+	}()
+
+	err := startCmd.Execute()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid value for did-resolution-strict")
+}
+
 func checkFlagPropertiesCorrect(t *testing.T, cmd *cobra.Command, flagName, flagShorthand, flagUsage string) {
 	t.Helper()
 
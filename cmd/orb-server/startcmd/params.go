@@ -26,6 +26,7 @@ import (
 	"github.com/trustbloc/orb/pkg/datauri"
 	"github.com/trustbloc/orb/pkg/document/util"
 	"github.com/trustbloc/orb/pkg/httpserver/auth"
+	"github.com/trustbloc/orb/pkg/store/logentry"
 )
 
 // kmsMode kms mode
@@ -120,10 +121,12 @@ const (
 	defaultAnchorSyncInterval               = time.Minute
 	defaultAnchorSyncMinActivityAge         = time.Minute
 	defaultVCTProofMonitoringInterval       = 10 * time.Second
+	defaultVCTProofMonitoringRetention      = 24 * time.Hour
 	defaultVCTLogMonitoringInterval         = 10 * time.Second
 	defaultVCTLogMonitoringMaxTreeSize      = 50000
 	defaultVCTLogMonitoringGetEntriesRange  = 1000
 	defaultVCTLogEntriesStoreEnabled        = false
+	defaultVCTLogEntriesCompactionInterval  = time.Hour
 	defaultAnchorStatusMonitoringInterval   = 5 * time.Second
 	defaultAnchorStatusInProcessGracePeriod = 30 * time.Second
 	mqDefaultMaxConnectionSubscriptions     = 1000
@@ -146,6 +149,8 @@ const (
 	defaultWitnessPolicyCacheExpiration     = 30 * time.Second
 	defaultDataURIMediaType                 = datauri.MediaTypeDataURIGzipBase64
 	defaultAllowedOriginsCacheExpiration    = time.Minute
+	defaultResolutionCacheSize              = 1000
+	defaultResolutionCacheExpiration        = time.Minute
 
 	opQueueDefaultPoolSize            = 5
 	opQueueDefaultTaskMonitorInterval = 10 * time.Second
@@ -176,6 +181,13 @@ const (
 	vctProofMonitoringExpiryPeriodEnvKey    = "VCT_PROOF_MONITORING_EXPIRY_PERIOD"
 	vctProofMonitoringExpiryPeriodFlagUsage = "Monitoring service will keep checking for this period of time for proof to be included(default 1h). " + commonEnvVarUsageText + vctProofMonitoringExpiryPeriodEnvKey
 
+	vctProofMonitoringFailedRetentionFlagName  = "vct-proof-monitoring-failed-retention-period"
+	vctProofMonitoringFailedRetentionEnvKey    = "VCT_PROOF_MONITORING_FAILED_RETENTION_PERIOD"
+	vctProofMonitoringFailedRetentionFlagUsage = "The period of time for which credentials that were not included " +
+		"in a VCT log before the deadline are retained (and reported by the proof monitor REST endpoint). " +
+		"Defaults to 24h if not set. " +
+		commonEnvVarUsageText + vctProofMonitoringFailedRetentionEnvKey
+
 	vctLogMonitoringIntervalFlagName  = "vct-log-monitoring-interval"
 	vctLogMonitoringIntervalEnvKey    = "VCT_LOG_MONITORING_INTERVAL"
 	vctLogMonitoringIntervalFlagUsage = "The interval in which VCT logs are monitored to ensure that they are consistent. " +
@@ -200,6 +212,44 @@ const (
 		"Defaults to false if not set. " +
 		commonEnvVarUsageText + vctLogEntriesStoreEnabledEnvKey
 
+	vctLogEntriesMaxEntriesFlagName  = "vct-log-entries-max-entries"
+	vctLogEntriesMaxEntriesEnvKey    = "VCT_LOG_ENTRIES_MAX_ENTRIES"
+	vctLogEntriesMaxEntriesFlagUsage = "The maximum number of the most recent log entries that are kept in the log " +
+		"entries store for each log. Older entries are archived (if an archive type is set) and removed from the store. " +
+		"Defaults to 0 (no limit) if not set. " +
+		commonEnvVarUsageText + vctLogEntriesMaxEntriesEnvKey
+
+	vctLogEntriesMaxAgeFlagName  = "vct-log-entries-max-age"
+	vctLogEntriesMaxAgeEnvKey    = "VCT_LOG_ENTRIES_MAX_AGE"
+	vctLogEntriesMaxAgeFlagUsage = "The maximum amount of time that log entries are kept in the log entries store. " +
+		"Expired entries are archived (if an archive type is set) and removed from the store. " +
+		"Defaults to 0 (no limit) if not set. " +
+		commonEnvVarUsageText + vctLogEntriesMaxAgeEnvKey
+
+	vctLogEntriesRetentionPolicyFlagName  = "vct-log-entries-retention-policy"
+	vctLogEntriesRetentionPolicyEnvKey    = "VCT_LOG_ENTRIES_RETENTION_POLICY"
+	vctLogEntriesRetentionPolicyFlagUsage = "Log-specific retention policies which override the default maximum " +
+		"number of entries and maximum age. Format: <log URL>=<max entries>:<max age>. " +
+		"For example: https://vct.example.com/log=10000:720h. " +
+		commonEnvVarUsageText + vctLogEntriesRetentionPolicyEnvKey
+
+	vctLogEntriesArchiveTypeFlagName  = "vct-log-entries-archive-type"
+	vctLogEntriesArchiveTypeEnvKey    = "VCT_LOG_ENTRIES_ARCHIVE_TYPE"
+	vctLogEntriesArchiveTypeFlagUsage = "The type of archive to which log entries are exported before they are " +
+		"removed from the log entries store. Supported values: none, file, cas. Defaults to none if not set. " +
+		commonEnvVarUsageText + vctLogEntriesArchiveTypeEnvKey
+
+	vctLogEntriesArchiveDirFlagName  = "vct-log-entries-archive-dir"
+	vctLogEntriesArchiveDirEnvKey    = "VCT_LOG_ENTRIES_ARCHIVE_DIR"
+	vctLogEntriesArchiveDirFlagUsage = "The directory to which log entry archives are written if the archive type " +
+		"is 'file'. " + commonEnvVarUsageText + vctLogEntriesArchiveDirEnvKey
+
+	vctLogEntriesCompactionIntervalFlagName  = "vct-log-entries-compaction-interval"
+	vctLogEntriesCompactionIntervalEnvKey    = "VCT_LOG_ENTRIES_COMPACTION_INTERVAL"
+	vctLogEntriesCompactionIntervalFlagUsage = "The interval in which the log entries store is compacted according " +
+		"to the maximum number of entries. Defaults to 1h if not set. " +
+		commonEnvVarUsageText + vctLogEntriesCompactionIntervalEnvKey
+
 	anchorStatusMonitoringIntervalFlagName  = "anchor-status-monitoring-interval"
 	anchorStatusMonitoringIntervalEnvKey    = "ANCHOR_STATUS_MONITORING_INTERVAL"
 	anchorStatusMonitoringIntervalFlagUsage = "The interval in which 'in-process' anchors are monitored to ensure that they will be witnessed(completed) as per policy." +
@@ -264,9 +314,95 @@ const (
 	casTypeFlagShorthand = "c"
 	casTypeEnvKey        = "CAS_TYPE"
 	casTypeFlagUsage     = "The type of the Content Addressable Storage (CAS). " +
-		"Supported options: local, ipfs. For local, the storage provider specified by " + databaseTypeFlagName +
+		"Supported options: local, ipfs, s3. For local, the storage provider specified by " + databaseTypeFlagName +
 		" will be used. For ipfs, the node specified by " + ipfsURLFlagName +
-		" will be used. This is a required parameter. " + commonEnvVarUsageText + casTypeEnvKey
+		" will be used. For s3, the bucket specified by " + casS3BucketFlagName + " will be used. " +
+		"This is a required parameter. " + commonEnvVarUsageText + casTypeEnvKey
+
+	casS3EndpointFlagName  = "cas-s3-endpoint"
+	casS3EndpointEnvKey    = "CAS_S3_ENDPOINT"
+	casS3EndpointFlagUsage = "The endpoint of the S3-compatible object store (e.g. http://minio:9000) if the CAS type " +
+		"is s3. If not set then the default AWS S3 endpoint for the region is used. " +
+		commonEnvVarUsageText + casS3EndpointEnvKey
+
+	casS3RegionFlagName  = "cas-s3-region"
+	casS3RegionEnvKey    = "CAS_S3_REGION"
+	casS3RegionFlagUsage = "The region of the S3 bucket if the CAS type is s3. " + commonEnvVarUsageText + casS3RegionEnvKey
+
+	casS3BucketFlagName  = "cas-s3-bucket"
+	casS3BucketEnvKey    = "CAS_S3_BUCKET"
+	casS3BucketFlagUsage = "The S3 bucket in which CAS objects are stored. This parameter is required if the CAS type " +
+		"is s3. " + commonEnvVarUsageText + casS3BucketEnvKey
+
+	casS3PrefixFlagName  = "cas-s3-prefix"
+	casS3PrefixEnvKey    = "CAS_S3_PREFIX"
+	casS3PrefixFlagUsage = "An optional prefix that is prepended to the key of each CAS object in the S3 bucket. " +
+		commonEnvVarUsageText + casS3PrefixEnvKey
+
+	casS3ForcePathStyleFlagName  = "cas-s3-force-path-style"
+	casS3ForcePathStyleEnvKey    = "CAS_S3_FORCE_PATH_STYLE"
+	casS3ForcePathStyleFlagUsage = "If true then path-style addressing (http://host/bucket/key) is used for S3 " +
+		"requests instead of virtual-hosted style. This is usually required for S3-compatible stores such as MinIO. " +
+		"Defaults to false. " + commonEnvVarUsageText + casS3ForcePathStyleEnvKey
+
+	casGCEnabledFlagName  = "cas-gc-enabled"
+	casGCEnabledEnvKey    = "CAS_GC_ENABLED"
+	casGCEnabledFlagUsage = "If true then content in the local CAS that is neither reachable from the anchor graph " +
+		"nor pinned is periodically deleted. This parameter only applies if the CAS type is local. " +
+		"Defaults to false. " + commonEnvVarUsageText + casGCEnabledEnvKey
+
+	casGCIntervalFlagName  = "cas-gc-interval"
+	casGCIntervalEnvKey    = "CAS_GC_INTERVAL"
+	casGCIntervalFlagUsage = "The interval in which the CAS garbage collector runs. Defaults to 24h. " +
+		commonEnvVarUsageText + casGCIntervalEnvKey
+
+	casGCGracePeriodFlagName  = "cas-gc-grace-period"
+	casGCGracePeriodEnvKey    = "CAS_GC_GRACE_PERIOD"
+	casGCGracePeriodFlagUsage = "CAS content that was written within this period is never collected. " +
+		"Defaults to 24h. " + commonEnvVarUsageText + casGCGracePeriodEnvKey
+
+	casGCDryRunFlagName  = "cas-gc-dry-run"
+	casGCDryRunEnvKey    = "CAS_GC_DRY_RUN"
+	casGCDryRunFlagUsage = "If true then the CAS garbage collector only reports unreachable content and doesn't " +
+		"delete it. The report of the last run is available at the /cas-gc endpoint. Defaults to false. " + commonEnvVarUsageText + casGCDryRunEnvKey
+
+	casResolveHedgedEnabledFlagName  = "cas-resolve-hedged-enabled"
+	casResolveHedgedEnabledEnvKey    = "CAS_RESOLVE_HEDGED_ENABLED"
+	casResolveHedgedEnabledFlagUsage = "If true then content that isn't in the local CAS is requested in parallel " +
+		"from all candidate sources (WebCAS links, IPFS links and the domain hint) using hedged requests. The first " +
+		"verified response wins and all other requests are cancelled. If false then the sources are tried " +
+		"sequentially. Defaults to false. " + commonEnvVarUsageText + casResolveHedgedEnabledEnvKey
+
+	casResolveHedgeDelayFlagName  = "cas-resolve-hedge-delay"
+	casResolveHedgeDelayEnvKey    = "CAS_RESOLVE_HEDGE_DELAY"
+	casResolveHedgeDelayFlagUsage = "The time to wait for a response from a CAS source before also requesting the " +
+		"content from the next source. If 0 then all sources are requested at once. This parameter only applies " +
+		"if hedged CAS resolution is enabled. Defaults to 500ms. " + commonEnvVarUsageText + casResolveHedgeDelayEnvKey
+
+	casResolveCircuitBreakerThresholdFlagName  = "cas-resolve-circuit-breaker-threshold"
+	casResolveCircuitBreakerThresholdEnvKey    = "CAS_RESOLVE_CIRCUIT_BREAKER_THRESHOLD"
+	casResolveCircuitBreakerThresholdFlagUsage = "The number of consecutive failures after which requests to a " +
+		"WebCAS endpoint are skipped (i.e. the circuit is opened). If 0 then the circuit breaker is disabled. " +
+		"Defaults to 0. " + commonEnvVarUsageText + casResolveCircuitBreakerThresholdEnvKey
+
+	casResolveCircuitBreakerOpenDurationFlagName  = "cas-resolve-circuit-breaker-open-duration"
+	casResolveCircuitBreakerOpenDurationEnvKey    = "CAS_RESOLVE_CIRCUIT_BREAKER_OPEN_DURATION"
+	casResolveCircuitBreakerOpenDurationFlagUsage = "The amount of time that requests to a failing WebCAS endpoint " +
+		"are skipped before the endpoint is tried again. Defaults to 1m. " +
+		commonEnvVarUsageText + casResolveCircuitBreakerOpenDurationEnvKey
+
+	cacheTypeFlagName  = "cache-type"
+	cacheTypeEnvKey    = "CACHE_TYPE"
+	cacheTypeFlagUsage = "The type of cache used for ActivityPub actors and IRIs, WebFinger resources, host-meta " +
+		"documents, public keys and the witness policy. Possible values are 'memory' and 'redis'. If 'memory' then " +
+		"each instance has its own in-memory cache. If 'redis' then the caches are shared across instances using " +
+		"a Redis (or Redis-compatible) server and invalidations (such as witness policy updates) are propagated " +
+		"to all instances. Defaults to 'memory'. " + commonEnvVarUsageText + cacheTypeEnvKey
+
+	cacheRedisURLFlagName  = "cache-redis-url"
+	cacheRedisURLEnvKey    = "CACHE_REDIS_URL"
+	cacheRedisURLFlagUsage = "The URL of the Redis server in the format redis://[:password@]host:port. " +
+		"This parameter is required if cache-type is 'redis'. " + commonEnvVarUsageText + cacheRedisURLEnvKey
 
 	ipfsURLFlagName      = "ipfs-url"
 	ipfsURLFlagShorthand = "r"
@@ -286,7 +422,8 @@ const (
 	mqURLFlagName      = "mq-url"
 	mqURLFlagShorthand = "q"
 	mqURLEnvKey        = "MQ_URL"
-	mqURLFlagUsage     = "The URL of the message broker. " + commonEnvVarUsageText + mqURLEnvKey
+	mqURLFlagUsage     = "The URL of the message broker. An AMQP URL (amqp://...) selects RabbitMQ and a " +
+		"NATS URL (nats://...) selects NATS JetStream. " + commonEnvVarUsageText + mqURLEnvKey
 
 	mqObserverPoolFlagName      = "mq-observer-pool"
 	mqObserverPoolFlagShorthand = "B"
@@ -349,6 +486,15 @@ const (
 	mqRedeliveryMaxIntervalFlagUsage = "The maximum delay for a redelivery (default is 1m). " +
 		commonEnvVarUsageText + mqRedeliveryMaxIntervalEnvKey
 
+	mqEmbeddedDurableEnabledFlagName  = "mq-embedded-durable-enabled"
+	mqEmbeddedDurableEnabledEnvKey    = "MQ_EMBEDDED_DURABLE_ENABLED"
+	mqEmbeddedDurableEnabledFlagUsage = "If enabled and no MQ URL is specified then an embedded publisher/subscriber " +
+		"is used which persists messages in the configured database so that queued messages survive a restart. " +
+		"This option is intended for single-node deployments and may not be used with the mem database type. " +
+		"If disabled then messages are queued in memory. " +
+		"Supported options: false, true. Defaults to false if not set. " +
+		commonEnvVarUsageText + mqEmbeddedDurableEnabledEnvKey
+
 	opQueuePoolFlagName      = "op-queue-pool"
 	opQueuePoolFlagShorthand = "O"
 	opQueuePoolEnvKey        = "OP_QUEUE_POOL"
@@ -402,6 +548,29 @@ const (
 		"Currently this setting only applies if you're using MongoDB. " +
 		commonEnvVarUsageText + databaseTimeoutEnvKey
 
+	databaseEncryptionEnabledFlagName  = "database-encryption-enabled"
+	databaseEncryptionEnabledEnvKey    = "DATABASE_ENCRYPTION_ENABLED"
+	databaseEncryptionEnabledFlagUsage = "Set to true to encrypt the values of sensitive stores (config, " +
+		"unpublished operation and activity stores) at rest. Values are encrypted with data keys that are " +
+		"wrapped by a key from the configured KMS. Tags (indexed fields) are not encrypted. Defaults to false. " +
+		commonEnvVarUsageText + databaseEncryptionEnabledEnvKey
+
+	databaseEncryptionKeyIDFlagName  = "database-encryption-key-id"
+	databaseEncryptionKeyIDEnvKey    = "DATABASE_ENCRYPTION_KEY_ID"
+	databaseEncryptionKeyIDFlagUsage = "The ID of the KMS key which is used to wrap the database encryption keys. " +
+		"This parameter is required for the AWS KMS. If not set for the local or web KMS then a key is generated. " +
+		"In order to rotate the key, set this parameter to the ID of a new key (the previous key must still exist " +
+		"in the KMS on the first startup after rotation). " +
+		commonEnvVarUsageText + databaseEncryptionKeyIDEnvKey
+
+	databaseEncryptionRotateDataKeyFlagName  = "database-encryption-rotate-data-key"
+	databaseEncryptionRotateDataKeyEnvKey    = "DATABASE_ENCRYPTION_ROTATE_DATA_KEY"
+	databaseEncryptionRotateDataKeyFlagUsage = "Set to true to generate a new data encryption key on startup. " +
+		"The new key is used for all subsequent writes by this instance and values encrypted with previous " +
+		"data keys may still be decrypted. Other instances continue to encrypt with their current data key " +
+		"until they are restarted. Defaults to false. " +
+		commonEnvVarUsageText + databaseEncryptionRotateDataKeyEnvKey
+
 	databaseTypeMemOption     = "mem"
 	databaseTypeCouchDBOption = "couchdb"
 	databaseTypeMongoDBOption = "mongodb"
@@ -448,11 +617,13 @@ const (
 
 	discoveryDomainsFlagName  = "discovery-domains"
 	discoveryDomainsEnvKey    = "DISCOVERY_DOMAINS"
-	discoveryDomainsFlagUsage = "Discovery domains. " + commonEnvVarUsageText + discoveryDomainsEnvKey
+	discoveryDomainsFlagUsage = "Discovery domains. These domains are also queried when a DID is resolved in " +
+		"consensus mode (with the 'consensus=true' resolution option). " + commonEnvVarUsageText + discoveryDomainsEnvKey
 
 	discoveryMinimumResolversFlagName  = "discovery-minimum-resolvers"
 	discoveryMinimumResolversEnvKey    = "DISCOVERY_MINIMUM_RESOLVERS"
-	discoveryMinimumResolversFlagUsage = "Discovery minimum resolvers number." +
+	discoveryMinimumResolversFlagUsage = "Discovery minimum resolvers number. This is also the minimum number of " +
+		"domains (including this one) which must return matching results when a DID is resolved in consensus mode. " +
 		commonEnvVarUsageText + discoveryMinimumResolversEnvKey
 
 	httpSignaturesEnabledFlagName  = "enable-http-signatures"
@@ -498,6 +669,34 @@ const (
 	verifyLatestFromAnchorOriginUsage    = `Set to "true" to verify latest operations against anchor origin. ` +
 		commonEnvVarUsageText + verifyLatestFromAnchorOriginEnvKey
 
+	didResolutionStrictFlagName  = "did-resolution-strict"
+	didResolutionStrictEnvKey    = "DID_RESOLUTION_STRICT"
+	didResolutionStrictFlagUsage = "If enabled then DID resolution strictly conforms to the W3C DID Resolution " +
+		"HTTP(S) binding, i.e. a request for application/did+ld+json returns the DID document only and the " +
+		"resolution of a deactivated DID returns status 410 (Gone). If disabled then a request for " +
+		"application/did+ld+json returns the resolution result and a deactivated DID returns status 200, " +
+		"which is what the Orb VDR of previous releases expects. " +
+		"Supported options: false, true. Defaults to false if not set. " +
+		commonEnvVarUsageText + didResolutionStrictEnvKey
+
+	resolutionCacheEnabledFlagName  = "resolution-cache-enabled"
+	resolutionCacheEnabledEnvKey    = "RESOLUTION_CACHE_ENABLED"
+	resolutionCacheEnabledFlagUsage = "If enabled then the result of resolving a DID from its operations is cached. " +
+		"A cached result is invalidated when an anchor containing the DID is processed or an unpublished operation " +
+		"for the DID is added (or expires). The invalidation is published to all instances over the message queue. " +
+		"Supported options: false, true. Defaults to false if not set. " +
+		commonEnvVarUsageText + resolutionCacheEnabledEnvKey
+
+	resolutionCacheSizeFlagName  = "resolution-cache-size"
+	resolutionCacheSizeEnvKey    = "RESOLUTION_CACHE_SIZE"
+	resolutionCacheSizeFlagUsage = "The maximum number of DID resolution results held in the resolution cache. " +
+		"Defaults to 1000 if not set. " + commonEnvVarUsageText + resolutionCacheSizeEnvKey
+
+	resolutionCacheExpirationFlagName  = "resolution-cache-expiration"
+	resolutionCacheExpirationEnvKey    = "RESOLUTION_CACHE_EXPIRATION"
+	resolutionCacheExpirationFlagUsage = "The expiration time of a DID resolution result in the resolution cache. " +
+		"Defaults to 1m if not set. " + commonEnvVarUsageText + resolutionCacheExpirationEnvKey
+
 	authTokensDefFlagName      = "auth-tokens-def"
 	authTokensDefFlagShorthand = "D"
 	authTokensDefFlagUsage     = "Authorization token definitions."
@@ -645,9 +844,13 @@ const (
 	dataURIMediaTypeFlagName  = "anchor-data-uri-media-type"
 	dataURIMediaTypeEnvKey    = "ANCHOR_DATA_URI_MEDIA_TYPE"
 	dataURIMediaTypeFlagUsage = "The media type for data URIs in an anchor Linkset. Possible values are " +
-		"'application/json' and 'application/gzip;base64'. If 'application/json' is specified then the content of the data URIs " +
-		"in the anchor LInkset are encoded as an escaped JSON string. If 'application/gzip;base64' is specified then the content is " +
-		"compressed with gzip and base64 encoded (default is 'application/gzip;base64')." +
+		"'application/json', 'application/gzip;base64', 'application/zstd;base64', 'application/cbor;base64' and " +
+		"'application/cbor+zstd;base64'. If 'application/json' is specified then the content of the data URIs " +
+		"in the anchor LInkset are encoded as an escaped JSON string. If 'application/gzip;base64' or 'application/zstd;base64' " +
+		"is specified then the content is compressed with gzip or zstd, respectively, and base64 encoded. If " +
+		"'application/cbor;base64' is specified then the content is encoded as CBOR and base64 encoded. If " +
+		"'application/cbor+zstd;base64' is specified then the content is encoded as CBOR, compressed with zstd and " +
+		"base64 encoded (default is 'application/gzip;base64')." +
 		commonEnvVarUsageText + dataURIMediaTypeEnvKey
 
 	sidetreeProtocolVersionsFlagName = "sidetree-protocol-versions"
@@ -700,6 +903,10 @@ type orbParameters struct {
 	dataURIMediaType                        datauri.MediaType
 	batchWriterTimeout                      time.Duration
 	casType                                 string
+	casS3Params                             *casS3Params
+	casGCParams                             *casGCParams
+	casResolveParams                        *casResolveParams
+	cacheParams                             *cacheParams
 	ipfsURL                                 string
 	localCASReplicateInIPFSEnabled          bool
 	cidVersion                              int
@@ -720,6 +927,7 @@ type orbParameters struct {
 	maxClockSkew                            time.Duration
 	witnessStoreExpiryPeriod                time.Duration
 	proofMonitoringExpiryPeriod             time.Duration
+	proofMonitoringFailedRetentionPeriod    time.Duration
 	syncTimeout                             uint64
 	signWithLocalWitness                    bool
 	httpSignaturesEnabled                   bool
@@ -730,6 +938,10 @@ type orbParameters struct {
 	includePublishedOperations              bool
 	resolveFromAnchorOrigin                 bool
 	verifyLatestFromAnchorOrigin            bool
+	didResolutionStrict                     bool
+	resolutionCacheEnabled                  bool
+	resolutionCacheSize                     int
+	resolutionCacheExpiration               time.Duration
 	authTokenDefinitions                    []*auth.TokenDef
 	authTokens                              map[string]string
 	clientAuthTokenDefinitions              []*auth.TokenDef
@@ -757,6 +969,7 @@ type orbParameters struct {
 	vctLogMonitoringTreeSize                uint64
 	vctLogMonitoringGetEntriesRange         int
 	vctLogEntriesStoreEnabled               bool
+	logEntryRetention                       *logEntryRetentionParams
 	anchorStatusMonitoringInterval          time.Duration
 	anchorStatusInProcessGracePeriod        time.Duration
 	apClientCacheSize                       int
@@ -793,9 +1006,12 @@ type anchorCredentialParams struct {
 }
 
 type dbParameters struct {
-	databaseType   string
-	databaseURL    string
-	databasePrefix string
+	databaseType                    string
+	databaseURL                     string
+	databasePrefix                  string
+	databaseEncryptionEnabled       bool
+	databaseEncryptionKeyID         string
+	databaseEncryptionRotateDataKey bool
 }
 
 type kmsParameters struct {
@@ -956,6 +1172,26 @@ func getOrbParameters(cmd *cobra.Command) (*orbParameters, error) {
 			"change the CAS type to local")
 	}
 
+	casS3Params, err := getCASS3Parameters(cmd, casType)
+	if err != nil {
+		return nil, err
+	}
+
+	casGCParams, err := getCASGCParameters(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	casResolveParams, err := getCASResolveParameters(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheParams, err := getCacheParameters(cmd)
+	if err != nil {
+		return nil, err
+	}
+
 	localCASReplicateInIPFSEnabledString, err := cmdutil.GetUserSetVarFromString(cmd, localCASReplicateInIPFSFlagName,
 		localCASReplicateInIPFSEnvKey, true)
 	if err != nil {
@@ -1205,6 +1441,28 @@ func getOrbParameters(cmd *cobra.Command) (*orbParameters, error) {
 		verifyLatestFromAnchorOrigin = enable
 	}
 
+	didResolutionStrict, err := getBool(cmd, didResolutionStrictFlagName, didResolutionStrictEnvKey, false)
+	if err != nil {
+		return nil, err
+	}
+
+	resolutionCacheEnabled, err := getBool(cmd, resolutionCacheEnabledFlagName, resolutionCacheEnabledEnvKey, false)
+	if err != nil {
+		return nil, err
+	}
+
+	resolutionCacheSize, err := getInt(cmd, resolutionCacheSizeFlagName, resolutionCacheSizeEnvKey,
+		defaultResolutionCacheSize)
+	if err != nil {
+		return nil, err
+	}
+
+	resolutionCacheExpiration, err := getDuration(cmd, resolutionCacheExpirationFlagName,
+		resolutionCacheExpirationEnvKey, defaultResolutionCacheExpiration)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", resolutionCacheExpirationFlagName, err)
+	}
+
 	didNamespace, err := cmdutil.GetUserSetVarFromString(cmd, didNamespaceFlagName, didNamespaceEnvKey, false)
 	if err != nil {
 		return nil, err
@@ -1222,6 +1480,11 @@ func getOrbParameters(cmd *cobra.Command) (*orbParameters, error) {
 		return nil, err
 	}
 
+	if mqParams.embeddedDurableEnabled && mqParams.endpoint == "" && dbParams.databaseType == databaseTypeMemOption {
+		return nil, fmt.Errorf("%s may not be enabled with database type [%s] since messages would not "+
+			"survive a restart", mqEmbeddedDurableEnabledFlagName, databaseTypeMemOption)
+	}
+
 	loggingLevel, err := cmdutil.GetUserSetVarFromString(cmd, LogLevelFlagName, LogLevelEnvKey, true)
 	if err != nil {
 		return nil, err
@@ -1261,6 +1524,8 @@ func getOrbParameters(cmd *cobra.Command) (*orbParameters, error) {
 
 	if dataURIMediaType == "" {
 		dataURIMediaType = defaultDataURIMediaType
+	} else if !datauri.IsSupportedMediaType(dataURIMediaType) {
+		return nil, fmt.Errorf("unsupported value for %s [%s]", dataURIMediaTypeFlagName, dataURIMediaType)
 	}
 
 	discoveryDomains := cmdutil.GetUserSetOptionalVarFromArrayString(cmd, discoveryDomainsFlagName, discoveryDomainsEnvKey)
@@ -1386,6 +1651,12 @@ func getOrbParameters(cmd *cobra.Command) (*orbParameters, error) {
 		return nil, fmt.Errorf("%s: %w", vctProofMonitoringExpiryPeriodFlagName, err)
 	}
 
+	proofMonitoringFailedRetentionPeriod, err := getDuration(cmd, vctProofMonitoringFailedRetentionFlagName,
+		vctProofMonitoringFailedRetentionEnvKey, defaultVCTProofMonitoringRetention)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", vctProofMonitoringFailedRetentionFlagName, err)
+	}
+
 	vctLogMonitoringInterval, err := getDuration(cmd, vctLogMonitoringIntervalFlagName, vctLogMonitoringIntervalEnvKey,
 		defaultVCTLogMonitoringInterval)
 	if err != nil {
@@ -1431,6 +1702,11 @@ func getOrbParameters(cmd *cobra.Command) (*orbParameters, error) {
 		vctLogEntriesStoreEnabled = enable
 	}
 
+	logEntryRetention, err := getLogEntryRetentionParameters(cmd)
+	if err != nil {
+		return nil, err
+	}
+
 	anchorStatusMonitoringInterval, err := getDuration(cmd, anchorStatusMonitoringIntervalFlagName, anchorStatusMonitoringIntervalEnvKey,
 		defaultAnchorStatusMonitoringInterval)
 	if err != nil {
@@ -1492,6 +1768,10 @@ func getOrbParameters(cmd *cobra.Command) (*orbParameters, error) {
 		allowedOriginsCacheExpiration:           allowedOriginsCacheExpiration,
 		allowedDIDWebDomains:                    allowedDIDWebDomains,
 		casType:                                 casType,
+		casS3Params:                             casS3Params,
+		casGCParams:                             casGCParams,
+		casResolveParams:                        casResolveParams,
+		cacheParams:                             cacheParams,
 		ipfsURL:                                 ipfsURL,
 		localCASReplicateInIPFSEnabled:          localCASReplicateInIPFSEnabled,
 		cidVersion:                              cidVersion,
@@ -1507,6 +1787,7 @@ func getOrbParameters(cmd *cobra.Command) (*orbParameters, error) {
 		maxClockSkew:                            maxClockSkew,
 		witnessStoreExpiryPeriod:                witnessStoreExpiryPeriod,
 		proofMonitoringExpiryPeriod:             proofMonitoringExpiryPeriod,
+		proofMonitoringFailedRetentionPeriod:    proofMonitoringFailedRetentionPeriod,
 		syncTimeout:                             syncTimeout,
 		signWithLocalWitness:                    signWithLocalWitness,
 		httpSignaturesEnabled:                   httpSignaturesEnabled,
@@ -1517,6 +1798,10 @@ func getOrbParameters(cmd *cobra.Command) (*orbParameters, error) {
 		includeUnpublishedOperations:            includeUnpublishedOperations,
 		resolveFromAnchorOrigin:                 resolveFromAnchorOrigin,
 		verifyLatestFromAnchorOrigin:            verifyLatestFromAnchorOrigin,
+		didResolutionStrict:                     didResolutionStrict,
+		resolutionCacheEnabled:                  resolutionCacheEnabled,
+		resolutionCacheSize:                     resolutionCacheSize,
+		resolutionCacheExpiration:               resolutionCacheExpiration,
 		authTokenDefinitions:                    authTokenDefs,
 		authTokens:                              authTokens,
 		clientAuthTokenDefinitions:              clientAuthTokenDefs,
@@ -1542,6 +1827,7 @@ func getOrbParameters(cmd *cobra.Command) (*orbParameters, error) {
 		vctLogMonitoringTreeSize:                vctLogMonitoringMaxTreeSize,
 		vctLogMonitoringGetEntriesRange:         vctLogMonitoringGetEntriesRange,
 		vctLogEntriesStoreEnabled:               vctLogEntriesStoreEnabled,
+		logEntryRetention:                       logEntryRetention,
 		anchorStatusMonitoringInterval:          anchorStatusMonitoringInterval,
 		anchorStatusInProcessGracePeriod:        anchorStatusInProcessGracePeriod,
 		witnessPolicyCacheExpiration:            witnessPolicyCacheExpiration,
@@ -1628,10 +1914,28 @@ func getDBParameters(cmd *cobra.Command) (*dbParameters, error) {
 		return nil, err
 	}
 
+	databaseEncryptionEnabled, err := getBool(cmd, databaseEncryptionEnabledFlagName,
+		databaseEncryptionEnabledEnvKey, false)
+	if err != nil {
+		return nil, err
+	}
+
+	databaseEncryptionKeyID := cmdutil.GetUserSetOptionalVarFromString(cmd, databaseEncryptionKeyIDFlagName,
+		databaseEncryptionKeyIDEnvKey)
+
+	databaseEncryptionRotateDataKey, err := getBool(cmd, databaseEncryptionRotateDataKeyFlagName,
+		databaseEncryptionRotateDataKeyEnvKey, false)
+	if err != nil {
+		return nil, err
+	}
+
 	return &dbParameters{
-		databaseType:   databaseType,
-		databaseURL:    databaseURL,
-		databasePrefix: databasePrefix,
+		databaseType:                    databaseType,
+		databaseURL:                     databaseURL,
+		databasePrefix:                  databasePrefix,
+		databaseEncryptionEnabled:       databaseEncryptionEnabled,
+		databaseEncryptionKeyID:         databaseEncryptionKeyID,
+		databaseEncryptionRotateDataKey: databaseEncryptionRotateDataKey,
 	}, nil
 }
 
@@ -1853,6 +2157,7 @@ type mqParams struct {
 	redeliveryMultiplier      float64
 	redeliveryInitialInterval time.Duration
 	maxRedeliveryInterval     time.Duration
+	embeddedDurableEnabled    bool
 }
 
 func getMQParameters(cmd *cobra.Command) (*mqParams, error) {
@@ -1924,6 +2229,12 @@ func getMQParameters(cmd *cobra.Command) (*mqParams, error) {
 		return nil, err
 	}
 
+	mqEmbeddedDurableEnabled, err := getBool(cmd, mqEmbeddedDurableEnabledFlagName,
+		mqEmbeddedDurableEnabledEnvKey, false)
+	if err != nil {
+		return nil, err
+	}
+
 	return &mqParams{
 		endpoint:                  mqURL,
 		observerPoolSize:          mqObserverPoolSize,
@@ -1937,6 +2248,270 @@ func getMQParameters(cmd *cobra.Command) (*mqParams, error) {
 		redeliveryMultiplier:      mqRedeliveryMultiplier,
 		redeliveryInitialInterval: mqRedeliveryInitialInterval,
 		maxRedeliveryInterval:     mqRedeliveryMaxInterval,
+		embeddedDurableEnabled:    mqEmbeddedDurableEnabled,
+	}, nil
+}
+
+const (
+	logEntryArchiveTypeNone = "none"
+	logEntryArchiveTypeFile = "file"
+	logEntryArchiveTypeCAS  = "cas"
+)
+
+type logEntryRetentionParams struct {
+	defaultPolicy      logentry.RetentionPolicy
+	logPolicies        map[string]logentry.RetentionPolicy
+	archiveType        string
+	archiveDir         string
+	compactionInterval time.Duration
+}
+
+func getLogEntryRetentionParameters(cmd *cobra.Command) (*logEntryRetentionParams, error) {
+	maxEntries, err := getInt(cmd, vctLogEntriesMaxEntriesFlagName, vctLogEntriesMaxEntriesEnvKey, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", vctLogEntriesMaxEntriesFlagName, err)
+	}
+
+	maxAge, err := getDuration(cmd, vctLogEntriesMaxAgeFlagName, vctLogEntriesMaxAgeEnvKey, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", vctLogEntriesMaxAgeFlagName, err)
+	}
+
+	logPolicies, err := getLogEntryRetentionPolicies(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	archiveType := cmdutil.GetUserSetOptionalVarFromString(cmd, vctLogEntriesArchiveTypeFlagName,
+		vctLogEntriesArchiveTypeEnvKey)
+
+	archiveDir := cmdutil.GetUserSetOptionalVarFromString(cmd, vctLogEntriesArchiveDirFlagName,
+		vctLogEntriesArchiveDirEnvKey)
+
+	switch strings.ToLower(archiveType) {
+	case "", logEntryArchiveTypeNone, logEntryArchiveTypeCAS:
+	case logEntryArchiveTypeFile:
+		if archiveDir == "" {
+			return nil, fmt.Errorf("%s is required when %s is '%s'", vctLogEntriesArchiveDirFlagName,
+				vctLogEntriesArchiveTypeFlagName, logEntryArchiveTypeFile)
+		}
+	default:
+		return nil, fmt.Errorf("%s: unsupported archive type [%s]", vctLogEntriesArchiveTypeFlagName, archiveType)
+	}
+
+	compactionInterval, err := getDuration(cmd, vctLogEntriesCompactionIntervalFlagName,
+		vctLogEntriesCompactionIntervalEnvKey, defaultVCTLogEntriesCompactionInterval)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", vctLogEntriesCompactionIntervalFlagName, err)
+	}
+
+	return &logEntryRetentionParams{
+		defaultPolicy: logentry.RetentionPolicy{
+			MaxEntries: maxEntries,
+			MaxAge:     maxAge,
+		},
+		logPolicies:        logPolicies,
+		archiveType:        strings.ToLower(archiveType),
+		archiveDir:         archiveDir,
+		compactionInterval: compactionInterval,
+	}, nil
+}
+
+func getLogEntryRetentionPolicies(cmd *cobra.Command) (map[string]logentry.RetentionPolicy, error) {
+	policiesStr := cmdutil.GetUserSetOptionalVarFromArrayString(cmd, vctLogEntriesRetentionPolicyFlagName,
+		vctLogEntriesRetentionPolicyEnvKey)
+
+	policies := make(map[string]logentry.RetentionPolicy)
+
+	for _, policyStr := range policiesStr {
+		// The log URL may contain '=' so split on the last occurrence.
+		i := strings.LastIndex(policyStr, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("%s: invalid retention policy [%s]", vctLogEntriesRetentionPolicyFlagName, policyStr)
+		}
+
+		policyParts := strings.Split(policyStr[i+1:], ":")
+		if len(policyParts) != 2 {
+			return nil, fmt.Errorf("%s: invalid retention policy [%s]", vctLogEntriesRetentionPolicyFlagName, policyStr)
+		}
+
+		policy := logentry.RetentionPolicy{}
+
+		if policyParts[0] != "" {
+			maxEntries, err := strconv.Atoi(policyParts[0])
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid max entries in retention policy [%s]: %w",
+					vctLogEntriesRetentionPolicyFlagName, policyStr, err)
+			}
+
+			policy.MaxEntries = maxEntries
+		}
+
+		if policyParts[1] != "" {
+			maxAge, err := time.ParseDuration(policyParts[1])
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid max age in retention policy [%s]: %w",
+					vctLogEntriesRetentionPolicyFlagName, policyStr, err)
+			}
+
+			policy.MaxAge = maxAge
+		}
+
+		policies[policyStr[:i]] = policy
+	}
+
+	return policies, nil
+}
+
+type casS3Params struct {
+	endpoint       string
+	region         string
+	bucket         string
+	prefix         string
+	forcePathStyle bool
+}
+
+func getCASS3Parameters(cmd *cobra.Command, casType string) (*casS3Params, error) {
+	bucket := cmdutil.GetUserSetOptionalVarFromString(cmd, casS3BucketFlagName, casS3BucketEnvKey)
+
+	if bucket == "" && strings.EqualFold(casType, "s3") {
+		return nil, fmt.Errorf("%s is required when %s is 's3'", casS3BucketFlagName, casTypeFlagName)
+	}
+
+	forcePathStyle, err := getBool(cmd, casS3ForcePathStyleFlagName, casS3ForcePathStyleEnvKey, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &casS3Params{
+		endpoint:       cmdutil.GetUserSetOptionalVarFromString(cmd, casS3EndpointFlagName, casS3EndpointEnvKey),
+		region:         cmdutil.GetUserSetOptionalVarFromString(cmd, casS3RegionFlagName, casS3RegionEnvKey),
+		bucket:         bucket,
+		prefix:         cmdutil.GetUserSetOptionalVarFromString(cmd, casS3PrefixFlagName, casS3PrefixEnvKey),
+		forcePathStyle: forcePathStyle,
+	}, nil
+}
+
+type casGCParams struct {
+	enabled     bool
+	interval    time.Duration
+	gracePeriod time.Duration
+	dryRun      bool
+}
+
+func getCASGCParameters(cmd *cobra.Command) (*casGCParams, error) {
+	enabled, err := getBool(cmd, casGCEnabledFlagName, casGCEnabledEnvKey, false)
+	if err != nil {
+		return nil, err
+	}
+
+	interval, err := getDuration(cmd, casGCIntervalFlagName, casGCIntervalEnvKey, defaultCASGCInterval)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", casGCIntervalFlagName, err)
+	}
+
+	gracePeriod, err := getDuration(cmd, casGCGracePeriodFlagName, casGCGracePeriodEnvKey, defaultCASGCGracePeriod)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", casGCGracePeriodFlagName, err)
+	}
+
+	dryRun, err := getBool(cmd, casGCDryRunFlagName, casGCDryRunEnvKey, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &casGCParams{
+		enabled:     enabled,
+		interval:    interval,
+		gracePeriod: gracePeriod,
+		dryRun:      dryRun,
+	}, nil
+}
+
+type casResolveParams struct {
+	hedgedEnabled              bool
+	hedgeDelay                 time.Duration
+	circuitBreakerThreshold    int
+	circuitBreakerOpenDuration time.Duration
+}
+
+func getCASResolveParameters(cmd *cobra.Command) (*casResolveParams, error) {
+	hedgedEnabled, err := getBool(cmd, casResolveHedgedEnabledFlagName, casResolveHedgedEnabledEnvKey, false)
+	if err != nil {
+		return nil, err
+	}
+
+	hedgeDelay, err := getDuration(cmd, casResolveHedgeDelayFlagName, casResolveHedgeDelayEnvKey,
+		defaultCASResolveHedgeDelay)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", casResolveHedgeDelayFlagName, err)
+	}
+
+	circuitBreakerThreshold, err := getInt(cmd, casResolveCircuitBreakerThresholdFlagName,
+		casResolveCircuitBreakerThresholdEnvKey, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	circuitBreakerOpenDuration, err := getDuration(cmd, casResolveCircuitBreakerOpenDurationFlagName,
+		casResolveCircuitBreakerOpenDurationEnvKey, defaultCASCircuitBreakerOpenDuration)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", casResolveCircuitBreakerOpenDurationFlagName, err)
+	}
+
+	return &casResolveParams{
+		hedgedEnabled:              hedgedEnabled,
+		hedgeDelay:                 hedgeDelay,
+		circuitBreakerThreshold:    circuitBreakerThreshold,
+		circuitBreakerOpenDuration: circuitBreakerOpenDuration,
+	}, nil
+}
+
+const (
+	cacheTypeMemory = "memory"
+	cacheTypeRedis  = "redis"
+)
+
+type cacheParams struct {
+	cacheType     string
+	redisAddress  string
+	redisPassword string
+}
+
+func getCacheParameters(cmd *cobra.Command) (*cacheParams, error) {
+	cacheType := cmdutil.GetUserSetOptionalVarFromString(cmd, cacheTypeFlagName, cacheTypeEnvKey)
+
+	switch cacheType {
+	case "", cacheTypeMemory:
+		return &cacheParams{cacheType: cacheTypeMemory}, nil
+	case cacheTypeRedis:
+	default:
+		return nil, fmt.Errorf("unsupported value for %s [%s]", cacheTypeFlagName, cacheType)
+	}
+
+	redisURL, err := cmdutil.GetUserSetVarFromString(cmd, cacheRedisURLFlagName, cacheRedisURLEnvKey, false)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", cacheRedisURLFlagName, err)
+	}
+
+	if u.Scheme != "redis" || u.Host == "" {
+		return nil, fmt.Errorf("%s: invalid Redis URL [%s]", cacheRedisURLFlagName, u.Redacted())
+	}
+
+	password, ok := u.User.Password()
+	if !ok {
+		password = u.User.Username()
+	}
+
+	return &cacheParams{
+		cacheType:     cacheTypeRedis,
+		redisAddress:  u.Host,
+		redisPassword: password,
 	}, nil
 }
 
@@ -2229,7 +2804,27 @@ func createFlags(startCmd *cobra.Command) {
 	startCmd.Flags().String(includePublishedOperationsFlagName, "", includePublishedOperationsUsage)
 	startCmd.Flags().String(resolveFromAnchorOriginFlagName, "", resolveFromAnchorOriginUsage)
 	startCmd.Flags().String(verifyLatestFromAnchorOriginFlagName, "", verifyLatestFromAnchorOriginUsage)
+	startCmd.Flags().String(didResolutionStrictFlagName, "false", didResolutionStrictFlagUsage)
+	startCmd.Flags().String(resolutionCacheEnabledFlagName, "false", resolutionCacheEnabledFlagUsage)
+	startCmd.Flags().String(resolutionCacheSizeFlagName, "", resolutionCacheSizeFlagUsage)
+	startCmd.Flags().String(resolutionCacheExpirationFlagName, "", resolutionCacheExpirationFlagUsage)
 	startCmd.Flags().StringP(casTypeFlagName, casTypeFlagShorthand, "", casTypeFlagUsage)
+	startCmd.Flags().String(casS3EndpointFlagName, "", casS3EndpointFlagUsage)
+	startCmd.Flags().String(casS3RegionFlagName, "", casS3RegionFlagUsage)
+	startCmd.Flags().String(casS3BucketFlagName, "", casS3BucketFlagUsage)
+	startCmd.Flags().String(casS3PrefixFlagName, "", casS3PrefixFlagUsage)
+	startCmd.Flags().String(casS3ForcePathStyleFlagName, "", casS3ForcePathStyleFlagUsage)
+	startCmd.Flags().String(casGCEnabledFlagName, "", casGCEnabledFlagUsage)
+	startCmd.Flags().String(casGCIntervalFlagName, "", casGCIntervalFlagUsage)
+	startCmd.Flags().String(casGCGracePeriodFlagName, "", casGCGracePeriodFlagUsage)
+	startCmd.Flags().String(casGCDryRunFlagName, "", casGCDryRunFlagUsage)
+	startCmd.Flags().String(casResolveHedgedEnabledFlagName, "", casResolveHedgedEnabledFlagUsage)
+	startCmd.Flags().String(casResolveHedgeDelayFlagName, "", casResolveHedgeDelayFlagUsage)
+	startCmd.Flags().String(casResolveCircuitBreakerThresholdFlagName, "", casResolveCircuitBreakerThresholdFlagUsage)
+	startCmd.Flags().String(casResolveCircuitBreakerOpenDurationFlagName, "",
+		casResolveCircuitBreakerOpenDurationFlagUsage)
+	startCmd.Flags().String(cacheTypeFlagName, "", cacheTypeFlagUsage)
+	startCmd.Flags().String(cacheRedisURLFlagName, "", cacheRedisURLFlagUsage)
 	startCmd.Flags().StringP(ipfsURLFlagName, ipfsURLFlagShorthand, "", ipfsURLFlagUsage)
 	startCmd.Flags().StringP(localCASReplicateInIPFSFlagName, "", "false", localCASReplicateInIPFSFlagUsage)
 	startCmd.Flags().StringP(mqURLFlagName, mqURLFlagShorthand, "", mqURLFlagUsage)
@@ -2244,6 +2839,7 @@ func createFlags(startCmd *cobra.Command) {
 	startCmd.Flags().StringP(mqRedeliveryInitialIntervalFlagName, "", "", mqRedeliveryInitialIntervalFlagUsage)
 	startCmd.Flags().StringP(mqRedeliveryMultiplierFlagName, "", "", mqRedeliveryMultiplierFlagUsage)
 	startCmd.Flags().StringP(mqRedeliveryMaxIntervalFlagName, "", "", mqRedeliveryMaxIntervalFlagUsage)
+	startCmd.Flags().String(mqEmbeddedDurableEnabledFlagName, "false", mqEmbeddedDurableEnabledFlagUsage)
 	startCmd.Flags().StringP(opQueuePoolFlagName, opQueuePoolFlagShorthand, "", opQueuePoolFlagUsage)
 	startCmd.Flags().StringP(opQueueTaskMonitorIntervalFlagName, "", "", opQueueTaskMonitorIntervalFlagUsage)
 	startCmd.Flags().StringP(opQueueTaskExpirationFlagName, "", "", opQueueTaskExpirationFlagUsage)
@@ -2256,6 +2852,9 @@ func createFlags(startCmd *cobra.Command) {
 	startCmd.Flags().StringP(databaseTypeFlagName, databaseTypeFlagShorthand, "", databaseTypeFlagUsage)
 	startCmd.Flags().StringP(databaseURLFlagName, databaseURLFlagShorthand, "", databaseURLFlagUsage)
 	startCmd.Flags().StringP(databasePrefixFlagName, "", "", databasePrefixFlagUsage)
+	startCmd.Flags().String(databaseEncryptionEnabledFlagName, "false", databaseEncryptionEnabledFlagUsage)
+	startCmd.Flags().String(databaseEncryptionKeyIDFlagName, "", databaseEncryptionKeyIDFlagUsage)
+	startCmd.Flags().String(databaseEncryptionRotateDataKeyFlagName, "false", databaseEncryptionRotateDataKeyFlagUsage)
 	startCmd.Flags().StringP(kmsSecretsDatabaseTypeFlagName, kmsSecretsDatabaseTypeFlagShorthand, "",
 		kmsSecretsDatabaseTypeFlagUsage)
 	startCmd.Flags().StringP(kmsSecretsDatabaseURLFlagName, kmsSecretsDatabaseURLFlagShorthand, "",
@@ -2286,10 +2885,17 @@ func createFlags(startCmd *cobra.Command) {
 	startCmd.Flags().StringP(anchorSyncMinActivityAgeFlagName, "", "", anchorSyncMinActivityAgeFlagUsage)
 	startCmd.Flags().StringP(vctProofMonitoringIntervalFlagName, "", "", vctProofMonitoringIntervalFlagUsage)
 	startCmd.Flags().StringP(vctProofMonitoringExpiryPeriodFlagName, "", "", vctProofMonitoringExpiryPeriodFlagUsage)
+	startCmd.Flags().StringP(vctProofMonitoringFailedRetentionFlagName, "", "", vctProofMonitoringFailedRetentionFlagUsage)
 	startCmd.Flags().StringP(vctLogMonitoringIntervalFlagName, "", "", vctLogMonitoringIntervalFlagUsage)
 	startCmd.Flags().StringP(vctLogMonitoringMaxTreeSizeFlagName, "", "", vctLogMonitoringMaxTreeSizeFlagUsage)
 	startCmd.Flags().StringP(vctLogMonitoringGetEntriesRangeFlagName, "", "", vctLogMonitoringGetEntriesRangeFlagUsage)
 	startCmd.Flags().StringP(vctLogEntriesStoreEnabledFlagName, "", "", vctLogEntriesStoreEnabledFlagUsage)
+	startCmd.Flags().StringP(vctLogEntriesMaxEntriesFlagName, "", "", vctLogEntriesMaxEntriesFlagUsage)
+	startCmd.Flags().StringP(vctLogEntriesMaxAgeFlagName, "", "", vctLogEntriesMaxAgeFlagUsage)
+	startCmd.Flags().StringArrayP(vctLogEntriesRetentionPolicyFlagName, "", []string{}, vctLogEntriesRetentionPolicyFlagUsage)
+	startCmd.Flags().StringP(vctLogEntriesArchiveTypeFlagName, "", "", vctLogEntriesArchiveTypeFlagUsage)
+	startCmd.Flags().StringP(vctLogEntriesArchiveDirFlagName, "", "", vctLogEntriesArchiveDirFlagUsage)
+	startCmd.Flags().StringP(vctLogEntriesCompactionIntervalFlagName, "", "", vctLogEntriesCompactionIntervalFlagUsage)
 	startCmd.Flags().StringP(anchorStatusMonitoringIntervalFlagName, "", "", anchorStatusMonitoringIntervalFlagUsage)
 	startCmd.Flags().StringP(anchorStatusInProcessGracePeriodFlagName, "", "", anchorStatusInProcessGracePeriodFlagUsage)
 	startCmd.Flags().StringP(witnessPolicyCacheExpirationFlagName, "", "", witnessPolicyCacheExpirationFlagUsage)
@@ -501,6 +501,106 @@ func TestStartCmdWithMissingArg(t *testing.T) {
 		require.Contains(t, err.Error(), "invalid value for resolve-from-anchor-origin")
 	})
 
+	t.Run("test invalid did-resolution-strict", func(t *testing.T) {
+		startCmd := GetStartCmd()
+
+		args := []string{
+			"--" + hostURLFlagName, "localhost:8247",
+			"--" + metricsProviderFlagName, "prometheus",
+			"--" + promHttpUrlFlagName, "localhost:8248",
+			"--" + externalEndpointFlagName, "orb.example.com",
+			"--" + casTypeFlagName, "ipfs",
+			"--" + ipfsURLFlagName, "localhost:8081",
+			"--" + didNamespaceFlagName, "namespace", "--" + databaseTypeFlagName, databaseTypeMemOption,
+			"--" + kmsSecretsDatabaseTypeFlagName, databaseTypeMemOption,
+			"--" + anchorCredentialDomainFlagName, "domain.com",
+			"--" + LogLevelFlagName, log.ERROR.String(),
+			"--" + didResolutionStrictFlagName, "invalid bool",
+		}
+
+		startCmd.SetArgs(args)
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid value for did-resolution-strict")
+	})
+
+	t.Run("test invalid resolution-cache-enabled", func(t *testing.T) {
+		startCmd := GetStartCmd()
+
+		args := []string{
+			"--" + hostURLFlagName, "localhost:8247",
+			"--" + metricsProviderFlagName, "prometheus",
+			"--" + promHttpUrlFlagName, "localhost:8248",
+			"--" + externalEndpointFlagName, "orb.example.com",
+			"--" + casTypeFlagName, "ipfs",
+			"--" + ipfsURLFlagName, "localhost:8081",
+			"--" + didNamespaceFlagName, "namespace", "--" + databaseTypeFlagName, databaseTypeMemOption,
+			"--" + kmsSecretsDatabaseTypeFlagName, databaseTypeMemOption,
+			"--" + anchorCredentialDomainFlagName, "domain.com",
+			"--" + LogLevelFlagName, log.ERROR.String(),
+			"--" + resolutionCacheEnabledFlagName, "invalid bool",
+		}
+
+		startCmd.SetArgs(args)
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid value for resolution-cache-enabled")
+	})
+
+	t.Run("test invalid resolution-cache-size", func(t *testing.T) {
+		startCmd := GetStartCmd()
+
+		args := []string{
+			"--" + hostURLFlagName, "localhost:8247",
+			"--" + metricsProviderFlagName, "prometheus",
+			"--" + promHttpUrlFlagName, "localhost:8248",
+			"--" + externalEndpointFlagName, "orb.example.com",
+			"--" + casTypeFlagName, "ipfs",
+			"--" + ipfsURLFlagName, "localhost:8081",
+			"--" + didNamespaceFlagName, "namespace", "--" + databaseTypeFlagName, databaseTypeMemOption,
+			"--" + kmsSecretsDatabaseTypeFlagName, databaseTypeMemOption,
+			"--" + anchorCredentialDomainFlagName, "domain.com",
+			"--" + LogLevelFlagName, log.ERROR.String(),
+			"--" + resolutionCacheSizeFlagName, "invalid int",
+		}
+
+		startCmd.SetArgs(args)
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid value for resolution-cache-size")
+	})
+
+	t.Run("test invalid resolution-cache-expiration", func(t *testing.T) {
+		startCmd := GetStartCmd()
+
+		args := []string{
+			"--" + hostURLFlagName, "localhost:8247",
+			"--" + metricsProviderFlagName, "prometheus",
+			"--" + promHttpUrlFlagName, "localhost:8248",
+			"--" + externalEndpointFlagName, "orb.example.com",
+			"--" + casTypeFlagName, "ipfs",
+			"--" + ipfsURLFlagName, "localhost:8081",
+			"--" + didNamespaceFlagName, "namespace", "--" + databaseTypeFlagName, databaseTypeMemOption,
+			"--" + kmsSecretsDatabaseTypeFlagName, databaseTypeMemOption,
+			"--" + anchorCredentialDomainFlagName, "domain.com",
+			"--" + LogLevelFlagName, log.ERROR.String(),
+			"--" + resolutionCacheExpirationFlagName, "invalid duration",
+		}
+
+		startCmd.SetArgs(args)
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "resolution-cache-expiration")
+	})
+
 	t.Run("test invalid verify-latest-from-anchor-origin", func(t *testing.T) {
 		startCmd := GetStartCmd()
 
@@ -732,6 +832,279 @@ func TestStartCmdWithMissingArg(t *testing.T) {
 		require.Contains(t, err.Error(), "vct-proof-monitoring-expiry-period: invalid value [xxx]")
 	})
 
+	t.Run("VCT proof monitoring failed retention period", func(t *testing.T) {
+		restoreEnv := setEnv(t, vctProofMonitoringFailedRetentionEnvKey, "xxx")
+		defer restoreEnv()
+
+		startCmd := GetStartCmd()
+
+		startCmd.SetArgs(getTestArgs("localhost:8081", "local", "false", databaseTypeMemOption, ""))
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "vct-proof-monitoring-failed-retention-period: invalid value [xxx]")
+	})
+
+	t.Run("VCT log entries max entries", func(t *testing.T) {
+		restoreEnv := setEnv(t, vctLogEntriesMaxEntriesEnvKey, "xxx")
+		defer restoreEnv()
+
+		startCmd := GetStartCmd()
+
+		startCmd.SetArgs(getTestArgs("localhost:8081", "local", "false", databaseTypeMemOption, ""))
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "vct-log-entries-max-entries: invalid value for vct-log-entries-max-entries [xxx]")
+	})
+
+	t.Run("Database encryption enabled", func(t *testing.T) {
+		restoreEnv := setEnv(t, databaseEncryptionEnabledEnvKey, "xxx")
+		defer restoreEnv()
+
+		startCmd := GetStartCmd()
+
+		startCmd.SetArgs(getTestArgs("localhost:8081", "local", "false", databaseTypeMemOption, ""))
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid value for database-encryption-enabled [xxx]")
+	})
+
+	t.Run("Database encryption rotate data key", func(t *testing.T) {
+		restoreEnv := setEnv(t, databaseEncryptionRotateDataKeyEnvKey, "xxx")
+		defer restoreEnv()
+
+		startCmd := GetStartCmd()
+
+		startCmd.SetArgs(getTestArgs("localhost:8081", "local", "false", databaseTypeMemOption, ""))
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid value for database-encryption-rotate-data-key [xxx]")
+	})
+
+	t.Run("MQ embedded durable enabled", func(t *testing.T) {
+		restoreEnv := setEnv(t, mqEmbeddedDurableEnabledEnvKey, "xxx")
+		defer restoreEnv()
+
+		startCmd := GetStartCmd()
+
+		startCmd.SetArgs(getTestArgs("localhost:8081", "local", "false", databaseTypeMemOption, ""))
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid value for mq-embedded-durable-enabled [xxx]")
+	})
+
+	t.Run("MQ embedded durable enabled with mem database", func(t *testing.T) {
+		restoreEnv := setEnv(t, mqEmbeddedDurableEnabledEnvKey, "true")
+		defer restoreEnv()
+
+		startCmd := GetStartCmd()
+
+		startCmd.SetArgs(getTestArgs("localhost:8081", "local", "false", databaseTypeMemOption, ""))
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "mq-embedded-durable-enabled may not be enabled with database type [mem]")
+	})
+
+	t.Run("VCT log entries max age", func(t *testing.T) {
+		restoreEnv := setEnv(t, vctLogEntriesMaxAgeEnvKey, "xxx")
+		defer restoreEnv()
+
+		startCmd := GetStartCmd()
+
+		startCmd.SetArgs(getTestArgs("localhost:8081", "local", "false", databaseTypeMemOption, ""))
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "vct-log-entries-max-age: invalid value [xxx]")
+	})
+
+	t.Run("VCT log entries compaction interval", func(t *testing.T) {
+		restoreEnv := setEnv(t, vctLogEntriesCompactionIntervalEnvKey, "xxx")
+		defer restoreEnv()
+
+		startCmd := GetStartCmd()
+
+		startCmd.SetArgs(getTestArgs("localhost:8081", "local", "false", databaseTypeMemOption, ""))
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "vct-log-entries-compaction-interval: invalid value [xxx]")
+	})
+
+	t.Run("CAS S3 bucket", func(t *testing.T) {
+		startCmd := GetStartCmd()
+
+		startCmd.SetArgs(getTestArgs("localhost:8081", "s3", "false", databaseTypeMemOption, ""))
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "cas-s3-bucket is required when cas-type is 's3'")
+	})
+
+	t.Run("CAS S3 force path style", func(t *testing.T) {
+		restoreBucketEnv := setEnv(t, casS3BucketEnvKey, "orb-cas")
+		defer restoreBucketEnv()
+
+		restoreEnv := setEnv(t, casS3ForcePathStyleEnvKey, "xxx")
+		defer restoreEnv()
+
+		startCmd := GetStartCmd()
+
+		startCmd.SetArgs(getTestArgs("localhost:8081", "s3", "false", databaseTypeMemOption, ""))
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid value for cas-s3-force-path-style [xxx]")
+	})
+
+	t.Run("invalid CAS GC enabled", func(t *testing.T) {
+		restoreEnv := setEnv(t, casGCEnabledEnvKey, "xxx")
+		defer restoreEnv()
+
+		startCmd := GetStartCmd()
+
+		startCmd.SetArgs(getTestArgs("localhost:8081", "local", "false", databaseTypeMemOption, ""))
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid value for cas-gc-enabled [xxx]")
+	})
+
+	t.Run("invalid CAS GC grace period", func(t *testing.T) {
+		restoreEnv := setEnv(t, casGCGracePeriodEnvKey, "xxx")
+		defer restoreEnv()
+
+		startCmd := GetStartCmd()
+
+		startCmd.SetArgs(getTestArgs("localhost:8081", "local", "false", databaseTypeMemOption, ""))
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "cas-gc-grace-period: invalid value [xxx]")
+	})
+
+	t.Run("invalid CAS resolve hedge delay", func(t *testing.T) {
+		restoreEnv := setEnv(t, casResolveHedgeDelayEnvKey, "xxx")
+		defer restoreEnv()
+
+		startCmd := GetStartCmd()
+
+		startCmd.SetArgs(getTestArgs("localhost:8081", "local", "false", databaseTypeMemOption, ""))
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "cas-resolve-hedge-delay: invalid value [xxx]")
+	})
+
+	t.Run("invalid CAS resolve circuit breaker threshold", func(t *testing.T) {
+		restoreEnv := setEnv(t, casResolveCircuitBreakerThresholdEnvKey, "xxx")
+		defer restoreEnv()
+
+		startCmd := GetStartCmd()
+
+		startCmd.SetArgs(getTestArgs("localhost:8081", "local", "false", databaseTypeMemOption, ""))
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "cas-resolve-circuit-breaker-threshold")
+	})
+
+	t.Run("unsupported anchor data URI media type", func(t *testing.T) {
+		restoreEnv := setEnv(t, dataURIMediaTypeEnvKey, "application/xxx")
+		defer restoreEnv()
+
+		startCmd := GetStartCmd()
+
+		startCmd.SetArgs(getTestArgs("localhost:8081", "local", "false", databaseTypeMemOption, ""))
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported value for anchor-data-uri-media-type [application/xxx]")
+	})
+
+	t.Run("VCT log entries archive type", func(t *testing.T) {
+		restoreEnv := setEnv(t, vctLogEntriesArchiveTypeEnvKey, "xxx")
+		defer restoreEnv()
+
+		startCmd := GetStartCmd()
+
+		startCmd.SetArgs(getTestArgs("localhost:8081", "local", "false", databaseTypeMemOption, ""))
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "vct-log-entries-archive-type: unsupported archive type [xxx]")
+	})
+
+	t.Run("VCT log entries archive dir", func(t *testing.T) {
+		restoreEnv := setEnv(t, vctLogEntriesArchiveTypeEnvKey, "file")
+		defer restoreEnv()
+
+		startCmd := GetStartCmd()
+
+		startCmd.SetArgs(getTestArgs("localhost:8081", "local", "false", databaseTypeMemOption, ""))
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "vct-log-entries-archive-dir is required")
+	})
+
+	t.Run("VCT log entries retention policy", func(t *testing.T) {
+		restoreEnv := setEnv(t, vctLogEntriesRetentionPolicyEnvKey, "https://vct.example.com/log")
+		defer restoreEnv()
+
+		startCmd := GetStartCmd()
+
+		startCmd.SetArgs(getTestArgs("localhost:8081", "local", "false", databaseTypeMemOption, ""))
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "vct-log-entries-retention-policy: invalid retention policy")
+	})
+
+	t.Run("VCT log entries retention policy format", func(t *testing.T) {
+		restoreEnv := setEnv(t, vctLogEntriesRetentionPolicyEnvKey, "https://vct.example.com/log=100")
+		defer restoreEnv()
+
+		startCmd := GetStartCmd()
+
+		startCmd.SetArgs(getTestArgs("localhost:8081", "local", "false", databaseTypeMemOption, ""))
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "vct-log-entries-retention-policy: invalid retention policy")
+	})
+
+	t.Run("VCT log entries retention policy max entries", func(t *testing.T) {
+		restoreEnv := setEnv(t, vctLogEntriesRetentionPolicyEnvKey, "https://vct.example.com/log=xxx:1h")
+		defer restoreEnv()
+
+		startCmd := GetStartCmd()
+
+		startCmd.SetArgs(getTestArgs("localhost:8081", "local", "false", databaseTypeMemOption, ""))
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid max entries in retention policy")
+	})
+
+	t.Run("VCT log entries retention policy max age", func(t *testing.T) {
+		restoreEnv := setEnv(t, vctLogEntriesRetentionPolicyEnvKey, "https://vct.example.com/log?a=b=100:xxx")
+		defer restoreEnv()
+
+		startCmd := GetStartCmd()
+
+		startCmd.SetArgs(getTestArgs("localhost:8081", "local", "false", databaseTypeMemOption, ""))
+
+		err := startCmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid max age in retention policy")
+	})
+
 	t.Run("VCT log monitoring interval", func(t *testing.T) {
 		restoreEnv := setEnv(t, vctLogMonitoringIntervalEnvKey, "xxx")
 		defer restoreEnv()
@@ -1158,7 +1531,7 @@ func TestStartCmdWithInvalidCASType(t *testing.T) {
 	startCmd.SetArgs(getTestArgs("localhost:8081", "InvalidName", "false", databaseTypeMemOption, ""))
 
 	err := startCmd.Execute()
-	require.EqualError(t, err, "InvalidName is not a valid CAS type. It must be either local or ipfs")
+	require.EqualError(t, err, "InvalidName is not a valid CAS type. It must be either local, ipfs or s3")
 }
 
 func TestGetActivityPubPageSize(t *testing.T) {
@@ -1672,6 +2045,72 @@ func TestGetActivityPubIRICacheParameters(t *testing.T) {
 	})
 }
 
+func TestGetCacheParameters(t *testing.T) {
+	t.Run("Not specified -> memory", func(t *testing.T) {
+		cmd := getTestCmd(t)
+
+		params, err := getCacheParameters(cmd)
+		require.NoError(t, err)
+		require.Equal(t, cacheTypeMemory, params.cacheType)
+	})
+
+	t.Run("Redis -> success", func(t *testing.T) {
+		restoreTypeEnv := setEnv(t, cacheTypeEnvKey, cacheTypeRedis)
+		restoreURLEnv := setEnv(t, cacheRedisURLEnvKey, "redis://:secret@localhost:6379")
+
+		defer func() {
+			restoreTypeEnv()
+			restoreURLEnv()
+		}()
+
+		cmd := getTestCmd(t)
+
+		params, err := getCacheParameters(cmd)
+		require.NoError(t, err)
+		require.Equal(t, cacheTypeRedis, params.cacheType)
+		require.Equal(t, "localhost:6379", params.redisAddress)
+		require.Equal(t, "secret", params.redisPassword)
+	})
+
+	t.Run("Unsupported cache type -> error", func(t *testing.T) {
+		restoreEnv := setEnv(t, cacheTypeEnvKey, "xxx")
+		defer restoreEnv()
+
+		cmd := getTestCmd(t)
+
+		_, err := getCacheParameters(cmd)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported value for cache-type [xxx]")
+	})
+
+	t.Run("Redis with no URL -> error", func(t *testing.T) {
+		restoreEnv := setEnv(t, cacheTypeEnvKey, cacheTypeRedis)
+		defer restoreEnv()
+
+		cmd := getTestCmd(t)
+
+		_, err := getCacheParameters(cmd)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Neither cache-redis-url (command line flag) nor CACHE_REDIS_URL")
+	})
+
+	t.Run("Invalid Redis URL -> error", func(t *testing.T) {
+		restoreTypeEnv := setEnv(t, cacheTypeEnvKey, cacheTypeRedis)
+		restoreURLEnv := setEnv(t, cacheRedisURLEnvKey, "http://localhost:6379")
+
+		defer func() {
+			restoreTypeEnv()
+			restoreURLEnv()
+		}()
+
+		cmd := getTestCmd(t)
+
+		_, err := getCacheParameters(cmd)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "cache-redis-url: invalid Redis URL [http://localhost:6379]")
+	})
+}
+
 func setEnvVars(t *testing.T, databaseType, casType, replicateLocalCASToIPFS string) {
 	t.Helper()
 
@@ -1810,6 +2249,7 @@ func getTestArgs(ipfsURL, casType, localCASReplicateInIPFSEnabled, databaseType,
 		"--" + includeUnpublishedOperationsFlagName, "true",
 		"--" + resolveFromAnchorOriginFlagName, "true",
 		"--" + verifyLatestFromAnchorOriginFlagName, "true",
+		"--" + resolutionCacheEnabledFlagName, "true",
 		"--" + sidetreeProtocolVersionsFlagName, "1.0",
 		"--" + currentSidetreeProtocolVersionFlagName, "1.0",
 		"--" + kmsTypeFlagName, "local",
@@ -31,6 +31,7 @@ import (
 	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
+	awskms "github.com/aws/aws-sdk-go/service/kms"
 	"github.com/google/uuid"
 	ariescouchdbstorage "github.com/hyperledger/aries-framework-go-ext/component/storage/couchdb"
 	ariesmongodbstorage "github.com/hyperledger/aries-framework-go-ext/component/storage/mongodb"
@@ -61,9 +62,11 @@ import (
 	"github.com/spf13/cobra"
 	awssvc "github.com/trustbloc/kms/pkg/aws"
 	casapi "github.com/trustbloc/sidetree-core-go/pkg/api/cas"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
 	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
 	"github.com/trustbloc/sidetree-core-go/pkg/batch"
 	"github.com/trustbloc/sidetree-core-go/pkg/dochandler"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
 	"github.com/trustbloc/sidetree-core-go/pkg/processor"
 	restcommon "github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/diddochandler"
@@ -97,14 +100,22 @@ import (
 	"github.com/trustbloc/orb/pkg/anchor/handler/credential"
 	"github.com/trustbloc/orb/pkg/anchor/handler/proof"
 	"github.com/trustbloc/orb/pkg/anchor/linkstore"
+	"github.com/trustbloc/orb/pkg/anchor/reindex"
+	reindexhandler "github.com/trustbloc/orb/pkg/anchor/reindex/resthandler"
 	"github.com/trustbloc/orb/pkg/anchor/witness/policy"
 	policycfg "github.com/trustbloc/orb/pkg/anchor/witness/policy/config"
 	"github.com/trustbloc/orb/pkg/anchor/witness/policy/inspector"
 	policyhandler "github.com/trustbloc/orb/pkg/anchor/witness/policy/resthandler"
 	"github.com/trustbloc/orb/pkg/anchor/writer"
+	"github.com/trustbloc/orb/pkg/cache"
+	memorycache "github.com/trustbloc/orb/pkg/cache/memory"
+	rediscache "github.com/trustbloc/orb/pkg/cache/redis"
 	"github.com/trustbloc/orb/pkg/cas/extendedcasclient"
+	casgc "github.com/trustbloc/orb/pkg/cas/gc"
+	casgchandler "github.com/trustbloc/orb/pkg/cas/gc/resthandler"
 	ipfscas "github.com/trustbloc/orb/pkg/cas/ipfs"
 	"github.com/trustbloc/orb/pkg/cas/resolver"
+	s3cas "github.com/trustbloc/orb/pkg/cas/s3"
 	"github.com/trustbloc/orb/pkg/config"
 	configclient "github.com/trustbloc/orb/pkg/config/client"
 	sidetreecontext "github.com/trustbloc/orb/pkg/context"
@@ -115,8 +126,13 @@ import (
 	localdiscovery "github.com/trustbloc/orb/pkg/discovery/did/local"
 	discoveryclient "github.com/trustbloc/orb/pkg/discovery/endpoint/client"
 	discoveryrest "github.com/trustbloc/orb/pkg/discovery/endpoint/restapi"
+	"github.com/trustbloc/orb/pkg/document/bulkresolver"
+	"github.com/trustbloc/orb/pkg/document/didresolution"
 	"github.com/trustbloc/orb/pkg/document/didresolver"
+	"github.com/trustbloc/orb/pkg/document/history"
+	historyresthandler "github.com/trustbloc/orb/pkg/document/history/resthandler"
 	"github.com/trustbloc/orb/pkg/document/remoteresolver"
+	"github.com/trustbloc/orb/pkg/document/resolutioncache"
 	"github.com/trustbloc/orb/pkg/document/resolvehandler"
 	"github.com/trustbloc/orb/pkg/document/updatehandler"
 	"github.com/trustbloc/orb/pkg/document/updatehandler/decorator"
@@ -125,14 +141,20 @@ import (
 	"github.com/trustbloc/orb/pkg/httpserver"
 	"github.com/trustbloc/orb/pkg/httpserver/auth"
 	"github.com/trustbloc/orb/pkg/httpserver/auth/signature"
+	"github.com/trustbloc/orb/pkg/maintenance"
+	maintenancehandler "github.com/trustbloc/orb/pkg/maintenance/resthandler"
 	"github.com/trustbloc/orb/pkg/nodeinfo"
 	metricsProvider "github.com/trustbloc/orb/pkg/observability/metrics"
 	promMetricsProvider "github.com/trustbloc/orb/pkg/observability/metrics/prometheus"
 	"github.com/trustbloc/orb/pkg/observer"
 	"github.com/trustbloc/orb/pkg/protocolversion/factoryregistry"
 	"github.com/trustbloc/orb/pkg/pubsub/amqp"
+	"github.com/trustbloc/orb/pkg/pubsub/durablepubsub"
 	"github.com/trustbloc/orb/pkg/pubsub/mempubsub"
+	natspubsub "github.com/trustbloc/orb/pkg/pubsub/nats"
 	"github.com/trustbloc/orb/pkg/pubsub/spi"
+	"github.com/trustbloc/orb/pkg/pubsub/undeliverable"
+	undeliverablehandler "github.com/trustbloc/orb/pkg/pubsub/undeliverable/resthandler"
 	"github.com/trustbloc/orb/pkg/resolver/resource"
 	"github.com/trustbloc/orb/pkg/resolver/resource/registry"
 	"github.com/trustbloc/orb/pkg/resolver/resource/registry/didanchorinfo"
@@ -141,6 +163,7 @@ import (
 	"github.com/trustbloc/orb/pkg/store/anchorstatus"
 	casstore "github.com/trustbloc/orb/pkg/store/cas"
 	didanchorstore "github.com/trustbloc/orb/pkg/store/didanchor"
+	"github.com/trustbloc/orb/pkg/store/encrypted"
 	"github.com/trustbloc/orb/pkg/store/expiry"
 	"github.com/trustbloc/orb/pkg/store/logentry"
 	"github.com/trustbloc/orb/pkg/store/logmonitor"
@@ -157,6 +180,7 @@ import (
 	"github.com/trustbloc/orb/pkg/vct/logmonitoring/handler"
 	logmonitorhandler "github.com/trustbloc/orb/pkg/vct/logmonitoring/resthandler"
 	"github.com/trustbloc/orb/pkg/vct/proofmonitoring"
+	proofmonitorhandler "github.com/trustbloc/orb/pkg/vct/proofmonitoring/resthandler"
 	vcthandler "github.com/trustbloc/orb/pkg/vct/resthandler"
 	"github.com/trustbloc/orb/pkg/versions/1_0/operationparser/validators/anchororigin"
 	"github.com/trustbloc/orb/pkg/webcas"
@@ -184,6 +208,10 @@ const (
 	defaultCasCacheSize                     = 1000
 	defaultWebfingerCacheExpiration         = 5 * time.Minute
 	defaultWebfingerCacheSize               = 1000
+	defaultCASGCInterval                    = 24 * time.Hour
+	defaultCASGCGracePeriod                 = 24 * time.Hour
+	defaultCASResolveHedgeDelay             = 500 * time.Millisecond
+	defaultCASCircuitBreakerOpenDuration    = time.Minute
 
 	unpublishedDIDLabel = "uAAA"
 )
@@ -204,11 +232,23 @@ const (
 	jsonWebSignature2020 = "JsonWebSignature2020"
 	ed25519Signature2020 = "Ed25519Signature2020"
 
-	webKeyStoreKey = "web-key-store"
-	vcKidKey       = "vckid"
-	httpKidKey     = "httpkid"
+	webKeyStoreKey      = "web-key-store"
+	vcKidKey            = "vckid"
+	httpKidKey          = "httpkid"
+	dbEncryptionKidKey  = "dbencryptionkid"
+	configStoreName     = "orb-config"
+	dbEncryptionKeyType = kms.AES256GCMType
+
+	natsURLPrefix = "nats://"
 )
 
+// configStoreTagGroups contains the tags which are queried in the config store (by the accept list
+// and allowed origins managers).
+var configStoreTagGroups = []store.TagGroup{
+	store.NewTagGroup("acceptType"),
+	store.NewTagGroup("allowedOrigin"),
+}
+
 type pubSub interface {
 	Subscribe(ctx context.Context, topic string) (<-chan *message.Message, error)
 	SubscribeWithOpts(ctx context.Context, topic string, opts ...spi.Option) (<-chan *message.Message, error)
@@ -230,6 +270,11 @@ type crypto interface {
 	Sign(msg []byte, kh interface{}) ([]byte, error)
 }
 
+type encryptionCrypto interface {
+	Encrypt(msg, aad []byte, kh interface{}) ([]byte, []byte, error)
+	Decrypt(cipher, aad, nonce []byte, kh interface{}) ([]byte, error)
+}
+
 type service interface {
 	Start()
 	Stop()
@@ -408,6 +453,104 @@ func (a awsKMSWrapper) HealthCheck() error {
 	return a.service.HealthCheck()
 }
 
+// createEncryptedStoreProvider returns a storage provider which encrypts the values of the stores that it opens
+// using data keys which are wrapped by a key from the configured KMS.
+func createEncryptedStoreProvider(parameters *orbParameters, p dbProvider, km keyManager, cr crypto,
+	cfg storage.Store) (dbProvider, error) {
+	keyWrapper, kekID, err := createDBEncryptionKeyWrapper(parameters, km, cr, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Database encryption is enabled", log.WithKeyID(kekID))
+
+	if mp, ok := p.(mongoDBProvider); ok {
+		ep, e := encrypted.NewMongoDB(mp, keyWrapper, kekID)
+		if e != nil {
+			return nil, e
+		}
+
+		if e := rotateDataKey(parameters, ep.Provider); e != nil {
+			return nil, e
+		}
+
+		return &mongoDBStorageProvider{ep, p.DBType()}, nil
+	}
+
+	ep, err := encrypted.New(p, keyWrapper, kekID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := rotateDataKey(parameters, ep); err != nil {
+		return nil, err
+	}
+
+	return &storageProvider{ep, p.DBType()}, nil
+}
+
+// rotateDataKey generates a new data encryption key if requested. Values which were encrypted with previous
+// data keys may still be decrypted.
+func rotateDataKey(parameters *orbParameters, ep *encrypted.Provider) error {
+	if !parameters.dbParameters.databaseEncryptionRotateDataKey {
+		return nil
+	}
+
+	logger.Info("Rotating the database data encryption key")
+
+	if err := ep.RotateDataKey(); err != nil {
+		return fmt.Errorf("rotate data encryption key: %w", err)
+	}
+
+	return nil
+}
+
+func createDBEncryptionKeyWrapper(parameters *orbParameters, km keyManager, cr crypto,
+	cfg storage.Store) (encrypted.KeyWrapper, string, error) {
+	kekID := parameters.dbParameters.databaseEncryptionKeyID
+
+	if parameters.kmsParams.kmsType == kmsAWS {
+		if kekID == "" {
+			return nil, "", fmt.Errorf("%s is required for the AWS KMS", databaseEncryptionKeyIDFlagName)
+		}
+
+		awsSession, err := session.NewSession(&aws.Config{
+			Endpoint:                      &parameters.kmsParams.kmsEndpoint,
+			Region:                        aws.String(parameters.kmsParams.kmsRegion),
+			CredentialsChainVerboseErrors: aws.Bool(true),
+		})
+		if err != nil {
+			return nil, "", err
+		}
+
+		return encrypted.NewAWSKeyWrapper(awskms.New(awsSession)), kekID, nil
+	}
+
+	ec, ok := cr.(encryptionCrypto)
+	if !ok {
+		return nil, "", fmt.Errorf("crypto for KMS type [%s] does not support encryption", parameters.kmsParams.kmsType)
+	}
+
+	if kekID == "" {
+		keyStoreCfg := &keyStoreCfg{}
+
+		err := getOrInit(cfg, dbEncryptionKidKey, keyStoreCfg, func() (interface{}, error) {
+			var err error
+
+			keyStoreCfg.KeyID, _, err = km.Create(dbEncryptionKeyType)
+
+			return keyStoreCfg, err
+		}, parameters.syncTimeout)
+		if err != nil {
+			return nil, "", fmt.Errorf("create database encryption key ID: %w", err)
+		}
+
+		kekID = keyStoreCfg.KeyID
+	}
+
+	return encrypted.NewKMSKeyWrapper(km, ec), kekID, nil
+}
+
 func createKID(km keyManager, httpSignKeyType bool, parameters *orbParameters, cfg storage.Store) error {
 	activeKeyID := &parameters.kmsParams.vcSignActiveKeyID
 	kidKey := vcKidKey
@@ -478,7 +621,7 @@ func startOrbServices(parameters *orbParameters) error {
 		return err
 	}
 
-	configStore, err := store.Open(storeProviders.provider, "orb-config")
+	configStore, err := store.Open(storeProviders.provider, configStoreName, configStoreTagGroups...)
 	if err != nil {
 		return fmt.Errorf("open store: %w", err)
 	}
@@ -524,6 +667,9 @@ func startOrbServices(parameters *orbParameters) error {
 
 	var coreCASClient extendedcasclient.Client
 
+	// localCAS is set only if the CAS type is local, since garbage collection applies only to the local CAS.
+	var localCAS *casstore.CAS
+
 	switch {
 	case strings.EqualFold(parameters.casType, "ipfs"):
 		logger.Info("Initializing Orb CAS with IPFS.")
@@ -537,7 +683,7 @@ func startOrbServices(parameters *orbParameters) error {
 		if parameters.localCASReplicateInIPFSEnabled {
 			logger.Info("Local CAS writes will be replicated in IPFS.")
 
-			coreCASClient, err = casstore.New(storeProviders.provider, casIRI.String(),
+			localCAS, err = casstore.New(storeProviders.provider, casIRI.String(),
 				ipfscas.New(parameters.ipfsURL, parameters.ipfsTimeout, defaultCasCacheSize, metrics,
 					extendedcasclient.WithCIDVersion(parameters.cidVersion)),
 				metrics, defaultCasCacheSize, extendedcasclient.WithCIDVersion(parameters.cidVersion))
@@ -545,15 +691,32 @@ func startOrbServices(parameters *orbParameters) error {
 				return err
 			}
 		} else {
-			coreCASClient, err = casstore.New(storeProviders.provider, casIRI.String(), nil,
+			localCAS, err = casstore.New(storeProviders.provider, casIRI.String(), nil,
 				metrics, defaultCasCacheSize, extendedcasclient.WithCIDVersion(parameters.cidVersion))
 			if err != nil {
 				return err
 			}
 		}
 
+		coreCASClient = localCAS
+
+	case strings.EqualFold(parameters.casType, "s3"):
+		logger.Info("Initializing Orb CAS with S3 object storage.")
+
+		awsSession, err := session.NewSession(&aws.Config{
+			Endpoint:                      aws.String(parameters.casS3Params.endpoint),
+			Region:                        aws.String(parameters.casS3Params.region),
+			S3ForcePathStyle:              aws.Bool(parameters.casS3Params.forcePathStyle),
+			CredentialsChainVerboseErrors: aws.Bool(true),
+		})
+		if err != nil {
+			return fmt.Errorf("create S3 session: %w", err)
+		}
+
+		coreCASClient = s3cas.New(awsSession, parameters.casS3Params.bucket, parameters.casS3Params.prefix,
+			casIRI.String(), metrics, defaultCasCacheSize, extendedcasclient.WithCIDVersion(parameters.cidVersion))
 	default:
-		return fmt.Errorf("%s is not a valid CAS type. It must be either local or ipfs", parameters.casType)
+		return fmt.Errorf("%s is not a valid CAS type. It must be either local, ipfs or s3", parameters.casType)
 	}
 
 	didAnchors, err := didanchorstore.New(storeProviders.provider)
@@ -624,6 +787,23 @@ func startOrbServices(parameters *orbParameters) error {
 		}
 	}
 
+	// sensitiveStoreProvider is used to open the stores which hold sensitive data. If database encryption
+	// is enabled then the values in these stores are encrypted at rest.
+	sensitiveStoreProvider := storeProviders.provider
+
+	if parameters.dbParameters.databaseEncryptionEnabled {
+		sensitiveStoreProvider, err = createEncryptedStoreProvider(parameters, storeProviders.provider, km, cr,
+			configStore)
+		if err != nil {
+			return fmt.Errorf("create encrypted store provider: %w", err)
+		}
+
+		configStore, err = store.Open(sensitiveStoreProvider, configStoreName, configStoreTagGroups...)
+		if err != nil {
+			return fmt.Errorf("open encrypted config store: %w", err)
+		}
+	}
+
 	apServicePath := parameters.apServiceParams.serviceEndpoint().Path
 
 	// authTokenManager is used by the REST endpoints to authorize the request.
@@ -654,6 +834,13 @@ func startOrbServices(parameters *orbParameters) error {
 
 	t := transport.New(httpClient, publicKeyID, apGetSigner, apPostSigner, clientTokenManager)
 
+	cacheProvider, closeCacheProvider, err := createCacheProvider(parameters.cacheParams)
+	if err != nil {
+		return err
+	}
+
+	defer closeCacheProvider()
+
 	var endpointClient *discoveryclient.Client
 
 	wfClient := wfclient.New(
@@ -663,18 +850,30 @@ func startOrbServices(parameters *orbParameters) error {
 		}),
 		wfclient.WithCacheLifetime(defaultWebfingerCacheExpiration), // TODO: Define parameter.
 		wfclient.WithCacheSize(defaultWebfingerCacheSize),           // TODO: Define parameter.
+		wfclient.WithCacheProvider(cacheProvider),
 	)
 
 	webCASResolver := resolver.NewWebCASResolver(t, wfClient, webFingerURIScheme)
 
+	casResolverOpts := []resolver.Option{
+		resolver.WithCircuitBreaker(parameters.casResolveParams.circuitBreakerThreshold,
+			parameters.casResolveParams.circuitBreakerOpenDuration),
+	}
+
+	if parameters.casResolveParams.hedgedEnabled {
+		logger.Info("Hedged CAS resolution is enabled.", log.WithDuration(parameters.casResolveParams.hedgeDelay))
+
+		casResolverOpts = append(casResolverOpts, resolver.WithHedgedResolution(parameters.casResolveParams.hedgeDelay))
+	}
+
 	var ipfsReader *ipfscas.Client
 	var casResolver *resolver.Resolver
 	if parameters.ipfsURL != "" {
 		ipfsReader = ipfscas.New(parameters.ipfsURL, parameters.ipfsTimeout, defaultCasCacheSize, metrics,
 			extendedcasclient.WithCIDVersion(parameters.cidVersion))
-		casResolver = resolver.New(coreCASClient, ipfsReader, webCASResolver, metrics)
+		casResolver = resolver.New(coreCASClient, ipfsReader, webCASResolver, metrics, casResolverOpts...)
 	} else {
-		casResolver = resolver.New(coreCASClient, nil, webCASResolver, metrics)
+		casResolver = resolver.New(coreCASClient, nil, webCASResolver, metrics, casResolverOpts...)
 	}
 
 	generatorRegistry := generator.NewRegistry()
@@ -696,7 +895,7 @@ func startOrbServices(parameters *orbParameters) error {
 
 	var updateDocumentStore *unpublishedopstore.Store
 	if parameters.unpublishedOperationStoreEnabled {
-		updateDocumentStore, err = unpublishedopstore.New(storeProviders.provider,
+		updateDocumentStore, err = unpublishedopstore.New(sensitiveStoreProvider,
 			parameters.unpublishedOperationLifespan, expiryService, metrics)
 		if err != nil {
 			return fmt.Errorf("failed to create unpublished document store: %w", err)
@@ -726,6 +925,9 @@ func startOrbServices(parameters *orbParameters) error {
 		return fmt.Errorf("failed to get protocol client for namespace [%s]: %w", parameters.didNamespace, err)
 	}
 
+	// Anchors are hashed using the anchor multihash algorithm of the protocol version (genesis time) of the anchor.
+	graphProviders.AnchorMultihashProvider = factoryregistry.New().NewAnchorMultihashProvider(pc)
+
 	u, err := url.Parse(parameters.externalEndpoint)
 	if err != nil {
 		return fmt.Errorf("parse external endpoint: %w", err)
@@ -796,7 +998,17 @@ func startOrbServices(parameters *orbParameters) error {
 
 	mqParams := parameters.mqParams
 
-	if mqParams.endpoint != "" {
+	switch {
+	case strings.HasPrefix(mqParams.endpoint, natsURLPrefix):
+		pubSub = natspubsub.New(natspubsub.Config{
+			URL:                       mqParams.endpoint,
+			MaxConnectRetries:         mqParams.maxConnectRetries,
+			MaxRedeliveryAttempts:     mqParams.maxRedeliveryAttempts,
+			RedeliveryMultiplier:      mqParams.redeliveryMultiplier,
+			RedeliveryInitialInterval: mqParams.redeliveryInitialInterval,
+			MaxRedeliveryInterval:     mqParams.maxRedeliveryInterval,
+		})
+	case mqParams.endpoint != "":
 		pubSub = amqp.New(amqp.Config{
 			URI:                       mqParams.endpoint,
 			MaxConnectionChannels:     mqParams.maxConnectionChannels,
@@ -808,10 +1020,41 @@ func startOrbServices(parameters *orbParameters) error {
 			RedeliveryInitialInterval: mqParams.redeliveryInitialInterval,
 			MaxRedeliveryInterval:     mqParams.maxRedeliveryInterval,
 		})
-	} else {
+	case mqParams.embeddedDurableEnabled:
+		pubSub, err = durablepubsub.New(sensitiveStoreProvider, durablepubsub.Config{
+			AckTimeout:                durablepubsub.DefaultConfig().AckTimeout,
+			MaxRedeliveryAttempts:     mqParams.maxRedeliveryAttempts,
+			RedeliveryMultiplier:      mqParams.redeliveryMultiplier,
+			RedeliveryInitialInterval: mqParams.redeliveryInitialInterval,
+			MaxRedeliveryInterval:     mqParams.maxRedeliveryInterval,
+		})
+		if err != nil {
+			return fmt.Errorf("create durable publisher/subscriber: %w", err)
+		}
+	default:
 		pubSub = mempubsub.New(mempubsub.DefaultConfig())
 	}
 
+	// The resolution processor is used by the document handler to resolve DIDs. If the resolution cache
+	// is enabled then resolution results are cached and invalidated by the observer. Invalidations are
+	// published to all instances over the message queue.
+	var resolutionProcessor operationProcessor = opProcessor
+
+	var resolutionCache *resolutioncache.Cache
+
+	if parameters.resolutionCacheEnabled {
+		resolutionCache, err = resolutioncache.New(opProcessor, cacheProvider, metrics,
+			resolutioncache.WithCacheSize(parameters.resolutionCacheSize),
+			resolutioncache.WithCacheExpiration(parameters.resolutionCacheExpiration),
+			resolutioncache.WithPubSub(pubSub),
+		)
+		if err != nil {
+			return fmt.Errorf("create resolution cache: %w", err)
+		}
+
+		resolutionProcessor = resolutionCache
+	}
+
 	apConfig := &apservice.Config{
 		ServicePath:              apServicePath,
 		ServiceIRI:               parameters.apServiceParams.serviceIRI(),
@@ -822,9 +1065,10 @@ func startOrbServices(parameters *orbParameters) error {
 		IRICacheExpiration:       parameters.apIRICacheExpiration,
 		OutboxSubscriberPoolSize: parameters.mqParams.outboxPoolSize,
 		InboxSubscriberPoolSize:  parameters.mqParams.inboxPoolSize,
+		CacheProvider:            cacheProvider,
 	}
 
-	apStore, err := createActivityPubStore(storeProviders.provider, apConfig.ServicePath)
+	apStore, err := createActivityPubStore(sensitiveStoreProvider, apConfig.ServicePath)
 	if err != nil {
 		return err
 	}
@@ -881,7 +1125,8 @@ func startOrbServices(parameters *orbParameters) error {
 		Type: httpSignKeyType, Value: httpSignActivePubKey,
 	})
 
-	pkStore, err := publickey.New(storeProviders.provider, verifiable.NewVDRKeyResolver(vdr).PublicKeyFetcher())
+	pkStore, err := publickey.New(storeProviders.provider, verifiable.NewVDRKeyResolver(vdr).PublicKeyFetcher(),
+		publickey.WithCacheProvider(cacheProvider))
 	if err != nil {
 		return fmt.Errorf("create public key storage: %w", err)
 	}
@@ -899,17 +1144,22 @@ func startOrbServices(parameters *orbParameters) error {
 		discoveryclient.WithVDR(vdr),
 	)
 
-	resourceResolver := resource.New(httpClient, ipfsReader, endpointClient)
+	resourceResolver := resource.New(httpClient, ipfsReader, endpointClient,
+		resource.WithCacheProvider(cacheProvider))
 
 	apClient := client.New(client.Config{
 		CacheSize:       parameters.apClientCacheSize,
 		CacheExpiration: parameters.apClientCacheExpiration,
+		CacheProvider:   cacheProvider,
 	}, t, publicKeyFetcher, resourceResolver)
 
 	apSigVerifier := getActivityPubVerifier(parameters, km, cr, apClient)
 
 	proofMonitoringSvc, err := proofmonitoring.New(storeProviders.provider, orbDocumentLoader, wfClient,
-		httpClient, taskMgr, parameters.vctProofMonitoringInterval, parameters.requestTokens)
+		httpClient, taskMgr, parameters.vctProofMonitoringInterval, parameters.requestTokens,
+		proofmonitoring.WithMetrics(metrics),
+		proofmonitoring.WithExpiryService(expiryService, parameters.proofMonitoringFailedRetentionPeriod),
+	)
 	if err != nil {
 		return fmt.Errorf("new VCT monitoring service: %w", err)
 	}
@@ -924,8 +1174,16 @@ func startOrbServices(parameters *orbParameters) error {
 		logmonitoring.WithMaxGetEntriesRange(parameters.vctLogMonitoringGetEntriesRange),
 	}
 
+	var logEntryStore *logentry.Store
+
 	if parameters.vctLogEntriesStoreEnabled {
-		logEntryStore, err := logentry.New(storeProviders.provider)
+		logEntryStoreOpts, err := getLogEntryStoreOptions(parameters.logEntryRetention, coreCASClient,
+			expiryService, taskMgr, logMonitorStore)
+		if err != nil {
+			return err
+		}
+
+		logEntryStore, err = logentry.New(storeProviders.provider, logEntryStoreOpts...)
 		if err != nil {
 			return fmt.Errorf("failed to create log entries store: %w", err)
 		}
@@ -935,6 +1193,32 @@ func startOrbServices(parameters *orbParameters) error {
 			logmonitoring.WithLogEntriesStore(logEntryStore))
 	}
 
+	var casGC *casgc.Collector
+
+	if parameters.casGCParams.enabled {
+		if localCAS == nil {
+			return fmt.Errorf("%s is only supported if the CAS type is local", casGCEnabledFlagName)
+		}
+
+		logger.Info("CAS garbage collection is enabled.", log.WithDuration(parameters.casGCParams.interval))
+
+		casGCOpts := []casgc.Option{
+			casgc.WithGracePeriod(parameters.casGCParams.gracePeriod),
+			casgc.WithDryRun(parameters.casGCParams.dryRun),
+		}
+
+		if logEntryStore != nil {
+			// Log entry archives may have been written to the local CAS.
+			casGCOpts = append(casGCOpts, casgc.WithArchives(logEntryStore))
+		}
+
+		casGC, err = casgc.New(storeProviders.provider, localCAS, opStore, anchorLinksetBuilder, taskMgr,
+			parameters.casGCParams.interval, casGCOpts...)
+		if err != nil {
+			return fmt.Errorf("create CAS garbage collector: %w", err)
+		}
+	}
+
 	logMonitoringSvc, err := logmonitoring.New(logMonitorStore, httpClient, parameters.requestTokens,
 		logMonitoringOpts...)
 	if err != nil {
@@ -945,7 +1229,8 @@ func startOrbServices(parameters *orbParameters) error {
 
 	policyStore := policycfg.NewPolicyStore(configStore)
 
-	witnessPolicy, err := policy.New(policyStore, parameters.witnessPolicyCacheExpiration)
+	witnessPolicy, err := policy.New(policyStore, parameters.witnessPolicyCacheExpiration,
+		policy.WithCacheProvider(cacheProvider))
 	if err != nil {
 		return fmt.Errorf("failed to create witness policy: %s", err.Error())
 	}
@@ -1016,15 +1301,35 @@ func startOrbServices(parameters *orbParameters) error {
 		MonitoringSvc:          proofMonitoringSvc,
 	}
 
-	observer, err := observer.New(apConfig.ServiceIRI, providers,
+	observerOpts := []observer.Option{
 		observer.WithDiscoveryDomain(parameters.discoveryDomain),
 		observer.WithSubscriberPoolSize(parameters.mqParams.observerPoolSize),
 		observer.WithProofMonitoringExpiryPeriod(parameters.proofMonitoringExpiryPeriod),
-	)
+	}
+
+	if resolutionCache != nil {
+		observerOpts = append(observerOpts, observer.WithResolutionCache(resolutionCache))
+	}
+
+	observer, err := observer.New(apConfig.ServiceIRI, providers, observerOpts...)
 	if err != nil {
 		return fmt.Errorf("failed to create observer: %w", err)
 	}
 
+	reindexer := reindex.New(
+		&reindex.Providers{
+			AnchorGraph:            anchorGraph,
+			AnchorLinkStore:        anchorLinkStore,
+			AnchorLinksetBuilder:   anchorLinksetBuilder,
+			ProtocolClientProvider: pcp,
+			OpStore:                opStore,
+			DidAnchors:             didAnchors,
+			DocLoader:              orbDocumentLoader,
+			Pkf:                    publicKeyFetcher,
+		},
+		reindex.WithDiscoveryDomain(parameters.discoveryDomain),
+	)
+
 	anchorEventHandler := acknowlegement.New(anchorLinkStore)
 
 	err = anchorsynctask.Register(
@@ -1109,14 +1414,26 @@ func startOrbServices(parameters *orbParameters) error {
 		return fmt.Errorf("failed to create operation queue: %s", err.Error())
 	}
 
+	// Messages which exhaust redelivery are persisted so that they may be requeued (or dropped) by an operator.
+	undeliverableSvc, err := undeliverable.New(sensitiveStoreProvider, pubSub)
+	if err != nil {
+		return fmt.Errorf("failed to create undeliverable message service: %w", err)
+	}
+
+	batchWriterContext := sidetreecontext.New(pc, anchorWriter, opQueue)
+
 	// create new batch writer
-	batchWriter, err := batch.New(parameters.didNamespace,
-		sidetreecontext.New(pc, anchorWriter, opQueue),
+	batchWriter, err := batch.New(parameters.didNamespace, batchWriterContext,
 		batch.WithBatchTimeout(parameters.batchWriterTimeout))
 	if err != nil {
 		return fmt.Errorf("failed to create batch writer: %s", err.Error())
 	}
 
+	// Components are paused in the given order. The operation queue is paused first so that the batches
+	// which are in flight are written before the batch writer is paused. The quiesce only applies to this
+	// instance so every instance in the cluster must be quiesced before a backup is taken.
+	maintenanceSvc := maintenance.New(taskMgr.InstanceID(), opQueue, batchWriterContext, observer, activityPubService)
+
 	var didDocHandlerOpts []dochandler.Option
 
 	if parameters.enableDevMode {
@@ -1128,7 +1445,16 @@ func startOrbServices(parameters *orbParameters) error {
 	didDocHandlerOpts = append(didDocHandlerOpts, dochandler.WithLabel(unpublishedDIDLabel))
 
 	if parameters.unpublishedOperationStoreEnabled {
-		didDocHandlerOpts = append(didDocHandlerOpts, dochandler.WithUnpublishedOperationStore(updateDocumentStore, parameters.unpublishedOperationStoreOperationTypes))
+		var unpublishedOpStore unpublishedOperationStore = updateDocumentStore
+
+		if resolutionCache != nil {
+			unpublishedOpStore = resolutionCache.UnpublishedOperationStore(updateDocumentStore)
+
+			// Expired unpublished operations are deleted by the expiry service (not through the store above).
+			updateDocumentStore.OnExpired(resolutionCache.Invalidate)
+		}
+
+		didDocHandlerOpts = append(didDocHandlerOpts, dochandler.WithUnpublishedOperationStore(unpublishedOpStore, parameters.unpublishedOperationStoreOperationTypes))
 	}
 
 	if parameters.verifyLatestFromAnchorOrigin {
@@ -1148,7 +1474,7 @@ func startOrbServices(parameters *orbParameters) error {
 		parameters.didAliases,
 		pc,
 		batchWriter,
-		opProcessor,
+		resolutionProcessor,
 		metrics,
 		didDocHandlerOpts...,
 	)
@@ -1165,6 +1491,11 @@ func startOrbServices(parameters *orbParameters) error {
 	resolveHandlerOpts = append(resolveHandlerOpts, resolvehandler.WithUnpublishedDIDLabel(unpublishedDIDLabel))
 	resolveHandlerOpts = append(resolveHandlerOpts, resolvehandler.WithEnableDIDDiscovery(parameters.didDiscoveryEnabled))
 	resolveHandlerOpts = append(resolveHandlerOpts, resolvehandler.WithEnableResolutionFromAnchorOrigin(parameters.resolveFromAnchorOrigin))
+	resolveHandlerOpts = append(resolveHandlerOpts, resolvehandler.WithOperationStore(opStore))
+	resolveHandlerOpts = append(resolveHandlerOpts, resolvehandler.WithDocumentLoader(orbDocumentLoader))
+	resolveHandlerOpts = append(resolveHandlerOpts, resolvehandler.WithConsensusDomains(parameters.discoveryDomains))
+	resolveHandlerOpts = append(resolveHandlerOpts,
+		resolvehandler.WithConsensusMinimumResolvers(parameters.discoveryMinimumResolvers))
 
 	var updateHandlerOpts []updatehandler.Option
 
@@ -1248,9 +1579,45 @@ func startOrbServices(parameters *orbParameters) error {
 
 	didResolveHandler := didresolver.NewResolveHandler(orbResolveHandler, webResolveHandler)
 
+	didHistorySvc := history.New(parameters.didNamespace, opStore, anchorGraph, witnessProofStore, orbDocumentLoader)
+
+	// The bulk resolver resolves DIDs using the same resolution chain as the resolve handler except that
+	// the published operations are read (in bulk) from storage up front.
+	didBulkResolver := bulkresolver.New(parameters.didNamespace, didAnchors, opStore,
+		func(store bulkresolver.OperationStore) bulkresolver.DocumentResolver {
+			docHandler := dochandler.New(
+				parameters.didNamespace,
+				parameters.didAliases,
+				pc,
+				batchWriter,
+				processor.New(parameters.didNamespace, store, pc, processorOpts...),
+				metrics,
+				didDocHandlerOpts...,
+			)
+
+			return didresolver.NewResolveHandler(
+				resolvehandler.NewResolveHandler(
+					parameters.didNamespace,
+					docHandler,
+					didDiscovery,
+					parameters.externalEndpoint,
+					endpointClient,
+					remoteresolver.New(t),
+					anchorGraph,
+					metrics,
+					resolveHandlerOpts...,
+				),
+				webResolveHandler,
+			)
+		},
+	)
+
 	handlers = append(handlers,
 		auth.NewHandlerWrapper(diddochandler.NewUpdateHandler(baseUpdatePath, orbDocUpdateHandler, pc, metrics), authTokenManager),
-		signature.NewHandlerWrapper(diddochandler.NewResolveHandler(baseResolvePath, didResolveHandler, metrics),
+		signature.NewHandlerWrapper(
+			didresolution.NewResolveHandler(baseResolvePath+"/{id}", didResolveHandler, metrics,
+				didresolution.WithStrictMode(parameters.didResolutionStrict),
+			),
 			&aphandler.Config{
 				ObjectIRI:              parameters.apServiceParams.serviceIRI(),
 				VerifyActorInSignature: parameters.httpSignaturesEnabled,
@@ -1258,6 +1625,16 @@ func startOrbServices(parameters *orbParameters) error {
 			},
 			apStore, apSigVerifier, authTokenManager,
 		),
+		signature.NewHandlerWrapper(
+			didresolution.NewBulkResolveHandler(baseResolvePath, didBulkResolver, metrics),
+			&aphandler.Config{
+				ObjectIRI:              parameters.apServiceParams.serviceIRI(),
+				VerifyActorInSignature: parameters.httpSignaturesEnabled,
+				PageSize:               parameters.activityPubPageSize,
+			},
+			apStore, apSigVerifier, authTokenManager,
+		),
+		auth.NewHandlerWrapper(historyresthandler.New(baseResolvePath+"/{id}/history", didHistorySvc), authTokenManager),
 		activityPubService.InboxHTTPHandler(),
 		aphandler.NewServices(apEndpointCfg, apStore, httpSignActivePublicKey, authTokenManager),
 		aphandler.NewPublicKeys(apEndpointCfg, apStore, httpSignActivePublicKey, authTokenManager),
@@ -1280,10 +1657,20 @@ func startOrbServices(parameters *orbParameters) error {
 			},
 			apStore, apSigVerifier, coreCASClient, authTokenManager,
 		),
-		auth.NewHandlerWrapper(policyhandler.New(policyStore), authTokenManager),
+		webcas.NewBatch(
+			&aphandler.Config{
+				ObjectIRI:              parameters.apServiceParams.serviceIRI(),
+				VerifyActorInSignature: parameters.httpSignaturesEnabled,
+				PageSize:               parameters.activityPubPageSize,
+			},
+			apStore, apSigVerifier, coreCASClient, authTokenManager,
+		),
+		auth.NewHandlerWrapper(policyhandler.New(policyStore, policyhandler.WithPolicyInvalidator(witnessPolicy)),
+			authTokenManager),
 		auth.NewHandlerWrapper(policyhandler.NewRetriever(policyStore), authTokenManager),
 		auth.NewHandlerWrapper(logmonitorhandler.NewUpdateHandler(logMonitorStore), authTokenManager),
 		auth.NewHandlerWrapper(logmonitorhandler.NewRetriever(logMonitorStore), authTokenManager),
+		auth.NewHandlerWrapper(proofmonitorhandler.NewRetriever(proofMonitoringSvc), authTokenManager),
 		auth.NewHandlerWrapper(vcthandler.New(configStore, logMonitorStore), authTokenManager),
 		auth.NewHandlerWrapper(vcthandler.NewRetriever(configStore), authTokenManager),
 		auth.NewHandlerWrapper(nodeinfo.NewHandler(nodeinfo.V2_0, nodeInfoService), authTokenManager),
@@ -1291,6 +1678,13 @@ func startOrbServices(parameters *orbParameters) error {
 		auth.NewHandlerWrapper(vcresthandler.New(vcStore), authTokenManager),
 		auth.NewHandlerWrapper(allowedoriginsrest.NewWriter(allowedOriginsStore), authTokenManager),
 		auth.NewHandlerWrapper(allowedoriginsrest.NewReader(allowedOriginsStore), authTokenManager),
+		auth.NewHandlerWrapper(maintenancehandler.NewQuiesceHandler(maintenanceSvc), authTokenManager),
+		auth.NewHandlerWrapper(maintenancehandler.NewResumeHandler(maintenanceSvc), authTokenManager),
+		auth.NewHandlerWrapper(reindexhandler.NewStartHandler(reindexer), authTokenManager),
+		auth.NewHandlerWrapper(reindexhandler.NewStatusHandler(reindexer), authTokenManager),
+		auth.NewHandlerWrapper(undeliverablehandler.NewListHandler(undeliverableSvc), authTokenManager),
+		auth.NewHandlerWrapper(undeliverablehandler.NewRequeueHandler(undeliverableSvc), authTokenManager),
+		auth.NewHandlerWrapper(undeliverablehandler.NewDropHandler(undeliverableSvc), authTokenManager),
 	)
 
 	handlers = append(handlers,
@@ -1300,6 +1694,22 @@ func startOrbServices(parameters *orbParameters) error {
 		handlers = append(handlers, auth.NewHandlerWrapper(&httpHandler{handler}, authTokenManager))
 	}
 
+	if logEntryStore != nil {
+		handlers = append(handlers,
+			auth.NewHandlerWrapper(logmonitorhandler.NewArchivesHandler(logEntryStore), authTokenManager),
+			auth.NewHandlerWrapper(logmonitorhandler.NewImportArchiveHandler(logEntryStore), authTokenManager),
+			auth.NewHandlerWrapper(logmonitorhandler.NewImportedEntriesHandler(logEntryStore), authTokenManager),
+		)
+	}
+
+	if casGC != nil {
+		handlers = append(handlers,
+			auth.NewHandlerWrapper(casgchandler.NewReportHandler(casGC), authTokenManager),
+			auth.NewHandlerWrapper(casgchandler.NewPinHandler(casGC), authTokenManager),
+			auth.NewHandlerWrapper(casgchandler.NewPinRetriever(casGC), authTokenManager),
+		)
+	}
+
 	if parameters.followAuthPolicy == acceptListPolicy || parameters.inviteWitnessAuthPolicy == acceptListPolicy {
 		// Register endpoints to manage the 'accept list'.
 		handlers = append(handlers, auth.NewHandlerWrapper(
@@ -1335,7 +1745,8 @@ func startOrbServices(parameters *orbParameters) error {
 		}
 	}
 
-	err = run(httpServer, activityPubService, opQueue, observer, batchWriter, taskMgr, nodeInfoService)
+	err = run(httpServer, activityPubService, opQueue, undeliverableSvc, observer, batchWriter, taskMgr,
+		nodeInfoService)
 	if err != nil {
 		return err
 	}
@@ -1347,6 +1758,34 @@ func startOrbServices(parameters *orbParameters) error {
 	return nil
 }
 
+func getLogEntryStoreOptions(params *logEntryRetentionParams, casClient casapi.Client,
+	expiryService *expiry.Service, taskMgr *taskmgr.Manager, logMonitorStore *logmonitor.Store,
+) ([]logentry.Option, error) {
+	opts := []logentry.Option{
+		logentry.WithRetentionPolicy(params.defaultPolicy),
+		logentry.WithExpiryService(expiryService),
+		logentry.WithCompaction(taskMgr, params.compactionInterval, logMonitorStore),
+	}
+
+	for logURL, policy := range params.logPolicies {
+		opts = append(opts, logentry.WithLogRetentionPolicy(logURL, policy))
+	}
+
+	switch params.archiveType {
+	case logEntryArchiveTypeFile:
+		archiver, err := logentry.NewFileArchiver(params.archiveDir)
+		if err != nil {
+			return nil, fmt.Errorf("create log entry file archiver: %w", err)
+		}
+
+		opts = append(opts, logentry.WithArchiver(archiver))
+	case logEntryArchiveTypeCAS:
+		opts = append(opts, logentry.WithArchiver(logentry.NewCASArchiver(casClient)))
+	}
+
+	return opts, nil
+}
+
 func getProtocolClientProvider(parameters *orbParameters, casClient casapi.Client, casResolver common.CASResolver,
 	opStore common.OperationStore, provider storage.Provider, unpublishedOpStore *unpublishedopstore.Store,
 	allowedOriginsValidator operationparser.ObjectValidator, metrics metricsProvider.Metrics) (*orbpcp.ClientProvider, error) {
@@ -1685,6 +2124,15 @@ type logEndpoint interface {
 	HealthCheck() error
 }
 
+type operationProcessor interface {
+	Resolve(uniqueSuffix string, opts ...document.ResolutionOption) (*protocol.ResolutionModel, error)
+}
+
+type unpublishedOperationStore interface {
+	Put(op *operation.AnchoredOperation) error
+	Delete(op *operation.AnchoredOperation) error
+}
+
 type noOpRetriever struct{}
 
 func (r *noOpRetriever) GetLogEndpoint() (string, error) {
@@ -1833,3 +2281,20 @@ func NewMetricsProvider(parameters *orbParameters, pubSub pubSub, witness *vct.C
 		return nil, nil
 	}
 }
+
+func createCacheProvider(params *cacheParams) (cache.Provider, func(), error) {
+	if params.cacheType != cacheTypeRedis {
+		logger.Info("Using in-memory caches")
+
+		return memorycache.New(), func() {}, nil
+	}
+
+	p, err := rediscache.New(params.redisAddress, rediscache.WithPassword(params.redisPassword))
+	if err != nil {
+		return nil, nil, fmt.Errorf("create Redis cache provider: %w", err)
+	}
+
+	logger.Info("Using shared Redis caches", log.WithAddress(params.redisAddress))
+
+	return p, p.Close, nil
+}
@@ -110,6 +110,127 @@ func TestCreateKMSAndCrypto(t *testing.T) {
 	})
 }
 
+func TestCreateEncryptedStoreProvider(t *testing.T) {
+	t.Run("Success (local kms)", func(t *testing.T) {
+		cfgStore, err := mem.NewProvider().OpenStore("cfg")
+		require.NoError(t, err)
+
+		parameters := &orbParameters{
+			kmsParams: &kmsParameters{
+				kmsSecretsDatabaseType: "mem",
+				kmsType:                kmsLocal,
+			},
+			dbParameters: &dbParameters{},
+		}
+
+		km, cr, err := createKMSAndCrypto(parameters, nil, mem.NewProvider(), cfgStore, nil)
+		require.NoError(t, err)
+
+		p, err := createEncryptedStoreProvider(parameters,
+			&storageProvider{&memProvider{mem.NewProvider()}, databaseTypeMemOption}, km, cr, cfgStore)
+		require.NoError(t, err)
+		require.Equal(t, databaseTypeMemOption, p.DBType())
+
+		s, err := p.OpenStore("store1")
+		require.NoError(t, err)
+
+		require.NoError(t, s.Put("key1", []byte("value1")))
+
+		v, err := s.Get("key1")
+		require.NoError(t, err)
+		require.Equal(t, "value1", string(v))
+
+		// The generated key-encryption key ID should be saved in the config store.
+		_, err = cfgStore.Get(dbEncryptionKidKey)
+		require.NoError(t, err)
+	})
+
+	t.Run("Rotate data key", func(t *testing.T) {
+		cfgStore, err := mem.NewProvider().OpenStore("cfg")
+		require.NoError(t, err)
+
+		parameters := &orbParameters{
+			kmsParams: &kmsParameters{
+				kmsSecretsDatabaseType: "mem",
+				kmsType:                kmsLocal,
+			},
+			dbParameters: &dbParameters{},
+		}
+
+		km, cr, err := createKMSAndCrypto(parameters, nil, mem.NewProvider(), cfgStore, nil)
+		require.NoError(t, err)
+
+		dbProvider := &storageProvider{&memProvider{mem.NewProvider()}, databaseTypeMemOption}
+
+		p, err := createEncryptedStoreProvider(parameters, dbProvider, km, cr, cfgStore)
+		require.NoError(t, err)
+
+		s, err := p.OpenStore("store1")
+		require.NoError(t, err)
+
+		require.NoError(t, s.Put("key1", []byte("value1")))
+
+		parameters.dbParameters.databaseEncryptionRotateDataKey = true
+
+		p, err = createEncryptedStoreProvider(parameters, dbProvider, km, cr, cfgStore)
+		require.NoError(t, err)
+
+		s, err = p.OpenStore("store1")
+		require.NoError(t, err)
+
+		require.NoError(t, s.Put("key2", []byte("value2")))
+
+		// Values encrypted with the previous data key may still be decrypted.
+		v, err := s.Get("key1")
+		require.NoError(t, err)
+		require.Equal(t, "value1", string(v))
+
+		v, err = s.Get("key2")
+		require.NoError(t, err)
+		require.Equal(t, "value2", string(v))
+
+		keyStore, err := dbProvider.OpenStore("orb-encryption-keys")
+		require.NoError(t, err)
+
+		it, err := keyStore.Query("dataKey")
+		require.NoError(t, err)
+
+		n, err := it.TotalItems()
+		require.NoError(t, err)
+		require.Equal(t, 2, n)
+	})
+
+	t.Run("AWS KMS -> missing key ID", func(t *testing.T) {
+		_, err := createEncryptedStoreProvider(&orbParameters{
+			kmsParams:    &kmsParameters{kmsType: kmsAWS},
+			dbParameters: &dbParameters{},
+		}, nil, nil, nil, nil)
+		require.EqualError(t, err, "database-encryption-key-id is required for the AWS KMS")
+	})
+
+	t.Run("Crypto does not support encryption", func(t *testing.T) {
+		_, err := createEncryptedStoreProvider(&orbParameters{
+			kmsParams:    &kmsParameters{kmsType: kmsWeb},
+			dbParameters: &dbParameters{databaseEncryptionKeyID: "kid1"},
+		}, nil, nil, &signOnlyCrypto{}, nil)
+		require.EqualError(t, err, "crypto for KMS type [web] does not support encryption")
+	})
+}
+
+type memProvider struct {
+	ariesspi.Provider
+}
+
+func (p *memProvider) Ping() error {
+	return nil
+}
+
+type signOnlyCrypto struct{}
+
+func (c *signOnlyCrypto) Sign([]byte, interface{}) ([]byte, error) {
+	return nil, nil
+}
+
 func TestCreateLocalKMS(t *testing.T) {
 	t.Run("Fail to create kms", func(t *testing.T) {
 		km, cr, err := createLocalKMS("", "", mem.NewProvider())
@@ -120,6 +241,27 @@ func TestCreateLocalKMS(t *testing.T) {
 	})
 }
 
+func TestCreateCacheProvider(t *testing.T) {
+	t.Run("Memory", func(t *testing.T) {
+		p, closeProvider, err := createCacheProvider(&cacheParams{cacheType: cacheTypeMemory})
+		require.NoError(t, err)
+		require.NotNil(t, p)
+
+		closeProvider()
+	})
+
+	t.Run("Redis connection error", func(t *testing.T) {
+		p, closeProvider, err := createCacheProvider(&cacheParams{
+			cacheType:    cacheTypeRedis,
+			redisAddress: "127.0.0.1:1",
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "create Redis cache provider")
+		require.Nil(t, p)
+		require.Nil(t, closeProvider)
+	})
+}
+
 type mockMetricsProvider struct {
 }
 
@@ -41,6 +41,7 @@ const (
 	FieldSize                   = "size"
 	FieldMaxSize                = "maxSize"
 	FieldCacheExpiration        = "cacheExpiration"
+	FieldCacheName              = "cacheName"
 	FieldTarget                 = "target"
 	FieldTargets                = "targets"
 	FieldTopic                  = "topic"
@@ -93,6 +94,7 @@ const (
 	FieldAnchorCID              = "anchorCid"
 	FieldCIDVersion             = "cidVersion"
 	FieldMultihash              = "multihash"
+	FieldMultihashCode          = "multihashCode"
 	FieldCASData                = "casData"
 	FieldDomain                 = "domain"
 	FieldLink                   = "link"
@@ -147,6 +149,7 @@ const (
 	FieldAge                    = "age"
 	FieldMinAge                 = "minAge"
 	FieldDuration               = "duration"
+	FieldDryRun                 = "dryRun"
 )
 
 // WithError sets the error field.
@@ -280,6 +283,11 @@ func WithTargetIRIs(value ...*url.URL) zap.Field {
 	return zap.Array(FieldTargets, NewURLArrayMarshaller(value))
 }
 
+// WithCacheName sets the cache-name field.
+func WithCacheName(value string) zap.Field {
+	return zap.String(FieldCacheName, value)
+}
+
 // WithTopic sets the topic field.
 func WithTopic(value string) zap.Field {
 	return zap.String(FieldTopic, value)
@@ -601,6 +609,11 @@ func WithMultihash(value string) zap.Field {
 	return zap.String(FieldMultihash, value)
 }
 
+// WithMultihashCode sets the multihash-code field.
+func WithMultihashCode(value uint) zap.Field {
+	return zap.Uint(FieldMultihashCode, value)
+}
+
 // WithCASData sets the cas-data field.
 func WithCASData(value []byte) zap.Field {
 	return zap.Binary(FieldCASData, value)
@@ -881,6 +894,11 @@ func WithDuration(value time.Duration) zap.Field {
 	return zap.Duration(FieldDuration, value)
 }
 
+// WithDryRun sets the dry-run field.
+func WithDryRun(value bool) zap.Field {
+	return zap.Bool(FieldDryRun, value)
+}
+
 type jsonMarshaller struct {
 	key string
 	obj interface{}
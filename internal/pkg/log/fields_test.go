@@ -74,7 +74,7 @@ func TestStandardFields(t *testing.T) {
 			WithActorIRI(u1), WithActivityID(u2), WithActivityType("Create"),
 			WithServiceIRI(parseURL(t, u2.String())), WithServiceName("service1"),
 			WithServiceEndpoint("/services/service1"),
-			WithSize(1234), WithCacheExpiration(12*time.Second),
+			WithSize(1234), WithCacheExpiration(12*time.Second), WithCacheName("some-cache"),
 			WithTargetIRI(u1), WithTopic("queue1"),
 			WithHTTPStatus(http.StatusNotFound), WithParameter("param1"),
 			WithReferenceType("followers"), WithURI(u2), WithURIs(u1, u2),
@@ -111,6 +111,7 @@ func TestStandardFields(t *testing.T) {
 		require.Equal(t, u2.String(), l.ServiceIri)
 		require.Equal(t, 1234, l.Size)
 		require.Equal(t, `12s`, l.CacheExpiration)
+		require.Equal(t, "some-cache", l.CacheName)
 		require.Equal(t, u1.String(), l.Target)
 		require.Equal(t, `queue1`, l.Topic)
 		require.Equal(t, 404, l.HTTPStatus)
@@ -176,7 +177,8 @@ func TestStandardFields(t *testing.T) {
 			WithWitnessURIString(u1.String()), WithWitnessURIStrings(u1.String(), u2.String()),
 			WithHash("hash1"), WithAnchorOriginEndpoint(aoep), WithKey("key1"),
 			WithCID("cid1"), WithResolvedCID("cid2"), WithAnchorCID("cid3"),
-			WithCIDVersion(1), WithMultihash("fsdfervs"), WithCASData([]byte("cas data")),
+			WithCIDVersion(1), WithMultihash("fsdfervs"), WithMultihashCode(22),
+			WithCASData([]byte("cas data")),
 			WithDomain(u1.String()), WithLink(u2.String()), WithLinks(u1.String(), u2.String()),
 			WithTaskMgrInstanceID("12345"), WithRetries(7), WithMaxRetries(12),
 			WithSubscriberPoolSize(30), WithTaskMonitorInterval(5*time.Second),
@@ -212,6 +214,7 @@ func TestStandardFields(t *testing.T) {
 		require.Equal(t, "cid3", l.AnchorCID)
 		require.Equal(t, 1, l.CIDVersion)
 		require.Equal(t, "fsdfervs", l.Multihash)
+		require.Equal(t, uint(22), l.MultihashCode)
 
 		casData, err := base64.StdEncoding.DecodeString(l.CASData)
 		require.NoError(t, err)
@@ -267,7 +270,7 @@ func TestStandardFields(t *testing.T) {
 			WithAnchorString("anchor1"), WithJRD(jrd), WithBackoff(5*time.Second), WithTimeout(2*time.Minute),
 			WithLogMonitor(logMonitor), WithLogMonitors([]*mockObject{logMonitor, logMonitor}),
 			WithMaxTime(time.Hour), WithIndex(3), WithFromIndexUint64(9), WithToIndexUint64(13),
-			WithSource("inbox"), WithAge(time.Minute), WithMinAge(10*time.Minute),
+			WithSource("inbox"), WithAge(time.Minute), WithMinAge(10*time.Minute), WithDryRun(true),
 		)
 
 		l := unmarshalLogData(t, stdOut.Bytes())
@@ -316,6 +319,7 @@ func TestStandardFields(t *testing.T) {
 		require.Equal(t, "inbox", l.Source)
 		require.Equal(t, "1m0s", l.Age)
 		require.Equal(t, "10m0s", l.MinAge)
+		require.True(t, l.DryRun)
 	})
 
 	t.Run("json fields 4", func(t *testing.T) {
@@ -359,6 +363,7 @@ type logData struct {
 	ServiceEndpoint        string              `json:"serviceEndpoint"`
 	Size                   int                 `json:"size"`
 	CacheExpiration        string              `json:"cacheExpiration"`
+	CacheName              string              `json:"cacheName"`
 	Target                 string              `json:"target"`
 	Topic                  string              `json:"topic"`
 	HTTPStatus             int                 `json:"httpStatus"`
@@ -411,6 +416,7 @@ type logData struct {
 	AnchorCID              string              `json:"anchorCid"`
 	CIDVersion             int                 `json:"cidVersion"`
 	Multihash              string              `json:"multihash"`
+	MultihashCode          uint                `json:"multihashCode"`
 	CASData                string              `json:"casData"`
 	Domain                 string              `json:"domain"`
 	Link                   string              `json:"link"`
@@ -476,6 +482,7 @@ type logData struct {
 	Source                 string              `json:"source"`
 	Age                    string              `json:"age"`
 	MinAge                 string              `json:"minAge"`
+	DryRun                 bool                `json:"dryRun"`
 }
 
 func unmarshalLogData(t *testing.T, b []byte) *logData {
@@ -17,13 +17,14 @@ import (
 	"sort"
 	"time"
 
-	"github.com/bluele/gcache"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
 
 	"github.com/trustbloc/orb/internal/pkg/log"
 	"github.com/trustbloc/orb/pkg/activitypub/client/transport"
 	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	"github.com/trustbloc/orb/pkg/cache"
+	"github.com/trustbloc/orb/pkg/cache/memory"
 	discoveryrest "github.com/trustbloc/orb/pkg/discovery/endpoint/restapi"
 	docutil "github.com/trustbloc/orb/pkg/document/util"
 	orberrors "github.com/trustbloc/orb/pkg/errors"
@@ -35,6 +36,9 @@ var logger = log.New("activitypub_client")
 const (
 	defaultCacheSize       = 100
 	defaultCacheExpiration = time.Minute
+
+	actorCacheName     = "activitypub-actor"
+	publicKeyCacheName = "activitypub-public-key"
 )
 
 // ErrNotFound is returned when the object is not found or the iterator has reached the end.
@@ -88,6 +92,10 @@ type serviceResolver interface {
 type Config struct {
 	CacheSize       int
 	CacheExpiration time.Duration
+
+	// CacheProvider is used to create the actor and public key caches. If not set
+	// then in-memory caches are used.
+	CacheProvider cache.Provider
 }
 
 // Client implements an ActivityPub client which retrieves ActivityPub objects (such as actors, activities,
@@ -95,8 +103,8 @@ type Config struct {
 type Client struct {
 	httpTransport
 
-	actorCache     gcache.Cache
-	publicKeyCache gcache.Cache
+	actorCache     cache.Cache
+	publicKeyCache cache.Cache
 	fetchPublicKey verifiable.PublicKeyFetcher
 	resolver       serviceResolver
 }
@@ -123,17 +131,27 @@ func New(cfg Config, t httpTransport, fetchPublicKey verifiable.PublicKeyFetcher
 
 	logger.Debug("Creating actor cache", log.WithSize(cacheSize), log.WithCacheExpiration(cacheExpiration))
 
-	c.actorCache = gcache.New(cacheSize).ARC().
-		Expiration(cacheExpiration).
-		LoaderFunc(func(i interface{}) (interface{}, error) {
-			return c.loadActor(i.(string)) //nolint:forcetypeassert
-		}).Build()
-
-	c.publicKeyCache = gcache.New(cacheSize).ARC().
-		Expiration(cacheExpiration).
-		LoaderFunc(func(i interface{}) (interface{}, error) {
-			return c.loadPublicKey(i.(string)) //nolint:forcetypeassert
-		}).Build()
+	cacheProvider := cfg.CacheProvider
+
+	if cacheProvider == nil {
+		cacheProvider = memory.New()
+	}
+
+	c.actorCache = cacheProvider.NewCache(actorCacheName,
+		func(key string) (interface{}, error) {
+			return c.loadActor(key)
+		},
+		cache.WithSize(cacheSize), cache.WithExpiration(cacheExpiration),
+		cache.WithCodec(cache.NewJSONCodec(func() interface{} { return &vocab.ActorType{} })),
+	)
+
+	c.publicKeyCache = cacheProvider.NewCache(publicKeyCacheName,
+		func(key string) (interface{}, error) {
+			return c.loadPublicKey(key)
+		},
+		cache.WithSize(cacheSize), cache.WithExpiration(cacheExpiration),
+		cache.WithCodec(cache.NewJSONCodec(func() interface{} { return &vocab.PublicKeyType{} })),
+	)
 
 	return c
 }
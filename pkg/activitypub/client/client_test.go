@@ -33,8 +33,6 @@ import (
 	"github.com/trustbloc/orb/pkg/internal/testutil"
 )
 
-//go:generate counterfeiter -o ../mocks/httptransport.gen.go --fake-name HTTPTransport . httpTransport
-
 func TestClient_GetActor(t *testing.T) {
 	actorIRI := testutil.MustParseURL("https://example.com/services/service1")
 
@@ -24,6 +24,21 @@ type HTTPTransport struct {
 		result1 *http.Response
 		result2 error
 	}
+	PostStub        func(ctx context.Context, req *transport.Request, payload []byte) (*http.Response, error)
+	postMutex       sync.RWMutex
+	postArgsForCall []struct {
+		ctx     context.Context
+		req     *transport.Request
+		payload []byte
+	}
+	postReturns struct {
+		result1 *http.Response
+		result2 error
+	}
+	postReturnsOnCall map[int]struct {
+		result1 *http.Response
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -80,11 +95,71 @@ func (fake *HTTPTransport) GetReturnsOnCall(i int, result1 *http.Response, resul
 	}{result1, result2}
 }
 
+func (fake *HTTPTransport) Post(ctx context.Context, req *transport.Request, payload []byte) (*http.Response, error) {
+	var payloadCopy []byte
+	if payload != nil {
+		payloadCopy = make([]byte, len(payload))
+		copy(payloadCopy, payload)
+	}
+	fake.postMutex.Lock()
+	ret, specificReturn := fake.postReturnsOnCall[len(fake.postArgsForCall)]
+	fake.postArgsForCall = append(fake.postArgsForCall, struct {
+		ctx     context.Context
+		req     *transport.Request
+		payload []byte
+	}{ctx, req, payloadCopy})
+	fake.recordInvocation("Post", []interface{}{ctx, req, payloadCopy})
+	fake.postMutex.Unlock()
+	if fake.PostStub != nil {
+		return fake.PostStub(ctx, req, payload)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.postReturns.result1, fake.postReturns.result2
+}
+
+func (fake *HTTPTransport) PostCallCount() int {
+	fake.postMutex.RLock()
+	defer fake.postMutex.RUnlock()
+	return len(fake.postArgsForCall)
+}
+
+func (fake *HTTPTransport) PostArgsForCall(i int) (context.Context, *transport.Request, []byte) {
+	fake.postMutex.RLock()
+	defer fake.postMutex.RUnlock()
+	return fake.postArgsForCall[i].ctx, fake.postArgsForCall[i].req, fake.postArgsForCall[i].payload
+}
+
+func (fake *HTTPTransport) PostReturns(result1 *http.Response, result2 error) {
+	fake.PostStub = nil
+	fake.postReturns = struct {
+		result1 *http.Response
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HTTPTransport) PostReturnsOnCall(i int, result1 *http.Response, result2 error) {
+	fake.PostStub = nil
+	if fake.postReturnsOnCall == nil {
+		fake.postReturnsOnCall = make(map[int]struct {
+			result1 *http.Response
+			result2 error
+		})
+	}
+	fake.postReturnsOnCall[i] = struct {
+		result1 *http.Response
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *HTTPTransport) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.getMutex.RLock()
 	defer fake.getMutex.RUnlock()
+	fake.postMutex.RLock()
+	defer fake.postMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
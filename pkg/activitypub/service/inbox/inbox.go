@@ -26,6 +26,7 @@ import (
 	"github.com/trustbloc/orb/pkg/activitypub/vocab"
 	orberrors "github.com/trustbloc/orb/pkg/errors"
 	"github.com/trustbloc/orb/pkg/lifecycle"
+	"github.com/trustbloc/orb/pkg/maintenance"
 	"github.com/trustbloc/orb/pkg/pubsub/spi"
 	"github.com/trustbloc/orb/pkg/pubsub/wmlogger"
 )
@@ -77,6 +78,7 @@ type Inbox struct {
 	metrics                metricsProvider
 	verifyActorInSignature bool
 	logger                 *log.Log
+	gate                   *maintenance.Gate
 }
 
 // New returns a new ActivityPub inbox.
@@ -91,6 +93,7 @@ func New(cnfg *Config, s store.Store, pubSub pubSub, activityHandler service.Act
 		jsonUnmarshal:   json.Unmarshal,
 		metrics:         metrics,
 		logger:          log.New(loggerModule, log.WithFields(log.WithServiceName(cfg.ServiceEndpoint))),
+		gate:            maintenance.NewGate(),
 	}
 
 	h.Lifecycle = lifecycle.New(cfg.ServiceEndpoint,
@@ -146,6 +149,17 @@ func (h *Inbox) HTTPHandler() common.HTTPHandler {
 	return h.httpSubscriber
 }
 
+// Pause stops the handling of activities (which remain in the message queue) and waits for the activities
+// that are currently being handled.
+func (h *Inbox) Pause(ctx context.Context) error {
+	return h.gate.Pause(ctx)
+}
+
+// Resume resumes the handling of activities.
+func (h *Inbox) Resume() {
+	h.gate.Resume()
+}
+
 func (h *Inbox) start() {
 	// Start the router
 	go h.route()
@@ -189,6 +203,9 @@ func (h *Inbox) listen() {
 }
 
 func (h *Inbox) handle(msg *message.Message) {
+	done := h.gate.Enter()
+	defer done()
+
 	startTime := time.Now()
 
 	activity, err := h.handleActivityMsg(msg)
@@ -0,0 +1,45 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package outbox
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/internal/testutil"
+)
+
+// TestCompatibility replays activity messages that were recorded from previous releases. A recorded message
+// must be added to testdata/compat/activity whenever the schema version of the activity message changes.
+func TestCompatibility(t *testing.T) {
+	h := &Outbox{jsonUnmarshal: json.Unmarshal}
+
+	for name, msg := range testutil.LoadRecordedMessages(t, "testdata/compat/activity") {
+		msg := msg
+
+		t.Run(name, func(t *testing.T) {
+			activityMsg, err := h.decodeActivityMsg(msg)
+			require.NoError(t, err)
+			require.NotNil(t, activityMsg.Activity)
+			require.NotNil(t, activityMsg.Activity.ID())
+
+			switch activityMsg.Type {
+			case broadcastType:
+				require.NotEmpty(t, activityMsg.ExcludeIRIs.URLs())
+			case resolveAndDeliverType:
+				require.NotNil(t, activityMsg.TargetIRI.URL())
+				require.NotEmpty(t, activityMsg.ExcludeIRIs.URLs())
+			case deliverType:
+				require.NotNil(t, activityMsg.TargetIRI.URL())
+			default:
+				t.Fatalf("unexpected message type [%s]", activityMsg.Type)
+			}
+		})
+	}
+}
@@ -19,7 +19,6 @@ import (
 	"github.com/ThreeDotsLabs/watermill"
 	wmhttp "github.com/ThreeDotsLabs/watermill-http/pkg/http"
 	"github.com/ThreeDotsLabs/watermill/message"
-	"github.com/bluele/gcache"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
@@ -31,9 +30,13 @@ import (
 	store "github.com/trustbloc/orb/pkg/activitypub/store/spi"
 	"github.com/trustbloc/orb/pkg/activitypub/store/storeutil"
 	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	"github.com/trustbloc/orb/pkg/cache"
+	"github.com/trustbloc/orb/pkg/cache/memory"
 	discoveryrest "github.com/trustbloc/orb/pkg/discovery/endpoint/restapi"
 	orberrors "github.com/trustbloc/orb/pkg/errors"
 	"github.com/trustbloc/orb/pkg/lifecycle"
+	"github.com/trustbloc/orb/pkg/maintenance"
+	"github.com/trustbloc/orb/pkg/pubsub/envelope"
 	"github.com/trustbloc/orb/pkg/pubsub/spi"
 )
 
@@ -44,6 +47,8 @@ const (
 	defaultCacheSize              = 100
 	defaultCacheExpiration        = time.Minute
 	defaultSubscriberPoolSize     = 5
+
+	iriCacheName = "activitypub-outbox-iri"
 )
 
 type pubSub interface {
@@ -63,6 +68,9 @@ type Config struct {
 	CacheSize             int
 	CacheExpiration       time.Duration
 	SubscriberPoolSize    int
+
+	// CacheProvider is used to create the IRI cache. If not set then an in-memory cache is used.
+	CacheProvider cache.Provider `json:"-"`
 }
 
 type activityPubClient interface {
@@ -88,11 +96,12 @@ type Outbox struct {
 	resourceResolver resourceResolver
 	jsonMarshal      func(v interface{}) ([]byte, error)
 	jsonUnmarshal    func(data []byte, v interface{}) error
-	iriCache         gcache.Cache
+	iriCache         cache.Cache
 	metrics          metricsProvider
 	followersPath    string
 	witnessesPath    string
 	logger           *log.Log
+	gate             *maintenance.Gate
 }
 
 type httpTransport interface {
@@ -135,6 +144,7 @@ func New(cnfg *Config, s store.Store, pubSub pubSub, t httpTransport, activityHa
 		followersPath:    cfg.ServiceEndpointURL.String() + resthandler.FollowersPath,
 		witnessesPath:    cfg.ServiceEndpointURL.String() + resthandler.WitnessesPath,
 		logger:           logger,
+		gate:             maintenance.NewGate(),
 	}
 
 	h.Lifecycle = lifecycle.New(cfg.ServiceName,
@@ -144,15 +154,38 @@ func New(cnfg *Config, s store.Store, pubSub pubSub, t httpTransport, activityHa
 
 	logger.Debug("Creating IRI cache", log.WithSize(cfg.CacheSize), log.WithCacheExpiration(cfg.CacheExpiration))
 
-	h.iriCache = gcache.New(cfg.CacheSize).ARC().
-		Expiration(cfg.CacheExpiration).
-		LoaderFunc(func(i interface{}) (interface{}, error) {
-			return h.resolveActorIRI(i.(*url.URL)) //nolint:forcetypeassert
-		}).Build()
+	cacheProvider := cfg.CacheProvider
+
+	if cacheProvider == nil {
+		cacheProvider = memory.New()
+	}
+
+	h.iriCache = cacheProvider.NewCache(iriCacheName,
+		func(key string) (interface{}, error) {
+			iri, err := url.Parse(key)
+			if err != nil {
+				return nil, fmt.Errorf("parse IRI [%s]: %w", key, err)
+			}
+
+			return h.resolveActorIRI(iri)
+		},
+		cache.WithSize(cfg.CacheSize), cache.WithExpiration(cfg.CacheExpiration), cache.WithCodec(&iriCodec{}),
+	)
 
 	return h, nil
 }
 
+// Pause stops the handling of activity messages (which remain in the message queue) and waits for the
+// messages that are currently being handled.
+func (h *Outbox) Pause(ctx context.Context) error {
+	return h.gate.Pause(ctx)
+}
+
+// Resume resumes the handling of activity messages.
+func (h *Outbox) Resume() {
+	h.gate.Resume()
+}
+
 func (h *Outbox) start() {
 	go h.listen()
 }
@@ -181,6 +214,10 @@ const (
 	resolveAndDeliverType messageType = "resolve-and-deliver"
 )
 
+// activitySchema is the schema of the activity message. The version must be incremented (and an upcaster
+// added) whenever the activityMessage structure changes in a way that isn't backward compatible.
+var activitySchema = envelope.New("activity", 1)
+
 type activityMessage struct {
 	Type        messageType                  `json:"type"`
 	Activity    *vocab.ActivityType          `json:"activity"`
@@ -219,6 +256,9 @@ func (h *Outbox) Post(activity *vocab.ActivityType, exclude ...*url.URL) (*url.U
 }
 
 func (h *Outbox) handle(msg *message.Message) {
+	done := h.gate.Enter()
+	defer done()
+
 	activity, err := h.handleActivityMsg(msg)
 	if err != nil {
 		if orberrors.IsTransient(err) {
@@ -238,15 +278,30 @@ func (h *Outbox) handle(msg *message.Message) {
 	}
 }
 
-func (h *Outbox) handleActivityMsg(msg *message.Message) (*vocab.ActivityType, error) {
-	h.logger.Debug("Handling activity message", log.WithMessageID(msg.UUID))
+// decodeActivityMsg upcasts the payload of the given message to the current schema version and unmarshals it.
+func (h *Outbox) decodeActivityMsg(msg *message.Message) (*activityMessage, error) {
+	payload, err := activitySchema.Payload(msg)
+	if err != nil {
+		return nil, fmt.Errorf("activity message [%s]: %w", msg.UUID, err)
+	}
 
 	activityMsg := &activityMessage{}
 
-	if err := h.jsonUnmarshal(msg.Payload, activityMsg); err != nil {
+	if err := h.jsonUnmarshal(payload, activityMsg); err != nil {
 		return nil, fmt.Errorf("unmarshal activity message [%s]: %w", msg.UUID, err)
 	}
 
+	return activityMsg, nil
+}
+
+func (h *Outbox) handleActivityMsg(msg *message.Message) (*vocab.ActivityType, error) {
+	h.logger.Debug("Handling activity message", log.WithMessageID(msg.UUID))
+
+	activityMsg, err := h.decodeActivityMsg(msg)
+	if err != nil {
+		return nil, err
+	}
+
 	switch activityMsg.Type {
 	case broadcastType:
 		h.logger.Debug("Handling 'broadcast' activity message",
@@ -377,7 +432,7 @@ func (h *Outbox) publishBroadcastMessage(activity *vocab.ActivityType, excludeIR
 		return orberrors.NewBadRequest(fmt.Errorf("marshal: %w", err))
 	}
 
-	msg := message.NewMessage(watermill.NewUUID(), msgBytes)
+	msg := activitySchema.NewMessage(msgBytes)
 
 	h.logger.Debug("Publishing activity message to topic", log.WithMessageID(msg.UUID),
 		log.WithActivityID(activity.ID()), log.WithTopic(h.Topic))
@@ -399,7 +454,7 @@ func (h *Outbox) publishResolveAndDeliverMessage(activity *vocab.ActivityType, t
 		return orberrors.NewBadRequest(fmt.Errorf("marshal: %w", err))
 	}
 
-	msg := message.NewMessage(watermill.NewUUID(), msgBytes)
+	msg := activitySchema.NewMessage(msgBytes)
 
 	h.logger.Debug("Publishing 'resolve-and-deliver' activity message to topic",
 		log.WithMessageID(msg.UUID), log.WithActivityID(activity.ID()), log.WithTopic(h.Topic))
@@ -419,7 +474,7 @@ func (h *Outbox) publishDeliverMessage(activity *vocab.ActivityType, target *url
 		return orberrors.NewBadRequest(fmt.Errorf("marshal: %w", err))
 	}
 
-	msg := message.NewMessage(watermill.NewUUID(), msgBytes)
+	msg := activitySchema.NewMessage(msgBytes)
 
 	h.logger.Debug("Publishing 'deliver' activity message to topic",
 		log.WithMessageID(msg.UUID), log.WithActivityID(activity.ID()),
@@ -549,7 +604,7 @@ func (h *Outbox) resolveReferences(refType store.ReferenceType) ([]*resolveIRIRe
 }
 
 func (h *Outbox) doResolveActorIRI(iri *url.URL) ([]*url.URL, error) {
-	result, err := h.iriCache.Get(iri)
+	result, err := h.iriCache.Get(iri.String())
 	if err != nil {
 		h.logger.Debug("Got error resolving IRI from cache for actor", log.WithActorIRI(iri), log.WithError(err))
 
@@ -773,3 +828,42 @@ func contains(arr []*url.URL, u *url.URL) bool {
 
 	return false
 }
+
+// iriCodec marshals and unmarshals the list of IRIs in the IRI cache.
+type iriCodec struct{}
+
+func (c *iriCodec) Marshal(value interface{}) ([]byte, error) {
+	iris, ok := value.([]*url.URL)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type [%T] in IRI cache", value)
+	}
+
+	strIRIs := make([]string, len(iris))
+
+	for i, iri := range iris {
+		strIRIs[i] = iri.String()
+	}
+
+	return json.Marshal(strIRIs)
+}
+
+func (c *iriCodec) Unmarshal(data []byte) (interface{}, error) {
+	var strIRIs []string
+
+	if err := json.Unmarshal(data, &strIRIs); err != nil {
+		return nil, fmt.Errorf("unmarshal IRIs: %w", err)
+	}
+
+	iris := make([]*url.URL, len(strIRIs))
+
+	for i, strIRI := range strIRIs {
+		iri, err := url.Parse(strIRI)
+		if err != nil {
+			return nil, fmt.Errorf("parse IRI [%s]: %w", strIRI, err)
+		}
+
+		iris[i] = iri
+	}
+
+	return iris, nil
+}
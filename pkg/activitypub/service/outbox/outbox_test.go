@@ -940,6 +940,37 @@ func handleMockCollectionPage(t *testing.T, collID *url.URL, uris []*url.URL,
 	require.NoError(t, err)
 }
 
+func TestIRICodec(t *testing.T) {
+	codec := &iriCodec{}
+
+	iri1 := testutil.MustParseURL("https://example1.com/services/orb")
+	iri2 := testutil.MustParseURL("https://example2.com/services/orb")
+
+	t.Run("success", func(t *testing.T) {
+		data, err := codec.Marshal([]*url.URL{iri1, iri2})
+		require.NoError(t, err)
+
+		value, err := codec.Unmarshal(data)
+		require.NoError(t, err)
+		require.Equal(t, []*url.URL{iri1, iri2}, value)
+	})
+
+	t.Run("invalid value type", func(t *testing.T) {
+		_, err := codec.Marshal("invalid")
+		require.EqualError(t, err, "unexpected value type [string] in IRI cache")
+	})
+
+	t.Run("unmarshal error", func(t *testing.T) {
+		_, err := codec.Unmarshal([]byte("{"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal IRIs")
+
+		_, err = codec.Unmarshal([]byte(`[":invalid"]`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parse IRI")
+	})
+}
+
 type mockService struct {
 	isConnectedErr error
 	healthCheckErr error
@@ -25,6 +25,7 @@ import (
 	"github.com/trustbloc/orb/pkg/activitypub/service/spi"
 	store "github.com/trustbloc/orb/pkg/activitypub/store/spi"
 	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	"github.com/trustbloc/orb/pkg/cache"
 	"github.com/trustbloc/orb/pkg/lifecycle"
 	pubsub "github.com/trustbloc/orb/pkg/pubsub/spi"
 )
@@ -57,6 +58,9 @@ type Config struct {
 	IRICacheExpiration       time.Duration
 	OutboxSubscriberPoolSize int
 	InboxSubscriberPoolSize  int
+
+	// CacheProvider is used to create the caches. If not set then in-memory caches are used.
+	CacheProvider cache.Provider
 }
 
 // Service implements an ActivityPub service which has an inbox, outbox, and
@@ -121,6 +125,7 @@ func New(cfg *Config, activityStore store.Store, t httpTransport, sigVerifier si
 			CacheSize:          cfg.IRICacheSize,
 			CacheExpiration:    cfg.IRICacheExpiration,
 			SubscriberPoolSize: cfg.OutboxSubscriberPoolSize,
+			CacheProvider:      cfg.CacheProvider,
 		},
 		activityStore, pubSub,
 		t, outboxHandler, activityPubClient, resourceResolver, m,
@@ -180,6 +185,28 @@ func (s *Service) stop() {
 	s.activityHandler.Stop()
 }
 
+// Pause stops the handling of inbox and outbox activities and waits for the activities that are currently
+// being handled. Activities remain in the message queue until the service is resumed.
+func (s *Service) Pause(ctx context.Context) error {
+	if err := s.inbox.Pause(ctx); err != nil {
+		return fmt.Errorf("pause inbox: %w", err)
+	}
+
+	if err := s.outbox.Pause(ctx); err != nil {
+		s.inbox.Resume()
+
+		return fmt.Errorf("pause outbox: %w", err)
+	}
+
+	return nil
+}
+
+// Resume resumes the handling of inbox and outbox activities.
+func (s *Service) Resume() {
+	s.outbox.Resume()
+	s.inbox.Resume()
+}
+
 // Outbox returns the outbox, which allows clients to post activities.
 func (s *Service) Outbox() spi.Outbox {
 	return s.outbox
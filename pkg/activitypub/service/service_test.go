@@ -78,6 +78,47 @@ func TestNewService(t *testing.T) {
 	require.Equal(t, lifecycle.StateStopped, service1.State())
 }
 
+func TestService_PauseResume(t *testing.T) {
+	service1IRI := testutil.MustParseURL("http://localhost:8301/services/service1")
+
+	service1, store1, _, _ := newServiceWithMocks(t, "/services/service1", service1IRI)
+
+	service1.Start()
+	defer service1.Stop()
+
+	require.NoError(t, service1.Pause(context.Background()))
+
+	create := vocab.NewCreateActivity(
+		vocab.NewObjectProperty(vocab.WithAnchorEvent(
+			aptestutil.NewMockAnchorEvent(t, aptestutil.NewMockAnchorLink(t)))),
+	)
+
+	_, err := service1.Outbox().Post(create)
+	require.NoError(t, err)
+
+	outboxContains := func() bool {
+		it, err := store1.QueryActivities(
+			spi.NewCriteria(
+				spi.WithObjectIRI(service1IRI),
+				spi.WithReferenceType(spi.Outbox),
+			))
+		require.NoError(t, err)
+
+		activities, err := storeutil.ReadActivities(it, -1)
+		require.NoError(t, err)
+
+		return containsActivity(activities, create.ID())
+	}
+
+	// The activity isn't stored while the service is paused.
+	time.Sleep(200 * time.Millisecond)
+	require.False(t, outboxContains())
+
+	service1.Resume()
+
+	require.Eventually(t, outboxContains, time.Second, 20*time.Millisecond)
+}
+
 func TestService_Create(t *testing.T) {
 	log.SetLevel(wmlogger.Module, log.WARNING)
 
@@ -267,7 +267,7 @@ func (g *Generator) ValidateAnchorCredential(vc *verifiable.Credential, contentB
 		return fmt.Errorf("parse credential subject: %w", err)
 	}
 
-	anchorHL, err := hashlink.New().CreateHashLink(contentBytes, nil)
+	anchorHL, err := createHashLinkWithAlgorithmOf(s.HRef, contentBytes)
 	if err != nil {
 		return fmt.Errorf("create hashlink of data: %w", err)
 	}
@@ -290,6 +290,23 @@ func (g *Generator) ValidateAnchorCredential(vc *verifiable.Credential, contentB
 	return nil
 }
 
+// createHashLinkWithAlgorithmOf creates a hashlink of the given content using the hash algorithm of the given
+// hashlink, so that anchors created with any of the supported algorithms may be validated. The default algorithm
+// is used if the algorithm of the given hashlink can't be determined.
+func createHashLinkWithAlgorithmOf(hl string, content []byte) (string, error) {
+	hlParser := hashlink.New()
+
+	var opts []hashlink.Option
+
+	if resourceHash, err := hashlink.GetResourceHashFromHashLink(hl); err == nil {
+		if code, e := hlParser.GetMultihashCode(resourceHash); e == nil && hashlink.IsSupportedMultihashCode(code) {
+			opts = append(opts, hashlink.WithMultihashCode(code))
+		}
+	}
+
+	return hashlink.New(opts...).CreateHashLink(content, nil)
+}
+
 func getPreviousAnchors(resources []*linkset.Item, previous []*url.URL) ([]*subject.SuffixAnchor, error) {
 	var previousAnchors []*subject.SuffixAnchor
 
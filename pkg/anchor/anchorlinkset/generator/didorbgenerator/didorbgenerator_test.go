@@ -8,6 +8,7 @@ package didorbgenerator
 
 import (
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
@@ -16,6 +17,7 @@ import (
 
 	"github.com/trustbloc/orb/pkg/activitypub/vocab"
 	"github.com/trustbloc/orb/pkg/anchor/subject"
+	"github.com/trustbloc/orb/pkg/hashlink"
 	"github.com/trustbloc/orb/pkg/internal/testutil"
 )
 
@@ -205,6 +207,25 @@ func TestGenerator_ValidateAnchorCredentialSubject(t *testing.T) {
 		require.Contains(t, err.Error(), "anchor in anchor linkset is nil")
 	})
 
+	t.Run("Other multihash algorithm -> success", func(t *testing.T) {
+		content := testutil.GetCanonicalBytes(t, linksetJSON3)
+
+		for _, code := range []uint{hashlink.SHA3_256, hashlink.BLAKE2b_256} {
+			hl, err := hashlink.New(hashlink.WithMultihashCode(code)).CreateHashLink(content, nil)
+			require.NoError(t, err)
+
+			vc, err := verifiable.ParseCredential(
+				[]byte(strings.Replace(vcJSON, "hl:uEiCHU0O97gyQ8oq5O-pdxuacArLGIHu-_MFSfA4g7YSf3A", hl, 1)),
+				verifiable.WithDisabledProofCheck(),
+				verifiable.WithJSONLDDocumentLoader(testutil.GetLoader(t)),
+				verifiable.WithStrictValidation(),
+			)
+			require.NoError(t, err)
+
+			require.NoError(t, New().ValidateAnchorCredential(vc, content))
+		}
+	})
+
 	t.Run("Invalid subject href -> error", func(t *testing.T) {
 		vc, err := verifiable.ParseCredential([]byte(vcInvalidHRefJSON),
 			verifiable.WithDisabledProofCheck(),
@@ -16,6 +16,7 @@ import (
 
 	"github.com/trustbloc/orb/internal/pkg/log"
 	"github.com/trustbloc/orb/pkg/anchor/subject"
+	"github.com/trustbloc/orb/pkg/cas/extendedcasclient"
 	"github.com/trustbloc/orb/pkg/errors"
 	"github.com/trustbloc/orb/pkg/linkset"
 )
@@ -31,12 +32,20 @@ type anchorLinksetBuilder interface {
 	GetPayloadFromAnchorLink(anchorLink *linkset.Link) (*subject.Payload, error)
 }
 
+type anchorMultihashProvider interface {
+	AnchorMultihashCode(genesisTime uint64) (uint, error)
+}
+
 // Providers for anchor graph.
 type Providers struct {
 	CasWriter            casWriter
 	CasResolver          casResolver
 	DocLoader            ld.DocumentLoader
 	AnchorLinksetBuilder anchorLinksetBuilder
+
+	// AnchorMultihashProvider is optional. If set (and the CAS writer supports CID format options) then the anchor
+	// is hashed using the anchor multihash algorithm of the protocol version of the anchor.
+	AnchorMultihashProvider anchorMultihashProvider
 }
 
 // New creates new graph manager.
@@ -62,7 +71,12 @@ func (g *Graph) Add(anchorLinkset *linkset.Linkset) (string, error) { //nolint:i
 		return "", fmt.Errorf("failed to marshal anchor: %w", err)
 	}
 
-	hl, err := g.CasWriter.Write(canonicalBytes)
+	writer, err := g.casWriterForAnchor(anchorLinkset)
+	if err != nil {
+		return "", fmt.Errorf("failed to add anchor to graph: %w", err)
+	}
+
+	hl, err := writer.Write(canonicalBytes)
 	if err != nil {
 		return "", errors.NewTransient(fmt.Errorf("failed to add anchor to graph: %w", err))
 	}
@@ -72,6 +86,27 @@ func (g *Graph) Add(anchorLinkset *linkset.Linkset) (string, error) { //nolint:i
 	return hl, nil
 }
 
+// casWriterForAnchor returns a CAS writer which hashes the anchor using the anchor multihash algorithm of the
+// protocol version (genesis time) of the anchor.
+func (g *Graph) casWriterForAnchor(anchorLinkset *linkset.Linkset) (casWriter, error) {
+	formatter, ok := g.CasWriter.(extendedcasclient.CIDFormatter)
+	if !ok || g.AnchorMultihashProvider == nil {
+		return g.CasWriter, nil
+	}
+
+	payload, err := g.AnchorLinksetBuilder.GetPayloadFromAnchorLink(anchorLinkset.Link())
+	if err != nil {
+		return nil, fmt.Errorf("get payload from anchor link: %w", err)
+	}
+
+	code, err := g.AnchorMultihashProvider.AnchorMultihashCode(payload.Version)
+	if err != nil {
+		return nil, fmt.Errorf("get anchor multihash code for version %d: %w", payload.Version, err)
+	}
+
+	return formatter.WithCIDFormat(extendedcasclient.WithMultihashCode(code)), nil
+}
+
 // Read reads anchor.
 func (g *Graph) Read(hl string) (*linkset.Linkset, error) {
 	anchorLinksetBytes, _, err := g.CasResolver.Resolve(nil, hl, nil)
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package graph
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -22,6 +23,7 @@ import (
 	"github.com/trustbloc/orb/pkg/anchor/subject"
 	casresolver "github.com/trustbloc/orb/pkg/cas/resolver"
 	"github.com/trustbloc/orb/pkg/datauri"
+	"github.com/trustbloc/orb/pkg/hashlink"
 	"github.com/trustbloc/orb/pkg/internal/testutil"
 	"github.com/trustbloc/orb/pkg/linkset"
 	"github.com/trustbloc/orb/pkg/store/cas"
@@ -62,6 +64,38 @@ func TestGraph_Add(t *testing.T) {
 		require.NoError(t, err)
 		require.NotEmpty(t, hl)
 	})
+
+	t.Run("success - multihash of protocol version", func(t *testing.T) {
+		graph := New(&Providers{
+			CasWriter:               casClient,
+			DocLoader:               testutil.GetLoader(t),
+			AnchorLinksetBuilder:    anchorlinkset.NewBuilder(generator.NewRegistry()),
+			AnchorMultihashProvider: &mockAnchorMultihashProvider{code: hashlink.SHA3_256},
+		})
+
+		hl, err := graph.Add(newDefaultMockAnchorEvent(t))
+		require.NoError(t, err)
+
+		resourceHash, err := hashlink.GetResourceHashFromHashLink(hl)
+		require.NoError(t, err)
+
+		code, err := hashlink.New().GetMultihashCode(resourceHash)
+		require.NoError(t, err)
+		require.Equal(t, hashlink.SHA3_256, code)
+	})
+
+	t.Run("error - anchor multihash provider error", func(t *testing.T) {
+		graph := New(&Providers{
+			CasWriter:               casClient,
+			DocLoader:               testutil.GetLoader(t),
+			AnchorLinksetBuilder:    anchorlinkset.NewBuilder(generator.NewRegistry()),
+			AnchorMultihashProvider: &mockAnchorMultihashProvider{err: errors.New("injected provider error")},
+		})
+
+		_, err := graph.Add(newDefaultMockAnchorEvent(t))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected provider error")
+	})
 }
 
 func TestGraph_Read(t *testing.T) {
@@ -302,6 +336,15 @@ func newMockAnchorLinkset(t *testing.T, payload *subject.Payload) *linkset.Links
 	return linkset.New(al)
 }
 
+type mockAnchorMultihashProvider struct {
+	code uint
+	err  error
+}
+
+func (m *mockAnchorMultihashProvider) AnchorMultihashCode(uint64) (uint, error) {
+	return m.code, m.err
+}
+
 type metricsProvider struct{}
 
 func (m *metricsProvider) CASWriteTime(value time.Duration) {
@@ -310,6 +353,9 @@ func (m *metricsProvider) CASWriteTime(value time.Duration) {
 func (m *metricsProvider) CASResolveTime(value time.Duration) {
 }
 
+func (m *metricsProvider) CASResolveSourceTime(source string, value time.Duration) {
+}
+
 func (m *metricsProvider) CASIncrementCacheHitCount() {
 }
 
@@ -50,6 +50,12 @@ type casResolver interface {
 	Resolve(webCASURL *url.URL, cid string, data []byte) ([]byte, string, error)
 }
 
+// casPrefetcher is optionally implemented by the CAS resolver in order to retrieve multiple
+// references in batches.
+type casPrefetcher interface {
+	Prefetch(hashesWithPossibleHints ...string)
+}
+
 type anchorPublisher interface {
 	PublishAnchor(anchor *anchorinfo.AnchorInfo) error
 }
@@ -190,6 +196,9 @@ func (h *AnchorEventHandler) ensureParentAnchorsAreProcessed(anchorRef *url.URL,
 	logger.Debug("Processing parents of anchor", log.WithTotal(len(unprocessedParents)),
 		log.WithAnchorURI(anchorRef), log.WithParents(unprocessedParents.HashLinks()))
 
+	// Retrieve the core index files of all of the unprocessed parents in batches instead of one at a time.
+	h.prefetch(unprocessedParents.anchors()...)
+
 	for _, parentAnchorInfo := range unprocessedParents {
 		logger.Info("Processing parent", log.WithAnchorURI(anchorRef), log.WithParent(parentAnchorInfo.Hashlink))
 
@@ -230,6 +239,8 @@ func (h *AnchorEventHandler) getUnprocessedParentAnchors(hl string, anchorLink *
 
 	var unprocessed []*anchorInfo
 
+	h.prefetch(relatedLink.Up()...)
+
 	for _, parentHL := range relatedLink.Up() {
 		if containsAnchor(unprocessed, parentHL.String()) {
 			logger.Debug("Not adding parent of anchor to the unprocessed list since it has already been added",
@@ -307,6 +318,21 @@ func (h *AnchorEventHandler) getUnprocessedParentAnchor(hl string, parentHL *url
 	}, nil
 }
 
+func (h *AnchorEventHandler) prefetch(refs ...*url.URL) {
+	prefetcher, ok := h.casResolver.(casPrefetcher)
+	if !ok || len(refs) == 0 {
+		return
+	}
+
+	hashLinks := make([]string, len(refs))
+
+	for i, ref := range refs {
+		hashLinks[i] = ref.String()
+	}
+
+	prefetcher.Prefetch(hashLinks...)
+}
+
 func prependAnchors(existingAnchors, newAnchors []*anchorInfo) []*anchorInfo {
 	resultingAnchors := existingAnchors
 
@@ -360,3 +386,15 @@ func (s anchorInfoSlice) HashLinks() []string {
 
 	return hashlinks
 }
+
+func (s anchorInfoSlice) anchors() []*url.URL {
+	var anchors []*url.URL
+
+	for _, ai := range s {
+		if ai.anchorLink != nil && ai.anchorLink.Anchor() != nil {
+			anchors = append(anchors, ai.anchorLink.Anchor())
+		}
+	}
+
+	return anchors
+}
@@ -175,6 +175,46 @@ func TestGetUnprocessedParentAnchorEvents(t *testing.T) {
 		require.Equal(t, parentHL, parents[1].Hashlink)
 	})
 
+	t.Run("Parents are prefetched", func(t *testing.T) {
+		casResolver := &mockPrefetchingResolver{CASResolver: &mocks2.CASResolver{}}
+		anchorLinkStore := &orbmocks.AnchorLinkStore{}
+
+		anchorLinkStore.GetLinksReturns(nil, nil)
+
+		casResolver.ResolveReturnsOnCall(0, []byte(testutil.GetCanonical(t, sampleParentAnchorLinkset)),
+			parentHL, nil)
+		casResolver.ResolveReturnsOnCall(1, []byte(testutil.GetCanonical(t, sampleGrandparentAnchorLinkset)),
+			grandparentHL, nil)
+
+		handler := New(&anchormocks.AnchorPublisher{}, casResolver, testutil.GetLoader(t),
+			time.Second, anchorLinkStore, registry)
+		require.NotNil(t, handler)
+
+		anchorEvent := &vocab.AnchorEventType{}
+
+		require.NoError(t, json.Unmarshal([]byte(sampleAnchorEvent), anchorEvent))
+
+		anchorLinkset := &linkset.Linkset{}
+		require.NoError(t, vocab.UnmarshalFromDoc(anchorEvent.Object().Document(), anchorLinkset))
+
+		parents, err := handler.getUnprocessedParentAnchors(hl, anchorLinkset.Link())
+		require.NoError(t, err)
+		require.Len(t, parents, 2)
+
+		// The parent and then the grandparent should have been prefetched.
+		require.Len(t, casResolver.prefetched, 2)
+		require.Equal(t, []string{parents[1].Hashlink}, casResolver.prefetched[0])
+		require.Equal(t, []string{parents[0].Hashlink}, casResolver.prefetched[1])
+
+		// The core index files of all of the parents should be prefetched.
+		casResolver.prefetched = nil
+
+		handler.prefetch(parents.anchors()...)
+
+		require.Len(t, casResolver.prefetched, 1)
+		require.Len(t, casResolver.prefetched[0], 2)
+	})
+
 	t.Run("Duplicate parents -> Success", func(t *testing.T) {
 		casResolver := &mocks2.CASResolver{}
 		anchorLinkStore := &orbmocks.AnchorLinkStore{}
@@ -800,3 +840,13 @@ const anchorLinksetInvalidVC = `{
     }
   ]
 }`
+
+type mockPrefetchingResolver struct {
+	*mocks2.CASResolver
+
+	prefetched [][]string
+}
+
+func (m *mockPrefetchingResolver) Prefetch(hashesWithPossibleHints ...string) {
+	m.prefetched = append(m.prefetched, hashesWithPossibleHints)
+}
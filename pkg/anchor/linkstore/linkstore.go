@@ -119,19 +119,45 @@ func (s *Store) DeleteLinks(links []*url.URL) error {
 func (s *Store) GetLinks(anchorHash string) ([]*url.URL, error) {
 	logger.Debug("Retrieving anchor link references for anchor hash", log.WithAnchorHash(anchorHash))
 
-	var err error
+	links, err := s.query(fmt.Sprintf("%s:%s", hashTag, anchorHash))
+	if err != nil {
+		return nil, fmt.Errorf("anchor [%s]: %w", anchorHash, err)
+	}
+
+	logger.Debug("Returning anchor references for anchor hash", log.WithAnchorHash(anchorHash), log.WithURIs(links...))
+
+	return links, nil
+}
+
+// GetAllLinks returns all of the links in the store.
+func (s *Store) GetAllLinks() ([]*url.URL, error) {
+	logger.Debug("Retrieving all anchor link references")
+
+	links, err := s.query(hashTag)
+	if err != nil {
+		return nil, fmt.Errorf("all anchors: %w", err)
+	}
+
+	logger.Debug("Returning all anchor references", log.WithTotal(len(links)))
 
-	query := fmt.Sprintf("%s:%s", hashTag, anchorHash)
+	return links, nil
+}
 
+func (s *Store) query(query string) ([]*url.URL, error) {
 	iter, err := s.store.Query(query)
 	if err != nil {
-		return nil, orberrors.NewTransient(fmt.Errorf("failed to get refs for anchor [%s] query[%s]: %w",
-			anchorHash, query, err))
+		return nil, orberrors.NewTransient(fmt.Errorf("failed to get refs for query[%s]: %w", query, err))
 	}
 
+	defer func() {
+		if errClose := iter.Close(); errClose != nil {
+			log.CloseIteratorError(logger, errClose)
+		}
+	}()
+
 	ok, err := iter.Next()
 	if err != nil {
-		return nil, orberrors.NewTransient(fmt.Errorf("iterator error for anchor [%s]: %w", anchorHash, err))
+		return nil, orberrors.NewTransient(fmt.Errorf("iterator error: %w", err))
 	}
 
 	var links []*url.URL
@@ -139,32 +165,29 @@ func (s *Store) GetLinks(anchorHash string) ([]*url.URL, error) {
 	for ok {
 		value, err := iter.Value()
 		if err != nil {
-			return nil, orberrors.NewTransient(fmt.Errorf("failed to get iterator value for anchor [%s]: %w",
-				anchorHash, err))
+			return nil, orberrors.NewTransient(fmt.Errorf("failed to get iterator value: %w", err))
 		}
 
 		linkRef := anchorLinkRef{}
 
 		err = s.unmarshal(value, &linkRef)
 		if err != nil {
-			return nil, fmt.Errorf("unmarshal link [%s] for anchor [%s]: %w", value, anchorHash, err)
+			return nil, fmt.Errorf("unmarshal link [%s]: %w", value, err)
 		}
 
 		u, err := url.Parse(linkRef.URL)
 		if err != nil {
-			return nil, fmt.Errorf("parse link [%s] for anchor [%s]: %w", linkRef.URL, anchorHash, err)
+			return nil, fmt.Errorf("parse link [%s]: %w", linkRef.URL, err)
 		}
 
 		links = append(links, u)
 
 		ok, err = iter.Next()
 		if err != nil {
-			return nil, orberrors.NewTransient(fmt.Errorf("iterator error for anchor [%s]: %w", anchorHash, err))
+			return nil, orberrors.NewTransient(fmt.Errorf("iterator error: %w", err))
 		}
 	}
 
-	logger.Debug("Returning anchor references for anchor hash", log.WithAnchorHash(anchorHash), log.WithURIs(links...))
-
 	return links, nil
 }
 
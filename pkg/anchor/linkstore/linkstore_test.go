@@ -194,6 +194,50 @@ func TestStore_GetLinks(t *testing.T) {
 	})
 }
 
+func TestStore_GetAllLinks(t *testing.T) {
+	const (
+		hash1 = "uEiALYp_C4wk2WegpfnCSoSTBdKZ1MVdDadn4rdmZl5GKzQ"
+		hash2 = "uEiBUQDRI5ttIzXbe1LZKUaZWb6yFsnMnrgDksAtQ-wCaKw"
+	)
+
+	provider := storage.NewMockStoreProvider()
+
+	s, err := New(provider)
+	require.NoError(t, err)
+	require.NotNil(t, s)
+
+	t.Run("Success", func(t *testing.T) {
+		links, err := s.GetAllLinks()
+		require.NoError(t, err)
+		require.Empty(t, links)
+
+		require.NoError(t, s.PutLinks(
+			[]*url.URL{
+				testutil.MustParseURL(fmt.Sprintf("hl:%s:uoQ-BeEtodUZzbk1ucmdEa3NBdFEtd0NhS3c", hash1)),
+				testutil.MustParseURL(fmt.Sprintf("hl:%s:uoQ-BeEtodWJRbWI2SzZ4OVhtYkNTZjRfTWc", hash1)),
+				testutil.MustParseURL(fmt.Sprintf("hl:%s:uoQ-BeEtodUZzbk1ucmdEa3NBdFEtd0NhS3c", hash2)),
+			},
+		))
+
+		links, err = s.GetAllLinks()
+		require.NoError(t, err)
+		require.Len(t, links, 3)
+	})
+
+	t.Run("Query error", func(t *testing.T) {
+		errExpected := errors.New("injected query error")
+
+		provider.Store.ErrQuery = errExpected
+		defer func() { provider.Store.ErrQuery = nil }()
+
+		links, err := s.GetAllLinks()
+		require.Error(t, err)
+		require.Len(t, links, 0)
+		require.Contains(t, err.Error(), errExpected.Error())
+		require.True(t, orberrors.IsTransient(err))
+	})
+}
+
 func TestStore_DeleteLinks(t *testing.T) {
 	provider := storage.NewMockStoreProvider()
 
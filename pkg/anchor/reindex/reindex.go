@@ -0,0 +1,616 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package reindex
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/piprate/json-gold/ld"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	txnapi "github.com/trustbloc/sidetree-core-go/pkg/api/txn"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/anchor/subject"
+	"github.com/trustbloc/orb/pkg/anchor/util"
+	"github.com/trustbloc/orb/pkg/context/common"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	"github.com/trustbloc/orb/pkg/linkset"
+	"github.com/trustbloc/orb/pkg/versions/1_0/txnprocessor"
+)
+
+var logger = log.New("anchor-reindex")
+
+const (
+	defaultBatchSize = 100
+
+	// maxReportedDivergences is the maximum number of divergences included in the report. The divergence
+	// counts in the report are always accurate.
+	maxReportedDivergences = 1000
+)
+
+// ErrInProgress indicates that a reindex is already in progress.
+var ErrInProgress = errors.New("reindex is already in progress")
+
+// DivergenceType is the type of divergence between the anchor graph and the existing data.
+type DivergenceType string
+
+const (
+	// MissingOperation indicates that an operation in an anchor is missing from the operation store.
+	MissingOperation DivergenceType = "missing-operation"
+	// MissingDIDAnchor indicates that the DID anchor store doesn't contain the latest anchor for a DID.
+	MissingDIDAnchor DivergenceType = "missing-did-anchor"
+	// DIDAnchorMismatch indicates that the DID anchor store contains a different anchor for a DID than
+	// the latest anchor in the anchor graph.
+	DIDAnchorMismatch DivergenceType = "did-anchor-mismatch"
+)
+
+// Divergence describes a difference between the anchor graph and the existing data.
+type Divergence struct {
+	Type     DivergenceType `json:"type"`
+	Suffix   string         `json:"suffix"`
+	Anchor   string         `json:"anchor"`
+	Existing string         `json:"existing,omitempty"`
+}
+
+// Report contains the results of a reindex.
+type Report struct {
+	DryRun               bool          `json:"dryRun"`
+	Anchors              int           `json:"anchors"`
+	DIDs                 int           `json:"dids"`
+	MissingOperations    int           `json:"missingOperations"`
+	DIDAnchorDivergences int           `json:"didAnchorDivergences"`
+	Divergences          []*Divergence `json:"divergences,omitempty"`
+}
+
+func (r *Report) addDivergence(d *Divergence) {
+	if d.Type == MissingOperation {
+		r.MissingOperations++
+	} else {
+		r.DIDAnchorDivergences++
+	}
+
+	if len(r.Divergences) < maxReportedDivergences {
+		r.Divergences = append(r.Divergences, d)
+	}
+}
+
+// State is the state of a reindex.
+type State string
+
+const (
+	// StateRunning indicates that the reindex is in progress.
+	StateRunning State = "running"
+	// StateCompleted indicates that the reindex completed successfully.
+	StateCompleted State = "completed"
+	// StateFailed indicates that the reindex failed.
+	StateFailed State = "failed"
+)
+
+// Status contains the status of the current (or last) reindex.
+type Status struct {
+	State       State      `json:"state"`
+	StartedAt   *time.Time `json:"startedAt,omitempty"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+	Report      *Report    `json:"report,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
+
+type anchorGraph interface {
+	Read(hl string) (*linkset.Linkset, error)
+}
+
+type anchorLinkStore interface {
+	GetAllLinks() ([]*url.URL, error)
+}
+
+type anchorLinksetBuilder interface {
+	GetPayloadFromAnchorLink(anchorLink *linkset.Link) (*subject.Payload, error)
+}
+
+type didAnchors interface {
+	PutBulk(dids []string, areNew []bool, cid string) error
+	GetBulk(dids []string) ([]string, error)
+}
+
+// Providers contains the providers required by the Reindexer.
+type Providers struct {
+	AnchorGraph            anchorGraph
+	AnchorLinkStore        anchorLinkStore
+	AnchorLinksetBuilder   anchorLinksetBuilder
+	ProtocolClientProvider protocol.ClientProvider
+	OpStore                common.OperationStore
+	DidAnchors             didAnchors
+	DocLoader              ld.DocumentLoader
+	Pkf                    verifiable.PublicKeyFetcher
+}
+
+// Reindexer rebuilds the operation store and DID anchor store from the local anchor graph. Starting at the
+// latest anchors, the anchor graph is walked back to the create operations and each anchor is replayed
+// through the transaction processor. Any operation or DID anchor that differs from the existing data
+// is reported and, unless a dry run is requested, repaired.
+type Reindexer struct {
+	*Providers
+
+	discoveryDomain string
+	batchSize       int
+
+	mutex  sync.RWMutex
+	status *Status
+}
+
+// Option is a Reindexer option.
+type Option func(r *Reindexer)
+
+// WithDiscoveryDomain sets the discovery domain which is added to the equivalent references of
+// each operation (in the same way as the observer).
+func WithDiscoveryDomain(domain string) Option {
+	return func(r *Reindexer) {
+		r.discoveryDomain = domain
+	}
+}
+
+// WithBatchSize sets the number of DIDs that are checked against the DID anchor store at a time.
+func WithBatchSize(batchSize int) Option {
+	return func(r *Reindexer) {
+		r.batchSize = batchSize
+	}
+}
+
+// New returns a new Reindexer.
+func New(providers *Providers, opts ...Option) *Reindexer {
+	r := &Reindexer{
+		Providers: providers,
+		batchSize: defaultBatchSize,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Start starts a reindex in the background and returns the initial status. ErrInProgress is returned
+// if a reindex is already running. The progress may be retrieved using Status.
+func (r *Reindexer) Start(anchors []string, dryRun bool) (*Status, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.status != nil && r.status.State == StateRunning {
+		return nil, ErrInProgress
+	}
+
+	startedAt := time.Now()
+
+	r.status = &Status{
+		State:     StateRunning,
+		StartedAt: &startedAt,
+	}
+
+	status := *r.status
+
+	go r.runAsync(anchors, dryRun)
+
+	return &status, nil
+}
+
+// Status returns the status of the current (or last) reindex or nil if a reindex was never started.
+func (r *Reindexer) Status() *Status {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.status == nil {
+		return nil
+	}
+
+	status := *r.status
+
+	return &status
+}
+
+func (r *Reindexer) runAsync(anchors []string, dryRun bool) {
+	report, err := r.Run(anchors, dryRun)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	completedAt := time.Now()
+
+	r.status.CompletedAt = &completedAt
+
+	if err != nil {
+		logger.Error("Reindex failed", log.WithError(err))
+
+		r.status.State = StateFailed
+		r.status.Error = err.Error()
+
+		return
+	}
+
+	r.status.State = StateCompleted
+	r.status.Report = report
+}
+
+// Run walks the anchor graph starting at the given anchor hashlinks (or at all anchors in the anchor link
+// store if none are provided) and replays each anchor, in order, through the transaction processor.
+// Operations that are missing from the operation store are added and the DID anchor store is updated with
+// the latest anchor for each DID. If dryRun is true then the divergences are reported but not repaired.
+func (r *Reindexer) Run(anchors []string, dryRun bool) (*Report, error) {
+	heads, err := r.getHeads(anchors)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Starting reindex", log.WithTotal(len(heads)), log.WithDryRun(dryRun))
+
+	nodes, err := r.collect(heads)
+	if err != nil {
+		return nil, fmt.Errorf("collect anchors: %w", err)
+	}
+
+	sorted, err := sortAnchors(nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{DryRun: dryRun}
+
+	opStore := &recordingOpStore{OperationStore: r.OpStore, dryRun: dryRun}
+
+	latestAnchors := make(map[string]string)
+
+	var suffixes []string
+
+	for _, node := range sorted {
+		if err := r.replay(node, opStore); err != nil {
+			return nil, fmt.Errorf("replay anchor [%s]: %w", node.hl, err)
+		}
+
+		report.Anchors++
+
+		for _, op := range opStore.drain() {
+			report.addDivergence(&Divergence{
+				Type:   MissingOperation,
+				Suffix: op.UniqueSuffix,
+				Anchor: node.hl,
+			})
+		}
+
+		for _, prev := range node.payload.PreviousAnchors {
+			if _, ok := latestAnchors[prev.Suffix]; !ok {
+				suffixes = append(suffixes, prev.Suffix)
+			}
+
+			// The anchors are sorted so that the last anchor for a DID is the latest.
+			latestAnchors[prev.Suffix] = node.hl
+		}
+	}
+
+	report.DIDs = len(suffixes)
+
+	if err := r.reconcileDIDAnchors(suffixes, latestAnchors, report); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Reindex completed", log.WithTotal(report.Anchors), log.WithDryRun(dryRun),
+		log.WithMetadata(report))
+
+	return report, nil
+}
+
+func (r *Reindexer) getHeads(anchors []string) ([]string, error) {
+	if len(anchors) > 0 {
+		return anchors, nil
+	}
+
+	links, err := r.AnchorLinkStore.GetAllLinks()
+	if err != nil {
+		return nil, fmt.Errorf("get anchor links: %w", err)
+	}
+
+	heads := make([]string, len(links))
+
+	for i, link := range links {
+		heads[i] = link.String()
+	}
+
+	return heads, nil
+}
+
+type anchorNode struct {
+	hl       string
+	hash     string
+	link     *linkset.Link
+	payload  *subject.Payload
+	previous []string
+}
+
+// collect returns all of the anchors that are reachable from the given anchors, in the order in
+// which they were discovered.
+func (r *Reindexer) collect(heads []string) ([]*anchorNode, error) {
+	var nodes []*anchorNode
+
+	visited := make(map[string]bool)
+
+	queue := append([]string{}, heads...)
+
+	for len(queue) > 0 {
+		hl := queue[0]
+		queue = queue[1:]
+
+		hash, err := hashlink.GetResourceHashFromHashLink(hl)
+		if err != nil {
+			return nil, fmt.Errorf("get hash from hashlink [%s]: %w", hl, err)
+		}
+
+		if visited[hash] {
+			continue
+		}
+
+		visited[hash] = true
+
+		node, err := r.readAnchor(hl, hash)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes = append(nodes, node)
+
+		for _, prev := range node.payload.PreviousAnchors {
+			if prev.Anchor != "" {
+				queue = append(queue, prev.Anchor)
+			}
+		}
+	}
+
+	return nodes, nil
+}
+
+func (r *Reindexer) readAnchor(hl, hash string) (*anchorNode, error) {
+	anchorLinkset, err := r.AnchorGraph.Read(hl)
+	if err != nil {
+		return nil, fmt.Errorf("read anchor [%s]: %w", hl, err)
+	}
+
+	anchorLink := anchorLinkset.Link()
+	if anchorLink == nil {
+		return nil, fmt.Errorf("empty anchor linkset [%s]", hl)
+	}
+
+	payload, err := r.AnchorLinksetBuilder.GetPayloadFromAnchorLink(anchorLink)
+	if err != nil {
+		return nil, fmt.Errorf("get payload from anchor link [%s]: %w", hl, err)
+	}
+
+	node := &anchorNode{
+		hl:      hl,
+		hash:    hash,
+		link:    anchorLink,
+		payload: payload,
+	}
+
+	seen := make(map[string]bool)
+
+	for _, prev := range payload.PreviousAnchors {
+		if prev.Anchor == "" {
+			continue
+		}
+
+		prevHash, err := hashlink.GetResourceHashFromHashLink(prev.Anchor)
+		if err != nil {
+			return nil, fmt.Errorf("get hash from previous anchor [%s]: %w", prev.Anchor, err)
+		}
+
+		if !seen[prevHash] {
+			seen[prevHash] = true
+
+			node.previous = append(node.previous, prevHash)
+		}
+	}
+
+	return node, nil
+}
+
+// sortAnchors sorts the anchors so that each anchor comes after all of its previous anchors.
+func sortAnchors(nodes []*anchorNode) ([]*anchorNode, error) {
+	pending := make(map[string]int, len(nodes))
+	dependents := make(map[string][]*anchorNode)
+
+	var ready []*anchorNode
+
+	// Process the nodes in reverse order of discovery so that the oldest anchors come first.
+	for i := len(nodes) - 1; i >= 0; i-- {
+		node := nodes[i]
+
+		pending[node.hash] = len(node.previous)
+
+		for _, prev := range node.previous {
+			dependents[prev] = append(dependents[prev], node)
+		}
+
+		if len(node.previous) == 0 {
+			ready = append(ready, node)
+		}
+	}
+
+	sorted := make([]*anchorNode, 0, len(nodes))
+
+	for len(ready) > 0 {
+		node := ready[0]
+		ready = ready[1:]
+
+		sorted = append(sorted, node)
+
+		for _, dependent := range dependents[node.hash] {
+			pending[dependent.hash]--
+
+			if pending[dependent.hash] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	if len(sorted) != len(nodes) {
+		return nil, fmt.Errorf("anchor graph contains a cycle")
+	}
+
+	return sorted, nil
+}
+
+func (r *Reindexer) replay(node *anchorNode, opStore common.OperationStore) error {
+	pc, err := r.ProtocolClientProvider.ForNamespace(node.payload.Namespace)
+	if err != nil {
+		return fmt.Errorf("get protocol client for namespace [%s]: %w", node.payload.Namespace, err)
+	}
+
+	v, err := pc.Get(node.payload.Version)
+	if err != nil {
+		return fmt.Errorf("get protocol version [%d]: %w", node.payload.Version, err)
+	}
+
+	vc, err := util.VerifiableCredentialFromAnchorLink(node.link,
+		verifiable.WithPublicKeyFetcher(r.Pkf),
+		verifiable.WithJSONLDDocumentLoader(r.DocLoader),
+		verifiable.WithStrictValidation(),
+	)
+	if err != nil {
+		return fmt.Errorf("get verifiable credential from anchor link: %w", err)
+	}
+
+	equivalentRefs := []string{node.hl}
+	if r.discoveryDomain != "" {
+		equivalentRefs = append(equivalentRefs, "https:"+r.discoveryDomain+":"+node.hash)
+	}
+
+	ad := &util.AnchorData{OperationCount: node.payload.OperationCount, CoreIndexFileURI: node.payload.CoreIndex}
+
+	sidetreeTxn := txnapi.SidetreeTxn{
+		TransactionTime:      uint64(vc.Issued.Unix()),
+		AnchorString:         ad.GetAnchorString(),
+		Namespace:            node.payload.Namespace,
+		ProtocolVersion:      node.payload.Version,
+		CanonicalReference:   node.hash,
+		EquivalentReferences: equivalentRefs,
+	}
+
+	logger.Debug("Replaying anchor", log.WithAnchorURIString(node.hl), log.WithCoreIndex(node.payload.CoreIndex))
+
+	tp := txnprocessor.New(&txnprocessor.Providers{
+		OpStore:                   opStore,
+		OperationProtocolProvider: v.OperationProvider(),
+	})
+
+	if _, err := tp.Process(sidetreeTxn); err != nil {
+		return fmt.Errorf("process anchor core index [%s]: %w", node.payload.CoreIndex, err)
+	}
+
+	return nil
+}
+
+func (r *Reindexer) reconcileDIDAnchors(suffixes []string, latestAnchors map[string]string, report *Report) error {
+	for start := 0; start < len(suffixes); start += r.batchSize {
+		end := start + r.batchSize
+		if end > len(suffixes) {
+			end = len(suffixes)
+		}
+
+		batch := suffixes[start:end]
+
+		existing, err := r.DidAnchors.GetBulk(batch)
+		if err != nil {
+			return fmt.Errorf("get DID anchors: %w", err)
+		}
+
+		var anchors []string
+
+		repairs := make(map[string][]string)
+
+		for i, suffix := range batch {
+			latest := latestAnchors[suffix]
+
+			switch {
+			case existing[i] == "":
+				report.addDivergence(&Divergence{Type: MissingDIDAnchor, Suffix: suffix, Anchor: latest})
+			case !sameAnchor(existing[i], latest):
+				report.addDivergence(&Divergence{
+					Type: DIDAnchorMismatch, Suffix: suffix, Anchor: latest, Existing: existing[i],
+				})
+			default:
+				continue
+			}
+
+			if _, ok := repairs[latest]; !ok {
+				anchors = append(anchors, latest)
+			}
+
+			repairs[latest] = append(repairs[latest], suffix)
+		}
+
+		if report.DryRun {
+			continue
+		}
+
+		for _, anchor := range anchors {
+			if err := r.DidAnchors.PutBulk(repairs[anchor], make([]bool, len(repairs[anchor])), anchor); err != nil {
+				return fmt.Errorf("update DID anchors for anchor [%s]: %w", anchor, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// sameAnchor returns true if the given hashlinks refer to the same anchor. The hashlinks may differ
+// in their metadata (e.g. alternate links).
+func sameAnchor(hl1, hl2 string) bool {
+	hash1, err := hashlink.GetResourceHashFromHashLink(hl1)
+	if err != nil {
+		return hl1 == hl2
+	}
+
+	hash2, err := hashlink.GetResourceHashFromHashLink(hl2)
+	if err != nil {
+		return hl1 == hl2
+	}
+
+	return hash1 == hash2
+}
+
+// recordingOpStore records the operations that are added to the operation store. If dryRun is
+// true then the operations are recorded but not stored.
+type recordingOpStore struct {
+	common.OperationStore
+
+	dryRun bool
+	added  []*operation.AnchoredOperation
+}
+
+func (s *recordingOpStore) Put(ops []*operation.AnchoredOperation) error {
+	if !s.dryRun {
+		if err := s.OperationStore.Put(ops); err != nil {
+			return err
+		}
+	}
+
+	s.added = append(s.added, ops...)
+
+	return nil
+}
+
+func (s *recordingOpStore) drain() []*operation.AnchoredOperation {
+	added := s.added
+
+	s.added = nil
+
+	return added
+}
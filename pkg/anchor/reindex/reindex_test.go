@@ -0,0 +1,464 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package reindex
+
+import (
+	"errors"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
+	afgoutil "github.com/hyperledger/aries-framework-go/pkg/doc/util"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/txn"
+	"github.com/trustbloc/sidetree-core-go/pkg/canonicalizer"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+
+	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	"github.com/trustbloc/orb/pkg/anchor/anchorlinkset"
+	"github.com/trustbloc/orb/pkg/anchor/anchorlinkset/generator"
+	"github.com/trustbloc/orb/pkg/anchor/builder"
+	"github.com/trustbloc/orb/pkg/anchor/subject"
+	"github.com/trustbloc/orb/pkg/anchor/util"
+	"github.com/trustbloc/orb/pkg/datauri"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	"github.com/trustbloc/orb/pkg/internal/testutil"
+	"github.com/trustbloc/orb/pkg/linkset"
+	orbmocks "github.com/trustbloc/orb/pkg/mocks"
+	"github.com/trustbloc/orb/pkg/store/didanchor"
+	opstore "github.com/trustbloc/orb/pkg/store/operation"
+)
+
+const (
+	namespace = "did:orb"
+
+	did1 = "EiDahaOGH-liLLdDtTxEAdc8i-cfCz-WUcQdRJheMVNn3A"
+	did2 = "EiDcMHkCfbKm7vDxNHsGgy7hM1qyHv4OYhpkpUTZQ4DYdQ"
+
+	coreIndex1 = "hl:uEiBGozN2uP1HBNNZtL-oeg2ifE0NuKY8Bg3miVMJtVZvYQ"
+	coreIndex2 = "hl:uEiC3Q4SF3bP-qb0i9MIz_k_n-rKi-BhSgcOk8qoKVcJqrg"
+	coreIndex3 = "hl:uEiCWKM6q1fGqlpW4HjpXYP5KbM8bLRQv_wZkDwyV_rp_JQ"
+)
+
+func TestReindexer_Run(t *testing.T) {
+	graph := newMockAnchorGraph()
+
+	// Anchor 1 creates DID 1 and DID 2, anchor 2 updates DID 1 and anchor 3 updates DID 1 and DID 2.
+	hl1 := graph.add(t, &subject.Payload{
+		Namespace: namespace, CoreIndex: coreIndex1, OperationCount: 2,
+		PreviousAnchors: []*subject.SuffixAnchor{{Suffix: did1}, {Suffix: did2}},
+	})
+
+	hl2 := graph.add(t, &subject.Payload{
+		Namespace: namespace, CoreIndex: coreIndex2, OperationCount: 1,
+		PreviousAnchors: []*subject.SuffixAnchor{{Suffix: did1, Anchor: hl1}},
+	})
+
+	hl3 := graph.add(t, &subject.Payload{
+		Namespace: namespace, CoreIndex: coreIndex3, OperationCount: 2,
+		PreviousAnchors: []*subject.SuffixAnchor{{Suffix: did1, Anchor: hl2}, {Suffix: did2, Anchor: hl1}},
+	})
+
+	opProvider := newMockOperationProvider(map[string][]string{
+		coreIndex1: {did1, did2},
+		coreIndex2: {did1},
+		coreIndex3: {did1, did2},
+	})
+
+	t.Run("Dry run", func(t *testing.T) {
+		r, opStore, didAnchors := newReindexer(t, graph, &mockAnchorLinkStore{links: []string{hl3, hl1}}, opProvider)
+
+		report, err := r.Run(nil, true)
+		require.NoError(t, err)
+		require.True(t, report.DryRun)
+		require.Equal(t, 3, report.Anchors)
+		require.Equal(t, 2, report.DIDs)
+		require.Equal(t, 5, report.MissingOperations)
+		require.Equal(t, 2, report.DIDAnchorDivergences)
+		require.Len(t, report.Divergences, 7)
+
+		// The anchors are replayed from the oldest to the latest.
+		require.Equal(t, hl1, report.Divergences[0].Anchor)
+		require.Equal(t, hl1, report.Divergences[1].Anchor)
+		require.Equal(t, hl2, report.Divergences[2].Anchor)
+		require.Equal(t, hl3, report.Divergences[3].Anchor)
+
+		require.Equal(t, &Divergence{Type: MissingDIDAnchor, Suffix: did1, Anchor: hl3}, report.Divergences[5])
+
+		// Nothing should have been written.
+		_, err = opStore.Get(did1)
+		require.Error(t, err)
+
+		anchors, err := didAnchors.GetBulk([]string{did1, did2})
+		require.NoError(t, err)
+		require.Equal(t, []string{"", ""}, anchors)
+	})
+
+	t.Run("Repair", func(t *testing.T) {
+		r, opStore, didAnchors := newReindexer(t, graph, &mockAnchorLinkStore{links: []string{hl3}}, opProvider,
+			WithBatchSize(1), WithDiscoveryDomain("webcas:shared.domain.com"))
+
+		report, err := r.Run(nil, false)
+		require.NoError(t, err)
+		require.False(t, report.DryRun)
+		require.Equal(t, 3, report.Anchors)
+		require.Equal(t, 5, report.MissingOperations)
+		require.Equal(t, 2, report.DIDAnchorDivergences)
+
+		ops, err := opStore.Get(did1)
+		require.NoError(t, err)
+		require.Len(t, ops, 3)
+		require.Len(t, ops[0].EquivalentReferences, 2)
+
+		ops, err = opStore.Get(did2)
+		require.NoError(t, err)
+		require.Len(t, ops, 2)
+
+		anchors, err := didAnchors.GetBulk([]string{did1, did2})
+		require.NoError(t, err)
+		require.Equal(t, []string{hl3, hl3}, anchors)
+
+		// Nothing should diverge after a repair.
+		report, err = r.Run(nil, false)
+		require.NoError(t, err)
+		require.Equal(t, 3, report.Anchors)
+		require.Zero(t, report.MissingOperations)
+		require.Zero(t, report.DIDAnchorDivergences)
+		require.Empty(t, report.Divergences)
+
+		// Corrupt the DID anchor store.
+		require.NoError(t, didAnchors.PutBulk([]string{did1}, []bool{false}, hl2))
+
+		report, err = r.Run(nil, false)
+		require.NoError(t, err)
+		require.Zero(t, report.MissingOperations)
+		require.Equal(t, 1, report.DIDAnchorDivergences)
+		require.Equal(t, &Divergence{Type: DIDAnchorMismatch, Suffix: did1, Anchor: hl3, Existing: hl2},
+			report.Divergences[0])
+
+		anchors, err = didAnchors.GetBulk([]string{did1})
+		require.NoError(t, err)
+		require.Equal(t, []string{hl3}, anchors)
+	})
+
+	t.Run("Explicit anchors", func(t *testing.T) {
+		r, _, _ := newReindexer(t, graph, &mockAnchorLinkStore{}, opProvider)
+
+		report, err := r.Run([]string{hl2}, true)
+		require.NoError(t, err)
+		require.Equal(t, 2, report.Anchors)
+		require.Equal(t, 2, report.DIDs)
+		require.Equal(t, 3, report.MissingOperations)
+	})
+
+	t.Run("No anchors", func(t *testing.T) {
+		r, _, _ := newReindexer(t, graph, &mockAnchorLinkStore{}, opProvider)
+
+		report, err := r.Run(nil, false)
+		require.NoError(t, err)
+		require.Zero(t, report.Anchors)
+	})
+
+	t.Run("Get anchor links error", func(t *testing.T) {
+		r, _, _ := newReindexer(t, graph, &mockAnchorLinkStore{err: errors.New("injected query error")}, opProvider)
+
+		_, err := r.Run(nil, false)
+		require.EqualError(t, err, "get anchor links: injected query error")
+	})
+
+	t.Run("Invalid hashlink", func(t *testing.T) {
+		r, _, _ := newReindexer(t, graph, &mockAnchorLinkStore{}, opProvider)
+
+		_, err := r.Run([]string{"xxx"}, false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get hash from hashlink [xxx]")
+	})
+
+	t.Run("Anchor not found", func(t *testing.T) {
+		r, _, _ := newReindexer(t, newMockAnchorGraph(), &mockAnchorLinkStore{}, opProvider)
+
+		_, err := r.Run([]string{hl3}, false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "collect anchors: read anchor")
+	})
+
+	t.Run("Unsupported namespace", func(t *testing.T) {
+		r, _, _ := newReindexer(t, graph, &mockAnchorLinkStore{}, opProvider)
+
+		r.ProtocolClientProvider = mocks.NewMockProtocolClientProvider().WithProtocolClient("did:xxx",
+			mocks.NewMockProtocolClient())
+
+		_, err := r.Run([]string{hl3}, false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get protocol client for namespace [did:orb]")
+	})
+
+	t.Run("Get operations error", func(t *testing.T) {
+		errExpected := errors.New("injected operation provider error")
+
+		op := &mocks.OperationProvider{}
+		op.GetTxnOperationsReturns(nil, errExpected)
+
+		r, _, _ := newReindexer(t, graph, &mockAnchorLinkStore{}, op)
+
+		_, err := r.Run([]string{hl3}, false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+
+	t.Run("DID anchor store error", func(t *testing.T) {
+		r, _, _ := newReindexer(t, graph, &mockAnchorLinkStore{}, opProvider)
+
+		errExpected := errors.New("injected DID anchor error")
+
+		r.DidAnchors = &mockDIDAnchors{err: errExpected}
+
+		_, err := r.Run([]string{hl3}, true)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+}
+
+func TestReindexer_Start(t *testing.T) {
+	graph := newMockAnchorGraph()
+
+	hl := graph.add(t, &subject.Payload{
+		Namespace: namespace, CoreIndex: coreIndex1, OperationCount: 2,
+		PreviousAnchors: []*subject.SuffixAnchor{{Suffix: did1}, {Suffix: did2}},
+	})
+
+	opProvider := newMockOperationProvider(map[string][]string{coreIndex1: {did1, did2}})
+
+	t.Run("Success", func(t *testing.T) {
+		r, _, _ := newReindexer(t, graph, &mockAnchorLinkStore{links: []string{hl}}, opProvider)
+
+		require.Nil(t, r.Status())
+
+		graph.block()
+
+		status, err := r.Start(nil, false)
+		require.NoError(t, err)
+		require.Equal(t, StateRunning, status.State)
+		require.NotNil(t, status.StartedAt)
+
+		_, err = r.Start(nil, false)
+		require.ErrorIs(t, err, ErrInProgress)
+
+		graph.unblock()
+
+		require.Eventually(t, func() bool {
+			return r.Status().State == StateCompleted
+		}, time.Second, 10*time.Millisecond)
+
+		status = r.Status()
+		require.NotNil(t, status.CompletedAt)
+		require.NotNil(t, status.Report)
+		require.Equal(t, 1, status.Report.Anchors)
+		require.Equal(t, 2, status.Report.MissingOperations)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		r, _, _ := newReindexer(t, graph, &mockAnchorLinkStore{err: errors.New("injected query error")}, opProvider)
+
+		_, err := r.Start(nil, false)
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			return r.Status().State == StateFailed
+		}, time.Second, 10*time.Millisecond)
+
+		require.Equal(t, "get anchor links: injected query error", r.Status().Error)
+	})
+}
+
+func TestSortAnchors(t *testing.T) {
+	nodes := []*anchorNode{
+		{hash: "3", previous: []string{"2", "1"}},
+		{hash: "2", previous: []string{"1"}},
+		{hash: "1"},
+	}
+
+	sorted, err := sortAnchors(nodes)
+	require.NoError(t, err)
+	require.Len(t, sorted, 3)
+	require.Equal(t, "1", sorted[0].hash)
+	require.Equal(t, "2", sorted[1].hash)
+	require.Equal(t, "3", sorted[2].hash)
+
+	_, err = sortAnchors([]*anchorNode{
+		{hash: "1", previous: []string{"2"}},
+		{hash: "2", previous: []string{"1"}},
+	})
+	require.EqualError(t, err, "anchor graph contains a cycle")
+}
+
+func newReindexer(t *testing.T, graph anchorGraph, linkStore anchorLinkStore, opProvider *mocks.OperationProvider,
+	opts ...Option) (*Reindexer, *opstore.Store, *didanchor.Store) {
+	t.Helper()
+
+	storeProvider := mem.NewProvider()
+
+	opStore, err := opstore.New(storeProvider, &orbmocks.MetricsProvider{})
+	require.NoError(t, err)
+
+	didAnchors, err := didanchor.New(storeProvider)
+	require.NoError(t, err)
+
+	pc := mocks.NewMockProtocolClient()
+	pc.Versions[0].OperationProviderReturns(opProvider)
+
+	return New(&Providers{
+		AnchorGraph:            graph,
+		AnchorLinkStore:        linkStore,
+		AnchorLinksetBuilder:   anchorlinkset.NewBuilder(generator.NewRegistry()),
+		ProtocolClientProvider: mocks.NewMockProtocolClientProvider().WithProtocolClient(namespace, pc),
+		OpStore:                opStore,
+		DidAnchors:             didAnchors,
+		DocLoader:              testutil.GetLoader(t),
+		Pkf: func(issuerID, keyID string) (*verifier.PublicKey, error) {
+			return nil, nil //nolint:nilnil
+		},
+	}, opts...), opStore, didAnchors
+}
+
+// newMockOperationProvider returns an operation provider which returns an operation for each of
+// the given suffixes, keyed by core index.
+func newMockOperationProvider(suffixesByCoreIndex map[string][]string) *mocks.OperationProvider {
+	op := &mocks.OperationProvider{}
+
+	op.GetTxnOperationsStub = func(sidetreeTxn *txn.SidetreeTxn) ([]*operation.AnchoredOperation, error) {
+		ad, err := util.ParseAnchorString(sidetreeTxn.AnchorString)
+		if err != nil {
+			return nil, err
+		}
+
+		var ops []*operation.AnchoredOperation
+
+		for _, suffix := range suffixesByCoreIndex[ad.CoreIndexFileURI] {
+			ops = append(ops, &operation.AnchoredOperation{
+				Type:         operation.TypeUpdate,
+				UniqueSuffix: suffix,
+			})
+		}
+
+		return ops, nil
+	}
+
+	return op
+}
+
+type mockAnchorGraph struct {
+	anchors map[string]*linkset.Linkset
+	wg      sync.WaitGroup
+}
+
+func newMockAnchorGraph() *mockAnchorGraph {
+	return &mockAnchorGraph{anchors: make(map[string]*linkset.Linkset)}
+}
+
+func (m *mockAnchorGraph) add(t *testing.T, payload *subject.Payload) string {
+	t.Helper()
+
+	vc := &verifiable.Credential{
+		Types:   []string{"VerifiableCredential", "AnchorCredential"},
+		Context: []string{vocab.ContextCredentials, vocab.ContextActivityAnchors},
+		Subject: &builder.CredentialSubject{
+			HRef:    payload.CoreIndex,
+			Type:    []string{"AnchorLink"},
+			Profile: "https://w3id.org/orb#v0",
+			Anchor:  "hl:uEiD7xzrz5lEKIq0ZZWh9ky0mNW6wxpGx_H2bxhg80c1IDA",
+			Rel:     "linkset",
+		},
+		Issuer: verifiable.Issuer{
+			ID: "https://orb.domain1.com",
+		},
+		Issued: &afgoutil.TimeWrapper{Time: time.Now()},
+	}
+
+	al, _, err := anchorlinkset.NewBuilder(
+		generator.NewRegistry()).BuildAnchorLink(payload, datauri.MediaTypeDataURIGzipBase64,
+		func(anchorHashlink, coreIndexHashlink string) (*verifiable.Credential, error) {
+			return vc, nil
+		},
+	)
+	require.NoError(t, err)
+
+	ls := linkset.New(al)
+
+	lsBytes, err := canonicalizer.MarshalCanonical(ls)
+	require.NoError(t, err)
+
+	hash, err := hashlink.New().CreateResourceHash(lsBytes)
+	require.NoError(t, err)
+
+	hl, err := hashlink.New().CreateHashLink(lsBytes, []string{"https://orb.domain1.com/cas/" + hash})
+	require.NoError(t, err)
+
+	m.anchors[hash] = ls
+
+	return hl
+}
+
+func (m *mockAnchorGraph) block() {
+	m.wg.Add(1)
+}
+
+func (m *mockAnchorGraph) unblock() {
+	m.wg.Done()
+}
+
+func (m *mockAnchorGraph) Read(hl string) (*linkset.Linkset, error) {
+	m.wg.Wait()
+
+	hash, err := hashlink.GetResourceHashFromHashLink(hl)
+	if err != nil {
+		return nil, err
+	}
+
+	ls, ok := m.anchors[hash]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+
+	return ls, nil
+}
+
+type mockAnchorLinkStore struct {
+	links []string
+	err   error
+}
+
+func (m *mockAnchorLinkStore) GetAllLinks() ([]*url.URL, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	links := make([]*url.URL, len(m.links))
+
+	for i, link := range m.links {
+		links[i] = testutil.MustParseURL(link)
+	}
+
+	return links, nil
+}
+
+type mockDIDAnchors struct {
+	err error
+}
+
+func (m *mockDIDAnchors) PutBulk([]string, []bool, string) error {
+	return m.err
+}
+
+func (m *mockDIDAnchors) GetBulk([]string) ([]string, error) {
+	return nil, m.err
+}
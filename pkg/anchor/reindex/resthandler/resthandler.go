@@ -0,0 +1,204 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/anchor/reindex"
+)
+
+const reindexEndpoint = "/reindex"
+
+const (
+	badRequestResponse          = "Bad Request."
+	notFoundResponse            = "Not Found."
+	conflictResponse            = "Conflict."
+	internalServerErrorResponse = "Internal Server Error."
+)
+
+const loggerModule = "reindex-rest-handler"
+
+type reindexer interface {
+	Start(anchors []string, dryRun bool) (*reindex.Status, error)
+	Status() *reindex.Status
+}
+
+// StartHandler starts a reindex of the DID state from the local anchor graph. The reindex runs in the
+// background and its status may be retrieved using the StatusHandler.
+type StartHandler struct {
+	reindexer reindexer
+	logger    *log.Log
+	marshal   func(interface{}) ([]byte, error)
+	unmarshal func([]byte, interface{}) error
+}
+
+// NewStartHandler returns a new StartHandler.
+func NewStartHandler(reindexer reindexer) *StartHandler {
+	return &StartHandler{
+		reindexer: reindexer,
+		logger:    log.New(loggerModule, log.WithFields(log.WithServiceEndpoint(reindexEndpoint))),
+		marshal:   json.Marshal,
+		unmarshal: json.Unmarshal,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the start handler.
+func (h *StartHandler) Path() string {
+	return reindexEndpoint
+}
+
+// Method returns the HTTP REST method for the start handler.
+func (h *StartHandler) Method() string {
+	return http.MethodPost
+}
+
+// Handler returns the HTTP REST handle for the start handler.
+func (h *StartHandler) Handler() common.HTTPRequestHandler {
+	return h.handle
+}
+
+func (h *StartHandler) handle(w http.ResponseWriter, req *http.Request) {
+	reqBytes, err := io.ReadAll(req.Body)
+	if err != nil {
+		h.logger.Error("Error reading request body", log.WithError(err))
+
+		writeResponse(h.logger, w, http.StatusBadRequest, []byte(badRequestResponse))
+
+		return
+	}
+
+	request, err := h.getRequest(reqBytes)
+	if err != nil {
+		h.logger.Info("Invalid reindex request", log.WithError(err))
+
+		writeResponse(h.logger, w, http.StatusBadRequest, []byte(badRequestResponse))
+
+		return
+	}
+
+	status, err := h.reindexer.Start(request.Anchors, request.DryRun)
+	if err != nil {
+		if errors.Is(err, reindex.ErrInProgress) {
+			h.logger.Info("Reindex is already in progress")
+
+			writeResponse(h.logger, w, http.StatusConflict, []byte(conflictResponse))
+
+			return
+		}
+
+		h.logger.Error("Error starting reindex", log.WithError(err))
+
+		writeResponse(h.logger, w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	writeJSON(h.logger, w, h.marshal, status)
+}
+
+func (h *StartHandler) getRequest(reqBytes []byte) (*reindexRequest, error) {
+	request := &reindexRequest{}
+
+	if len(reqBytes) == 0 {
+		return request, nil
+	}
+
+	if err := h.unmarshal(reqBytes, request); err != nil {
+		return nil, fmt.Errorf("unmarshal request: %w", err)
+	}
+
+	return request, nil
+}
+
+// StatusHandler returns the status (and report) of the current or last reindex.
+type StatusHandler struct {
+	reindexer reindexer
+	logger    *log.Log
+	marshal   func(interface{}) ([]byte, error)
+}
+
+// NewStatusHandler returns a new StatusHandler.
+func NewStatusHandler(reindexer reindexer) *StatusHandler {
+	return &StatusHandler{
+		reindexer: reindexer,
+		logger:    log.New(loggerModule, log.WithFields(log.WithServiceEndpoint(reindexEndpoint))),
+		marshal:   json.Marshal,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the status handler.
+func (h *StatusHandler) Path() string {
+	return reindexEndpoint
+}
+
+// Method returns the HTTP REST method for the status handler.
+func (h *StatusHandler) Method() string {
+	return http.MethodGet
+}
+
+// Handler returns the HTTP REST handle for the status handler.
+func (h *StatusHandler) Handler() common.HTTPRequestHandler {
+	return h.handle
+}
+
+func (h *StatusHandler) handle(w http.ResponseWriter, _ *http.Request) {
+	status := h.reindexer.Status()
+	if status == nil {
+		writeResponse(h.logger, w, http.StatusNotFound, []byte(notFoundResponse))
+
+		return
+	}
+
+	writeJSON(h.logger, w, h.marshal, status)
+}
+
+type reindexRequest struct {
+	// Anchors contains the hashlinks of the anchors from which to start walking the anchor graph. If empty
+	// then all anchors in the anchor link store are used.
+	Anchors []string `json:"anchors,omitempty"`
+	// DryRun indicates that the divergences should be reported but not repaired.
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+func writeJSON(logger *log.Log, w http.ResponseWriter, marshal func(interface{}) ([]byte, error), v interface{}) {
+	respBytes, err := marshal(v)
+	if err != nil {
+		logger.Error("Marshal response error", log.WithError(err))
+
+		writeResponse(logger, w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	writeResponse(logger, w, http.StatusOK, respBytes)
+}
+
+func writeResponse(logger *log.Log, w http.ResponseWriter, status int, body []byte) {
+	if len(body) > 0 {
+		w.Header().Set("Content-Type", "text/plain")
+	}
+
+	w.WriteHeader(status)
+
+	if len(body) > 0 {
+		if _, err := w.Write(body); err != nil {
+			log.WriteResponseBodyError(logger, err)
+
+			return
+		}
+
+		log.WroteResponse(logger, body)
+	}
+}
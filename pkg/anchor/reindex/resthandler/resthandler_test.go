@@ -0,0 +1,178 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/anchor/reindex"
+)
+
+const anchorHL = "hl:uEiBGozN2uP1HBNNZtL-oeg2ifE0NuKY8Bg3miVMJtVZvYQ:uoQ-BeEtodHRwczovL29yYi5kb21haW4xLmNvbS9jYXM"
+
+func TestStartHandler(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		r := &mockReindexer{}
+
+		h := NewStartHandler(r)
+		require.Equal(t, reindexEndpoint, h.Path())
+		require.Equal(t, http.MethodPost, h.Method())
+		require.NotNil(t, h.Handler())
+
+		rw := httptest.NewRecorder()
+
+		h.Handler()(rw, httptest.NewRequest(http.MethodPost, reindexEndpoint,
+			strings.NewReader(`{"anchors":["`+anchorHL+`"],"dryRun":true}`)))
+
+		result := rw.Result()
+		require.NoError(t, result.Body.Close())
+		require.Equal(t, http.StatusOK, result.StatusCode)
+		require.Equal(t, []string{anchorHL}, r.anchors)
+		require.True(t, r.dryRun)
+
+		status := &reindex.Status{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), status))
+		require.Equal(t, reindex.StateRunning, status.State)
+	})
+
+	t.Run("No request body", func(t *testing.T) {
+		r := &mockReindexer{}
+
+		rw := httptest.NewRecorder()
+
+		NewStartHandler(r).Handler()(rw, httptest.NewRequest(http.MethodPost, reindexEndpoint, nil))
+
+		result := rw.Result()
+		require.NoError(t, result.Body.Close())
+		require.Equal(t, http.StatusOK, result.StatusCode)
+		require.Empty(t, r.anchors)
+		require.False(t, r.dryRun)
+	})
+
+	t.Run("Invalid request", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		NewStartHandler(&mockReindexer{}).Handler()(rw,
+			httptest.NewRequest(http.MethodPost, reindexEndpoint, strings.NewReader(`{`)))
+
+		result := rw.Result()
+		require.NoError(t, result.Body.Close())
+		require.Equal(t, http.StatusBadRequest, result.StatusCode)
+		require.Equal(t, badRequestResponse, rw.Body.String())
+	})
+
+	t.Run("In progress", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		NewStartHandler(&mockReindexer{err: reindex.ErrInProgress}).Handler()(rw,
+			httptest.NewRequest(http.MethodPost, reindexEndpoint, nil))
+
+		result := rw.Result()
+		require.NoError(t, result.Body.Close())
+		require.Equal(t, http.StatusConflict, result.StatusCode)
+		require.Equal(t, conflictResponse, rw.Body.String())
+	})
+
+	t.Run("Start error", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		NewStartHandler(&mockReindexer{err: errors.New("injected start error")}).Handler()(rw,
+			httptest.NewRequest(http.MethodPost, reindexEndpoint, nil))
+
+		result := rw.Result()
+		require.NoError(t, result.Body.Close())
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.Equal(t, internalServerErrorResponse, rw.Body.String())
+	})
+
+	t.Run("Marshal error", func(t *testing.T) {
+		h := NewStartHandler(&mockReindexer{})
+		h.marshal = func(interface{}) ([]byte, error) { return nil, errors.New("injected marshal error") }
+
+		rw := httptest.NewRecorder()
+
+		h.Handler()(rw, httptest.NewRequest(http.MethodPost, reindexEndpoint, nil))
+
+		result := rw.Result()
+		require.NoError(t, result.Body.Close())
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+	})
+}
+
+func TestStatusHandler(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		completedAt := time.Now()
+
+		r := &mockReindexer{
+			status: &reindex.Status{
+				State:       reindex.StateCompleted,
+				CompletedAt: &completedAt,
+				Report:      &reindex.Report{Anchors: 3, MissingOperations: 1},
+			},
+		}
+
+		h := NewStatusHandler(r)
+		require.Equal(t, reindexEndpoint, h.Path())
+		require.Equal(t, http.MethodGet, h.Method())
+		require.NotNil(t, h.Handler())
+
+		rw := httptest.NewRecorder()
+
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, reindexEndpoint, nil))
+
+		result := rw.Result()
+		require.NoError(t, result.Body.Close())
+		require.Equal(t, http.StatusOK, result.StatusCode)
+
+		status := &reindex.Status{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), status))
+		require.Equal(t, reindex.StateCompleted, status.State)
+		require.Equal(t, 3, status.Report.Anchors)
+		require.Equal(t, 1, status.Report.MissingOperations)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		NewStatusHandler(&mockReindexer{}).Handler()(rw, httptest.NewRequest(http.MethodGet, reindexEndpoint, nil))
+
+		result := rw.Result()
+		require.NoError(t, result.Body.Close())
+		require.Equal(t, http.StatusNotFound, result.StatusCode)
+		require.Equal(t, notFoundResponse, rw.Body.String())
+	})
+}
+
+type mockReindexer struct {
+	anchors []string
+	dryRun  bool
+	status  *reindex.Status
+	err     error
+}
+
+func (m *mockReindexer) Start(anchors []string, dryRun bool) (*reindex.Status, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	m.anchors = anchors
+	m.dryRun = dryRun
+
+	return &reindex.Status{State: reindex.StateRunning}, nil
+}
+
+func (m *mockReindexer) Status() *reindex.Status {
+	return m.status
+}
@@ -0,0 +1,63 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vcpubsub
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/internal/testutil"
+	"github.com/trustbloc/orb/pkg/linkset"
+	"github.com/trustbloc/orb/pkg/pubsub/envelope"
+)
+
+// TestCompatibility replays anchor linkset messages that were recorded from previous releases. A recorded message
+// must be added to testdata/compat/anchor-linkset whenever the schema version of the message changes.
+func TestCompatibility(t *testing.T) {
+	s := &Subscriber{jsonUnmarshal: json.Unmarshal}
+
+	for name, msg := range testutil.LoadRecordedMessages(t, "testdata/compat/anchor-linkset") {
+		msg := msg
+
+		t.Run(name, func(t *testing.T) {
+			ls, err := s.decode(msg)
+			require.NoError(t, err)
+			require.NotNil(t, ls.Link())
+			require.NotNil(t, ls.Link().Anchor())
+			require.NotNil(t, ls.Link().Author())
+			require.NotNil(t, ls.Link().Original())
+			require.NotNil(t, ls.Link().Related())
+			require.NotNil(t, ls.Link().Replies())
+		})
+	}
+}
+
+func TestNewerSchemaVersion(t *testing.T) {
+	s := &Subscriber{
+		jsonUnmarshal: json.Unmarshal,
+		processAnchor: func(*linkset.Linkset) error {
+			t.Fatal("message should not have been processed")
+
+			return nil
+		},
+	}
+
+	msg := linksetSchema.NewMessage([]byte(`{"linkset":[]}`))
+	msg.Metadata.Set(envelope.MetadataSchemaVersion, "1000")
+
+	s.handleAnchorMessage(msg)
+
+	// The message should be nacked so that it may be processed by a server which supports the version.
+	select {
+	case <-msg.Nacked():
+	case <-time.After(time.Second):
+		t.Fatal("expecting message to be nacked")
+	}
+}
@@ -11,18 +11,22 @@ import (
 	"encoding/json"
 	"fmt"
 
-	"github.com/ThreeDotsLabs/watermill"
 	"github.com/ThreeDotsLabs/watermill/message"
 
 	"github.com/trustbloc/orb/internal/pkg/log"
 	"github.com/trustbloc/orb/pkg/errors"
 	"github.com/trustbloc/orb/pkg/linkset"
+	"github.com/trustbloc/orb/pkg/pubsub/envelope"
 )
 
 var logger = log.New("anchor")
 
 const anchorTopic = "orb.anchor_linkset"
 
+// linksetSchema is the schema of the anchor linkset message. The version must be incremented (and an upcaster
+// added) whenever the structure of the message changes in a way that isn't backward compatible.
+var linksetSchema = envelope.New("anchor-linkset", 1)
+
 type pubSub interface {
 	Publish(topic string, messages ...*message.Message) error
 	Subscribe(ctx context.Context, topic string) (<-chan *message.Message, error)
@@ -49,7 +53,7 @@ func (h *Publisher) Publish(anchorLinkset *linkset.Linkset) error {
 		return fmt.Errorf("marshal anchor link: %w", err)
 	}
 
-	msg := message.NewMessage(watermill.NewUUID(), payload)
+	msg := linksetSchema.NewMessage(payload)
 
 	logger.Debug("Publishing anchor linkset", log.WithTopic(anchorTopic), log.WithData(payload))
 
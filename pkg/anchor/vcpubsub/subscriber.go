@@ -72,22 +72,31 @@ func (h *Subscriber) listen() {
 	logger.Debug("Listener stopped.")
 }
 
-func (h *Subscriber) handleAnchorMessage(msg *message.Message) {
-	logger.Debug("Handling message", log.WithMessageID(msg.UUID), log.WithData(msg.Payload))
+// decode upcasts the payload of the given message to the current schema version and unmarshals it.
+func (h *Subscriber) decode(msg *message.Message) (*linkset.Linkset, error) {
+	payload, err := linksetSchema.Payload(msg)
+	if err != nil {
+		return nil, err
+	}
 
 	anchorLinkset := &linkset.Linkset{}
 
-	err := h.jsonUnmarshal(msg.Payload, &anchorLinkset)
-	if err != nil {
-		logger.Error("Error parsing anchor Linkset", log.WithMessageID(msg.UUID), log.WithError(err))
+	if err := h.jsonUnmarshal(payload, anchorLinkset); err != nil {
+		return nil, fmt.Errorf("unmarshal anchor linkset: %w", err)
+	}
 
-		// Ack the message to indicate that it should not be redelivered since this is a persistent error.
-		msg.Ack()
+	return anchorLinkset, nil
+}
 
-		return
-	}
+func (h *Subscriber) handleAnchorMessage(msg *message.Message) {
+	logger.Debug("Handling message", log.WithMessageID(msg.UUID), log.WithData(msg.Payload))
 
-	err = h.processAnchor(anchorLinkset)
+	anchorLinkset, err := h.decode(msg)
+	if err == nil {
+		err = h.processAnchor(anchorLinkset)
+	} else {
+		logger.Error("Error parsing anchor Linkset", log.WithMessageID(msg.UUID), log.WithError(err))
+	}
 
 	switch {
 	case err == nil:
@@ -12,19 +12,20 @@ import (
 	"math"
 	"time"
 
-	"github.com/bluele/gcache"
 	"github.com/hyperledger/aries-framework-go/spi/storage"
 
 	"github.com/trustbloc/orb/internal/pkg/log"
 	"github.com/trustbloc/orb/pkg/anchor/witness/policy/config"
 	"github.com/trustbloc/orb/pkg/anchor/witness/policy/selector/random"
 	"github.com/trustbloc/orb/pkg/anchor/witness/proof"
+	"github.com/trustbloc/orb/pkg/cache"
+	"github.com/trustbloc/orb/pkg/cache/memory"
 )
 
 // WitnessPolicy evaluates witness policy.
 type WitnessPolicy struct {
 	retriever   policyRetriever
-	cache       gCache
+	cache       policyCache
 	cacheExpiry time.Duration
 
 	selector selector
@@ -37,13 +38,15 @@ const (
 	maxPercent = 100
 
 	defaultCacheSize = 10
+
+	policyCacheName = "witness-policy"
 )
 
 var logger = log.New("witness-policy")
 
-type gCache interface {
-	Get(key interface{}) (interface{}, error)
-	SetWithExpire(interface{}, interface{}, time.Duration) error
+type policyCache interface {
+	Get(key string) (interface{}, error)
+	Invalidate(key string) error
 }
 
 type selector interface {
@@ -54,32 +57,68 @@ type policyRetriever interface {
 	GetPolicy() (string, error)
 }
 
+// Option is a witness policy option.
+type Option func(wp *options)
+
+type options struct {
+	cacheProvider cache.Provider
+}
+
+// WithCacheProvider sets the provider of the witness policy cache. If the provider supports
+// shared caching then an invalidation of the policy (see Invalidate) is propagated to all instances.
+// If not set then an in-memory cache is used.
+func WithCacheProvider(p cache.Provider) Option {
+	return func(opts *options) {
+		opts.cacheProvider = p
+	}
+}
+
 // New will create new witness policy evaluator.
-func New(retriever policyRetriever, policyCacheExpiry time.Duration) (*WitnessPolicy, error) {
+func New(retriever policyRetriever, policyCacheExpiry time.Duration, opts ...Option) (*WitnessPolicy, error) {
+	options := &options{}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if options.cacheProvider == nil {
+		options.cacheProvider = memory.New()
+	}
+
 	wp := &WitnessPolicy{
 		retriever:   retriever,
 		cacheExpiry: policyCacheExpiry,
 		selector:    random.New(),
 	}
 
-	wp.cache = gcache.New(defaultCacheSize).ARC().LoaderExpireFunc(wp.loadWitnessPolicy).Build()
+	wp.cache = options.cacheProvider.NewCache(policyCacheName, wp.loadWitnessPolicy,
+		cache.WithSize(defaultCacheSize), cache.WithExpiration(policyCacheExpiry),
+	)
 
-	policy, _, err := wp.loadWitnessPolicy("")
+	// Load the policy into the cache.
+	policy, err := wp.cache.Get(WitnessPolicyKey)
 	if err != nil {
 		return nil, err
 	}
 
-	err = wp.cache.SetWithExpire(WitnessPolicyKey, policy, policyCacheExpiry)
-	if err != nil {
-		return nil, fmt.Errorf("failed to set expiry entry in policy cache: %w", err)
-	}
-
 	logger.Debug("Created new witness policy evaluator with cache",
 		log.WithWitnessPolicy(policy.(string)), log.WithCacheExpiration(policyCacheExpiry)) //nolint:forcetypeassert
 
 	return wp, nil
 }
 
+// Invalidate removes the witness policy from the cache so that the policy is reloaded from
+// the store on the next evaluation.
+func (wp *WitnessPolicy) Invalidate() error {
+	if err := wp.cache.Invalidate(WitnessPolicyKey); err != nil {
+		return fmt.Errorf("invalidate witness policy: %w", err)
+	}
+
+	logger.Debug("Invalidated witness policy")
+
+	return nil
+}
+
 // Evaluate evaluates if witness policy has been satisfied for provided witnesses.
 func (wp *WitnessPolicy) Evaluate(witnesses []*proof.WitnessProof) (bool, error) {
 	cfg, err := wp.getWitnessPolicyConfig()
@@ -125,15 +164,15 @@ func (wp *WitnessPolicy) Evaluate(witnesses []*proof.WitnessProof) (bool, error)
 	return evaluated, nil
 }
 
-func (wp *WitnessPolicy) loadWitnessPolicy(interface{}) (interface{}, *time.Duration, error) {
+func (wp *WitnessPolicy) loadWitnessPolicy(string) (interface{}, error) {
 	policy, err := wp.retriever.GetPolicy()
 	if err != nil && !errors.Is(err, storage.ErrDataNotFound) {
-		return nil, nil, err
+		return nil, err
 	}
 
 	logger.Debug("Loaded witness policy from store", log.WithWitnessPolicy(policy))
 
-	return policy, &wp.cacheExpiry, nil
+	return policy, nil
 }
 
 func (wp *WitnessPolicy) getWitnessPolicyConfig() (*config.WitnessPolicyConfig, error) {
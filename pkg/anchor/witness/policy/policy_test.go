@@ -17,6 +17,7 @@ import (
 	"github.com/trustbloc/orb/pkg/activitypub/vocab"
 	"github.com/trustbloc/orb/pkg/anchor/witness/policy/mocks"
 	"github.com/trustbloc/orb/pkg/anchor/witness/proof"
+	"github.com/trustbloc/orb/pkg/cache/memory"
 )
 
 const (
@@ -43,6 +44,14 @@ func TestNew(t *testing.T) {
 		time.Sleep(2 * time.Second)
 	})
 
+	t.Run("success - with cache provider", func(t *testing.T) {
+		policyStore := &mocks.PolicyStore{}
+
+		wp, err := New(policyStore, defaultPolicyCacheExpiry, WithCacheProvider(memory.New()))
+		require.NoError(t, err)
+		require.NotNil(t, wp)
+	})
+
 	t.Run("error - config store error", func(t *testing.T) {
 		policyStore := &mocks.PolicyStore{}
 		policyStore.GetPolicyReturns("", fmt.Errorf("get error"))
@@ -54,6 +63,41 @@ func TestNew(t *testing.T) {
 	})
 }
 
+func TestWitnessPolicy_Invalidate(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		policyStore := &mocks.PolicyStore{}
+		policyStore.GetPolicyReturns("MinPercent(30,system) AND MinPercent(70,batch)", nil)
+
+		wp, err := New(policyStore, defaultPolicyCacheExpiry)
+		require.NoError(t, err)
+		require.Equal(t, 1, policyStore.GetPolicyCallCount())
+
+		cfg, err := wp.getWitnessPolicyConfig()
+		require.NoError(t, err)
+		require.Equal(t, 30, cfg.MinPercentSystem)
+		require.Equal(t, 1, policyStore.GetPolicyCallCount())
+
+		policyStore.GetPolicyReturns("MinPercent(40,system) AND MinPercent(70,batch)", nil)
+
+		require.NoError(t, wp.Invalidate())
+
+		cfg, err = wp.getWitnessPolicyConfig()
+		require.NoError(t, err)
+		require.Equal(t, 40, cfg.MinPercentSystem)
+		require.Equal(t, 2, policyStore.GetPolicyCallCount())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		wp, err := New(&mocks.PolicyStore{}, defaultPolicyCacheExpiry)
+		require.NoError(t, err)
+
+		wp.cache = &mockCache{InvalidateErr: fmt.Errorf("injected invalidate error")}
+
+		err = wp.Invalidate()
+		require.EqualError(t, err, "invalidate witness policy: injected invalidate error")
+	})
+}
+
 //nolint:maintidx
 func TestEvaluate(t *testing.T) {
 	witnessURL, err := url.Parse("https://domain.com/service")
@@ -1314,12 +1358,12 @@ func TestDifference(t *testing.T) {
 }
 
 type mockCache struct {
-	GetErr   error
-	SetErr   error
-	GetValue interface{}
+	GetErr        error
+	InvalidateErr error
+	GetValue      interface{}
 }
 
-func (mc *mockCache) Get(interface{}) (interface{}, error) {
+func (mc *mockCache) Get(string) (interface{}, error) {
 	if mc.GetErr != nil {
 		return nil, mc.GetErr
 	}
@@ -1327,10 +1371,6 @@ func (mc *mockCache) Get(interface{}) (interface{}, error) {
 	return mc.GetValue, nil
 }
 
-func (mc *mockCache) SetWithExpire(interface{}, interface{}, time.Duration) error {
-	if mc.SetErr != nil {
-		return mc.SetErr
-	}
-
-	return nil
+func (mc *mockCache) Invalidate(string) error {
+	return mc.InvalidateErr
 }
@@ -30,9 +30,25 @@ type policyStore interface {
 	GetPolicy() (string, error)
 }
 
+type policyInvalidator interface {
+	Invalidate() error
+}
+
 // PolicyConfigurator updates witness policy in config store.
 type PolicyConfigurator struct {
-	store policyStore
+	store       policyStore
+	invalidator policyInvalidator
+}
+
+// Option is a policy configurator option.
+type Option func(pc *PolicyConfigurator)
+
+// WithPolicyInvalidator sets the invalidator which is invoked after the witness policy is updated
+// so that the cached policy is reloaded (on all instances if the cache is shared).
+func WithPolicyInvalidator(invalidator policyInvalidator) Option {
+	return func(pc *PolicyConfigurator) {
+		pc.invalidator = invalidator
+	}
 }
 
 // Path returns the HTTP REST endpoint for the PolicyConfigurator service.
@@ -51,11 +67,15 @@ func (pc *PolicyConfigurator) Handler() common.HTTPRequestHandler {
 }
 
 // New returns a new PolicyConfigurator.
-func New(store policyStore) *PolicyConfigurator {
+func New(store policyStore, opts ...Option) *PolicyConfigurator {
 	h := &PolicyConfigurator{
 		store: store,
 	}
 
+	for _, opt := range opts {
+		opt(h)
+	}
+
 	return h
 }
 
@@ -91,6 +111,13 @@ func (pc *PolicyConfigurator) handle(w http.ResponseWriter, req *http.Request) {
 
 	logger.Debug("Stored witness policy", log.WithWitnessPolicy(policyStr))
 
+	if pc.invalidator != nil {
+		if err := pc.invalidator.Invalidate(); err != nil {
+			// The policy was stored. The cached policy will be reloaded when it expires.
+			logger.Warn("Error invalidating cached witness policy", log.WithError(err))
+		}
+	}
+
 	writeResponse(w, http.StatusOK, nil)
 }
 
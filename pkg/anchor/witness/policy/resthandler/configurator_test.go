@@ -122,6 +122,70 @@ func TestHandler(t *testing.T) {
 	})
 }
 
+func TestHandler_Invalidate(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		invalidator := &mockInvalidator{}
+
+		policyConfigurator := New(&mocks.PolicyStore{}, WithPolicyInvalidator(invalidator))
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, endpoint, bytes.NewBuffer([]byte(testPolicy)))
+
+		policyConfigurator.handle(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+		require.Equal(t, 1, invalidator.numCalls)
+	})
+
+	t.Run("invalidate error", func(t *testing.T) {
+		invalidator := &mockInvalidator{err: errors.New("injected invalidate error")}
+
+		policyConfigurator := New(&mocks.PolicyStore{}, WithPolicyInvalidator(invalidator))
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, endpoint, bytes.NewBuffer([]byte(testPolicy)))
+
+		policyConfigurator.handle(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+		require.Equal(t, 1, invalidator.numCalls)
+	})
+
+	t.Run("not invalidated on store error", func(t *testing.T) {
+		invalidator := &mockInvalidator{}
+
+		policyStore := &mocks.PolicyStore{}
+		policyStore.PutPolicyReturns(fmt.Errorf("put error"))
+
+		policyConfigurator := New(policyStore, WithPolicyInvalidator(invalidator))
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, endpoint, bytes.NewBuffer([]byte(testPolicy)))
+
+		policyConfigurator.handle(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+		require.Zero(t, invalidator.numCalls)
+	})
+}
+
+type mockInvalidator struct {
+	err      error
+	numCalls int
+}
+
+func (m *mockInvalidator) Invalidate() error {
+	m.numCalls++
+
+	return m.err
+}
+
 type errReader int
 
 func (errReader) Read(p []byte) (n int, err error) {
@@ -0,0 +1,133 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Loader loads the value for the given key when the value is not found in the cache.
+type Loader func(key string) (interface{}, error)
+
+// Cache is a loading cache. If a value isn't found in the cache then the value
+// is loaded using the Loader that was provided when the cache was created.
+type Cache interface {
+	// Get returns the value for the given key. If the value isn't cached then it is loaded.
+	Get(key string) (interface{}, error)
+
+	// Invalidate removes the value for the given key from the cache. If the cache is shared
+	// between multiple instances then the value is removed from all instances.
+	Invalidate(key string) error
+}
+
+// Provider creates caches.
+type Provider interface {
+	// NewCache returns a new cache with the given name. Caches with the same name on different
+	// instances share the same values if the provider supports shared caching.
+	NewCache(name string, loader Loader, opts ...Option) Cache
+}
+
+// Codec marshals and unmarshals cached values. A codec is required in order for
+// values to be shared across instances.
+type Codec interface {
+	Marshal(value interface{}) ([]byte, error)
+	Unmarshal(data []byte) (interface{}, error)
+}
+
+// Options holds the options for a cache.
+type Options struct {
+	Size       int
+	Expiration time.Duration
+	Codec      Codec
+
+	expirationSet bool
+}
+
+// CachingDisabled returns true if an expiration of zero (or less) was specified, in which case values
+// are never kept and are loaded on every request. If no expiration was specified then values don't expire.
+func (o *Options) CachingDisabled() bool {
+	return o.expirationSet && o.Expiration <= 0
+}
+
+// Option sets a cache option.
+type Option func(opts *Options)
+
+// WithSize sets the maximum number of entries that are held in memory.
+func WithSize(size int) Option {
+	return func(opts *Options) {
+		opts.Size = size
+	}
+}
+
+// WithExpiration sets the expiration of cached values. An expiration of zero disables caching.
+func WithExpiration(expiration time.Duration) Option {
+	return func(opts *Options) {
+		opts.Expiration = expiration
+		opts.expirationSet = true
+	}
+}
+
+// WithCodec sets the codec which is used to marshal and unmarshal values so that
+// they may be shared across instances.
+func WithCodec(codec Codec) Option {
+	return func(opts *Options) {
+		opts.Codec = codec
+	}
+}
+
+const defaultSize = 100
+
+// GetOptions returns the options, populated with defaults for any option not specified.
+func GetOptions(opts ...Option) *Options {
+	options := &Options{
+		Size: defaultSize,
+	}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if options.Size <= 0 {
+		options.Size = defaultSize
+	}
+
+	return options
+}
+
+// JSONCodec marshals and unmarshals values using JSON.
+type JSONCodec struct {
+	newValue func() interface{}
+}
+
+// NewJSONCodec returns a JSON codec. The newValue function returns a pointer to
+// a new value into which the JSON data is unmarshalled.
+func NewJSONCodec(newValue func() interface{}) *JSONCodec {
+	return &JSONCodec{newValue: newValue}
+}
+
+// Marshal marshals the given value to JSON.
+func (c *JSONCodec) Marshal(value interface{}) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("marshal cache value: %w", err)
+	}
+
+	return data, nil
+}
+
+// Unmarshal unmarshals the given JSON data into a new value.
+func (c *JSONCodec) Unmarshal(data []byte) (interface{}, error) {
+	value := c.newValue()
+
+	if err := json.Unmarshal(data, value); err != nil {
+		return nil, fmt.Errorf("unmarshal cache value: %w", err)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOptions(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		opts := GetOptions()
+		require.Equal(t, defaultSize, opts.Size)
+		require.Zero(t, opts.Expiration)
+		require.Nil(t, opts.Codec)
+		require.False(t, opts.CachingDisabled())
+	})
+
+	t.Run("options", func(t *testing.T) {
+		codec := NewJSONCodec(func() interface{} { return &struct{}{} })
+
+		opts := GetOptions(WithSize(10), WithExpiration(time.Minute), WithCodec(codec))
+		require.Equal(t, 10, opts.Size)
+		require.Equal(t, time.Minute, opts.Expiration)
+		require.Equal(t, codec, opts.Codec)
+		require.False(t, opts.CachingDisabled())
+	})
+
+	t.Run("zero expiration", func(t *testing.T) {
+		opts := GetOptions(WithExpiration(0))
+		require.Zero(t, opts.Expiration)
+		require.True(t, opts.CachingDisabled())
+	})
+
+	t.Run("invalid size", func(t *testing.T) {
+		opts := GetOptions(WithSize(-1))
+		require.Equal(t, defaultSize, opts.Size)
+	})
+}
+
+func TestJSONCodec(t *testing.T) {
+	type value struct {
+		Field string `json:"field"`
+	}
+
+	codec := NewJSONCodec(func() interface{} { return &value{} })
+
+	t.Run("success", func(t *testing.T) {
+		data, err := codec.Marshal(&value{Field: "value1"})
+		require.NoError(t, err)
+		require.Equal(t, `{"field":"value1"}`, string(data))
+
+		v, err := codec.Unmarshal(data)
+		require.NoError(t, err)
+		require.Equal(t, &value{Field: "value1"}, v)
+	})
+
+	t.Run("marshal error", func(t *testing.T) {
+		_, err := codec.Marshal(func() {})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "marshal cache value")
+	})
+
+	t.Run("unmarshal error", func(t *testing.T) {
+		_, err := codec.Unmarshal([]byte("{"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal cache value")
+	})
+}
@@ -0,0 +1,73 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package memory
+
+import (
+	"github.com/bluele/gcache"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/cache"
+)
+
+var logger = log.New("memory-cache")
+
+// Provider creates in-memory caches. The caches are local to the instance and
+// therefore values are not shared across instances.
+type Provider struct{}
+
+// New returns a new in-memory cache provider.
+func New() *Provider {
+	return &Provider{}
+}
+
+// NewCache returns a new in-memory ARC cache.
+func (p *Provider) NewCache(name string, loader cache.Loader, opts ...cache.Option) cache.Cache {
+	options := cache.GetOptions(opts...)
+
+	logger.Debug("Creating in-memory cache", log.WithCacheName(name), log.WithSize(options.Size),
+		log.WithCacheExpiration(options.Expiration))
+
+	return &Cache{
+		name:  name,
+		cache: NewGCache(options, loader),
+	}
+}
+
+// Cache is an in-memory cache.
+type Cache struct {
+	name  string
+	cache gcache.Cache
+}
+
+// Get returns the value for the given key. If the value isn't cached then it is loaded.
+func (c *Cache) Get(key string) (interface{}, error) {
+	return c.cache.Get(key)
+}
+
+// Invalidate removes the value for the given key from the cache.
+func (c *Cache) Invalidate(key string) error {
+	c.cache.Remove(key)
+
+	logger.Debug("Invalidated cache entry", log.WithCacheName(c.name), log.WithKey(key))
+
+	return nil
+}
+
+// NewGCache returns an ARC cache for the given options and loader.
+func NewGCache(options *cache.Options, loader cache.Loader) gcache.Cache {
+	builder := gcache.New(options.Size).ARC().
+		LoaderFunc(func(key interface{}) (interface{}, error) {
+			return loader(key.(string)) //nolint:forcetypeassert
+		})
+
+	if options.Expiration > 0 || options.CachingDisabled() {
+		// Note that an expiration of zero results in the value being reloaded on every Get.
+		builder = builder.Expiration(options.Expiration)
+	}
+
+	return builder.Build()
+}
@@ -0,0 +1,111 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package memory
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/cache"
+)
+
+func TestCache(t *testing.T) {
+	var numLoads int
+
+	c := New().NewCache("test-cache", func(key string) (interface{}, error) {
+		numLoads++
+
+		if key == "error" {
+			return nil, errors.New("injected loader error")
+		}
+
+		return "value-" + key, nil
+	}, cache.WithSize(10), cache.WithExpiration(time.Minute))
+	require.NotNil(t, c)
+
+	t.Run("success", func(t *testing.T) {
+		v, err := c.Get("key1")
+		require.NoError(t, err)
+		require.Equal(t, "value-key1", v)
+		require.Equal(t, 1, numLoads)
+
+		v, err = c.Get("key1")
+		require.NoError(t, err)
+		require.Equal(t, "value-key1", v)
+		require.Equal(t, 1, numLoads)
+
+		require.NoError(t, c.Invalidate("key1"))
+
+		v, err = c.Get("key1")
+		require.NoError(t, err)
+		require.Equal(t, "value-key1", v)
+		require.Equal(t, 2, numLoads)
+	})
+
+	t.Run("loader error", func(t *testing.T) {
+		_, err := c.Get("error")
+		require.EqualError(t, err, "injected loader error")
+	})
+}
+
+func TestCache_Expiration(t *testing.T) {
+	var numLoads int
+
+	c := New().NewCache("test-cache", func(key string) (interface{}, error) {
+		numLoads++
+
+		return "value", nil
+	}, cache.WithExpiration(10*time.Millisecond))
+
+	_, err := c.Get("key1")
+	require.NoError(t, err)
+
+	time.Sleep(20 * time.Millisecond)
+
+	_, err = c.Get("key1")
+	require.NoError(t, err)
+	require.Equal(t, 2, numLoads)
+}
+
+func TestCache_ZeroExpiration(t *testing.T) {
+	var numLoads int
+
+	c := New().NewCache("test-cache", func(key string) (interface{}, error) {
+		numLoads++
+
+		return numLoads, nil
+	}, cache.WithExpiration(0))
+
+	v, err := c.Get("key1")
+	require.NoError(t, err)
+	require.Equal(t, 1, v)
+
+	v, err = c.Get("key1")
+	require.NoError(t, err)
+	require.Equal(t, 2, v)
+}
+
+func TestCache_NoExpiration(t *testing.T) {
+	var numLoads int
+
+	c := New().NewCache("test-cache", func(key string) (interface{}, error) {
+		numLoads++
+
+		return numLoads, nil
+	})
+
+	v, err := c.Get("key1")
+	require.NoError(t, err)
+	require.Equal(t, 1, v)
+
+	v, err = c.Get("key1")
+	require.NoError(t, err)
+	require.Equal(t, 1, v)
+}
@@ -0,0 +1,402 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bluele/gcache"
+	goredis "github.com/go-redis/redis/v8"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/cache"
+	"github.com/trustbloc/orb/pkg/cache/memory"
+)
+
+var logger = log.New("redis-cache")
+
+const (
+	defaultTimeout           = 5 * time.Second
+	defaultPoolSize          = 10
+	defaultKeyPrefix         = "orb-cache"
+	defaultReconnectInterval = time.Second
+
+	invalidationChannelSuffix = "invalidate"
+)
+
+// Provider creates caches which are shared across instances using a Redis server (or any server
+// which is compatible with the Redis protocol). Values are held in a local in-memory cache in front
+// of the Redis server and invalidations are broadcast to all instances using Redis Pub/Sub.
+//
+// Values are only stored in the Redis server for caches that are created with a codec
+// (see cache.WithCodec). Invalidations are broadcast for all caches.
+type Provider struct {
+	client            *goredis.Client
+	pubSub            *goredis.PubSub
+	keyPrefix         string
+	channel           string
+	timeout           time.Duration
+	reconnectInterval time.Duration
+
+	mutex     sync.RWMutex
+	caches    map[string]*Cache
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+type options struct {
+	password          string
+	timeout           time.Duration
+	poolSize          int
+	keyPrefix         string
+	reconnectInterval time.Duration
+}
+
+// Option sets a Redis provider option.
+type Option func(opts *options)
+
+// WithPassword sets the password used to authenticate to the Redis server.
+func WithPassword(password string) Option {
+	return func(opts *options) {
+		opts.password = password
+	}
+}
+
+// WithTimeout sets the timeout for connecting to the Redis server and for each command.
+func WithTimeout(timeout time.Duration) Option {
+	return func(opts *options) {
+		opts.timeout = timeout
+	}
+}
+
+// WithPoolSize sets the maximum number of connections to the Redis server.
+func WithPoolSize(size int) Option {
+	return func(opts *options) {
+		opts.poolSize = size
+	}
+}
+
+// WithKeyPrefix sets the prefix of all keys (and the invalidation channel) in the Redis server.
+func WithKeyPrefix(prefix string) Option {
+	return func(opts *options) {
+		opts.keyPrefix = prefix
+	}
+}
+
+// WithReconnectInterval sets the interval between attempts to reconnect the invalidation subscriber.
+func WithReconnectInterval(interval time.Duration) Option {
+	return func(opts *options) {
+		opts.reconnectInterval = interval
+	}
+}
+
+type invalidation struct {
+	Cache string `json:"cache"`
+	Key   string `json:"key"`
+}
+
+// New returns a new Redis cache provider for the server at the given address (host:port). An error is
+// returned if the server can't be reached. Close should be called when the provider is no longer required.
+func New(address string, opts ...Option) (*Provider, error) {
+	options := &options{
+		timeout:           defaultTimeout,
+		poolSize:          defaultPoolSize,
+		keyPrefix:         defaultKeyPrefix,
+		reconnectInterval: defaultReconnectInterval,
+	}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	client := goredis.NewClient(&goredis.Options{
+		Addr:         address,
+		Password:     options.password,
+		DialTimeout:  options.timeout,
+		ReadTimeout:  options.timeout,
+		WriteTimeout: options.timeout,
+		PoolSize:     options.poolSize,
+	})
+
+	p := &Provider{
+		client:            client,
+		keyPrefix:         options.keyPrefix,
+		channel:           options.keyPrefix + ":" + invalidationChannelSuffix,
+		timeout:           options.timeout,
+		reconnectInterval: options.reconnectInterval,
+		caches:            make(map[string]*Cache),
+		done:              make(chan struct{}),
+	}
+
+	ctx, cancel := p.context()
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close() //nolint:errcheck
+
+		return nil, fmt.Errorf("ping Redis server [%s]: %w", address, err)
+	}
+
+	p.pubSub = client.Subscribe(ctx, p.channel)
+
+	// Wait for the subscription to be confirmed so that no invalidations are missed.
+	if _, err := p.pubSub.ReceiveTimeout(ctx, options.timeout); err != nil {
+		_ = p.pubSub.Close() //nolint:errcheck
+		_ = client.Close()   //nolint:errcheck
+
+		return nil, fmt.Errorf("subscribe to invalidation channel: %w", err)
+	}
+
+	go p.listen()
+
+	logger.Info("Created Redis cache provider", log.WithAddress(address), log.WithTopic(p.channel))
+
+	return p, nil
+}
+
+// NewCache returns a new cache with the given name. The name must be the same on all instances
+// in order for the cache to be shared.
+func (p *Provider) NewCache(name string, loader cache.Loader, opts ...cache.Option) cache.Cache {
+	options := cache.GetOptions(opts...)
+
+	logger.Debug("Creating Redis cache", log.WithCacheName(name), log.WithSize(options.Size),
+		log.WithCacheExpiration(options.Expiration))
+
+	c := &Cache{
+		name:     name,
+		provider: p,
+		loader:   loader,
+		options:  options,
+	}
+
+	c.local = memory.NewGCache(options, c.load)
+
+	p.mutex.Lock()
+	p.caches[name] = c
+	p.mutex.Unlock()
+
+	return c
+}
+
+// Close stops the invalidation subscriber and closes all connections.
+func (p *Provider) Close() {
+	p.closeOnce.Do(func() {
+		close(p.done)
+
+		if err := p.pubSub.Close(); err != nil {
+			logger.Debug("Error closing invalidation subscriber", log.WithError(err))
+		}
+
+		if err := p.client.Close(); err != nil {
+			logger.Debug("Error closing Redis client", log.WithError(err))
+		}
+
+		logger.Info("Closed Redis cache provider")
+	})
+}
+
+// listen receives invalidations until the provider is closed. The subscriber is reconnected (and resubscribed)
+// by the Redis client after a connection error.
+func (p *Provider) listen() {
+	disconnected := false
+
+	for {
+		msg, err := p.pubSub.Receive(context.Background())
+		if err != nil {
+			select {
+			case <-p.done:
+				logger.Debug("Invalidation subscriber stopped")
+
+				return
+			default:
+			}
+
+			if !disconnected {
+				logger.Warn("Invalidation subscriber disconnected", log.WithError(err))
+			}
+
+			disconnected = true
+
+			select {
+			case <-p.done:
+				logger.Debug("Invalidation subscriber stopped")
+
+				return
+			case <-time.After(p.reconnectInterval):
+			}
+
+			continue
+		}
+
+		switch m := msg.(type) {
+		case *goredis.Subscription:
+			if disconnected {
+				disconnected = false
+
+				// Invalidations may have been missed while disconnected so purge all local values.
+				p.purgeLocal()
+
+				logger.Info("Reconnected invalidation subscriber")
+			}
+		case *goredis.Message:
+			p.handleInvalidation([]byte(m.Payload))
+		default:
+			logger.Debug("Ignoring unexpected message on invalidation channel",
+				log.WithData([]byte(fmt.Sprintf("%v", msg))))
+		}
+	}
+}
+
+func (p *Provider) handleInvalidation(payload []byte) {
+	inv := &invalidation{}
+
+	if err := json.Unmarshal(payload, inv); err != nil {
+		logger.Warn("Invalid message on invalidation channel", log.WithError(err))
+
+		return
+	}
+
+	p.mutex.RLock()
+	c, ok := p.caches[inv.Cache]
+	p.mutex.RUnlock()
+
+	if !ok {
+		return
+	}
+
+	c.local.Remove(inv.Key)
+
+	logger.Debug("Invalidated local cache entry", log.WithCacheName(inv.Cache), log.WithKey(inv.Key))
+}
+
+func (p *Provider) purgeLocal() {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	for _, c := range p.caches {
+		c.local.Purge()
+	}
+}
+
+func (p *Provider) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), p.timeout)
+}
+
+// Cache is a cache which is shared across instances.
+type Cache struct {
+	name     string
+	provider *Provider
+	loader   cache.Loader
+	options  *cache.Options
+	local    gcache.Cache
+}
+
+// Get returns the value for the given key. The value is first looked up in the local cache, then in the
+// Redis server and, if not found, the value is loaded and stored in the Redis server.
+func (c *Cache) Get(key string) (interface{}, error) {
+	return c.local.Get(key)
+}
+
+// Invalidate removes the value for the given key from the Redis server and from the local caches of all instances.
+func (c *Cache) Invalidate(key string) error {
+	c.local.Remove(key)
+
+	ctx, cancel := c.provider.context()
+	defer cancel()
+
+	if c.options.Codec != nil {
+		if err := c.provider.client.Del(ctx, c.redisKey(key)).Err(); err != nil {
+			return fmt.Errorf("delete key from Redis: %w", err)
+		}
+	}
+
+	payload, err := json.Marshal(&invalidation{Cache: c.name, Key: key})
+	if err != nil {
+		return fmt.Errorf("marshal invalidation: %w", err)
+	}
+
+	if err := c.provider.client.Publish(ctx, c.provider.channel, payload).Err(); err != nil {
+		return fmt.Errorf("publish invalidation: %w", err)
+	}
+
+	logger.Debug("Published cache invalidation", log.WithCacheName(c.name), log.WithKey(key))
+
+	return nil
+}
+
+func (c *Cache) load(key string) (interface{}, error) {
+	if c.options.Codec == nil || c.options.CachingDisabled() {
+		return c.loader(key)
+	}
+
+	redisKey := c.redisKey(key)
+
+	value, ok := c.getShared(redisKey)
+	if ok {
+		return value, nil
+	}
+
+	value, err := c.loader(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := c.options.Codec.Marshal(value)
+	if err != nil {
+		logger.Warn("Error marshalling value for Redis cache", log.WithCacheName(c.name), log.WithKey(key),
+			log.WithError(err))
+
+		return value, nil
+	}
+
+	ctx, cancel := c.provider.context()
+	defer cancel()
+
+	if err := c.provider.client.Set(ctx, redisKey, data, c.options.Expiration).Err(); err != nil {
+		// The value was loaded so don't fail the request if it can't be shared.
+		logger.Warn("Error storing value in Redis cache", log.WithCacheName(c.name), log.WithKey(key),
+			log.WithError(err))
+	}
+
+	return value, nil
+}
+
+func (c *Cache) getShared(redisKey string) (interface{}, bool) {
+	ctx, cancel := c.provider.context()
+	defer cancel()
+
+	data, err := c.provider.client.Get(ctx, redisKey).Bytes()
+	if err != nil {
+		if !errors.Is(err, goredis.Nil) {
+			logger.Warn("Error getting value from Redis cache. The value will be loaded.",
+				log.WithCacheName(c.name), log.WithKey(redisKey), log.WithError(err))
+		}
+
+		return nil, false
+	}
+
+	value, err := c.options.Codec.Unmarshal(data)
+	if err != nil {
+		logger.Warn("Error unmarshalling value from Redis cache. The value will be loaded.",
+			log.WithCacheName(c.name), log.WithKey(redisKey), log.WithError(err))
+
+		return nil, false
+	}
+
+	logger.Debug("Got value from Redis cache", log.WithCacheName(c.name), log.WithKey(redisKey))
+
+	return value, true
+}
+
+func (c *Cache) redisKey(key string) string {
+	return fmt.Sprintf("%s:%s:%s", c.provider.keyPrefix, c.name, key)
+}
@@ -0,0 +1,305 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package redis
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/cache"
+	"github.com/trustbloc/orb/pkg/internal/testutil/redistestutil"
+)
+
+type testValue struct {
+	Field string `json:"field"`
+}
+
+func TestNew(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		_, address, stop := redistestutil.StartServer("")
+		defer stop()
+
+		p, err := New(address)
+		require.NoError(t, err)
+		require.NotNil(t, p)
+
+		p.Close()
+		p.Close() // Should be OK to close twice.
+	})
+
+	t.Run("with password", func(t *testing.T) {
+		_, address, stop := redistestutil.StartServer("secret")
+		defer stop()
+
+		p, err := New(address, WithPassword("secret"), WithTimeout(time.Second), WithPoolSize(2),
+			WithKeyPrefix("test"), WithReconnectInterval(10*time.Millisecond))
+		require.NoError(t, err)
+		require.NotNil(t, p)
+		require.Equal(t, "test:invalidate", p.channel)
+
+		p.Close()
+	})
+
+	t.Run("invalid password", func(t *testing.T) {
+		_, address, stop := redistestutil.StartServer("secret")
+		defer stop()
+
+		_, err := New(address, WithPassword("invalid"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "WRONGPASS")
+	})
+
+	t.Run("no password", func(t *testing.T) {
+		_, address, stop := redistestutil.StartServer("secret")
+		defer stop()
+
+		_, err := New(address)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "NOAUTH")
+	})
+
+	t.Run("connection error", func(t *testing.T) {
+		_, err := New("127.0.0.1:1", WithTimeout(time.Second))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "ping Redis server")
+	})
+}
+
+func TestCache_Shared(t *testing.T) {
+	server, address, stop := redistestutil.StartServer("")
+	defer stop()
+
+	p1, err := New(address)
+	require.NoError(t, err)
+	defer p1.Close()
+
+	p2, err := New(address)
+	require.NoError(t, err)
+	defer p2.Close()
+
+	var numLoads int32
+
+	loader := func(key string) (interface{}, error) {
+		atomic.AddInt32(&numLoads, 1)
+
+		if key == "error" {
+			return nil, errors.New("injected loader error")
+		}
+
+		return &testValue{Field: "value-" + key}, nil
+	}
+
+	codec := cache.NewJSONCodec(func() interface{} { return &testValue{} })
+
+	c1 := p1.NewCache("test-cache", loader, cache.WithCodec(codec), cache.WithExpiration(time.Minute))
+	c2 := p2.NewCache("test-cache", loader, cache.WithCodec(codec), cache.WithExpiration(time.Minute))
+
+	t.Run("value is loaded once and shared", func(t *testing.T) {
+		v, err := c1.Get("key1")
+		require.NoError(t, err)
+		require.Equal(t, &testValue{Field: "value-key1"}, v)
+		require.Equal(t, int32(1), atomic.LoadInt32(&numLoads))
+		require.Equal(t, `{"field":"value-key1"}`, string(server.Get("orb-cache:test-cache:key1")))
+
+		v, err = c2.Get("key1")
+		require.NoError(t, err)
+		require.Equal(t, &testValue{Field: "value-key1"}, v)
+		require.Equal(t, int32(1), atomic.LoadInt32(&numLoads))
+	})
+
+	t.Run("invalidation", func(t *testing.T) {
+		_, err := c2.Get("key2")
+		require.NoError(t, err)
+
+		// Change the shared value directly so that we can verify that the local caches are invalidated.
+		server.Set("orb-cache:test-cache:key2", []byte(`{"field":"new-value"}`))
+
+		v, err := c1.Get("key2")
+		require.NoError(t, err)
+		require.Equal(t, &testValue{Field: "new-value"}, v)
+
+		v, err = c2.Get("key2")
+		require.NoError(t, err)
+		require.Equal(t, &testValue{Field: "value-key2"}, v) // Still cached locally.
+
+		require.NoError(t, c1.Invalidate("key2"))
+		require.Nil(t, server.Get("orb-cache:test-cache:key2"))
+
+		require.Eventually(t, func() bool {
+			v, err := c2.Get("key2")
+			require.NoError(t, err)
+
+			return v.(*testValue).Field == "value-key2" && server.Get("orb-cache:test-cache:key2") != nil
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("loader error", func(t *testing.T) {
+		_, err := c1.Get("error")
+		require.EqualError(t, err, "injected loader error")
+	})
+
+	t.Run("invalid shared value", func(t *testing.T) {
+		server.Set("orb-cache:test-cache:key3", []byte(`{`))
+
+		v, err := c1.Get("key3")
+		require.NoError(t, err)
+		require.Equal(t, &testValue{Field: "value-key3"}, v)
+	})
+
+	t.Run("marshal error", func(t *testing.T) {
+		c := p1.NewCache("func-cache", func(key string) (interface{}, error) {
+			return func() {}, nil
+		}, cache.WithCodec(codec))
+
+		v, err := c.Get("key1")
+		require.NoError(t, err)
+		require.NotNil(t, v)
+	})
+}
+
+func TestCache_LocalOnly(t *testing.T) {
+	server, address, stop := redistestutil.StartServer("")
+	defer stop()
+
+	p1, err := New(address)
+	require.NoError(t, err)
+	defer p1.Close()
+
+	p2, err := New(address)
+	require.NoError(t, err)
+	defer p2.Close()
+
+	var numLoads int32
+
+	loader := func(key string) (interface{}, error) {
+		return atomic.AddInt32(&numLoads, 1), nil
+	}
+
+	// No codec so values are not stored in Redis but invalidations are still broadcast.
+	c1 := p1.NewCache("local-cache", loader)
+	c2 := p2.NewCache("local-cache", loader)
+
+	v1, err := c1.Get("key1")
+	require.NoError(t, err)
+	require.Equal(t, int32(1), v1)
+	require.Nil(t, server.Get("orb-cache:local-cache:key1"))
+
+	v2, err := c2.Get("key1")
+	require.NoError(t, err)
+	require.Equal(t, int32(2), v2)
+
+	require.NoError(t, c1.Invalidate("key1"))
+
+	require.Eventually(t, func() bool {
+		v, err := c2.Get("key1")
+		require.NoError(t, err)
+
+		return v.(int32) > 2
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestCache_ZeroExpiration(t *testing.T) {
+	server, address, stop := redistestutil.StartServer("")
+	defer stop()
+
+	p, err := New(address)
+	require.NoError(t, err)
+	defer p.Close()
+
+	var numLoads int32
+
+	c := p.NewCache("no-cache", func(key string) (interface{}, error) {
+		return atomic.AddInt32(&numLoads, 1), nil
+	}, cache.WithExpiration(0), cache.WithCodec(cache.NewJSONCodec(func() interface{} { return new(int32) })))
+
+	v, err := c.Get("key1")
+	require.NoError(t, err)
+	require.Equal(t, int32(1), v)
+	require.Nil(t, server.Get("orb-cache:no-cache:key1"))
+
+	v, err = c.Get("key1")
+	require.NoError(t, err)
+	require.Equal(t, int32(2), v)
+}
+
+func TestCache_Reconnect(t *testing.T) {
+	server, address, stop := redistestutil.StartServer("")
+	defer stop()
+
+	p, err := New(address, WithReconnectInterval(10*time.Millisecond))
+	require.NoError(t, err)
+	defer p.Close()
+
+	var numLoads int32
+
+	c := p.NewCache("test-cache", func(key string) (interface{}, error) {
+		return atomic.AddInt32(&numLoads, 1), nil
+	})
+
+	_, err = c.Get("key1")
+	require.NoError(t, err)
+
+	server.DisconnectAll()
+
+	// The local cache is purged after the subscriber reconnects.
+	require.Eventually(t, func() bool {
+		v, err := c.Get("key1")
+		require.NoError(t, err)
+
+		return v.(int32) > 1
+	}, time.Second, 10*time.Millisecond)
+
+	// Commands should succeed on new connections.
+	require.NoError(t, c.Invalidate("key1"))
+}
+
+func TestCache_ServerUnavailable(t *testing.T) {
+	_, address, stop := redistestutil.StartServer("")
+
+	p, err := New(address, WithTimeout(100*time.Millisecond), WithReconnectInterval(10*time.Millisecond))
+	require.NoError(t, err)
+	defer p.Close()
+
+	c := p.NewCache("test-cache", func(key string) (interface{}, error) {
+		return &testValue{Field: "value-" + key}, nil
+	}, cache.WithCodec(cache.NewJSONCodec(func() interface{} { return &testValue{} })))
+
+	stop()
+
+	// The value should still be loaded even though the server is unavailable.
+	v, err := c.Get("key1")
+	require.NoError(t, err)
+	require.Equal(t, &testValue{Field: "value-key1"}, v)
+
+	err = c.Invalidate("key1")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "delete key from Redis")
+
+	c = p.NewCache("local-cache", func(key string) (interface{}, error) { return key, nil })
+
+	err = c.Invalidate("key1")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "publish invalidation")
+}
+
+func TestProvider_HandleInvalidation(t *testing.T) {
+	_, address, stop := redistestutil.StartServer("")
+	defer stop()
+
+	p, err := New(address)
+	require.NoError(t, err)
+	defer p.Close()
+
+	require.NotPanics(t, func() {
+		p.handleInvalidation([]byte("{"))
+		p.handleInvalidation([]byte(`{"cache":"unknown","key":"key1"}`))
+	})
+}
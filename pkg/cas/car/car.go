@@ -0,0 +1,190 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package car
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	gocid "github.com/ipfs/go-cid"
+)
+
+const (
+	carVersion = 1
+
+	// maxSectionSize is the maximum size of a header or block section. It protects the reader from allocating
+	// a huge buffer due to a corrupt archive.
+	maxSectionSize = 32 << 20
+)
+
+// Block is a block of content in a CAR archive.
+type Block struct {
+	CID  string
+	Data []byte
+}
+
+// Writer writes blocks to an archive in the CARv1 format (https://ipld.io/specs/transport/car/carv1/).
+// A block is written only once even if it's added multiple times.
+type Writer struct {
+	w       io.Writer
+	written map[string]struct{}
+}
+
+// NewWriter writes the CAR header with the given root CIDs and returns a Writer to which blocks may be added.
+func NewWriter(w io.Writer, roots ...string) (*Writer, error) {
+	rootCIDs := make([][]byte, len(roots))
+
+	for i, root := range roots {
+		c, err := gocid.Decode(root)
+		if err != nil {
+			return nil, fmt.Errorf("decode root CID [%s]: %w", root, err)
+		}
+
+		rootCIDs[i] = c.Bytes()
+	}
+
+	headerBytes, err := encodeHeader(rootCIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeSection(w, headerBytes); err != nil {
+		return nil, fmt.Errorf("write header: %w", err)
+	}
+
+	return &Writer{
+		w:       w,
+		written: make(map[string]struct{}),
+	}, nil
+}
+
+// Put writes the given block. The block is skipped if a block with the same CID was already written.
+func (w *Writer) Put(cid string, data []byte) error {
+	c, err := gocid.Decode(cid)
+	if err != nil {
+		return fmt.Errorf("decode CID [%s]: %w", cid, err)
+	}
+
+	key := c.KeyString()
+
+	if _, ok := w.written[key]; ok {
+		return nil
+	}
+
+	if err := writeSection(w.w, append(c.Bytes(), data...)); err != nil {
+		return fmt.Errorf("write block [%s]: %w", cid, err)
+	}
+
+	w.written[key] = struct{}{}
+
+	return nil
+}
+
+// Reader reads blocks from an archive in the CARv1 format.
+type Reader struct {
+	r     *bufio.Reader
+	roots []string
+}
+
+// NewReader reads the CAR header and returns a Reader from which blocks may be read.
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+
+	headerBytes, err := readSection(br)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("read header: empty archive")
+		}
+
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+
+	rootCIDs, version, err := decodeHeader(headerBytes)
+	if err != nil {
+		return nil, fmt.Errorf("decode header: %w", err)
+	}
+
+	if version != carVersion {
+		return nil, fmt.Errorf("unsupported CAR version: %d", version)
+	}
+
+	roots := make([]string, len(rootCIDs))
+
+	for i, rootCID := range rootCIDs {
+		c, err := gocid.Cast(rootCID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid root CID: %w", err)
+		}
+
+		roots[i] = c.String()
+	}
+
+	return &Reader{r: br, roots: roots}, nil
+}
+
+// Roots returns the root CIDs of the archive.
+func (r *Reader) Roots() []string {
+	return r.roots
+}
+
+// Next returns the next block in the archive. io.EOF is returned when there are no more blocks.
+func (r *Reader) Next() (*Block, error) {
+	section, err := readSection(r.r)
+	if err != nil {
+		return nil, err
+	}
+
+	n, c, err := gocid.CidFromBytes(section)
+	if err != nil {
+		return nil, fmt.Errorf("read block CID: %w", err)
+	}
+
+	return &Block{
+		CID:  c.String(),
+		Data: section[n:],
+	}, nil
+}
+
+func writeSection(w io.Writer, data []byte) error {
+	buf := make([]byte, binary.MaxVarintLen64)
+
+	n := binary.PutUvarint(buf, uint64(len(data)))
+
+	if _, err := w.Write(buf[:n]); err != nil {
+		return err
+	}
+
+	_, err := w.Write(data)
+
+	return err
+}
+
+func readSection(r *bufio.Reader) ([]byte, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+
+		return nil, fmt.Errorf("read section size: %w", err)
+	}
+
+	if size == 0 || size > maxSectionSize {
+		return nil, fmt.Errorf("invalid section size: %d", size)
+	}
+
+	data := make([]byte, size)
+
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, fmt.Errorf("read section: %w", err)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,186 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package car
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/hashlink"
+	"github.com/trustbloc/orb/pkg/multihash"
+)
+
+func TestWriter_Reader(t *testing.T) {
+	content1 := []byte("content1")
+	content2 := []byte("content2")
+
+	cid1 := newCID(t, content1)
+	cid2 := newCID(t, content2)
+
+	buf := &bytes.Buffer{}
+
+	w, err := NewWriter(buf, cid1)
+	require.NoError(t, err)
+
+	require.NoError(t, w.Put(cid1, content1))
+	require.NoError(t, w.Put(cid2, content2))
+	require.NoError(t, w.Put(cid1, content1)) // Duplicate should be ignored.
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, []string{cid1}, r.Roots())
+
+	var blocks []*Block
+
+	for {
+		block, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		require.NoError(t, err)
+
+		blocks = append(blocks, block)
+	}
+
+	require.Len(t, blocks, 2)
+	require.Equal(t, cid1, blocks[0].CID)
+	require.Equal(t, content1, blocks[0].Data)
+	require.Equal(t, cid2, blocks[1].CID)
+	require.Equal(t, content2, blocks[1].Data)
+}
+
+func TestHeader(t *testing.T) {
+	t.Run("CARv1 spec header", func(t *testing.T) {
+		// Header with no roots as produced by other CAR implementations: {"roots": [], "version": 1}.
+		header, err := hex.DecodeString("a265726f6f7473806776657273696f6e01")
+		require.NoError(t, err)
+
+		encoded, err := encodeHeader(nil)
+		require.NoError(t, err)
+		require.Equal(t, header, encoded)
+
+		roots, version, err := decodeHeader(header)
+		require.NoError(t, err)
+		require.Empty(t, roots)
+		require.Equal(t, uint64(1), version)
+	})
+
+	t.Run("Many roots", func(t *testing.T) {
+		var roots [][]byte
+
+		for i := 0; i < 300; i++ {
+			roots = append(roots, []byte{1, 85, 18, 1, byte(i)})
+		}
+
+		header, err := encodeHeader(roots)
+		require.NoError(t, err)
+
+		// Each root is encoded as tag 42 (0xd8 0x2a) followed by a byte string.
+		require.True(t, bytes.Contains(header, []byte{0xd8, 0x2a, 0x46, 0x00, 1, 85, 18, 1, 0}))
+
+		decoded, version, err := decodeHeader(header)
+		require.NoError(t, err)
+		require.Equal(t, roots, decoded)
+		require.Equal(t, uint64(carVersion), version)
+	})
+
+	t.Run("Invalid header", func(t *testing.T) {
+		_, _, err := decodeHeader([]byte{0x01})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal header")
+
+		_, _, err = decodeHeader([]byte{0xa1, 0x63, 'f', 'o', 'o', 0x01})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unknown field")
+
+		_, _, err = decodeHeader([]byte{0xa1, 0x65, 'r', 'o'})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal header")
+
+		header, err := encodeHeader(nil)
+		require.NoError(t, err)
+
+		_, _, err = decodeHeader(append(header, 0x00))
+		require.EqualError(t, err, "unexpected trailing data in header")
+
+		// Root without the CID tag.
+		_, _, err = decodeHeader([]byte{0xa1, 0x65, 'r', 'o', 'o', 't', 's', 0x81, 0x42, 0x00, 0x01})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal header")
+
+		// Root without the multibase identity prefix.
+		_, _, err = decodeHeader([]byte{0xa1, 0x65, 'r', 'o', 'o', 't', 's', 0x81, 0xd8, 0x2a, 0x42, 0x01, 0x01})
+		require.EqualError(t, err, "invalid CID encoding")
+	})
+}
+
+func TestNewWriter_Error(t *testing.T) {
+	_, err := NewWriter(&bytes.Buffer{}, "invalid")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "decode root CID [invalid]")
+
+	w, err := NewWriter(&bytes.Buffer{})
+	require.NoError(t, err)
+
+	err = w.Put("invalid", []byte("content"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "decode CID [invalid]")
+}
+
+func TestNewReader_Error(t *testing.T) {
+	t.Run("Empty archive", func(t *testing.T) {
+		_, err := NewReader(bytes.NewReader(nil))
+		require.EqualError(t, err, "read header: empty archive")
+	})
+
+	t.Run("Unsupported version", func(t *testing.T) {
+		header, err := encodeHeader(nil)
+		require.NoError(t, err)
+
+		header[len(header)-1] = 2
+
+		buf := &bytes.Buffer{}
+		require.NoError(t, writeSection(buf, header))
+
+		_, err = NewReader(buf)
+		require.EqualError(t, err, "unsupported CAR version: 2")
+	})
+
+	t.Run("Truncated block", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		w, err := NewWriter(buf)
+		require.NoError(t, err)
+
+		require.NoError(t, w.Put(newCID(t, []byte("content")), []byte("content")))
+
+		r, err := NewReader(bytes.NewReader(buf.Bytes()[:buf.Len()-1]))
+		require.NoError(t, err)
+
+		_, err = r.Next()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "read section")
+	})
+}
+
+func newCID(t *testing.T, content []byte) string {
+	t.Helper()
+
+	hash, err := hashlink.New().CreateResourceHash(content)
+	require.NoError(t, err)
+
+	cid, err := multihash.ToV1CID(hash)
+	require.NoError(t, err)
+
+	return cid
+}
@@ -0,0 +1,346 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package car
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/compression"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/txnprovider/models"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/anchor/graph"
+	"github.com/trustbloc/orb/pkg/anchor/subject"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	"github.com/trustbloc/orb/pkg/linkset"
+	"github.com/trustbloc/orb/pkg/multihash"
+)
+
+var logger = log.New("cas-car")
+
+const defaultCompressionAlgorithm = "GZIP"
+
+type casReader interface {
+	Read(address string) ([]byte, error)
+}
+
+type anchorLinksetBuilder interface {
+	GetPayloadFromAnchorLink(anchorLink *linkset.Link) (*subject.Payload, error)
+}
+
+type decompressor interface {
+	Decompress(alg string, data []byte) ([]byte, error)
+}
+
+// ExportReport contains the results of an export.
+type ExportReport struct {
+	// Roots contains the CIDs of the anchors from which the export started.
+	Roots []string `json:"roots"`
+	// Anchors is the number of anchor linksets that were exported.
+	Anchors int `json:"anchors"`
+	// Blocks is the total number of blocks (anchor linksets and Sidetree batch files) that were exported.
+	Blocks int `json:"blocks"`
+	// Missing contains the references that couldn't be found in the CAS.
+	Missing []string `json:"missing,omitempty"`
+}
+
+// Exporter exports the anchor graph, along with the Sidetree batch files referenced by each anchor, to a CAR archive.
+type Exporter struct {
+	cas          casReader
+	builder      anchorLinksetBuilder
+	decompressor decompressor
+	compression  string
+}
+
+// ExportOption is an option for the exporter.
+type ExportOption func(e *Exporter)
+
+// WithCompressionAlgorithm sets the algorithm used to decompress Sidetree batch files. The default is GZIP.
+func WithCompressionAlgorithm(alg string) ExportOption {
+	return func(e *Exporter) {
+		e.compression = alg
+	}
+}
+
+// NewExporter returns a new exporter which reads content from the given CAS.
+func NewExporter(cas casReader, anchorLinksetBuilder anchorLinksetBuilder, opts ...ExportOption) *Exporter {
+	e := &Exporter{
+		cas:          cas,
+		builder:      anchorLinksetBuilder,
+		decompressor: compression.New(compression.WithDefaultAlgorithms()),
+		compression:  defaultCompressionAlgorithm,
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+type anchorGraph interface {
+	Read(hl string) (*linkset.Linkset, error)
+}
+
+type exportState struct {
+	writer  *Writer
+	graph   anchorGraph
+	report  *ExportReport
+	visited map[string]struct{}
+}
+
+// Export writes the given anchors, all of the anchors that precede them in the anchor graph, and the core index,
+// core proof, provisional index, provisional proof and chunk files of each anchor to the given writer. The given
+// anchors are the roots of the archive. Content that isn't found is reported as missing; any other error aborts
+// the export.
+func (e *Exporter) Export(w io.Writer, anchors ...string) (*ExportReport, error) {
+	if len(anchors) == 0 {
+		return nil, errors.New("at least one anchor is required")
+	}
+
+	roots := make([]string, len(anchors))
+
+	for i, anchor := range anchors {
+		resourceHash, err := resourceHashFromRef(anchor)
+		if err != nil {
+			return nil, err
+		}
+
+		roots[i], err = multihash.ToV1CID(resourceHash)
+		if err != nil {
+			return nil, fmt.Errorf("convert anchor [%s] to CID: %w", anchor, err)
+		}
+	}
+
+	writer, err := NewWriter(w, roots...)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &exportState{
+		writer:  writer,
+		report:  &ExportReport{Roots: roots},
+		visited: make(map[string]struct{}),
+	}
+
+	// The anchor graph reads each anchor through a resolver which also writes the anchor to the archive.
+	s.graph = graph.New(&graph.Providers{
+		CasResolver:          &exportResolver{exporter: e, state: s},
+		AnchorLinksetBuilder: e.builder,
+	})
+
+	pending := anchors
+
+	for len(pending) > 0 {
+		anchor := pending[0]
+		pending = pending[1:]
+
+		previous, err := e.exportAnchor(anchor, s)
+		if err != nil {
+			return nil, err
+		}
+
+		pending = append(pending, previous...)
+	}
+
+	return s.report, nil
+}
+
+// exportAnchor exports the given anchor and its batch files and returns the previous anchors.
+func (e *Exporter) exportAnchor(anchor string, s *exportState) ([]string, error) {
+	anchorLinkset, err := s.graph.Read(anchor)
+	if err != nil {
+		if errors.Is(err, orberrors.ErrContentNotFound) {
+			// The anchor was either already exported or it's missing.
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("read anchor [%s]: %w", anchor, err)
+	}
+
+	s.report.Anchors++
+
+	logger.Debug("Exporting anchor", log.WithAnchorURIString(anchor))
+
+	anchorLink := anchorLinkset.Link()
+	if anchorLink == nil {
+		return nil, fmt.Errorf("empty anchor linkset [%s]", anchor)
+	}
+
+	if anchorLink.Anchor() != nil {
+		if err := e.exportBatchFiles(anchorLink.Anchor().String(), s); err != nil {
+			return nil, fmt.Errorf("export batch files for anchor [%s]: %w", anchor, err)
+		}
+	}
+
+	payload, err := e.builder.GetPayloadFromAnchorLink(anchorLink)
+	if err != nil {
+		return nil, fmt.Errorf("get payload from anchor [%s]: %w", anchor, err)
+	}
+
+	var previous []string
+
+	for _, prev := range payload.PreviousAnchors {
+		if prev.Anchor != "" {
+			previous = append(previous, prev.Anchor)
+		}
+	}
+
+	return previous, nil
+}
+
+func (e *Exporter) exportBatchFiles(coreIndexURI string, s *exportState) error {
+	content, ok, err := e.exportCompressed(coreIndexURI, s)
+	if err != nil || !ok {
+		return err
+	}
+
+	coreIndexFile, err := models.ParseCoreIndexFile(content)
+	if err != nil {
+		return fmt.Errorf("parse core index file [%s]: %w", coreIndexURI, err)
+	}
+
+	if coreIndexFile.CoreProofFileURI != "" {
+		if _, _, err := e.export(coreIndexFile.CoreProofFileURI, s); err != nil {
+			return err
+		}
+	}
+
+	if coreIndexFile.ProvisionalIndexFileURI == "" {
+		return nil
+	}
+
+	content, ok, err = e.exportCompressed(coreIndexFile.ProvisionalIndexFileURI, s)
+	if err != nil || !ok {
+		return err
+	}
+
+	provisionalIndexFile, err := models.ParseProvisionalIndexFile(content)
+	if err != nil {
+		return fmt.Errorf("parse provisional index file [%s]: %w", coreIndexFile.ProvisionalIndexFileURI, err)
+	}
+
+	if provisionalIndexFile.ProvisionalProofFileURI != "" {
+		if _, _, err := e.export(provisionalIndexFile.ProvisionalProofFileURI, s); err != nil {
+			return err
+		}
+	}
+
+	for _, chunk := range provisionalIndexFile.Chunks {
+		if _, _, err := e.export(chunk.ChunkFileURI, s); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// exportCompressed exports the file at the given URI and returns its decompressed content.
+func (e *Exporter) exportCompressed(uri string, s *exportState) ([]byte, bool, error) {
+	compressed, ok, err := e.export(uri, s)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	content, err := e.decompressor.Decompress(e.compression, compressed)
+	if err != nil {
+		return nil, false, fmt.Errorf("decompress [%s]: %w", uri, err)
+	}
+
+	return content, true, nil
+}
+
+// export reads the content at the given reference from the CAS and writes it to the archive. False is returned
+// if the content was already exported or if it wasn't found.
+func (e *Exporter) export(ref string, s *exportState) ([]byte, bool, error) {
+	resourceHash, err := resourceHashFromRef(ref)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if _, ok := s.visited[resourceHash]; ok {
+		return nil, false, nil
+	}
+
+	s.visited[resourceHash] = struct{}{}
+
+	content, err := e.cas.Read(resourceHash)
+	if err != nil {
+		if errors.Is(err, orberrors.ErrContentNotFound) {
+			logger.Warn("Content not found. It won't be included in the archive.", log.WithHash(resourceHash))
+
+			s.report.Missing = append(s.report.Missing, ref)
+
+			return nil, false, nil
+		}
+
+		return nil, false, fmt.Errorf("read [%s]: %w", ref, err)
+	}
+
+	cid, err := multihash.ToV1CID(resourceHash)
+	if err != nil {
+		return nil, false, fmt.Errorf("convert [%s] to CID: %w", resourceHash, err)
+	}
+
+	if err := s.writer.Put(cid, content); err != nil {
+		return nil, false, err
+	}
+
+	s.report.Blocks++
+
+	return content, true, nil
+}
+
+// exportResolver resolves anchors for the anchor graph. Each resolved anchor is also written to the archive.
+// ErrContentNotFound is returned if the anchor was already exported or if it wasn't found.
+type exportResolver struct {
+	exporter *Exporter
+	state    *exportState
+}
+
+func (r *exportResolver) Resolve(_ *url.URL, hl string, _ []byte) ([]byte, string, error) {
+	content, ok, err := r.exporter.export(hl, r.state)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !ok {
+		return nil, "", orberrors.ErrContentNotFound
+	}
+
+	return content, hl, nil
+}
+
+// resourceHashFromRef returns the resource hash for the given reference, which may be a hashlink,
+// a CID or a resource hash.
+func resourceHashFromRef(ref string) (string, error) {
+	switch {
+	case ref == "":
+		return "", errors.New("empty reference")
+	case strings.HasPrefix(ref, hashlink.HLPrefix):
+		resourceHash, err := hashlink.GetResourceHashFromHashLink(ref)
+		if err != nil || resourceHash == "" {
+			return "", fmt.Errorf("invalid hashlink [%s]", ref)
+		}
+
+		return resourceHash, nil
+	case multihash.IsValidCID(ref):
+		resourceHash, err := multihash.CIDToMultihash(ref)
+		if err != nil {
+			return "", fmt.Errorf("convert CID [%s] to resource hash: %w", ref, err)
+		}
+
+		return resourceHash, nil
+	default:
+		return ref, nil
+	}
+}
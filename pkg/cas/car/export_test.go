@@ -0,0 +1,346 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package car
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/compression"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/txnprovider/models"
+
+	"github.com/trustbloc/orb/pkg/anchor/subject"
+	"github.com/trustbloc/orb/pkg/cas/extendedcasclient"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	"github.com/trustbloc/orb/pkg/linkset"
+	"github.com/trustbloc/orb/pkg/multihash"
+)
+
+func TestExporter_Import(t *testing.T) {
+	cas := newMockCAS()
+	builder := newMockBuilder()
+
+	// anchor1 <- anchor2 <- anchor3
+	anchor1, batch1 := cas.addAnchor(t, "1", builder)
+	anchor2, batch2 := cas.addAnchor(t, "2", builder, anchor1)
+	anchor3, batch3 := cas.addAnchor(t, "3", builder, anchor2, anchor1)
+
+	// Not reachable from anchor3.
+	_, _ = cas.addAnchor(t, "4", builder)
+
+	buf := &bytes.Buffer{}
+
+	report, err := NewExporter(cas, builder).Export(buf, anchor3)
+	require.NoError(t, err)
+	require.Equal(t, []string{mustToV1CID(t, anchor3)}, report.Roots)
+	require.Equal(t, 3, report.Anchors)
+	require.Equal(t, 3+len(batch1)+len(batch2)+len(batch3), report.Blocks)
+	require.Empty(t, report.Missing)
+
+	t.Run("Import", func(t *testing.T) {
+		target := newMockCAS()
+
+		importReport, err := NewImporter(target).Import(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		require.Equal(t, report.Roots, importReport.Roots)
+		require.Equal(t, report.Blocks, importReport.Imported)
+
+		for _, hash := range append(append(batch1, batch2...), batch3...) {
+			require.Equal(t, cas.content[hash], target.content[hash])
+		}
+
+		for _, anchor := range []string{anchor1, anchor2, anchor3} {
+			hash := toHash(t, anchor)
+
+			require.Equal(t, cas.content[hash], target.content[hash])
+		}
+	})
+
+	t.Run("Import -> hash mismatch", func(t *testing.T) {
+		tampered := &bytes.Buffer{}
+
+		w, err := NewWriter(tampered, mustToV1CID(t, anchor3))
+		require.NoError(t, err)
+
+		require.NoError(t, w.Put(mustToV1CID(t, anchor3), []byte("tampered")))
+
+		target := newMockCAS()
+
+		_, err = NewImporter(target).Import(tampered)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "doesn't match its CID")
+		require.Empty(t, target.content)
+	})
+
+	t.Run("Import -> other multihash algorithm", func(t *testing.T) {
+		content := []byte("content")
+
+		rh, err := hashlink.New(hashlink.WithMultihashCode(hashlink.SHA3_256)).CreateResourceHash(content)
+		require.NoError(t, err)
+
+		archive := &bytes.Buffer{}
+
+		w, err := NewWriter(archive, mustToV1CID(t, rh))
+		require.NoError(t, err)
+		require.NoError(t, w.Put(mustToV1CID(t, rh), content))
+
+		target := newMockCAS()
+
+		importReport, err := NewImporter(target).Import(archive)
+		require.NoError(t, err)
+		require.Equal(t, 1, importReport.Imported)
+		require.Equal(t, content, target.content[rh])
+	})
+
+	t.Run("Import -> CAS error", func(t *testing.T) {
+		target := newMockCAS()
+		target.writeErr = errors.New("injected write error")
+
+		_, err = NewImporter(target).Import(bytes.NewReader(buf.Bytes()))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected write error")
+	})
+
+	t.Run("Import -> invalid hashlink from CAS", func(t *testing.T) {
+		target := newMockCAS()
+		target.writeHL = "hl:uEiAWradITyYpRGT3pMhcKfPL8kpJBGePjFjZOlS0zqAUqw"
+
+		_, err = NewImporter(target).Import(bytes.NewReader(buf.Bytes()))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "returned by the CAS")
+	})
+}
+
+func TestExporter_Export(t *testing.T) {
+	t.Run("Missing content", func(t *testing.T) {
+		cas := newMockCAS()
+		builder := newMockBuilder()
+
+		anchor1, batch1 := cas.addAnchor(t, "1", builder)
+		anchor2, _ := cas.addAnchor(t, "2", builder, anchor1)
+
+		// The provisional index file of anchor1 is missing.
+		delete(cas.content, batch1[1])
+
+		report, err := NewExporter(cas, builder).Export(&bytes.Buffer{}, anchor2)
+		require.NoError(t, err)
+		require.Equal(t, 2, report.Anchors)
+		require.Equal(t, []string{hashlink.GetHashLinkFromResourceHash(batch1[1])}, report.Missing)
+	})
+
+	t.Run("No anchors", func(t *testing.T) {
+		_, err := NewExporter(newMockCAS(), newMockBuilder()).Export(&bytes.Buffer{})
+		require.EqualError(t, err, "at least one anchor is required")
+	})
+
+	t.Run("Invalid anchor", func(t *testing.T) {
+		_, err := NewExporter(newMockCAS(), newMockBuilder()).Export(&bytes.Buffer{}, "hl:")
+		require.EqualError(t, err, "invalid hashlink [hl:]")
+	})
+
+	t.Run("Read error", func(t *testing.T) {
+		cas := newMockCAS()
+		builder := newMockBuilder()
+
+		anchor, _ := cas.addAnchor(t, "1", builder)
+
+		cas.readErr = errors.New("injected read error")
+
+		_, err := NewExporter(cas, builder).Export(&bytes.Buffer{}, anchor)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected read error")
+	})
+
+	t.Run("Payload error", func(t *testing.T) {
+		cas := newMockCAS()
+		builder := newMockBuilder()
+
+		anchor, _ := cas.addAnchor(t, "1", builder)
+
+		builder.err = errors.New("injected builder error")
+
+		_, err := NewExporter(cas, builder).Export(&bytes.Buffer{}, anchor)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected builder error")
+	})
+
+	t.Run("Decompress error", func(t *testing.T) {
+		cas := newMockCAS()
+		builder := newMockBuilder()
+
+		anchor, _ := cas.addAnchor(t, "1", builder)
+
+		_, err := NewExporter(cas, builder, WithCompressionAlgorithm("xxx")).Export(&bytes.Buffer{}, anchor)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "decompress")
+	})
+}
+
+type mockCAS struct {
+	content  map[string][]byte
+	readErr  error
+	writeErr error
+	writeHL  string
+	cp       *compression.Registry
+}
+
+func newMockCAS() *mockCAS {
+	return &mockCAS{
+		content: make(map[string][]byte),
+		cp:      compression.New(compression.WithDefaultAlgorithms()),
+	}
+}
+
+func (m *mockCAS) Read(address string) ([]byte, error) {
+	if m.readErr != nil {
+		return nil, m.readErr
+	}
+
+	content, ok := m.content[address]
+	if !ok {
+		return nil, orberrors.ErrContentNotFound
+	}
+
+	return content, nil
+}
+
+func (m *mockCAS) Write(content []byte) (string, error) {
+	return m.WriteWithCIDFormat(content)
+}
+
+func (m *mockCAS) WriteWithCIDFormat(content []byte, opts ...extendedcasclient.CIDFormatOption) (string, error) {
+	if m.writeErr != nil {
+		return "", m.writeErr
+	}
+
+	hash, err := hashlink.New(
+		hashlink.WithMultihashCode(extendedcasclient.MultihashCode(opts...)),
+	).CreateResourceHash(content)
+	if err != nil {
+		return "", err
+	}
+
+	m.content[hash] = content
+
+	if m.writeHL != "" {
+		return m.writeHL, nil
+	}
+
+	return hashlink.GetHashLinkFromResourceHash(hash), nil
+}
+
+func (m *mockCAS) add(t *testing.T, content []byte) string {
+	t.Helper()
+
+	hash, err := hashlink.New().CreateResourceHash(content)
+	require.NoError(t, err)
+
+	m.content[hash] = content
+
+	return hash
+}
+
+func (m *mockCAS) addCompressed(t *testing.T, content []byte) string {
+	t.Helper()
+
+	compressed, err := m.cp.Compress(defaultCompressionAlgorithm, content)
+	require.NoError(t, err)
+
+	return m.add(t, compressed)
+}
+
+// addAnchor adds an anchor linkset along with its batch files and returns the hashlink of the anchor
+// and the hashes of the batch files.
+func (m *mockCAS) addAnchor(t *testing.T, id string, builder *mockBuilder, previous ...string) (string, []string) {
+	t.Helper()
+
+	chunk := m.add(t, []byte("chunk"+id))
+	provisionalProof := m.add(t, []byte("provisionalProof"+id))
+	coreProof := m.add(t, []byte("coreProof"+id))
+
+	provisionalIndex := m.addCompressed(t, mustMarshal(t, &models.ProvisionalIndexFile{
+		ProvisionalProofFileURI: hashlink.GetHashLinkFromResourceHash(provisionalProof),
+		Chunks:                  []models.Chunk{{ChunkFileURI: mustToV1CID(t, chunk)}},
+	}))
+
+	coreIndex := m.addCompressed(t, mustMarshal(t, &models.CoreIndexFile{
+		CoreProofFileURI:        coreProof,
+		ProvisionalIndexFileURI: hashlink.GetHashLinkFromResourceHash(provisionalIndex),
+	}))
+
+	coreIndexURL, err := url.Parse(hashlink.GetHashLinkFromResourceHash(coreIndex))
+	require.NoError(t, err)
+
+	anchorHash := m.add(t, mustMarshal(t, linkset.New(linkset.NewLink(coreIndexURL, nil, nil, nil, nil, nil))))
+
+	payload := &subject.Payload{CoreIndex: coreIndexURL.String()}
+
+	for i, p := range previous {
+		payload.PreviousAnchors = append(payload.PreviousAnchors,
+			&subject.SuffixAnchor{Suffix: fmt.Sprintf("suffix%d", i), Anchor: p})
+	}
+
+	builder.payloads[coreIndexURL.String()] = payload
+
+	return hashlink.GetHashLinkFromResourceHash(anchorHash),
+		[]string{coreIndex, provisionalIndex, coreProof, provisionalProof, chunk}
+}
+
+type mockBuilder struct {
+	payloads map[string]*subject.Payload
+	err      error
+}
+
+func newMockBuilder() *mockBuilder {
+	return &mockBuilder{payloads: make(map[string]*subject.Payload)}
+}
+
+func (m *mockBuilder) GetPayloadFromAnchorLink(anchorLink *linkset.Link) (*subject.Payload, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	payload, ok := m.payloads[anchorLink.Anchor().String()]
+	if !ok {
+		return nil, fmt.Errorf("payload not found for anchor [%s]", anchorLink.Anchor())
+	}
+
+	return payload, nil
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	t.Helper()
+
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	return b
+}
+
+func mustToV1CID(t *testing.T, ref string) string {
+	t.Helper()
+
+	cid, err := multihash.ToV1CID(toHash(t, ref))
+	require.NoError(t, err)
+
+	return cid
+}
+
+func toHash(t *testing.T, ref string) string {
+	t.Helper()
+
+	hash, err := resourceHashFromRef(ref)
+	require.NoError(t, err)
+
+	return hash
+}
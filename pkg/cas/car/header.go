@@ -0,0 +1,118 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package car
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// The CARv1 header is the DAG-CBOR encoding of {"roots": [CID...], "version": 1}.
+
+const (
+	// cborTagCID is the CBOR tag registered for IPLD content identifiers.
+	cborTagCID = 42
+
+	// maxRoots is large enough for an export of every anchor published by a service.
+	maxRoots = 1 << 20
+)
+
+// cborCID is a CID as encoded in DAG-CBOR, i.e. the binary CID prefixed with the multibase identity
+// prefix (0x00).
+type cborCID []byte
+
+type header struct {
+	Roots   []cborCID `cbor:"roots"`
+	Version uint64    `cbor:"version"`
+}
+
+// cidTagSet returns the CBOR tag set which maps tag 42 to a CID.
+func cidTagSet() (cbor.TagSet, error) {
+	tags := cbor.NewTagSet()
+
+	err := tags.Add(cbor.TagOptions{EncTag: cbor.EncTagRequired, DecTag: cbor.DecTagRequired},
+		reflect.TypeOf(cborCID(nil)), cborTagCID)
+	if err != nil {
+		return nil, fmt.Errorf("add CID tag: %w", err)
+	}
+
+	return tags, nil
+}
+
+// encodeHeader encodes the header with the given (binary) root CIDs.
+func encodeHeader(roots [][]byte) ([]byte, error) {
+	h := &header{
+		Roots:   make([]cborCID, len(roots)),
+		Version: carVersion,
+	}
+
+	for i, root := range roots {
+		h.Roots[i] = append(cborCID{0}, root...)
+	}
+
+	tags, err := cidTagSet()
+	if err != nil {
+		return nil, err
+	}
+
+	// DAG-CBOR requires map keys to be sorted length-first.
+	encMode, err := cbor.EncOptions{Sort: cbor.SortLengthFirst}.EncModeWithTags(tags)
+	if err != nil {
+		return nil, fmt.Errorf("create CBOR encoder: %w", err)
+	}
+
+	headerBytes, err := encMode.Marshal(h)
+	if err != nil {
+		return nil, fmt.Errorf("marshal header: %w", err)
+	}
+
+	return headerBytes, nil
+}
+
+// decodeHeader decodes the header and returns the (binary) root CIDs and the version.
+func decodeHeader(data []byte) ([][]byte, uint64, error) {
+	tags, err := cidTagSet()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	decMode, err := cbor.DecOptions{
+		MaxArrayElements:  maxRoots,
+		ExtraReturnErrors: cbor.ExtraDecErrorUnknownField,
+	}.DecModeWithTags(tags)
+	if err != nil {
+		return nil, 0, fmt.Errorf("create CBOR decoder: %w", err)
+	}
+
+	h := &header{}
+
+	dec := decMode.NewDecoder(bytes.NewReader(data))
+
+	if err := dec.Decode(h); err != nil {
+		return nil, 0, fmt.Errorf("unmarshal header: %w", err)
+	}
+
+	if dec.NumBytesRead() != len(data) {
+		return nil, 0, errors.New("unexpected trailing data in header")
+	}
+
+	roots := make([][]byte, len(h.Roots))
+
+	for i, root := range h.Roots {
+		if len(root) < 2 || root[0] != 0 {
+			return nil, 0, errors.New("invalid CID encoding")
+		}
+
+		roots[i] = root[1:]
+	}
+
+	return roots, h.Version, nil
+}
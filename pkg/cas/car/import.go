@@ -0,0 +1,115 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package car
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/cas/extendedcasclient"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	"github.com/trustbloc/orb/pkg/multihash"
+)
+
+type casWriter interface {
+	WriteWithCIDFormat(content []byte, opts ...extendedcasclient.CIDFormatOption) (string, error)
+}
+
+// ImportReport contains the results of an import.
+type ImportReport struct {
+	// Roots contains the root CIDs of the archive.
+	Roots []string `json:"roots"`
+	// Imported is the number of blocks that were written to the CAS.
+	Imported int `json:"imported"`
+}
+
+// Importer imports the blocks of a CAR archive into a CAS.
+type Importer struct {
+	cas casWriter
+	hl  *hashlink.HashLink
+}
+
+// NewImporter returns a new importer which writes content to the given CAS.
+func NewImporter(cas casWriter) *Importer {
+	return &Importer{
+		cas: cas,
+		hl:  hashlink.New(),
+	}
+}
+
+// Import reads the blocks from the given archive and writes them to the CAS. The hash of each block is
+// verified against its CID before the block is written, and the hashlink returned by the CAS is verified
+// after it's written. The import is aborted on the first verification failure.
+func (i *Importer) Import(r io.Reader) (*ImportReport, error) {
+	reader, err := NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{Roots: reader.Roots()}
+
+	for {
+		block, err := reader.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return report, nil
+			}
+
+			return nil, fmt.Errorf("read block: %w", err)
+		}
+
+		if err := i.importBlock(block); err != nil {
+			return nil, err
+		}
+
+		report.Imported++
+	}
+}
+
+func (i *Importer) importBlock(block *Block) error {
+	expectedHash, err := multihash.CIDToMultihash(block.CID)
+	if err != nil {
+		return fmt.Errorf("convert CID [%s] to resource hash: %w", block.CID, err)
+	}
+
+	// The block is verified and written using the hash algorithm of its CID, which may be different from the
+	// default algorithm of the CAS.
+	code, err := i.hl.GetMultihashCode(expectedHash)
+	if err != nil {
+		return fmt.Errorf("get multihash code of block [%s]: %w", block.CID, err)
+	}
+
+	resourceHash, err := hashlink.New(hashlink.WithMultihashCode(code)).CreateResourceHash(block.Data)
+	if err != nil {
+		return fmt.Errorf("create resource hash for block [%s]: %w", block.CID, err)
+	}
+
+	if resourceHash != expectedHash {
+		return fmt.Errorf("hash of block [%s] doesn't match its CID: expecting [%s] but got [%s]",
+			block.CID, expectedHash, resourceHash)
+	}
+
+	hl, err := i.cas.WriteWithCIDFormat(block.Data, extendedcasclient.WithMultihashCode(code))
+	if err != nil {
+		return fmt.Errorf("write block [%s] to CAS: %w", block.CID, err)
+	}
+
+	hlInfo, err := i.hl.ParseHashLink(hl)
+	if err != nil {
+		return fmt.Errorf("parse hashlink [%s] of block [%s]: %w", hl, block.CID, err)
+	}
+
+	if hlInfo.ResourceHash != expectedHash {
+		return fmt.Errorf("hashlink [%s] returned by the CAS for block [%s] doesn't match its CID", hl, block.CID)
+	}
+
+	logger.Debug("Imported block", log.WithCID(block.CID), log.WithHashlink(hl))
+
+	return nil
+}
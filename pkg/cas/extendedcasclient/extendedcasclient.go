@@ -6,7 +6,11 @@ SPDX-License-Identifier: Apache-2.0
 
 package extendedcasclient
 
-import casapi "github.com/trustbloc/sidetree-core-go/pkg/api/cas"
+import (
+	casapi "github.com/trustbloc/sidetree-core-go/pkg/api/cas"
+
+	"github.com/trustbloc/orb/pkg/hashlink"
+)
 
 // CIDFormatOption is an option for specifying the CID format used in a WriteWithCIDFormat call.
 type CIDFormatOption func(opts *CIDFormatOptions)
@@ -14,6 +18,9 @@ type CIDFormatOption func(opts *CIDFormatOptions)
 // CIDFormatOptions represent CID format options for use in a Client.WriteWithCIDFormat call.
 type CIDFormatOptions struct {
 	CIDVersion int
+	// MultihashCode is the multihash code of the hash algorithm used to create the resource hash (and CID) of the
+	// content. If not set (zero) then the default algorithm (SHA2-256) is used.
+	MultihashCode uint
 }
 
 // WithCIDVersion sets the CID version to be used in a WriteWithCIDFormat call.
@@ -24,6 +31,32 @@ func WithCIDVersion(cidVersion int) CIDFormatOption {
 	}
 }
 
+// WithMultihashCode sets the multihash code of the hash algorithm to be used in a WriteWithCIDFormat call.
+// CIDs of content hashed with an algorithm other than SHA2-256 are always V1 CIDs.
+func WithMultihashCode(code uint) CIDFormatOption {
+	return func(opts *CIDFormatOptions) {
+		opts.MultihashCode = code
+	}
+}
+
+// MultihashCode returns the multihash code specified by the given options or, if not specified,
+// the default multihash code (SHA2-256).
+func MultihashCode(opts ...CIDFormatOption) uint {
+	options := &CIDFormatOptions{}
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(options)
+		}
+	}
+
+	if options.MultihashCode == 0 {
+		return hashlink.SHA2_256
+	}
+
+	return options.MultihashCode
+}
+
 // Client represents a CAS client with an additional method that allows the CID format
 // to be specified for a specific write.
 type Client interface {
@@ -31,3 +64,9 @@ type Client interface {
 	WriteWithCIDFormat(content []byte, opts ...CIDFormatOption) (string, error)
 	GetPrimaryWriterType() string
 }
+
+// CIDFormatter is implemented by clients that can return a copy of themselves whose Write method uses the given
+// CID format options (which override the client's default options).
+type CIDFormatter interface {
+	WithCIDFormat(opts ...CIDFormatOption) Client
+}
@@ -0,0 +1,663 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/sidetree-core-go/pkg/compression"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/txnprovider/models"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/anchor/graph"
+	"github.com/trustbloc/orb/pkg/anchor/subject"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	"github.com/trustbloc/orb/pkg/linkset"
+	"github.com/trustbloc/orb/pkg/multihash"
+	"github.com/trustbloc/orb/pkg/store"
+)
+
+var logger = log.New("cas-gc")
+
+const (
+	taskID = "cas-gc"
+
+	pinNamespace  = "cas-pin"
+	pinTagName    = "pinned"
+	ipfsPrefix    = "ipfs://"
+	hlPartsMinLen = 2
+
+	defaultGracePeriod          = 24 * time.Hour
+	defaultCompressionAlgorithm = "GZIP"
+
+	// maxReportedGarbage is the maximum number of garbage addresses that are included in a report.
+	maxReportedGarbage = 1000
+)
+
+type casStore interface {
+	Read(address string) ([]byte, error)
+	GetKeys(createdBefore time.Time, handle func(keys []string) error) error
+	Delete(addresses ...string) error
+}
+
+type referenceProvider interface {
+	GetReferences() ([]string, error)
+}
+
+type archiveProvider interface {
+	GetArchiveLocations() ([]string, error)
+}
+
+type anchorLinksetBuilder interface {
+	GetPayloadFromAnchorLink(anchorLink *linkset.Link) (*subject.Payload, error)
+}
+
+type anchorGraph interface {
+	Read(hl string) (*linkset.Linkset, error)
+}
+
+type taskManager interface {
+	RegisterTask(taskType string, interval time.Duration, task func())
+}
+
+type decompressor interface {
+	Decompress(alg string, data []byte) ([]byte, error)
+}
+
+// Report contains the results of a garbage collection run.
+type Report struct {
+	DryRun    bool          `json:"dryRun"`
+	StartTime time.Time     `json:"startTime"`
+	Duration  time.Duration `json:"duration"`
+	// Scanned is the number of CAS entries that were considered for collection.
+	Scanned int `json:"scanned"`
+	// Reachable is the number of CAS entries that are reachable from the anchor graph and operation store.
+	Reachable int `json:"reachable"`
+	// Pinned is the number of CAS entries that are explicitly pinned.
+	Pinned int `json:"pinned"`
+	// Archived is the number of CAS entries that are archives (e.g. of log entries).
+	Archived int `json:"archived"`
+	// Unreachable is the number of unreachable, unpinned CAS entries.
+	Unreachable int `json:"unreachable"`
+	// Garbage contains the addresses of (at most 1000) unreachable, unpinned CAS entries.
+	Garbage []string `json:"garbage,omitempty"`
+	// Deleted is the number of CAS entries that were deleted. This is always zero for a dry run.
+	Deleted int `json:"deleted"`
+}
+
+// Pin contains information about pinned CAS content.
+type Pin struct {
+	CID      string    `json:"cid"`
+	Reason   string    `json:"reason,omitempty"`
+	PinnedAt time.Time `json:"pinnedAt"`
+}
+
+// Collector deletes content from the local CAS that is neither reachable from the anchor graph (starting at
+// the anchors referenced by the operation store) nor explicitly pinned. Content that was written within the
+// grace period is never collected, since it may belong to a batch that is still being processed.
+type Collector struct {
+	cas          casStore
+	refProvider  referenceProvider
+	archives     archiveProvider
+	graph        anchorGraph
+	builder      anchorLinksetBuilder
+	pinStore     storage.Store
+	decompressor decompressor
+	compression  string
+	gracePeriod  time.Duration
+	dryRun       bool
+
+	mutex      sync.RWMutex
+	lastReport *Report
+}
+
+// Option is a garbage collector option.
+type Option func(c *Collector)
+
+// WithGracePeriod sets the minimum age of CAS content before it may be collected.
+func WithGracePeriod(period time.Duration) Option {
+	return func(c *Collector) {
+		c.gracePeriod = period
+	}
+}
+
+// WithDryRun indicates that the scheduled task should only report garbage and not delete it.
+func WithDryRun(dryRun bool) Option {
+	return func(c *Collector) {
+		c.dryRun = dryRun
+	}
+}
+
+// WithArchives sets the provider of the locations of archives (e.g. of compacted log entries) that were written
+// to the CAS. Archives are never collected.
+func WithArchives(provider archiveProvider) Option {
+	return func(c *Collector) {
+		c.archives = provider
+	}
+}
+
+// WithCompressionAlgorithm sets the compression algorithm of the Sidetree batch files. The default is GZIP.
+func WithCompressionAlgorithm(alg string) Option {
+	return func(c *Collector) {
+		c.compression = alg
+	}
+}
+
+// New returns a new garbage collector for the given local CAS. A task is registered with the task manager which
+// runs the collector at the given interval.
+func New(provider storage.Provider, cas casStore, refProvider referenceProvider,
+	anchorLinksetBuilder anchorLinksetBuilder, taskMgr taskManager, interval time.Duration,
+	opts ...Option) (*Collector, error) {
+	pinStore, err := store.Open(provider, pinNamespace, store.NewTagGroup(pinTagName))
+	if err != nil {
+		return nil, fmt.Errorf("open pin store: %w", err)
+	}
+
+	c := &Collector{
+		cas:          cas,
+		refProvider:  refProvider,
+		builder:      anchorLinksetBuilder,
+		pinStore:     pinStore,
+		decompressor: compression.New(compression.WithDefaultAlgorithms()),
+		compression:  defaultCompressionAlgorithm,
+		gracePeriod:  defaultGracePeriod,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	// Anchors are read only from the local CAS so that the collector never fetches content from remote servers.
+	c.graph = graph.New(&graph.Providers{
+		CasResolver:          &localResolver{cas: cas},
+		AnchorLinksetBuilder: anchorLinksetBuilder,
+	})
+
+	if taskMgr != nil {
+		taskMgr.RegisterTask(taskID, interval, c.collect)
+	}
+
+	return c, nil
+}
+
+// Run runs the garbage collector. If dryRun is true then unreachable content is reported but not deleted.
+func (c *Collector) Run(dryRun bool) (*Report, error) {
+	report := &Report{
+		DryRun:    dryRun,
+		StartTime: time.Now(),
+	}
+
+	reachable, err := c.mark()
+	if err != nil {
+		return nil, fmt.Errorf("mark reachable content: %w", err)
+	}
+
+	pinned, err := c.getPinnedSet()
+	if err != nil {
+		return nil, fmt.Errorf("get pinned content: %w", err)
+	}
+
+	archived, err := c.getArchivedSet()
+	if err != nil {
+		return nil, fmt.Errorf("get archived content: %w", err)
+	}
+
+	// Only content that was written before the run started (less the grace period) is considered, so content
+	// written while marking is never collected.
+	err = c.cas.GetKeys(report.StartTime.Add(-c.gracePeriod), func(keys []string) error {
+		garbage := c.sweep(keys, reachable, pinned, archived, report)
+
+		if dryRun || len(garbage) == 0 {
+			return nil
+		}
+
+		if err := c.cas.Delete(garbage...); err != nil {
+			return fmt.Errorf("delete unreachable content: %w", err)
+		}
+
+		report.Deleted += len(garbage)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("collect CAS keys: %w", err)
+	}
+
+	report.Duration = time.Since(report.StartTime)
+
+	return report, nil
+}
+
+// sweep updates the report with the given keys and returns the keys that are garbage.
+func (c *Collector) sweep(keys []string, reachable, pinned, archived map[string]struct{}, report *Report) []string {
+	var garbage []string
+
+	for _, key := range keys {
+		report.Scanned++
+
+		if _, ok := pinned[key]; ok {
+			report.Pinned++
+
+			continue
+		}
+
+		if _, ok := archived[key]; ok {
+			report.Archived++
+
+			continue
+		}
+
+		if _, ok := reachable[key]; ok {
+			report.Reachable++
+
+			continue
+		}
+
+		report.Unreachable++
+
+		if len(report.Garbage) < maxReportedGarbage {
+			report.Garbage = append(report.Garbage, key)
+		}
+
+		garbage = append(garbage, key)
+	}
+
+	return garbage
+}
+
+// Pin pins the content at the given address so that it is never collected.
+func (c *Collector) Pin(address, reason string) error {
+	resourceHash, err := toResourceHash(address)
+	if err != nil {
+		return err
+	}
+
+	pinBytes, err := json.Marshal(&Pin{CID: resourceHash, Reason: reason, PinnedAt: time.Now()})
+	if err != nil {
+		return fmt.Errorf("marshal pin: %w", err)
+	}
+
+	if err := c.pinStore.Put(resourceHash, pinBytes, storage.Tag{Name: pinTagName}); err != nil {
+		return orberrors.NewTransient(fmt.Errorf("store pin: %w", err))
+	}
+
+	logger.Info("Pinned CAS content", log.WithHash(resourceHash))
+
+	return nil
+}
+
+// Unpin removes the pin for the content at the given address.
+func (c *Collector) Unpin(address string) error {
+	resourceHash, err := toResourceHash(address)
+	if err != nil {
+		return err
+	}
+
+	if _, err := c.pinStore.Get(resourceHash); err != nil {
+		if errors.Is(err, storage.ErrDataNotFound) {
+			return orberrors.ErrContentNotFound
+		}
+
+		return orberrors.NewTransient(fmt.Errorf("get pin: %w", err))
+	}
+
+	if err := c.pinStore.Delete(resourceHash); err != nil {
+		return orberrors.NewTransient(fmt.Errorf("delete pin: %w", err))
+	}
+
+	logger.Info("Unpinned CAS content", log.WithHash(resourceHash))
+
+	return nil
+}
+
+// GetPins returns all pinned content.
+func (c *Collector) GetPins() ([]*Pin, error) {
+	iterator, err := c.pinStore.Query(pinTagName)
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("query pins: %w", err))
+	}
+
+	defer func() {
+		if errClose := iterator.Close(); errClose != nil {
+			log.CloseIteratorError(logger, errClose)
+		}
+	}()
+
+	var pins []*Pin
+
+	for {
+		ok, err := iterator.Next()
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("iterator next: %w", err))
+		}
+
+		if !ok {
+			break
+		}
+
+		value, err := iterator.Value()
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("iterator value: %w", err))
+		}
+
+		pin := &Pin{}
+
+		if err := json.Unmarshal(value, pin); err != nil {
+			return nil, fmt.Errorf("unmarshal pin: %w", err)
+		}
+
+		pins = append(pins, pin)
+	}
+
+	return pins, nil
+}
+
+// LastReport returns the report of the last scheduled run of the garbage collector, or nil if the
+// collector hasn't run yet.
+func (c *Collector) LastReport() *Report {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.lastReport
+}
+
+func (c *Collector) collect() {
+	report, err := c.Run(c.dryRun)
+	if err != nil {
+		logger.Error("Error running CAS garbage collection", log.WithError(err))
+
+		return
+	}
+
+	c.mutex.Lock()
+	c.lastReport = report
+	c.mutex.Unlock()
+
+	if report.DryRun {
+		logger.Info("CAS garbage collection dry run completed. Unreachable content was not deleted.",
+			log.WithTotal(report.Unreachable))
+
+		return
+	}
+
+	logger.Info("CAS garbage collection completed", log.WithTotal(report.Deleted))
+}
+
+func (c *Collector) getArchivedSet() (map[string]struct{}, error) {
+	archived := make(map[string]struct{})
+
+	if c.archives == nil {
+		return archived, nil
+	}
+
+	locations, err := c.archives.GetArchiveLocations()
+	if err != nil {
+		return nil, fmt.Errorf("get archive locations: %w", err)
+	}
+
+	for _, location := range locations {
+		resourceHash, err := toResourceHash(location)
+		if err != nil {
+			// The archive may not have been written to the CAS (e.g. it's a file).
+			logger.Debug("Ignoring archive location", log.WithURIString(location), log.WithError(err))
+
+			continue
+		}
+
+		archived[resourceHash] = struct{}{}
+	}
+
+	return archived, nil
+}
+
+func (c *Collector) getPinnedSet() (map[string]struct{}, error) {
+	pins, err := c.GetPins()
+	if err != nil {
+		return nil, err
+	}
+
+	pinned := make(map[string]struct{}, len(pins))
+
+	for _, pin := range pins {
+		pinned[pin.CID] = struct{}{}
+	}
+
+	return pinned, nil
+}
+
+// mark returns the set of resource hashes that are reachable from the anchors referenced by the operation store.
+// Any error (other than content not found) aborts the run so that reachable content is never collected.
+func (c *Collector) mark() (map[string]struct{}, error) {
+	refs, err := c.refProvider.GetReferences()
+	if err != nil {
+		return nil, fmt.Errorf("get anchor references: %w", err)
+	}
+
+	reachable := make(map[string]struct{})
+
+	pending := refs
+
+	for len(pending) > 0 {
+		ref := pending[0]
+		pending = pending[1:]
+
+		if strings.HasPrefix(ref, ipfsPrefix) {
+			// Content that is only referenced in IPFS doesn't need to be traversed since the canonical
+			// reference of the same anchor is also processed.
+			continue
+		}
+
+		resourceHash, err := toResourceHash(ref)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := reachable[resourceHash]; ok {
+			continue
+		}
+
+		reachable[resourceHash] = struct{}{}
+
+		previous, err := c.markAnchor(resourceHash, reachable)
+		if err != nil {
+			return nil, err
+		}
+
+		pending = append(pending, previous...)
+	}
+
+	return reachable, nil
+}
+
+// markAnchor marks the batch files referenced by the given anchor and returns the previous anchors.
+func (c *Collector) markAnchor(anchorHash string, reachable map[string]struct{}) ([]string, error) {
+	anchorLinkset, err := c.graph.Read(anchorHash)
+	if err != nil {
+		if errors.Is(err, orberrors.ErrContentNotFound) {
+			logger.Debug("Anchor not found in local CAS", log.WithHash(anchorHash))
+
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("read anchor [%s]: %w", anchorHash, err)
+	}
+
+	anchorLink := anchorLinkset.Link()
+	if anchorLink == nil {
+		return nil, fmt.Errorf("empty anchor linkset [%s]", anchorHash)
+	}
+
+	if anchorLink.Anchor() != nil {
+		if err := c.markBatchFiles(anchorLink.Anchor().String(), reachable); err != nil {
+			return nil, fmt.Errorf("mark batch files for anchor [%s]: %w", anchorHash, err)
+		}
+	}
+
+	payload, err := c.builder.GetPayloadFromAnchorLink(anchorLink)
+	if err != nil {
+		return nil, fmt.Errorf("get payload from anchor [%s]: %w", anchorHash, err)
+	}
+
+	var previous []string
+
+	for _, prev := range payload.PreviousAnchors {
+		if prev.Anchor != "" {
+			previous = append(previous, prev.Anchor)
+		}
+	}
+
+	return previous, nil
+}
+
+func (c *Collector) markBatchFiles(coreIndexURI string, reachable map[string]struct{}) error {
+	content, ok, err := c.readFile(coreIndexURI, reachable)
+	if err != nil || !ok {
+		return err
+	}
+
+	coreIndexFile, err := models.ParseCoreIndexFile(content)
+	if err != nil {
+		return fmt.Errorf("parse core index file [%s]: %w", coreIndexURI, err)
+	}
+
+	if coreIndexFile.CoreProofFileURI != "" {
+		if err := c.markURI(coreIndexFile.CoreProofFileURI, reachable); err != nil {
+			return err
+		}
+	}
+
+	if coreIndexFile.ProvisionalIndexFileURI == "" {
+		return nil
+	}
+
+	content, ok, err = c.readFile(coreIndexFile.ProvisionalIndexFileURI, reachable)
+	if err != nil || !ok {
+		return err
+	}
+
+	provisionalIndexFile, err := models.ParseProvisionalIndexFile(content)
+	if err != nil {
+		return fmt.Errorf("parse provisional index file [%s]: %w", coreIndexFile.ProvisionalIndexFileURI, err)
+	}
+
+	if provisionalIndexFile.ProvisionalProofFileURI != "" {
+		if err := c.markURI(provisionalIndexFile.ProvisionalProofFileURI, reachable); err != nil {
+			return err
+		}
+	}
+
+	for _, chunk := range provisionalIndexFile.Chunks {
+		if err := c.markURI(chunk.ChunkFileURI, reachable); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Collector) markURI(uri string, reachable map[string]struct{}) error {
+	resourceHash, err := toResourceHash(uri)
+	if err != nil {
+		return err
+	}
+
+	reachable[resourceHash] = struct{}{}
+
+	return nil
+}
+
+// readFile marks the file at the given URI as reachable and returns its decompressed content. False is returned
+// if the file doesn't exist in the local CAS.
+func (c *Collector) readFile(uri string, reachable map[string]struct{}) ([]byte, bool, error) {
+	resourceHash, err := toResourceHash(uri)
+	if err != nil {
+		return nil, false, err
+	}
+
+	reachable[resourceHash] = struct{}{}
+
+	compressed, err := c.cas.Read(resourceHash)
+	if err != nil {
+		if errors.Is(err, orberrors.ErrContentNotFound) {
+			logger.Debug("Batch file not found in local CAS", log.WithHash(resourceHash))
+
+			return nil, false, nil
+		}
+
+		return nil, false, fmt.Errorf("read [%s]: %w", uri, err)
+	}
+
+	content, err := c.decompressor.Decompress(c.compression, compressed)
+	if err != nil {
+		return nil, false, fmt.Errorf("decompress [%s]: %w", uri, err)
+	}
+
+	return content, true, nil
+}
+
+// toResourceHash returns the resource hash (multihash) for the given reference, which may be a hashlink,
+// a CID, a resource hash with a domain hint or a plain resource hash.
+func toResourceHash(ref string) (string, error) {
+	switch {
+	case strings.HasPrefix(ref, hashlink.HLPrefix):
+		parts := strings.Split(ref, ":")
+		if len(parts) < hlPartsMinLen || parts[1] == "" {
+			return "", fmt.Errorf("invalid hashlink [%s]", ref)
+		}
+
+		return parts[1], nil
+	case strings.HasPrefix(ref, ipfsPrefix):
+		return cidToResourceHash(strings.TrimPrefix(ref, ipfsPrefix))
+	case strings.Contains(ref, ":"):
+		// Resource hash with a domain hint, e.g. https:orb.domain.com:uEiD...
+		parts := strings.Split(ref, ":")
+
+		return parts[len(parts)-1], nil
+	case multihash.IsValidCID(ref):
+		return cidToResourceHash(ref)
+	case ref == "":
+		return "", errors.New("empty reference")
+	default:
+		return ref, nil
+	}
+}
+
+func cidToResourceHash(cid string) (string, error) {
+	resourceHash, err := multihash.CIDToMultihash(cid)
+	if err != nil {
+		return "", fmt.Errorf("convert CID [%s] to resource hash: %w", cid, err)
+	}
+
+	return resourceHash, nil
+}
+
+// localResolver resolves anchors from the local CAS only.
+type localResolver struct {
+	cas casStore
+}
+
+func (r *localResolver) Resolve(_ *url.URL, hl string, _ []byte) ([]byte, string, error) {
+	resourceHash, err := toResourceHash(hl)
+	if err != nil {
+		return nil, "", err
+	}
+
+	content, err := r.cas.Read(resourceHash)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return content, "", nil
+}
@@ -0,0 +1,540 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/compression"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/txnprovider/models"
+
+	"github.com/trustbloc/orb/pkg/activitypub/service/mocks"
+	"github.com/trustbloc/orb/pkg/anchor/subject"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	"github.com/trustbloc/orb/pkg/linkset"
+	"github.com/trustbloc/orb/pkg/multihash"
+)
+
+func TestCollector_Run(t *testing.T) {
+	cas := newMockCAS()
+	builder := newMockBuilder()
+
+	// anchor1 <- anchor2 (anchor2 references anchor1 as a previous anchor).
+	anchor1, batch1 := cas.addAnchor(t, "1", builder)
+	anchor2, batch2 := cas.addAnchor(t, "2", builder, anchor1)
+
+	// anchor3 isn't referenced by any operation.
+	_, batch3 := cas.addAnchor(t, "3", builder)
+
+	orphan := cas.add(t, []byte("orphan"))
+	pinned := cas.add(t, []byte("pinned"))
+
+	refs := &mockRefProvider{refs: []string{anchor2, "ipfs://" + mustToV1CID(t, anchor2)}}
+
+	c, err := New(mem.NewProvider(), cas, refs, builder, nil, 0, WithGracePeriod(0))
+	require.NoError(t, err)
+
+	require.NoError(t, c.Pin(hashlink.GetHashLinkFromResourceHash(pinned), "retain for audit"))
+
+	t.Run("dry run", func(t *testing.T) {
+		report, err := c.Run(true)
+		require.NoError(t, err)
+		require.True(t, report.DryRun)
+		require.Equal(t, len(cas.content), report.Scanned)
+		require.Equal(t, 1, report.Pinned)
+		require.Equal(t, 2+len(batch1)+len(batch2), report.Reachable)
+		require.ElementsMatch(t, append([]string{toHash(t, anchorFor(cas, batch3)), orphan},
+			batch3...), report.Garbage)
+		require.Zero(t, report.Deleted)
+
+		// Nothing should have been deleted.
+		_, err = cas.Read(orphan)
+		require.NoError(t, err)
+	})
+
+	t.Run("collect", func(t *testing.T) {
+		report, err := c.Run(false)
+		require.NoError(t, err)
+		require.False(t, report.DryRun)
+		require.Equal(t, len(report.Garbage), report.Deleted)
+
+		_, err = cas.Read(orphan)
+		require.True(t, errors.Is(err, orberrors.ErrContentNotFound))
+
+		for _, hash := range append(batch1, batch2...) {
+			_, err = cas.Read(hash)
+			require.NoError(t, err)
+		}
+
+		_, err = cas.Read(pinned)
+		require.NoError(t, err)
+
+		report, err = c.Run(false)
+		require.NoError(t, err)
+		require.Empty(t, report.Garbage)
+	})
+
+	t.Run("unpin", func(t *testing.T) {
+		require.NoError(t, c.Unpin(pinned))
+
+		err := c.Unpin(pinned)
+		require.True(t, errors.Is(err, orberrors.ErrContentNotFound))
+
+		report, err := c.Run(false)
+		require.NoError(t, err)
+		require.Equal(t, []string{pinned}, report.Garbage)
+	})
+}
+
+func TestCollector_Pages(t *testing.T) {
+	cas := newMockCAS()
+	cas.pageSize = 2
+
+	builder := newMockBuilder()
+
+	anchor, batch := cas.addAnchor(t, "1", builder)
+
+	for i := 0; i < 5; i++ {
+		cas.add(t, []byte(fmt.Sprintf("orphan%d", i)))
+	}
+
+	c, err := New(mem.NewProvider(), cas, &mockRefProvider{refs: []string{anchor}}, builder, nil, 0,
+		WithGracePeriod(0))
+	require.NoError(t, err)
+
+	report, err := c.Run(false)
+	require.NoError(t, err)
+	require.Equal(t, 10, report.Scanned)
+	require.Equal(t, 1+len(batch), report.Reachable)
+	require.Equal(t, 5, report.Unreachable)
+	require.Equal(t, 5, report.Deleted)
+	require.Len(t, cas.content, 1+len(batch))
+}
+
+func TestCollector_Archives(t *testing.T) {
+	cas := newMockCAS()
+
+	archive := cas.add(t, []byte("archive"))
+	orphan := cas.add(t, []byte("orphan"))
+
+	archives := &mockArchiveProvider{
+		locations: []string{hashlink.GetHashLinkFromResourceHash(archive) + ":metadata", "/archives/file.json"},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		c, err := New(mem.NewProvider(), cas, &mockRefProvider{}, newMockBuilder(), nil, 0,
+			WithGracePeriod(0), WithArchives(archives))
+		require.NoError(t, err)
+
+		report, err := c.Run(true)
+		require.NoError(t, err)
+		require.Equal(t, 1, report.Archived)
+		require.Equal(t, []string{orphan}, report.Garbage)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		c, err := New(mem.NewProvider(), cas, &mockRefProvider{}, newMockBuilder(), nil, 0,
+			WithGracePeriod(0), WithArchives(&mockArchiveProvider{err: errors.New("injected archives error")}))
+		require.NoError(t, err)
+
+		_, err = c.Run(true)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected archives error")
+	})
+}
+
+func TestCollector_GracePeriod(t *testing.T) {
+	cas := newMockCAS()
+
+	cas.add(t, []byte("orphan"))
+
+	c, err := New(mem.NewProvider(), cas, &mockRefProvider{}, newMockBuilder(), nil, 0)
+	require.NoError(t, err)
+
+	report, err := c.Run(false)
+	require.NoError(t, err)
+	require.Zero(t, report.Scanned)
+	require.Empty(t, report.Garbage)
+}
+
+func TestCollector_Errors(t *testing.T) {
+	t.Run("get keys error", func(t *testing.T) {
+		cas := newMockCAS()
+		cas.keysErr = errors.New("injected keys error")
+
+		c, err := New(mem.NewProvider(), cas, &mockRefProvider{}, newMockBuilder(), nil, 0)
+		require.NoError(t, err)
+
+		_, err = c.Run(true)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected keys error")
+	})
+
+	t.Run("get references error", func(t *testing.T) {
+		cas := newMockCAS()
+		cas.add(t, []byte("content"))
+
+		c, err := New(mem.NewProvider(), cas, &mockRefProvider{err: errors.New("injected refs error")},
+			newMockBuilder(), nil, 0, WithGracePeriod(0))
+		require.NoError(t, err)
+
+		_, err = c.Run(true)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected refs error")
+	})
+
+	t.Run("read anchor error -> nothing deleted", func(t *testing.T) {
+		cas := newMockCAS()
+		builder := newMockBuilder()
+
+		anchor, _ := cas.addAnchor(t, "1", builder)
+
+		cas.readErr = errors.New("injected read error")
+
+		c, err := New(mem.NewProvider(), cas, &mockRefProvider{refs: []string{anchor}}, builder, nil, 0,
+			WithGracePeriod(0))
+		require.NoError(t, err)
+
+		_, err = c.Run(false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected read error")
+		require.Len(t, cas.content, 5)
+	})
+
+	t.Run("decompress error", func(t *testing.T) {
+		cas := newMockCAS()
+		builder := newMockBuilder()
+
+		anchor, _ := cas.addAnchor(t, "1", builder)
+
+		c, err := New(mem.NewProvider(), cas, &mockRefProvider{refs: []string{anchor}}, builder, nil, 0,
+			WithGracePeriod(0), WithCompressionAlgorithm("invalid"))
+		require.NoError(t, err)
+
+		_, err = c.Run(false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "decompress")
+	})
+
+	t.Run("payload error", func(t *testing.T) {
+		cas := newMockCAS()
+		builder := newMockBuilder()
+
+		anchor, _ := cas.addAnchor(t, "1", builder)
+
+		builder.err = errors.New("injected payload error")
+
+		c, err := New(mem.NewProvider(), cas, &mockRefProvider{refs: []string{anchor}}, builder, nil, 0,
+			WithGracePeriod(0))
+		require.NoError(t, err)
+
+		_, err = c.Run(false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected payload error")
+	})
+
+	t.Run("delete error", func(t *testing.T) {
+		cas := newMockCAS()
+		cas.add(t, []byte("orphan"))
+		cas.deleteErr = errors.New("injected delete error")
+
+		c, err := New(mem.NewProvider(), cas, &mockRefProvider{}, newMockBuilder(), nil, 0, WithGracePeriod(0))
+		require.NoError(t, err)
+
+		_, err = c.Run(false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected delete error")
+	})
+}
+
+func TestCollector_Task(t *testing.T) {
+	taskMgr := mocks.NewTaskManager("gc").WithInterval(10 * time.Millisecond)
+
+	taskMgr.Start()
+	defer taskMgr.Stop()
+
+	cas := newMockCAS()
+	cas.add(t, []byte("orphan"))
+
+	c, err := New(mem.NewProvider(), cas, &mockRefProvider{}, newMockBuilder(), taskMgr, 10*time.Millisecond,
+		WithGracePeriod(0), WithDryRun(true))
+	require.NoError(t, err)
+	require.Nil(t, c.LastReport())
+
+	time.Sleep(100 * time.Millisecond)
+
+	// Dry run so nothing should be deleted.
+	require.Len(t, cas.content, 1)
+
+	report := c.LastReport()
+	require.NotNil(t, report)
+	require.True(t, report.DryRun)
+	require.Len(t, report.Garbage, 1)
+}
+
+func TestCollector_GetPins(t *testing.T) {
+	c, err := New(mem.NewProvider(), newMockCAS(), &mockRefProvider{}, newMockBuilder(), nil, 0)
+	require.NoError(t, err)
+
+	pins, err := c.GetPins()
+	require.NoError(t, err)
+	require.Empty(t, pins)
+
+	require.NoError(t, c.Pin("uEiCCQWmOzsPbjQAH1kcHTSVNhWbnX8FLNkgRjsFJD0xOFg", "reason1"))
+	require.NoError(t, c.Pin("https:orb.domain.com:uEiDat0G2KJ59zMHtQjMMrhrMwrdVzoB5ws1dS1Nmyfdppg", ""))
+
+	pins, err = c.GetPins()
+	require.NoError(t, err)
+	require.Len(t, pins, 2)
+
+	err = c.Pin("", "")
+	require.EqualError(t, err, "empty reference")
+}
+
+func TestToResourceHash(t *testing.T) {
+	const hash = "uEiCCQWmOzsPbjQAH1kcHTSVNhWbnX8FLNkgRjsFJD0xOFg"
+
+	for _, ref := range []string{
+		hash,
+		"hl:" + hash,
+		"hl:" + hash + ":metadata",
+		"https:orb.domain.com:" + hash,
+		"ipfs://" + mustToV1CID(t, hash),
+		mustToV1CID(t, hash),
+	} {
+		h, err := toResourceHash(ref)
+		require.NoError(t, err, ref)
+		require.Equal(t, hash, h, ref)
+	}
+
+	_, err := toResourceHash("hl:")
+	require.Error(t, err)
+
+	_, err = toResourceHash("ipfs://xxx")
+	require.Error(t, err)
+}
+
+type mockCAS struct {
+	content   map[string][]byte
+	created   map[string]time.Time
+	anchors   map[string]string
+	pageSize  int
+	readErr   error
+	keysErr   error
+	deleteErr error
+	cp        *compression.Registry
+}
+
+func newMockCAS() *mockCAS {
+	return &mockCAS{
+		content: make(map[string][]byte),
+		created: make(map[string]time.Time),
+		anchors: make(map[string]string),
+		cp:      compression.New(compression.WithDefaultAlgorithms()),
+	}
+}
+
+func (m *mockCAS) Read(address string) ([]byte, error) {
+	if m.readErr != nil {
+		return nil, m.readErr
+	}
+
+	content, ok := m.content[address]
+	if !ok {
+		return nil, orberrors.ErrContentNotFound
+	}
+
+	return content, nil
+}
+
+func (m *mockCAS) GetKeys(createdBefore time.Time, handle func(keys []string) error) error {
+	if m.keysErr != nil {
+		return m.keysErr
+	}
+
+	var keys []string
+
+	for key := range m.content {
+		if m.created[key].Before(createdBefore) {
+			keys = append(keys, key)
+		}
+	}
+
+	pageSize := m.pageSize
+	if pageSize == 0 {
+		pageSize = len(keys)
+	}
+
+	for len(keys) > 0 {
+		n := pageSize
+		if n > len(keys) {
+			n = len(keys)
+		}
+
+		if err := handle(keys[:n]); err != nil {
+			return err
+		}
+
+		keys = keys[n:]
+	}
+
+	return nil
+}
+
+func (m *mockCAS) Delete(addresses ...string) error {
+	if m.deleteErr != nil {
+		return m.deleteErr
+	}
+
+	for _, address := range addresses {
+		delete(m.content, address)
+	}
+
+	return nil
+}
+
+func (m *mockCAS) add(t *testing.T, content []byte) string {
+	t.Helper()
+
+	hash, err := hashlink.New().CreateResourceHash(content)
+	require.NoError(t, err)
+
+	m.content[hash] = content
+	m.created[hash] = time.Now()
+
+	return hash
+}
+
+func (m *mockCAS) addCompressed(t *testing.T, content []byte) string {
+	t.Helper()
+
+	compressed, err := m.cp.Compress(defaultCompressionAlgorithm, content)
+	require.NoError(t, err)
+
+	return m.add(t, compressed)
+}
+
+// addAnchor adds an anchor linkset along with its batch files and returns the hashlink of the anchor
+// and the hashes of the batch files.
+func (m *mockCAS) addAnchor(t *testing.T, id string, builder *mockBuilder, previous ...string) (string, []string) {
+	t.Helper()
+
+	chunk := m.add(t, []byte("chunk"+id))
+	provisionalProof := m.add(t, []byte("provisionalProof"+id))
+
+	provisionalIndex := m.addCompressed(t, mustMarshal(t, &models.ProvisionalIndexFile{
+		ProvisionalProofFileURI: provisionalProof,
+		Chunks:                  []models.Chunk{{ChunkFileURI: chunk}},
+	}))
+
+	coreIndex := m.addCompressed(t, mustMarshal(t, &models.CoreIndexFile{
+		ProvisionalIndexFileURI: provisionalIndex,
+	}))
+
+	coreIndexURL, err := url.Parse(hashlink.GetHashLinkFromResourceHash(coreIndex))
+	require.NoError(t, err)
+
+	ls := linkset.New(linkset.NewLink(coreIndexURL, nil, nil, nil, nil, nil))
+
+	anchorHash := m.add(t, mustMarshal(t, ls))
+	anchorHL := hashlink.GetHashLinkFromResourceHash(anchorHash)
+
+	m.anchors[coreIndex] = anchorHash
+
+	payload := &subject.Payload{CoreIndex: coreIndexURL.String()}
+
+	for _, p := range previous {
+		payload.PreviousAnchors = append(payload.PreviousAnchors, &subject.SuffixAnchor{Suffix: "suffix", Anchor: p})
+	}
+
+	builder.payloads[coreIndexURL.String()] = payload
+
+	return anchorHL, []string{coreIndex, provisionalIndex, provisionalProof, chunk}
+}
+
+func anchorFor(m *mockCAS, batch []string) string {
+	return m.anchors[batch[0]]
+}
+
+type mockBuilder struct {
+	payloads map[string]*subject.Payload
+	err      error
+}
+
+func newMockBuilder() *mockBuilder {
+	return &mockBuilder{payloads: make(map[string]*subject.Payload)}
+}
+
+func (m *mockBuilder) GetPayloadFromAnchorLink(anchorLink *linkset.Link) (*subject.Payload, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	payload, ok := m.payloads[anchorLink.Anchor().String()]
+	if !ok {
+		return nil, fmt.Errorf("payload not found for anchor [%s]", anchorLink.Anchor())
+	}
+
+	return payload, nil
+}
+
+type mockRefProvider struct {
+	refs []string
+	err  error
+}
+
+func (m *mockRefProvider) GetReferences() ([]string, error) {
+	return m.refs, m.err
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	t.Helper()
+
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	return b
+}
+
+func mustToV1CID(t *testing.T, hash string) string {
+	t.Helper()
+
+	if h, err := toResourceHash(hash); err == nil {
+		hash = h
+	}
+
+	cid, err := multihash.ToV1CID(hash)
+	require.NoError(t, err)
+
+	return cid
+}
+
+func toHash(t *testing.T, ref string) string {
+	t.Helper()
+
+	hash, err := toResourceHash(ref)
+	require.NoError(t, err)
+
+	return hash
+}
+
+type mockArchiveProvider struct {
+	locations []string
+	err       error
+}
+
+func (m *mockArchiveProvider) GetArchiveLocations() ([]string, error) {
+	return m.locations, m.err
+}
@@ -0,0 +1,290 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/cas/gc"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+)
+
+const (
+	pinEndpoint = "/cas-pin"
+	gcEndpoint  = "/cas-gc"
+)
+
+const (
+	badRequestResponse          = "Bad Request."
+	notFoundResponse            = "Not Found"
+	internalServerErrorResponse = "Internal Server Error."
+)
+
+const loggerModule = "cas-gc-rest-handler"
+
+type collector interface {
+	LastReport() *gc.Report
+	Pin(address, reason string) error
+	Unpin(address string) error
+	GetPins() ([]*gc.Pin, error)
+}
+
+// PinHandler pins and unpins CAS content.
+type PinHandler struct {
+	collector collector
+	logger    *log.Log
+	unmarshal func([]byte, interface{}) error
+}
+
+// NewPinHandler returns a new PinHandler.
+func NewPinHandler(collector collector) *PinHandler {
+	return &PinHandler{
+		collector: collector,
+		logger:    log.New(loggerModule, log.WithFields(log.WithServiceEndpoint(pinEndpoint))),
+		unmarshal: json.Unmarshal,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the pin handler.
+func (h *PinHandler) Path() string {
+	return pinEndpoint
+}
+
+// Method returns the HTTP REST method for the pin handler.
+func (h *PinHandler) Method() string {
+	return http.MethodPost
+}
+
+// Handler returns the HTTP REST handle for the pin handler.
+func (h *PinHandler) Handler() common.HTTPRequestHandler {
+	return h.handle
+}
+
+func (h *PinHandler) handle(w http.ResponseWriter, req *http.Request) {
+	reqBytes, err := io.ReadAll(req.Body)
+	if err != nil {
+		h.logger.Error("Error reading request body", log.WithError(err))
+
+		writeResponse(h.logger, w, http.StatusBadRequest, []byte(badRequestResponse))
+
+		return
+	}
+
+	h.logger.Debug("Got request to pin/unpin CAS content", log.WithRequestBody(reqBytes))
+
+	request := &pinRequest{}
+
+	if err := h.unmarshalAndValidate(reqBytes, request); err != nil {
+		h.logger.Info("Invalid pin/unpin request", log.WithError(err))
+
+		writeResponse(h.logger, w, http.StatusBadRequest, []byte(badRequestResponse))
+
+		return
+	}
+
+	for _, cid := range request.Pin {
+		if err := h.collector.Pin(cid, request.Reason); err != nil {
+			h.handleError(w, "Error pinning CAS content", err)
+
+			return
+		}
+	}
+
+	for _, cid := range request.Unpin {
+		if err := h.collector.Unpin(cid); err != nil {
+			if errors.Is(err, orberrors.ErrContentNotFound) {
+				h.logger.Debug("CAS content is not pinned", log.WithHash(cid))
+
+				continue
+			}
+
+			h.handleError(w, "Error unpinning CAS content", err)
+
+			return
+		}
+	}
+
+	writeResponse(h.logger, w, http.StatusOK, nil)
+}
+
+func (h *PinHandler) unmarshalAndValidate(reqBytes []byte, request *pinRequest) error {
+	if err := h.unmarshal(reqBytes, request); err != nil {
+		return fmt.Errorf("unmarshal request: %w", err)
+	}
+
+	return request.validate()
+}
+
+func (h *PinHandler) handleError(w http.ResponseWriter, msg string, err error) {
+	if orberrors.IsTransient(err) {
+		h.logger.Error(msg, log.WithError(err))
+
+		writeResponse(h.logger, w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	h.logger.Info(msg, log.WithError(err))
+
+	writeResponse(h.logger, w, http.StatusBadRequest, []byte(badRequestResponse))
+}
+
+// PinRetriever returns all pinned CAS content.
+type PinRetriever struct {
+	collector collector
+	logger    *log.Log
+	marshal   func(interface{}) ([]byte, error)
+}
+
+// NewPinRetriever returns a new PinRetriever.
+func NewPinRetriever(collector collector) *PinRetriever {
+	return &PinRetriever{
+		collector: collector,
+		logger:    log.New(loggerModule, log.WithFields(log.WithServiceEndpoint(pinEndpoint))),
+		marshal:   json.Marshal,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the pin retriever.
+func (h *PinRetriever) Path() string {
+	return pinEndpoint
+}
+
+// Method returns the HTTP REST method for the pin retriever.
+func (h *PinRetriever) Method() string {
+	return http.MethodGet
+}
+
+// Handler returns the HTTP REST handle for the pin retriever.
+func (h *PinRetriever) Handler() common.HTTPRequestHandler {
+	return h.handle
+}
+
+func (h *PinRetriever) handle(w http.ResponseWriter, _ *http.Request) {
+	pins, err := h.collector.GetPins()
+	if err != nil {
+		h.logger.Error("Error retrieving pins", log.WithError(err))
+
+		writeResponse(h.logger, w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	if len(pins) == 0 {
+		writeResponse(h.logger, w, http.StatusNotFound, []byte(notFoundResponse))
+
+		return
+	}
+
+	h.writeJSON(w, pins)
+}
+
+func (h *PinRetriever) writeJSON(w http.ResponseWriter, v interface{}) {
+	respBytes, err := h.marshal(v)
+	if err != nil {
+		h.logger.Error("Marshal response error", log.WithError(err))
+
+		writeResponse(h.logger, w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	writeResponse(h.logger, w, http.StatusOK, respBytes)
+}
+
+// ReportHandler returns the report of the last scheduled run of the CAS garbage collector. (The collector is
+// not run on request since marking the entire anchor graph is expensive.)
+type ReportHandler struct {
+	collector collector
+	logger    *log.Log
+	marshal   func(interface{}) ([]byte, error)
+}
+
+// NewReportHandler returns a new ReportHandler.
+func NewReportHandler(collector collector) *ReportHandler {
+	return &ReportHandler{
+		collector: collector,
+		logger:    log.New(loggerModule, log.WithFields(log.WithServiceEndpoint(gcEndpoint))),
+		marshal:   json.Marshal,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the garbage collection report.
+func (h *ReportHandler) Path() string {
+	return gcEndpoint
+}
+
+// Method returns the HTTP REST method for the garbage collection report.
+func (h *ReportHandler) Method() string {
+	return http.MethodGet
+}
+
+// Handler returns the HTTP REST handle for the garbage collection report.
+func (h *ReportHandler) Handler() common.HTTPRequestHandler {
+	return h.handle
+}
+
+func (h *ReportHandler) handle(w http.ResponseWriter, _ *http.Request) {
+	report := h.collector.LastReport()
+	if report == nil {
+		h.logger.Debug("CAS garbage collector hasn't run yet")
+
+		writeResponse(h.logger, w, http.StatusNotFound, []byte(notFoundResponse))
+
+		return
+	}
+
+	respBytes, err := h.marshal(report)
+	if err != nil {
+		h.logger.Error("Marshal report error", log.WithError(err))
+
+		writeResponse(h.logger, w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	writeResponse(h.logger, w, http.StatusOK, respBytes)
+}
+
+type pinRequest struct {
+	Pin    []string `json:"pin"`
+	Unpin  []string `json:"unpin"`
+	Reason string   `json:"reason,omitempty"`
+}
+
+func (r *pinRequest) validate() error {
+	if len(r.Pin) == 0 && len(r.Unpin) == 0 {
+		return fmt.Errorf("no CIDs to pin or unpin")
+	}
+
+	return nil
+}
+
+func writeResponse(logger *log.Log, w http.ResponseWriter, status int, body []byte) {
+	if len(body) > 0 {
+		w.Header().Set("Content-Type", "text/plain")
+	}
+
+	w.WriteHeader(status)
+
+	if len(body) > 0 {
+		if _, err := w.Write(body); err != nil {
+			log.WriteResponseBodyError(logger, err)
+
+			return
+		}
+
+		log.WroteResponse(logger, body)
+	}
+}
@@ -0,0 +1,281 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/cas/gc"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+)
+
+const (
+	cid1 = "uEiCCQWmOzsPbjQAH1kcHTSVNhWbnX8FLNkgRjsFJD0xOFg"
+	cid2 = "uEiDuIicNljP8PoHJk6_aA7w1d4U3FAvDMfF7Dsh7fkw3Wg"
+
+	pinPayload   = `{"pin": ["` + cid1 + `"], "reason": "audit"}`
+	unpinPayload = `{"unpin": ["` + cid1 + `", "` + cid2 + `"]}`
+)
+
+func TestPinHandler(t *testing.T) {
+	t.Run("new", func(t *testing.T) {
+		handler := NewPinHandler(&mockCollector{})
+		require.Equal(t, pinEndpoint, handler.Path())
+		require.Equal(t, http.MethodPost, handler.Method())
+		require.NotNil(t, handler.Handler())
+	})
+
+	t.Run("pin -> success", func(t *testing.T) {
+		c := newMockCollector()
+
+		status, body := doRequest(t, NewPinHandler(c).handle, http.MethodPost, bytes.NewBufferString(pinPayload))
+		require.Equal(t, http.StatusOK, status)
+		require.Empty(t, body)
+		require.Contains(t, c.pins, cid1)
+		require.Equal(t, "audit", c.pins[cid1].Reason)
+	})
+
+	t.Run("unpin -> success", func(t *testing.T) {
+		c := newMockCollector()
+		c.pins[cid1] = &gc.Pin{CID: cid1}
+
+		// cid2 isn't pinned, which should be ignored.
+		status, body := doRequest(t, NewPinHandler(c).handle, http.MethodPost, bytes.NewBufferString(unpinPayload))
+		require.Equal(t, http.StatusOK, status)
+		require.Empty(t, body)
+		require.Empty(t, c.pins)
+	})
+
+	t.Run("reader error", func(t *testing.T) {
+		status, body := doRequest(t, NewPinHandler(newMockCollector()).handle, http.MethodPost, errReader(0))
+		require.Equal(t, http.StatusBadRequest, status)
+		require.Equal(t, badRequestResponse, body)
+	})
+
+	t.Run("unmarshal error", func(t *testing.T) {
+		handler := NewPinHandler(newMockCollector())
+		handler.unmarshal = func([]byte, interface{}) error { return errors.New("injected unmarshal error") }
+
+		status, body := doRequest(t, handler.handle, http.MethodPost, bytes.NewBufferString(pinPayload))
+		require.Equal(t, http.StatusBadRequest, status)
+		require.Equal(t, badRequestResponse, body)
+	})
+
+	t.Run("nothing to pin or unpin", func(t *testing.T) {
+		status, body := doRequest(t, NewPinHandler(newMockCollector()).handle, http.MethodPost,
+			bytes.NewBufferString(`{}`))
+		require.Equal(t, http.StatusBadRequest, status)
+		require.Equal(t, badRequestResponse, body)
+	})
+
+	t.Run("pin -> invalid CID", func(t *testing.T) {
+		c := newMockCollector()
+		c.pinErr = errors.New("invalid CID")
+
+		status, body := doRequest(t, NewPinHandler(c).handle, http.MethodPost, bytes.NewBufferString(pinPayload))
+		require.Equal(t, http.StatusBadRequest, status)
+		require.Equal(t, badRequestResponse, body)
+	})
+
+	t.Run("pin -> transient error", func(t *testing.T) {
+		c := newMockCollector()
+		c.pinErr = orberrors.NewTransient(errors.New("injected store error"))
+
+		status, body := doRequest(t, NewPinHandler(c).handle, http.MethodPost, bytes.NewBufferString(pinPayload))
+		require.Equal(t, http.StatusInternalServerError, status)
+		require.Equal(t, internalServerErrorResponse, body)
+	})
+
+	t.Run("unpin -> transient error", func(t *testing.T) {
+		c := newMockCollector()
+		c.unpinErr = orberrors.NewTransient(errors.New("injected store error"))
+
+		status, body := doRequest(t, NewPinHandler(c).handle, http.MethodPost, bytes.NewBufferString(unpinPayload))
+		require.Equal(t, http.StatusInternalServerError, status)
+		require.Equal(t, internalServerErrorResponse, body)
+	})
+}
+
+func TestPinRetriever(t *testing.T) {
+	t.Run("new", func(t *testing.T) {
+		handler := NewPinRetriever(&mockCollector{})
+		require.Equal(t, pinEndpoint, handler.Path())
+		require.Equal(t, http.MethodGet, handler.Method())
+		require.NotNil(t, handler.Handler())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		c := newMockCollector()
+		c.pins[cid1] = &gc.Pin{CID: cid1, Reason: "audit", PinnedAt: time.Now()}
+
+		status, body := doRequest(t, NewPinRetriever(c).handle, http.MethodGet, nil)
+		require.Equal(t, http.StatusOK, status)
+
+		var pins []*gc.Pin
+		require.NoError(t, json.Unmarshal([]byte(body), &pins))
+		require.Len(t, pins, 1)
+		require.Equal(t, cid1, pins[0].CID)
+		require.Equal(t, "audit", pins[0].Reason)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		status, body := doRequest(t, NewPinRetriever(newMockCollector()).handle, http.MethodGet, nil)
+		require.Equal(t, http.StatusNotFound, status)
+		require.Equal(t, notFoundResponse, body)
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		c := newMockCollector()
+		c.getPinsErr = errors.New("injected store error")
+
+		status, body := doRequest(t, NewPinRetriever(c).handle, http.MethodGet, nil)
+		require.Equal(t, http.StatusInternalServerError, status)
+		require.Equal(t, internalServerErrorResponse, body)
+	})
+
+	t.Run("marshal error", func(t *testing.T) {
+		c := newMockCollector()
+		c.pins[cid1] = &gc.Pin{CID: cid1}
+
+		handler := NewPinRetriever(c)
+		handler.marshal = func(interface{}) ([]byte, error) { return nil, errors.New("injected marshal error") }
+
+		status, body := doRequest(t, handler.handle, http.MethodGet, nil)
+		require.Equal(t, http.StatusInternalServerError, status)
+		require.Equal(t, internalServerErrorResponse, body)
+	})
+}
+
+func TestReportHandler(t *testing.T) {
+	t.Run("new", func(t *testing.T) {
+		handler := NewReportHandler(&mockCollector{})
+		require.Equal(t, gcEndpoint, handler.Path())
+		require.Equal(t, http.MethodGet, handler.Method())
+		require.NotNil(t, handler.Handler())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		c := newMockCollector()
+		c.report = &gc.Report{DryRun: true, Scanned: 3, Reachable: 1, Pinned: 1, Garbage: []string{cid2}}
+
+		status, body := doRequest(t, NewReportHandler(c).handle, http.MethodGet, nil)
+		require.Equal(t, http.StatusOK, status)
+
+		report := &gc.Report{}
+		require.NoError(t, json.Unmarshal([]byte(body), report))
+		require.True(t, report.DryRun)
+		require.Equal(t, 3, report.Scanned)
+		require.Equal(t, []string{cid2}, report.Garbage)
+	})
+
+	t.Run("not run yet", func(t *testing.T) {
+		c := newMockCollector()
+		c.report = nil
+
+		status, body := doRequest(t, NewReportHandler(c).handle, http.MethodGet, nil)
+		require.Equal(t, http.StatusNotFound, status)
+		require.Equal(t, notFoundResponse, body)
+	})
+
+	t.Run("marshal error", func(t *testing.T) {
+		handler := NewReportHandler(newMockCollector())
+		handler.marshal = func(interface{}) ([]byte, error) { return nil, errors.New("injected marshal error") }
+
+		status, body := doRequest(t, handler.handle, http.MethodGet, nil)
+		require.Equal(t, http.StatusInternalServerError, status)
+		require.Equal(t, internalServerErrorResponse, body)
+	})
+}
+
+func doRequest(t *testing.T, handle http.HandlerFunc, method string, body io.Reader) (int, string) {
+	t.Helper()
+
+	rw := httptest.NewRecorder()
+
+	handle(rw, httptest.NewRequest(method, pinEndpoint, body))
+
+	result := rw.Result()
+
+	respBytes, err := io.ReadAll(result.Body)
+	require.NoError(t, err)
+	require.NoError(t, result.Body.Close())
+
+	return result.StatusCode, string(respBytes)
+}
+
+type mockCollector struct {
+	pins       map[string]*gc.Pin
+	report     *gc.Report
+	pinErr     error
+	unpinErr   error
+	getPinsErr error
+}
+
+func newMockCollector() *mockCollector {
+	return &mockCollector{
+		pins:   make(map[string]*gc.Pin),
+		report: &gc.Report{},
+	}
+}
+
+func (m *mockCollector) LastReport() *gc.Report {
+	return m.report
+}
+
+func (m *mockCollector) Pin(address, reason string) error {
+	if m.pinErr != nil {
+		return m.pinErr
+	}
+
+	m.pins[address] = &gc.Pin{CID: address, Reason: reason, PinnedAt: time.Now()}
+
+	return nil
+}
+
+func (m *mockCollector) Unpin(address string) error {
+	if m.unpinErr != nil {
+		return m.unpinErr
+	}
+
+	if _, ok := m.pins[address]; !ok {
+		return orberrors.ErrContentNotFound
+	}
+
+	delete(m.pins, address)
+
+	return nil
+}
+
+func (m *mockCollector) GetPins() ([]*gc.Pin, error) {
+	if m.getPinsErr != nil {
+		return nil, m.getPinsErr
+	}
+
+	var pins []*gc.Pin
+
+	for _, p := range m.pins {
+		pins = append(pins, p)
+	}
+
+	return pins, nil
+}
+
+type errReader int
+
+func (errReader) Read([]byte) (n int, err error) {
+	return 0, fmt.Errorf("injected reader error")
+}
@@ -16,6 +16,7 @@ import (
 
 	"github.com/bluele/gcache"
 	shell "github.com/ipfs/go-ipfs-api"
+	mh "github.com/multiformats/go-multihash"
 
 	"github.com/trustbloc/orb/internal/pkg/log"
 	"github.com/trustbloc/orb/pkg/cas/extendedcasclient"
@@ -90,6 +91,11 @@ func newClient(ipfs ipfsClient, cacheSize int, metrics metricsProvider,
 // Write writes the given content to IPFS.
 // Returns the address (CID) of the content.
 func (m *Client) Write(content []byte) (string, error) {
+	options, err := getOptions(m.opts)
+	if err != nil {
+		return "", err
+	}
+
 	cid, err := m.WriteWithCIDFormat(content, m.opts...)
 	if err != nil {
 		return "", err
@@ -97,7 +103,7 @@ func (m *Client) Write(content []byte) (string, error) {
 
 	links := []string{"ipfs://" + cid}
 
-	hl, err := m.hl.CreateHashLink(content, links)
+	hl, err := hashlink.New(hashlink.WithMultihashCode(options.MultihashCode)).CreateHashLink(content, links)
 	if err != nil {
 		return "", fmt.Errorf("failed to create hashlink for ipfs: %w", err)
 	}
@@ -107,6 +113,15 @@ func (m *Client) Write(content []byte) (string, error) {
 	return hl, nil
 }
 
+// WithCIDFormat returns a copy of this client whose Write method uses the given CID format options. The copy
+// shares the underlying IPFS shell and cache with this client.
+func (m *Client) WithCIDFormat(opts ...extendedcasclient.CIDFormatOption) extendedcasclient.Client {
+	c := *m
+	c.opts = append(append([]extendedcasclient.CIDFormatOption{}, m.opts...), opts...)
+
+	return &c
+}
+
 // WriteWithCIDFormat writes the given content to IPFS using the provided CID format options.
 // Returns the address (CID) of the content.
 // TODO (#443): Support v1 CID formats (different multibases and multicodecs) other than just the IPFS default.
@@ -120,13 +135,17 @@ func (m *Client) WriteWithCIDFormat(content []byte, opts ...extendedcasclient.CI
 		return "", err
 	}
 
-	var v1AddOpt []shell.AddOpts
+	var addOpts []shell.AddOpts
 
 	if options.CIDVersion == 1 {
-		v1AddOpt = []shell.AddOpts{shell.CidVersion(1)}
+		addOpts = append(addOpts, shell.CidVersion(1))
+	}
+
+	if options.MultihashCode != hashlink.SHA2_256 {
+		addOpts = append(addOpts, shell.Hash(mh.Codes[uint64(options.MultihashCode)]))
 	}
 
-	cid, err := m.ipfs.Add(bytes.NewReader(content), v1AddOpt...)
+	cid, err := m.ipfs.Add(bytes.NewReader(content), addOpts...)
 	if err != nil {
 		if strings.Contains(err.Error(), "command not found") {
 			return "", fmt.Errorf("%w. (Does this IPFS node support writes?)", err)
@@ -235,9 +254,17 @@ func (m *Client) getCIDFromHash(hash string) (string, error) {
 		return "", err
 	}
 
+	cidVersion := options.CIDVersion
+
+	// V0 CIDs support only SHA2-256 so a hash that was created using a different algorithm (which may be
+	// different from the algorithm that this client uses to write content) is always converted to a V1 CID.
+	if code, e := m.hl.GetMultihashCode(hash); e == nil && code != hashlink.SHA2_256 {
+		cidVersion = 1
+	}
+
 	var cid string
 
-	switch options.CIDVersion {
+	switch cidVersion {
 	case 0:
 		cid, err = multihash.ToV0CID(hash)
 		if err != nil {
@@ -249,7 +276,7 @@ func (m *Client) getCIDFromHash(hash string) (string, error) {
 			return "", fmt.Errorf("value[%s] cannot be converted to V1 CID: %w", hash, err)
 		}
 	default:
-		return "", fmt.Errorf("cid version[%d] not supported", options.CIDVersion)
+		return "", fmt.Errorf("cid version[%d] not supported", cidVersion)
 	}
 
 	return cid, nil
@@ -257,7 +284,7 @@ func (m *Client) getCIDFromHash(hash string) (string, error) {
 
 func getOptions(opts []extendedcasclient.CIDFormatOption) (
 	extendedcasclient.CIDFormatOptions, error) {
-	options := extendedcasclient.CIDFormatOptions{CIDVersion: 1}
+	options := extendedcasclient.CIDFormatOptions{CIDVersion: 1, MultihashCode: hashlink.SHA2_256}
 
 	for _, option := range opts {
 		if option != nil {
@@ -270,6 +297,20 @@ func getOptions(opts []extendedcasclient.CIDFormatOption) (
 			fmt.Errorf("%d is not a supported CID version. It must be either 0 or 1", options.CIDVersion)
 	}
 
+	if options.MultihashCode == 0 {
+		options.MultihashCode = hashlink.SHA2_256
+	}
+
+	if !hashlink.IsSupportedMultihashCode(options.MultihashCode) {
+		return extendedcasclient.CIDFormatOptions{},
+			fmt.Errorf("multihash code [%d] is not supported", options.MultihashCode)
+	}
+
+	if options.MultihashCode != hashlink.SHA2_256 {
+		// V0 CIDs support only SHA2-256.
+		options.CIDVersion = 1
+	}
+
 	return options, nil
 }
 
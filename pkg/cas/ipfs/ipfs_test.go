@@ -25,7 +25,9 @@ import (
 	"github.com/trustbloc/orb/pkg/cas/extendedcasclient"
 	"github.com/trustbloc/orb/pkg/cas/ipfs/mocks"
 	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/hashlink"
 	orbmocks "github.com/trustbloc/orb/pkg/mocks"
+	"github.com/trustbloc/orb/pkg/multihash"
 )
 
 //go:generate counterfeiter -o ./mocks/ipfsclient.gen.go --fake-name IPFSClient . ipfsClient
@@ -158,6 +160,40 @@ func TestWrite(t *testing.T) {
 		require.EqualError(t, err, "2 is not a supported CID version. It must be either 0 or 1")
 	})
 
+	t.Run("invalid multihash code", func(t *testing.T) {
+		cas := New("IPFS URL", 20*time.Second, 0, &orbmocks.MetricsProvider{},
+			extendedcasclient.WithMultihashCode(55))
+		require.NotNil(t, cas)
+
+		cid, err := cas.Write([]byte("content"))
+		require.Empty(t, cid)
+		require.EqualError(t, err, "multihash code [55] is not supported")
+	})
+
+	t.Run("other multihash algorithm", func(t *testing.T) {
+		ipfs := &mocks.IPFSClient{}
+		ipfs.AddReturns("bafkreihnoabliopjvscf6irvpwbcxlauirzq7pnwafwt5skdekl3t3e7om", nil)
+
+		cas := newClient(ipfs, 0, &orbmocks.MetricsProvider{},
+			extendedcasclient.WithCIDVersion(0), extendedcasclient.WithMultihashCode(hashlink.SHA3_256))
+		require.NotNil(t, cas)
+
+		hl, err := cas.Write([]byte("content"))
+		require.NoError(t, err)
+
+		// Both the CID version (forced to V1) and the hash algorithm should be passed to IPFS.
+		require.Equal(t, 1, ipfs.AddCallCount())
+		_, addOpts := ipfs.AddArgsForCall(0)
+		require.Len(t, addOpts, 2)
+
+		hlInfo, err := hashlink.New().ParseHashLink(hl)
+		require.NoError(t, err)
+
+		code, err := hashlink.New().GetMultihashCode(hlInfo.ResourceHash)
+		require.NoError(t, err)
+		require.Equal(t, hashlink.SHA3_256, code)
+	})
+
 	t.Run("empty content", func(t *testing.T) {
 		cas := New("IPFS URL", 20*time.Second, 0, &orbmocks.MetricsProvider{})
 		require.NotNil(t, cas)
@@ -220,6 +256,26 @@ func TestRead(t *testing.T) {
 		require.NotNil(t, read)
 	})
 
+	t.Run("success - other multihash algorithm", func(t *testing.T) {
+		ipfs := &mocks.IPFSClient{}
+		ipfs.CatReturns(newMockReader([]byte("content")), nil)
+
+		cas := newClient(ipfs, 0, &orbmocks.MetricsProvider{}, extendedcasclient.WithCIDVersion(0))
+		require.NotNil(t, cas)
+
+		rh, err := hashlink.New(hashlink.WithMultihashCode(hashlink.BLAKE2b_256)).CreateResourceHash([]byte("content"))
+		require.NoError(t, err)
+
+		read, err := cas.Read(rh)
+		require.NoError(t, err)
+		require.Equal(t, "content", string(read))
+
+		// A V1 CID should be used even though the client is configured for V0 CIDs.
+		expectedCID, err := multihash.ToV1CID(rh)
+		require.NoError(t, err)
+		require.Equal(t, expectedCID, ipfs.CatArgsForCall(0))
+	})
+
 	t.Run("error - internal server error", func(t *testing.T) {
 		ipfs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusInternalServerError)
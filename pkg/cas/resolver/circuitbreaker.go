@@ -0,0 +1,99 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resolver
+
+import (
+	"sync"
+	"time"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+)
+
+// circuitBreaker keeps track of consecutive failures per WebCAS endpoint (host). After failureThreshold
+// consecutive failures the circuit for the endpoint is opened and requests to the endpoint are skipped
+// for openDuration. After openDuration elapses, requests are allowed again; a single success closes the circuit
+// and another failure immediately re-opens it. A nil circuit breaker allows all requests.
+type circuitBreaker struct {
+	failureThreshold int
+	openDuration     time.Duration
+	now              func() time.Time
+
+	mutex     sync.Mutex
+	endpoints map[string]*endpointState
+}
+
+type endpointState struct {
+	failures  int
+	openUntil time.Time
+}
+
+func newCircuitBreaker(failureThreshold int, openDuration time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		failureThreshold: failureThreshold,
+		openDuration:     openDuration,
+		now:              time.Now,
+		endpoints:        make(map[string]*endpointState),
+	}
+}
+
+// allow returns false if the circuit for the given endpoint is open.
+func (cb *circuitBreaker) allow(endpoint string) bool {
+	if cb == nil {
+		return true
+	}
+
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	state, ok := cb.endpoints[endpoint]
+	if !ok {
+		return true
+	}
+
+	return !cb.now().Before(state.openUntil)
+}
+
+// success closes the circuit for the given endpoint.
+func (cb *circuitBreaker) success(endpoint string) {
+	if cb == nil {
+		return
+	}
+
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	if _, ok := cb.endpoints[endpoint]; ok {
+		logger.Debug("Closing circuit for WebCAS endpoint", log.WithDomain(endpoint))
+
+		delete(cb.endpoints, endpoint)
+	}
+}
+
+// failure records a failure for the given endpoint and opens the circuit if the failure threshold is reached.
+func (cb *circuitBreaker) failure(endpoint string) {
+	if cb == nil {
+		return
+	}
+
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	state, ok := cb.endpoints[endpoint]
+	if !ok {
+		state = &endpointState{}
+		cb.endpoints[endpoint] = state
+	}
+
+	state.failures++
+
+	if state.failures >= cb.failureThreshold {
+		state.openUntil = cb.now().Add(cb.openDuration)
+
+		logger.Warn("Opening circuit for WebCAS endpoint due to consecutive failures", log.WithDomain(endpoint),
+			log.WithTotal(state.failures), log.WithDuration(cb.openDuration))
+	}
+}
@@ -8,9 +8,12 @@ package resolver
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"mime"
+	"mime/multipart"
 	"net/http"
 	"net/url"
 	"strings"
@@ -22,6 +25,7 @@ import (
 	orberrors "github.com/trustbloc/orb/pkg/errors"
 	"github.com/trustbloc/orb/pkg/hashlink"
 	"github.com/trustbloc/orb/pkg/multihash"
+	"github.com/trustbloc/orb/pkg/webcas"
 	webfingerclient "github.com/trustbloc/orb/pkg/webfinger/client"
 )
 
@@ -30,6 +34,9 @@ const (
 	httpsPrefix = "https://"
 	ipfsPrefix  = "ipfs://"
 
+	contentTypeHeader = "Content-Type"
+	jsonContentType   = "application/json"
+
 	cidWithPossibleHintNumPartsWithDomainPort = 4
 )
 
@@ -39,10 +46,12 @@ var logger = log.New(logModule)
 
 type httpClient interface {
 	Get(ctx context.Context, req *transport.Request) (*http.Response, error)
+	Post(ctx context.Context, req *transport.Request, payload []byte) (*http.Response, error)
 }
 
 type metricsProvider interface {
 	CASResolveTime(value time.Duration)
+	CASResolveSourceTime(source string, value time.Duration)
 }
 
 // Resolver represents a resolver that can resolve data in a CAS based on a CID (with possible hint) and a WebCAS URL.
@@ -52,6 +61,35 @@ type Resolver struct {
 	webCASResolver WebCASResolver
 	metrics        metricsProvider
 	hl             *hashlink.HashLink
+	hedged         bool
+	hedgeDelay     time.Duration
+	circuitBreaker *circuitBreaker
+}
+
+// Option is a resolver option.
+type Option func(r *Resolver)
+
+// WithHedgedResolution enables hedged resolution. When content isn't found in the local CAS, all candidate sources
+// (WebCAS links and IPFS links from the hashlink metadata, and the domain hint) are queried in parallel instead of
+// sequentially: the first source is requested and, if it hasn't responded within hedgeDelay, the next source is
+// requested, and so on. The first response whose hash is verified wins and all other requests are cancelled.
+// If hedgeDelay is zero then all sources are requested at once.
+func WithHedgedResolution(hedgeDelay time.Duration) Option {
+	return func(r *Resolver) {
+		r.hedged = true
+		r.hedgeDelay = hedgeDelay
+	}
+}
+
+// WithCircuitBreaker enables a circuit breaker for WebCAS endpoints. After failureThreshold consecutive
+// transient failures, requests to an endpoint are skipped for openDuration. The circuit breaker is disabled
+// if failureThreshold is zero.
+func WithCircuitBreaker(failureThreshold int, openDuration time.Duration) Option {
+	return func(r *Resolver) {
+		if failureThreshold > 0 {
+			r.circuitBreaker = newCircuitBreaker(failureThreshold, openDuration)
+		}
+	}
 }
 
 type ipfsReader interface {
@@ -61,14 +99,20 @@ type ipfsReader interface {
 // New returns a new Resolver.
 // ipfsReader is optional. If not provided (is nil), CIDs with IPFS hints won't be resolvable.
 func New(casClient extendedcasclient.Client, ipfsReader ipfsReader, webCASResolver WebCASResolver,
-	metrics metricsProvider) *Resolver {
-	return &Resolver{
+	metrics metricsProvider, opts ...Option) *Resolver {
+	r := &Resolver{
 		localCAS:       casClient,
 		ipfsReader:     ipfsReader,
 		webCASResolver: webCASResolver,
 		metrics:        metrics,
 		hl:             hashlink.New(),
 	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
 }
 
 // Resolve does the following:
@@ -77,7 +121,8 @@ func New(casClient extendedcasclient.Client, ipfsReader ipfsReader, webCASResolv
 // returned back to the caller, along with the hashlink of the stored data.
 // 2. If data is not provided (is nil), then the local CAS will be checked to see if it has data at the cid provided.
 // If it does, then it is returned. If it doesn't, and a webCASURL is provided, then the data will be retrieved by
-// querying the webCASURL. This data will then get stored in the local CAS.
+// querying the webCASURL. This data will then get stored in the local CAS. If hedged resolution is enabled then
+// all candidate sources are queried in parallel (see WithHedgedResolution).
 // Finally, the data is returned to the caller, along with the hashlink of the stored data.
 // In both cases above, the CID produced by the local CAS will be checked against the cid passed in to ensure they are
 // the same.
@@ -120,6 +165,12 @@ func (h *Resolver) Resolve(_ *url.URL, hashWithPossibleHint string, data []byte)
 	dataFromLocal, err := h.localCAS.Read(resourceHash)
 	if err != nil { //nolint: nestif // Breaking this up seems worse than leaving the nested ifs
 		if errors.Is(err, orberrors.ErrContentNotFound) {
+			if h.hedged {
+				if sources := h.candidateSources(casLinks, ipfsLinks, domain, resourceHash); len(sources) > 0 {
+					return h.getAndStoreDataFromSources(sources, resourceHash)
+				}
+			}
+
 			if len(casLinks) > 0 {
 				dataFromRemote, localHL, errGetAndStoreRemoteData := h.getAndStoreDataFromWebCASEndpoints(casLinks, resourceHash)
 				if errGetAndStoreRemoteData != nil {
@@ -145,6 +196,125 @@ func (h *Resolver) Resolve(_ *url.URL, hashWithPossibleHint string, data []byte)
 	return dataFromLocal, "", nil
 }
 
+// Prefetch retrieves the content for the given hashlinks (or hashes with possible hints) that isn't already in the
+// local CAS and stores it locally. References are grouped by WebCAS endpoint so that content is retrieved using
+// as few batch requests as possible, which is much faster than resolving each reference individually when catching
+// up on anchor history. This is a best-effort optimization: errors are logged and any content that couldn't be
+// prefetched is retrieved individually by Resolve.
+func (h *Resolver) Prefetch(hashesWithPossibleHints ...string) {
+	endpoints, resourceHashesByEndpoint := h.groupMissingByWebCASEndpoint(hashesWithPossibleHints)
+
+	for _, endpoint := range endpoints {
+		resourceHashes := resourceHashesByEndpoint[endpoint]
+
+		for start := 0; start < len(resourceHashes); start += webcas.MaxBatchSize {
+			end := start + webcas.MaxBatchSize
+			if end > len(resourceHashes) {
+				end = len(resourceHashes)
+			}
+
+			h.prefetchBatch(endpoint, resourceHashes[start:end])
+		}
+	}
+}
+
+func (h *Resolver) groupMissingByWebCASEndpoint(hashesWithPossibleHints []string) ([]string, map[string][]string) {
+	var endpoints []string
+
+	resourceHashesByEndpoint := make(map[string][]string)
+	added := make(map[string]struct{})
+
+	for _, hashWithPossibleHint := range hashesWithPossibleHints {
+		resourceHash, _, links, err := h.getResourceHashWithPossibleDomainAndLinks(hashWithPossibleHint)
+		if err != nil {
+			logger.Debug("Not prefetching reference", log.WithKey(hashWithPossibleHint), log.WithError(err))
+
+			continue
+		}
+
+		if _, ok := added[resourceHash]; ok {
+			continue
+		}
+
+		casLinks, _ := separateLinks(links)
+		if len(casLinks) == 0 {
+			continue
+		}
+
+		if _, err := h.localCAS.Read(resourceHash); !errors.Is(err, orberrors.ErrContentNotFound) {
+			// Either the content is already stored locally or there was an error, in which case Resolve will
+			// deal with it.
+			continue
+		}
+
+		endpoint, ok := webCASBatchEndpoint(casLinks[0], resourceHash)
+		if !ok {
+			logger.Debug("Not prefetching reference since the link isn't a WebCAS link",
+				log.WithKey(hashWithPossibleHint), log.WithLink(casLinks[0]))
+
+			continue
+		}
+
+		if _, ok := resourceHashesByEndpoint[endpoint]; !ok {
+			endpoints = append(endpoints, endpoint)
+		}
+
+		resourceHashesByEndpoint[endpoint] = append(resourceHashesByEndpoint[endpoint], resourceHash)
+		added[resourceHash] = struct{}{}
+	}
+
+	return endpoints, resourceHashesByEndpoint
+}
+
+func (h *Resolver) prefetchBatch(endpoint string, resourceHashes []string) {
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		logger.Warn("Invalid WebCAS batch endpoint", log.WithURLString(endpoint), log.WithError(err))
+
+		return
+	}
+
+	if !h.circuitBreaker.allow(endpointURL.Host) {
+		logger.Debug("Not prefetching content since the circuit breaker is open for the WebCAS endpoint",
+			log.WithURL(endpointURL))
+
+		return
+	}
+
+	contents, err := h.webCASResolver.GetDataViaWebCASBatchEndpoint(endpointURL, resourceHashes)
+	if err != nil {
+		if orberrors.IsTransient(err) {
+			h.circuitBreaker.failure(endpointURL.Host)
+		}
+
+		logger.Warn("Error prefetching content from WebCAS batch endpoint. The content will be resolved individually.",
+			log.WithURL(endpointURL), log.WithError(err))
+
+		return
+	}
+
+	for resourceHash, content := range contents {
+		if _, err := h.storeLocallyAndVerifyHash(content, resourceHash); err != nil {
+			logger.Warn("Error storing prefetched content", log.WithHash(resourceHash), log.WithError(err))
+		}
+	}
+
+	logger.Debug("Prefetched content from WebCAS batch endpoint", log.WithURL(endpointURL),
+		log.WithTotal(len(contents)))
+}
+
+// webCASBatchEndpoint returns the batch endpoint for the given WebCAS link. A WebCAS link has the form
+// https://domain/cas/{resourceHash} and the batch endpoint is https://domain/cas.
+func webCASBatchEndpoint(link, resourceHash string) (string, bool) {
+	suffix := "/" + resourceHash
+
+	if !strings.HasSuffix(link, suffix) {
+		return "", false
+	}
+
+	return strings.TrimSuffix(link, suffix), true
+}
+
 func (h *Resolver) getResourceHashWithPossibleDomainAndLinks(hashWithPossibleHint string) (string, string, []string, error) {
 	var domain string
 
@@ -215,7 +385,7 @@ func separateLinks(links []string) ([]string, []string) {
 }
 
 func (h *Resolver) getAndStoreDataFromDomain(domain, resourceHash string) ([]byte, string, error) {
-	dataFromRemote, err := h.webCASResolver.Resolve(domain, resourceHash)
+	dataFromRemote, err := h.fetchFromSource(context.Background(), h.domainSource(domain, resourceHash))
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to resolve domain and resource hash via WebCAS: %w", err)
 	}
@@ -270,7 +440,7 @@ func (h *Resolver) getAndStoreDataFromWebCASEndpoint(webCASEndpoint, cid string)
 		return nil, "", fmt.Errorf("failed to parse webcas endpoint: %w", err)
 	}
 
-	dataFromRemote, err := h.webCASResolver.GetDataViaWebCASEndpoint(webCASEndpointLink)
+	dataFromRemote, err := h.fetchFromSource(context.Background(), h.webCASSource(webCASEndpointLink))
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to get data via WebCAS endpoint: %w", err)
 	}
@@ -285,7 +455,7 @@ func (h *Resolver) getAndStoreDataFromWebCASEndpoint(webCASEndpoint, cid string)
 }
 
 func (h *Resolver) getAndStoreDataFromIPFS(cid, resourceHash string) ([]byte, string, error) {
-	resp, err := h.ipfsReader.Read(cid)
+	resp, err := h.fetchFromSource(context.Background(), h.ipfsSource(cid))
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to read cid[%s] from ipfs: %w", cid, err)
 	}
@@ -300,7 +470,7 @@ func (h *Resolver) getAndStoreDataFromIPFS(cid, resourceHash string) ([]byte, st
 }
 
 func (h *Resolver) storeLocallyAndVerifyHash(data []byte, resourceHash string) (string, error) {
-	newHLFromLocalCAS, err := h.localCAS.Write(data)
+	newHLFromLocalCAS, err := h.writeLocally(data, resourceHash)
 	if err != nil {
 		return "", fmt.Errorf("failed to write data to CAS "+
 			"(and calculate CID in the process of doing so): %w", err)
@@ -324,6 +494,18 @@ func (h *Resolver) storeLocallyAndVerifyHash(data []byte, resourceHash string) (
 	return newHLFromLocalCAS, nil
 }
 
+// writeLocally writes the data to the local CAS. If the resource hash is a multihash then the data is written using
+// the hash algorithm of the resource hash (which may be different from the default algorithm of the local CAS)
+// so that content that was anchored using a different algorithm may be stored under its original resource hash.
+func (h *Resolver) writeLocally(data []byte, resourceHash string) (string, error) {
+	code, err := h.hl.GetMultihashCode(resourceHash)
+	if err != nil {
+		return h.localCAS.Write(data)
+	}
+
+	return h.localCAS.WriteWithCIDFormat(data, extendedcasclient.WithMultihashCode(code))
+}
+
 // WebCASResolver is used to resolve data from another Orb server's CAS.
 type WebCASResolver struct {
 	httpClient         httpClient
@@ -343,12 +525,16 @@ func NewWebCASResolver(httpClient httpClient, webFingerClient *webfingerclient.C
 // First, a WebFinger is done at domain in order to determine the WebCAS URL.
 // Then the data is retrieved using the WebCAS URL.
 func (w *WebCASResolver) Resolve(domain, cid string) ([]byte, error) {
+	return w.resolve(context.Background(), domain, cid)
+}
+
+func (w *WebCASResolver) resolve(ctx context.Context, domain, cid string) ([]byte, error) {
 	webCASURL, err := w.webFingerClient.GetWebCASURL(fmt.Sprintf("%s://%s", w.webFingerURIScheme, domain), cid)
 	if err != nil {
 		return nil, fmt.Errorf("failed to determine WebCAS URL via WebFinger: %w", err)
 	}
 
-	data, err := w.GetDataViaWebCASEndpoint(webCASURL)
+	data, err := w.getDataViaWebCASEndpoint(ctx, webCASURL)
 	if err != nil {
 		return nil, fmt.Errorf("failure while getting and storing data from the remote "+
 			"WebCAS endpoint: %w", err)
@@ -360,9 +546,91 @@ func (w *WebCASResolver) Resolve(domain, cid string) ([]byte, error) {
 	return data, nil
 }
 
+// GetDataViaWebCASBatchEndpoint retrieves the data for the given CIDs from the given WebCAS batch endpoint.
+// A map of CID to data is returned. CIDs that weren't found by the remote server aren't included in the map.
+func (w *WebCASResolver) GetDataViaWebCASBatchEndpoint(batchEndpoint *url.URL, cids []string) (map[string][]byte, error) {
+	reqBytes, err := json.Marshal(cids)
+	if err != nil {
+		return nil, fmt.Errorf("marshal CIDs: %w", err)
+	}
+
+	resp, err := w.httpClient.Post(context.Background(), transport.NewRequest(batchEndpoint,
+		transport.WithHeader(contentTypeHeader, jsonContentType)), reqBytes)
+	if err != nil {
+		return nil, orberrors.NewTransientf("failed to execute POST call on %s: %w", batchEndpoint, err)
+	}
+
+	defer func() {
+		errClose := resp.Body.Close()
+		if errClose != nil {
+			log.CloseResponseBodyError(logger, errClose)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		responseBody, e := io.ReadAll(resp.Body)
+		if e != nil {
+			return nil, orberrors.NewTransientf("failed to read response body from remote WebCAS batch endpoint: %w", e)
+		}
+
+		err = fmt.Errorf("failed to retrieve data from %s. Response status code: %d. Response body: %s",
+			batchEndpoint, resp.StatusCode, responseBody)
+
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return nil, orberrors.NewTransient(err)
+		}
+
+		return nil, err
+	}
+
+	return readBatchResponse(resp)
+}
+
+func readBatchResponse(resp *http.Response) (map[string][]byte, error) {
+	mediaType, params, err := mime.ParseMediaType(resp.Header.Get(contentTypeHeader))
+	if err != nil {
+		return nil, fmt.Errorf("parse content type of batch response: %w", err)
+	}
+
+	if mediaType != webcas.MultipartMixedContentType {
+		return nil, fmt.Errorf("unsupported content type of batch response: %s", mediaType)
+	}
+
+	contents := make(map[string][]byte)
+
+	mr := multipart.NewReader(resp.Body, params["boundary"])
+
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return contents, nil
+			}
+
+			return nil, orberrors.NewTransientf("read part of batch response: %w", err)
+		}
+
+		cid := part.Header.Get(webcas.CIDHeader)
+		if cid == "" {
+			return nil, fmt.Errorf("part of batch response is missing the %s header", webcas.CIDHeader)
+		}
+
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return nil, orberrors.NewTransientf("read content of batch response for CID [%s]: %w", cid, err)
+		}
+
+		contents[cid] = content
+	}
+}
+
 // GetDataViaWebCASEndpoint retrieves data from the given webCASEndpoint and returns it.
 func (w *WebCASResolver) GetDataViaWebCASEndpoint(webCASEndpoint *url.URL) ([]byte, error) {
-	resp, err := w.httpClient.Get(context.Background(), transport.NewRequest(webCASEndpoint,
+	return w.getDataViaWebCASEndpoint(context.Background(), webCASEndpoint)
+}
+
+func (w *WebCASResolver) getDataViaWebCASEndpoint(ctx context.Context, webCASEndpoint *url.URL) ([]byte, error) {
+	resp, err := w.httpClient.Get(ctx, transport.NewRequest(webCASEndpoint,
 		transport.WithHeader(transport.AcceptHeader, transport.LDPlusJSONContentType)))
 	if err != nil {
 		return nil, orberrors.NewTransientf("failed to execute GET call on %s: %w",
@@ -10,8 +10,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/textproto"
 	"net/url"
 	"testing"
 	"time"
@@ -43,6 +45,7 @@ import (
 )
 
 //go:generate counterfeiter -o ./mocks/casclient.gen.go --fake-name CASClient ../extendedcasclient Client
+//go:generate counterfeiter -o ../../activitypub/mocks/httptransport.gen.go --fake-name HTTPTransport . httpClient
 
 const (
 	sampleData = `{
@@ -265,6 +268,7 @@ func TestResolver_Resolve(t *testing.T) {
 			casClientWithError := &resolvermocks.CASClient{}
 			casClientWithError.ReadReturns(nil, orberrors.ErrContentNotFound)
 			casClientWithError.WriteReturns("", errExpected)
+			casClientWithError.WriteWithCIDFormatReturns("", errExpected)
 
 			resolver := createNewResolver(t, casClientWithError, nil)
 			resolver.webCASResolver.webFingerURIScheme = httpScheme
@@ -776,7 +780,169 @@ func TestResolver_Resolve(t *testing.T) {
 	})
 }
 
-func createNewResolver(t *testing.T, casClient extendedcasclient.Client, ipfsReader ipfsReader) *Resolver {
+func TestResolver_Prefetch(t *testing.T) {
+	remoteCAS := createInMemoryCAS(t)
+
+	var batchRequests int
+
+	router := mux.NewRouter()
+
+	batch := webcas.NewBatch(&resthandler.Config{}, memstore.New(""), &mocks.SignatureVerifier{},
+		remoteCAS, &apmocks.AuthTokenMgr{})
+
+	router.HandleFunc(batch.Path(), func(rw http.ResponseWriter, req *http.Request) {
+		batchRequests++
+
+		batch.Handler()(rw, req)
+	}).Methods(batch.Method())
+
+	testServer := httptest.NewServer(router)
+	defer testServer.Close()
+
+	var hashLinks []string
+
+	for i := 0; i < webcas.MaxBatchSize+5; i++ {
+		content := []byte(fmt.Sprintf("content %d", i))
+
+		_, err := remoteCAS.Write(content)
+		require.NoError(t, err)
+
+		hashLinks = append(hashLinks, newHashLink(t, content, testServer.URL+"/cas"))
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		batchRequests = 0
+
+		localCAS := createInMemoryCAS(t)
+
+		// Content which is already stored locally shouldn't be requested.
+		_, err := localCAS.Write([]byte("content 0"))
+		require.NoError(t, err)
+
+		notFoundHL := newHashLink(t, []byte("not found"), testServer.URL+"/cas")
+
+		resolver := createNewResolver(t, localCAS, nil)
+
+		// Duplicates and references without WebCAS links should be ignored.
+		resolver.Prefetch(append(hashLinks, hashLinks[1], notFoundHL, "uEiAWradITyYpRGT3pMhcKfPL8kpJBGePjFjZOlS0zqAUqw",
+			"hl:invalid")...)
+
+		// Two batch requests are required since the number of references exceeds the maximum batch size.
+		require.Equal(t, 2, batchRequests)
+
+		for i, hl := range hashLinks {
+			rh, err := hashlink.GetResourceHashFromHashLink(hl)
+			require.NoError(t, err)
+
+			data, err := localCAS.Read(rh)
+			require.NoError(t, err)
+			require.Equal(t, fmt.Sprintf("content %d", i), string(data))
+		}
+
+		// Nothing should be requested since everything is stored locally.
+		batchRequests = 0
+
+		resolver.Prefetch(hashLinks...)
+
+		require.Zero(t, batchRequests)
+	})
+
+	t.Run("Batch endpoint error", func(t *testing.T) {
+		localCAS := createInMemoryCAS(t)
+
+		resolver := createNewResolver(t, localCAS, nil)
+
+		resolver.Prefetch(newHashLink(t, []byte("content 0"), "http://localhost:1/cas"))
+
+		rh, err := hashlink.New().CreateResourceHash([]byte("content 0"))
+		require.NoError(t, err)
+
+		_, err = localCAS.Read(rh)
+		require.True(t, errors.Is(err, orberrors.ErrContentNotFound))
+	})
+}
+
+func TestWebCASResolver_GetDataViaWebCASBatchEndpoint(t *testing.T) {
+	resolver := createNewResolver(t, createInMemoryCAS(t), nil)
+
+	t.Run("Status error", func(t *testing.T) {
+		testServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			rw.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer testServer.Close()
+
+		contents, err := resolver.webCASResolver.GetDataViaWebCASBatchEndpoint(
+			testutil.MustParseURL(testServer.URL+"/cas"), []string{"cid"})
+		require.Error(t, err)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "Response status code: 500")
+		require.Nil(t, contents)
+	})
+
+	t.Run("Bad request", func(t *testing.T) {
+		testServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			rw.WriteHeader(http.StatusBadRequest)
+		}))
+		defer testServer.Close()
+
+		contents, err := resolver.webCASResolver.GetDataViaWebCASBatchEndpoint(
+			testutil.MustParseURL(testServer.URL+"/cas"), []string{"cid"})
+		require.Error(t, err)
+		require.False(t, orberrors.IsTransient(err))
+		require.Nil(t, contents)
+	})
+
+	t.Run("Unsupported content type", func(t *testing.T) {
+		testServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			rw.Header().Set("Content-Type", "application/json")
+		}))
+		defer testServer.Close()
+
+		contents, err := resolver.webCASResolver.GetDataViaWebCASBatchEndpoint(
+			testutil.MustParseURL(testServer.URL+"/cas"), []string{"cid"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported content type of batch response: application/json")
+		require.Nil(t, contents)
+	})
+
+	t.Run("Missing CID header", func(t *testing.T) {
+		testServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			mw := multipart.NewWriter(rw)
+
+			rw.Header().Set("Content-Type", "multipart/mixed; boundary="+mw.Boundary())
+
+			pw, err := mw.CreatePart(textproto.MIMEHeader{})
+			require.NoError(t, err)
+
+			_, err = pw.Write([]byte("content"))
+			require.NoError(t, err)
+
+			require.NoError(t, mw.Close())
+		}))
+		defer testServer.Close()
+
+		contents, err := resolver.webCASResolver.GetDataViaWebCASBatchEndpoint(
+			testutil.MustParseURL(testServer.URL+"/cas"), []string{"cid"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "part of batch response is missing the Content-ID header")
+		require.Nil(t, contents)
+	})
+}
+
+func newHashLink(t *testing.T, content []byte, casURL string) string {
+	t.Helper()
+
+	rh, err := hashlink.New().CreateResourceHash(content)
+	require.NoError(t, err)
+
+	md, err := hashlink.New().CreateMetadataFromLinks([]string{casURL + "/" + rh})
+	require.NoError(t, err)
+
+	return hashlink.GetHashLink(rh, md)
+}
+
+func createNewResolver(t *testing.T, casClient extendedcasclient.Client, ipfsReader ipfsReader,
+	opts ...Option) *Resolver {
 	t.Helper()
 
 	webFingerResolver := webfingerclient.New()
@@ -788,7 +954,7 @@ func createNewResolver(t *testing.T, casClient extendedcasclient.Client, ipfsRea
 		webFingerResolver,
 		"http")
 
-	casResolver := New(casClient, ipfsReader, webCASResolver, &orbmocks.MetricsProvider{})
+	casResolver := New(casClient, ipfsReader, webCASResolver, &orbmocks.MetricsProvider{}, opts...)
 	require.NotNil(t, casResolver)
 
 	return casResolver
@@ -0,0 +1,270 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/hashlink"
+)
+
+// Source types used as the metrics label for per-source latency.
+const (
+	sourceWebCAS = "webcas"
+	sourceIPFS   = "ipfs"
+	sourceDomain = "domain"
+)
+
+// casSource is a remote source from which content may be retrieved.
+type casSource struct {
+	// kind is the type of source (webcas, ipfs or domain).
+	kind string
+	// id identifies the source in logs and errors.
+	id string
+	// breakerKey is the key used by the circuit breaker. An empty key means that the circuit breaker
+	// doesn't apply to the source.
+	breakerKey string
+	fetch      func(ctx context.Context) ([]byte, error)
+}
+
+type sourceResult struct {
+	source *casSource
+	data   []byte
+	err    error
+}
+
+func (h *Resolver) webCASSource(webCASEndpoint *url.URL) *casSource {
+	return &casSource{
+		kind:       sourceWebCAS,
+		id:         webCASEndpoint.String(),
+		breakerKey: webCASEndpoint.Host,
+		fetch: func(ctx context.Context) ([]byte, error) {
+			return h.webCASResolver.getDataViaWebCASEndpoint(ctx, webCASEndpoint)
+		},
+	}
+}
+
+func (h *Resolver) ipfsSource(cid string) *casSource {
+	return &casSource{
+		kind: sourceIPFS,
+		id:   ipfsPrefix + cid,
+		fetch: func(context.Context) ([]byte, error) {
+			return h.ipfsReader.Read(cid)
+		},
+	}
+}
+
+func (h *Resolver) domainSource(domain, resourceHash string) *casSource {
+	return &casSource{
+		kind:       sourceDomain,
+		id:         domain,
+		breakerKey: domain,
+		fetch: func(ctx context.Context) ([]byte, error) {
+			return h.webCASResolver.resolve(ctx, domain, resourceHash)
+		},
+	}
+}
+
+// candidateSources returns all of the remote sources from which the content may be retrieved, in order of
+// preference: WebCAS links, IPFS links and, finally, the domain hint.
+func (h *Resolver) candidateSources(casLinks, ipfsLinks []string, domain, resourceHash string) []*casSource {
+	var sources []*casSource
+
+	for _, link := range casLinks {
+		u, err := url.Parse(link)
+		if err != nil {
+			logger.Debug("Ignoring invalid WebCAS link", log.WithLink(link), log.WithError(err))
+
+			continue
+		}
+
+		sources = append(sources, h.webCASSource(u))
+	}
+
+	if h.ipfsReader != nil {
+		for _, link := range ipfsLinks {
+			sources = append(sources, h.ipfsSource(link[len(ipfsPrefix):]))
+		}
+	}
+
+	if domain != "" {
+		sources = append(sources, h.domainSource(domain, resourceHash))
+	}
+
+	return sources
+}
+
+// fetchFromSource retrieves content from the given source, recording the latency of the source and updating the
+// circuit breaker. If the circuit for the source is open then a transient error is returned without contacting
+// the source.
+func (h *Resolver) fetchFromSource(ctx context.Context, s *casSource) ([]byte, error) {
+	if s.breakerKey != "" && !h.circuitBreaker.allow(s.breakerKey) {
+		return nil, orberrors.NewTransientf("circuit breaker is open for endpoint [%s]", s.breakerKey)
+	}
+
+	startTime := time.Now()
+
+	data, err := s.fetch(ctx)
+
+	if ctx.Err() != nil {
+		// The request was cancelled (for example, another source responded first) so the result
+		// doesn't reflect the health of the source.
+		return nil, ctx.Err()
+	}
+
+	h.metrics.CASResolveSourceTime(s.kind, time.Since(startTime))
+
+	if s.breakerKey != "" {
+		if err != nil && orberrors.IsTransient(err) {
+			h.circuitBreaker.failure(s.breakerKey)
+		} else if err == nil {
+			h.circuitBreaker.success(s.breakerKey)
+		}
+	}
+
+	return data, err
+}
+
+// getAndStoreDataFromSources retrieves the content from the given sources using hedged requests (see resolveHedged),
+// and stores it in the local CAS.
+func (h *Resolver) getAndStoreDataFromSources(sources []*casSource, resourceHash string) ([]byte, string, error) {
+	data, err := h.resolveHedged(sources, resourceHash)
+	if err != nil {
+		return nil, "", fmt.Errorf("failure while getting data from remote CAS sources: %w", err)
+	}
+
+	localHL, err := h.storeLocallyAndVerifyHash(data, resourceHash)
+	if err != nil {
+		return nil, "", fmt.Errorf("failure while storing data retrieved from a remote CAS source locally: %w", err)
+	}
+
+	return data, localHL, nil
+}
+
+// resolveHedged requests the content from the first source and, if no verified response is received within the
+// hedge delay, from the next source, and so on. A failed request causes the next source to be requested
+// immediately. (With a hedge delay of zero all sources are requested at once.) The first response whose hash
+// matches the resource hash wins and all outstanding requests are cancelled.
+func (h *Resolver) resolveHedged(sources []*casSource, resourceHash string) ([]byte, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The channel is buffered so that outstanding requests don't block after a winner is chosen.
+	results := make(chan *sourceResult, len(sources))
+
+	next := 0
+
+	launch := func() {
+		s := sources[next]
+		next++
+
+		go func() {
+			data, err := h.fetchFromSource(ctx, s)
+			if err == nil {
+				err = h.verifyHash(data, resourceHash)
+			}
+
+			results <- &sourceResult{source: s, data: data, err: err}
+		}()
+	}
+
+	launch()
+
+	if h.hedgeDelay == 0 {
+		for next < len(sources) {
+			launch()
+		}
+	}
+
+	var errMsgs []string
+
+	var isTransient bool
+
+	for pending := next; pending > 0; {
+		r := awaitResult(results, next < len(sources), h.hedgeDelay)
+		if r == nil {
+			logger.Debug("No response from remote CAS source within hedge delay. Requesting from next source.",
+				log.WithHash(resourceHash), log.WithSource(sources[next].id), log.WithDuration(h.hedgeDelay))
+
+			launch()
+			pending++
+
+			continue
+		}
+
+		pending--
+
+		if r.err == nil {
+			logger.Debug("Retrieved data from remote CAS source", log.WithHash(resourceHash),
+				log.WithSource(r.source.id))
+
+			return r.data, nil
+		}
+
+		logger.Debug("Error retrieving data from remote CAS source", log.WithHash(resourceHash),
+			log.WithSource(r.source.id), log.WithError(r.err))
+
+		errMsgs = append(errMsgs, fmt.Sprintf("%s[%s]: %s", r.source.kind, r.source.id, r.err))
+		isTransient = isTransient || orberrors.IsTransient(r.err)
+
+		if next < len(sources) {
+			launch()
+			pending++
+		}
+	}
+
+	err := fmt.Errorf("%s", errMsgs)
+
+	if isTransient {
+		return nil, orberrors.NewTransient(err)
+	}
+
+	return nil, err
+}
+
+// awaitResult waits for the next result. If hedge is true and the hedge delay elapses before a result is
+// received then nil is returned.
+func awaitResult(results <-chan *sourceResult, hedge bool, hedgeDelay time.Duration) *sourceResult {
+	if !hedge {
+		return <-results
+	}
+
+	timer := time.NewTimer(hedgeDelay)
+	defer timer.Stop()
+
+	select {
+	case r := <-results:
+		return r
+	case <-timer.C:
+		return nil
+	}
+}
+
+// verifyHash verifies the data against the resource hash using the hash algorithm of the resource hash.
+func (h *Resolver) verifyHash(data []byte, resourceHash string) error {
+	code, err := h.hl.GetMultihashCode(resourceHash)
+	if err != nil {
+		return fmt.Errorf("get multihash code: %w", err)
+	}
+
+	hash, err := hashlink.New(hashlink.WithMultihashCode(code)).CreateResourceHash(data)
+	if err != nil {
+		return fmt.Errorf("create resource hash: %w", err)
+	}
+
+	if hash != resourceHash {
+		return fmt.Errorf("resource hash of the retrieved data (%s) does not match the requested "+
+			"resource hash (%s)", hash, resourceHash)
+	}
+
+	return nil
+}
@@ -0,0 +1,318 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resolver
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/cas/extendedcasclient"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	orbmocks "github.com/trustbloc/orb/pkg/mocks"
+	"github.com/trustbloc/orb/pkg/store/cas"
+)
+
+func TestResolver_ResolveHedged(t *testing.T) {
+	content := []byte("content")
+
+	resourceHash, err := hashlink.New().CreateResourceHash(content)
+	require.NoError(t, err)
+
+	// The slow server blocks until the request is cancelled.
+	var slowCancelled int32
+
+	slowServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		select {
+		case <-req.Context().Done():
+			atomic.StoreInt32(&slowCancelled, 1)
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer slowServer.Close()
+
+	fastServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, err := rw.Write(content)
+		require.NoError(t, err)
+	}))
+	defer fastServer.Close()
+
+	failingServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failingServer.Close()
+
+	badDataServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, err := rw.Write([]byte("bad data"))
+		require.NoError(t, err)
+	}))
+	defer badDataServer.Close()
+
+	t.Run("Slow source -> next source requested after hedge delay", func(t *testing.T) {
+		atomic.StoreInt32(&slowCancelled, 0)
+
+		resolver := createNewResolver(t, createInMemoryCAS(t), nil, WithHedgedResolution(20*time.Millisecond))
+
+		hl := newHashLinkWithLinks(t, content, slowServer.URL+"/cas/"+resourceHash, fastServer.URL+"/cas/"+resourceHash)
+
+		startTime := time.Now()
+
+		data, localHL, err := resolver.Resolve(nil, hl, nil)
+		require.NoError(t, err)
+		require.Equal(t, content, data)
+		require.NotEmpty(t, localHL)
+		require.Less(t, time.Since(startTime), 2*time.Second)
+
+		// The request to the slow server should have been cancelled.
+		require.Eventually(t, func() bool { return atomic.LoadInt32(&slowCancelled) == 1 }, time.Second, 10*time.Millisecond)
+
+		// The data should now be stored locally.
+		data, localHL, err = resolver.Resolve(nil, hl, nil)
+		require.NoError(t, err)
+		require.Equal(t, content, data)
+		require.Empty(t, localHL)
+	})
+
+	t.Run("Zero hedge delay -> all sources requested at once", func(t *testing.T) {
+		resolver := createNewResolver(t, createInMemoryCAS(t), nil, WithHedgedResolution(0))
+
+		hl := newHashLinkWithLinks(t, content, slowServer.URL+"/cas/"+resourceHash, fastServer.URL+"/cas/"+resourceHash)
+
+		data, _, err := resolver.Resolve(nil, hl, nil)
+		require.NoError(t, err)
+		require.Equal(t, content, data)
+	})
+
+	t.Run("Failed source -> next source requested immediately", func(t *testing.T) {
+		resolver := createNewResolver(t, createInMemoryCAS(t), nil, WithHedgedResolution(time.Minute))
+
+		hl := newHashLinkWithLinks(t, content, failingServer.URL+"/cas/"+resourceHash,
+			badDataServer.URL+"/cas/"+resourceHash, fastServer.URL+"/cas/"+resourceHash)
+
+		data, _, err := resolver.Resolve(nil, hl, nil)
+		require.NoError(t, err)
+		require.Equal(t, content, data)
+	})
+
+	t.Run("All sources fail -> transient error", func(t *testing.T) {
+		resolver := createNewResolver(t, createInMemoryCAS(t), nil, WithHedgedResolution(0))
+
+		hl := newHashLinkWithLinks(t, content, failingServer.URL+"/cas/"+resourceHash,
+			badDataServer.URL+"/cas/"+resourceHash)
+
+		_, _, err := resolver.Resolve(nil, hl, nil)
+		require.Error(t, err)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "Response status code: 500")
+		require.Contains(t, err.Error(), "does not match the requested resource hash")
+	})
+
+	t.Run("IPFS source", func(t *testing.T) {
+		ipfsReader := &mockIPFSReader{content: content}
+
+		resolver := createNewResolver(t, createInMemoryCAS(t), ipfsReader, WithHedgedResolution(0))
+
+		data, _, err := resolver.Resolve(nil, "ipfs:"+resourceHash, nil)
+		require.NoError(t, err)
+		require.Equal(t, content, data)
+	})
+
+	t.Run("IPFS source error", func(t *testing.T) {
+		ipfsReader := &mockIPFSReader{err: errors.New("injected IPFS error")}
+
+		resolver := createNewResolver(t, createInMemoryCAS(t), ipfsReader, WithHedgedResolution(0))
+
+		_, _, err := resolver.Resolve(nil, "ipfs:"+resourceHash, nil)
+		require.Error(t, err)
+		require.False(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "injected IPFS error")
+	})
+
+	t.Run("Domain source", func(t *testing.T) {
+		resolver := createNewResolver(t, createInMemoryCAS(t), nil, WithHedgedResolution(0))
+
+		_, _, err := resolver.Resolve(nil, "https:localhost:1:"+resourceHash, nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "domain[localhost:1]")
+	})
+}
+
+func TestResolver_CrossAlgorithm(t *testing.T) {
+	content := []byte("content")
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, err := rw.Write(content)
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	for _, code := range []uint{hashlink.SHA2_256, hashlink.BLAKE2b_256} {
+		rh, err := hashlink.New(hashlink.WithMultihashCode(code)).CreateResourceHash(content)
+		require.NoError(t, err)
+
+		md, err := hashlink.New().CreateMetadataFromLinks([]string{server.URL + "/cas/" + rh})
+		require.NoError(t, err)
+
+		for _, opts := range [][]Option{nil, {WithHedgedResolution(0)}} {
+			// The local CAS uses SHA3-256 by default.
+			localCAS, err := cas.New(mem.NewProvider(), sampleCASURL, nil, &orbmocks.MetricsProvider{}, 0,
+				extendedcasclient.WithMultihashCode(hashlink.SHA3_256))
+			require.NoError(t, err)
+
+			resolver := createNewResolver(t, localCAS, nil, opts...)
+
+			data, localHL, err := resolver.Resolve(nil, hashlink.GetHashLink(rh, md), nil)
+			require.NoError(t, err)
+			require.Equal(t, content, data)
+
+			// The content should be stored locally under the original resource hash.
+			localRH, err := hashlink.GetResourceHashFromHashLink(localHL)
+			require.NoError(t, err)
+			require.Equal(t, rh, localRH)
+		}
+	}
+}
+
+func TestResolver_CircuitBreaker(t *testing.T) {
+	content := []byte("content")
+
+	var failingRequests int32
+
+	failingServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&failingRequests, 1)
+
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failingServer.Close()
+
+	notFoundServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusNotFound)
+	}))
+	defer notFoundServer.Close()
+
+	t.Run("Circuit opens after consecutive failures", func(t *testing.T) {
+		atomic.StoreInt32(&failingRequests, 0)
+
+		resolver := createNewResolver(t, createInMemoryCAS(t), nil, WithCircuitBreaker(2, time.Minute))
+
+		hl := newHashLink(t, content, failingServer.URL+"/cas")
+
+		for i := 0; i < 3; i++ {
+			_, _, err := resolver.Resolve(nil, hl, nil)
+			require.Error(t, err)
+			require.True(t, orberrors.IsTransient(err))
+		}
+
+		require.Equal(t, int32(2), atomic.LoadInt32(&failingRequests))
+
+		_, _, err := resolver.Resolve(nil, hl, nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "circuit breaker is open")
+
+		// Batch prefetch requests should also be skipped.
+		resolver.Prefetch(hl)
+
+		require.Equal(t, int32(2), atomic.LoadInt32(&failingRequests))
+	})
+
+	t.Run("Hedged -> circuit opens after consecutive failures", func(t *testing.T) {
+		atomic.StoreInt32(&failingRequests, 0)
+
+		resolver := createNewResolver(t, createInMemoryCAS(t), nil,
+			WithHedgedResolution(0), WithCircuitBreaker(1, time.Minute))
+
+		hl := newHashLink(t, content, failingServer.URL+"/cas")
+
+		for i := 0; i < 2; i++ {
+			_, _, err := resolver.Resolve(nil, hl, nil)
+			require.Error(t, err)
+		}
+
+		require.Equal(t, int32(1), atomic.LoadInt32(&failingRequests))
+	})
+
+	t.Run("Not found doesn't open circuit", func(t *testing.T) {
+		resolver := createNewResolver(t, createInMemoryCAS(t), nil, WithCircuitBreaker(1, time.Minute))
+
+		hl := newHashLink(t, content, notFoundServer.URL+"/cas")
+
+		for i := 0; i < 2; i++ {
+			_, _, err := resolver.Resolve(nil, hl, nil)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "Response status code: 404")
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		resolver := createNewResolver(t, createInMemoryCAS(t), nil, WithCircuitBreaker(0, time.Minute))
+		require.Nil(t, resolver.circuitBreaker)
+	})
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Now()
+
+	cb := newCircuitBreaker(2, time.Minute)
+	cb.now = func() time.Time { return now }
+
+	require.True(t, cb.allow("domain1"))
+
+	cb.failure("domain1")
+	require.True(t, cb.allow("domain1"))
+
+	cb.failure("domain1")
+	require.False(t, cb.allow("domain1"))
+	require.True(t, cb.allow("domain2"))
+
+	// After the open duration, requests are allowed again but another failure re-opens the circuit.
+	now = now.Add(time.Minute)
+	require.True(t, cb.allow("domain1"))
+
+	cb.failure("domain1")
+	require.False(t, cb.allow("domain1"))
+
+	// A success closes the circuit.
+	now = now.Add(time.Minute)
+	cb.success("domain1")
+
+	cb.failure("domain1")
+	require.True(t, cb.allow("domain1"))
+
+	var nilCB *circuitBreaker
+
+	require.True(t, nilCB.allow("domain1"))
+	require.NotPanics(t, func() { nilCB.failure("domain1") })
+	require.NotPanics(t, func() { nilCB.success("domain1") })
+}
+
+type mockIPFSReader struct {
+	content []byte
+	err     error
+}
+
+func (m *mockIPFSReader) Read(string) ([]byte, error) {
+	return m.content, m.err
+}
+
+func newHashLinkWithLinks(t *testing.T, content []byte, links ...string) string {
+	t.Helper()
+
+	rh, err := hashlink.New().CreateResourceHash(content)
+	require.NoError(t, err)
+
+	md, err := hashlink.New().CreateMetadataFromLinks(links)
+	require.NoError(t, err)
+
+	return hashlink.GetHashLink(rh, md)
+}
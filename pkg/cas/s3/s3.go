@@ -0,0 +1,259 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package s3
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/bluele/gcache"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/cas/extendedcasclient"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	"github.com/trustbloc/orb/pkg/multihash"
+)
+
+const logModule = "cas-s3"
+
+var logger = log.New(logModule)
+
+const (
+	defaultCacheSize = 1000
+	casType          = "s3"
+	contentType      = "application/octet-stream"
+	notFoundErrCode  = "NotFound"
+)
+
+type metricsProvider interface {
+	CASIncrementCacheHitCount()
+	CASReadTime(casType string, value time.Duration)
+}
+
+type s3Client interface {
+	PutObject(input *s3.PutObjectInput) (*s3.PutObjectOutput, error)
+	GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error)
+}
+
+// Client stores content in an S3-compatible object store (AWS S3, MinIO, etc.). Objects are keyed by the same
+// resource hash that is produced by the local CAS, so hashlinks created by this client are interchangeable with
+// those created by the local CAS.
+// It implements the extended CAS client interface.
+type Client struct {
+	s3      s3Client
+	bucket  string
+	prefix  string
+	casLink string
+	opts    []extendedcasclient.CIDFormatOption
+	hl      *hashlink.HashLink
+	cache   gcache.Cache
+	metrics metricsProvider
+}
+
+// New returns a new S3 CAS client which stores objects in the given bucket. The AWS session (config provider)
+// determines the endpoint, region and credentials. prefix is optional and, if provided, is prepended to each
+// object key, which allows a bucket to be shared. casLink is the WebCAS URL that's added to the hashlinks
+// of written content.
+func New(cfg client.ConfigProvider, bucket, prefix, casLink string, metrics metricsProvider, cacheSize int,
+	opts ...extendedcasclient.CIDFormatOption) *Client {
+	return newClient(s3.New(cfg), bucket, prefix, casLink, metrics, cacheSize, opts...)
+}
+
+func newClient(s3 s3Client, bucket, prefix, casLink string, metrics metricsProvider, cacheSize int,
+	opts ...extendedcasclient.CIDFormatOption) *Client {
+	if cacheSize == 0 {
+		cacheSize = defaultCacheSize
+	}
+
+	c := &Client{
+		s3:      s3,
+		bucket:  bucket,
+		prefix:  prefix,
+		casLink: casLink,
+		opts:    opts,
+		hl:      hashlink.New(),
+		metrics: metrics,
+	}
+
+	c.cache = gcache.New(cacheSize).ARC().
+		LoaderFunc(func(k interface{}) (interface{}, error) {
+			key := k.(string) //nolint:forcetypeassert
+
+			content, err := c.get(key)
+			if err != nil {
+				return nil, err
+			}
+
+			logger.Debug("Content was cached for key", log.WithKey(key))
+
+			return content, nil
+		}).Build()
+
+	return c
+}
+
+// Write writes the given content to the S3 bucket using this client's default CID format.
+// Returns the hashlink of the content.
+func (c *Client) Write(content []byte) (string, error) {
+	return c.WriteWithCIDFormat(content, c.opts...)
+}
+
+// WithCIDFormat returns a copy of this client whose Write method uses the given CID format options. The copy
+// shares the underlying S3 client and cache with this client.
+func (c *Client) WithCIDFormat(opts ...extendedcasclient.CIDFormatOption) extendedcasclient.Client {
+	client := *c
+	client.opts = append(append([]extendedcasclient.CIDFormatOption{}, c.opts...), opts...)
+
+	return &client
+}
+
+// WriteWithCIDFormat writes the given content to the S3 bucket. The object key is the multihash of the content
+// (the same as the resource hash generated by the local CAS) so the CID version has no effect on the key. The
+// multihash code option selects the hash algorithm of the key.
+// Returns the hashlink of the content.
+func (c *Client) WriteWithCIDFormat(content []byte, opts ...extendedcasclient.CIDFormatOption) (string, error) {
+	if len(content) == 0 {
+		return "", errors.New("empty content")
+	}
+
+	opts = append(append([]extendedcasclient.CIDFormatOption{}, c.opts...), opts...)
+
+	resourceHash, err := hashlink.New(
+		hashlink.WithMultihashCode(extendedcasclient.MultihashCode(opts...)),
+	).CreateResourceHash(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to create resource hash from content: %w", err)
+	}
+
+	logger.Debug("Writing to S3. Content (base64-encoded)", log.WithHash(resourceHash), log.WithCASData(content))
+
+	_, err = c.s3.PutObject(&s3.PutObjectInput{
+		Bucket:      aws.String(c.bucket),
+		Key:         aws.String(c.key(resourceHash)),
+		Body:        bytes.NewReader(content),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return "", orberrors.NewTransient(fmt.Errorf("failed to put content into S3 bucket [%s]: %w", c.bucket, err))
+	}
+
+	if err = c.cache.Set(resourceHash, content); err != nil {
+		// This shouldn't be possible.
+		logger.Warn("Error caching content for resource hash", log.WithHash(resourceHash), log.WithError(err))
+	}
+
+	metadata, err := c.hl.CreateMetadataFromLinks([]string{c.casLink + "/" + resourceHash})
+	if err != nil {
+		return "", fmt.Errorf("failed to create metadata from links: %w", err)
+	}
+
+	return hashlink.GetHashLink(resourceHash, metadata), nil
+}
+
+// GetPrimaryWriterType returns primary writer type.
+func (c *Client) GetPrimaryWriterType() string {
+	return casType
+}
+
+// Read reads the content for the given address from the S3 bucket. The address may be a resource hash,
+// a hashlink or a CID.
+func (c *Client) Read(address string) ([]byte, error) {
+	resourceHash, err := c.getResourceHash(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.cache.Has(resourceHash) {
+		c.metrics.CASIncrementCacheHitCount()
+	}
+
+	content, err := c.cache.Get(resourceHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return content.([]byte), nil //nolint:forcetypeassert
+}
+
+func (c *Client) get(resourceHash string) ([]byte, error) {
+	startTime := time.Now()
+
+	defer func() { c.metrics.CASReadTime(casType, time.Since(startTime)) }()
+
+	out, err := c.s3.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(c.key(resourceHash)),
+	})
+	if err != nil {
+		if isNotFound(err) {
+			return nil, orberrors.ErrContentNotFound
+		}
+
+		return nil, orberrors.NewTransient(fmt.Errorf("failed to get content from S3 bucket [%s]: %w", c.bucket, err))
+	}
+
+	defer func() {
+		if errClose := out.Body.Close(); errClose != nil {
+			logger.Warn("Failed to close S3 object body", log.WithError(errClose))
+		}
+	}()
+
+	content, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("failed to read content from S3 bucket [%s]: %w", c.bucket, err))
+	}
+
+	logger.Debug("Got content from S3 (base64-encoded)", log.WithHash(resourceHash), log.WithCASData(content))
+
+	return content, nil
+}
+
+func (c *Client) getResourceHash(address string) (string, error) {
+	if strings.HasPrefix(address, hashlink.HLPrefix) {
+		hlInfo, err := c.hl.ParseHashLink(address)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse hashlink [%s]: %w", address, err)
+		}
+
+		return hlInfo.ResourceHash, nil
+	}
+
+	if multihash.IsValidCID(address) {
+		resourceHash, err := multihash.CIDToMultihash(address)
+		if err != nil {
+			return "", fmt.Errorf("failed to convert CID [%s] to multihash: %w", address, err)
+		}
+
+		return resourceHash, nil
+	}
+
+	return address, nil
+}
+
+func (c *Client) key(resourceHash string) string {
+	return c.prefix + resourceHash
+}
+
+func isNotFound(err error) bool {
+	var awsErr awserr.Error
+
+	if !errors.As(err, &awsErr) {
+		return false
+	}
+
+	// Some S3-compatible stores return a generic "NotFound" code instead of "NoSuchKey".
+	return awsErr.Code() == s3.ErrCodeNoSuchKey || awsErr.Code() == notFoundErrCode
+}
@@ -0,0 +1,283 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package s3
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/cas/extendedcasclient"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	orbmocks "github.com/trustbloc/orb/pkg/mocks"
+	"github.com/trustbloc/orb/pkg/multihash"
+)
+
+const (
+	casLink = "https://domain.com/cas"
+	bucket  = "orb-cas"
+	prefix  = "anchors/"
+)
+
+func TestClient_Write_Read(t *testing.T) {
+	server := newMockS3Server(bucket)
+	defer server.Close()
+
+	c := New(newTestSession(t, server.URL), bucket, prefix, casLink, &orbmocks.MetricsProvider{}, 0)
+	require.Equal(t, "s3", c.GetPrimaryWriterType())
+
+	content := []byte(`{"field":"value"}`)
+
+	t.Run("success", func(t *testing.T) {
+		hl, err := c.Write(content)
+		require.NoError(t, err)
+
+		resourceHash, err := hashlink.GetResourceHashFromHashLink(hl)
+		require.NoError(t, err)
+
+		require.Contains(t, server.objects, prefix+resourceHash)
+
+		hlInfo, err := hashlink.New().ParseHashLink(hl)
+		require.NoError(t, err)
+		require.Equal(t, []string{casLink + "/" + resourceHash}, hlInfo.Links)
+
+		// Read from a new client so that the content isn't cached.
+		c2 := New(newTestSession(t, server.URL), bucket, prefix, casLink, &orbmocks.MetricsProvider{}, 0)
+
+		data, err := c2.Read(resourceHash)
+		require.NoError(t, err)
+		require.Equal(t, content, data)
+
+		data, err = c2.Read(hl)
+		require.NoError(t, err)
+		require.Equal(t, content, data)
+
+		cid, err := multihash.ToV1CID(resourceHash)
+		require.NoError(t, err)
+
+		data, err = c2.Read(cid)
+		require.NoError(t, err)
+		require.Equal(t, content, data)
+	})
+
+	t.Run("same hashlink as local CAS", func(t *testing.T) {
+		hl, err := c.WriteWithCIDFormat(content, extendedcasclient.WithCIDVersion(0))
+		require.NoError(t, err)
+
+		resourceHash, err := hashlink.New().CreateResourceHash(content)
+		require.NoError(t, err)
+
+		// The local CAS creates the hashlink from the resource hash and its WebCAS link.
+		expectedHL, err := hashlink.New().CreateHashLink(content, []string{casLink + "/" + resourceHash})
+		require.NoError(t, err)
+		require.Equal(t, expectedHL, hl)
+	})
+
+	t.Run("with CID format", func(t *testing.T) {
+		hl, err := c.WithCIDFormat(extendedcasclient.WithMultihashCode(hashlink.SHA3_256)).Write(content)
+		require.NoError(t, err)
+
+		resourceHash, err := hashlink.GetResourceHashFromHashLink(hl)
+		require.NoError(t, err)
+
+		code, err := hashlink.New().GetMultihashCode(resourceHash)
+		require.NoError(t, err)
+		require.Equal(t, hashlink.SHA3_256, code)
+
+		data, err := c.Read(resourceHash)
+		require.NoError(t, err)
+		require.Equal(t, content, data)
+
+		// The options of the original client are unchanged.
+		hl, err = c.Write(content)
+		require.NoError(t, err)
+
+		resourceHash, err = hashlink.GetResourceHashFromHashLink(hl)
+		require.NoError(t, err)
+
+		code, err = hashlink.New().GetMultihashCode(resourceHash)
+		require.NoError(t, err)
+		require.Equal(t, hashlink.SHA2_256, code)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		data, err := c.Read("uEiCCQWmOzsPbjQAH1kcHTSVNhWbnX8FLNkgRjsFJD0xOFg")
+		require.True(t, errors.Is(err, orberrors.ErrContentNotFound))
+		require.Nil(t, data)
+	})
+
+	t.Run("empty content", func(t *testing.T) {
+		hl, err := c.Write(nil)
+		require.EqualError(t, err, "empty content")
+		require.Empty(t, hl)
+	})
+
+	t.Run("invalid hashlink", func(t *testing.T) {
+		data, err := c.Read("hl:invalid")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to parse hashlink")
+		require.Nil(t, data)
+	})
+}
+
+func TestClient_Errors(t *testing.T) {
+	t.Run("put error", func(t *testing.T) {
+		c := newClient(&mockS3Client{putErr: errors.New("injected put error")}, bucket, "", casLink,
+			&orbmocks.MetricsProvider{}, 0)
+
+		hl, err := c.Write([]byte("content"))
+		require.Error(t, err)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "injected put error")
+		require.Empty(t, hl)
+	})
+
+	t.Run("get error", func(t *testing.T) {
+		c := newClient(&mockS3Client{getErr: errors.New("injected get error")}, bucket, "", casLink,
+			&orbmocks.MetricsProvider{}, 0)
+
+		data, err := c.Read("uEiCCQWmOzsPbjQAH1kcHTSVNhWbnX8FLNkgRjsFJD0xOFg")
+		require.Error(t, err)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "injected get error")
+		require.Nil(t, data)
+	})
+
+	t.Run("bucket not found", func(t *testing.T) {
+		server := newMockS3Server(bucket)
+		defer server.Close()
+
+		c := New(newTestSession(t, server.URL), "invalid", "", casLink, &orbmocks.MetricsProvider{}, 0)
+
+		hl, err := c.Write([]byte("content"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "NoSuchBucket")
+		require.Empty(t, hl)
+	})
+}
+
+func newTestSession(t *testing.T, endpoint string) *session.Session {
+	t.Helper()
+
+	s, err := session.NewSession(&aws.Config{
+		Endpoint:         aws.String(endpoint),
+		Region:           aws.String("us-east-1"),
+		Credentials:      credentials.NewStaticCredentials("access-key", "secret-key", ""),
+		S3ForcePathStyle: aws.Bool(true),
+		DisableSSL:       aws.Bool(true),
+	})
+	require.NoError(t, err)
+
+	return s
+}
+
+// mockS3Server is a minimal stand-in for an S3-compatible object store (such as MinIO) which supports
+// path-style PUT and GET of objects.
+type mockS3Server struct {
+	*httptest.Server
+
+	bucket  string
+	mutex   sync.RWMutex
+	objects map[string][]byte
+}
+
+func newMockS3Server(bucket string) *mockS3Server {
+	s := &mockS3Server{
+		bucket:  bucket,
+		objects: make(map[string][]byte),
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+
+	return s
+}
+
+func (s *mockS3Server) handle(w http.ResponseWriter, r *http.Request) {
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+
+	if parts[0] != s.bucket {
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket")
+
+		return
+	}
+
+	if len(parts) < 2 || parts[1] == "" {
+		writeS3Error(w, http.StatusBadRequest, "InvalidRequest")
+
+		return
+	}
+
+	key := parts[1]
+
+	switch r.Method {
+	case http.MethodPut:
+		content, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeS3Error(w, http.StatusInternalServerError, "InternalError")
+
+			return
+		}
+
+		s.mutex.Lock()
+		s.objects[key] = content
+		s.mutex.Unlock()
+
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		s.mutex.RLock()
+		content, ok := s.objects[key]
+		s.mutex.RUnlock()
+
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, s3.ErrCodeNoSuchKey)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+
+		if _, err := w.Write(content); err != nil {
+			panic(err)
+		}
+	default:
+		writeS3Error(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+
+	if _, err := w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><Error><Code>` + code +
+		`</Code><Message>` + code + `</Message></Error>`)); err != nil {
+		panic(err)
+	}
+}
+
+type mockS3Client struct {
+	putErr error
+	getErr error
+}
+
+func (m *mockS3Client) PutObject(*s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+	return &s3.PutObjectOutput{}, m.putErr
+}
+
+func (m *mockS3Client) GetObject(*s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	return nil, m.getErr
+}
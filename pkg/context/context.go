@@ -7,9 +7,14 @@ SPDX-License-Identifier: Apache-2.0
 package context
 
 import (
+	"context"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
 	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
 	"github.com/trustbloc/sidetree-core-go/pkg/batch"
 	"github.com/trustbloc/sidetree-core-go/pkg/batch/cutter"
+
+	"github.com/trustbloc/orb/pkg/maintenance"
 )
 
 // New returns a new server context.
@@ -18,6 +23,7 @@ func New(pc protocol.Client, aw batch.AnchorWriter, opQueue cutter.OperationQueu
 		ProtocolClient: pc,
 		AnchorWriter:   aw,
 		OpQueue:        opQueue,
+		gate:           maintenance.NewGate(),
 	}
 }
 
@@ -26,6 +32,8 @@ type ServerContext struct {
 	ProtocolClient protocol.Client
 	AnchorWriter   batch.AnchorWriter
 	OpQueue        cutter.OperationQueue
+
+	gate *maintenance.Gate
 }
 
 // Protocol returns the ProtocolClient.
@@ -33,12 +41,41 @@ func (m *ServerContext) Protocol() protocol.Client {
 	return m.ProtocolClient
 }
 
-// Anchor returns the anchor writer.
+// Anchor returns the anchor writer. Anchors are not written by the batch writer while the context is paused.
 func (m *ServerContext) Anchor() batch.AnchorWriter {
-	return m.AnchorWriter
+	if m.AnchorWriter == nil {
+		return nil
+	}
+
+	return &pausableAnchorWriter{AnchorWriter: m.AnchorWriter, gate: m.gate}
 }
 
 // OperationQueue returns the queue containing the pending operations.
 func (m *ServerContext) OperationQueue() cutter.OperationQueue {
 	return m.OpQueue
 }
+
+// Pause pauses the batch writer, i.e. it waits for the anchors that are currently being written and blocks
+// the batch writer from writing new anchors until Resume is called.
+func (m *ServerContext) Pause(ctx context.Context) error {
+	return m.gate.Pause(ctx)
+}
+
+// Resume resumes the batch writer.
+func (m *ServerContext) Resume() {
+	m.gate.Resume()
+}
+
+type pausableAnchorWriter struct {
+	batch.AnchorWriter
+
+	gate *maintenance.Gate
+}
+
+func (w *pausableAnchorWriter) WriteAnchor(anchor string, artifacts []*protocol.AnchorDocument,
+	ops []*operation.Reference, protocolVersion uint64) error {
+	done := w.gate.Enter()
+	defer done()
+
+	return w.AnchorWriter.WriteAnchor(anchor, artifacts, ops, protocolVersion)
+}
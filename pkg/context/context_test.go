@@ -7,9 +7,15 @@ SPDX-License-Identifier: Apache-2.0
 package context
 
 import (
+	"context"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/txn"
 	"github.com/trustbloc/sidetree-core-go/pkg/batch/opqueue"
 )
 
@@ -21,3 +27,47 @@ func TestNew(t *testing.T) {
 	require.Equal(t, nil, c.Protocol())
 	require.NotNil(t, c.OperationQueue())
 }
+
+func TestServerContext_PauseResume(t *testing.T) {
+	aw := &mockAnchorWriter{}
+
+	c := New(nil, aw, &opqueue.MemQueue{})
+
+	require.NoError(t, c.Pause(context.Background()))
+
+	errChan := make(chan error, 1)
+
+	go func() {
+		errChan <- c.Anchor().WriteAnchor("anchor", nil, nil, 0)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, int32(0), atomic.LoadInt32(&aw.numWrites))
+
+	c.Resume()
+
+	select {
+	case err := <-errChan:
+		require.NoError(t, err)
+		require.Equal(t, int32(1), atomic.LoadInt32(&aw.numWrites))
+	case <-time.After(time.Second):
+		t.Fatal("anchor should be written after the context is resumed")
+	}
+
+	ok, _ := c.Anchor().Read(0)
+	require.False(t, ok)
+}
+
+type mockAnchorWriter struct {
+	numWrites int32
+}
+
+func (m *mockAnchorWriter) WriteAnchor(string, []*protocol.AnchorDocument, []*operation.Reference, uint64) error {
+	atomic.AddInt32(&m.numWrites, 1)
+
+	return nil
+}
+
+func (m *mockAnchorWriter) Read(int) (bool, *txn.SidetreeTxn) {
+	return false, nil
+}
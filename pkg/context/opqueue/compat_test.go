@@ -0,0 +1,39 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package opqueue
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/internal/testutil"
+)
+
+// TestCompatibility replays operation messages that were recorded from previous releases. A recorded message
+// must be added to testdata/compat/operation whenever the schema version of the operation message changes.
+func TestCompatibility(t *testing.T) {
+	q := &Queue{unmarshal: json.Unmarshal}
+
+	for name, msg := range testutil.LoadRecordedMessages(t, "testdata/compat/operation") {
+		msg := msg
+
+		t.Run(name, func(t *testing.T) {
+			op, err := q.decodeOperationMessage(msg)
+			require.NoError(t, err)
+			require.NotEmpty(t, op.ID)
+			require.NotNil(t, op.Operation)
+			require.NotEmpty(t, op.Operation.OperationRequest)
+			require.NotEmpty(t, op.Operation.UniqueSuffix)
+			require.NotEmpty(t, op.Operation.Namespace)
+			require.NotEmpty(t, op.Operation.AnchorOrigin)
+			require.NotZero(t, op.Operation.ProtocolVersion)
+			require.NotZero(t, op.Retries)
+		})
+	}
+}
@@ -14,14 +14,15 @@ import (
 	"sync"
 	"time"
 
-	"github.com/ThreeDotsLabs/watermill"
 	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/google/uuid"
 	"github.com/hyperledger/aries-framework-go/spi/storage"
 	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
 
 	"github.com/trustbloc/orb/internal/pkg/log"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
 	"github.com/trustbloc/orb/pkg/lifecycle"
+	"github.com/trustbloc/orb/pkg/pubsub/envelope"
 	"github.com/trustbloc/orb/pkg/pubsub/spi"
 	"github.com/trustbloc/orb/pkg/store"
 )
@@ -42,8 +43,14 @@ const (
 	defaultRetryInitialDelay    = 2 * time.Second
 	defaultMaxRetryDelay        = 30 * time.Second
 	defaultRetryMultiplier      = 1.5
+
+	pausePollInterval = 50 * time.Millisecond
 )
 
+// operationSchema is the schema of the operation message. The version must be incremented (and an upcaster
+// added) whenever the OperationMessage structure changes in a way that isn't backward compatible.
+var operationSchema = envelope.New("operation", 1)
+
 type pubSub interface {
 	SubscribeWithOpts(ctx context.Context, topic string, opts ...spi.Option) (<-chan *message.Message, error)
 	PublishWithOpts(topic string, msg *message.Message, opts ...spi.Option) error
@@ -134,6 +141,9 @@ type Queue struct {
 	maxRedeliveryInterval     time.Duration
 	redeliveryMultiplier      float64
 	logger                    *log.Log
+	paused                    bool
+	inFlight                  int
+	stateChanged              chan struct{}
 }
 
 // New returns a new operation queue.
@@ -174,6 +184,7 @@ func New(cfg Config, pubSub pubSub, p storage.Provider, taskMgr taskManager, met
 		redeliveryMultiplier:      cfg.RetriesMultiplier,
 		maxRedeliveryInterval:     cfg.RetriesMaxDelay,
 		logger:                    logger,
+		stateChanged:              make(chan struct{}, 1),
 	}
 
 	q.Lifecycle = lifecycle.New("operation-queue", lifecycle.WithStart(q.start))
@@ -222,7 +233,7 @@ func (q *Queue) publish(op *OperationMessage) (uint, error) {
 		return 0, fmt.Errorf("marshall queued operation: %w", err)
 	}
 
-	msg := message.NewMessage(watermill.NewUUID(), b)
+	msg := operationSchema.NewMessage(b)
 
 	delay := q.getDeliveryDelay(op.Retries)
 
@@ -247,6 +258,10 @@ func (q *Queue) Peek(num uint) (operation.QueuedOperationsAtTime, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
+	if q.paused {
+		return nil, nil
+	}
+
 	n := int(num)
 	if len(q.pending) < n {
 		n = len(q.pending)
@@ -274,7 +289,7 @@ func (q *Queue) Remove(num uint) (ops operation.QueuedOperationsAtTime, ack func
 		n = len(q.pending)
 	}
 
-	if n == 0 {
+	if n == 0 || q.paused {
 		return nil,
 			func() uint { return 0 },
 			func() {}, nil
@@ -283,9 +298,27 @@ func (q *Queue) Remove(num uint) (ops operation.QueuedOperationsAtTime, ack func
 	items := q.pending[0:n]
 	q.pending = q.pending[n:]
 
+	// The batch is in flight until it is either acknowledged or rolled back.
+	q.inFlight++
+
 	q.logger.Debug("Removed operations.", log.WithTotal(len(items)))
 
-	return q.asQueuedOperations(items), q.newAckFunc(items), q.newNackFunc(items), nil
+	done := q.newDoneFunc()
+
+	ackFunc := q.newAckFunc(items)
+	nackFunc := q.newNackFunc(items)
+
+	return q.asQueuedOperations(items),
+		func() uint {
+			defer done()
+
+			return ackFunc()
+		},
+		func() {
+			defer done()
+
+			nackFunc()
+		}, nil
 }
 
 // Len returns the length of the pending queue.
@@ -300,6 +333,104 @@ func (q *Queue) Len() uint {
 	return uint(len(q.pending))
 }
 
+// Pause quiesces the queue. New operations are no longer consumed from the message broker (they remain in
+// the broker until the queue is resumed) and no operations are handed to the batch writer. Pause blocks until
+// all batches which are currently being processed by the batch writer have been either acknowledged or
+// rolled back, so that no further writes are made by the queue or the batch writer after Pause returns.
+// If the given context is done before the queue becomes idle then the queue is resumed and an error is returned.
+func (q *Queue) Pause(ctx context.Context) error {
+	q.mutex.Lock()
+	q.paused = true
+	q.mutex.Unlock()
+
+	q.notifyStateChanged()
+
+	q.logger.Info("Pausing operation queue...")
+
+	ticker := time.NewTicker(pausePollInterval)
+	defer ticker.Stop()
+
+	for {
+		if n := q.numInFlight(); n == 0 {
+			q.logger.Info("... operation queue paused.")
+
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			q.Resume()
+
+			return fmt.Errorf("wait for in-flight operations: %w", ctx.Err())
+		}
+	}
+}
+
+// Resume resumes processing of operations after the queue was paused.
+func (q *Queue) Resume() {
+	q.mutex.Lock()
+	q.paused = false
+	q.mutex.Unlock()
+
+	q.notifyStateChanged()
+
+	q.logger.Info("Resumed operation queue.")
+}
+
+// Paused returns true if the queue is paused.
+func (q *Queue) Paused() bool {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	return q.paused
+}
+
+func (q *Queue) notifyStateChanged() {
+	select {
+	case q.stateChanged <- struct{}{}:
+	default:
+		// The listener has already been notified.
+	}
+}
+
+func (q *Queue) numInFlight() int {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	return q.inFlight
+}
+
+// beginWork returns false if the queue is paused. Otherwise the work is counted as in flight
+// until the returned function is invoked.
+func (q *Queue) beginWork() (func(), bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.paused {
+		return nil, false
+	}
+
+	q.inFlight++
+
+	return q.newDoneFunc(), true
+}
+
+// newDoneFunc returns a function which decrements the in-flight count. The count is decremented only once,
+// regardless of how many times the function is invoked.
+func (q *Queue) newDoneFunc() func() {
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			q.mutex.Lock()
+			defer q.mutex.Unlock()
+
+			q.inFlight--
+		})
+	}
+}
+
 func (q *Queue) start() {
 	q.taskMgr.RegisterTask(taskID, q.taskMonitorInterval, q.monitorOtherServers)
 
@@ -314,8 +445,15 @@ func (q *Queue) listen() {
 	ticker := time.NewTicker(q.taskMonitorInterval)
 
 	for {
+		msgChan := q.msgChan
+
+		if q.Paused() {
+			// Leave the messages in the broker until the queue is resumed.
+			msgChan = nil
+		}
+
 		select {
-		case msg, ok := <-q.msgChan:
+		case msg, ok := <-msgChan:
 			if !ok {
 				q.logger.Debug("Message listener stopped")
 
@@ -324,6 +462,9 @@ func (q *Queue) listen() {
 
 			q.handleMessage(msg)
 
+		case <-q.stateChanged:
+			// The queue was either paused or resumed.
+
 		case <-ticker.C:
 			// Update the task time so that other instances don't think I'm down.
 			if err := q.updateTaskTime(q.serverInstanceID); err != nil {
@@ -336,10 +477,28 @@ func (q *Queue) listen() {
 func (q *Queue) handleMessage(msg *message.Message) {
 	q.logger.Debug("Handling operation message", log.WithMessageID(msg.UUID))
 
-	op := &OperationMessage{}
+	done, ok := q.beginWork()
+	if !ok {
+		q.logger.Debug("Operation queue is paused. Message will be nacked and retried.", log.WithMessageID(msg.UUID))
+
+		msg.Nack()
 
-	err := q.unmarshal(msg.Payload, op)
+		return
+	}
+
+	defer done()
+
+	op, err := q.decodeOperationMessage(msg)
 	if err != nil {
+		if orberrors.IsTransient(err) {
+			q.logger.Warn("Operation message could not be decoded due to a transient error. "+
+				"Message will be nacked and retried.", log.WithMessageID(msg.UUID), log.WithError(err))
+
+			msg.Nack()
+
+			return
+		}
+
 		q.logger.Error("Error unmarshalling operation message payload.",
 			log.WithMessageID(msg.UUID), log.WithError(err))
 
@@ -393,6 +552,23 @@ func (q *Queue) handleMessage(msg *message.Message) {
 	msg.Ack()
 }
 
+// decodeOperationMessage upcasts the payload of the given message to the current schema version
+// and unmarshals it.
+func (q *Queue) decodeOperationMessage(msg *message.Message) (*OperationMessage, error) {
+	payload, err := operationSchema.Payload(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	op := &OperationMessage{}
+
+	if err := q.unmarshal(payload, op); err != nil {
+		return nil, fmt.Errorf("unmarshal operation message: %w", err)
+	}
+
+	return op, nil
+}
+
 func (q *Queue) newAckFunc(items []*queuedOperation) func() uint {
 	return func() uint {
 		if err := q.deleteOperations(items); err != nil {
@@ -465,6 +641,12 @@ func (q *Queue) newNackFunc(items []*queuedOperation) func() {
 }
 
 func (q *Queue) monitorOtherServers() {
+	if q.Paused() {
+		q.logger.Debug("Operation queue is paused. Not monitoring other servers.")
+
+		return
+	}
+
 	it, err := q.store.Query(tagOpQueueTask)
 	if err != nil {
 		q.logger.Warn("Error querying for operation queue tasks", log.WithError(err))
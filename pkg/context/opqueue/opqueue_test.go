@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package opqueue
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -503,6 +504,77 @@ func TestRepostWithMaxRetries(t *testing.T) {
 	require.Emptyf(t, removedOps, "no operations should have been remaining since the max retry count was reached")
 }
 
+func TestQueue_Pause(t *testing.T) {
+	ps := mempubsub.New(mempubsub.DefaultConfig())
+	defer ps.Stop()
+
+	q, err := New(Config{TaskMonitorInterval: time.Second}, ps, storage.NewMockStoreProvider(),
+		servicemocks.NewTaskManager("taskmgr1"), &mocks.MetricsProvider{})
+	require.NoError(t, err)
+
+	q.Start()
+	defer q.Stop()
+
+	operations := newProcessedOperations(4)
+
+	for _, op := range operations {
+		_, err = q.Add(op.op, 100)
+		require.NoError(t, err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	require.Equal(t, uint(4), q.Len())
+
+	removedOps, ack, _, err := q.Remove(2)
+	require.NoError(t, err)
+	require.Len(t, removedOps, 2)
+
+	t.Run("Timed out waiting for in-flight batch", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		err := q.Pause(ctx)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "wait for in-flight operations")
+		require.False(t, q.Paused())
+	})
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+
+		ack()
+	}()
+
+	require.NoError(t, q.Pause(context.Background()))
+	require.True(t, q.Paused())
+
+	ops, err := q.Peek(10)
+	require.NoError(t, err)
+	require.Empty(t, ops)
+
+	removedOps, _, _, err = q.Remove(10)
+	require.NoError(t, err)
+	require.Empty(t, removedOps)
+
+	// Operations that are added while the queue is paused remain in the broker.
+	_, err = q.Add(&operation.QueuedOperation{UniqueSuffix: "op-paused"}, 100)
+	require.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+
+	q.Resume()
+	require.False(t, q.Paused())
+
+	time.Sleep(100 * time.Millisecond)
+
+	removedOps, ack, _, err = q.Remove(10)
+	require.NoError(t, err)
+	require.Len(t, removedOps, 3)
+
+	ack()
+}
+
 func TestMain(m *testing.M) {
 	code := 1
 
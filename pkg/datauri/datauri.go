@@ -10,12 +10,15 @@ import (
 	"bytes"
 	"compress/gzip"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/url"
 	"strings"
 
+	"github.com/fxamacker/cbor/v2"
+	"github.com/klauspost/compress/zstd"
 	"github.com/trustbloc/sidetree-core-go/pkg/canonicalizer"
 )
 
@@ -27,10 +30,48 @@ const (
 	MediaTypeDataURIJSON MediaType = "application/json"
 	// MediaTypeDataURIGzipBase64 indicates that the contents of the data URL is compressed with gzip and base64-encoded.
 	MediaTypeDataURIGzipBase64 MediaType = "application/gzip;base64"
+	// MediaTypeDataURIZstdBase64 indicates that the contents of the data URL is compressed with zstd and base64-encoded.
+	MediaTypeDataURIZstdBase64 MediaType = "application/zstd;base64"
+	// MediaTypeDataURICBORBase64 indicates that the JSON contents of the data URL is encoded as CBOR and base64-encoded.
+	MediaTypeDataURICBORBase64 MediaType = "application/cbor;base64"
+	// MediaTypeDataURICBORZstdBase64 indicates that the JSON contents of the data URL is encoded as CBOR,
+	// compressed with zstd and base64-encoded.
+	MediaTypeDataURICBORZstdBase64 MediaType = "application/cbor+zstd;base64"
 )
 
 const numDataURISegments = 2
 
+var (
+	// The encoder and decoder are safe for concurrent use with EncodeAll and DecodeAll. Errors are
+	// ignored since they are only returned for invalid options.
+	zstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedBestCompression))
+	zstdDecoder, _ = zstd.NewReader(nil)
+
+	cborEncMode = newCBOREncMode()
+)
+
+// SupportedMediaTypes returns the media types that are supported for data URIs.
+func SupportedMediaTypes() []MediaType {
+	return []MediaType{
+		MediaTypeDataURIJSON,
+		MediaTypeDataURIGzipBase64,
+		MediaTypeDataURIZstdBase64,
+		MediaTypeDataURICBORBase64,
+		MediaTypeDataURICBORZstdBase64,
+	}
+}
+
+// IsSupportedMediaType returns true if the given media type is supported for data URIs.
+func IsSupportedMediaType(mediaType MediaType) bool {
+	for _, mt := range SupportedMediaTypes() {
+		if mt == mediaType {
+			return true
+		}
+	}
+
+	return false
+}
+
 // New encodes the given content using the given media type and returns
 // a data URI with the encoded data. For example: 'data:application/gzip;base64,H4sIAbAAvAAA...'.
 func New(content []byte, dataType MediaType) (*url.URL, error) {
@@ -79,6 +120,22 @@ func encode(content []byte, mediaType MediaType) (string, error) {
 	switch mediaType {
 	case MediaTypeDataURIGzipBase64:
 		return GzipCompress(content)
+	case MediaTypeDataURIZstdBase64:
+		return ZstdCompress(content), nil
+	case MediaTypeDataURICBORBase64:
+		cborBytes, err := jsonToCBOR(content)
+		if err != nil {
+			return "", err
+		}
+
+		return base64.StdEncoding.EncodeToString(cborBytes), nil
+	case MediaTypeDataURICBORZstdBase64:
+		cborBytes, err := jsonToCBOR(content)
+		if err != nil {
+			return "", err
+		}
+
+		return ZstdCompress(cborBytes), nil
 	case MediaTypeDataURIJSON:
 		return url.QueryEscape(string(content)), nil
 	case "":
@@ -93,6 +150,22 @@ func decode(content string, mediaType MediaType) ([]byte, error) {
 	switch mediaType {
 	case MediaTypeDataURIGzipBase64:
 		return GzipDecompress(content)
+	case MediaTypeDataURIZstdBase64:
+		return ZstdDecompress(content)
+	case MediaTypeDataURICBORBase64:
+		cborBytes, err := base64.StdEncoding.DecodeString(content)
+		if err != nil {
+			return nil, fmt.Errorf("base64 decode content: %w", err)
+		}
+
+		return cborToJSON(cborBytes)
+	case MediaTypeDataURICBORZstdBase64:
+		cborBytes, err := ZstdDecompress(content)
+		if err != nil {
+			return nil, err
+		}
+
+		return cborToJSON(cborBytes)
 	case MediaTypeDataURIJSON:
 		c, err := url.QueryUnescape(content)
 		if err != nil {
@@ -142,3 +215,149 @@ func GzipDecompress(content string) ([]byte, error) {
 
 	return decompressedBytes, nil
 }
+
+// ZstdCompress compresses the given content with zstd and returns a base64-encoded string.
+func ZstdCompress(docBytes []byte) string {
+	return base64.StdEncoding.EncodeToString(zstdEncoder.EncodeAll(docBytes, nil))
+}
+
+// ZstdDecompress decompresses the given base64-encoded string with zstd.
+func ZstdDecompress(content string) ([]byte, error) {
+	compressedBytes, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		return nil, fmt.Errorf("base64 decode content: %w", err)
+	}
+
+	decompressedBytes, err := zstdDecoder.DecodeAll(compressedBytes, nil)
+	if err != nil {
+		return nil, fmt.Errorf("zstd decompress: %w", err)
+	}
+
+	return decompressedBytes, nil
+}
+
+// jsonToCBOR converts the given JSON document to CBOR. Integers are encoded as CBOR integers and
+// floating point numbers are encoded using the shortest float that preserves the value.
+func jsonToCBOR(content []byte) ([]byte, error) {
+	d := json.NewDecoder(bytes.NewReader(content))
+	d.UseNumber()
+
+	var doc interface{}
+
+	if err := d.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("unmarshal JSON content: %w", err)
+	}
+
+	cborBytes, err := cborEncMode.Marshal(fromJSONNumbers(doc))
+	if err != nil {
+		return nil, fmt.Errorf("marshal CBOR content: %w", err)
+	}
+
+	return cborBytes, nil
+}
+
+// cborToJSON converts the given CBOR document to canonical JSON. Since JSON content is always
+// canonicalized before it is embedded in a data URI, the resulting bytes are the same as the original.
+func cborToJSON(content []byte) ([]byte, error) {
+	var doc interface{}
+
+	if err := cbor.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("unmarshal CBOR content: %w", err)
+	}
+
+	doc, err := toJSONValue(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonBytes, err := canonicalizer.MarshalCanonical(doc)
+	if err != nil {
+		return nil, fmt.Errorf("marshal canonical: %w", err)
+	}
+
+	return jsonBytes, nil
+}
+
+// fromJSONNumbers replaces all json.Number values in the given document with either an int64
+// (if the number is an integer) or a float64.
+func fromJSONNumbers(doc interface{}) interface{} {
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			v[key] = fromJSONNumbers(value)
+		}
+
+		return v
+	case []interface{}:
+		for i, value := range v {
+			v[i] = fromJSONNumbers(value)
+		}
+
+		return v
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+
+		f, err := v.Float64()
+		if err != nil {
+			// Should never happen since the number was parsed by the JSON decoder.
+			return v.String()
+		}
+
+		return f
+	default:
+		return v
+	}
+}
+
+// toJSONValue converts the maps in a decoded CBOR document (which are keyed by interface{}) to
+// maps keyed by string so that the document may be marshalled to JSON.
+func toJSONValue(doc interface{}) (interface{}, error) {
+	switch v := doc.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+
+		for key, value := range v {
+			strKey, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("unsupported CBOR map key type [%T]", key)
+			}
+
+			jsonValue, err := toJSONValue(value)
+			if err != nil {
+				return nil, err
+			}
+
+			m[strKey] = jsonValue
+		}
+
+		return m, nil
+	case []interface{}:
+		for i, value := range v {
+			jsonValue, err := toJSONValue(value)
+			if err != nil {
+				return nil, err
+			}
+
+			v[i] = jsonValue
+		}
+
+		return v, nil
+	default:
+		return v, nil
+	}
+}
+
+func newCBOREncMode() cbor.EncMode {
+	em, err := cbor.EncOptions{
+		Sort:          cbor.SortCanonical,
+		ShortestFloat: cbor.ShortestFloat16,
+	}.EncMode()
+	if err != nil {
+		// Should never happen since the options are static.
+		panic(fmt.Errorf("create CBOR encoding mode: %w", err))
+	}
+
+	return em
+}
@@ -0,0 +1,97 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package datauri
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/canonicalizer"
+)
+
+// The benchmarks report the size of the resulting data URI (uri-bytes) in addition to the usual timing
+// metrics so that the compactness of each media type may be compared. For example:
+//
+//	go test -run=^$ -bench=. ./pkg/datauri/
+func BenchmarkNew(b *testing.B) {
+	for _, numItems := range []int{1, 10, 100} {
+		content := newAnchorContent(b, numItems)
+
+		for _, mediaType := range SupportedMediaTypes() {
+			mt := mediaType
+
+			b.Run(fmt.Sprintf("%s/items=%d", mt, numItems), func(b *testing.B) {
+				var size int
+
+				for i := 0; i < b.N; i++ {
+					u, err := New(content, mt)
+					require.NoError(b, err)
+
+					size = len(u.String())
+				}
+
+				b.ReportMetric(float64(len(content)), "content-bytes")
+				b.ReportMetric(float64(size), "uri-bytes")
+			})
+		}
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	for _, numItems := range []int{1, 10, 100} {
+		content := newAnchorContent(b, numItems)
+
+		for _, mediaType := range SupportedMediaTypes() {
+			mt := mediaType
+
+			u, err := New(content, mt)
+			require.NoError(b, err)
+
+			b.Run(fmt.Sprintf("%s/items=%d", mt, numItems), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					_, err := Decode(u)
+					require.NoError(b, err)
+				}
+			})
+		}
+	}
+}
+
+// newAnchorContent returns canonical JSON that resembles the content of an anchor
+// linkset with the given number of DID items.
+func newAnchorContent(b *testing.B, numItems int) []byte {
+	b.Helper()
+
+	type item struct {
+		Href     string   `json:"href"`
+		Previous []string `json:"previous,omitempty"`
+	}
+
+	items := make([]item, numItems)
+
+	for i := range items {
+		items[i] = item{
+			Href:     fmt.Sprintf("did:orb:uAAA:EiBfWqeAJfENeHLABsYIYmsIqtk-bsmvJmoR6IgI%010d", i),
+			Previous: []string{fmt.Sprintf("hl:uEiAVAPhjewMUvawD0gl-MYMzvTPVuUA1DpO1SmB%010d", i)},
+		}
+	}
+
+	content, err := canonicalizer.MarshalCanonical(map[string]interface{}{
+		"linkset": []interface{}{
+			map[string]interface{}{
+				"anchor":  "hl:uEiCVVS-n0wx0OfeEXBM9jcGNOcMEArYYWPIxk5D_l96ySg",
+				"author":  []interface{}{map[string]interface{}{"href": "https://orb.domain2.com/services/orb"}},
+				"item":    items,
+				"profile": []interface{}{map[string]interface{}{"href": "https://w3id.org/orb#v0"}},
+			},
+		},
+	})
+	require.NoError(b, err)
+
+	return content
+}
@@ -7,10 +7,14 @@ SPDX-License-Identifier: Apache-2.0
 package datauri
 
 import (
+	"encoding/base64"
+	"fmt"
 	"net/url"
 	"testing"
 
+	"github.com/fxamacker/cbor/v2"
 	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/canonicalizer"
 )
 
 func TestDataURI(t *testing.T) {
@@ -87,6 +91,95 @@ func TestDataURI(t *testing.T) {
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "illegal base64 data")
 	})
+
+	t.Run("zstd -> success", func(t *testing.T) {
+		u, err := New([]byte(content), MediaTypeDataURIZstdBase64)
+		require.NoError(t, err)
+		require.Equal(t, "data", u.Scheme)
+
+		contentBytes, err := Decode(u)
+		require.NoError(t, err)
+		require.Equal(t, content, string(contentBytes))
+	})
+
+	t.Run("zstd decompress -> error", func(t *testing.T) {
+		_, err := ZstdDecompress("sfsdf")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "illegal base64 data")
+
+		_, err = ZstdDecompress(base64.StdEncoding.EncodeToString([]byte("not zstd")))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "zstd decompress")
+	})
+
+	for _, mediaType := range []MediaType{MediaTypeDataURICBORBase64, MediaTypeDataURICBORZstdBase64} {
+		mt := mediaType
+
+		t.Run(fmt.Sprintf("%s -> success", mt), func(t *testing.T) {
+			doc := []byte(`{"array":[1,-2,3.25,1e+21,"str",true,null,{"nested":"<value>"}],"field1":"value1","int":9007199254740991}`)
+
+			canonicalDoc, err := canonicalizer.MarshalCanonical(doc)
+			require.NoError(t, err)
+
+			u, err := New(canonicalDoc, mt)
+			require.NoError(t, err)
+
+			contentBytes, err := Decode(u)
+			require.NoError(t, err)
+			require.Equal(t, string(canonicalDoc), string(contentBytes))
+		})
+
+		t.Run(fmt.Sprintf("%s -> invalid JSON error", mt), func(t *testing.T) {
+			_, err := New([]byte("not JSON"), mt)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "unmarshal JSON content")
+		})
+	}
+
+	t.Run("CBOR -> decode error", func(t *testing.T) {
+		u, err := url.Parse("data:application/cbor;base64,sfsdf")
+		require.NoError(t, err)
+
+		_, err = Decode(u)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "illegal base64 data")
+
+		u, err = url.Parse("data:application/cbor;base64," + base64.StdEncoding.EncodeToString([]byte{0xff}))
+		require.NoError(t, err)
+
+		_, err = Decode(u)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal CBOR content")
+
+		u, err = url.Parse("data:application/cbor+zstd;base64,sfsdf")
+		require.NoError(t, err)
+
+		_, err = Decode(u)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "illegal base64 data")
+	})
+
+	t.Run("CBOR -> unsupported map key error", func(t *testing.T) {
+		cborBytes, err := cbor.Marshal(map[string]interface{}{
+			"field": []interface{}{map[int]string{1: "value"}},
+		})
+		require.NoError(t, err)
+
+		u, err := url.Parse("data:application/cbor;base64," + base64.StdEncoding.EncodeToString(cborBytes))
+		require.NoError(t, err)
+
+		_, err = Decode(u)
+		require.EqualError(t, err, "unsupported CBOR map key type [uint64]")
+	})
+}
+
+func TestIsSupportedMediaType(t *testing.T) {
+	for _, mt := range SupportedMediaTypes() {
+		require.True(t, IsSupportedMediaType(mt))
+	}
+
+	require.False(t, IsSupportedMediaType(""))
+	require.False(t, IsSupportedMediaType("application/unsupported"))
 }
 
 func TestMarshalCanonical(t *testing.T) {
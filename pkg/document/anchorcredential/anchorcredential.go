@@ -0,0 +1,62 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package anchorcredential
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/piprate/json-gold/ld"
+
+	"github.com/trustbloc/orb/pkg/anchor/graph"
+	anchorutil "github.com/trustbloc/orb/pkg/anchor/util"
+	"github.com/trustbloc/orb/pkg/hashlink"
+)
+
+// DIDAnchorGraph returns the anchors of a DID from the anchor graph.
+type DIDAnchorGraph interface {
+	GetDidAnchors(cid, suffix string) ([]graph.Anchor, error)
+}
+
+// Credential contains an anchor from the anchor graph along with its (parsed) verifiable credential.
+type Credential struct {
+	graph.Anchor
+
+	VC *verifiable.Credential
+}
+
+// Get returns all anchors of the given suffix (starting from the anchor with the given
+// reference) along with their verifiable credentials, mapped by anchor reference. The proofs of the
+// credentials are not verified.
+func Get(anchorGraph DIDAnchorGraph, suffix, latestRef string,
+	documentLoader ld.DocumentLoader) (map[string]*Credential, error) {
+	anchors, err := anchorGraph.GetDidAnchors(hashlink.GetHashLinkFromResourceHash(latestRef), suffix)
+	if err != nil {
+		return nil, fmt.Errorf("get DID anchors for reference [%s]: %w", latestRef, err)
+	}
+
+	credentials := make(map[string]*Credential)
+
+	for _, anchor := range anchors {
+		ref, err := hashlink.GetResourceHashFromHashLink(anchor.CID)
+		if err != nil {
+			return nil, fmt.Errorf("get resource hash from anchor [%s]: %w", anchor.CID, err)
+		}
+
+		vc, err := anchorutil.VerifiableCredentialFromAnchorLink(anchor.Info,
+			verifiable.WithDisabledProofCheck(),
+			verifiable.WithJSONLDDocumentLoader(documentLoader),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("get verifiable credential from anchor [%s]: %w", anchor.CID, err)
+		}
+
+		credentials[ref] = &Credential{Anchor: anchor, VC: vc}
+	}
+
+	return credentials, nil
+}
@@ -0,0 +1,64 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package anchorcredential
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/anchor/graph"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	"github.com/trustbloc/orb/pkg/internal/testutil"
+	"github.com/trustbloc/orb/pkg/linkset"
+	orbmocks "github.com/trustbloc/orb/pkg/mocks"
+)
+
+const anchorRef = "uEiBqkaTRFZScQsXTw8IDBSpVxiKGqjJCDUcgiwpcd2frLw"
+
+func TestGet(t *testing.T) {
+	docLoader := testutil.GetLoader(t)
+
+	t.Run("No anchors", func(t *testing.T) {
+		anchorGraph := &orbmocks.AnchorGraph{}
+
+		credentials, err := Get(anchorGraph, "suffix", anchorRef, docLoader)
+		require.NoError(t, err)
+		require.Empty(t, credentials)
+
+		cid, suffix := anchorGraph.GetDidAnchorsArgsForCall(0)
+		require.Equal(t, hashlink.GetHashLinkFromResourceHash(anchorRef), cid)
+		require.Equal(t, "suffix", suffix)
+	})
+
+	t.Run("Anchor graph error", func(t *testing.T) {
+		anchorGraph := &orbmocks.AnchorGraph{}
+		anchorGraph.GetDidAnchorsReturns(nil, errors.New("injected anchor graph error"))
+
+		_, err := Get(anchorGraph, "suffix", anchorRef, docLoader)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected anchor graph error")
+	})
+
+	t.Run("Invalid anchor", func(t *testing.T) {
+		anchorGraph := &orbmocks.AnchorGraph{}
+		anchorGraph.GetDidAnchorsReturns([]graph.Anchor{{CID: "invalid", Info: &linkset.Link{}}}, nil)
+
+		_, err := Get(anchorGraph, "suffix", anchorRef, docLoader)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get resource hash from anchor")
+
+		anchorGraph.GetDidAnchorsReturns([]graph.Anchor{
+			{CID: hashlink.GetHashLinkFromResourceHash(anchorRef), Info: &linkset.Link{}},
+		}, nil)
+
+		_, err = Get(anchorGraph, "suffix", anchorRef, docLoader)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get verifiable credential from anchor")
+	})
+}
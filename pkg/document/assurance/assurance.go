@@ -0,0 +1,124 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package assurance
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+)
+
+// MetadataProperty is the document metadata property which holds the assurance metadata.
+const MetadataProperty = "assurance"
+
+// Resolver resolves a DID document using only the operations whose anchors satisfy the given criteria.
+type Resolver interface {
+	ResolveDocumentWithAssurance(id string, criteria *Criteria,
+		opts ...document.ResolutionOption) (*document.ResolutionResult, error)
+}
+
+// Criteria contains the requirements that the anchor of an operation must satisfy in order for the
+// operation to be included in the resolution.
+type Criteria struct {
+	// Witnesses contains the witnesses (service IRIs or domains) which must all have provided a proof
+	// for the anchor.
+	Witnesses []string `json:"witnesses,omitempty"`
+
+	// Logs contains the VCT logs in which the anchor must have been logged (i.e. for each log, at least
+	// one proof must specify the log as its domain).
+	Logs []string `json:"logs,omitempty"`
+}
+
+// IsEmpty returns true if no requirements are specified.
+func (c *Criteria) IsEmpty() bool {
+	return c == nil || (len(c.Witnesses) == 0 && len(c.Logs) == 0)
+}
+
+// Evaluate returns the reasons why the given anchor proofs don't satisfy the criteria. An empty slice
+// is returned if the criteria are satisfied.
+func (c *Criteria) Evaluate(proofs []verifiable.Proof) []string {
+	var reasons []string
+
+	for _, witness := range c.Witnesses {
+		if !containsProof(proofs, "verificationMethod", witness) {
+			reasons = append(reasons, fmt.Sprintf("missing proof from witness [%s]", witness))
+		}
+	}
+
+	for _, log := range c.Logs {
+		if !containsProof(proofs, "domain", log) {
+			reasons = append(reasons, fmt.Sprintf("not logged in VCT log [%s]", log))
+		}
+	}
+
+	return reasons
+}
+
+// SkippedOperation contains an operation which was excluded from the resolution and the reason why.
+type SkippedOperation struct {
+	Type               operation.Type `json:"type"`
+	CanonicalReference string         `json:"canonicalReference,omitempty"`
+	TransactionTime    uint64         `json:"transactionTime,omitempty"`
+	Reason             string         `json:"reason"`
+}
+
+// Metadata is added to the document metadata of a resolution that was performed with assurance criteria.
+type Metadata struct {
+	Criteria          *Criteria           `json:"criteria"`
+	SkippedOperations []*SkippedOperation `json:"skippedOperations,omitempty"`
+}
+
+// containsProof returns true if the given property of any of the proofs matches the given value.
+func containsProof(proofs []verifiable.Proof, property, value string) bool {
+	for _, proof := range proofs {
+		if v, ok := proof[property].(string); ok && matches(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matches returns true if the given proof value (verification method or domain) matches the required
+// value. If the required value is a URL then the proof value must either be equal to it or start with
+// it (e.g. a verification method of a witness service); otherwise the required value is treated as a
+// host which must match the host of the proof value.
+func matches(proofValue, required string) bool {
+	required = strings.TrimSuffix(required, "/")
+
+	if strings.Contains(required, "://") {
+		return proofValue == required || strings.HasPrefix(proofValue, required+"/") ||
+			strings.HasPrefix(proofValue, required+"#")
+	}
+
+	return strings.EqualFold(host(proofValue), required)
+}
+
+// host returns the host of the given URL or did:web DID.
+func host(value string) string {
+	if strings.HasPrefix(value, "did:web:") {
+		h := strings.SplitN(strings.TrimPrefix(value, "did:web:"), ":", 2)[0]
+		h = strings.SplitN(h, "#", 2)[0]
+
+		if unescaped, err := url.PathUnescape(h); err == nil {
+			return unescaped
+		}
+
+		return h
+	}
+
+	u, err := url.Parse(value)
+	if err != nil {
+		return ""
+	}
+
+	return u.Host
+}
@@ -0,0 +1,78 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package assurance
+
+import (
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCriteria_IsEmpty(t *testing.T) {
+	var c *Criteria
+
+	require.True(t, c.IsEmpty())
+	require.True(t, (&Criteria{}).IsEmpty())
+	require.False(t, (&Criteria{Witnesses: []string{"orb.domain2.com"}}).IsEmpty())
+	require.False(t, (&Criteria{Logs: []string{"https://vct.example.com/maple2020"}}).IsEmpty())
+}
+
+func TestCriteria_Evaluate(t *testing.T) {
+	proofs := []verifiable.Proof{
+		{
+			"verificationMethod": "https://orb.domain1.com/services/orb/keys/main-key",
+			"domain":             "https://vct.domain1.com/maple2020",
+		},
+		{
+			"verificationMethod": "did:web:orb.domain2.com%3A8443:services:orb#main-key",
+			"domain":             "https://vct.domain2.com/maple2020",
+		},
+		{
+			"verificationMethod": 123,
+		},
+	}
+
+	t.Run("Satisfied", func(t *testing.T) {
+		c := &Criteria{
+			Witnesses: []string{
+				"https://orb.domain1.com/services/orb", "orb.domain2.com:8443", "ORB.DOMAIN1.COM",
+			},
+			Logs: []string{"https://vct.domain1.com/maple2020/", "vct.domain2.com"},
+		}
+
+		require.Empty(t, c.Evaluate(proofs))
+	})
+
+	t.Run("Not satisfied", func(t *testing.T) {
+		c := &Criteria{
+			Witnesses: []string{"https://orb.domain1.com/services/orb2", "orb.domain3.com"},
+			Logs:      []string{"https://vct.domain3.com/maple2020", "::invalid"},
+		}
+
+		require.Equal(t, []string{
+			"missing proof from witness [https://orb.domain1.com/services/orb2]",
+			"missing proof from witness [orb.domain3.com]",
+			"not logged in VCT log [https://vct.domain3.com/maple2020]",
+			"not logged in VCT log [::invalid]",
+		}, c.Evaluate(proofs))
+	})
+
+	t.Run("No proofs", func(t *testing.T) {
+		c := &Criteria{Witnesses: []string{"orb.domain1.com"}}
+
+		require.Len(t, c.Evaluate(nil), 1)
+	})
+}
+
+func TestHost(t *testing.T) {
+	require.Equal(t, "orb.domain1.com", host("https://orb.domain1.com/services/orb"))
+	require.Equal(t, "orb.domain1.com", host("did:web:orb.domain1.com#key"))
+	require.Equal(t, "orb.domain1.com:8443", host("did:web:orb.domain1.com%3A8443:services:orb"))
+	require.Equal(t, "orb.domain1.com%zz", host("did:web:orb.domain1.com%zz"))
+	require.Equal(t, "", host("::invalid"))
+}
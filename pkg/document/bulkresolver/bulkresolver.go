@@ -0,0 +1,237 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bulkresolver
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/document/util"
+)
+
+var logger = log.New("bulk-resolver")
+
+const defaultMaxConcurrency = 10
+
+// OperationStore retrieves the published operations of a DID suffix.
+type OperationStore interface {
+	Get(suffix string) ([]*operation.AnchoredOperation, error)
+}
+
+// DocumentResolver resolves a DID document.
+type DocumentResolver interface {
+	ResolveDocument(id string, opts ...document.ResolutionOption) (*document.ResolutionResult, error)
+}
+
+// ResolverFactory returns a document resolver which reads published operations from the given operation store.
+type ResolverFactory func(opStore OperationStore) DocumentResolver
+
+type didAnchorStore interface {
+	GetBulk(suffixes []string) ([]string, error)
+}
+
+type operationStore interface {
+	OperationStore
+
+	GetBulk(suffixes []string) (map[string][]*operation.AnchoredOperation, error)
+}
+
+// Result contains the resolution result (or the error) of a single DID.
+type Result struct {
+	ID     string
+	Result *document.ResolutionResult
+	Err    error
+}
+
+// Option is a bulk resolver option.
+type Option func(r *Resolver)
+
+// WithMaxConcurrency sets the maximum number of DIDs which are resolved concurrently within a single request.
+func WithMaxConcurrency(value int) Option {
+	return func(r *Resolver) {
+		if value > 0 {
+			r.maxConcurrency = value
+		}
+	}
+}
+
+// Resolver resolves multiple DIDs at once. Instead of reading the DID anchor index and the operation store
+// once per DID, the latest anchors of all of the requested DIDs are retrieved in a single call and the operations
+// of the anchored DIDs are then retrieved in a single (batched) call. The DIDs are resolved from these operations.
+type Resolver struct {
+	namespace      string
+	didAnchors     didAnchorStore
+	opStore        operationStore
+	newResolver    ResolverFactory
+	maxConcurrency int
+}
+
+// New returns a new bulk resolver.
+func New(namespace string, didAnchors didAnchorStore, opStore operationStore, newResolver ResolverFactory,
+	opts ...Option) *Resolver {
+	r := &Resolver{
+		namespace:      namespace,
+		didAnchors:     didAnchors,
+		opStore:        opStore,
+		newResolver:    newResolver,
+		maxConcurrency: defaultMaxConcurrency,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// ResolveDocuments resolves the given DIDs and returns a result for each DID (in the same order as the given DIDs).
+// An error is returned only if the operations could not be retrieved from storage. Errors which are specific to
+// a DID are returned in the result of the DID.
+func (r *Resolver) ResolveDocuments(ids []string) ([]*Result, error) {
+	opStore, err := r.prefetch(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	resolver := r.newResolver(opStore)
+
+	results := make([]*Result, len(ids))
+
+	var wg sync.WaitGroup
+
+	sem := make(chan struct{}, r.maxConcurrency)
+
+	for i, id := range ids {
+		wg.Add(1)
+
+		sem <- struct{}{}
+
+		go func(i int, id string) {
+			defer func() {
+				<-sem
+
+				wg.Done()
+			}()
+
+			result, err := resolver.ResolveDocument(id)
+			if err != nil {
+				logger.Debug("Error resolving DID", log.WithDID(id), log.WithError(err))
+			}
+
+			results[i] = &Result{ID: id, Result: result, Err: err}
+		}(i, id)
+	}
+
+	wg.Wait()
+
+	return results, nil
+}
+
+// prefetch retrieves the operations of all of the given DIDs which have been anchored and returns an operation
+// store which serves these operations. DIDs which aren't in the DID anchor index (for example, DIDs in a
+// different namespace or DIDs whose anchor is still being processed) are read from the underlying store.
+func (r *Resolver) prefetch(ids []string) (*prefetchedStore, error) {
+	suffixes := r.getSuffixes(ids)
+
+	store := &prefetchedStore{
+		OperationStore: r.opStore,
+		ops:            make(map[string][]*operation.AnchoredOperation),
+	}
+
+	if len(suffixes) == 0 {
+		return store, nil
+	}
+
+	anchors, err := r.didAnchors.GetBulk(suffixes)
+	if err != nil {
+		return nil, fmt.Errorf("get DID anchors: %w", err)
+	}
+
+	var anchoredSuffixes []string
+
+	for i, anchor := range anchors {
+		if anchor != "" {
+			anchoredSuffixes = append(anchoredSuffixes, suffixes[i])
+		}
+	}
+
+	if len(anchoredSuffixes) == 0 {
+		return store, nil
+	}
+
+	opsBySuffix, err := r.opStore.GetBulk(anchoredSuffixes)
+	if err != nil {
+		return nil, fmt.Errorf("get operations: %w", err)
+	}
+
+	for _, suffix := range anchoredSuffixes {
+		store.ops[suffix] = opsBySuffix[suffix]
+	}
+
+	logger.Debug("Prefetched operations for anchored suffixes", log.WithSuffixes(anchoredSuffixes...))
+
+	return store, nil
+}
+
+// getSuffixes returns the unique suffixes of the given DIDs which are in the configured namespace.
+func (r *Resolver) getSuffixes(ids []string) []string {
+	var suffixes []string
+
+	exists := make(map[string]struct{})
+
+	for _, id := range ids {
+		if !strings.HasPrefix(id, r.namespace+":") {
+			continue
+		}
+
+		suffix, err := util.GetSuffix(id)
+		if err != nil {
+			continue
+		}
+
+		if _, ok := exists[suffix]; ok {
+			continue
+		}
+
+		exists[suffix] = struct{}{}
+
+		suffixes = append(suffixes, suffix)
+	}
+
+	return suffixes
+}
+
+// prefetchedStore serves the operations which were retrieved in bulk and falls back to the underlying
+// operation store for all other suffixes.
+type prefetchedStore struct {
+	OperationStore
+
+	ops map[string][]*operation.AnchoredOperation
+}
+
+func (s *prefetchedStore) Get(suffix string) ([]*operation.AnchoredOperation, error) {
+	ops, ok := s.ops[suffix]
+	if !ok {
+		return s.OperationStore.Get(suffix)
+	}
+
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("suffix[%s] not found in the store", suffix)
+	}
+
+	// The operations are sorted in place by the operation processor, so return a copy in case the
+	// same suffix is resolved more than once.
+	opsCopy := make([]*operation.AnchoredOperation, len(ops))
+	copy(opsCopy, ops)
+
+	return opsCopy, nil
+}
@@ -0,0 +1,245 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bulkresolver
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+)
+
+const (
+	namespace = "did:orb"
+
+	suffix1 = "EiDJpL-xeSE4kVgoGjaQm_OX5y5Dy3Nfr1Ld7cdMZwqb0A"
+	suffix2 = "EiBn4uIWbAQsJ6RJX6YFnrDW8lqIMFWsSqpd8dVl9Lq8Tg"
+	suffix3 = "EiA0vZMyECEFGGZBhEX12NPDxsUQvi3E0oNrIH8eX9ewXw"
+
+	did1 = namespace + ":uAAA:" + suffix1
+	did2 = namespace + ":uAAA:" + suffix2
+	did3 = namespace + ":uAAA:" + suffix3
+)
+
+func TestResolver_ResolveDocuments(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		didAnchors := &mockDIDAnchorStore{anchors: map[string]string{suffix1: "hl:1", suffix2: "hl:2"}}
+
+		opStore := &mockOperationStore{
+			ops: map[string][]*operation.AnchoredOperation{
+				suffix1: {{UniqueSuffix: suffix1}},
+				suffix3: {{UniqueSuffix: suffix3}},
+			},
+		}
+
+		r := New(namespace, didAnchors, opStore, func(s OperationStore) DocumentResolver {
+			return &mockResolver{opStore: s}
+		}, WithMaxConcurrency(2))
+
+		results, err := r.ResolveDocuments([]string{did1, did2, did3, "did:web:example.com", did1})
+		require.NoError(t, err)
+		require.Len(t, results, 5)
+
+		require.Equal(t, did1, results[0].ID)
+		require.NoError(t, results[0].Err)
+		require.Equal(t, did1, results[0].Result.Document.ID())
+
+		// The DID is in the anchor index but the operations weren't found.
+		require.Equal(t, did2, results[1].ID)
+		require.Error(t, results[1].Err)
+		require.Contains(t, results[1].Err.Error(), "not found")
+
+		// The DID isn't in the anchor index so the operations are read from the underlying store.
+		require.Equal(t, did3, results[2].ID)
+		require.NoError(t, results[2].Err)
+
+		require.Equal(t, "did:web:example.com", results[3].ID)
+		require.Error(t, results[3].Err)
+		require.Contains(t, results[3].Err.Error(), "method not supported")
+
+		require.Equal(t, did1, results[4].ID)
+		require.NoError(t, results[4].Err)
+
+		require.Equal(t, []string{suffix1, suffix2, suffix3}, didAnchors.suffixes)
+		require.Equal(t, []string{suffix1, suffix2}, opStore.bulkSuffixes)
+		require.Equal(t, []string{suffix3}, opStore.getSuffixes())
+	})
+
+	t.Run("no DIDs in namespace", func(t *testing.T) {
+		didAnchors := &mockDIDAnchorStore{}
+		opStore := &mockOperationStore{}
+
+		r := New(namespace, didAnchors, opStore, func(s OperationStore) DocumentResolver {
+			return &mockResolver{opStore: s}
+		})
+
+		results, err := r.ResolveDocuments([]string{"did:web:example.com", "did:orb:invalid"})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		require.Error(t, results[0].Err)
+		require.Error(t, results[1].Err)
+		require.Nil(t, didAnchors.suffixes)
+	})
+
+	t.Run("no anchored DIDs", func(t *testing.T) {
+		didAnchors := &mockDIDAnchorStore{}
+		opStore := &mockOperationStore{}
+
+		r := New(namespace, didAnchors, opStore, func(s OperationStore) DocumentResolver {
+			return &mockResolver{opStore: s}
+		})
+
+		results, err := r.ResolveDocuments([]string{did1})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		require.Error(t, results[0].Err)
+		require.Contains(t, results[0].Err.Error(), "not found")
+		require.Nil(t, opStore.bulkSuffixes)
+	})
+
+	t.Run("DID anchor store error", func(t *testing.T) {
+		errExpected := errors.New("injected anchor store error")
+
+		r := New(namespace, &mockDIDAnchorStore{err: errExpected}, &mockOperationStore{},
+			func(s OperationStore) DocumentResolver {
+				return &mockResolver{opStore: s}
+			},
+		)
+
+		results, err := r.ResolveDocuments([]string{did1})
+		require.ErrorIs(t, err, errExpected)
+		require.Nil(t, results)
+	})
+
+	t.Run("operation store error", func(t *testing.T) {
+		errExpected := errors.New("injected operation store error")
+
+		r := New(namespace, &mockDIDAnchorStore{anchors: map[string]string{suffix1: "hl:1"}},
+			&mockOperationStore{bulkErr: errExpected},
+			func(s OperationStore) DocumentResolver {
+				return &mockResolver{opStore: s}
+			},
+		)
+
+		results, err := r.ResolveDocuments([]string{did1})
+		require.ErrorIs(t, err, errExpected)
+		require.Nil(t, results)
+	})
+}
+
+func TestPrefetchedStore_Get(t *testing.T) {
+	op := &operation.AnchoredOperation{UniqueSuffix: suffix1}
+
+	s := &prefetchedStore{
+		OperationStore: &mockOperationStore{},
+		ops: map[string][]*operation.AnchoredOperation{
+			suffix1: {op},
+		},
+	}
+
+	ops, err := s.Get(suffix1)
+	require.NoError(t, err)
+	require.Equal(t, []*operation.AnchoredOperation{op}, ops)
+
+	// Ensure that a copy of the slice is returned.
+	ops[0] = nil
+
+	ops, err = s.Get(suffix1)
+	require.NoError(t, err)
+	require.Equal(t, op, ops[0])
+}
+
+type mockDIDAnchorStore struct {
+	anchors  map[string]string
+	err      error
+	suffixes []string
+}
+
+func (m *mockDIDAnchorStore) GetBulk(suffixes []string) ([]string, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	m.suffixes = suffixes
+
+	anchors := make([]string, len(suffixes))
+
+	for i, suffix := range suffixes {
+		anchors[i] = m.anchors[suffix]
+	}
+
+	return anchors, nil
+}
+
+type mockOperationStore struct {
+	ops          map[string][]*operation.AnchoredOperation
+	bulkErr      error
+	bulkSuffixes []string
+
+	mutex    sync.Mutex
+	suffixes []string
+}
+
+func (m *mockOperationStore) Get(suffix string) ([]*operation.AnchoredOperation, error) {
+	m.mutex.Lock()
+	m.suffixes = append(m.suffixes, suffix)
+	m.mutex.Unlock()
+
+	ops, ok := m.ops[suffix]
+	if !ok {
+		return nil, fmt.Errorf("suffix[%s] not found in the store", suffix)
+	}
+
+	return ops, nil
+}
+
+func (m *mockOperationStore) GetBulk(suffixes []string) (map[string][]*operation.AnchoredOperation, error) {
+	if m.bulkErr != nil {
+		return nil, m.bulkErr
+	}
+
+	m.bulkSuffixes = suffixes
+
+	opsBySuffix := make(map[string][]*operation.AnchoredOperation)
+
+	for _, suffix := range suffixes {
+		if ops, ok := m.ops[suffix]; ok {
+			opsBySuffix[suffix] = ops
+		}
+	}
+
+	return opsBySuffix, nil
+}
+
+func (m *mockOperationStore) getSuffixes() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.suffixes
+}
+
+type mockResolver struct {
+	opStore OperationStore
+}
+
+func (m *mockResolver) ResolveDocument(id string, _ ...document.ResolutionOption) (*document.ResolutionResult, error) {
+	if !strings.HasPrefix(id, namespace+":") {
+		return nil, fmt.Errorf("did method not supported: %s", id)
+	}
+
+	ops, err := m.opStore.Get(id[strings.LastIndex(id, ":")+1:])
+	if err != nil {
+		return nil, err
+	}
+
+	return &document.ResolutionResult{Document: document.Document{"id": id, "ops": len(ops)}}, nil
+}
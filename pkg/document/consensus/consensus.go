@@ -0,0 +1,56 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package consensus
+
+import (
+	"fmt"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+)
+
+// MetadataProperty is the document metadata property which holds the consensus metadata.
+const MetadataProperty = "consensus"
+
+// Resolver resolves a DID document from multiple Orb domains and returns the result that a minimum
+// number of the domains agree on.
+type Resolver interface {
+	ResolveDocumentWithConsensus(id string, opts ...document.ResolutionOption) (*document.ResolutionResult, error)
+}
+
+// Disagreement contains a resolver (domain) whose result doesn't match the consensus result (or which
+// failed to return a result) and the reason why.
+type Disagreement struct {
+	Resolver string `json:"resolver"`
+	Reason   string `json:"reason"`
+}
+
+// Metadata is added to the document metadata of a resolution that was performed in consensus mode.
+type Metadata struct {
+	// MinimumResolvers is the number of resolvers which must agree on the result.
+	MinimumResolvers int `json:"minimumResolvers"`
+
+	// Agreed contains the resolvers which returned the consensus result.
+	Agreed []string `json:"agreed,omitempty"`
+
+	// Disagreed contains the resolvers which returned a different result or no result at all.
+	Disagreed []*Disagreement `json:"disagreed,omitempty"`
+}
+
+// Error is returned when the minimum number of resolvers don't agree on the resolution result.
+type Error struct {
+	Metadata *Metadata
+}
+
+// NewError returns a new consensus error.
+func NewError(metadata *Metadata) *Error {
+	return &Error{Metadata: metadata}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("consensus not reached: %d resolver(s) agreed on the result but %d are required",
+		len(e.Metadata.Agreed), e.Metadata.MinimumResolvers)
+}
@@ -0,0 +1,32 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package consensus
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestError(t *testing.T) {
+	err := fmt.Errorf("resolve document: %w", NewError(&Metadata{
+		MinimumResolvers: 3,
+		Agreed:           []string{"https://orb.domain1.com"},
+		Disagreed: []*Disagreement{
+			{Resolver: "https://orb.domain2.com", Reason: "documents don't match"},
+		},
+	}))
+
+	require.EqualError(t, err,
+		"resolve document: consensus not reached: 1 resolver(s) agreed on the result but 3 are required")
+
+	var cErr *Error
+	require.True(t, errors.As(err, &cErr))
+	require.Len(t, cErr.Metadata.Disagreed, 1)
+}
@@ -0,0 +1,127 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didresolution
+
+import (
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// representation is the representation of the response which was negotiated from the Accept header.
+type representation int
+
+const (
+	// resolutionResult is the W3C DID resolution result.
+	resolutionResult representation = iota
+	// dereferencingResult is the W3C DID URL dereferencing result.
+	dereferencingResult
+	// didDocumentLDJSON is the DID document only, with media type application/did+ld+json.
+	didDocumentLDJSON
+	// didDocumentJSON is the DID document only, with media type application/did+json.
+	didDocumentJSON
+	// legacyResolutionResult is the resolution result with media type application/did+ld+json, which is what
+	// clients of previous releases (including the Orb VDR) expect when requesting application/did+ld+json.
+	legacyResolutionResult
+)
+
+type mediaRange struct {
+	mediaType string
+	profiles  []string
+	q         float64
+}
+
+// negotiate returns the representation for the given Accept header. False is returned if none of the
+// requested media types are supported. If strict is false then a request for application/did+ld+json (or a
+// request with no specific media type) is served the resolution result rather than the DID document
+// alone, which is what clients of previous releases expect.
+func negotiate(accept string, strict bool) (representation, bool) {
+	defaultRepresentation := legacyResolutionResult
+	if strict {
+		defaultRepresentation = resolutionResult
+	}
+
+	if strings.TrimSpace(accept) == "" {
+		return defaultRepresentation, true
+	}
+
+	for _, mr := range parseAccept(accept) {
+		switch mr.mediaType {
+		case MediaTypeLDJSON:
+			switch {
+			case contains(mr.profiles, ResolutionProfile):
+				return resolutionResult, true
+			case contains(mr.profiles, DereferencingProfile):
+				return dereferencingResult, true
+			default:
+				return didDocumentLDJSON, true
+			}
+		case MediaTypeDIDLDJSON:
+			if !strict {
+				return legacyResolutionResult, true
+			}
+
+			return didDocumentLDJSON, true
+		case MediaTypeDIDJSON:
+			return didDocumentJSON, true
+		case MediaTypeJSON, "application/*", "*/*":
+			return defaultRepresentation, true
+		}
+	}
+
+	return 0, false
+}
+
+// parseAccept parses the given Accept header and returns the media ranges in order of preference. Media
+// ranges with a quality of zero (i.e. not acceptable) and invalid media ranges are omitted.
+func parseAccept(accept string) []*mediaRange {
+	var ranges []*mediaRange
+
+	for _, value := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+
+		mr := &mediaRange{mediaType: mediaType, q: 1}
+
+		if q, ok := params["q"]; ok {
+			mr.q, err = strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+		}
+
+		if mr.q <= 0 {
+			continue
+		}
+
+		if profile, ok := params["profile"]; ok {
+			mr.profiles = strings.Fields(profile)
+		}
+
+		ranges = append(ranges, mr)
+	}
+
+	// A stable sort is used so that media ranges of equal quality are preferred in the order they were specified.
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+
+	return ranges
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
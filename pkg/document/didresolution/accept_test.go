@@ -0,0 +1,45 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didresolution
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept string
+		strict bool
+		rep    representation
+	}{
+		{accept: "", rep: legacyResolutionResult},
+		{accept: "", strict: true, rep: resolutionResult},
+		{accept: "*/*", strict: true, rep: resolutionResult},
+		{accept: MediaTypeDIDLDJSON, rep: legacyResolutionResult},
+		{accept: MediaTypeDIDLDJSON, strict: true, rep: didDocumentLDJSON},
+		{accept: MediaTypeDIDJSON, rep: didDocumentJSON},
+		{accept: `application/ld+json;profile="https://w3id.org/did-resolution"`, rep: resolutionResult},
+		{accept: `application/ld+json; profile="https://w3id.org/did-url-dereferencing"`, rep: dereferencingResult},
+		{accept: "text/html, application/did+json", rep: didDocumentJSON},
+		{accept: "application/did+ld+json;q=0.5, application/did+json", strict: true, rep: didDocumentJSON},
+		{accept: "application/did+json;q=0, */*;q=0.1", strict: true, rep: resolutionResult},
+		{accept: "application/did+json;q=xxx, application/did+ld+json", strict: true, rep: didDocumentLDJSON},
+	}
+
+	for _, test := range tests {
+		rep, ok := negotiate(test.accept, test.strict)
+		require.True(t, ok, test.accept)
+		require.Equal(t, test.rep, rep, test.accept)
+	}
+
+	for _, accept := range []string{"text/html", "application/did+json;q=0", ";;;"} {
+		_, ok := negotiate(accept, false)
+		require.False(t, ok, accept)
+	}
+}
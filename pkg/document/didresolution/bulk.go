@@ -0,0 +1,259 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didresolution
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/document/bulkresolver"
+)
+
+const (
+	defaultMaxBulkDIDs = 100
+
+	// maxBulkRequestSize is the maximum size of the request body. DIDs (including long-form DIDs) are
+	// typically much less than 10KB.
+	maxBulkRequestSize = 10 * 1024 * defaultMaxBulkDIDs
+)
+
+// BulkResolver resolves multiple DIDs at once.
+type BulkResolver interface {
+	ResolveDocuments(ids []string) ([]*bulkresolver.Result, error)
+}
+
+// BulkRequest contains the DIDs to resolve.
+type BulkRequest struct {
+	DIDs []string `json:"dids"`
+}
+
+// BulkResponse contains the resolution result of each of the requested DIDs (in the order in which
+// they were requested).
+type BulkResponse struct {
+	Results []*BulkResult `json:"results"`
+}
+
+// BulkResult is the DID resolution result of a single DID in a bulk request. If the DID could not be resolved
+// then the error is returned in the resolution metadata.
+type BulkResult struct {
+	ID string `json:"id"`
+
+	*ResolutionResult
+}
+
+// BulkOption is an option for the bulk resolve handler.
+type BulkOption func(h *BulkResolveHandler)
+
+// WithMaxBulkDIDs sets the maximum number of DIDs which may be resolved in a single request.
+func WithMaxBulkDIDs(value int) BulkOption {
+	return func(h *BulkResolveHandler) {
+		if value > 0 {
+			h.maxDIDs = value
+		}
+	}
+}
+
+// BulkResolveHandler is an HTTP handler which resolves multiple DIDs in a single request. Each DID in the
+// response has its own DID resolution result so that an error resolving one DID doesn't fail the entire request.
+type BulkResolveHandler struct {
+	path     string
+	resolver BulkResolver
+	metrics  metricsProvider
+	maxDIDs  int
+	marshal  func(interface{}) ([]byte, error)
+}
+
+// NewBulkResolveHandler returns a new bulk DID resolve handler.
+func NewBulkResolveHandler(path string, resolver BulkResolver, metrics metricsProvider,
+	opts ...BulkOption) *BulkResolveHandler {
+	h := &BulkResolveHandler{
+		path:     path,
+		resolver: resolver,
+		metrics:  metrics,
+		maxDIDs:  defaultMaxBulkDIDs,
+		marshal:  json.Marshal,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// Path returns the HTTP REST endpoint for the bulk resolve handler.
+func (h *BulkResolveHandler) Path() string {
+	return h.path
+}
+
+// Method returns the HTTP REST method for the bulk resolve handler.
+func (h *BulkResolveHandler) Method() string {
+	return http.MethodPost
+}
+
+// Handler returns the HTTP REST handler for the bulk resolve handler.
+func (h *BulkResolveHandler) Handler() common.HTTPRequestHandler {
+	return h.resolve
+}
+
+func (h *BulkResolveHandler) resolve(rw http.ResponseWriter, req *http.Request) {
+	startTime := time.Now()
+
+	defer func() {
+		h.metrics.HTTPResolveTime(time.Since(startTime))
+	}()
+
+	request, err := h.parseRequest(req)
+	if err != nil {
+		logger.Debug("Invalid bulk resolution request", log.WithError(err))
+
+		h.writeResponse(rw, http.StatusBadRequest, "text/plain", []byte(err.Error()))
+
+		return
+	}
+
+	results := make([]*BulkResult, len(request.DIDs))
+
+	var ids []string
+
+	var indexes []int
+
+	for i, id := range request.DIDs {
+		if err := validateDID(id); err != nil {
+			results[i] = newBulkErrorResult(id, err)
+
+			continue
+		}
+
+		ids = append(ids, id)
+		indexes = append(indexes, i)
+	}
+
+	logger.Debug("Resolving DIDs", log.WithTotal(len(ids)))
+
+	if len(ids) > 0 {
+		resolved, err := h.resolver.ResolveDocuments(ids)
+		if err != nil {
+			logger.Error("Error resolving DIDs", log.WithError(err))
+
+			h.writeResponse(rw, http.StatusInternalServerError, "text/plain", []byte("Internal Server Error."))
+
+			return
+		}
+
+		for i, r := range resolved {
+			results[indexes[i]] = newBulkResult(r)
+		}
+	}
+
+	respBytes, err := h.marshal(&BulkResponse{Results: results})
+	if err != nil {
+		logger.Error("Error marshalling response", log.WithError(err))
+
+		rw.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	h.writeResponse(rw, http.StatusOK, MediaTypeJSON, respBytes)
+}
+
+func (h *BulkResolveHandler) parseRequest(req *http.Request) (*BulkRequest, error) {
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxBulkRequestSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("read request body: %w", err)
+	}
+
+	if len(body) > maxBulkRequestSize {
+		return nil, fmt.Errorf("request body exceeds the maximum size of %d bytes", maxBulkRequestSize)
+	}
+
+	request := &BulkRequest{}
+
+	if err := json.Unmarshal(body, request); err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	if len(request.DIDs) == 0 {
+		return nil, fmt.Errorf("no DIDs specified in request")
+	}
+
+	if len(request.DIDs) > h.maxDIDs {
+		return nil, fmt.Errorf("the number of DIDs [%d] exceeds the maximum of %d", len(request.DIDs), h.maxDIDs)
+	}
+
+	return request, nil
+}
+
+func (h *BulkResolveHandler) writeResponse(rw http.ResponseWriter, status int, contentType string, body []byte) {
+	rw.Header().Set("Content-Type", contentType)
+	rw.WriteHeader(status)
+
+	if _, err := rw.Write(body); err != nil {
+		log.WriteResponseBodyError(logger, err)
+	}
+}
+
+// validateDID ensures that the given ID is a DID. DID URLs (which contain DID parameters, a path or a fragment)
+// are not supported in a bulk request.
+func validateDID(id string) error {
+	if !strings.HasPrefix(id, "did:") || strings.ContainsAny(id, "?/#") {
+		return newResolutionError(InvalidDID, fmt.Errorf("invalid did: %s", id))
+	}
+
+	if _, err := did.Parse(id); err != nil {
+		return newResolutionError(InvalidDID, err)
+	}
+
+	return nil
+}
+
+func newBulkResult(r *bulkresolver.Result) *BulkResult {
+	if r.Err != nil {
+		return newBulkErrorResult(r.ID, r.Err)
+	}
+
+	metadata := &Metadata{ContentType: MediaTypeDIDLDJSON}
+
+	if isDeactivated(r.Result.DocumentMetadata) {
+		metadata.Error = Deactivated
+	}
+
+	return &BulkResult{
+		ID: r.ID,
+		ResolutionResult: &ResolutionResult{
+			Context:            resultContext(r.Result.Context),
+			Document:           r.Result.Document,
+			DocumentMetadata:   r.Result.DocumentMetadata,
+			ResolutionMetadata: metadata,
+		},
+	}
+}
+
+func newBulkErrorResult(id string, err error) *BulkResult {
+	code := errorCodeFromResolverError(err)
+
+	if code == InternalError {
+		logger.Error("Error resolving DID", log.WithDID(id), log.WithError(err))
+	}
+
+	return &BulkResult{
+		ID: id,
+		ResolutionResult: &ResolutionResult{
+			Context:            ResolutionContext,
+			ResolutionMetadata: &Metadata{Error: code, ErrorMessage: err.Error()},
+		},
+	}
+}
@@ -0,0 +1,204 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didresolution
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/document/bulkresolver"
+	"github.com/trustbloc/orb/pkg/document/resolvehandler"
+	"github.com/trustbloc/orb/pkg/observability/metrics/noop"
+)
+
+const bulkResolvePath = "/sidetree/v1/identifiers"
+
+func TestBulkResolveHandler(t *testing.T) {
+	const (
+		notFoundDID    = "did:orb:uAAA:EiBn4uIWbAQsJ6RJX6YFnrDW8lqIMFWsSqpd8dVl9Lq8Tg"
+		deactivatedDID = "did:orb:uAAA:EiA0vZMyECEFGGZBhEX12NPDxsUQvi3E0oNrIH8eX9ewXw"
+		errorDID       = "did:orb:uAAA:EiAuRBcRT4PyV4JxmLn3Obe9VOTJXMbKkyqLeyb0Im0HFQ"
+	)
+
+	resolver := &mockBulkResolver{
+		results: map[string]*bulkresolver.Result{
+			testDID:        {ID: testDID, Result: newResolutionResult(false)},
+			notFoundDID:    {ID: notFoundDID, Err: fmt.Errorf("resolve document: %w", resolvehandler.ErrDocumentNotFound)},
+			deactivatedDID: {ID: deactivatedDID, Result: newResolutionResult(true)},
+			errorDID:       {ID: errorDID, Err: errors.New("injected resolver error")},
+		},
+	}
+
+	h := NewBulkResolveHandler(bulkResolvePath, resolver, noop.GetMetrics(), WithMaxBulkDIDs(6))
+	require.Equal(t, bulkResolvePath, h.Path())
+	require.Equal(t, http.MethodPost, h.Method())
+	require.NotNil(t, h.Handler())
+
+	t.Run("Success", func(t *testing.T) {
+		rw := serveBulk(h, &BulkRequest{
+			DIDs: []string{testDID, "invalid", notFoundDID, testDID + "#key-1", deactivatedDID, errorDID},
+		})
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, MediaTypeJSON, rw.Header().Get("Content-Type"))
+
+		resp := &BulkResponse{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), resp))
+		require.Len(t, resp.Results, 6)
+
+		require.Equal(t, testDID, resp.Results[0].ID)
+		require.Equal(t, testDID, resp.Results[0].Document.ID())
+		require.Equal(t, MediaTypeDIDLDJSON, resp.Results[0].ResolutionMetadata.ContentType)
+		require.Empty(t, resp.Results[0].ResolutionMetadata.Error)
+
+		require.Equal(t, "invalid", resp.Results[1].ID)
+		require.Equal(t, InvalidDID, resp.Results[1].ResolutionMetadata.Error)
+
+		require.Equal(t, notFoundDID, resp.Results[2].ID)
+		require.Equal(t, NotFound, resp.Results[2].ResolutionMetadata.Error)
+		require.Nil(t, resp.Results[2].Document)
+
+		require.Equal(t, testDID+"#key-1", resp.Results[3].ID)
+		require.Equal(t, InvalidDID, resp.Results[3].ResolutionMetadata.Error)
+
+		require.Equal(t, deactivatedDID, resp.Results[4].ID)
+		require.Equal(t, Deactivated, resp.Results[4].ResolutionMetadata.Error)
+		require.NotNil(t, resp.Results[4].Document)
+
+		require.Equal(t, errorDID, resp.Results[5].ID)
+		require.Equal(t, InternalError, resp.Results[5].ResolutionMetadata.Error)
+		require.Equal(t, "injected resolver error", resp.Results[5].ResolutionMetadata.ErrorMessage)
+
+		require.Equal(t, []string{testDID, notFoundDID, deactivatedDID, errorDID}, resolver.ids)
+	})
+
+	t.Run("Only invalid DIDs", func(t *testing.T) {
+		resolver.ids = nil
+
+		rw := serveBulk(h, &BulkRequest{DIDs: []string{"did:orb"}})
+		require.Equal(t, http.StatusOK, rw.Code)
+
+		resp := &BulkResponse{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), resp))
+		require.Len(t, resp.Results, 1)
+		require.Equal(t, InvalidDID, resp.Results[0].ResolutionMetadata.Error)
+		require.Nil(t, resolver.ids)
+	})
+
+	t.Run("No DIDs", func(t *testing.T) {
+		rw := serveBulk(h, &BulkRequest{})
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Contains(t, rw.Body.String(), "no DIDs specified")
+	})
+
+	t.Run("Too many DIDs", func(t *testing.T) {
+		rw := serveBulk(h, &BulkRequest{DIDs: []string{testDID, testDID, testDID, testDID, testDID, testDID, testDID}})
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Contains(t, rw.Body.String(), "exceeds the maximum of 6")
+	})
+
+	t.Run("Invalid request", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, bulkResolvePath, bytes.NewBufferString("{"))
+		rw := httptest.NewRecorder()
+
+		h.Handler()(rw, req)
+
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Contains(t, rw.Body.String(), "invalid request body")
+	})
+
+	t.Run("Request too large", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, bulkResolvePath,
+			strings.NewReader(strings.Repeat(" ", maxBulkRequestSize+1)))
+		rw := httptest.NewRecorder()
+
+		h.Handler()(rw, req)
+
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Contains(t, rw.Body.String(), "exceeds the maximum size")
+	})
+
+	t.Run("Read error", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, bulkResolvePath, &errReader{})
+		rw := httptest.NewRecorder()
+
+		h.Handler()(rw, req)
+
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Contains(t, rw.Body.String(), "read request body")
+	})
+
+	t.Run("Resolver error", func(t *testing.T) {
+		h := NewBulkResolveHandler(bulkResolvePath, &mockBulkResolver{err: errors.New("injected error")},
+			noop.GetMetrics())
+
+		rw := serveBulk(h, &BulkRequest{DIDs: []string{testDID}})
+		require.Equal(t, http.StatusInternalServerError, rw.Code)
+	})
+
+	t.Run("Marshal error", func(t *testing.T) {
+		h := NewBulkResolveHandler(bulkResolvePath, resolver, noop.GetMetrics())
+		h.marshal = func(interface{}) ([]byte, error) { return nil, errors.New("injected marshal error") }
+
+		rw := serveBulk(h, &BulkRequest{DIDs: []string{testDID}})
+		require.Equal(t, http.StatusInternalServerError, rw.Code)
+	})
+}
+
+type mockBulkResolver struct {
+	results map[string]*bulkresolver.Result
+	err     error
+	ids     []string
+}
+
+func (m *mockBulkResolver) ResolveDocuments(ids []string) ([]*bulkresolver.Result, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	m.ids = ids
+
+	results := make([]*bulkresolver.Result, len(ids))
+
+	for i, id := range ids {
+		r, ok := m.results[id]
+		if !ok {
+			r = &bulkresolver.Result{ID: id, Err: resolvehandler.ErrDocumentNotFound}
+		}
+
+		results[i] = r
+	}
+
+	return results, nil
+}
+
+type errReader struct{}
+
+func (r *errReader) Read([]byte) (int, error) {
+	return 0, errors.New("injected read error")
+}
+
+func serveBulk(h *BulkResolveHandler, request *BulkRequest) *httptest.ResponseRecorder {
+	reqBytes, err := json.Marshal(request)
+	if err != nil {
+		panic(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, bulkResolvePath, bytes.NewBuffer(reqBytes))
+	rw := httptest.NewRecorder()
+
+	h.Handler()(rw, req)
+
+	return rw
+}
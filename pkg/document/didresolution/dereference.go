@@ -0,0 +1,254 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didresolution
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+
+	"github.com/trustbloc/orb/pkg/document/assurance"
+)
+
+// DID parameters.
+const (
+	serviceParam     = "service"
+	relativeRefParam = "relativeRef"
+	versionIDParam   = "versionId"
+	versionTimeParam = "versionTime"
+)
+
+// Resolution options which specify the assurance criteria. Each option may be repeated.
+const (
+	requiredWitnessParam = "requiredWitness"
+	requiredLogParam     = "requiredLog"
+)
+
+// consensusParam is the resolution option which requests that the DID be resolved from multiple Orb domains
+// and that the result be returned only if the minimum number of domains agree on it.
+const consensusParam = "consensus"
+
+// Properties of a DID document which may contain embedded verification methods or services.
+var fragmentProperties = []string{
+	"verificationMethod",
+	"authentication",
+	"assertionMethod",
+	"keyAgreement",
+	"capabilityInvocation",
+	"capabilityDelegation",
+	"service",
+}
+
+// didURL contains the parsed components of the requested DID URL.
+type didURL struct {
+	did         string
+	fragment    string
+	service     string
+	relativeRef string
+	versionID   string
+	versionTime string
+	criteria    *assurance.Criteria
+	consensus   bool
+}
+
+// isDereference returns true if the DID URL refers to a resource within (or referenced by) the DID
+// document, rather than to the DID document itself.
+func (u *didURL) isDereference() bool {
+	return u.fragment != "" || u.service != ""
+}
+
+func (u *didURL) resolutionOptions() ([]document.ResolutionOption, error) {
+	if u.versionID != "" && u.versionTime != "" {
+		return nil, newResolutionError(InvalidDIDURL,
+			fmt.Errorf("cannot specify both '%s' and '%s'", versionIDParam, versionTimeParam))
+	}
+
+	if !u.criteria.IsEmpty() && (u.versionID != "" || u.versionTime != "") {
+		return nil, newResolutionError(InvalidDIDURL,
+			fmt.Errorf("cannot specify '%s' or '%s' along with '%s' or '%s'",
+				requiredWitnessParam, requiredLogParam, versionIDParam, versionTimeParam))
+	}
+
+	if u.consensus && (!u.criteria.IsEmpty() || u.versionID != "" || u.versionTime != "") {
+		return nil, newResolutionError(InvalidDIDURL,
+			fmt.Errorf("cannot specify '%s' along with '%s', '%s', '%s' or '%s'", consensusParam,
+				requiredWitnessParam, requiredLogParam, versionIDParam, versionTimeParam))
+	}
+
+	var opts []document.ResolutionOption
+
+	if u.versionID != "" {
+		opts = append(opts, document.WithVersionID(u.versionID))
+	}
+
+	if u.versionTime != "" {
+		opts = append(opts, document.WithVersionTime(u.versionTime))
+	}
+
+	return opts, nil
+}
+
+// parseDIDURL parses the given DID URL. The DID parameters may be provided in the DID URL itself
+// (in which case the '?' must be percent-encoded by the client) and/or in the query of the HTTP request.
+func parseDIDURL(id string, query url.Values) (*didURL, error) {
+	if !strings.HasPrefix(id, "did:") {
+		return nil, newResolutionError(InvalidDID, fmt.Errorf("invalid did: %s", id))
+	}
+
+	parsed, err := did.ParseDIDURL(id)
+	if err != nil {
+		code := InvalidDIDURL
+		if !strings.ContainsAny(id, "?/#") {
+			code = InvalidDID
+		}
+
+		return nil, newResolutionError(code, err)
+	}
+
+	if parsed.Path != "" {
+		return nil, newResolutionError(NotFound, fmt.Errorf("DID URL path is not supported: %s", parsed.Path))
+	}
+
+	params := url.Values(parsed.Queries)
+
+	for name, values := range query {
+		params[name] = append(params[name], values...)
+	}
+
+	var consensus bool
+
+	if value := params.Get(consensusParam); value != "" {
+		consensus, err = strconv.ParseBool(value)
+		if err != nil {
+			return nil, newResolutionError(InvalidDIDURL, fmt.Errorf("invalid value for '%s': %s", consensusParam, value))
+		}
+	}
+
+	return &didURL{
+		did:         parsed.DID.String(),
+		fragment:    parsed.Fragment,
+		service:     params.Get(serviceParam),
+		relativeRef: params.Get(relativeRefParam),
+		versionID:   params.Get(versionIDParam),
+		versionTime: params.Get(versionTimeParam),
+		criteria: &assurance.Criteria{
+			Witnesses: params[requiredWitnessParam],
+			Logs:      params[requiredLogParam],
+		},
+		consensus: consensus,
+	}, nil
+}
+
+// dereferenceFragment returns the object (verification method or service) in the DID document
+// which is identified by the given fragment.
+func dereferenceFragment(doc document.Document, fragment string) (map[string]interface{}, error) {
+	obj, ok := findObject(doc, fragmentProperties, func(id string) bool {
+		return id == "#"+fragment || strings.HasSuffix(id, "#"+fragment)
+	})
+	if !ok {
+		return nil, newResolutionError(NotFound, fmt.Errorf("fragment not found in DID document: #%s", fragment))
+	}
+
+	return obj, nil
+}
+
+// dereferenceService returns the URL of the given service with the relative reference (if any)
+// resolved against the service endpoint, as described in the DID Resolution specification.
+func dereferenceService(doc document.Document, service, relativeRef string) (string, error) {
+	svc, ok := findObject(doc, []string{"service"}, func(id string) bool {
+		return id == service || id == "#"+service || strings.HasSuffix(id, "#"+service)
+	})
+	if !ok {
+		return "", newResolutionError(NotFound, fmt.Errorf("service not found in DID document: %s", service))
+	}
+
+	endpoint, ok := serviceEndpointURI(svc["serviceEndpoint"])
+	if !ok {
+		return "", newResolutionError(NotFound, fmt.Errorf("service [%s] does not have a URI endpoint", service))
+	}
+
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return "", newResolutionError(InternalError, fmt.Errorf("parse service endpoint [%s]: %w", endpoint, err))
+	}
+
+	if relativeRef == "" {
+		return endpointURL.String(), nil
+	}
+
+	ref, err := url.Parse(relativeRef)
+	if err != nil {
+		return "", newResolutionError(InvalidDIDURL, fmt.Errorf("invalid %s [%s]: %w", relativeRefParam, relativeRef, err))
+	}
+
+	return endpointURL.ResolveReference(ref).String(), nil
+}
+
+// findObject returns the first object of the given properties whose ID satisfies the given function.
+func findObject(doc document.Document, properties []string, matches func(id string) bool) (map[string]interface{}, bool) {
+	for _, property := range properties {
+		entries, ok := doc[property].([]interface{})
+		if !ok {
+			continue
+		}
+
+		for _, entry := range entries {
+			obj, ok := entry.(map[string]interface{})
+			if !ok {
+				// The entry may be a reference to a verification method (i.e. a string).
+				continue
+			}
+
+			if id, ok := obj["id"].(string); ok && matches(id) {
+				return obj, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// serviceEndpointURI returns the URI of the given service endpoint, which may be a URI, a list of URIs
+// or a map which contains a URI.
+func serviceEndpointURI(endpoint interface{}) (string, bool) {
+	switch ep := endpoint.(type) {
+	case string:
+		return ep, true
+	case []interface{}:
+		for _, e := range ep {
+			if uri, ok := serviceEndpointURI(e); ok {
+				return uri, true
+			}
+		}
+	case map[string]interface{}:
+		return serviceEndpointURI(ep["uri"])
+	}
+
+	return "", false
+}
+
+// normalizeDocument returns a copy of the given document which contains only generic JSON values
+// (i.e. maps and slices) so that the document may be traversed.
+func normalizeDocument(doc document.Document) (document.Document, error) {
+	docBytes, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("marshal document: %w", err)
+	}
+
+	normalized := make(document.Document)
+
+	if err := json.Unmarshal(docBytes, &normalized); err != nil {
+		return nil, fmt.Errorf("unmarshal document: %w", err)
+	}
+
+	return normalized, nil
+}
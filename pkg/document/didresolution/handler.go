@@ -0,0 +1,344 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didresolution
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/document/assurance"
+	"github.com/trustbloc/orb/pkg/document/consensus"
+)
+
+var logger = log.New("did-resolution")
+
+// Resolver resolves DID documents.
+type Resolver interface {
+	ResolveDocument(id string, opts ...document.ResolutionOption) (*document.ResolutionResult, error)
+}
+
+type metricsProvider interface {
+	HTTPResolveTime(duration time.Duration)
+}
+
+// Option is an option for the resolve handler.
+type Option func(h *ResolveHandler)
+
+// WithStrictMode enables strict conformance with the W3C DID Resolution HTTP(S) binding. In strict mode a
+// request for application/did+ld+json returns the DID document alone and the resolution of a deactivated
+// DID returns status 410 (Gone). By default, a request for application/did+ld+json returns the resolution
+// result and a deactivated DID returns status 200, which is what clients of previous releases
+// (including the Orb VDR) expect.
+func WithStrictMode(enable bool) Option {
+	return func(h *ResolveHandler) {
+		h.strict = enable
+	}
+}
+
+// ResolveHandler is an HTTP handler which resolves DIDs and dereferences DID URLs according to
+// the W3C DID Resolution specification.
+type ResolveHandler struct {
+	path     string
+	resolver Resolver
+	metrics  metricsProvider
+	strict   bool
+	marshal  func(interface{}) ([]byte, error)
+}
+
+// NewResolveHandler returns a new DID resolve handler. The given path must contain the {id} variable.
+func NewResolveHandler(path string, resolver Resolver, metrics metricsProvider, opts ...Option) *ResolveHandler {
+	h := &ResolveHandler{
+		path:     path,
+		resolver: resolver,
+		metrics:  metrics,
+		marshal:  json.Marshal,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// Path returns the HTTP REST endpoint for the resolve handler.
+func (h *ResolveHandler) Path() string {
+	return h.path
+}
+
+// Method returns the HTTP REST method for the resolve handler.
+func (h *ResolveHandler) Method() string {
+	return http.MethodGet
+}
+
+// Handler returns the HTTP REST handler for the resolve handler.
+func (h *ResolveHandler) Handler() common.HTTPRequestHandler {
+	return h.resolve
+}
+
+func (h *ResolveHandler) resolve(rw http.ResponseWriter, req *http.Request) {
+	startTime := time.Now()
+
+	defer func() {
+		h.metrics.HTTPResolveTime(time.Since(startTime))
+	}()
+
+	rep, ok := negotiate(req.Header.Get("Accept"), h.strict)
+	if !ok {
+		h.writeError(rw, resolutionResult, newResolutionError(RepresentationNotSupported,
+			fmt.Errorf("none of the requested representations are supported: %s", req.Header.Get("Accept"))))
+
+		return
+	}
+
+	u, err := parseDIDURL(mux.Vars(req)["id"], req.URL.Query())
+	if err != nil {
+		h.writeError(rw, rep, err)
+
+		return
+	}
+
+	opts, err := u.resolutionOptions()
+	if err != nil {
+		h.writeError(rw, rep, err)
+
+		return
+	}
+
+	logger.Debug("Resolving DID", log.WithDID(u.did))
+
+	result, err := h.resolveDocument(u, opts)
+	if err != nil {
+		h.writeError(rw, rep, err)
+
+		return
+	}
+
+	if u.isDereference() {
+		h.dereference(rw, rep, u, result)
+
+		return
+	}
+
+	h.writeResolutionResult(rw, rep, result)
+}
+
+// resolveDocument resolves the DID, taking into account the assurance criteria (if any) and the consensus
+// option in the request.
+func (h *ResolveHandler) resolveDocument(u *didURL, opts []document.ResolutionOption) (*document.ResolutionResult, error) {
+	if u.consensus {
+		resolver, ok := h.resolver.(consensus.Resolver)
+		if !ok {
+			return nil, newResolutionError(InvalidDIDURL, fmt.Errorf("'%s' is not supported", consensusParam))
+		}
+
+		logger.Debug("Resolving DID with consensus", log.WithDID(u.did))
+
+		return resolver.ResolveDocumentWithConsensus(u.did, opts...)
+	}
+
+	if u.criteria.IsEmpty() {
+		return h.resolver.ResolveDocument(u.did, opts...)
+	}
+
+	resolver, ok := h.resolver.(assurance.Resolver)
+	if !ok {
+		return nil, newResolutionError(InvalidDIDURL,
+			fmt.Errorf("'%s' and '%s' are not supported", requiredWitnessParam, requiredLogParam))
+	}
+
+	logger.Debug("Resolving DID with assurance criteria", log.WithDID(u.did))
+
+	return resolver.ResolveDocumentWithAssurance(u.did, u.criteria, opts...)
+}
+
+func (h *ResolveHandler) writeResolutionResult(rw http.ResponseWriter, rep representation,
+	result *document.ResolutionResult) {
+	docMetadata, consensusMetadata := splitConsensusMetadata(result.DocumentMetadata)
+
+	metadata := &Metadata{ContentType: documentContentType(rep), Consensus: consensusMetadata}
+	status := http.StatusOK
+
+	if isDeactivated(result.DocumentMetadata) {
+		metadata.Error = Deactivated
+
+		if h.strict {
+			status = Deactivated.Status()
+		}
+	}
+
+	switch rep {
+	case didDocumentLDJSON, didDocumentJSON:
+		h.writeResponse(rw, status, contentType(rep), result.Document)
+	default:
+		h.writeResponse(rw, status, contentType(rep), &ResolutionResult{
+			Context:            resultContext(result.Context),
+			Document:           result.Document,
+			DocumentMetadata:   docMetadata,
+			ResolutionMetadata: metadata,
+		})
+	}
+}
+
+func (h *ResolveHandler) dereference(rw http.ResponseWriter, rep representation, u *didURL,
+	result *document.ResolutionResult) {
+	doc, err := normalizeDocument(result.Document)
+	if err != nil {
+		h.writeError(rw, rep, err)
+
+		return
+	}
+
+	if u.service != "" {
+		h.dereferenceService(rw, rep, u, doc, result)
+
+		return
+	}
+
+	obj, err := dereferenceFragment(doc, u.fragment)
+	if err != nil {
+		h.writeError(rw, rep, err)
+
+		return
+	}
+
+	if rep == dereferencingResult {
+		docMetadata, consensusMetadata := splitConsensusMetadata(result.DocumentMetadata)
+
+		h.writeResponse(rw, http.StatusOK, mediaTypeDereferencingResult, &DereferencingResult{
+			Context:               resultContext(result.Context),
+			ContentStream:         obj,
+			ContentMetadata:       docMetadata,
+			DereferencingMetadata: &Metadata{ContentType: MediaTypeDIDLDJSON, Consensus: consensusMetadata},
+		})
+
+		return
+	}
+
+	h.writeResponse(rw, http.StatusOK, documentContentType(rep), obj)
+}
+
+func (h *ResolveHandler) dereferenceService(rw http.ResponseWriter, rep representation, u *didURL,
+	doc document.Document, result *document.ResolutionResult) {
+	serviceURL, err := dereferenceService(doc, u.service, u.relativeRef)
+	if err != nil {
+		h.writeError(rw, rep, err)
+
+		return
+	}
+
+	if rep == dereferencingResult {
+		docMetadata, consensusMetadata := splitConsensusMetadata(result.DocumentMetadata)
+
+		h.writeResponse(rw, http.StatusOK, mediaTypeDereferencingResult, &DereferencingResult{
+			Context:               resultContext(result.Context),
+			ContentStream:         serviceURL,
+			ContentMetadata:       docMetadata,
+			DereferencingMetadata: &Metadata{ContentType: MediaTypeURIList, Consensus: consensusMetadata},
+		})
+
+		return
+	}
+
+	// As per the HTTP(S) binding, the client is redirected to the selected service endpoint.
+	rw.Header().Set("Location", serviceURL)
+	rw.Header().Set("Content-Type", MediaTypeURIList)
+	rw.WriteHeader(http.StatusSeeOther)
+
+	if _, err := rw.Write([]byte(serviceURL)); err != nil {
+		log.WriteResponseBodyError(logger, err)
+	}
+}
+
+// writeError writes the DID resolution (or dereferencing) result with the error code that corresponds
+// to the given error.
+func (h *ResolveHandler) writeError(rw http.ResponseWriter, rep representation, err error) {
+	code := errorCodeFromResolverError(err)
+	metadata := &Metadata{Error: code, ErrorMessage: err.Error(), Consensus: consensusMetadataFromError(err)}
+
+	if code == InternalError {
+		logger.Error("Error resolving DID", log.WithError(err))
+	} else {
+		logger.Debug("Error resolving DID", log.WithError(err))
+	}
+
+	if rep == dereferencingResult {
+		h.writeResponse(rw, code.Status(), mediaTypeDereferencingResult, &DereferencingResult{
+			Context:               ResolutionContext,
+			DereferencingMetadata: metadata,
+		})
+
+		return
+	}
+
+	ct := mediaTypeResolutionResult
+	if rep == legacyResolutionResult {
+		ct = MediaTypeDIDLDJSON
+	}
+
+	h.writeResponse(rw, code.Status(), ct, &ResolutionResult{
+		Context:            ResolutionContext,
+		ResolutionMetadata: metadata,
+	})
+}
+
+func (h *ResolveHandler) writeResponse(rw http.ResponseWriter, status int, mediaType string, v interface{}) {
+	respBytes, err := h.marshal(v)
+	if err != nil {
+		logger.Error("Error marshalling response", log.WithError(err))
+
+		rw.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	rw.Header().Set("Content-Type", mediaType)
+	rw.WriteHeader(status)
+
+	if _, err := rw.Write(respBytes); err != nil {
+		log.WriteResponseBodyError(logger, err)
+	}
+}
+
+// contentType returns the content type of the response for the given representation.
+func contentType(rep representation) string {
+	switch rep {
+	case resolutionResult:
+		return mediaTypeResolutionResult
+	case dereferencingResult:
+		return mediaTypeDereferencingResult
+	case didDocumentJSON:
+		return MediaTypeDIDJSON
+	default:
+		return MediaTypeDIDLDJSON
+	}
+}
+
+// documentContentType returns the content type of the DID document (or the dereferenced resource) for the
+// given representation.
+func documentContentType(rep representation) string {
+	if rep == didDocumentJSON {
+		return MediaTypeDIDJSON
+	}
+
+	return MediaTypeDIDLDJSON
+}
+
+func resultContext(ctx interface{}) interface{} {
+	if ctx == nil {
+		return ResolutionContext
+	}
+
+	return ctx
+}
@@ -0,0 +1,512 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didresolution
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gorilla/mux"
+	vdrapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+
+	"github.com/trustbloc/orb/pkg/document/assurance"
+	"github.com/trustbloc/orb/pkg/document/consensus"
+	"github.com/trustbloc/orb/pkg/document/didresolver"
+	"github.com/trustbloc/orb/pkg/document/didresolver/mocks"
+	"github.com/trustbloc/orb/pkg/document/resolvehandler"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/observability/metrics/noop"
+)
+
+const (
+	resolvePath = "/sidetree/v1/identifiers/{id}"
+	testDID     = "did:orb:uAAA:EiDJpL-xeSE4kVgoGjaQm_OX5y5Dy3Nfr1Ld7cdMZwqb0A"
+)
+
+func TestResolveHandler(t *testing.T) {
+	resolver := &mocks.OrbResolver{}
+	resolver.ResolveDocumentReturns(newResolutionResult(false), nil)
+
+	h := NewResolveHandler(resolvePath, resolver, noop.GetMetrics())
+	require.Equal(t, resolvePath, h.Path())
+	require.Equal(t, http.MethodGet, h.Method())
+	require.NotNil(t, h.Handler())
+
+	t.Run("Resolution result (default)", func(t *testing.T) {
+		for _, accept := range []string{"", MediaTypeDIDLDJSON, MediaTypeJSON, "*/*"} {
+			rw := serve(h, testDID, "", accept)
+			require.Equal(t, http.StatusOK, rw.Code, accept)
+			require.Equal(t, MediaTypeDIDLDJSON, rw.Header().Get("Content-Type"))
+
+			result := &ResolutionResult{}
+			require.NoError(t, json.Unmarshal(rw.Body.Bytes(), result))
+			require.Equal(t, testDID, result.Document.ID())
+			require.NotEmpty(t, result.DocumentMetadata)
+			require.Equal(t, MediaTypeDIDLDJSON, result.ResolutionMetadata.ContentType)
+			require.Empty(t, result.ResolutionMetadata.Error)
+		}
+	})
+
+	t.Run("Resolution result (profile)", func(t *testing.T) {
+		rw := serve(h, testDID, "", mediaTypeResolutionResult)
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, mediaTypeResolutionResult, rw.Header().Get("Content-Type"))
+
+		result := &ResolutionResult{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), result))
+		require.Equal(t, testDID, result.Document.ID())
+	})
+
+	t.Run("DID document", func(t *testing.T) {
+		rw := serve(h, testDID, "", MediaTypeDIDJSON)
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, MediaTypeDIDJSON, rw.Header().Get("Content-Type"))
+
+		doc := document.Document{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &doc))
+		require.Equal(t, testDID, doc.ID())
+		require.Nil(t, doc["didDocumentMetadata"])
+	})
+
+	t.Run("Representation not supported", func(t *testing.T) {
+		rw := serve(h, testDID, "", "text/html")
+		require.Equal(t, http.StatusNotAcceptable, rw.Code)
+		requireError(t, rw, RepresentationNotSupported)
+	})
+
+	t.Run("Version ID", func(t *testing.T) {
+		r := &mocks.OrbResolver{}
+		r.ResolveDocumentReturns(newResolutionResult(false), nil)
+
+		rw := serve(NewResolveHandler(resolvePath, r, noop.GetMetrics()), testDID, "versionId=v1", "")
+		require.Equal(t, http.StatusOK, rw.Code)
+
+		id, opts := r.ResolveDocumentArgsForCall(0)
+		require.Equal(t, testDID, id)
+
+		resolutionOpts := &document.ResolutionOptions{}
+
+		for _, opt := range opts {
+			opt(resolutionOpts)
+		}
+
+		require.Equal(t, "v1", resolutionOpts.VersionID)
+	})
+
+	t.Run("Version ID and version time", func(t *testing.T) {
+		rw := serve(h, testDID, "versionId=v1&versionTime=2021-05-10T17:00:00Z", "")
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		requireError(t, rw, InvalidDIDURL)
+	})
+
+	t.Run("Invalid DID", func(t *testing.T) {
+		for _, id := range []string{"xxx", "did:orb", "did:orb:"} {
+			rw := serve(h, id, "", "")
+			require.Equal(t, http.StatusBadRequest, rw.Code, id)
+			requireError(t, rw, InvalidDID)
+		}
+	})
+
+	t.Run("DID URL path", func(t *testing.T) {
+		rw := serve(h, testDID+"/some/path", "", "")
+		require.Equal(t, http.StatusNotFound, rw.Code)
+		requireError(t, rw, NotFound)
+	})
+
+	t.Run("Resolver errors", func(t *testing.T) {
+		tests := []struct {
+			err    error
+			code   ErrorCode
+			status int
+		}{
+			{
+				err:  fmt.Errorf("resolve: %w", resolvehandler.ErrDocumentNotFound),
+				code: NotFound, status: http.StatusNotFound,
+			},
+			{err: orberrors.ErrContentNotFound, code: NotFound, status: http.StatusNotFound},
+			{err: fmt.Errorf("read: %w", vdrapi.ErrNotFound), code: NotFound, status: http.StatusNotFound},
+			{
+				err:  orberrors.NewBadRequestf("bad request: invalid suffix"),
+				code: InvalidDID, status: http.StatusBadRequest,
+			},
+			{
+				err:  fmt.Errorf("%w for id[did:abc:123]", didresolver.ErrMethodNotSupported),
+				code: MethodNotSupported, status: http.StatusNotImplemented,
+			},
+			{err: errors.New("injected error"), code: InternalError, status: http.StatusInternalServerError},
+			// Errors which aren't typed are internal errors, regardless of the error message.
+			{err: errors.New("document not found"), code: InternalError, status: http.StatusInternalServerError},
+			{
+				err:  errors.New("bad request: invalid suffix"),
+				code: InternalError, status: http.StatusInternalServerError,
+			},
+		}
+
+		for _, test := range tests {
+			r := &mocks.OrbResolver{}
+			r.ResolveDocumentReturns(nil, test.err)
+
+			rw := serve(NewResolveHandler(resolvePath, r, noop.GetMetrics()), testDID, "", "")
+			require.Equal(t, test.status, rw.Code, test.err.Error())
+			require.Equal(t, MediaTypeDIDLDJSON, rw.Header().Get("Content-Type"))
+			requireError(t, rw, test.code)
+		}
+	})
+
+	t.Run("Marshal error", func(t *testing.T) {
+		h2 := NewResolveHandler(resolvePath, resolver, noop.GetMetrics())
+		h2.marshal = func(interface{}) ([]byte, error) { return nil, errors.New("injected marshal error") }
+
+		rw := serve(h2, testDID, "", "")
+		require.Equal(t, http.StatusInternalServerError, rw.Code)
+	})
+}
+
+func TestResolveHandler_Deactivated(t *testing.T) {
+	resolver := &mocks.OrbResolver{}
+	resolver.ResolveDocumentReturns(newResolutionResult(true), nil)
+
+	t.Run("Default mode", func(t *testing.T) {
+		rw := serve(NewResolveHandler(resolvePath, resolver, noop.GetMetrics()), testDID, "", MediaTypeDIDLDJSON)
+		require.Equal(t, http.StatusOK, rw.Code)
+		requireError(t, rw, Deactivated)
+	})
+
+	t.Run("Strict mode", func(t *testing.T) {
+		h := NewResolveHandler(resolvePath, resolver, noop.GetMetrics(), WithStrictMode(true))
+
+		rw := serve(h, testDID, "", "")
+		require.Equal(t, http.StatusGone, rw.Code)
+		require.Equal(t, mediaTypeResolutionResult, rw.Header().Get("Content-Type"))
+		requireError(t, rw, Deactivated)
+	})
+}
+
+func TestResolveHandler_StrictMode(t *testing.T) {
+	resolver := &mocks.OrbResolver{}
+	resolver.ResolveDocumentReturns(newResolutionResult(false), nil)
+
+	h := NewResolveHandler(resolvePath, resolver, noop.GetMetrics(), WithStrictMode(true))
+
+	t.Run("DID document", func(t *testing.T) {
+		for _, accept := range []string{MediaTypeDIDLDJSON, MediaTypeLDJSON} {
+			rw := serve(h, testDID, "", accept)
+			require.Equal(t, http.StatusOK, rw.Code)
+			require.Equal(t, MediaTypeDIDLDJSON, rw.Header().Get("Content-Type"))
+
+			doc := document.Document{}
+			require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &doc))
+			require.Equal(t, testDID, doc.ID())
+		}
+	})
+
+	t.Run("Resolution result (default)", func(t *testing.T) {
+		rw := serve(h, testDID, "", "")
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, mediaTypeResolutionResult, rw.Header().Get("Content-Type"))
+	})
+}
+
+func TestResolveHandler_Dereference(t *testing.T) {
+	resolver := &mocks.OrbResolver{}
+	resolver.ResolveDocumentReturns(newResolutionResult(false), nil)
+
+	h := NewResolveHandler(resolvePath, resolver, noop.GetMetrics())
+
+	t.Run("Fragment", func(t *testing.T) {
+		for _, fragment := range []string{"key-1", "key-2", "hub"} {
+			rw := serve(h, testDID+"#"+fragment, "", "")
+			require.Equal(t, http.StatusOK, rw.Code, fragment)
+			require.Equal(t, MediaTypeDIDLDJSON, rw.Header().Get("Content-Type"))
+
+			obj := make(map[string]interface{})
+			require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &obj))
+			require.Contains(t, obj["id"], "#"+fragment)
+		}
+
+		id, _ := resolver.ResolveDocumentArgsForCall(resolver.ResolveDocumentCallCount() - 1)
+		require.Equal(t, testDID, id)
+	})
+
+	t.Run("Fragment -> dereferencing result", func(t *testing.T) {
+		rw := serve(h, testDID+"#key-1", "", mediaTypeDereferencingResult)
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, mediaTypeDereferencingResult, rw.Header().Get("Content-Type"))
+
+		result := &DereferencingResult{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), result))
+		require.Equal(t, MediaTypeDIDLDJSON, result.DereferencingMetadata.ContentType)
+		require.Equal(t, testDID+"#key-1", result.ContentStream.(map[string]interface{})["id"])
+		require.NotEmpty(t, result.ContentMetadata)
+	})
+
+	t.Run("Fragment not found", func(t *testing.T) {
+		rw := serve(h, testDID+"#key-3", "", mediaTypeDereferencingResult)
+		require.Equal(t, http.StatusNotFound, rw.Code)
+
+		result := &DereferencingResult{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), result))
+		require.Equal(t, NotFound, result.DereferencingMetadata.Error)
+	})
+
+	t.Run("Service -> redirect", func(t *testing.T) {
+		rw := serve(h, testDID, "service=hub&relativeRef="+url.QueryEscape("/some/path?query#frag"), "")
+		require.Equal(t, http.StatusSeeOther, rw.Code)
+		require.Equal(t, "https://example.com/some/path?query#frag", rw.Header().Get("Location"))
+		require.Equal(t, MediaTypeURIList, rw.Header().Get("Content-Type"))
+	})
+
+	t.Run("Service in DID URL -> dereferencing result", func(t *testing.T) {
+		rw := serve(h, testDID+"?service=files", "", mediaTypeDereferencingResult)
+		require.Equal(t, http.StatusOK, rw.Code)
+
+		result := &DereferencingResult{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), result))
+		require.Equal(t, "https://files.example.com/a", result.ContentStream)
+		require.Equal(t, MediaTypeURIList, result.DereferencingMetadata.ContentType)
+	})
+
+	t.Run("Service not found", func(t *testing.T) {
+		rw := serve(h, testDID, "service=xxx", "")
+		require.Equal(t, http.StatusNotFound, rw.Code)
+		requireError(t, rw, NotFound)
+	})
+
+	t.Run("Service endpoint not a URI", func(t *testing.T) {
+		rw := serve(h, testDID, "service=didcomm", "")
+		require.Equal(t, http.StatusNotFound, rw.Code)
+		requireError(t, rw, NotFound)
+	})
+
+	t.Run("Invalid relative reference", func(t *testing.T) {
+		rw := serve(h, testDID, "service=hub&relativeRef=%25zz", "")
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		requireError(t, rw, InvalidDIDURL)
+	})
+
+	t.Run("Invalid DID URL", func(t *testing.T) {
+		rw := serve(h, testDID+"#%zz", "", "")
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		requireError(t, rw, InvalidDIDURL)
+	})
+}
+
+func TestResolveHandler_Assurance(t *testing.T) {
+	const query = "requiredWitness=orb.domain1.com&requiredWitness=orb.domain2.com&requiredLog=vct.domain1.com"
+
+	t.Run("Success", func(t *testing.T) {
+		resolver := &assuranceResolver{result: newResolutionResult(false)}
+
+		rw := serve(NewResolveHandler(resolvePath, resolver, noop.GetMetrics()), testDID, query, "")
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, &assurance.Criteria{
+			Witnesses: []string{"orb.domain1.com", "orb.domain2.com"},
+			Logs:      []string{"vct.domain1.com"},
+		}, resolver.criteria)
+		require.Zero(t, resolver.ResolveDocumentCallCount())
+	})
+
+	t.Run("Not supported by resolver", func(t *testing.T) {
+		resolver := &mocks.OrbResolver{}
+
+		rw := serve(NewResolveHandler(resolvePath, resolver, noop.GetMetrics()), testDID, query, "")
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		requireError(t, rw, InvalidDIDURL)
+		require.Zero(t, resolver.ResolveDocumentCallCount())
+	})
+
+	t.Run("Combined with versionId", func(t *testing.T) {
+		resolver := &assuranceResolver{result: newResolutionResult(false)}
+
+		rw := serve(NewResolveHandler(resolvePath, resolver, noop.GetMetrics()), testDID,
+			query+"&versionId=uEiBqkaTRFZScQsXTw8IDBSpVxiKGqjJCDUcgiwpcd2frLw", "")
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		requireError(t, rw, InvalidDIDURL)
+		require.Nil(t, resolver.criteria)
+	})
+}
+
+type assuranceResolver struct {
+	mocks.OrbResolver
+
+	criteria *assurance.Criteria
+	result   *document.ResolutionResult
+}
+
+func (r *assuranceResolver) ResolveDocumentWithAssurance(_ string, criteria *assurance.Criteria,
+	_ ...document.ResolutionOption) (*document.ResolutionResult, error) {
+	r.criteria = criteria
+
+	return r.result, nil
+}
+
+func TestResolveHandler_Consensus(t *testing.T) {
+	md := &consensus.Metadata{
+		MinimumResolvers: 2,
+		Agreed:           []string{"https://orb.domain1.com", "https://orb.domain2.com"},
+		Disagreed: []*consensus.Disagreement{
+			{Resolver: "https://orb.domain3.com", Reason: "documents don't match"},
+		},
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		result := newResolutionResult(false)
+		result.DocumentMetadata[consensus.MetadataProperty] = md
+
+		resolver := &consensusResolver{result: result}
+
+		rw := serve(NewResolveHandler(resolvePath, resolver, noop.GetMetrics()), testDID, "consensus=true",
+			mediaTypeResolutionResult)
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, testDID, resolver.id)
+		require.Zero(t, resolver.ResolveDocumentCallCount())
+
+		resp := &ResolutionResult{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), resp))
+		require.Equal(t, md, resp.ResolutionMetadata.Consensus)
+		require.NotContains(t, resp.DocumentMetadata, consensus.MetadataProperty)
+	})
+
+	t.Run("Dereference", func(t *testing.T) {
+		result := newResolutionResult(false)
+		result.DocumentMetadata[consensus.MetadataProperty] = md
+
+		resolver := &consensusResolver{result: result}
+
+		rw := serve(NewResolveHandler(resolvePath, resolver, noop.GetMetrics()), testDID+"#key-1", "consensus=true",
+			mediaTypeDereferencingResult)
+		require.Equal(t, http.StatusOK, rw.Code)
+
+		resp := &DereferencingResult{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), resp))
+		require.Equal(t, md, resp.DereferencingMetadata.Consensus)
+		require.NotContains(t, resp.ContentMetadata, consensus.MetadataProperty)
+	})
+
+	t.Run("Consensus not reached", func(t *testing.T) {
+		resolver := &consensusResolver{err: fmt.Errorf("resolve document: %w", consensus.NewError(md))}
+
+		rw := serve(NewResolveHandler(resolvePath, resolver, noop.GetMetrics()), testDID, "consensus=true", "")
+		require.Equal(t, http.StatusInternalServerError, rw.Code)
+		requireError(t, rw, ConsensusNotReached)
+
+		resp := &ResolutionResult{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), resp))
+		require.Equal(t, md, resp.ResolutionMetadata.Consensus)
+	})
+
+	t.Run("Not requested", func(t *testing.T) {
+		resolver := &consensusResolver{}
+		resolver.ResolveDocumentReturns(newResolutionResult(false), nil)
+
+		rw := serve(NewResolveHandler(resolvePath, resolver, noop.GetMetrics()), testDID, "consensus=false", "")
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Empty(t, resolver.id)
+		require.Equal(t, 1, resolver.ResolveDocumentCallCount())
+	})
+
+	t.Run("Not supported by resolver", func(t *testing.T) {
+		resolver := &mocks.OrbResolver{}
+
+		rw := serve(NewResolveHandler(resolvePath, resolver, noop.GetMetrics()), testDID, "consensus=true", "")
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		requireError(t, rw, InvalidDIDURL)
+		require.Zero(t, resolver.ResolveDocumentCallCount())
+	})
+
+	t.Run("Invalid value", func(t *testing.T) {
+		resolver := &consensusResolver{}
+
+		rw := serve(NewResolveHandler(resolvePath, resolver, noop.GetMetrics()), testDID, "consensus=maybe", "")
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		requireError(t, rw, InvalidDIDURL)
+		require.Empty(t, resolver.id)
+	})
+
+	t.Run("Combined with versionId", func(t *testing.T) {
+		resolver := &consensusResolver{}
+
+		rw := serve(NewResolveHandler(resolvePath, resolver, noop.GetMetrics()), testDID,
+			"consensus=true&versionId=uEiBqkaTRFZScQsXTw8IDBSpVxiKGqjJCDUcgiwpcd2frLw", "")
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		requireError(t, rw, InvalidDIDURL)
+		require.Empty(t, resolver.id)
+	})
+}
+
+type consensusResolver struct {
+	mocks.OrbResolver
+
+	id     string
+	result *document.ResolutionResult
+	err    error
+}
+
+func (r *consensusResolver) ResolveDocumentWithConsensus(id string,
+	_ ...document.ResolutionOption) (*document.ResolutionResult, error) {
+	r.id = id
+
+	return r.result, r.err
+}
+
+func serve(h *ResolveHandler, id, query, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/sidetree/v1/identifiers/id?"+query, nil)
+
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	rw := httptest.NewRecorder()
+
+	h.Handler()(rw, mux.SetURLVars(req, map[string]string{"id": id}))
+
+	return rw
+}
+
+func requireError(t *testing.T, rw *httptest.ResponseRecorder, code ErrorCode) {
+	t.Helper()
+
+	result := &ResolutionResult{}
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), result))
+	require.NotNil(t, result.ResolutionMetadata)
+	require.Equal(t, code, result.ResolutionMetadata.Error)
+}
+
+func newResolutionResult(deactivated bool) *document.ResolutionResult {
+	return &document.ResolutionResult{
+		Context: ResolutionContext,
+		Document: document.Document{
+			"id": testDID,
+			"verificationMethod": []document.PublicKey{
+				{"id": testDID + "#key-1", "type": "JsonWebKey2020", "controller": testDID},
+			},
+			"authentication": []interface{}{
+				testDID + "#key-1",
+				map[string]interface{}{"id": "#key-2", "type": "JsonWebKey2020", "controller": testDID},
+			},
+			"service": []document.Service{
+				{"id": testDID + "#hub", "type": "hub", "serviceEndpoint": "https://example.com/hub/"},
+				{
+					"id": "#files", "type": "files",
+					"serviceEndpoint": []interface{}{map[string]interface{}{"uri": "https://files.example.com/a"}},
+				},
+				{"id": "#didcomm", "type": "DIDCommMessaging", "serviceEndpoint": map[string]interface{}{}},
+			},
+		},
+		DocumentMetadata: document.Metadata{
+			document.CanonicalIDProperty: testDID,
+			document.DeactivatedProperty: deactivated,
+		},
+	}
+}
@@ -0,0 +1,186 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package didresolution
+
+import (
+	"errors"
+	"net/http"
+
+	vdrapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+
+	"github.com/trustbloc/orb/pkg/document/consensus"
+	"github.com/trustbloc/orb/pkg/document/didresolver"
+	"github.com/trustbloc/orb/pkg/document/resolvehandler"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+)
+
+const (
+	// ResolutionContext is the JSON-LD context of a DID resolution result.
+	ResolutionContext = "https://w3id.org/did-resolution/v1"
+
+	// ResolutionProfile is the media type profile which requests a DID resolution result.
+	ResolutionProfile = "https://w3id.org/did-resolution"
+
+	// DereferencingProfile is the media type profile which requests a DID URL dereferencing result.
+	DereferencingProfile = "https://w3id.org/did-url-dereferencing"
+)
+
+// Media types.
+const (
+	MediaTypeDIDLDJSON = "application/did+ld+json"
+	MediaTypeDIDJSON   = "application/did+json"
+	MediaTypeLDJSON    = "application/ld+json"
+	MediaTypeJSON      = "application/json"
+	MediaTypeURIList   = "text/uri-list"
+
+	mediaTypeResolutionResult    = MediaTypeLDJSON + `;profile="` + ResolutionProfile + `"`
+	mediaTypeDereferencingResult = MediaTypeLDJSON + `;profile="` + DereferencingProfile + `"`
+)
+
+// ErrorCode is an error code which is returned in the DID resolution (or dereferencing) metadata.
+type ErrorCode string
+
+// Error codes as defined by the DID Resolution specification.
+const (
+	InvalidDID                 ErrorCode = "invalidDid"
+	InvalidDIDURL              ErrorCode = "invalidDidUrl"
+	NotFound                   ErrorCode = "notFound"
+	Deactivated                ErrorCode = "deactivated"
+	MethodNotSupported         ErrorCode = "methodNotSupported"
+	RepresentationNotSupported ErrorCode = "representationNotSupported"
+	InternalError              ErrorCode = "internalError"
+)
+
+// ConsensusNotReached is returned when a DID is resolved in consensus mode and the minimum number of
+// domains don't agree on the resolution result. This error code is specific to Orb.
+const ConsensusNotReached ErrorCode = "consensusNotReached"
+
+// Status returns the HTTP status code for the error code.
+func (c ErrorCode) Status() int {
+	switch c {
+	case InvalidDID, InvalidDIDURL:
+		return http.StatusBadRequest
+	case NotFound:
+		return http.StatusNotFound
+	case Deactivated:
+		return http.StatusGone
+	case MethodNotSupported:
+		return http.StatusNotImplemented
+	case RepresentationNotSupported:
+		return http.StatusNotAcceptable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Metadata contains the DID resolution (or dereferencing) metadata.
+type Metadata struct {
+	ContentType  string    `json:"contentType,omitempty"`
+	Error        ErrorCode `json:"error,omitempty"`
+	ErrorMessage string    `json:"errorMessage,omitempty"`
+
+	// Consensus contains the domains which agreed (and disagreed) on the result of a consensus resolution.
+	Consensus *consensus.Metadata `json:"consensus,omitempty"`
+}
+
+// ResolutionResult is a DID resolution result.
+type ResolutionResult struct {
+	Context            interface{}       `json:"@context"`
+	Document           document.Document `json:"didDocument"`
+	DocumentMetadata   document.Metadata `json:"didDocumentMetadata"`
+	ResolutionMetadata *Metadata         `json:"didResolutionMetadata"`
+}
+
+// DereferencingResult is a DID URL dereferencing result.
+type DereferencingResult struct {
+	Context               interface{}       `json:"@context"`
+	ContentStream         interface{}       `json:"contentStream"`
+	ContentMetadata       document.Metadata `json:"contentMetadata"`
+	DereferencingMetadata *Metadata         `json:"dereferencingMetadata"`
+}
+
+// resolutionError is an error which is returned to the client along with an error code.
+type resolutionError struct {
+	code ErrorCode
+	err  error
+}
+
+func newResolutionError(code ErrorCode, err error) *resolutionError {
+	return &resolutionError{code: code, err: err}
+}
+
+func (e *resolutionError) Error() string {
+	return e.err.Error()
+}
+
+func (e *resolutionError) Unwrap() error {
+	return e.err
+}
+
+// errorCodeFromResolverError maps the given resolver error to an error code. Only typed (or sentinel) errors
+// are mapped to a specific error code; all other errors are internal errors.
+func errorCodeFromResolverError(err error) ErrorCode {
+	var rErr *resolutionError
+	if errors.As(err, &rErr) {
+		return rErr.code
+	}
+
+	var cErr *consensus.Error
+	if errors.As(err, &cErr) {
+		return ConsensusNotReached
+	}
+
+	switch {
+	case errors.Is(err, resolvehandler.ErrDocumentNotFound),
+		errors.Is(err, orberrors.ErrContentNotFound),
+		errors.Is(err, vdrapi.ErrNotFound):
+		return NotFound
+	case errors.Is(err, didresolver.ErrMethodNotSupported):
+		return MethodNotSupported
+	case orberrors.IsBadRequest(err):
+		return InvalidDID
+	default:
+		return InternalError
+	}
+}
+
+func isDeactivated(metadata document.Metadata) bool {
+	deactivated, ok := metadata[document.DeactivatedProperty].(bool)
+
+	return ok && deactivated
+}
+
+// consensusMetadataFromError returns the consensus metadata (if any) from the given error.
+func consensusMetadataFromError(err error) *consensus.Metadata {
+	var cErr *consensus.Error
+	if errors.As(err, &cErr) {
+		return cErr.Metadata
+	}
+
+	return nil
+}
+
+// splitConsensusMetadata returns a copy of the given document metadata without the consensus metadata,
+// along with the consensus metadata (if any). The consensus metadata is returned in the resolution
+// (or dereferencing) metadata rather than in the document metadata.
+func splitConsensusMetadata(metadata document.Metadata) (document.Metadata, *consensus.Metadata) {
+	md, ok := metadata[consensus.MetadataProperty].(*consensus.Metadata)
+	if !ok {
+		return metadata, nil
+	}
+
+	docMetadata := make(document.Metadata, len(metadata))
+
+	for k, v := range metadata {
+		if k != consensus.MetadataProperty {
+			docMetadata[k] = v
+		}
+	}
+
+	return docMetadata, md
+}
@@ -7,12 +7,20 @@ SPDX-License-Identifier: Apache-2.0
 package didresolver
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/trustbloc/sidetree-core-go/pkg/document"
+
+	"github.com/trustbloc/orb/pkg/document/assurance"
+	"github.com/trustbloc/orb/pkg/document/consensus"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
 )
 
+// ErrMethodNotSupported is returned when the DID method of the given ID isn't supported.
+var ErrMethodNotSupported = errors.New("did method not supported")
+
 type webResolver interface {
 	ResolveDocument(id string) (*document.ResolutionResult, error)
 }
@@ -45,6 +53,42 @@ func (r *ResolveHandler) ResolveDocument(id string, opts ...document.ResolutionO
 	case strings.HasPrefix(id, "did:web"):
 		return r.webResolver.ResolveDocument(id)
 	default:
-		return nil, fmt.Errorf("did method not supported for id[%s]", id)
+		return nil, fmt.Errorf("%w for id[%s]", ErrMethodNotSupported, id)
+	}
+}
+
+// ResolveDocumentWithAssurance resolves a did:orb document using only the operations whose anchors satisfy
+// the given assurance criteria. Assurance criteria are not supported for did:web.
+func (r *ResolveHandler) ResolveDocumentWithAssurance(id string, criteria *assurance.Criteria,
+	opts ...document.ResolutionOption) (*document.ResolutionResult, error) {
+	if criteria.IsEmpty() {
+		return r.ResolveDocument(id, opts...)
+	}
+
+	if !strings.HasPrefix(id, "did:orb") {
+		return nil, orberrors.NewBadRequestf("bad request: assurance criteria not supported for id[%s]", id)
+	}
+
+	resolver, ok := r.orbResolver.(assurance.Resolver)
+	if !ok {
+		return nil, orberrors.NewBadRequestf("bad request: assurance criteria not supported for id[%s]", id)
+	}
+
+	return resolver.ResolveDocumentWithAssurance(id, criteria, opts...)
+}
+
+// ResolveDocumentWithConsensus resolves a did:orb document from multiple Orb domains. Consensus resolution
+// is not supported for did:web.
+func (r *ResolveHandler) ResolveDocumentWithConsensus(id string,
+	opts ...document.ResolutionOption) (*document.ResolutionResult, error) {
+	if !strings.HasPrefix(id, "did:orb") {
+		return nil, orberrors.NewBadRequestf("bad request: consensus resolution not supported for id[%s]", id)
+	}
+
+	resolver, ok := r.orbResolver.(consensus.Resolver)
+	if !ok {
+		return nil, orberrors.NewBadRequestf("bad request: consensus resolution not supported for id[%s]", id)
 	}
+
+	return resolver.ResolveDocumentWithConsensus(id, opts...)
 }
@@ -15,7 +15,9 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/trustbloc/sidetree-core-go/pkg/document"
 
+	"github.com/trustbloc/orb/pkg/document/assurance"
 	"github.com/trustbloc/orb/pkg/document/didresolver/mocks"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
 )
 
 func TestResolveHandler_Resolve(t *testing.T) {
@@ -59,6 +61,114 @@ func TestResolveHandler_Resolve(t *testing.T) {
 		response, err := handler.ResolveDocument("did:other:suffix")
 		require.Error(t, err)
 		require.Nil(t, response)
+		require.ErrorIs(t, err, ErrMethodNotSupported)
 		require.Contains(t, err.Error(), "did method not supported")
 	})
 }
+
+func TestResolveHandler_ResolveDocumentWithAssurance(t *testing.T) {
+	criteria := &assurance.Criteria{Witnesses: []string{"orb.domain1.com"}}
+
+	t.Run("success", func(t *testing.T) {
+		orbResolver := &assuranceResolver{result: &document.ResolutionResult{}}
+
+		handler := NewResolveHandler(orbResolver, &mocks.WebResolver{})
+
+		response, err := handler.ResolveDocumentWithAssurance("did:orb:suffix", criteria)
+		require.NoError(t, err)
+		require.NotNil(t, response)
+		require.Equal(t, criteria, orbResolver.criteria)
+	})
+
+	t.Run("no criteria", func(t *testing.T) {
+		webResolver := &mocks.WebResolver{}
+		webResolver.ResolveDocumentReturns(&document.ResolutionResult{}, nil)
+
+		handler := NewResolveHandler(&mocks.OrbResolver{}, webResolver)
+
+		response, err := handler.ResolveDocumentWithAssurance("did:web:suffix", nil)
+		require.NoError(t, err)
+		require.NotNil(t, response)
+	})
+
+	t.Run("error - not supported for did:web", func(t *testing.T) {
+		handler := NewResolveHandler(&assuranceResolver{}, &mocks.WebResolver{})
+
+		response, err := handler.ResolveDocumentWithAssurance("did:web:suffix", criteria)
+		require.Error(t, err)
+		require.Nil(t, response)
+		require.True(t, orberrors.IsBadRequest(err))
+		require.Contains(t, err.Error(), "assurance criteria not supported")
+	})
+
+	t.Run("error - not supported by orb resolver", func(t *testing.T) {
+		handler := NewResolveHandler(&mocks.OrbResolver{}, &mocks.WebResolver{})
+
+		response, err := handler.ResolveDocumentWithAssurance("did:orb:suffix", criteria)
+		require.Error(t, err)
+		require.Nil(t, response)
+		require.True(t, orberrors.IsBadRequest(err))
+		require.Contains(t, err.Error(), "assurance criteria not supported")
+	})
+}
+
+type assuranceResolver struct {
+	mocks.OrbResolver
+
+	criteria *assurance.Criteria
+	result   *document.ResolutionResult
+}
+
+func (r *assuranceResolver) ResolveDocumentWithAssurance(_ string, criteria *assurance.Criteria,
+	_ ...document.ResolutionOption) (*document.ResolutionResult, error) {
+	r.criteria = criteria
+
+	return r.result, nil
+}
+
+func TestResolveHandler_ResolveDocumentWithConsensus(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		orbResolver := &consensusResolver{result: &document.ResolutionResult{}}
+
+		handler := NewResolveHandler(orbResolver, &mocks.WebResolver{})
+
+		response, err := handler.ResolveDocumentWithConsensus("did:orb:suffix")
+		require.NoError(t, err)
+		require.NotNil(t, response)
+		require.Equal(t, "did:orb:suffix", orbResolver.id)
+	})
+
+	t.Run("error - not supported for did:web", func(t *testing.T) {
+		handler := NewResolveHandler(&consensusResolver{}, &mocks.WebResolver{})
+
+		response, err := handler.ResolveDocumentWithConsensus("did:web:suffix")
+		require.Error(t, err)
+		require.Nil(t, response)
+		require.True(t, orberrors.IsBadRequest(err))
+		require.Contains(t, err.Error(), "consensus resolution not supported")
+	})
+
+	t.Run("error - not supported by orb resolver", func(t *testing.T) {
+		handler := NewResolveHandler(&mocks.OrbResolver{}, &mocks.WebResolver{})
+
+		response, err := handler.ResolveDocumentWithConsensus("did:orb:suffix")
+		require.Error(t, err)
+		require.Nil(t, response)
+		require.True(t, orberrors.IsBadRequest(err))
+		require.Contains(t, err.Error(), "consensus resolution not supported")
+	})
+}
+
+type consensusResolver struct {
+	mocks.OrbResolver
+
+	id     string
+	result *document.ResolutionResult
+}
+
+func (r *consensusResolver) ResolveDocumentWithConsensus(id string,
+	_ ...document.ResolutionOption) (*document.ResolutionResult, error) {
+	r.id = id
+
+	return r.result, nil
+}
@@ -0,0 +1,254 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package history
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/piprate/json-gold/ld"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/anchor/witness/proof"
+	"github.com/trustbloc/orb/pkg/document/anchorcredential"
+	"github.com/trustbloc/orb/pkg/document/util"
+)
+
+var logger = log.New("did-history")
+
+// ErrNotFound is returned when no published operations exist for the requested DID.
+var ErrNotFound = errors.New("DID not found")
+
+// ErrInvalidDID is returned when the requested DID is not a valid DID for the configured namespace.
+var ErrInvalidDID = errors.New("invalid DID")
+
+type operationStore interface {
+	Get(suffix string) ([]*operation.AnchoredOperation, error)
+}
+
+type witnessStore interface {
+	Get(anchorID string) ([]*proof.WitnessProof, error)
+}
+
+// History contains the published operations of a DID (in the order in which they were anchored) along
+// with the provenance of the anchor of each operation.
+type History struct {
+	ID         string       `json:"id"`
+	Operations []*Operation `json:"operations"`
+}
+
+// Operation contains a published operation and the anchor in which it was included.
+type Operation struct {
+	Type               operation.Type `json:"type"`
+	ProtocolVersion    uint64         `json:"protocolVersion"`
+	TransactionTime    time.Time      `json:"transactionTime"`
+	TransactionNumber  uint64         `json:"transactionNumber"`
+	CanonicalReference string         `json:"canonicalReference"`
+	Anchor             *Anchor        `json:"anchor,omitempty"`
+}
+
+// Anchor contains the provenance of an anchor.
+type Anchor struct {
+	// Hashlink is the hashlink of the anchor linkset.
+	Hashlink string `json:"hashlink"`
+	// Anchor is the hashlink of the core index file.
+	Anchor string `json:"anchor"`
+	// Origin is the anchor origin, i.e. the service which created the anchor.
+	Origin string `json:"origin,omitempty"`
+	// Issued is the time at which the anchor credential was issued.
+	Issued *time.Time `json:"issued,omitempty"`
+	// Proofs contains the witness proofs of the anchor credential.
+	Proofs []*Proof `json:"proofs"`
+	// Witnesses contains the witnesses which were selected by this server for the anchor. This
+	// information is only available while the anchor is being witnessed by this server.
+	Witnesses []*WitnessStatus `json:"witnesses,omitempty"`
+}
+
+// Proof contains a witness proof of an anchor credential.
+type Proof struct {
+	// VerificationMethod is the verification method of the witness which signed the proof.
+	VerificationMethod string `json:"verificationMethod,omitempty"`
+	// Log is the VCT log in which the anchor credential was logged (if any).
+	Log string `json:"log,omitempty"`
+	// Created is the time at which the proof was created. If the anchor credential was logged then this
+	// is the timestamp of the VCT log entry.
+	Created string `json:"created,omitempty"`
+	// Proof is the complete proof so that it may be independently verified.
+	Proof verifiable.Proof `json:"proof"`
+}
+
+// WitnessStatus contains a witness of an anchor and whether or not its proof has been received.
+type WitnessStatus struct {
+	*proof.Witness
+	ProofReceived bool `json:"proofReceived"`
+}
+
+// Service assembles the history of a DID from the operation store, the anchor graph and the witness store.
+type Service struct {
+	namespace      string
+	opStore        operationStore
+	anchorGraph    anchorcredential.DIDAnchorGraph
+	witnessStore   witnessStore
+	documentLoader ld.DocumentLoader
+}
+
+// New returns a new DID history service.
+func New(namespace string, opStore operationStore, anchorGraph anchorcredential.DIDAnchorGraph, witnessStore witnessStore,
+	documentLoader ld.DocumentLoader) *Service {
+	return &Service{
+		namespace:      namespace,
+		opStore:        opStore,
+		anchorGraph:    anchorGraph,
+		witnessStore:   witnessStore,
+		documentLoader: documentLoader,
+	}
+}
+
+// Get returns the history of the given DID. ErrNotFound is returned if the DID has no published operations.
+func (s *Service) Get(did string) (*History, error) {
+	if !strings.HasPrefix(did, s.namespace+":") {
+		return nil, fmt.Errorf("%w: must start with [%s]: %s", ErrInvalidDID, s.namespace, did)
+	}
+
+	suffix, err := util.GetSuffix(did)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidDID, err)
+	}
+
+	ops, err := s.opStore.Get(suffix)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, did)
+		}
+
+		return nil, fmt.Errorf("get operations for suffix [%s]: %w", suffix, err)
+	}
+
+	util.SortOperations(ops)
+
+	anchors, err := s.getAnchors(suffix, ops[len(ops)-1].CanonicalReference)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &History{ID: did}
+
+	for _, op := range ops {
+		anchor, ok := anchors[op.CanonicalReference]
+		if !ok {
+			logger.Warn("Anchor for operation not found in the anchor graph", log.WithDID(did),
+				log.WithHashlink(op.CanonicalReference))
+		}
+
+		history.Operations = append(history.Operations, &Operation{
+			Type:               op.Type,
+			ProtocolVersion:    op.ProtocolVersion,
+			TransactionTime:    time.Unix(int64(op.TransactionTime), 0).UTC(),
+			TransactionNumber:  op.TransactionNumber,
+			CanonicalReference: op.CanonicalReference,
+			Anchor:             anchor,
+		})
+	}
+
+	return history, nil
+}
+
+// getAnchors returns the anchors of the given suffix (starting from the given anchor) mapped by reference.
+func (s *Service) getAnchors(suffix, latestRef string) (map[string]*Anchor, error) {
+	credentials, err := anchorcredential.Get(s.anchorGraph, suffix, latestRef, s.documentLoader)
+	if err != nil {
+		return nil, err
+	}
+
+	anchors := make(map[string]*Anchor, len(credentials))
+
+	for ref, ac := range credentials {
+		anchor, err := s.newAnchor(ac)
+		if err != nil {
+			return nil, err
+		}
+
+		anchors[ref] = anchor
+	}
+
+	return anchors, nil
+}
+
+func (s *Service) newAnchor(ac *anchorcredential.Credential) (*Anchor, error) {
+	vc := ac.VC
+
+	anchor := &Anchor{
+		Hashlink: ac.CID,
+		Anchor:   ac.Info.Anchor().String(),
+		Proofs:   make([]*Proof, len(vc.Proofs)),
+	}
+
+	if author := ac.Info.Author(); author != nil {
+		anchor.Origin = author.String()
+	}
+
+	if vc.Issued != nil {
+		issued := vc.Issued.Time.UTC()
+		anchor.Issued = &issued
+	}
+
+	for i, p := range vc.Proofs {
+		anchor.Proofs[i] = &Proof{
+			VerificationMethod: stringValue(p, "verificationMethod"),
+			Log:                stringValue(p, "domain"),
+			Created:            stringValue(p, "created"),
+			Proof:              p,
+		}
+	}
+
+	witnesses, err := s.getWitnesses(anchor.Anchor)
+	if err != nil {
+		return nil, err
+	}
+
+	anchor.Witnesses = witnesses
+
+	return anchor, nil
+}
+
+// getWitnesses returns the witnesses of the given anchor from the witness store. Witnesses are only stored
+// for anchors which were created by this server and are removed once the anchor has been witnessed, so nil
+// is returned if the anchor isn't found.
+func (s *Service) getWitnesses(anchorID string) ([]*WitnessStatus, error) {
+	witnessProofs, err := s.witnessStore.Get(anchorID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("get witnesses for anchor [%s]: %w", anchorID, err)
+	}
+
+	witnesses := make([]*WitnessStatus, len(witnessProofs))
+
+	for i, wp := range witnessProofs {
+		witnesses[i] = &WitnessStatus{
+			Witness:       wp.Witness,
+			ProofReceived: len(wp.Proof) > 0,
+		}
+	}
+
+	return witnesses, nil
+}
+
+func stringValue(p verifiable.Proof, property string) string {
+	v, ok := p[property].(string)
+	if !ok {
+		return ""
+	}
+
+	return v
+}
@@ -0,0 +1,249 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package history
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/util"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+
+	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	"github.com/trustbloc/orb/pkg/anchor/anchorlinkset"
+	"github.com/trustbloc/orb/pkg/anchor/anchorlinkset/generator"
+	"github.com/trustbloc/orb/pkg/anchor/builder"
+	"github.com/trustbloc/orb/pkg/anchor/graph"
+	"github.com/trustbloc/orb/pkg/anchor/subject"
+	"github.com/trustbloc/orb/pkg/anchor/witness/policy/mocks"
+	"github.com/trustbloc/orb/pkg/anchor/witness/proof"
+	"github.com/trustbloc/orb/pkg/datauri"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	"github.com/trustbloc/orb/pkg/internal/testutil"
+	"github.com/trustbloc/orb/pkg/linkset"
+	orbmocks "github.com/trustbloc/orb/pkg/mocks"
+)
+
+const (
+	testNS  = "did:orb"
+	testDID = "did:orb:uEiBqkaTRFZScQsXTw8IDBSpVxiKGqjJCDUcgiwpcd2frLw:suffix"
+
+	createRef = "uEiBqkaTRFZScQsXTw8IDBSpVxiKGqjJCDUcgiwpcd2frLw"
+	updateRef = "uEiAtvFg7Ti4-0MquG-sFMGRDcGUwz22JpCmOksomNTQGXw"
+
+	anchorOrigin = "https://orb.domain1.com"
+	witness1     = "https://orb.domain1.com/services/orb"
+	witness2     = "https://orb.domain2.com/services/orb"
+	vctLog       = "https://vct.domain1.com/maple2020"
+)
+
+func TestService_Get(t *testing.T) {
+	docLoader := testutil.GetLoader(t)
+
+	opStore := orbmocks.NewMockOperationStore()
+	require.NoError(t, opStore.Put([]*operation.AnchoredOperation{
+		{Type: operation.TypeUpdate, UniqueSuffix: "suffix", CanonicalReference: updateRef, TransactionTime: 2000},
+		{Type: operation.TypeCreate, UniqueSuffix: "suffix", CanonicalReference: createRef, TransactionTime: 1000},
+	}))
+
+	anchorGraph := &orbmocks.AnchorGraph{}
+	anchorGraph.GetDidAnchorsReturns([]graph.Anchor{
+		newTestAnchor(t, createRef, newProof(witness1, vctLog), newProof(witness2, "")),
+		newTestAnchor(t, updateRef, newProof(witness1, vctLog)),
+	}, nil)
+
+	t.Run("Success", func(t *testing.T) {
+		witnessStore := &mocks.WitnessStore{}
+		witnessStore.GetReturns(nil, errors.New("anchorID[xxx] not found in the store"))
+		witnessStore.GetReturnsOnCall(1, []*proof.WitnessProof{
+			{
+				Witness: &proof.Witness{
+					Type:     proof.WitnessTypeSystem,
+					URI:      vocab.NewURLProperty(testutil.MustParseURL(witness1)),
+					HasLog:   true,
+					Selected: true,
+				},
+				Proof: []byte(`{}`),
+			},
+			{
+				Witness: &proof.Witness{
+					Type: proof.WitnessTypeBatch,
+					URI:  vocab.NewURLProperty(testutil.MustParseURL(witness2)),
+				},
+			},
+		}, nil)
+
+		s := New(testNS, opStore, anchorGraph, witnessStore, docLoader)
+
+		h, err := s.Get(testDID)
+		require.NoError(t, err)
+		require.Equal(t, testDID, h.ID)
+		require.Len(t, h.Operations, 2)
+
+		create := h.Operations[0]
+		require.Equal(t, operation.TypeCreate, create.Type)
+		require.Equal(t, createRef, create.CanonicalReference)
+		require.Equal(t, time.Unix(1000, 0).UTC(), create.TransactionTime)
+		require.NotNil(t, create.Anchor)
+		require.Equal(t, hashlink.GetHashLinkFromResourceHash(createRef), create.Anchor.Hashlink)
+		require.Equal(t, anchorOrigin, create.Anchor.Origin)
+		require.NotNil(t, create.Anchor.Issued)
+		require.Len(t, create.Anchor.Proofs, 2)
+		require.Equal(t, witness1+"/keys/main-key", create.Anchor.Proofs[0].VerificationMethod)
+		require.Equal(t, vctLog, create.Anchor.Proofs[0].Log)
+		require.NotEmpty(t, create.Anchor.Proofs[0].Created)
+		require.NotEmpty(t, create.Anchor.Proofs[0].Proof)
+		require.Empty(t, create.Anchor.Proofs[1].Log)
+		require.Empty(t, create.Anchor.Witnesses)
+
+		update := h.Operations[1]
+		require.Equal(t, operation.TypeUpdate, update.Type)
+		require.Len(t, update.Anchor.Witnesses, 2)
+		require.True(t, update.Anchor.Witnesses[0].ProofReceived)
+		require.False(t, update.Anchor.Witnesses[1].ProofReceived)
+	})
+
+	t.Run("Anchor not found in anchor graph", func(t *testing.T) {
+		anchorGraph := &orbmocks.AnchorGraph{}
+		anchorGraph.GetDidAnchorsReturns([]graph.Anchor{newTestAnchor(t, createRef)}, nil)
+
+		witnessStore := &mocks.WitnessStore{}
+		witnessStore.GetReturns(nil, errors.New("not found"))
+
+		h, err := New(testNS, opStore, anchorGraph, witnessStore, docLoader).Get(testDID)
+		require.NoError(t, err)
+		require.Len(t, h.Operations, 2)
+		require.NotNil(t, h.Operations[0].Anchor)
+		require.Empty(t, h.Operations[0].Anchor.Proofs)
+		require.Nil(t, h.Operations[1].Anchor)
+	})
+
+	t.Run("Invalid DID", func(t *testing.T) {
+		s := New(testNS, opStore, anchorGraph, &mocks.WitnessStore{}, docLoader)
+
+		_, err := s.Get("did:web:suffix")
+		require.True(t, errors.Is(err, ErrInvalidDID))
+
+		_, err = s.Get("did:orb:suffix")
+		require.True(t, errors.Is(err, ErrInvalidDID))
+	})
+
+	t.Run("DID not found", func(t *testing.T) {
+		s := New(testNS, orbmocks.NewMockOperationStore(), anchorGraph, &mocks.WitnessStore{}, docLoader)
+
+		_, err := s.Get(testDID)
+		require.True(t, errors.Is(err, ErrNotFound))
+	})
+
+	t.Run("Operation store error", func(t *testing.T) {
+		s := New(testNS, &opStoreWithError{err: errors.New("injected store error")}, anchorGraph,
+			&mocks.WitnessStore{}, docLoader)
+
+		_, err := s.Get(testDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected store error")
+	})
+
+	t.Run("Anchor graph error", func(t *testing.T) {
+		anchorGraph := &orbmocks.AnchorGraph{}
+		anchorGraph.GetDidAnchorsReturns(nil, errors.New("injected anchor graph error"))
+
+		_, err := New(testNS, opStore, anchorGraph, &mocks.WitnessStore{}, docLoader).Get(testDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected anchor graph error")
+	})
+
+	t.Run("Invalid anchor", func(t *testing.T) {
+		anchorGraph := &orbmocks.AnchorGraph{}
+		s := New(testNS, opStore, anchorGraph, &mocks.WitnessStore{}, docLoader)
+
+		anchorGraph.GetDidAnchorsReturns([]graph.Anchor{{CID: "invalid", Info: &linkset.Link{}}}, nil)
+
+		_, err := s.Get(testDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get resource hash from anchor")
+
+		anchorGraph.GetDidAnchorsReturns([]graph.Anchor{
+			{CID: hashlink.GetHashLinkFromResourceHash(createRef), Info: &linkset.Link{}},
+		}, nil)
+
+		_, err = s.Get(testDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get verifiable credential from anchor")
+	})
+
+	t.Run("Witness store error", func(t *testing.T) {
+		witnessStore := &mocks.WitnessStore{}
+		witnessStore.GetReturns(nil, errors.New("injected witness store error"))
+
+		_, err := New(testNS, opStore, anchorGraph, witnessStore, docLoader).Get(testDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected witness store error")
+	})
+}
+
+type opStoreWithError struct {
+	err error
+}
+
+func (s *opStoreWithError) Get(string) ([]*operation.AnchoredOperation, error) {
+	return nil, s.err
+}
+
+func newProof(witness, domain string) verifiable.Proof {
+	p := verifiable.Proof{
+		"type":               "Ed25519Signature2018",
+		"proofPurpose":       "assertionMethod",
+		"verificationMethod": witness + "/keys/main-key",
+		"created":            "2022-01-20T18:19:15.139Z",
+		"jws":                "eyJhbGciOiJFZERTQSIsImI2NCI6ZmFsc2UsImNyaXQiOlsiYjY0Il19..signature",
+	}
+
+	if domain != "" {
+		p["domain"] = domain
+	}
+
+	return p
+}
+
+func newTestAnchor(t *testing.T, ref string, proofs ...verifiable.Proof) graph.Anchor {
+	t.Helper()
+
+	payload := &subject.Payload{
+		OperationCount:  1,
+		CoreIndex:       hashlink.GetHashLinkFromResourceHash(ref),
+		Namespace:       testNS,
+		AnchorOrigin:    anchorOrigin,
+		PreviousAnchors: []*subject.SuffixAnchor{{Suffix: "suffix"}},
+	}
+
+	al, _, err := anchorlinkset.NewBuilder(generator.NewRegistry()).BuildAnchorLink(payload,
+		datauri.MediaTypeDataURIGzipBase64,
+		func(anchorHashlink, coreIndexHashlink string) (*verifiable.Credential, error) {
+			return &verifiable.Credential{
+				Types:   []string{"VerifiableCredential", "AnchorCredential"},
+				Context: []string{vocab.ContextCredentials, vocab.ContextActivityAnchors},
+				Subject: &builder.CredentialSubject{
+					HRef:    anchorHashlink,
+					Type:    []string{"AnchorLink"},
+					Profile: "https://w3id.org/orb#v0",
+					Anchor:  coreIndexHashlink,
+					Rel:     "linkset",
+				},
+				Issuer: verifiable.Issuer{ID: anchorOrigin},
+				Issued: &util.TimeWrapper{Time: time.Now()},
+				Proofs: proofs,
+			}, nil
+		},
+	)
+	require.NoError(t, err)
+
+	return graph.Anchor{Info: al, CID: hashlink.GetHashLinkFromResourceHash(ref)}
+}
@@ -0,0 +1,32 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import "github.com/trustbloc/orb/pkg/document/history"
+
+// swagger:parameters didHistoryGetReq
+type didHistoryGetReq struct { //nolint: unused
+	// in: path
+	ID string `json:"id"`
+}
+
+// swagger:response didHistoryGetResp
+type didHistoryGetResp struct { //nolint: unused
+	// in: body
+	Body history.History
+}
+
+// getDIDHistory swagger:route GET /sidetree/v1/identifiers/{id}/history DID didHistoryGetReq
+//
+// Retrieves the published operations of a DID, in the order in which they were anchored, along with
+// the anchor hashlink, anchor origin, witness proofs and VCT log entries of each operation.
+//
+// Responses:
+//
+//	200: didHistoryGetResp
+func getDIDHistory() { //nolint: unused
+}
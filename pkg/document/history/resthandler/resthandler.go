@@ -0,0 +1,115 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/document/history"
+)
+
+const idPathVariable = "id"
+
+const (
+	badRequestResponse          = "Bad Request."
+	statusNotFoundResponse      = "Content Not Found."
+	internalServerErrorResponse = "Internal Server Error."
+)
+
+var logger = log.New("did-history-rest-handler")
+
+type historyService interface {
+	Get(did string) (*history.History, error)
+}
+
+// Handler returns the published operations of a DID along with the provenance of their anchors.
+type Handler struct {
+	path    string
+	service historyService
+	marshal func(interface{}) ([]byte, error)
+}
+
+// New returns a new DID history handler. The given path must contain the {id} variable.
+func New(path string, service historyService) *Handler {
+	return &Handler{
+		path:    path,
+		service: service,
+		marshal: json.Marshal,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the DID history handler.
+func (h *Handler) Path() string {
+	return h.path
+}
+
+// Method returns the HTTP REST method for the DID history handler.
+func (h *Handler) Method() string {
+	return http.MethodGet
+}
+
+// Handler returns the HTTP REST handle for the DID history handler.
+func (h *Handler) Handler() common.HTTPRequestHandler {
+	return h.handle
+}
+
+func (h *Handler) handle(w http.ResponseWriter, req *http.Request) {
+	did := mux.Vars(req)[idPathVariable]
+
+	hist, err := h.service.Get(did)
+	if err != nil {
+		switch {
+		case errors.Is(err, history.ErrInvalidDID):
+			logger.Debug("Invalid DID", log.WithDID(did), log.WithError(err))
+
+			writeResponse(w, http.StatusBadRequest, []byte(badRequestResponse))
+		case errors.Is(err, history.ErrNotFound):
+			logger.Debug("DID not found", log.WithDID(did), log.WithError(err))
+
+			writeResponse(w, http.StatusNotFound, []byte(statusNotFoundResponse))
+		default:
+			logger.Error("Error retrieving DID history", log.WithDID(did), log.WithError(err))
+
+			writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+		}
+
+		return
+	}
+
+	respBytes, err := h.marshal(hist)
+	if err != nil {
+		logger.Error("Error marshalling DID history", log.WithDID(did), log.WithError(err))
+
+		writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	writeResponse(w, http.StatusOK, respBytes)
+}
+
+func writeResponse(w http.ResponseWriter, status int, body []byte) {
+	w.WriteHeader(status)
+
+	if len(body) > 0 {
+		if _, err := w.Write(body); err != nil {
+			log.WriteResponseBodyError(logger, err)
+
+			return
+		}
+
+		log.WroteResponse(logger, body)
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+
+	"github.com/trustbloc/orb/pkg/document/history"
+)
+
+const (
+	historyPath = "/sidetree/v1/identifiers/{id}/history"
+	testDID     = "did:orb:uAAA:suffix"
+)
+
+func TestNew(t *testing.T) {
+	h := New(historyPath, &mockService{})
+	require.Equal(t, historyPath, h.Path())
+	require.Equal(t, http.MethodGet, h.Method())
+	require.NotNil(t, h.Handler())
+}
+
+func TestHandler(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		svc := &mockService{history: &history.History{
+			ID:         testDID,
+			Operations: []*history.Operation{{Type: operation.TypeCreate, CanonicalReference: "ref"}},
+		}}
+
+		rw := serve(New(historyPath, svc))
+		require.Equal(t, http.StatusOK, rw.Code)
+		require.Equal(t, "application/json", rw.Header().Get("Content-Type"))
+		require.Equal(t, testDID, svc.did)
+
+		h := &history.History{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), h))
+		require.Equal(t, svc.history, h)
+	})
+
+	t.Run("Invalid DID", func(t *testing.T) {
+		rw := serve(New(historyPath, &mockService{err: fmt.Errorf("%w: xxx", history.ErrInvalidDID)}))
+		require.Equal(t, http.StatusBadRequest, rw.Code)
+		require.Equal(t, badRequestResponse, rw.Body.String())
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		rw := serve(New(historyPath, &mockService{err: fmt.Errorf("%w: xxx", history.ErrNotFound)}))
+		require.Equal(t, http.StatusNotFound, rw.Code)
+		require.Equal(t, statusNotFoundResponse, rw.Body.String())
+	})
+
+	t.Run("Service error", func(t *testing.T) {
+		rw := serve(New(historyPath, &mockService{err: errors.New("injected error")}))
+		require.Equal(t, http.StatusInternalServerError, rw.Code)
+		require.Equal(t, internalServerErrorResponse, rw.Body.String())
+	})
+
+	t.Run("Marshal error", func(t *testing.T) {
+		h := New(historyPath, &mockService{history: &history.History{}})
+		h.marshal = func(interface{}) ([]byte, error) { return nil, errors.New("injected marshal error") }
+
+		rw := serve(h)
+		require.Equal(t, http.StatusInternalServerError, rw.Code)
+	})
+}
+
+func serve(h *Handler) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/sidetree/v1/identifiers/"+testDID+"/history", nil)
+
+	rw := httptest.NewRecorder()
+
+	h.Handler()(rw, mux.SetURLVars(req, map[string]string{idPathVariable: testDID}))
+
+	return rw
+}
+
+type mockService struct {
+	history *history.History
+	err     error
+	did     string
+}
+
+func (m *mockService) Get(did string) (*history.History, error) {
+	m.did = did
+
+	return m.history, m.err
+}
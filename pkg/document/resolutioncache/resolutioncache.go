@@ -0,0 +1,312 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resolutioncache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/google/uuid"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/cache"
+	"github.com/trustbloc/orb/pkg/pubsub/envelope"
+	"github.com/trustbloc/orb/pkg/pubsub/spi"
+)
+
+var logger = log.New("resolution-cache")
+
+const (
+	cacheName = "did-resolution"
+
+	defaultCacheSize       = 1000
+	defaultCacheExpiration = time.Minute
+
+	// didTopic is the topic over which invalidations are published to all instances. The topic is shared with
+	// the observer, which ignores invalidation messages (and vice versa).
+	didTopic = "orb.did"
+
+	// metadataInstanceID is the metadata property of an invalidation message which holds the ID of the
+	// instance that published the message, so that an instance doesn't process its own invalidations.
+	metadataInstanceID = "orb-instance-id"
+)
+
+// invalidationSchema is the schema of the message which holds the suffixes of invalidated resolution results.
+// The schema version must be incremented (and an upcaster added) whenever the structure of the message changes
+// in a way that isn't backward compatible.
+var invalidationSchema = envelope.New("did-invalidation", 1)
+
+type operationProcessor interface {
+	Resolve(uniqueSuffix string, opts ...document.ResolutionOption) (*protocol.ResolutionModel, error)
+}
+
+type metricsProvider interface {
+	ResolverIncrementCacheHitCount()
+	ResolverIncrementCacheMissCount()
+}
+
+type pubSub interface {
+	Publish(topic string, messages ...*message.Message) error
+	SubscribeWithOpts(ctx context.Context, topic string, opts ...spi.Option) (<-chan *message.Message, error)
+}
+
+type unpublishedOperationStore interface {
+	Put(op *operation.AnchoredOperation) error
+	Delete(op *operation.AnchoredOperation) error
+}
+
+type options struct {
+	size       int
+	expiration time.Duration
+	pubSub     pubSub
+}
+
+// Option is a resolution cache option.
+type Option func(opts *options)
+
+// WithCacheSize sets the maximum number of resolution results held in the cache.
+func WithCacheSize(value int) Option {
+	return func(opts *options) {
+		opts.size = value
+	}
+}
+
+// WithCacheExpiration sets the expiration of cached resolution results.
+func WithCacheExpiration(value time.Duration) Option {
+	return func(opts *options) {
+		opts.expiration = value
+	}
+}
+
+// WithPubSub sets the publisher/subscriber over which invalidations are published to (and received from) all
+// instances. This is required if the cache provider isn't shared by all instances (e.g. a memory cache).
+func WithPubSub(value pubSub) Option {
+	return func(opts *options) {
+		opts.pubSub = value
+	}
+}
+
+// Cache is an operation processor which caches the resolution result (resolution model) of a DID suffix so that
+// the operations of the DID aren't replayed on every resolution. The cached result of a suffix must be invalidated
+// whenever an operation for the suffix is added, i.e. when an anchor containing the suffix is processed by the
+// observer or when an unpublished operation is added. If a publisher/subscriber is provided then an invalidation
+// is published to all instances.
+type Cache struct {
+	processor  operationProcessor
+	cache      cache.Cache
+	metrics    metricsProvider
+	pubSub     pubSub
+	instanceID string
+}
+
+// entry is the cached resolution result of a suffix.
+type entry struct {
+	Model *protocol.ResolutionModel `json:"model"`
+
+	// loaded is set when the entry is loaded by this instance and cleared by the first resolution that
+	// consumes it, so that a load is counted as exactly one cache miss.
+	loaded int32
+}
+
+// New returns a new resolution cache.
+func New(processor operationProcessor, cacheProvider cache.Provider, metrics metricsProvider,
+	opts ...Option) (*Cache, error) {
+	optns := &options{
+		size:       defaultCacheSize,
+		expiration: defaultCacheExpiration,
+	}
+
+	for _, opt := range opts {
+		opt(optns)
+	}
+
+	c := &Cache{
+		processor:  processor,
+		metrics:    metrics,
+		pubSub:     optns.pubSub,
+		instanceID: uuid.New().String(),
+	}
+
+	c.cache = cacheProvider.NewCache(cacheName, c.load,
+		cache.WithSize(optns.size), cache.WithExpiration(optns.expiration),
+		cache.WithCodec(cache.NewJSONCodec(func() interface{} { return &entry{} })),
+	)
+
+	if c.pubSub != nil {
+		logger.Info("Subscribing to topic for invalidations", log.WithTopic(didTopic))
+
+		msgChan, err := c.pubSub.SubscribeWithOpts(context.Background(), didTopic, spi.WithBroadcast())
+		if err != nil {
+			return nil, fmt.Errorf("subscribe to topic [%s]: %w", didTopic, err)
+		}
+
+		go c.listen(msgChan)
+	}
+
+	return c, nil
+}
+
+// Resolve returns the resolution result of the given suffix. The result is cached unless resolution options
+// (such as a version ID or additional operations) are provided, in which case the operations are always replayed.
+func (c *Cache) Resolve(suffix string, opts ...document.ResolutionOption) (*protocol.ResolutionModel, error) {
+	if len(opts) > 0 {
+		return c.processor.Resolve(suffix, opts...)
+	}
+
+	value, err := c.cache.Get(suffix)
+	if err != nil {
+		return nil, err
+	}
+
+	e := value.(*entry) //nolint:forcetypeassert
+
+	if atomic.CompareAndSwapInt32(&e.loaded, 1, 0) {
+		c.metrics.ResolverIncrementCacheMissCount()
+	} else {
+		logger.Debug("Resolved suffix from cache", log.WithSuffix(suffix))
+
+		c.metrics.ResolverIncrementCacheHitCount()
+	}
+
+	// Return a copy so that the caller can't modify the cached value.
+	rm := *e.Model
+
+	return &rm, nil
+}
+
+// Invalidate removes the cached resolution results of the given suffixes and publishes the invalidation
+// to the other instances.
+func (c *Cache) Invalidate(suffixes ...string) {
+	c.invalidate(suffixes...)
+
+	if c.pubSub != nil && len(suffixes) > 0 {
+		c.publish(suffixes)
+	}
+}
+
+func (c *Cache) invalidate(suffixes ...string) {
+	for _, suffix := range suffixes {
+		if err := c.cache.Invalidate(suffix); err != nil {
+			// The cached value will be replaced when it expires.
+			logger.Warn("Error invalidating cached resolution result", log.WithSuffix(suffix), log.WithError(err))
+
+			continue
+		}
+
+		logger.Debug("Invalidated cached resolution result", log.WithSuffix(suffix))
+	}
+}
+
+// UnpublishedOperationStore returns an unpublished operation store which invalidates the cached resolution result
+// of a suffix whenever an unpublished operation for the suffix is added to (or deleted from) the given store.
+func (c *Cache) UnpublishedOperationStore(store unpublishedOperationStore) *UnpublishedOperationStore {
+	return &UnpublishedOperationStore{
+		store: store,
+		cache: c,
+	}
+}
+
+func (c *Cache) publish(suffixes []string) {
+	payload, err := json.Marshal(suffixes)
+	if err != nil {
+		logger.Error("Error marshalling invalidated suffixes", log.WithError(err))
+
+		return
+	}
+
+	msg := invalidationSchema.NewMessage(payload)
+	msg.Metadata.Set(metadataInstanceID, c.instanceID)
+
+	logger.Debug("Publishing invalidated suffixes", log.WithTopic(didTopic), log.WithSuffixes(suffixes...))
+
+	if err := c.pubSub.Publish(didTopic, msg); err != nil {
+		// The cached values on the other instances will be replaced when they expire.
+		logger.Warn("Error publishing invalidated suffixes", log.WithTopic(didTopic),
+			log.WithSuffixes(suffixes...), log.WithError(err))
+	}
+}
+
+func (c *Cache) listen(msgChan <-chan *message.Message) {
+	for msg := range msgChan {
+		c.handleMessage(msg)
+	}
+
+	logger.Debug("Invalidation listener stopped")
+}
+
+// handleMessage invalidates the suffixes of an invalidation message published by another instance. Messages of
+// other schemas (i.e. DIDs which are processed by the observer) are ignored.
+func (c *Cache) handleMessage(msg *message.Message) {
+	defer msg.Ack()
+
+	if msg.Metadata.Get(envelope.MetadataSchema) != invalidationSchema.Name() ||
+		msg.Metadata.Get(metadataInstanceID) == c.instanceID {
+		return
+	}
+
+	payload, err := invalidationSchema.Payload(msg)
+	if err != nil {
+		logger.Warn("Error reading invalidation message", log.WithMessageID(msg.UUID), log.WithError(err))
+
+		return
+	}
+
+	var suffixes []string
+
+	if err := json.Unmarshal(payload, &suffixes); err != nil {
+		logger.Warn("Error unmarshalling invalidation message", log.WithMessageID(msg.UUID), log.WithError(err))
+
+		return
+	}
+
+	c.invalidate(suffixes...)
+}
+
+func (c *Cache) load(suffix string) (interface{}, error) {
+	rm, err := c.processor.Resolve(suffix)
+	if err != nil {
+		// The error isn't wrapped since the resolver inspects the message (e.g. for "not found").
+		return nil, err
+	}
+
+	return &entry{Model: rm, loaded: 1}, nil
+}
+
+// UnpublishedOperationStore is an unpublished operation store which invalidates cached resolution results.
+type UnpublishedOperationStore struct {
+	store unpublishedOperationStore
+	cache *Cache
+}
+
+// Put saves the unpublished operation and invalidates the cached resolution result of the operation's suffix.
+func (s *UnpublishedOperationStore) Put(op *operation.AnchoredOperation) error {
+	if err := s.store.Put(op); err != nil {
+		return err
+	}
+
+	s.cache.Invalidate(op.UniqueSuffix)
+
+	return nil
+}
+
+// Delete deletes the unpublished operation and invalidates the cached resolution result of the operation's suffix.
+func (s *UnpublishedOperationStore) Delete(op *operation.AnchoredOperation) error {
+	if err := s.store.Delete(op); err != nil {
+		return err
+	}
+
+	s.cache.Invalidate(op.UniqueSuffix)
+
+	return nil
+}
@@ -0,0 +1,343 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resolutioncache
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+
+	"github.com/trustbloc/orb/pkg/cache"
+	"github.com/trustbloc/orb/pkg/cache/memory"
+	"github.com/trustbloc/orb/pkg/mocks"
+	"github.com/trustbloc/orb/pkg/pubsub/envelope"
+	"github.com/trustbloc/orb/pkg/pubsub/mempubsub"
+)
+
+const (
+	suffix1 = "EiDJpL-xeSE4kVgoGjaQm_OX5y5Dy3Nfr1Ld7cdMZwqb0A"
+	suffix2 = "EiBn4uIWbAQsJ6RJX6YFnrDW8lqIMFWsSqpd8dVl9Lq8Tg"
+)
+
+func TestCache_Resolve(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		processor := newMockProcessor()
+		metrics := &mockMetrics{}
+
+		c, err := New(processor, memory.New(), metrics, WithCacheSize(10), WithCacheExpiration(time.Minute))
+		require.NoError(t, err)
+
+		rm, err := c.Resolve(suffix1)
+		require.NoError(t, err)
+		require.Equal(t, suffix1, rm.Doc.ID())
+		require.Equal(t, 1, processor.callCount(suffix1))
+		require.Equal(t, 1, metrics.misses)
+		require.Equal(t, 0, metrics.hits)
+
+		// Modifying the returned model shouldn't affect the cached model.
+		rm.Deactivated = true
+
+		rm, err = c.Resolve(suffix1)
+		require.NoError(t, err)
+		require.Equal(t, suffix1, rm.Doc.ID())
+		require.False(t, rm.Deactivated)
+		require.Equal(t, 1, processor.callCount(suffix1))
+		require.Equal(t, 1, metrics.misses)
+		require.Equal(t, 1, metrics.hits)
+
+		_, err = c.Resolve(suffix2)
+		require.NoError(t, err)
+		require.Equal(t, 1, processor.callCount(suffix2))
+		require.Equal(t, 2, metrics.misses)
+
+		c.Invalidate(suffix1)
+
+		_, err = c.Resolve(suffix1)
+		require.NoError(t, err)
+		require.Equal(t, 2, processor.callCount(suffix1))
+		require.Equal(t, 3, metrics.misses)
+
+		_, err = c.Resolve(suffix2)
+		require.NoError(t, err)
+		require.Equal(t, 1, processor.callCount(suffix2))
+		require.Equal(t, 2, metrics.hits)
+	})
+
+	t.Run("resolution options -> not cached", func(t *testing.T) {
+		processor := newMockProcessor()
+		metrics := &mockMetrics{}
+
+		c, err := New(processor, memory.New(), metrics)
+		require.NoError(t, err)
+
+		for i := 0; i < 2; i++ {
+			rm, err := c.Resolve(suffix1, document.WithVersionID("1234"))
+			require.NoError(t, err)
+			require.Equal(t, suffix1, rm.Doc.ID())
+		}
+
+		require.Equal(t, 2, processor.callCount(suffix1))
+		require.Equal(t, 0, metrics.misses)
+		require.Equal(t, 0, metrics.hits)
+	})
+
+	t.Run("processor error -> not cached", func(t *testing.T) {
+		processor := newMockProcessor()
+		processor.err = fmt.Errorf("suffix[%s] not found in the store", suffix1)
+
+		c, err := New(processor, memory.New(), &mockMetrics{})
+		require.NoError(t, err)
+
+		for i := 0; i < 2; i++ {
+			rm, err := c.Resolve(suffix1)
+			require.EqualError(t, err, processor.err.Error())
+			require.Nil(t, rm)
+		}
+
+		require.Equal(t, 2, processor.callCount(suffix1))
+	})
+
+	t.Run("invalidate error", func(t *testing.T) {
+		c, err := New(newMockProcessor(), &mockCacheProvider{err: errors.New("injected invalidate error")},
+			&mockMetrics{})
+		require.NoError(t, err)
+
+		require.NotPanics(t, func() { c.Invalidate(suffix1) })
+	})
+}
+
+func TestCache_PubSub(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		ps := mempubsub.New(mempubsub.DefaultConfig())
+		defer func() { require.NoError(t, ps.Close()) }()
+
+		processor1 := newMockProcessor()
+
+		c1, err := New(processor1, memory.New(), &mockMetrics{}, WithPubSub(ps))
+		require.NoError(t, err)
+
+		processor2 := newMockProcessor()
+
+		c2, err := New(processor2, memory.New(), &mockMetrics{}, WithPubSub(ps))
+		require.NoError(t, err)
+
+		_, err = c1.Resolve(suffix1)
+		require.NoError(t, err)
+
+		_, err = c2.Resolve(suffix1)
+		require.NoError(t, err)
+
+		// A DID message (which is processed by the observer) shouldn't invalidate anything.
+		require.NoError(t, ps.Publish(didTopic, envelope.New("did", 1).NewMessage([]byte(`"did:orb:123"`))))
+
+		c1.Invalidate(suffix1)
+
+		_, err = c1.Resolve(suffix1)
+		require.NoError(t, err)
+		require.Equal(t, 2, processor1.callCount(suffix1))
+
+		require.Eventually(t, func() bool {
+			_, err = c2.Resolve(suffix1)
+			require.NoError(t, err)
+
+			return processor2.callCount(suffix1) == 2
+		}, time.Second, 10*time.Millisecond)
+
+		// The instance that published the invalidation shouldn't invalidate its cache again.
+		time.Sleep(100 * time.Millisecond)
+
+		_, err = c1.Resolve(suffix1)
+		require.NoError(t, err)
+		require.Equal(t, 2, processor1.callCount(suffix1))
+	})
+
+	t.Run("invalid message", func(t *testing.T) {
+		ps := &mocks.PubSub{}
+
+		msgChan := make(chan *message.Message, 1)
+
+		ps.SubscribeWithOptsReturns(msgChan, nil)
+
+		processor := newMockProcessor()
+
+		c, err := New(processor, memory.New(), &mockMetrics{}, WithPubSub(ps))
+		require.NoError(t, err)
+
+		_, err = c.Resolve(suffix1)
+		require.NoError(t, err)
+
+		msg := invalidationSchema.NewMessage([]byte("{"))
+
+		msgChan <- msg
+
+		select {
+		case <-msg.Acked():
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for ack")
+		}
+
+		_, err = c.Resolve(suffix1)
+		require.NoError(t, err)
+		require.Equal(t, 1, processor.callCount(suffix1))
+
+		close(msgChan)
+	})
+
+	t.Run("subscribe error", func(t *testing.T) {
+		errExpected := errors.New("injected subscribe error")
+
+		ps := &mocks.PubSub{}
+		ps.SubscribeWithOptsReturns(nil, errExpected)
+
+		c, err := New(newMockProcessor(), memory.New(), &mockMetrics{}, WithPubSub(ps))
+		require.ErrorIs(t, err, errExpected)
+		require.Nil(t, c)
+	})
+
+	t.Run("publish error", func(t *testing.T) {
+		ps := &mocks.PubSub{}
+		ps.SubscribeWithOptsReturns(make(chan *message.Message), nil)
+		ps.PublishReturns(errors.New("injected publish error"))
+
+		c, err := New(newMockProcessor(), memory.New(), &mockMetrics{}, WithPubSub(ps))
+		require.NoError(t, err)
+
+		require.NotPanics(t, func() { c.Invalidate(suffix1) })
+		require.Equal(t, 1, ps.PublishCallCount())
+	})
+}
+
+func TestUnpublishedOperationStore(t *testing.T) {
+	op := &operation.AnchoredOperation{UniqueSuffix: suffix1}
+
+	t.Run("success", func(t *testing.T) {
+		processor := newMockProcessor()
+
+		c, err := New(processor, memory.New(), &mockMetrics{})
+		require.NoError(t, err)
+
+		s := c.UnpublishedOperationStore(&mockUnpublishedOpStore{})
+
+		_, err = c.Resolve(suffix1)
+		require.NoError(t, err)
+
+		require.NoError(t, s.Put(op))
+
+		_, err = c.Resolve(suffix1)
+		require.NoError(t, err)
+		require.Equal(t, 2, processor.callCount(suffix1))
+
+		require.NoError(t, s.Delete(op))
+
+		_, err = c.Resolve(suffix1)
+		require.NoError(t, err)
+		require.Equal(t, 3, processor.callCount(suffix1))
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		processor := newMockProcessor()
+
+		c, err := New(processor, memory.New(), &mockMetrics{})
+		require.NoError(t, err)
+
+		errExpected := errors.New("injected store error")
+
+		s := c.UnpublishedOperationStore(&mockUnpublishedOpStore{err: errExpected})
+
+		_, err = c.Resolve(suffix1)
+		require.NoError(t, err)
+
+		require.ErrorIs(t, s.Put(op), errExpected)
+		require.ErrorIs(t, s.Delete(op), errExpected)
+
+		_, err = c.Resolve(suffix1)
+		require.NoError(t, err)
+		require.Equal(t, 1, processor.callCount(suffix1))
+	})
+}
+
+type mockProcessor struct {
+	mutex sync.Mutex
+	calls map[string]int
+	err   error
+}
+
+func newMockProcessor() *mockProcessor {
+	return &mockProcessor{calls: make(map[string]int)}
+}
+
+func (m *mockProcessor) Resolve(suffix string, _ ...document.ResolutionOption) (*protocol.ResolutionModel, error) {
+	m.mutex.Lock()
+	m.calls[suffix]++
+	m.mutex.Unlock()
+
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &protocol.ResolutionModel{Doc: document.Document{"id": suffix}}, nil
+}
+
+func (m *mockProcessor) callCount(suffix string) int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.calls[suffix]
+}
+
+type mockMetrics struct {
+	hits   int
+	misses int
+}
+
+func (m *mockMetrics) ResolverIncrementCacheHitCount() {
+	m.hits++
+}
+
+func (m *mockMetrics) ResolverIncrementCacheMissCount() {
+	m.misses++
+}
+
+type mockUnpublishedOpStore struct {
+	err error
+}
+
+func (m *mockUnpublishedOpStore) Put(*operation.AnchoredOperation) error {
+	return m.err
+}
+
+func (m *mockUnpublishedOpStore) Delete(*operation.AnchoredOperation) error {
+	return m.err
+}
+
+type mockCacheProvider struct {
+	err error
+}
+
+func (m *mockCacheProvider) NewCache(string, cache.Loader, ...cache.Option) cache.Cache {
+	return &mockCache{err: m.err}
+}
+
+type mockCache struct {
+	err error
+}
+
+func (m *mockCache) Get(string) (interface{}, error) {
+	return nil, m.err
+}
+
+func (m *mockCache) Invalidate(string) error {
+	return m.err
+}
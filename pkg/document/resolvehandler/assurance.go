@@ -0,0 +1,168 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resolvehandler
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/document/anchorcredential"
+	"github.com/trustbloc/orb/pkg/document/assurance"
+	"github.com/trustbloc/orb/pkg/document/util"
+)
+
+// ErrAssuranceNotSupported indicates that the resolve handler was not configured with the providers
+// that are required in order to resolve with assurance criteria.
+var ErrAssuranceNotSupported = errors.New("bad request: resolution with assurance criteria is not supported")
+
+// ResolveDocumentWithAssurance resolves the document using only the operations whose anchors satisfy the
+// given assurance criteria. Since each operation is bound to the previous operation (via commitments),
+// the resolved document is the latest state before the first operation whose anchor doesn't satisfy
+// the criteria. The operations which were excluded, along with the reason, are returned in the
+// "assurance" document metadata.
+//
+// Note that the proofs of an anchor are not re-verified since they were verified when the anchor was
+// processed by the observer. Resolution from the anchor origin is not performed since the operations
+// of the anchor origin (which may not yet have been witnessed) can't be evaluated.
+func (r *ResolveHandler) ResolveDocumentWithAssurance(id string, criteria *assurance.Criteria,
+	opts ...document.ResolutionOption) (*document.ResolutionResult, error) {
+	if criteria.IsEmpty() {
+		return r.ResolveDocument(id, opts...)
+	}
+
+	if r.opStore == nil || r.documentLoader == nil {
+		return nil, ErrAssuranceNotSupported
+	}
+
+	resOpts, err := document.GetResolutionOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if resOpts.VersionID != "" || resOpts.VersionTime != "" {
+		return nil, fmt.Errorf("bad request: assurance criteria may not be combined with versionId or versionTime")
+	}
+
+	suffix, err := util.GetSuffix(id)
+	if err != nil {
+		return nil, fmt.Errorf("bad request: %w", err)
+	}
+
+	ops, err := r.opStore.Get(suffix)
+	if err != nil {
+		return nil, fmt.Errorf("get operations for suffix [%s]: %w", suffix, err)
+	}
+
+	util.SortOperations(ops)
+
+	accepted, skipped, err := r.evaluateOperations(suffix, ops, criteria)
+	if err != nil {
+		return nil, err
+	}
+
+	if accepted == nil {
+		logger.Debug("None of the operations satisfy the assurance criteria", log.WithDID(id))
+
+		return nil, fmt.Errorf("resolve document [%s]: %w", id, ErrDocumentNotFound)
+	}
+
+	result, err := r.resolveDocumentLocally(id, append(opts, document.WithVersionID(accepted.CanonicalReference))...)
+	if err != nil {
+		return nil, err
+	}
+
+	unpublishedOps, err := util.GetUnpublishedOperationsFromMetadata(result.DocumentMetadata)
+	if err == nil {
+		for _, op := range unpublishedOps {
+			skipped = append(skipped, newSkippedOperation(op, "operation has not been anchored"))
+		}
+	}
+
+	if result.DocumentMetadata == nil {
+		result.DocumentMetadata = make(document.Metadata)
+	}
+
+	result.DocumentMetadata[assurance.MetadataProperty] = &assurance.Metadata{
+		Criteria:          criteria,
+		SkippedOperations: skipped,
+	}
+
+	return result, nil
+}
+
+// evaluateOperations returns the last operation (in anchoring order) of the sequence of operations which
+// satisfy the given criteria along with the operations that were skipped. Nil is returned for the
+// accepted operation if the create operation doesn't satisfy the criteria.
+func (r *ResolveHandler) evaluateOperations(suffix string, ops []*operation.AnchoredOperation,
+	criteria *assurance.Criteria) (*operation.AnchoredOperation, []*assurance.SkippedOperation, error) {
+	proofs, err := r.getAnchorProofs(suffix, ops[len(ops)-1].CanonicalReference)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		accepted *operation.AnchoredOperation
+		skipped  []*assurance.SkippedOperation
+	)
+
+	for _, op := range ops {
+		var reasons []string
+
+		anchorProofs, ok := proofs[op.CanonicalReference]
+		if ok {
+			reasons = criteria.Evaluate(anchorProofs)
+		} else {
+			reasons = []string{"anchor not found in the anchor graph"}
+		}
+
+		if len(skipped) == 0 && len(reasons) == 0 {
+			accepted = op
+
+			continue
+		}
+
+		if len(reasons) == 0 {
+			reasons = []string{fmt.Sprintf("follows skipped operation [%s]", skipped[0].CanonicalReference)}
+		}
+
+		skipped = append(skipped, newSkippedOperation(op, strings.Join(reasons, "; ")))
+	}
+
+	return accepted, skipped, nil
+}
+
+// getAnchorProofs returns the proofs of all anchors of the given suffix (starting from the given anchor)
+// mapped by anchor reference.
+func (r *ResolveHandler) getAnchorProofs(suffix, latestRef string) (map[string][]verifiable.Proof, error) {
+	credentials, err := anchorcredential.Get(r.anchorGraph, suffix, latestRef, r.documentLoader)
+	if err != nil {
+		return nil, err
+	}
+
+	proofs := make(map[string][]verifiable.Proof, len(credentials))
+
+	for ref, ac := range credentials {
+		proofs[ref] = ac.VC.Proofs
+	}
+
+	return proofs, nil
+}
+
+func newSkippedOperation(op *operation.AnchoredOperation, reason string) *assurance.SkippedOperation {
+	return &assurance.SkippedOperation{
+		Type:               op.Type,
+		CanonicalReference: op.CanonicalReference,
+		TransactionTime:    op.TransactionTime,
+		Reason:             reason,
+	}
+}
@@ -0,0 +1,306 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resolvehandler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/util"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+
+	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	"github.com/trustbloc/orb/pkg/anchor/anchorlinkset"
+	"github.com/trustbloc/orb/pkg/anchor/anchorlinkset/generator"
+	"github.com/trustbloc/orb/pkg/anchor/builder"
+	"github.com/trustbloc/orb/pkg/anchor/graph"
+	"github.com/trustbloc/orb/pkg/anchor/subject"
+	"github.com/trustbloc/orb/pkg/datauri"
+	"github.com/trustbloc/orb/pkg/document/assurance"
+	"github.com/trustbloc/orb/pkg/document/mocks"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	"github.com/trustbloc/orb/pkg/internal/testutil"
+	"github.com/trustbloc/orb/pkg/linkset"
+	orbmocks "github.com/trustbloc/orb/pkg/mocks"
+)
+
+const (
+	createRef  = "uEiBqkaTRFZScQsXTw8IDBSpVxiKGqjJCDUcgiwpcd2frLw"
+	updateRef  = "uEiAtvFg7Ti4-0MquG-sFMGRDcGUwz22JpCmOksomNTQGXw"
+	update2Ref = "uEiAK4KusHyrEyiNE2fdYuOJQG8t55w6XqFdloCdKW-0jnA"
+
+	witness1 = "https://orb.domain1.com/services/orb"
+	witness2 = "https://orb.domain2.com/services/orb"
+	vctLog1  = "https://vct.domain1.com/maple2020"
+)
+
+func TestResolveHandler_ResolveDocumentWithAssurance(t *testing.T) {
+	docLoader := testutil.GetLoader(t)
+
+	opStore := orbmocks.NewMockOperationStore()
+	require.NoError(t, opStore.Put([]*operation.AnchoredOperation{
+		{Type: operation.TypeUpdate, UniqueSuffix: "suffix", CanonicalReference: update2Ref, TransactionTime: 3},
+		{Type: operation.TypeCreate, UniqueSuffix: "suffix", CanonicalReference: createRef, TransactionTime: 1},
+		{Type: operation.TypeUpdate, UniqueSuffix: "suffix", CanonicalReference: updateRef, TransactionTime: 2},
+	}))
+
+	anchorGraph := &orbmocks.AnchorGraph{}
+	anchorGraph.GetDidAnchorsReturns([]graph.Anchor{
+		newTestAnchor(t, createRef, newProof(witness1, vctLog1), newProof(witness2, vctLog1)),
+		newTestAnchor(t, updateRef, newProof(witness1, vctLog1)),
+		newTestAnchor(t, update2Ref, newProof(witness1, vctLog1), newProof(witness2, vctLog1)),
+	}, nil)
+
+	resolutionResult := &document.ResolutionResult{
+		Document:         make(document.Document),
+		DocumentMetadata: make(document.Metadata),
+	}
+
+	t.Run("All operations satisfy criteria", func(t *testing.T) {
+		coreHandler := &mocks.Resolver{}
+		coreHandler.ResolveDocumentReturns(resolutionResult, nil)
+
+		handler := NewResolveHandler(testNS, coreHandler, nil, "", nil, nil, anchorGraph,
+			&orbmocks.MetricsProvider{}, WithOperationStore(opStore), WithDocumentLoader(docLoader))
+
+		result, err := handler.ResolveDocumentWithAssurance(testDID, &assurance.Criteria{
+			Witnesses: []string{"orb.domain1.com"},
+			Logs:      []string{vctLog1},
+		})
+		require.NoError(t, err)
+
+		md, ok := result.DocumentMetadata[assurance.MetadataProperty].(*assurance.Metadata)
+		require.True(t, ok)
+		require.Empty(t, md.SkippedOperations)
+
+		requireVersionID(t, coreHandler, update2Ref)
+	})
+
+	t.Run("Operations skipped", func(t *testing.T) {
+		coreHandler := &mocks.Resolver{}
+		coreHandler.ResolveDocumentReturns(&document.ResolutionResult{
+			Document: make(document.Document),
+			DocumentMetadata: document.Metadata{
+				document.MethodProperty: document.Metadata{
+					document.UnpublishedOperationsProperty: []*operation.AnchoredOperation{
+						{Type: operation.TypeUpdate, UniqueSuffix: "suffix"},
+					},
+				},
+			},
+		}, nil)
+
+		handler := NewResolveHandler(testNS, coreHandler, nil, "", nil, nil, anchorGraph,
+			&orbmocks.MetricsProvider{}, WithOperationStore(opStore), WithDocumentLoader(docLoader))
+
+		result, err := handler.ResolveDocumentWithAssurance(testDID, &assurance.Criteria{
+			Witnesses: []string{witness2},
+		})
+		require.NoError(t, err)
+
+		md, ok := result.DocumentMetadata[assurance.MetadataProperty].(*assurance.Metadata)
+		require.True(t, ok)
+		require.Len(t, md.SkippedOperations, 3)
+		require.Equal(t, updateRef, md.SkippedOperations[0].CanonicalReference)
+		require.Contains(t, md.SkippedOperations[0].Reason, "missing proof from witness")
+		require.Equal(t, update2Ref, md.SkippedOperations[1].CanonicalReference)
+		require.Contains(t, md.SkippedOperations[1].Reason, "follows skipped operation ["+updateRef+"]")
+		require.Equal(t, "operation has not been anchored", md.SkippedOperations[2].Reason)
+
+		requireVersionID(t, coreHandler, createRef)
+	})
+
+	t.Run("Create operation doesn't satisfy criteria", func(t *testing.T) {
+		handler := NewResolveHandler(testNS, &mocks.Resolver{}, nil, "", nil, nil, anchorGraph,
+			&orbmocks.MetricsProvider{}, WithOperationStore(opStore), WithDocumentLoader(docLoader))
+
+		result, err := handler.ResolveDocumentWithAssurance(testDID, &assurance.Criteria{
+			Logs: []string{"vct.domain3.com"},
+		})
+		require.True(t, errors.Is(err, ErrDocumentNotFound))
+		require.Nil(t, result)
+	})
+
+	t.Run("Anchor not found in anchor graph", func(t *testing.T) {
+		anchorGraph := &orbmocks.AnchorGraph{}
+		anchorGraph.GetDidAnchorsReturns([]graph.Anchor{
+			newTestAnchor(t, createRef, newProof(witness1, vctLog1)),
+		}, nil)
+
+		coreHandler := &mocks.Resolver{}
+		coreHandler.ResolveDocumentReturns(resolutionResult, nil)
+
+		handler := NewResolveHandler(testNS, coreHandler, nil, "", nil, nil, anchorGraph,
+			&orbmocks.MetricsProvider{}, WithOperationStore(opStore), WithDocumentLoader(docLoader))
+
+		result, err := handler.ResolveDocumentWithAssurance(testDID, &assurance.Criteria{
+			Witnesses: []string{witness1},
+		})
+		require.NoError(t, err)
+
+		md, ok := result.DocumentMetadata[assurance.MetadataProperty].(*assurance.Metadata)
+		require.True(t, ok)
+		require.Len(t, md.SkippedOperations, 2)
+		require.Equal(t, "anchor not found in the anchor graph", md.SkippedOperations[0].Reason)
+
+		requireVersionID(t, coreHandler, createRef)
+	})
+
+	t.Run("No criteria", func(t *testing.T) {
+		coreHandler := &mocks.Resolver{}
+		coreHandler.ResolveDocumentReturns(resolutionResult, nil)
+
+		handler := NewResolveHandler(testNS, coreHandler, nil, "", nil, nil, anchorGraph,
+			&orbmocks.MetricsProvider{})
+
+		result, err := handler.ResolveDocumentWithAssurance(testDID, nil)
+		require.NoError(t, err)
+		require.NotNil(t, result)
+	})
+
+	t.Run("Not supported", func(t *testing.T) {
+		handler := NewResolveHandler(testNS, &mocks.Resolver{}, nil, "", nil, nil, anchorGraph,
+			&orbmocks.MetricsProvider{})
+
+		_, err := handler.ResolveDocumentWithAssurance(testDID, &assurance.Criteria{Witnesses: []string{witness1}})
+		require.True(t, errors.Is(err, ErrAssuranceNotSupported))
+	})
+
+	t.Run("Combined with version ID", func(t *testing.T) {
+		handler := NewResolveHandler(testNS, &mocks.Resolver{}, nil, "", nil, nil, anchorGraph,
+			&orbmocks.MetricsProvider{}, WithOperationStore(opStore), WithDocumentLoader(docLoader))
+
+		_, err := handler.ResolveDocumentWithAssurance(testDID, &assurance.Criteria{Witnesses: []string{witness1}},
+			document.WithVersionID(createRef))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "bad request")
+	})
+
+	t.Run("Invalid DID", func(t *testing.T) {
+		handler := NewResolveHandler(testNS, &mocks.Resolver{}, nil, "", nil, nil, anchorGraph,
+			&orbmocks.MetricsProvider{}, WithOperationStore(opStore), WithDocumentLoader(docLoader))
+
+		_, err := handler.ResolveDocumentWithAssurance("did", &assurance.Criteria{Witnesses: []string{witness1}})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "bad request")
+	})
+
+	t.Run("Operation store error", func(t *testing.T) {
+		handler := NewResolveHandler(testNS, &mocks.Resolver{}, nil, "", nil, nil, anchorGraph,
+			&orbmocks.MetricsProvider{}, WithOperationStore(orbmocks.NewMockOperationStore()),
+			WithDocumentLoader(docLoader))
+
+		_, err := handler.ResolveDocumentWithAssurance(testDID, &assurance.Criteria{Witnesses: []string{witness1}})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "not found in the store")
+	})
+
+	t.Run("Anchor graph error", func(t *testing.T) {
+		anchorGraph := &orbmocks.AnchorGraph{}
+		anchorGraph.GetDidAnchorsReturns(nil, errors.New("injected anchor graph error"))
+
+		handler := NewResolveHandler(testNS, &mocks.Resolver{}, nil, "", nil, nil, anchorGraph,
+			&orbmocks.MetricsProvider{}, WithOperationStore(opStore), WithDocumentLoader(docLoader))
+
+		_, err := handler.ResolveDocumentWithAssurance(testDID, &assurance.Criteria{Witnesses: []string{witness1}})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected anchor graph error")
+	})
+
+	t.Run("Invalid anchor", func(t *testing.T) {
+		anchorGraph := &orbmocks.AnchorGraph{}
+
+		handler := NewResolveHandler(testNS, &mocks.Resolver{}, nil, "", nil, nil, anchorGraph,
+			&orbmocks.MetricsProvider{}, WithOperationStore(opStore), WithDocumentLoader(docLoader))
+
+		anchorGraph.GetDidAnchorsReturns([]graph.Anchor{{CID: "invalid", Info: &linkset.Link{}}}, nil)
+
+		_, err := handler.ResolveDocumentWithAssurance(testDID, &assurance.Criteria{Witnesses: []string{witness1}})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get resource hash from anchor")
+
+		anchorGraph.GetDidAnchorsReturns([]graph.Anchor{
+			{CID: hashlink.GetHashLinkFromResourceHash(createRef), Info: &linkset.Link{}},
+		}, nil)
+
+		_, err = handler.ResolveDocumentWithAssurance(testDID, &assurance.Criteria{Witnesses: []string{witness1}})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get verifiable credential from anchor")
+	})
+
+	t.Run("Resolve error", func(t *testing.T) {
+		coreHandler := &mocks.Resolver{}
+		coreHandler.ResolveDocumentReturns(nil, errors.New("injected resolve error"))
+
+		handler := NewResolveHandler(testNS, coreHandler, nil, "", nil, nil, anchorGraph,
+			&orbmocks.MetricsProvider{}, WithOperationStore(opStore), WithDocumentLoader(docLoader))
+
+		_, err := handler.ResolveDocumentWithAssurance(testDID, &assurance.Criteria{Witnesses: []string{witness1}})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected resolve error")
+	})
+}
+
+func requireVersionID(t *testing.T, coreHandler *mocks.Resolver, versionID string) {
+	t.Helper()
+
+	require.Equal(t, 1, coreHandler.ResolveDocumentCallCount())
+
+	_, opts := coreHandler.ResolveDocumentArgsForCall(0)
+
+	resOpts, err := document.GetResolutionOptions(opts...)
+	require.NoError(t, err)
+	require.Equal(t, versionID, resOpts.VersionID)
+}
+
+func newProof(verificationMethod, domain string) verifiable.Proof {
+	return verifiable.Proof{
+		"type":               "Ed25519Signature2018",
+		"proofPurpose":       "assertionMethod",
+		"verificationMethod": verificationMethod + "/keys/main-key",
+		"domain":             domain,
+		"created":            "2022-01-20T18:19:15.139Z",
+		"jws":                "eyJhbGciOiJFZERTQSIsImI2NCI6ZmFsc2UsImNyaXQiOlsiYjY0Il19..signature",
+	}
+}
+
+func newTestAnchor(t *testing.T, ref string, proofs ...verifiable.Proof) graph.Anchor {
+	t.Helper()
+
+	payload := &subject.Payload{
+		OperationCount:  1,
+		CoreIndex:       hashlink.GetHashLinkFromResourceHash(ref),
+		Namespace:       testNS,
+		PreviousAnchors: []*subject.SuffixAnchor{{Suffix: "suffix"}},
+	}
+
+	al, _, err := anchorlinkset.NewBuilder(generator.NewRegistry()).BuildAnchorLink(payload,
+		datauri.MediaTypeDataURIGzipBase64,
+		func(anchorHashlink, coreIndexHashlink string) (*verifiable.Credential, error) {
+			return &verifiable.Credential{
+				Types:   []string{"VerifiableCredential", "AnchorCredential"},
+				Context: []string{vocab.ContextCredentials, vocab.ContextActivityAnchors},
+				Subject: &builder.CredentialSubject{
+					HRef:    anchorHashlink,
+					Type:    []string{"AnchorLink"},
+					Profile: "https://w3id.org/orb#v0",
+					Anchor:  coreIndexHashlink,
+					Rel:     "linkset",
+				},
+				Issuer: verifiable.Issuer{ID: witness1},
+				Issued: &util.TimeWrapper{Time: time.Now()},
+				Proofs: proofs,
+			}, nil
+		},
+	)
+	require.NoError(t, err)
+
+	return graph.Anchor{Info: al, CID: hashlink.GetHashLinkFromResourceHash(ref)}
+}
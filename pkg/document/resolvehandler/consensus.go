@@ -0,0 +1,325 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resolvehandler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/canonicalizer"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/document/consensus"
+	"github.com/trustbloc/orb/pkg/document/util"
+)
+
+// consensusResolver is a remote Orb domain which participates in a consensus resolution.
+type consensusResolver struct {
+	domain    string
+	endpoints []string
+}
+
+// consensusResponse is the response of a resolver (local or remote) in a consensus resolution.
+type consensusResponse struct {
+	resolver string
+	result   *document.ResolutionResult
+	err      error
+}
+
+// ResolveDocumentWithConsensus resolves the document locally as well as from the Orb domains which are
+// configured as consensus domains (along with the domains that they advertise as alternates) and from the
+// anchor origin of the DID. Each domain is counted once. The responses are compared by canonical document
+// and update/recovery commitments, and the result that most domains agree on is returned, provided that
+// at least the configured minimum number of domains (including this one) agree. The domains which agreed
+// and those that disagreed (along with the reason) are returned in the "consensus" document metadata.
+// If consensus isn't reached then a consensus.Error is returned.
+func (r *ResolveHandler) ResolveDocumentWithConsensus(id string,
+	opts ...document.ResolutionOption) (*document.ResolutionResult, error) {
+	resOpts, err := document.GetResolutionOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if resOpts.VersionID != "" || resOpts.VersionTime != "" {
+		return nil, fmt.Errorf("bad request: consensus resolution may not be combined with versionId or versionTime")
+	}
+
+	localResult, err := r.ResolveDocument(id, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers, responses := r.getConsensusResolvers(id, localResult)
+
+	responses = append([]*consensusResponse{{resolver: r.domain, result: localResult}},
+		append(r.resolveFromConsensusResolvers(id, resolvers), responses...)...)
+
+	agreed, md := r.evaluateConsensus(responses)
+
+	if len(md.Agreed) < md.MinimumResolvers {
+		logger.Warn("Consensus not reached for DID", log.WithDID(id), log.WithTotal(len(md.Agreed)))
+
+		return nil, fmt.Errorf("resolve document [%s]: %w", id, consensus.NewError(md))
+	}
+
+	if agreed != localResult {
+		logger.Warn("The local resolution result doesn't match the consensus result", log.WithDID(id))
+	}
+
+	docMetadata := make(document.Metadata)
+
+	for k, v := range agreed.DocumentMetadata {
+		docMetadata[k] = v
+	}
+
+	docMetadata[consensus.MetadataProperty] = md
+
+	return &document.ResolutionResult{
+		Context:          agreed.Context,
+		Document:         agreed.Document,
+		DocumentMetadata: docMetadata,
+	}, nil
+}
+
+// getConsensusResolvers returns the remote domains (and their resolution endpoints) which participate in
+// the consensus resolution. The responses of the domains whose endpoints couldn't be retrieved are also returned.
+func (r *ResolveHandler) getConsensusResolvers(id string,
+	localResult *document.ResolutionResult) ([]*consensusResolver, []*consensusResponse) {
+	domains := append([]string{}, r.consensusDomains...)
+
+	anchorOrigin, err := util.GetAnchorOrigin(localResult.DocumentMetadata)
+	if err != nil {
+		logger.Debug("Unable to get anchor origin from local response", log.WithDID(id), log.WithError(err))
+	} else if normalizeDomain(anchorOrigin) != normalizeDomain(r.domain) {
+		domains = append(domains, anchorOrigin)
+	}
+
+	localHost := hostFromURL(r.domain)
+
+	var resolvers []*consensusResolver
+
+	var responses []*consensusResponse
+
+	resolversByDomain := make(map[string]*consensusResolver)
+	processed := make(map[string]struct{})
+
+	for _, d := range domains {
+		// The same domain may be specified with or without a scheme (e.g. "orb.domain1.com" and
+		// "https://orb.domain1.com") so the domains are deduplicated by their normalized form.
+		domain := normalizeDomain(d)
+
+		if _, ok := processed[domain]; ok {
+			continue
+		}
+
+		processed[domain] = struct{}{}
+
+		endpoint, err := r.endpointClient.GetEndpoint(domain)
+		if err != nil {
+			logger.Warn("Unable to get endpoint of consensus domain", log.WithDID(id), log.WithDomain(domain),
+				log.WithError(err))
+
+			responses = append(responses, &consensusResponse{resolver: domain, err: err})
+
+			continue
+		}
+
+		for _, resolutionEndpoint := range endpoint.ResolutionEndpoints {
+			u, err := url.Parse(resolutionEndpoint)
+			if err != nil || u.Host == "" {
+				logger.Debug("Ignoring invalid resolution endpoint", log.WithURIString(resolutionEndpoint))
+
+				continue
+			}
+
+			if u.Host == localHost {
+				continue
+			}
+
+			resolverDomain := fmt.Sprintf("%s://%s", u.Scheme, u.Host)
+
+			resolver, ok := resolversByDomain[resolverDomain]
+			if !ok {
+				resolver = &consensusResolver{domain: resolverDomain}
+				resolversByDomain[resolverDomain] = resolver
+				resolvers = append(resolvers, resolver)
+			}
+
+			if !contains(resolver.endpoints, resolutionEndpoint) {
+				resolver.endpoints = append(resolver.endpoints, resolutionEndpoint)
+			}
+		}
+	}
+
+	return resolvers, responses
+}
+
+// resolveFromConsensusResolvers resolves the document from each of the given resolvers concurrently.
+func (r *ResolveHandler) resolveFromConsensusResolvers(id string, resolvers []*consensusResolver) []*consensusResponse {
+	responses := make([]*consensusResponse, len(resolvers))
+
+	var wg sync.WaitGroup
+
+	for i, resolver := range resolvers {
+		wg.Add(1)
+
+		go func(i int, resolver *consensusResolver) {
+			defer wg.Done()
+
+			result, err := r.remoteResolver.ResolveDocumentFromResolutionEndpoints(id, resolver.endpoints)
+			if err != nil {
+				logger.Debug("Error resolving document from consensus domain", log.WithDID(id),
+					log.WithDomain(resolver.domain), log.WithError(err))
+			}
+
+			responses[i] = &consensusResponse{resolver: resolver.domain, result: result, err: err}
+		}(i, resolver)
+	}
+
+	wg.Wait()
+
+	return responses
+}
+
+// evaluateConsensus groups the given responses by matching result and returns the result of the largest
+// group (the first group wins a tie, so the local result is preferred) along with the consensus metadata.
+func (r *ResolveHandler) evaluateConsensus(
+	responses []*consensusResponse) (*document.ResolutionResult, *consensus.Metadata) {
+	var groups [][]*consensusResponse
+
+	for _, response := range responses {
+		if response.err != nil {
+			continue
+		}
+
+		matched := false
+
+		for i, group := range groups {
+			if compareResults(group[0].result, response.result) == nil {
+				groups[i] = append(group, response)
+				matched = true
+
+				break
+			}
+		}
+
+		if !matched {
+			groups = append(groups, []*consensusResponse{response})
+		}
+	}
+
+	// The local response is always successful so there's at least one group.
+	agreed := groups[0]
+
+	for _, group := range groups[1:] {
+		if len(group) > len(agreed) {
+			agreed = group
+		}
+	}
+
+	md := &consensus.Metadata{MinimumResolvers: r.consensusMinimumResolvers}
+
+	for _, response := range agreed {
+		md.Agreed = append(md.Agreed, response.resolver)
+	}
+
+	for _, response := range responses {
+		if contains(md.Agreed, response.resolver) {
+			continue
+		}
+
+		reason := response.err
+		if reason == nil {
+			reason = compareResults(agreed[0].result, response.result)
+		}
+
+		md.Disagreed = append(md.Disagreed, &consensus.Disagreement{
+			Resolver: response.resolver,
+			Reason:   reason.Error(),
+		})
+	}
+
+	return agreed[0].result, md
+}
+
+// compareResults returns an error if the canonical documents or the update/recovery commitments
+// of the given resolution results don't match.
+func compareResults(expected, actual *document.ResolutionResult) error {
+	expectedBytes, err := canonicalizer.MarshalCanonical(expected.Document)
+	if err != nil {
+		return fmt.Errorf("marshal canonical document: %w", err)
+	}
+
+	actualBytes, err := canonicalizer.MarshalCanonical(actual.Document)
+	if err != nil {
+		return fmt.Errorf("marshal canonical document: %w", err)
+	}
+
+	if !bytes.Equal(expectedBytes, actualBytes) {
+		return errors.New("documents don't match")
+	}
+
+	expectedMethodMetadata, err := util.GetMethodMetadata(expected.DocumentMetadata)
+	if err != nil {
+		return fmt.Errorf("invalid document metadata: %w", err)
+	}
+
+	actualMethodMetadata, err := util.GetMethodMetadata(actual.DocumentMetadata)
+	if err != nil {
+		return fmt.Errorf("invalid document metadata: %w", err)
+	}
+
+	for _, property := range []string{document.UpdateCommitmentProperty, document.RecoveryCommitmentProperty} {
+		expectedValue := fmt.Sprint(expectedMethodMetadata[property])
+		actualValue := fmt.Sprint(actualMethodMetadata[property])
+
+		if expectedValue != actualValue {
+			return fmt.Errorf("%s [%s] doesn't match [%s]", property, actualValue, expectedValue)
+		}
+	}
+
+	return nil
+}
+
+// normalizeDomain returns the given domain in the form scheme://host. As in the discovery endpoint client,
+// a domain without a scheme is assumed to be an HTTPS domain.
+func normalizeDomain(domain string) string {
+	if !strings.HasPrefix(domain, "http://") && !strings.HasPrefix(domain, "https://") {
+		domain = "https://" + domain
+	}
+
+	u, err := url.Parse(domain)
+	if err != nil || u.Host == "" {
+		return domain
+	}
+
+	return fmt.Sprintf("%s://%s", u.Scheme, u.Host)
+}
+
+func hostFromURL(value string) string {
+	u, err := url.Parse(value)
+	if err != nil {
+		return ""
+	}
+
+	return u.Host
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
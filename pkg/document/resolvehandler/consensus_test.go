@@ -0,0 +1,279 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resolvehandler
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
+
+	"github.com/trustbloc/orb/pkg/discovery/endpoint/client/models"
+	"github.com/trustbloc/orb/pkg/document/consensus"
+	"github.com/trustbloc/orb/pkg/document/mocks"
+	orbmocks "github.com/trustbloc/orb/pkg/mocks"
+)
+
+const (
+	consensusDomain1 = "https://orb.domain1.com"
+	consensusDomain2 = "https://orb.domain2.com"
+	consensusDomain3 = "https://orb.domain3.com"
+
+	resolutionPath = "/sidetree/v1/identifiers"
+)
+
+func TestResolveHandler_ResolveDocumentWithConsensus(t *testing.T) {
+	anchorGraph := &orbmocks.AnchorGraph{}
+
+	localResult := newConsensusResult("key1", updateCommitment, recoveryCommitment, anchorOriginDomain)
+
+	endpointClient := &mocks.EndpointClient{}
+	endpointClient.GetEndpointStub = func(domain string) (*models.Endpoint, error) {
+		switch domain {
+		case consensusDomain1:
+			// domain1 advertises domain2 (and this domain) as alternates.
+			return &models.Endpoint{ResolutionEndpoints: []string{
+				consensusDomain1 + resolutionPath, consensusDomain2 + resolutionPath, domain + resolutionPath,
+			}}, nil
+		case consensusDomain2:
+			return &models.Endpoint{ResolutionEndpoints: []string{consensusDomain2 + resolutionPath, "://"}}, nil
+		case anchorOriginDomain:
+			return &models.Endpoint{ResolutionEndpoints: []string{anchorOriginDomain + resolutionPath}}, nil
+		default:
+			return nil, fmt.Errorf("domain [%s] not found", domain)
+		}
+	}
+
+	newRemoteResolver := func(results map[string]*document.ResolutionResult) *mocks.RemoteResolver {
+		remoteResolver := &mocks.RemoteResolver{}
+		remoteResolver.ResolveDocumentFromResolutionEndpointsStub = func(id string,
+			endpoints []string) (*document.ResolutionResult, error) {
+			result, ok := results[strings.TrimSuffix(endpoints[0], resolutionPath)]
+			if !ok {
+				return nil, errors.New("injected remote resolver error")
+			}
+
+			return result, nil
+		}
+
+		return remoteResolver
+	}
+
+	t.Run("consensus reached", func(t *testing.T) {
+		coreHandler := &mocks.Resolver{}
+		coreHandler.ResolveDocumentReturns(localResult, nil)
+
+		remoteResolver := newRemoteResolver(map[string]*document.ResolutionResult{
+			consensusDomain1:   newConsensusResult("key1", updateCommitment, recoveryCommitment, anchorOriginDomain),
+			consensusDomain2:   newConsensusResult("key2", updateCommitment, recoveryCommitment, anchorOriginDomain),
+			anchorOriginDomain: newConsensusResult("key1", updateCommitment, recoveryCommitment, anchorOriginDomain),
+		})
+
+		handler := NewResolveHandler(testNS, coreHandler, nil, domain, endpointClient, remoteResolver,
+			anchorGraph, &orbmocks.MetricsProvider{},
+			WithConsensusDomains([]string{consensusDomain1, consensusDomain2, consensusDomain1, consensusDomain3}),
+			WithConsensusMinimumResolvers(3),
+		)
+
+		result, err := handler.ResolveDocumentWithConsensus(testDID)
+		require.NoError(t, err)
+		require.Equal(t, localResult.Document, result.Document)
+
+		md, ok := result.DocumentMetadata[consensus.MetadataProperty].(*consensus.Metadata)
+		require.True(t, ok)
+		require.Equal(t, 3, md.MinimumResolvers)
+		require.Equal(t, []string{domain, consensusDomain1, anchorOriginDomain}, md.Agreed)
+		require.Len(t, md.Disagreed, 2)
+		require.Equal(t, consensusDomain2, md.Disagreed[0].Resolver)
+		require.Equal(t, "documents don't match", md.Disagreed[0].Reason)
+		require.Equal(t, consensusDomain3, md.Disagreed[1].Resolver)
+		require.Contains(t, md.Disagreed[1].Reason, "not found")
+
+		// The local result shouldn't be modified.
+		require.NotContains(t, localResult.DocumentMetadata, consensus.MetadataProperty)
+
+		// Each domain is queried only once.
+		require.Equal(t, 3, remoteResolver.ResolveDocumentFromResolutionEndpointsCallCount())
+	})
+
+	t.Run("same domain with and without scheme", func(t *testing.T) {
+		coreHandler := &mocks.Resolver{}
+		coreHandler.ResolveDocumentReturns(localResult, nil)
+
+		remoteResolver := newRemoteResolver(map[string]*document.ResolutionResult{
+			consensusDomain1:   newConsensusResult("key1", updateCommitment, recoveryCommitment, anchorOriginDomain),
+			consensusDomain2:   newConsensusResult("key1", updateCommitment, recoveryCommitment, anchorOriginDomain),
+			anchorOriginDomain: newConsensusResult("key1", updateCommitment, recoveryCommitment, anchorOriginDomain),
+		})
+
+		endpointClient2 := &mocks.EndpointClient{}
+		endpointClient2.GetEndpointStub = endpointClient.GetEndpointStub
+
+		handler := NewResolveHandler(testNS, coreHandler, nil, domain, endpointClient2, remoteResolver,
+			anchorGraph, &orbmocks.MetricsProvider{},
+			WithConsensusDomains([]string{
+				"orb.domain1.com", consensusDomain1, "orb.domain3.com", consensusDomain3 + "/",
+				strings.TrimPrefix(anchorOriginDomain, "https://"),
+			}),
+		)
+
+		result, err := handler.ResolveDocumentWithConsensus(testDID)
+		require.NoError(t, err)
+
+		md, ok := result.DocumentMetadata[consensus.MetadataProperty].(*consensus.Metadata)
+		require.True(t, ok)
+		require.Equal(t, []string{domain, consensusDomain1, consensusDomain2, anchorOriginDomain}, md.Agreed)
+		require.Len(t, md.Disagreed, 1)
+		require.Equal(t, consensusDomain3, md.Disagreed[0].Resolver)
+		require.Contains(t, md.Disagreed[0].Reason, "not found")
+
+		// Each domain is looked up and queried only once.
+		require.Equal(t, 3, endpointClient2.GetEndpointCallCount())
+		require.Equal(t, 3, remoteResolver.ResolveDocumentFromResolutionEndpointsCallCount())
+	})
+
+	t.Run("consensus reached on remote result", func(t *testing.T) {
+		coreHandler := &mocks.Resolver{}
+		coreHandler.ResolveDocumentReturns(localResult, nil)
+
+		remoteResult := newConsensusResult("key1", "new-update-commitment", recoveryCommitment, anchorOriginDomain)
+
+		remoteResolver := newRemoteResolver(map[string]*document.ResolutionResult{
+			consensusDomain1:   remoteResult,
+			consensusDomain2:   remoteResult,
+			anchorOriginDomain: remoteResult,
+		})
+
+		handler := NewResolveHandler(testNS, coreHandler, nil, domain, endpointClient, remoteResolver,
+			anchorGraph, &orbmocks.MetricsProvider{},
+			WithConsensusDomains([]string{consensusDomain1}),
+		)
+
+		result, err := handler.ResolveDocumentWithConsensus(testDID)
+		require.NoError(t, err)
+
+		md, ok := result.DocumentMetadata[consensus.MetadataProperty].(*consensus.Metadata)
+		require.True(t, ok)
+		require.Equal(t, 1, md.MinimumResolvers)
+		require.Equal(t, []string{consensusDomain1, consensusDomain2, anchorOriginDomain}, md.Agreed)
+		require.Len(t, md.Disagreed, 1)
+		require.Equal(t, domain, md.Disagreed[0].Resolver)
+		require.Equal(t, "updateCommitment [update-commitment] doesn't match [new-update-commitment]",
+			md.Disagreed[0].Reason)
+	})
+
+	t.Run("consensus not reached", func(t *testing.T) {
+		coreHandler := &mocks.Resolver{}
+		coreHandler.ResolveDocumentReturns(localResult, nil)
+
+		remoteResolver := newRemoteResolver(map[string]*document.ResolutionResult{
+			consensusDomain1: newConsensusResult("key1", updateCommitment, "new-recovery-commitment",
+				anchorOriginDomain),
+			consensusDomain2: {Document: localResult.Document},
+		})
+
+		handler := NewResolveHandler(testNS, coreHandler, nil, domain, endpointClient, remoteResolver,
+			anchorGraph, &orbmocks.MetricsProvider{},
+			WithConsensusDomains([]string{consensusDomain1}),
+			WithConsensusMinimumResolvers(2),
+		)
+
+		result, err := handler.ResolveDocumentWithConsensus(testDID)
+		require.Error(t, err)
+		require.Nil(t, result)
+		require.Contains(t, err.Error(), "consensus not reached")
+
+		var cErr *consensus.Error
+		require.True(t, errors.As(err, &cErr))
+		require.Equal(t, []string{domain}, cErr.Metadata.Agreed)
+		require.Len(t, cErr.Metadata.Disagreed, 3)
+		require.Equal(t, "recoveryCommitment [new-recovery-commitment] doesn't match [recovery-commitment]",
+			cErr.Metadata.Disagreed[0].Reason)
+		require.Contains(t, cErr.Metadata.Disagreed[1].Reason, "invalid document metadata")
+		require.Equal(t, anchorOriginDomain, cErr.Metadata.Disagreed[2].Resolver)
+		require.Equal(t, "injected remote resolver error", cErr.Metadata.Disagreed[2].Reason)
+	})
+
+	t.Run("no anchor origin", func(t *testing.T) {
+		coreHandler := &mocks.Resolver{}
+		coreHandler.ResolveDocumentReturns(&document.ResolutionResult{Document: document.Document{}}, nil)
+
+		remoteResolver := &mocks.RemoteResolver{}
+
+		handler := NewResolveHandler(testNS, coreHandler, nil, domain, endpointClient, remoteResolver,
+			anchorGraph, &orbmocks.MetricsProvider{})
+
+		result, err := handler.ResolveDocumentWithConsensus(testDID)
+		require.NoError(t, err)
+
+		md, ok := result.DocumentMetadata[consensus.MetadataProperty].(*consensus.Metadata)
+		require.True(t, ok)
+		require.Equal(t, []string{domain}, md.Agreed)
+		require.Empty(t, md.Disagreed)
+		require.Zero(t, remoteResolver.ResolveDocumentFromResolutionEndpointsCallCount())
+	})
+
+	t.Run("version ID -> error", func(t *testing.T) {
+		handler := NewResolveHandler(testNS, &mocks.Resolver{}, nil, domain, endpointClient,
+			&mocks.RemoteResolver{}, anchorGraph, &orbmocks.MetricsProvider{})
+
+		_, err := handler.ResolveDocumentWithConsensus(testDID, document.WithVersionID("1234"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "bad request")
+	})
+
+	t.Run("local resolution error", func(t *testing.T) {
+		coreHandler := &mocks.Resolver{}
+		coreHandler.ResolveDocumentReturns(nil, errors.New("not found"))
+
+		handler := NewResolveHandler(testNS, coreHandler, &mocks.Discovery{}, domain, endpointClient,
+			&mocks.RemoteResolver{}, anchorGraph, &orbmocks.MetricsProvider{})
+
+		_, err := handler.ResolveDocumentWithConsensus(testDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "not found")
+	})
+}
+
+func TestCompareResults(t *testing.T) {
+	result := newConsensusResult("key1", updateCommitment, recoveryCommitment, anchorOriginDomain)
+
+	require.NoError(t, compareResults(result, newConsensusResult("key1", updateCommitment, recoveryCommitment,
+		consensusDomain1)))
+
+	err := compareResults(result, &document.ResolutionResult{Document: document.Document{"id": make(chan int)}})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "marshal canonical document")
+
+	err = compareResults(&document.ResolutionResult{Document: document.Document{"id": make(chan int)}}, result)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "marshal canonical document")
+
+	err = compareResults(&document.ResolutionResult{Document: result.Document}, result)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid document metadata")
+}
+
+func newConsensusResult(keyID, updateCommitment, recoveryCommitment,
+	anchorOrigin string) *document.ResolutionResult {
+	return &document.ResolutionResult{
+		Document: document.Document{
+			"id":                 testDID,
+			"verificationMethod": []interface{}{map[string]interface{}{"id": "#" + keyID}},
+		},
+		DocumentMetadata: document.Metadata{
+			document.MethodProperty: map[string]interface{}{
+				document.UpdateCommitmentProperty:   updateCommitment,
+				document.RecoveryCommitmentProperty: recoveryCommitment,
+				document.AnchorOriginProperty:       anchorOrigin,
+			},
+		},
+	}
+}
@@ -8,10 +8,12 @@ package resolvehandler
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/piprate/json-gold/ld"
 	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
 	"github.com/trustbloc/sidetree-core-go/pkg/canonicalizer"
 	"github.com/trustbloc/sidetree-core-go/pkg/document"
@@ -21,6 +23,7 @@ import (
 	"github.com/trustbloc/orb/pkg/context/common"
 	"github.com/trustbloc/orb/pkg/discovery/endpoint/client/models"
 	"github.com/trustbloc/orb/pkg/document/util"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
 	"github.com/trustbloc/orb/pkg/hashlink"
 )
 
@@ -48,7 +51,13 @@ type ResolveHandler struct {
 
 	enableResolutionFromAnchorOrigin bool
 
+	consensusDomains          []string
+	consensusMinimumResolvers int
+
 	hl *hashlink.HashLink
+
+	opStore        operationStore
+	documentLoader ld.DocumentLoader
 }
 
 // Resolver resolves documents.
@@ -71,6 +80,10 @@ type remoteResolver interface {
 	ResolveDocumentFromResolutionEndpoints(id string, endpoints []string) (*document.ResolutionResult, error)
 }
 
+type operationStore interface {
+	Get(suffix string) ([]*operation.AnchoredOperation, error)
+}
+
 type metricsProvider interface {
 	DocumentResolveTime(duration time.Duration)
 	ResolveDocumentLocallyTime(duration time.Duration)
@@ -106,6 +119,38 @@ func WithUnpublishedDIDLabel(label string) Option {
 	}
 }
 
+// WithOperationStore sets the store of published operations. The operation store (along with the
+// document loader) is required in order to resolve documents with assurance criteria.
+func WithOperationStore(store operationStore) Option {
+	return func(opts *ResolveHandler) {
+		opts.opStore = store
+	}
+}
+
+// WithDocumentLoader sets the JSON-LD document loader which is used to parse anchor credentials.
+func WithDocumentLoader(loader ld.DocumentLoader) Option {
+	return func(opts *ResolveHandler) {
+		opts.documentLoader = loader
+	}
+}
+
+// WithConsensusDomains sets the Orb domains which are queried when a document is resolved in consensus mode.
+func WithConsensusDomains(domains []string) Option {
+	return func(opts *ResolveHandler) {
+		opts.consensusDomains = domains
+	}
+}
+
+// WithConsensusMinimumResolvers sets the minimum number of domains (including this one) which must return
+// matching results when a document is resolved in consensus mode.
+func WithConsensusMinimumResolvers(value int) Option {
+	return func(opts *ResolveHandler) {
+		if value > 0 {
+			opts.consensusMinimumResolvers = value
+		}
+	}
+}
+
 // NewResolveHandler returns a new document resolve handler.
 func NewResolveHandler(namespace string, resolver coreResolver, discovery discoveryService,
 	domain string, endpointClient endpointClient, remoteResolver remoteResolver,
@@ -120,6 +165,8 @@ func NewResolveHandler(namespace string, resolver coreResolver, discovery discov
 		anchorGraph:      anchorGraph,
 		metrics:          metrics,
 		hl:               hashlink.New(),
+
+		consensusMinimumResolvers: 1,
 	}
 
 	// apply options
@@ -432,7 +479,9 @@ func (r *ResolveHandler) resolveDocumentLocally(id string, opts ...document.Reso
 
 	response, err := r.coreResolver.ResolveDocument(id, opts...)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") &&
+		err = classifyCoreError(err)
+
+		if errors.Is(err, ErrDocumentNotFound) &&
 			!strings.Contains(id, r.unpublishedDIDLabel) &&
 			r.enableDidDiscovery {
 			r.requestDiscovery(id)
@@ -562,3 +611,36 @@ func (r *ResolveHandler) getCIDAndSuffix(id string) (string, string, error) {
 
 	return cid, suffix, nil
 }
+
+// classifyCoreError converts an error returned by the Sidetree core resolver into a typed error. The Sidetree
+// core resolver doesn't return typed errors, so the error message is inspected (once, at this boundary) so that
+// callers may use errors.Is(err, ErrDocumentNotFound) and orberrors.IsBadRequest(err). The error message is preserved.
+func classifyCoreError(err error) error {
+	msg := err.Error()
+
+	switch {
+	case strings.HasPrefix(msg, "bad request"):
+		return orberrors.NewBadRequest(err)
+	case strings.Contains(msg, "not found"):
+		return &notFoundError{err: err}
+	default:
+		return err
+	}
+}
+
+// notFoundError is a Sidetree "not found" error which matches ErrDocumentNotFound.
+type notFoundError struct {
+	err error
+}
+
+func (e *notFoundError) Error() string {
+	return e.err.Error()
+}
+
+func (e *notFoundError) Unwrap() error {
+	return e.err
+}
+
+func (e *notFoundError) Is(target error) bool {
+	return target == ErrDocumentNotFound
+}
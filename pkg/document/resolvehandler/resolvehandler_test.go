@@ -24,6 +24,7 @@ import (
 	"github.com/trustbloc/orb/pkg/anchor/graph"
 	"github.com/trustbloc/orb/pkg/discovery/endpoint/client/models"
 	"github.com/trustbloc/orb/pkg/document/mocks"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
 	"github.com/trustbloc/orb/pkg/linkset"
 	orbmocks "github.com/trustbloc/orb/pkg/mocks"
 )
@@ -701,6 +702,8 @@ func TestResolveHandler_Resolve(t *testing.T) {
 		require.Error(t, err)
 		require.Nil(t, response)
 		require.Contains(t, err.Error(), "local resolver error")
+		require.False(t, errors.Is(err, ErrDocumentNotFound))
+		require.False(t, orberrors.IsBadRequest(err))
 	})
 
 	t.Run("success - without document create store(interim did)", func(t *testing.T) {
@@ -783,6 +786,22 @@ func TestResolveHandler_Resolve(t *testing.T) {
 		response, err := handler.ResolveDocument(testInterimDID)
 		require.Error(t, err)
 		require.Nil(t, response)
+		require.ErrorIs(t, err, ErrDocumentNotFound)
+		require.Contains(t, err.Error(), "resolve document ["+testInterimDID+"]: not found")
+	})
+
+	t.Run("error - bad request error", func(t *testing.T) {
+		coreHandler := &mocks.Resolver{}
+		coreHandler.ResolveDocumentReturns(nil, errors.New("bad request: invalid suffix"))
+
+		handler := NewResolveHandler(testNS, coreHandler, &mocks.Discovery{}, "", nil, nil, anchorGraph,
+			&orbmocks.MetricsProvider{}, WithUnpublishedDIDLabel(testLabel))
+
+		response, err := handler.ResolveDocument(testInterimDID)
+		require.Error(t, err)
+		require.Nil(t, response)
+		require.True(t, orberrors.IsBadRequest(err))
+		require.False(t, errors.Is(err, ErrDocumentNotFound))
 	})
 
 	t.Run("error - did not found error in operation store", func(t *testing.T) {
@@ -9,6 +9,7 @@ package util
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
@@ -184,3 +185,14 @@ func getOperationsByKey(methodMetadata map[string]interface{}, key string) ([]*o
 
 	return ops, nil
 }
+
+// SortOperations sorts the operations in anchoring order (as done by the Sidetree operation processor).
+func SortOperations(ops []*operation.AnchoredOperation) {
+	sort.SliceStable(ops, func(i, j int) bool {
+		if ops[i].TransactionTime != ops[j].TransactionTime {
+			return ops[i].TransactionTime < ops[j].TransactionTime
+		}
+
+		return ops[i].TransactionNumber < ops[j].TransactionNumber
+	})
+}
@@ -197,3 +197,19 @@ func TestParseKeyURI(t *testing.T) {
 		require.EqualError(t, err, "invalid public key ID - expecting DID and key ID")
 	})
 }
+
+func TestSortOperations(t *testing.T) {
+	ops := []*operation.AnchoredOperation{
+		{CanonicalReference: "ref3", TransactionTime: 2000, TransactionNumber: 1},
+		{CanonicalReference: "ref2", TransactionTime: 1000, TransactionNumber: 2},
+		{CanonicalReference: "ref4", TransactionTime: 2000, TransactionNumber: 1},
+		{CanonicalReference: "ref1", TransactionTime: 1000, TransactionNumber: 1},
+	}
+
+	SortOperations(ops)
+
+	require.Equal(t, "ref1", ops[0].CanonicalReference)
+	require.Equal(t, "ref2", ops[1].CanonicalReference)
+	require.Equal(t, "ref3", ops[2].CanonicalReference)
+	require.Equal(t, "ref4", ops[3].CanonicalReference)
+}
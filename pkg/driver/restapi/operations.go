@@ -6,23 +6,23 @@ SPDX-License-Identifier: Apache-2.0
 package restapi
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 
-	"github.com/gorilla/mux"
 	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
+	"github.com/hyperledger/aries-framework-go/pkg/vdr/httpbinding"
+	"github.com/trustbloc/sidetree-core-go/pkg/document"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
 
-	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/document/didresolution"
+	"github.com/trustbloc/orb/pkg/observability/metrics/noop"
 )
 
 const (
 	resolveDIDEndpoint = "/1.0/identifiers/{id}"
-	didLDJson          = "application/did+ld+json"
 )
 
-var logger = log.New("driver")
-
 // Handler http handler for each controller API endpoint.
 type Handler interface {
 	Path() string
@@ -32,92 +32,66 @@ type Handler interface {
 
 // Operation defines handlers.
 type Operation struct {
-	orbVDR vdr.VDR
+	orbVDR     vdr.VDR
+	strictMode bool
 }
 
 // Config defines configuration for driver operations.
 type Config struct {
 	OrbVDR vdr.VDR
+
+	// StrictMode enables strict conformance with the W3C DID Resolution HTTP(S) binding.
+	// See didresolution.WithStrictMode.
+	StrictMode bool
 }
 
 // New returns driver operation instance.
 func New(config *Config) *Operation {
-	return &Operation{orbVDR: config.OrbVDR}
+	return &Operation{orbVDR: config.OrbVDR, strictMode: config.StrictMode}
 }
 
-func (o *Operation) resolveDIDHandler(rw http.ResponseWriter, req *http.Request) {
-	didID := mux.Vars(req)["id"]
-
-	if didID == "" {
-		o.writeErrorResponse(rw, http.StatusBadRequest, "url param 'did' is missing")
+// ResolveDocument resolves the DID document using the Orb VDR.
+func (o *Operation) ResolveDocument(id string, opts ...document.ResolutionOption) (*document.ResolutionResult, error) {
+	resolutionOpts := &document.ResolutionOptions{}
 
-		return
+	for _, opt := range opts {
+		opt(resolutionOpts)
 	}
 
-	DocResolution, err := o.orbVDR.Read(didID)
-	if err != nil {
-		o.writeErrorResponse(rw, http.StatusBadRequest,
-			fmt.Sprintf("failed to resolve did: %s", err.Error()))
+	var vdrOpts []vdr.DIDMethodOption
 
-		return
+	if resolutionOpts.VersionID != "" {
+		vdrOpts = append(vdrOpts, vdr.WithOption(httpbinding.VersionIDOpt, resolutionOpts.VersionID))
 	}
 
-	bytes, err := DocResolution.JSONBytes()
-	if err != nil {
-		o.writeErrorResponse(rw, http.StatusInternalServerError,
-			fmt.Sprintf("failed to marshal doc resolution: %s", err.Error()))
-
-		return
+	if resolutionOpts.VersionTime != "" {
+		vdrOpts = append(vdrOpts, vdr.WithOption(httpbinding.VersionTimeOpt, resolutionOpts.VersionTime))
 	}
 
-	rw.Header().Set("Content-type", didLDJson)
-	rw.WriteHeader(http.StatusOK)
+	docResolution, err := o.orbVDR.Read(id, vdrOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve did: %w", err)
+	}
 
-	if _, err := rw.Write(bytes); err != nil {
-		log.WriteResponseBodyError(logger, err)
+	docResolutionBytes, err := docResolution.JSONBytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal doc resolution: %w", err)
 	}
-}
 
-// writeErrorResponse writes interface value to response.
-func (o *Operation) writeErrorResponse(rw http.ResponseWriter, status int, msg string) {
-	rw.WriteHeader(status)
+	result := &document.ResolutionResult{}
 
-	if _, err := rw.Write([]byte(msg)); err != nil {
-		log.WriteResponseBodyError(logger, err)
+	if err := json.Unmarshal(docResolutionBytes, result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal doc resolution: %w", err)
 	}
+
+	return result, nil
 }
 
 // GetRESTHandlers get all controller API handler available for this service.
 func (o *Operation) GetRESTHandlers() []common.HTTPHandler {
 	return []common.HTTPHandler{
-		newHTTPHandler(resolveDIDEndpoint, http.MethodGet, o.resolveDIDHandler),
+		didresolution.NewResolveHandler(resolveDIDEndpoint, o, &noop.NoOptMetrics{},
+			didresolution.WithStrictMode(o.strictMode),
+		),
 	}
 }
-
-// newHTTPHandler returns instance of HTTPHandler which can be used to handle http requests.
-func newHTTPHandler(path, method string, handle common.HTTPRequestHandler) common.HTTPHandler {
-	return &httpHandler{path: path, method: method, handle: handle}
-}
-
-// HTTPHandler contains REST API handling details which can be used to build routers.
-// for http requests for given path.
-type httpHandler struct {
-	path   string
-	method string
-	handle common.HTTPRequestHandler
-}
-
-// Path returns http request path.
-func (h *httpHandler) Path() string {
-	return h.path
-}
-
-// Method returns http request method type.
-func (h *httpHandler) Method() string {
-	return h.method
-}
-
-// Handler returns http request handle func.
-func (h *httpHandler) Handler() common.HTTPRequestHandler {
-	return h.handle
-}
@@ -16,6 +16,7 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
 	vdrapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
 	mockvdr "github.com/hyperledger/aries-framework-go/pkg/mock/vdr"
+	"github.com/hyperledger/aries-framework-go/pkg/vdr/httpbinding"
 	"github.com/stretchr/testify/require"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
 
@@ -24,6 +25,7 @@ import (
 
 const (
 	resolveDIDEndpoint = "/1.0/identifiers/{id}"
+	testDID            = "did:orb:uAAA:EiDJpL-xeSE4kVgoGjaQm_OX5y5Dy3Nfr1Ld7cdMZwqb0A"
 )
 
 func TestDIDResolve(t *testing.T) {
@@ -35,7 +37,7 @@ func TestDIDResolve(t *testing.T) {
 		rr := serveHTTP(t, handler.Handler(), http.MethodGet, resolveDIDEndpoint, nil, nil)
 
 		require.Equal(t, http.StatusBadRequest, rr.Code)
-		require.Contains(t, rr.Body.String(), "url param 'did' is missing")
+		require.Contains(t, rr.Body.String(), "invalidDid")
 	})
 
 	t.Run("test error from read did", func(t *testing.T) {
@@ -48,30 +50,89 @@ func TestDIDResolve(t *testing.T) {
 		handler := getHandler(t, c, resolveDIDEndpoint)
 
 		urlVars := make(map[string]string)
-		urlVars["id"] = "did1"
+		urlVars["id"] = testDID
 
 		rr := serveHTTP(t, handler.Handler(), http.MethodGet, resolveDIDEndpoint, nil, urlVars)
 
-		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
 		require.Contains(t, rr.Body.String(), "failed to read did")
 	})
 
-	t.Run("test success", func(t *testing.T) {
+	t.Run("test did not found", func(t *testing.T) {
 		c := restapi.New(&restapi.Config{OrbVDR: &mockvdr.MockVDR{
 			ReadFunc: func(didID string, opts ...vdrapi.DIDMethodOption) (*did.DocResolution, error) {
-				return &did.DocResolution{DIDDocument: &did.Doc{ID: "did1"}}, nil
+				return nil, fmt.Errorf("failed to resolve did: %w", vdrapi.ErrNotFound)
 			},
 		}})
 
 		handler := getHandler(t, c, resolveDIDEndpoint)
 
 		urlVars := make(map[string]string)
-		urlVars["id"] = "did1"
+		urlVars["id"] = testDID
 
 		rr := serveHTTP(t, handler.Handler(), http.MethodGet, resolveDIDEndpoint, nil, urlVars)
 
+		require.Equal(t, http.StatusNotFound, rr.Code)
+		require.Contains(t, rr.Body.String(), "notFound")
+	})
+
+	t.Run("test success", func(t *testing.T) {
+		var versionID interface{}
+
+		c := restapi.New(&restapi.Config{OrbVDR: &mockvdr.MockVDR{
+			ReadFunc: func(didID string, opts ...vdrapi.DIDMethodOption) (*did.DocResolution, error) {
+				didMethodOpts := &vdrapi.DIDMethodOpts{Values: make(map[string]interface{})}
+
+				for _, opt := range opts {
+					opt(didMethodOpts)
+				}
+
+				versionID = didMethodOpts.Values[httpbinding.VersionIDOpt]
+
+				return &did.DocResolution{DIDDocument: &did.Doc{ID: didID}}, nil
+			},
+		}})
+
+		handler := getHandler(t, c, resolveDIDEndpoint)
+
+		urlVars := make(map[string]string)
+		urlVars["id"] = testDID
+
+		rr := serveHTTP(t, handler.Handler(), http.MethodGet, resolveDIDEndpoint+"?versionId=v1", nil, urlVars)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Contains(t, rr.Body.String(), testDID)
+		require.Contains(t, rr.Body.String(), "didResolutionMetadata")
+		require.Equal(t, "v1", versionID)
+	})
+
+	t.Run("test strict mode", func(t *testing.T) {
+		c := restapi.New(&restapi.Config{
+			OrbVDR: &mockvdr.MockVDR{
+				ReadFunc: func(didID string, opts ...vdrapi.DIDMethodOption) (*did.DocResolution, error) {
+					return &did.DocResolution{DIDDocument: &did.Doc{ID: didID}}, nil
+				},
+			},
+			StrictMode: true,
+		})
+
+		handler := getHandler(t, c, resolveDIDEndpoint)
+
+		urlVars := make(map[string]string)
+		urlVars["id"] = testDID
+
+		httpReq, err := http.NewRequest(http.MethodGet, resolveDIDEndpoint, nil)
+		require.NoError(t, err)
+
+		httpReq.Header.Set("Accept", "application/did+ld+json")
+
+		rr := httptest.NewRecorder()
+
+		handler.Handler()(rr, mux.SetURLVars(httpReq, urlVars))
+
 		require.Equal(t, http.StatusOK, rr.Code)
-		require.Contains(t, rr.Body.String(), "did1")
+		require.Contains(t, rr.Body.String(), testDID)
+		require.NotContains(t, rr.Body.String(), "didResolutionMetadata")
 	})
 }
 
@@ -18,8 +18,28 @@ import (
 	"github.com/trustbloc/sidetree-core-go/pkg/hashing"
 )
 
+// Multihash codes of the supported hash algorithms.
+const (
+	// SHA2_256 is the multihash code for SHA2-256 (the default).
+	SHA2_256 uint = multihash.SHA2_256
+	// SHA3_256 is the multihash code for SHA3-256.
+	SHA3_256 uint = multihash.SHA3_256
+	// BLAKE2b_256 is the multihash code for BLAKE2b-256.
+	BLAKE2b_256 uint = multihash.BLAKE2B_MIN + 31
+)
+
+// VerificationMode specifies which multihash algorithms are accepted when a hashlink is parsed.
+type VerificationMode int
+
+const (
+	// VerifySupported accepts resource hashes created with any of the supported algorithms so that hashlinks
+	// created with a different (e.g. older) algorithm may still be resolved. This is the default.
+	VerifySupported VerificationMode = iota
+	// VerifyStrict accepts only resource hashes created with the multihash algorithm of the HashLink.
+	VerifyStrict
+)
+
 const (
-	sha2_256 = 18
 	linksKey = 0x0f
 
 	hl        = "hl"
@@ -42,7 +62,7 @@ type Decoder func(encodedContent string) ([]byte, error)
 func New(opts ...Option) *HashLink {
 	// default encoder/decoder is base64 URL encoder/decoder
 	hl := &HashLink{
-		multihashCode: sha2_256,
+		multihashCode: SHA2_256,
 		encoder: func(data []byte) string {
 			return "u" + base64.RawURLEncoding.EncodeToString(data)
 		},
@@ -71,9 +91,10 @@ type Option func(opts *HashLink)
 
 // HashLink implements hashlink related functionality.
 type HashLink struct {
-	encoder       Encoder
-	decoder       Decoder
-	multihashCode uint
+	encoder          Encoder
+	decoder          Decoder
+	multihashCode    uint
+	verificationMode VerificationMode
 }
 
 // CreateHashLink will create hashlink for the supplied content and links.
@@ -140,9 +161,50 @@ type Info struct {
 
 // CreateResourceHash will create resource hash for the supplied content.
 func (hl *HashLink) CreateResourceHash(content []byte) (string, error) {
-	mh, err := hashing.ComputeMultihash(hl.multihashCode, content)
+	return hl.createResourceHash(hl.multihashCode, content)
+}
+
+// VerifyResourceHash verifies that the given resource hash was computed from the given content. The hash is
+// computed using the algorithm of the given resource hash, which may be different from the algorithm
+// of this HashLink (for example, when verifying content that was anchored with an older algorithm).
+func (hl *HashLink) VerifyResourceHash(content []byte, resourceHash string) error {
+	code, err := hl.GetMultihashCode(resourceHash)
+	if err != nil {
+		return fmt.Errorf("get multihash code of resource hash[%s]: %w", resourceHash, err)
+	}
+
+	hash, err := hl.createResourceHash(code, content)
 	if err != nil {
-		return "", fmt.Errorf("failed to compute multihash for code[%d]: %w", hl.multihashCode, err)
+		return err
+	}
+
+	if hash != resourceHash {
+		return fmt.Errorf("resource hash of the content (%s) does not match the resource hash (%s)",
+			hash, resourceHash)
+	}
+
+	return nil
+}
+
+// GetMultihashCode returns the multihash code (i.e. the hash algorithm) of the given resource hash.
+func (hl *HashLink) GetMultihashCode(resourceHash string) (uint, error) {
+	multihashBytes, err := hl.decoder(resourceHash)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode encoded multihash: %w", err)
+	}
+
+	mh, err := multihash.Decode(multihashBytes)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode multihash: %w", err)
+	}
+
+	return uint(mh.Code), nil
+}
+
+func (hl *HashLink) createResourceHash(code uint, content []byte) (string, error) {
+	mh, err := computeMultihash(code, content)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute multihash for code[%d]: %w", code, err)
 	}
 
 	return hl.encoder(mh), nil
@@ -214,6 +276,23 @@ func WithMultihashCode(mhCode uint) Option {
 	}
 }
 
+// WithVerificationMode option specifies which multihash algorithms are accepted when a hashlink is parsed.
+func WithVerificationMode(mode VerificationMode) Option {
+	return func(opts *HashLink) {
+		opts.verificationMode = mode
+	}
+}
+
+// IsSupportedMultihashCode returns true if hashes may be computed using the given multihash code.
+func IsSupportedMultihashCode(code uint) bool {
+	switch code {
+	case SHA2_256, multihash.SHA2_512, SHA3_256, BLAKE2b_256:
+		return true
+	default:
+		return false
+	}
+}
+
 // GetHashLink will create hashlink from resource hash and metadata.
 func GetHashLink(resource, metadata string) string {
 	return fmt.Sprintf("%s:%s:%s", hl, resource, metadata)
@@ -274,23 +353,37 @@ func stringEntry(entry interface{}) (string, error) {
 }
 
 func (hl *HashLink) isValidMultihash(encodedMultihash string) error {
-	multihashBytes, err := hl.decoder(encodedMultihash)
+	code, err := hl.GetMultihashCode(encodedMultihash)
 	if err != nil {
-		return fmt.Errorf("failed to decode encoded multihash: %w", err)
+		return err
 	}
 
-	mh, err := multihash.Decode(multihashBytes)
-	if err != nil {
-		return fmt.Errorf("failed to decode multihash: %w", err)
+	if hl.verificationMode == VerifyStrict {
+		if code != hl.multihashCode {
+			return fmt.Errorf("resource multihash code[%d] is not supported code[%d]", code, hl.multihashCode)
+		}
+
+		return nil
 	}
 
-	if mh.Code != uint64(hl.multihashCode) {
-		return fmt.Errorf("resource multihash code[%d] is not supported code[%d]", mh.Code, hl.multihashCode)
+	if code != hl.multihashCode && !IsSupportedMultihashCode(code) {
+		return fmt.Errorf("resource multihash code[%d] is not supported", code)
 	}
 
 	return nil
 }
 
+// computeMultihash computes the multihash of the given content. SHA2 hashes are computed in the same way as
+// Sidetree operation hashes; the other algorithms are computed directly by the multihash library.
+func computeMultihash(code uint, content []byte) ([]byte, error) {
+	switch code {
+	case SHA3_256, BLAKE2b_256:
+		return multihash.Sum(content, uint64(code), -1)
+	default:
+		return hashing.ComputeMultihash(code, content)
+	}
+}
+
 // ToString parses the given hashlink(s) and returns a human-readable form.
 func ToString(hl ...*url.URL) string {
 	str := ""
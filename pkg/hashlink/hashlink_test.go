@@ -7,12 +7,16 @@ SPDX-License-Identifier: Apache-2.0
 package hashlink
 
 import (
+	"encoding/base64"
 	"fmt"
 	"testing"
 
 	"github.com/btcsuite/btcutil/base58"
 	"github.com/fxamacker/cbor/v2"
+	"github.com/multiformats/go-multihash"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/sha3"
 
 	"github.com/trustbloc/orb/pkg/internal/testutil"
 )
@@ -192,6 +196,18 @@ func TestHashLink_CreateResourceHash(t *testing.T) {
 		require.Equal(t, "uEiB_g7Flf_H8U7ktwYFIodZd_C1LH6PWdyhK3dIAEm2QaQ", rh)
 	})
 
+	t.Run("success - SHA3-256", func(t *testing.T) {
+		rh, err := New(WithMultihashCode(SHA3_256)).CreateResourceHash([]byte(exampleContent))
+		require.NoError(t, err)
+		require.Equal(t, base64Encoder(multihashOf(t, SHA3_256)), rh)
+	})
+
+	t.Run("success - BLAKE2b-256", func(t *testing.T) {
+		rh, err := New(WithMultihashCode(BLAKE2b_256)).CreateResourceHash([]byte(exampleContent))
+		require.NoError(t, err)
+		require.Equal(t, base64Encoder(multihashOf(t, BLAKE2b_256)), rh)
+	})
+
 	t.Run("error - multihash code not supported", func(t *testing.T) {
 		hl := New(WithMultihashCode(invalidMultihashCode))
 
@@ -203,6 +219,51 @@ func TestHashLink_CreateResourceHash(t *testing.T) {
 	})
 }
 
+func TestHashLink_VerifyResourceHash(t *testing.T) {
+	content := []byte(exampleContent)
+
+	for _, code := range []uint{SHA2_256, SHA3_256, BLAKE2b_256} {
+		rh, err := New(WithMultihashCode(code)).CreateResourceHash(content)
+		require.NoError(t, err)
+
+		// The hash is verified using the algorithm of the resource hash, regardless of the configured algorithm.
+		hl := New()
+
+		mhCode, err := hl.GetMultihashCode(rh)
+		require.NoError(t, err)
+		require.Equal(t, code, mhCode)
+
+		require.NoError(t, hl.VerifyResourceHash(content, rh))
+
+		err = hl.VerifyResourceHash([]byte("other content"), rh)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "does not match the resource hash")
+	}
+
+	t.Run("invalid resource hash", func(t *testing.T) {
+		err := New().VerifyResourceHash(content, "abc")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to decode encoded multihash")
+
+		_, err = New().GetMultihashCode("uabc")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to decode multihash")
+	})
+
+	t.Run("unsupported algorithm", func(t *testing.T) {
+		err := New().VerifyResourceHash(content, base64Encoder(multihashOf(t, 0x11)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to compute multihash for code[17]")
+	})
+}
+
+func TestIsSupportedMultihashCode(t *testing.T) {
+	require.True(t, IsSupportedMultihashCode(SHA2_256))
+	require.True(t, IsSupportedMultihashCode(SHA3_256))
+	require.True(t, IsSupportedMultihashCode(BLAKE2b_256))
+	require.False(t, IsSupportedMultihashCode(invalidMultihashCode))
+}
+
 func TestHashLink_CreateMetadataFromLinks(t *testing.T) {
 	t.Run("success - with links", func(t *testing.T) {
 		links := []string{
@@ -299,8 +360,17 @@ func TestHashLink_ParseHashLink(t *testing.T) {
 			"resource hash[abc] for hashlink[hl:abc] is not a valid multihash: failed to decode encoded multihash")
 	})
 
-	t.Run("error - multi hash not supported", func(t *testing.T) {
-		hl := New(WithMultihashCode(0))
+	t.Run("success - other supported algorithm", func(t *testing.T) {
+		hash, err := New(WithMultihashCode(SHA3_256)).CreateHashLink([]byte(exampleContent), nil)
+		require.NoError(t, err)
+
+		hlInfo, err := New().ParseHashLink(hash)
+		require.NoError(t, err)
+		require.Equal(t, hash[len(HLPrefix):], hlInfo.ResourceHash)
+	})
+
+	t.Run("error - multi hash not supported (strict)", func(t *testing.T) {
+		hl := New(WithMultihashCode(0), WithVerificationMode(VerifyStrict))
 
 		hlInfo, err := hl.ParseHashLink("hl:uEiB_g7Flf_H8U7ktwYFIodZd_C1LH6PWdyhK3dIAEm2QaQ")
 		require.Error(t, err)
@@ -309,6 +379,13 @@ func TestHashLink_ParseHashLink(t *testing.T) {
 			"resource multihash code[18] is not supported code[0]")
 	})
 
+	t.Run("error - multi hash not supported", func(t *testing.T) {
+		hlInfo, err := New().ParseHashLink("hl:" + base64Encoder(multihashOf(t, 0x11)))
+		require.Error(t, err)
+		require.Nil(t, hlInfo)
+		require.Contains(t, err.Error(), "resource multihash code[17] is not supported")
+	})
+
 	t.Run("error - parse metadata error", func(t *testing.T) {
 		hl := New()
 
@@ -365,3 +442,30 @@ var base58Encoder = func(data []byte) string {
 var base58Decoder = func(enc string) ([]byte, error) {
 	return base58.Decode(enc[1:]), nil
 }
+
+var base64Encoder = func(data []byte) string {
+	return "u" + base64.RawURLEncoding.EncodeToString(data)
+}
+
+// multihashOf returns the multihash of the example content computed independently of the HashLink implementation.
+func multihashOf(t *testing.T, code uint) []byte {
+	t.Helper()
+
+	var digest []byte
+
+	switch code {
+	case SHA3_256:
+		d := sha3.Sum256([]byte(exampleContent))
+		digest = d[:]
+	case BLAKE2b_256:
+		d := blake2b.Sum256([]byte(exampleContent))
+		digest = d[:]
+	default:
+		digest = make([]byte, 20)
+	}
+
+	mh, err := multihash.Encode(digest, uint64(code))
+	require.NoError(t, err)
+
+	return mh
+}
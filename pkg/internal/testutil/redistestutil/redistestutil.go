@@ -0,0 +1,311 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package redistestutil
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server is a minimal, in-process stand-in for a Redis server. It supports the subset of commands
+// used by Orb (PING, AUTH, GET, SET [PX|EX], DEL, PUBLISH and SUBSCRIBE) and is intended for unit tests only.
+type Server struct {
+	listener net.Listener
+	password string
+
+	mutex       sync.Mutex
+	values      map[string]*entry
+	subscribers map[string][]*subscriber
+	conns       map[net.Conn]struct{}
+	wg          sync.WaitGroup
+}
+
+type entry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+type subscriber struct {
+	mutex sync.Mutex
+	conn  net.Conn
+}
+
+// StartServer starts a Redis stand-in server on a random local port. If password is not empty then
+// clients must authenticate with the given password. The address of the server is returned, as well as
+// a function that should be invoked to stop the server when it is no longer required.
+func StartServer(password string) (server *Server, address string, stop func()) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(fmt.Sprintf("Failed to start Redis stand-in server: %s", err.Error()))
+	}
+
+	s := &Server{
+		listener:    l,
+		password:    password,
+		values:      make(map[string]*entry),
+		subscribers: make(map[string][]*subscriber),
+		conns:       make(map[net.Conn]struct{}),
+	}
+
+	s.wg.Add(1)
+
+	go s.accept()
+
+	return s, l.Addr().String(), s.stop
+}
+
+// Get returns the value for the given key, or nil if the key doesn't exist.
+func (s *Server) Get(key string) []byte {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	e, ok := s.get(key)
+	if !ok {
+		return nil
+	}
+
+	return e.value
+}
+
+// Set sets the value for the given key.
+func (s *Server) Set(key string, value []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.values[key] = &entry{value: value}
+}
+
+// DisconnectAll closes all client connections (including subscriber connections).
+func (s *Server) DisconnectAll() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for c := range s.conns {
+		_ = c.Close() //nolint:errcheck
+	}
+}
+
+func (s *Server) stop() {
+	_ = s.listener.Close() //nolint:errcheck
+
+	s.DisconnectAll()
+
+	s.wg.Wait()
+}
+
+func (s *Server) accept() {
+	defer s.wg.Done()
+
+	for {
+		c, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mutex.Lock()
+		s.conns[c] = struct{}{}
+		s.mutex.Unlock()
+
+		s.wg.Add(1)
+
+		go s.serve(c)
+	}
+}
+
+func (s *Server) serve(c net.Conn) {
+	defer s.wg.Done()
+
+	defer func() {
+		s.mutex.Lock()
+		delete(s.conns, c)
+		s.mutex.Unlock()
+
+		_ = c.Close() //nolint:errcheck
+	}()
+
+	r := bufio.NewReader(c)
+	sub := &subscriber{conn: c}
+	authenticated := s.password == ""
+
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+
+		cmd := strings.ToUpper(args[0])
+
+		if cmd == "AUTH" {
+			authenticated = len(args) == 2 && args[1] == s.password
+		}
+
+		var reply string
+
+		switch {
+		case cmd == "AUTH" && !authenticated:
+			reply = "-WRONGPASS invalid password\r\n"
+		case !authenticated:
+			reply = "-NOAUTH Authentication required.\r\n"
+		default:
+			reply = s.handle(cmd, args[1:], sub)
+		}
+
+		sub.mutex.Lock()
+		_, err = io.WriteString(c, reply)
+		sub.mutex.Unlock()
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) handle(cmd string, args []string, sub *subscriber) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	switch cmd {
+	case "PING", "AUTH":
+		return "+OK\r\n"
+	case "GET":
+		e, ok := s.get(args[0])
+		if !ok {
+			return "$-1\r\n"
+		}
+
+		return bulkString(e.value)
+	case "SET":
+		return s.set(args)
+	case "DEL":
+		_, ok := s.values[args[0]]
+		delete(s.values, args[0])
+
+		if ok {
+			return ":1\r\n"
+		}
+
+		return ":0\r\n"
+	case "PUBLISH":
+		return s.publish(args[0], args[1])
+	case "SUBSCRIBE":
+		s.subscribers[args[0]] = append(s.subscribers[args[0]], sub)
+
+		return "*3\r\n" + bulkString([]byte("subscribe")) + bulkString([]byte(args[0])) + ":1\r\n"
+	default:
+		return fmt.Sprintf("-ERR unknown command '%s'\r\n", cmd)
+	}
+}
+
+func (s *Server) get(key string) (*entry, bool) {
+	e, ok := s.values[key]
+	if !ok {
+		return nil, false
+	}
+
+	if !e.expiresAt.IsZero() && time.Now().After(e.expiresAt) {
+		delete(s.values, key)
+
+		return nil, false
+	}
+
+	return e, true
+}
+
+func (s *Server) set(args []string) string {
+	e := &entry{value: []byte(args[1])}
+
+	if len(args) == 4 { //nolint:gomnd
+		n, err := strconv.ParseInt(args[3], 10, 64)
+		if err != nil {
+			return "-ERR value is not an integer or out of range\r\n"
+		}
+
+		switch strings.ToUpper(args[2]) {
+		case "PX":
+			e.expiresAt = time.Now().Add(time.Duration(n) * time.Millisecond)
+		case "EX":
+			e.expiresAt = time.Now().Add(time.Duration(n) * time.Second)
+		default:
+			return "-ERR syntax error\r\n"
+		}
+	}
+
+	s.values[args[0]] = e
+
+	return "+OK\r\n"
+}
+
+func (s *Server) publish(channel, message string) string {
+	msg := "*3\r\n" + bulkString([]byte("message")) + bulkString([]byte(channel)) + bulkString([]byte(message))
+
+	var n int
+
+	for _, sub := range s.subscribers[channel] {
+		sub.mutex.Lock()
+		_, err := io.WriteString(sub.conn, msg)
+		sub.mutex.Unlock()
+
+		if err == nil {
+			n++
+		}
+	}
+
+	return fmt.Sprintf(":%d\r\n", n)
+}
+
+func bulkString(value []byte) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	line = strings.TrimRight(line, "\r\n")
+
+	if !strings.HasPrefix(line, "*") {
+		return nil, errors.New("expecting array")
+	}
+
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n < 1 {
+		return nil, errors.New("invalid array length")
+	}
+
+	args := make([]string, n)
+
+	for i := range args {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		size, err := strconv.Atoi(strings.TrimRight(line, "\r\n")[1:])
+		if err != nil {
+			return nil, errors.New("invalid bulk string length")
+		}
+
+		data := make([]byte, size+2) //nolint:gomnd
+
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+
+		args[i] = string(data[:size])
+	}
+
+	return args, nil
+}
@@ -10,9 +10,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/ld"
 	ldstore "github.com/hyperledger/aries-framework-go/pkg/store/ld"
@@ -132,3 +136,42 @@ func GetExpiryService(t *testing.T) *expiry.Service {
 
 	return expiry.NewService(taskMgr, time.Second)
 }
+
+// RecordedMessage is a message which was recorded from a previous release. Recorded messages are replayed by
+// compatibility tests in order to ensure that the messages published by servers running a previous release
+// may still be processed by the current release (e.g. during a rolling upgrade).
+type RecordedMessage struct {
+	Description string            `json:"description"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Payload     json.RawMessage   `json:"payload"`
+}
+
+// LoadRecordedMessages loads the recorded messages (*.json) from the given directory. The returned map
+// is keyed by file name.
+func LoadRecordedMessages(t *testing.T, dir string) map[string]*message.Message {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	require.NotEmptyf(t, files, "no recorded messages found in %s", dir)
+
+	msgs := make(map[string]*message.Message)
+
+	for _, file := range files {
+		b, err := os.ReadFile(filepath.Clean(file))
+		require.NoError(t, err)
+
+		recorded := &RecordedMessage{}
+		require.NoErrorf(t, json.Unmarshal(b, recorded), "invalid recorded message %s", file)
+
+		msg := message.NewMessage(watermill.NewUUID(), []byte(recorded.Payload))
+
+		for k, v := range recorded.Metadata {
+			msg.Metadata.Set(k, v)
+		}
+
+		msgs[filepath.Base(file)] = msg
+	}
+
+	return msgs
+}
@@ -462,7 +462,14 @@ func validateOriginal(original []*Reference, anchorHL *url.URL) error {
 		return fmt.Errorf("invalid 'original' content: %w", err)
 	}
 
-	hashOfOriginal, err := hashlink.New().CreateResourceHash(content)
+	// The hash is computed using the algorithm of the anchor hash, which may be different from the default.
+	var opts []hashlink.Option
+
+	if code, e := hashlink.New().GetMultihashCode(anchorHL.Opaque); e == nil && hashlink.IsSupportedMultihashCode(code) {
+		opts = append(opts, hashlink.WithMultihashCode(code))
+	}
+
+	hashOfOriginal, err := hashlink.New(opts...).CreateResourceHash(content)
 	if err != nil {
 		return fmt.Errorf("create hashlink from 'original' content: %w", err)
 	}
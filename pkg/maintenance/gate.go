@@ -0,0 +1,112 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const gatePollInterval = 50 * time.Millisecond
+
+// Gate is a Pausable which guards work that writes to the database. Work is wrapped in Enter/done. While the
+// gate is paused, Enter blocks until the gate is resumed and Pause waits for all work that entered the gate
+// to complete.
+type Gate struct {
+	mutex    sync.Mutex
+	paused   bool
+	inFlight int
+	resumed  chan struct{}
+}
+
+// NewGate returns a new (resumed) gate.
+func NewGate() *Gate {
+	return &Gate{}
+}
+
+// Enter blocks while the gate is paused and then registers work as in flight. The returned function must be
+// invoked when the work is done.
+func (g *Gate) Enter() func() {
+	for {
+		g.mutex.Lock()
+
+		if !g.paused {
+			g.inFlight++
+			g.mutex.Unlock()
+
+			var once sync.Once
+
+			return func() {
+				once.Do(g.done)
+			}
+		}
+
+		resumed := g.resumed
+
+		g.mutex.Unlock()
+
+		<-resumed
+	}
+}
+
+// Pause pauses the gate and waits for all work in flight to complete. If the given context is done before
+// the work completes then the gate is resumed and an error is returned.
+func (g *Gate) Pause(ctx context.Context) error {
+	g.mutex.Lock()
+
+	if !g.paused {
+		g.paused = true
+		g.resumed = make(chan struct{})
+	}
+
+	g.mutex.Unlock()
+
+	ticker := time.NewTicker(gatePollInterval)
+	defer ticker.Stop()
+
+	for g.numInFlight() > 0 {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			g.Resume()
+
+			return fmt.Errorf("wait for in-flight work: %w", ctx.Err())
+		}
+	}
+
+	return nil
+}
+
+// Resume resumes the gate and releases all work that is blocked in Enter.
+func (g *Gate) Resume() {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if !g.paused {
+		return
+	}
+
+	g.paused = false
+
+	close(g.resumed)
+}
+
+func (g *Gate) numInFlight() int {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	return g.inFlight
+}
+
+func (g *Gate) done() {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.inFlight--
+}
@@ -0,0 +1,84 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package maintenance
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGate(t *testing.T) {
+	t.Run("Pause waits for work in flight", func(t *testing.T) {
+		g := NewGate()
+
+		done := g.Enter()
+
+		var paused int32
+
+		go func() {
+			require.NoError(t, g.Pause(context.Background()))
+
+			atomic.StoreInt32(&paused, 1)
+		}()
+
+		time.Sleep(100 * time.Millisecond)
+		require.Equal(t, int32(0), atomic.LoadInt32(&paused))
+
+		done()
+		done() // Should be OK to invoke twice.
+
+		require.Eventually(t, func() bool { return atomic.LoadInt32(&paused) == 1 }, time.Second, 10*time.Millisecond)
+		require.Equal(t, 0, g.numInFlight())
+
+		g.Resume()
+		g.Resume() // Should be OK to resume twice.
+	})
+
+	t.Run("Enter blocks while paused", func(t *testing.T) {
+		g := NewGate()
+
+		require.NoError(t, g.Pause(context.Background()))
+		require.NoError(t, g.Pause(context.Background())) // Should be OK to pause twice.
+
+		var entered int32
+
+		go func() {
+			done := g.Enter()
+			defer done()
+
+			atomic.StoreInt32(&entered, 1)
+		}()
+
+		time.Sleep(100 * time.Millisecond)
+		require.Equal(t, int32(0), atomic.LoadInt32(&entered))
+
+		g.Resume()
+
+		require.Eventually(t, func() bool { return atomic.LoadInt32(&entered) == 1 }, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Pause timeout", func(t *testing.T) {
+		g := NewGate()
+
+		done := g.Enter()
+		defer done()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		err := g.Pause(ctx)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "wait for in-flight work")
+
+		// The gate was resumed so work may enter.
+		g.Enter()()
+	})
+}
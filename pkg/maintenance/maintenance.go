@@ -0,0 +1,161 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+)
+
+var logger = log.New("maintenance")
+
+const (
+	defaultTimeout = 30 * time.Minute
+	maxTimeout     = 24 * time.Hour
+)
+
+// Pausable is a component which stops writing to the database while it is paused.
+type Pausable interface {
+	Pause(ctx context.Context) error
+	Resume()
+}
+
+// Status contains the quiesce status of the server.
+type Status struct {
+	InstanceID string     `json:"instanceID,omitempty"`
+	Quiesced   bool       `json:"quiesced"`
+	QuiescedAt *time.Time `json:"quiescedAt,omitempty"`
+	ResumeBy   *time.Time `json:"resumeBy,omitempty"`
+}
+
+// Service quiesces the components of the server which write to the database (so that a consistent backup
+// may be taken) and resumes them afterward. A quiesced server automatically resumes after a timeout so that
+// the server doesn't remain quiesced if the client which requested the quiesce goes away.
+type Service struct {
+	instanceID string
+	components []Pausable
+
+	mutex      sync.Mutex
+	quiescedAt *time.Time
+	resumeBy   *time.Time
+	timer      *time.Timer
+}
+
+// New returns a new maintenance service for the given server instance and components. Components are paused
+// in the given order and resumed in the reverse order.
+func New(instanceID string, components ...Pausable) *Service {
+	return &Service{instanceID: instanceID, components: components}
+}
+
+// Quiesce pauses all components. The components are automatically resumed after the given timeout (or after a
+// default timeout if zero). If the server is already quiesced then the timeout is extended.
+func (s *Service) Quiesce(ctx context.Context, timeout time.Duration) (*Status, error) {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	if timeout > maxTimeout {
+		return nil, fmt.Errorf("timeout %s exceeds the maximum of %s", timeout, maxTimeout)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.quiescedAt == nil {
+		for i, c := range s.components {
+			if err := c.Pause(ctx); err != nil {
+				resumeAll(s.components[:i])
+
+				return nil, fmt.Errorf("pause component: %w", err)
+			}
+		}
+
+		now := time.Now()
+
+		s.quiescedAt = &now
+
+		logger.Info("Server quiesced.", log.WithTimeout(timeout))
+	} else {
+		s.timer.Stop()
+
+		logger.Info("Server is already quiesced. Extending the quiesce timeout.", log.WithTimeout(timeout))
+	}
+
+	resumeBy := time.Now().Add(timeout)
+
+	s.resumeBy = &resumeBy
+	s.timer = time.AfterFunc(timeout, s.autoResume)
+
+	return s.status(), nil
+}
+
+// Resume resumes all components. Nothing is done if the server isn't quiesced.
+func (s *Service) Resume() *Status {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.resume()
+
+	return s.status()
+}
+
+// Status returns the quiesce status.
+func (s *Service) Status() *Status {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.status()
+}
+
+func (s *Service) autoResume() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.quiescedAt == nil || time.Now().Before(*s.resumeBy) {
+		// Already resumed or the timeout was extended.
+		return
+	}
+
+	logger.Warn("Quiesce timeout reached. Resuming server.")
+
+	s.resume()
+}
+
+func (s *Service) resume() {
+	if s.quiescedAt == nil {
+		return
+	}
+
+	s.timer.Stop()
+
+	resumeAll(s.components)
+
+	s.quiescedAt = nil
+	s.resumeBy = nil
+	s.timer = nil
+
+	logger.Info("Server resumed.")
+}
+
+func (s *Service) status() *Status {
+	return &Status{
+		InstanceID: s.instanceID,
+		Quiesced:   s.quiescedAt != nil,
+		QuiescedAt: s.quiescedAt,
+		ResumeBy:   s.resumeBy,
+	}
+}
+
+func resumeAll(components []Pausable) {
+	for i := len(components) - 1; i >= 0; i-- {
+		components[i].Resume()
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestService(t *testing.T) {
+	t.Run("Quiesce and resume", func(t *testing.T) {
+		c1 := &mockComponent{}
+		c2 := &mockComponent{}
+
+		s := New("instance1", c1, c2)
+
+		require.False(t, s.Status().Quiesced)
+
+		status, err := s.Quiesce(context.Background(), 0)
+		require.NoError(t, err)
+		require.Equal(t, "instance1", status.InstanceID)
+		require.True(t, status.Quiesced)
+		require.NotNil(t, status.QuiescedAt)
+		require.NotNil(t, status.ResumeBy)
+		require.True(t, status.ResumeBy.After(time.Now().Add(defaultTimeout-time.Minute)))
+		require.True(t, c1.isPaused())
+		require.True(t, c2.isPaused())
+
+		// Quiescing again extends the timeout.
+		status2, err := s.Quiesce(context.Background(), time.Hour)
+		require.NoError(t, err)
+		require.Equal(t, status.QuiescedAt, status2.QuiescedAt)
+		require.True(t, status2.ResumeBy.After(*status.ResumeBy))
+		require.Equal(t, 1, c1.numPaused())
+
+		status = s.Resume()
+		require.False(t, status.Quiesced)
+		require.Nil(t, status.QuiescedAt)
+		require.False(t, c1.isPaused())
+		require.False(t, c2.isPaused())
+
+		// Resuming again does nothing.
+		require.False(t, s.Resume().Quiesced)
+	})
+
+	t.Run("Auto resume", func(t *testing.T) {
+		c := &mockComponent{}
+
+		s := New("instance1", c)
+
+		_, err := s.Quiesce(context.Background(), 50*time.Millisecond)
+		require.NoError(t, err)
+		require.True(t, c.isPaused())
+
+		time.Sleep(200 * time.Millisecond)
+
+		require.False(t, c.isPaused())
+		require.False(t, s.Status().Quiesced)
+	})
+
+	t.Run("Timeout too large", func(t *testing.T) {
+		_, err := New("instance1").Quiesce(context.Background(), maxTimeout+time.Second)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "exceeds the maximum")
+	})
+
+	t.Run("Pause error", func(t *testing.T) {
+		c1 := &mockComponent{}
+		c2 := &mockComponent{err: errors.New("injected pause error")}
+
+		s := New("instance1", c1, c2)
+
+		_, err := s.Quiesce(context.Background(), 0)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected pause error")
+		require.False(t, c1.isPaused())
+		require.False(t, s.Status().Quiesced)
+	})
+}
+
+type mockComponent struct {
+	mutex  sync.Mutex
+	paused bool
+	pauses int
+	err    error
+}
+
+func (c *mockComponent) Pause(context.Context) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.err != nil {
+		return c.err
+	}
+
+	c.paused = true
+	c.pauses++
+
+	return nil
+}
+
+func (c *mockComponent) Resume() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.paused = false
+}
+
+func (c *mockComponent) isPaused() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.paused
+}
+
+func (c *mockComponent) numPaused() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.pauses
+}
@@ -0,0 +1,196 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/maintenance"
+)
+
+const (
+	quiesceEndpoint = "/maintenance/quiesce"
+	resumeEndpoint  = "/maintenance/resume"
+)
+
+const (
+	badRequestResponse          = "Bad Request."
+	internalServerErrorResponse = "Internal Server Error."
+)
+
+const loggerModule = "maintenance-rest-handler"
+
+type maintenanceService interface {
+	Quiesce(ctx context.Context, timeout time.Duration) (*maintenance.Status, error)
+	Resume() *maintenance.Status
+}
+
+// QuiesceHandler quiesces the server so that a consistent backup of the database may be taken.
+type QuiesceHandler struct {
+	service   maintenanceService
+	logger    *log.Log
+	marshal   func(interface{}) ([]byte, error)
+	unmarshal func([]byte, interface{}) error
+}
+
+// NewQuiesceHandler returns a new QuiesceHandler.
+func NewQuiesceHandler(service maintenanceService) *QuiesceHandler {
+	return &QuiesceHandler{
+		service:   service,
+		logger:    log.New(loggerModule, log.WithFields(log.WithServiceEndpoint(quiesceEndpoint))),
+		marshal:   json.Marshal,
+		unmarshal: json.Unmarshal,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the quiesce handler.
+func (h *QuiesceHandler) Path() string {
+	return quiesceEndpoint
+}
+
+// Method returns the HTTP REST method for the quiesce handler.
+func (h *QuiesceHandler) Method() string {
+	return http.MethodPost
+}
+
+// Handler returns the HTTP REST handle for the quiesce handler.
+func (h *QuiesceHandler) Handler() common.HTTPRequestHandler {
+	return h.handle
+}
+
+func (h *QuiesceHandler) handle(w http.ResponseWriter, req *http.Request) {
+	reqBytes, err := io.ReadAll(req.Body)
+	if err != nil {
+		h.logger.Error("Error reading request body", log.WithError(err))
+
+		writeResponse(h.logger, w, http.StatusBadRequest, []byte(badRequestResponse))
+
+		return
+	}
+
+	timeout, err := h.getTimeout(reqBytes)
+	if err != nil {
+		h.logger.Info("Invalid quiesce request", log.WithError(err))
+
+		writeResponse(h.logger, w, http.StatusBadRequest, []byte(badRequestResponse))
+
+		return
+	}
+
+	status, err := h.service.Quiesce(req.Context(), timeout)
+	if err != nil {
+		h.logger.Error("Error quiescing server", log.WithError(err))
+
+		writeResponse(h.logger, w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	writeJSON(h.logger, w, h.marshal, status)
+}
+
+func (h *QuiesceHandler) getTimeout(reqBytes []byte) (time.Duration, error) {
+	if len(reqBytes) == 0 {
+		return 0, nil
+	}
+
+	request := &quiesceRequest{}
+
+	if err := h.unmarshal(reqBytes, request); err != nil {
+		return 0, fmt.Errorf("unmarshal request: %w", err)
+	}
+
+	if request.Timeout == "" {
+		return 0, nil
+	}
+
+	timeout, err := time.ParseDuration(request.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("parse timeout: %w", err)
+	}
+
+	return timeout, nil
+}
+
+// ResumeHandler resumes a quiesced server.
+type ResumeHandler struct {
+	service maintenanceService
+	logger  *log.Log
+	marshal func(interface{}) ([]byte, error)
+}
+
+// NewResumeHandler returns a new ResumeHandler.
+func NewResumeHandler(service maintenanceService) *ResumeHandler {
+	return &ResumeHandler{
+		service: service,
+		logger:  log.New(loggerModule, log.WithFields(log.WithServiceEndpoint(resumeEndpoint))),
+		marshal: json.Marshal,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the resume handler.
+func (h *ResumeHandler) Path() string {
+	return resumeEndpoint
+}
+
+// Method returns the HTTP REST method for the resume handler.
+func (h *ResumeHandler) Method() string {
+	return http.MethodPost
+}
+
+// Handler returns the HTTP REST handle for the resume handler.
+func (h *ResumeHandler) Handler() common.HTTPRequestHandler {
+	return h.handle
+}
+
+func (h *ResumeHandler) handle(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(h.logger, w, h.marshal, h.service.Resume())
+}
+
+type quiesceRequest struct {
+	// Timeout is the duration (e.g. "30m") after which the server is automatically resumed.
+	Timeout string `json:"timeout,omitempty"`
+}
+
+func writeJSON(logger *log.Log, w http.ResponseWriter, marshal func(interface{}) ([]byte, error), v interface{}) {
+	respBytes, err := marshal(v)
+	if err != nil {
+		logger.Error("Marshal response error", log.WithError(err))
+
+		writeResponse(logger, w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	writeResponse(logger, w, http.StatusOK, respBytes)
+}
+
+func writeResponse(logger *log.Log, w http.ResponseWriter, status int, body []byte) {
+	if len(body) > 0 {
+		w.Header().Set("Content-Type", "text/plain")
+	}
+
+	w.WriteHeader(status)
+
+	if len(body) > 0 {
+		if _, err := w.Write(body); err != nil {
+			log.WriteResponseBodyError(logger, err)
+
+			return
+		}
+
+		log.WroteResponse(logger, body)
+	}
+}
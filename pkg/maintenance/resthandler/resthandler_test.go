@@ -0,0 +1,140 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/maintenance"
+)
+
+func TestQuiesceHandler(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		svc := &mockService{}
+
+		h := NewQuiesceHandler(svc)
+		require.Equal(t, quiesceEndpoint, h.Path())
+		require.Equal(t, http.MethodPost, h.Method())
+		require.NotNil(t, h.Handler())
+
+		rw := httptest.NewRecorder()
+
+		h.Handler()(rw, httptest.NewRequest(http.MethodPost, quiesceEndpoint, strings.NewReader(`{"timeout":"10m"}`)))
+
+		result := rw.Result()
+		require.NoError(t, result.Body.Close())
+		require.Equal(t, http.StatusOK, result.StatusCode)
+		require.Equal(t, 10*time.Minute, svc.timeout)
+
+		status := &maintenance.Status{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), status))
+		require.True(t, status.Quiesced)
+	})
+
+	t.Run("No request body", func(t *testing.T) {
+		svc := &mockService{}
+
+		rw := httptest.NewRecorder()
+
+		NewQuiesceHandler(svc).Handler()(rw, httptest.NewRequest(http.MethodPost, quiesceEndpoint, nil))
+
+		result := rw.Result()
+		require.NoError(t, result.Body.Close())
+		require.Equal(t, http.StatusOK, result.StatusCode)
+		require.Zero(t, svc.timeout)
+	})
+
+	t.Run("Invalid request", func(t *testing.T) {
+		for _, body := range []string{`{`, `{"timeout":"xxx"}`} {
+			rw := httptest.NewRecorder()
+
+			NewQuiesceHandler(&mockService{}).Handler()(rw,
+				httptest.NewRequest(http.MethodPost, quiesceEndpoint, strings.NewReader(body)))
+
+			result := rw.Result()
+			require.NoError(t, result.Body.Close())
+			require.Equal(t, http.StatusBadRequest, result.StatusCode)
+		}
+	})
+
+	t.Run("Quiesce error", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		NewQuiesceHandler(&mockService{err: errors.New("injected error")}).Handler()(rw,
+			httptest.NewRequest(http.MethodPost, quiesceEndpoint, nil))
+
+		result := rw.Result()
+		require.NoError(t, result.Body.Close())
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+	})
+
+	t.Run("Marshal error", func(t *testing.T) {
+		h := NewQuiesceHandler(&mockService{})
+		h.marshal = func(interface{}) ([]byte, error) { return nil, errors.New("injected marshal error") }
+
+		rw := httptest.NewRecorder()
+
+		h.Handler()(rw, httptest.NewRequest(http.MethodPost, quiesceEndpoint, nil))
+
+		result := rw.Result()
+		require.NoError(t, result.Body.Close())
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+	})
+}
+
+func TestResumeHandler(t *testing.T) {
+	svc := &mockService{quiesced: true}
+
+	h := NewResumeHandler(svc)
+	require.Equal(t, resumeEndpoint, h.Path())
+	require.Equal(t, http.MethodPost, h.Method())
+
+	rw := httptest.NewRecorder()
+
+	h.Handler()(rw, httptest.NewRequest(http.MethodPost, resumeEndpoint, nil))
+
+	result := rw.Result()
+	require.NoError(t, result.Body.Close())
+	require.Equal(t, http.StatusOK, result.StatusCode)
+	require.False(t, svc.quiesced)
+
+	status := &maintenance.Status{}
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), status))
+	require.False(t, status.Quiesced)
+}
+
+type mockService struct {
+	quiesced bool
+	timeout  time.Duration
+	err      error
+}
+
+func (m *mockService) Quiesce(_ context.Context, timeout time.Duration) (*maintenance.Status, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	m.quiesced = true
+	m.timeout = timeout
+
+	return &maintenance.Status{Quiesced: true}, nil
+}
+
+func (m *mockService) Resume() *maintenance.Status {
+	m.quiesced = false
+
+	return &maintenance.Status{}
+}
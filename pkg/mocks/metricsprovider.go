@@ -108,6 +108,10 @@ func (m *MetricsProvider) CASWriteTime(value time.Duration) {
 func (m *MetricsProvider) CASResolveTime(value time.Duration) {
 }
 
+// CASResolveSourceTime records the time it takes to retrieve a document from a remote CAS source.
+func (m *MetricsProvider) CASResolveSourceTime(source string, value time.Duration) {
+}
+
 // WitnessAnchorCredentialTime records the time it takes for a verifiable credential to gather proofs from all
 // required witnesses (according to witness policy). The start time is when the verifiable credential is issued
 // and the end time is the time that the witness policy is satisfied.
@@ -210,6 +214,14 @@ func (m *MetricsProvider) VerifyCIDTime(value time.Duration) {
 func (m *MetricsProvider) RequestDiscoveryTime(value time.Duration) {
 }
 
+// ResolverIncrementCacheHitCount increments the number of resolution cache hits.
+func (m *MetricsProvider) ResolverIncrementCacheHitCount() {
+}
+
+// ResolverIncrementCacheMissCount increments the number of resolution cache misses.
+func (m *MetricsProvider) ResolverIncrementCacheMissCount() {
+}
+
 // DecorateTime records the time it takes to decorate operation (for update handler).
 func (m *MetricsProvider) DecorateTime(value time.Duration) {
 }
@@ -34,13 +34,24 @@ func IsValidCID(value string) bool {
 	return true
 }
 
-// ToV0CID takes a multibase-encoded multihash and converts it to a V0 CID.
+// ToV0CID takes a multibase-encoded multihash and converts it to a V0 CID. Only SHA2-256 multihashes
+// may be converted to a V0 CID.
 func ToV0CID(multibaseEncodedMultihash string) (string, error) {
 	multihash, err := getMultihashFromMultibaseEncodedMultihash(multibaseEncodedMultihash)
 	if err != nil {
 		return "", err
 	}
 
+	decoded, err := mh.Decode(multihash)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode multihash: %w", err)
+	}
+
+	if decoded.Code != mh.SHA2_256 {
+		return "", fmt.Errorf("multihash code [%d] is not supported by V0 CIDs (only SHA2-256 is supported)",
+			decoded.Code)
+	}
+
 	return gocid.NewCidV0(multihash).String(), nil
 }
 
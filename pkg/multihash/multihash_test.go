@@ -11,6 +11,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/trustbloc/orb/pkg/hashlink"
 	"github.com/trustbloc/orb/pkg/multihash"
 )
 
@@ -41,6 +42,14 @@ func TestToV0CID(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, "QmS6haUrtQ8tcTTLCMdknWXAhUci1g1wfHorxM65RxNc5R", v1CID)
 	})
+	t.Run("Multihash algorithm not supported", func(t *testing.T) {
+		rh, err := hashlink.New(hashlink.WithMultihashCode(hashlink.SHA3_256)).CreateResourceHash([]byte("content"))
+		require.NoError(t, err)
+
+		v0CID, err := multihash.ToV0CID(rh)
+		require.EqualError(t, err, "multihash code [22] is not supported by V0 CIDs (only SHA2-256 is supported)")
+		require.Empty(t, v0CID)
+	})
 	t.Run("Fail to decode multibase-encoded multihash", func(t *testing.T) {
 		v1CID, err := multihash.ToV0CID("")
 		require.EqualError(t, err, "failed to decode multibase-encoded multihash: "+
@@ -61,6 +70,20 @@ func TestToV1CID(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, "bafkreibx3pob32ai67uyizvhwndjdydzaa45ln6acf2mmtb7g7l3epateq", v1CID)
 	})
+	t.Run("Success - other multihash algorithms", func(t *testing.T) {
+		for _, code := range []uint{hashlink.SHA3_256, hashlink.BLAKE2b_256} {
+			rh, err := hashlink.New(hashlink.WithMultihashCode(code)).CreateResourceHash([]byte("content"))
+			require.NoError(t, err)
+
+			v1CID, err := multihash.ToV1CID(rh)
+			require.NoError(t, err)
+			require.True(t, multihash.IsValidCID(v1CID))
+
+			multihashFromCID, err := multihash.CIDToMultihash(v1CID)
+			require.NoError(t, err)
+			require.Equal(t, rh, multihashFromCID)
+		}
+	})
 	t.Run("Fail to decode multibase-encoded multihash", func(t *testing.T) {
 		v1CID, err := multihash.ToV1CID("")
 		require.EqualError(t, err, "failed to decode multibase-encoded multihash: "+
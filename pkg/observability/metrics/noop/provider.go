@@ -32,6 +32,9 @@ func (nm NoOptMetrics) GetPublishedOperations(duration time.Duration) {}
 // CASResolveTime records the time it takes to resolve a document from CAS.
 func (nm NoOptMetrics) CASResolveTime(value time.Duration) {}
 
+// CASResolveSourceTime records the time it takes to retrieve a document from a remote CAS source.
+func (nm NoOptMetrics) CASResolveSourceTime(source string, value time.Duration) {}
+
 // PutUnpublishedOperation records the time it takes to store unpublished operation.
 func (nm NoOptMetrics) PutUnpublishedOperation(duration time.Duration) {}
 
@@ -76,6 +79,17 @@ func (nm NoOptMetrics) AddProofParseCredential(value time.Duration) {}
 // AddProofSign records vct sign in add proof.
 func (nm NoOptMetrics) AddProofSign(value time.Duration) {}
 
+// ProofMonitorPendingCount records the number of credentials waiting to be included in a VCT log.
+func (nm NoOptMetrics) ProofMonitorPendingCount(value int) {}
+
+// ProofMonitorFailedCount increments the number of credentials that were not included in a VCT log
+// before the deadline.
+func (nm NoOptMetrics) ProofMonitorFailedCount() {}
+
+// ProofMonitorInclusionTime records the time from when a credential is created until its inclusion
+// in a VCT log is confirmed.
+func (nm NoOptMetrics) ProofMonitorInclusionTime(value time.Duration) {}
+
 // ProcessAnchorTime records the time it takes for the Observer to process an anchor credential.
 func (nm NoOptMetrics) ProcessAnchorTime(value time.Duration) {}
 
@@ -229,6 +243,12 @@ func (nm NoOptMetrics) VerifyCIDTime(duration time.Duration) {}
 // RequestDiscoveryTime records the time it takes to request discovery.
 func (nm NoOptMetrics) RequestDiscoveryTime(duration time.Duration) {}
 
+// ResolverIncrementCacheHitCount increments the number of resolution cache hits.
+func (nm NoOptMetrics) ResolverIncrementCacheHitCount() {}
+
+// ResolverIncrementCacheMissCount increments the number of resolution cache misses.
+func (nm NoOptMetrics) ResolverIncrementCacheMissCount() {}
+
 // DocumentCreateUpdateTime records the time it takes the REST handler to process a create/update operation.
 func (nm NoOptMetrics) DocumentCreateUpdateTime(duration time.Duration) {}
 
@@ -102,10 +102,11 @@ type PromMetrics struct {
 	observerProcessAnchorTime prometheus.Histogram
 	observerProcessDIDTime    prometheus.Histogram
 
-	casWriteTime     prometheus.Histogram
-	casResolveTime   prometheus.Histogram
-	casCacheHitCount prometheus.Counter
-	casReadTimes     map[string]prometheus.Histogram
+	casWriteTime          prometheus.Histogram
+	casResolveTime        prometheus.Histogram
+	casCacheHitCount      prometheus.Counter
+	casReadTimes          map[string]prometheus.Histogram
+	casResolveSourceTimes map[string]prometheus.Histogram
 
 	docCreateUpdateTime prometheus.Histogram
 	docResolveTime      prometheus.Histogram
@@ -125,6 +126,9 @@ type PromMetrics struct {
 	vctWitnessVerifyVCTimes         prometheus.Histogram
 	vctAddProofParseCredentialTimes prometheus.Histogram
 	vctAddProofSignTimes            prometheus.Histogram
+	vctProofMonitorPendingCount     prometheus.Gauge
+	vctProofMonitorFailedCount      prometheus.Counter
+	vctProofMonitorInclusionTime    prometheus.Histogram
 	signerGetKeyTimes               prometheus.Histogram
 	signerSignTimes                 prometheus.Histogram
 	signerAddLinkedDataProofTimes   prometheus.Histogram
@@ -138,6 +142,8 @@ type PromMetrics struct {
 	resolverResolveDocumentFromCreateStoreTimes  prometheus.Histogram
 	resolverVerifyCIDTimes                       prometheus.Histogram
 	resolverRequestDiscoveryTimes                prometheus.Histogram
+	resolverCacheHitCount                        prometheus.Counter
+	resolverCacheMissCount                       prometheus.Counter
 
 	decoratorDecorateTime                      prometheus.Histogram
 	decoratorProcessorResolveTime              prometheus.Histogram
@@ -202,6 +208,7 @@ func NewMetrics() metrics.Metrics {
 		casWriteTime:                                 newCASWriteTime(),
 		casResolveTime:                               newCASResolveTime(),
 		casReadTimes:                                 newCASReadTimes(),
+		casResolveSourceTimes:                        newCASResolveSourceTimes(),
 		casCacheHitCount:                             newCASCacheHitCount(),
 		docCreateUpdateTime:                          newDocCreateUpdateTime(),
 		docResolveTime:                               newDocResolveTime(),
@@ -221,6 +228,9 @@ func NewMetrics() metrics.Metrics {
 		vctWitnessVerifyVCTimes:                      newVCTWitnessVerifyVCTTime(),
 		vctAddProofParseCredentialTimes:              newVCTAddProofParseCredentialTime(),
 		vctAddProofSignTimes:                         newVCTAddProofSignTime(),
+		vctProofMonitorPendingCount:                  newVCTProofMonitorPendingCount(),
+		vctProofMonitorFailedCount:                   newVCTProofMonitorFailedCount(),
+		vctProofMonitorInclusionTime:                 newVCTProofMonitorInclusionTime(),
 		signerGetKeyTimes:                            newSignerGetKeyTime(),
 		signerSignTimes:                              newSignerSignTime(),
 		signerAddLinkedDataProofTimes:                newSignerAddLinkedDataProofTime(),
@@ -232,6 +242,8 @@ func NewMetrics() metrics.Metrics {
 		resolverResolveDocumentFromCreateStoreTimes:  newResolverResolveDocumentFromCreateStoreTime(),
 		resolverVerifyCIDTimes:                       newResolverVerifyCIDTime(),
 		resolverRequestDiscoveryTimes:                newResolverRequestDiscoveryTime(),
+		resolverCacheHitCount:                        newResolverCacheHitCount(),
+		resolverCacheMissCount:                       newResolverCacheMissCount(),
 		decoratorDecorateTime:                        newDecoratorDecorateTime(),
 		decoratorProcessorResolveTime:                newDecoratorProcessorResolveTime(),
 		decoratorGetAOEndpointAndResolveFromAOTime:   newDecoratorGetAOEndpointAndResolveFromAOTime(),
@@ -278,13 +290,15 @@ func registerMetrics(pm *PromMetrics) { //nolint:cyclop
 		pm.docCreateUpdateTime, pm.docResolveTime,
 		pm.vctWitnessAddProofVCTNilTimes, pm.vctWitnessAddVCTimes, pm.vctWitnessAddProofTimes,
 		pm.vctWitnessAddWebFingerTimes, pm.vctWitnessVerifyVCTimes, pm.vctAddProofParseCredentialTimes,
-		pm.vctAddProofSignTimes, pm.signerSignTimes, pm.signerGetKeyTimes, pm.signerAddLinkedDataProofTimes,
+		pm.vctAddProofSignTimes, pm.vctProofMonitorPendingCount, pm.vctProofMonitorFailedCount,
+		pm.vctProofMonitorInclusionTime, pm.signerSignTimes, pm.signerGetKeyTimes, pm.signerAddLinkedDataProofTimes,
 		pm.anchorWriteResolveHostMetaLinkTime,
 		pm.webResolverResolveDocument,
 		pm.resolverResolveDocumentLocallyTimes, pm.resolverGetAnchorOriginEndpointTimes,
 		pm.resolverResolveDocumentFromAnchorOriginTimes,
 		pm.resolverResolveDocumentFromCreateStoreTimes, pm.resolverDeleteDocumentFromCreateStoreTimes,
 		pm.resolverVerifyCIDTimes, pm.resolverRequestDiscoveryTimes,
+		pm.resolverCacheHitCount, pm.resolverCacheMissCount,
 		pm.decoratorDecorateTime, pm.decoratorProcessorResolveTime, pm.decoratorGetAOEndpointAndResolveFromAOTime,
 		pm.unpublishedPutOperationTime, pm.unpublishedGetOperationsTime, pm.unpublishedCalculateOperationKeyTime,
 		pm.publishedPutOperationsTime, pm.publishedGetOperationsTime,
@@ -336,6 +350,10 @@ func registerMetrics(pm *PromMetrics) { //nolint:cyclop
 		prometheus.MustRegister(c)
 	}
 
+	for _, c := range pm.casResolveSourceTimes {
+		prometheus.MustRegister(c)
+	}
+
 	for _, c := range pm.coreCASWriteSize {
 		prometheus.MustRegister(c)
 	}
@@ -550,6 +568,14 @@ func (pm *PromMetrics) CASReadTime(casType string, value time.Duration) {
 	}
 }
 
+// CASResolveSourceTime records the time it takes to retrieve a document from a remote CAS source
+// (webcas, ipfs or domain) during CAS resolution.
+func (pm *PromMetrics) CASResolveSourceTime(source string, value time.Duration) {
+	if c, ok := pm.casResolveSourceTimes[source]; ok {
+		c.Observe(value.Seconds())
+	}
+}
+
 // DocumentCreateUpdateTime records the time it takes the REST handler to process a create/update operation.
 func (pm *PromMetrics) DocumentCreateUpdateTime(value time.Duration) {
 	pm.docCreateUpdateTime.Observe(value.Seconds())
@@ -662,6 +688,27 @@ func (pm *PromMetrics) AddProofSign(value time.Duration) {
 	logger.Debug("vct sign add proof", log.WithDuration(value))
 }
 
+// ProofMonitorPendingCount records the number of credentials waiting to be included in a VCT log.
+func (pm *PromMetrics) ProofMonitorPendingCount(value int) {
+	pm.vctProofMonitorPendingCount.Set(float64(value))
+
+	logger.Debug("vct proof monitor pending count", log.WithTotal(value))
+}
+
+// ProofMonitorFailedCount increments the number of credentials that were not included in a VCT log
+// before the deadline.
+func (pm *PromMetrics) ProofMonitorFailedCount() {
+	pm.vctProofMonitorFailedCount.Inc()
+}
+
+// ProofMonitorInclusionTime records the time from when a credential is created until its inclusion
+// in a VCT log is confirmed.
+func (pm *PromMetrics) ProofMonitorInclusionTime(value time.Duration) {
+	pm.vctProofMonitorInclusionTime.Observe(value.Seconds())
+
+	logger.Debug("vct proof monitor inclusion time", log.WithDuration(value))
+}
+
 // SignerGetKey records get key time.
 func (pm *PromMetrics) SignerGetKey(value time.Duration) {
 	pm.signerGetKeyTimes.Observe(value.Seconds())
@@ -732,6 +779,16 @@ func (pm *PromMetrics) RequestDiscoveryTime(value time.Duration) {
 	logger.Debug("resolver request discovery time", log.WithDuration(value))
 }
 
+// ResolverIncrementCacheHitCount increments the number of resolution cache hits.
+func (pm *PromMetrics) ResolverIncrementCacheHitCount() {
+	pm.resolverCacheHitCount.Inc()
+}
+
+// ResolverIncrementCacheMissCount increments the number of resolution cache misses.
+func (pm *PromMetrics) ResolverIncrementCacheMissCount() {
+	pm.resolverCacheMissCount.Inc()
+}
+
 // DecorateTime records the time it takes to decorate operation (for update handler).
 func (pm *PromMetrics) DecorateTime(value time.Duration) {
 	pm.decoratorDecorateTime.Observe(value.Seconds())
@@ -1201,6 +1258,20 @@ func newCASReadTimes() map[string]prometheus.Histogram {
 	return times
 }
 
+func newCASResolveSourceTimes() map[string]prometheus.Histogram {
+	times := make(map[string]prometheus.Histogram)
+
+	for _, source := range []string{"webcas", "ipfs", "domain"} {
+		times[source] = newHistogram(
+			metrics.Cas, metrics.CasResolveSourceTimeMetric,
+			"The time (in seconds) that it takes to retrieve a document from a remote CAS source during resolution.",
+			prometheus.Labels{"source": source},
+		)
+	}
+
+	return times
+}
+
 func newDocCreateUpdateTime() prometheus.Histogram {
 	return newHistogram(
 		metrics.Document, metrics.DocCreateUpdateTimeMetric,
@@ -1371,6 +1442,30 @@ func newVCTAddProofSignTime() prometheus.Histogram {
 	)
 }
 
+func newVCTProofMonitorPendingCount() prometheus.Gauge {
+	return newGauge(
+		metrics.Vct, metrics.VctProofMonitorPendingCountMetric,
+		"The number of credentials that are waiting to be included in a VCT log.",
+		nil,
+	)
+}
+
+func newVCTProofMonitorFailedCount() prometheus.Counter {
+	return newCounter(
+		metrics.Vct, metrics.VctProofMonitorFailedCountMetric,
+		"The number of credentials that were not included in a VCT log before the deadline.",
+		nil,
+	)
+}
+
+func newVCTProofMonitorInclusionTime() prometheus.Histogram {
+	return newHistogram(
+		metrics.Vct, metrics.VctProofMonitorInclusionTimeMetric,
+		"The time (in seconds) from when a credential is created until its inclusion in a VCT log is confirmed.",
+		nil,
+	)
+}
+
 func newSignerGetKeyTime() prometheus.Histogram {
 	return newHistogram(
 		metrics.Signer, metrics.SignerGetKeyTimeMetric,
@@ -1459,6 +1554,22 @@ func newResolverRequestDiscoveryTime() prometheus.Histogram {
 	)
 }
 
+func newResolverCacheHitCount() prometheus.Counter {
+	return newCounter(
+		metrics.Resolver, metrics.ResolverCacheHitCountMetric,
+		"The number of times a DID was resolved from the resolution cache.",
+		nil,
+	)
+}
+
+func newResolverCacheMissCount() prometheus.Counter {
+	return newCounter(
+		metrics.Resolver, metrics.ResolverCacheMissCountMetric,
+		"The number of times a DID was not found in the resolution cache and was resolved from its operations.",
+		nil,
+	)
+}
+
 func newDecoratorDecorateTime() prometheus.Histogram {
 	return newHistogram(
 		metrics.Decorator, metrics.DecoratorDecorateTimeMetric,
@@ -48,6 +48,7 @@ func TestMetrics(t *testing.T) {
 		require.NotPanics(t, func() { m.CASResolveTime(time.Second) })
 		require.NotPanics(t, func() { m.CASIncrementCacheHitCount() })
 		require.NotPanics(t, func() { m.CASReadTime("local", time.Second) })
+		require.NotPanics(t, func() { m.CASResolveSourceTime("webcas", time.Second) })
 		require.NotPanics(t, func() { m.DocumentCreateUpdateTime(time.Second) })
 		require.NotPanics(t, func() { m.DocumentResolveTime(time.Second) })
 		require.NotPanics(t, func() { m.OutboxIncrementActivityCount("Create") })
@@ -65,6 +66,9 @@ func TestMetrics(t *testing.T) {
 		require.NotPanics(t, func() { m.WitnessVerifyVCTSignature(time.Second) })
 		require.NotPanics(t, func() { m.AddProofParseCredential(time.Second) })
 		require.NotPanics(t, func() { m.AddProofSign(time.Second) })
+		require.NotPanics(t, func() { m.ProofMonitorPendingCount(10) })
+		require.NotPanics(t, func() { m.ProofMonitorFailedCount() })
+		require.NotPanics(t, func() { m.ProofMonitorInclusionTime(time.Second) })
 		require.NotPanics(t, func() { m.SignerGetKey(time.Second) })
 		require.NotPanics(t, func() { m.SignerSign(time.Second) })
 		require.NotPanics(t, func() { m.SignerAddLinkedDataProof(time.Second) })
@@ -75,6 +79,8 @@ func TestMetrics(t *testing.T) {
 		require.NotPanics(t, func() { m.ResolveDocumentFromCreateDocumentStoreTime(time.Second) })
 		require.NotPanics(t, func() { m.VerifyCIDTime(time.Second) })
 		require.NotPanics(t, func() { m.RequestDiscoveryTime(time.Second) })
+		require.NotPanics(t, func() { m.ResolverIncrementCacheHitCount() })
+		require.NotPanics(t, func() { m.ResolverIncrementCacheMissCount() })
 		require.NotPanics(t, func() { m.DecorateTime(time.Second) })
 		require.NotPanics(t, func() { m.ProcessorResolveTime(time.Second) })
 		require.NotPanics(t, func() { m.GetAOEndpointAndResolveDocumentFromAOTime(time.Second) })
@@ -58,11 +58,12 @@ const (
 	ObserverProcessDIDTimeMetric    = "process_did_seconds"
 
 	// Cas CAS.
-	Cas                    = "cas"
-	CasWriteTimeMetric     = "write_seconds"
-	CasResolveTimeMetric   = "resolve_seconds"
-	CasCacheHitCountMetric = "cache_hit_count"
-	CasReadTimeMetric      = "read_seconds"
+	Cas                        = "cas"
+	CasWriteTimeMetric         = "write_seconds"
+	CasResolveTimeMetric       = "resolve_seconds"
+	CasCacheHitCountMetric     = "cache_hit_count"
+	CasReadTimeMetric          = "read_seconds"
+	CasResolveSourceTimeMetric = "resolve_source_seconds"
 
 	// Document handler.
 	Document                  = "document"
@@ -88,6 +89,9 @@ const (
 	VctWitnessVerifyVCTTimeMetric        = "witness_verify_vct_signature_seconds"
 	VctAddProofParseCredentialTimeMetric = "witness_add_proof_parse_credential_seconds" //nolint:gosec
 	VctAddProofSignTimeMetric            = "witness_add_proof_sign_seconds"
+	VctProofMonitorPendingCountMetric    = "proof_monitor_pending_count"
+	VctProofMonitorFailedCountMetric     = "proof_monitor_failed_count"
+	VctProofMonitorInclusionTimeMetric   = "proof_monitor_inclusion_seconds"
 
 	// Signer Signer.
 	Signer                         = "signer"
@@ -105,6 +109,8 @@ const (
 	ResolverDeleteDocumentFromCreateStoreTimeMetric   = "delete_document_from_create_document_store_seconds"
 	ResolverVerifyCIDTimeMetric                       = "verify_cid_seconds"
 	ResolverRequestDiscoveryTimeMetric                = "request_discovery_seconds"
+	ResolverCacheHitCountMetric                       = "cache_hit_count"
+	ResolverCacheMissCountMetric                      = "cache_miss_count"
 
 	// WebResolver Resolver.
 	WebResolver                = "web_resolver"
@@ -172,6 +178,7 @@ type Metrics interface {
 	PutPublishedOperations(duration time.Duration)
 	GetPublishedOperations(duration time.Duration)
 	CASResolveTime(value time.Duration)
+	CASResolveSourceTime(source string, value time.Duration)
 	PutUnpublishedOperation(duration time.Duration)
 	GetUnpublishedOperations(duration time.Duration)
 	CalculateUnpublishedOperationKey(duration time.Duration)
@@ -186,6 +193,9 @@ type Metrics interface {
 	WitnessVerifyVCTSignature(value time.Duration)
 	AddProofParseCredential(value time.Duration)
 	AddProofSign(value time.Duration)
+	ProofMonitorPendingCount(value int)
+	ProofMonitorFailedCount()
+	ProofMonitorInclusionTime(value time.Duration)
 	ProcessAnchorTime(value time.Duration)
 	ProcessDIDTime(value time.Duration)
 	InboxHandlerTime(activityType string, value time.Duration)
@@ -235,6 +245,8 @@ type Metrics interface {
 	ResolveDocumentFromCreateDocumentStoreTime(duration time.Duration)
 	VerifyCIDTime(duration time.Duration)
 	RequestDiscoveryTime(duration time.Duration)
+	ResolverIncrementCacheHitCount()
+	ResolverIncrementCacheMissCount()
 	DocumentCreateUpdateTime(duration time.Duration)
 	WebDocumentResolveTime(duration time.Duration)
 	HTTPCreateUpdateTime(duration time.Duration)
@@ -0,0 +1,69 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package observer
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	anchorinfo "github.com/trustbloc/orb/pkg/anchor/info"
+	"github.com/trustbloc/orb/pkg/internal/testutil"
+	"github.com/trustbloc/orb/pkg/pubsub/envelope"
+)
+
+// TestCompatibility replays anchor and DID messages that were recorded from previous releases. A recorded
+// message must be added to testdata/compat whenever the schema version of the corresponding message changes.
+func TestCompatibility(t *testing.T) {
+	h := &PubSub{jsonUnmarshal: json.Unmarshal}
+
+	t.Run("Anchor info", func(t *testing.T) {
+		for name, msg := range testutil.LoadRecordedMessages(t, "testdata/compat/anchor-info") {
+			anchorInfo := &anchorinfo.AnchorInfo{}
+
+			require.NoError(t, h.decode(anchorInfoSchema, msg, anchorInfo), name)
+			require.NotEmpty(t, anchorInfo.Hashlink, name)
+			require.NotEmpty(t, anchorInfo.LocalHashlink, name)
+			require.NotEmpty(t, anchorInfo.AttributedTo, name)
+			require.NotEmpty(t, anchorInfo.AlternateSources, name)
+		}
+	})
+
+	t.Run("DID", func(t *testing.T) {
+		for name, msg := range testutil.LoadRecordedMessages(t, "testdata/compat/did") {
+			var did string
+
+			require.NoError(t, h.decode(didSchema, msg, &did), name)
+			require.NotEmpty(t, did, name)
+		}
+	})
+}
+
+func TestNewerSchemaVersion(t *testing.T) {
+	h := &PubSub{
+		jsonUnmarshal: json.Unmarshal,
+		processAnchors: func(*anchorinfo.AnchorInfo) error {
+			t.Fatal("message should not have been processed")
+
+			return nil
+		},
+	}
+
+	msg := anchorInfoSchema.NewMessage([]byte(`{}`))
+	msg.Metadata.Set(envelope.MetadataSchemaVersion, "1000")
+
+	h.handleAnchorCredentialMessage(msg)
+
+	// The message should be nacked so that it may be processed by a server which supports the version.
+	select {
+	case <-msg.Nacked():
+	case <-time.After(time.Second):
+		t.Fatal("expecting message to be nacked")
+	}
+}
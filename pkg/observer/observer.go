@@ -31,6 +31,7 @@ import (
 	"github.com/trustbloc/orb/pkg/errors"
 	"github.com/trustbloc/orb/pkg/hashlink"
 	"github.com/trustbloc/orb/pkg/linkset"
+	"github.com/trustbloc/orb/pkg/maintenance"
 	"github.com/trustbloc/orb/pkg/pubsub/spi"
 )
 
@@ -113,10 +114,15 @@ type monitoringSvc interface {
 
 type outboxProvider func() Outbox
 
+type resolutionCache interface {
+	Invalidate(suffixes ...string)
+}
+
 type options struct {
 	discoveryDomain          string
 	subscriberPoolSize       int
 	proofMonitoringSvcExpiry time.Duration
+	resolutionCache          resolutionCache
 }
 
 // Option is an option for observer.
@@ -143,6 +149,14 @@ func WithProofMonitoringExpiryPeriod(value time.Duration) Option {
 	}
 }
 
+// WithResolutionCache sets the DID resolution cache. The cached resolution results of the suffixes in an anchor
+// are invalidated after the anchor is processed.
+func WithResolutionCache(value resolutionCache) Option {
+	return func(opts *options) {
+		opts.resolutionCache = value
+	}
+}
+
 // Providers contains all of the providers required by the observer.
 type Providers struct {
 	ProtocolClientProvider protocol.ClientProvider
@@ -168,12 +182,15 @@ type Observer struct {
 	pubSub              *PubSub
 	discoveryDomain     string
 	monitoringSvcExpiry time.Duration
+	resolutionCache     resolutionCache
+	gate                *maintenance.Gate
 }
 
 // New returns a new observer.
 func New(serviceIRI *url.URL, providers *Providers, opts ...Option) (*Observer, error) {
 	optns := &options{
 		proofMonitoringSvcExpiry: defaultMonitoringSvcExpiry,
+		resolutionCache:          &noopResolutionCache{},
 	}
 
 	for _, opt := range opts {
@@ -185,6 +202,8 @@ func New(serviceIRI *url.URL, providers *Providers, opts ...Option) (*Observer,
 		Providers:           providers,
 		discoveryDomain:     optns.discoveryDomain,
 		monitoringSvcExpiry: optns.proofMonitoringSvcExpiry,
+		resolutionCache:     optns.resolutionCache,
+		gate:                maintenance.NewGate(),
 	}
 
 	subscriberPoolSize := optns.subscriberPoolSize
@@ -212,12 +231,34 @@ func (o *Observer) Stop() {
 	o.pubSub.Stop()
 }
 
+// Pause stops the processing of anchors and DIDs (which remain in the message queue) and waits for the anchors
+// and DIDs that are currently being processed.
+func (o *Observer) Pause(ctx context.Context) error {
+	if err := o.gate.Pause(ctx); err != nil {
+		return fmt.Errorf("pause observer: %w", err)
+	}
+
+	logger.Info("Observer paused")
+
+	return nil
+}
+
+// Resume resumes the processing of anchors and DIDs.
+func (o *Observer) Resume() {
+	o.gate.Resume()
+
+	logger.Info("Observer resumed")
+}
+
 // Publisher returns the publisher that adds anchors and DIDs to a message queue for processing.
 func (o *Observer) Publisher() Publisher {
 	return o.pubSub
 }
 
 func (o *Observer) handleAnchor(anchor *anchorinfo.AnchorInfo) error {
+	done := o.gate.Enter()
+	defer done()
+
 	logger.Debug("Observing anchor", log.WithAnchorEventURIString(anchor.Hashlink),
 		log.WithLocalHashlink(anchor.LocalHashlink), log.WithAttributedTo(anchor.AttributedTo))
 
@@ -249,6 +290,9 @@ func (o *Observer) handleAnchor(anchor *anchorinfo.AnchorInfo) error {
 }
 
 func (o *Observer) processDID(did string) error {
+	done := o.gate.Enter()
+	defer done()
+
 	logger.Debug("Processing out-of-system DID", log.WithDID(did))
 
 	startTime := time.Now()
@@ -399,6 +443,8 @@ func (o *Observer) processAnchor(anchor *anchorinfo.AnchorInfo,
 		return fmt.Errorf("failed updating did anchor references for anchor credential[%s]: %w", anchor.Hashlink, err)
 	}
 
+	o.resolutionCache.Invalidate(acSuffixes...)
+
 	logger.Info("Successfully processed DIDs in anchor", log.WithTotal(int(anchorPayload.OperationCount)),
 		log.WithAnchorEventURIString(anchor.Hashlink), log.WithCoreIndex(anchorPayload.CoreIndex))
 
@@ -661,3 +707,7 @@ func getUniqueDomainCreated(proofs []verifiable.Proof) []verifiable.Proof {
 
 	return result
 }
+
+type noopResolutionCache struct{}
+
+func (c *noopResolutionCache) Invalidate(...string) {}
@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"sync"
 	"testing"
 	"time"
 
@@ -349,7 +350,9 @@ func TestStartObserver(t *testing.T) {
 			AnchorLinksetBuilder:   anchorlinkset.NewBuilder(generator.NewRegistry()),
 		}
 
-		o, err := New(serviceIRI, providers)
+		resolutionCache := &mockResolutionCache{}
+
+		o, err := New(serviceIRI, providers, WithResolutionCache(resolutionCache))
 		require.NotNil(t, o)
 		require.NoError(t, err)
 
@@ -360,6 +363,7 @@ func TestStartObserver(t *testing.T) {
 		time.Sleep(200 * time.Millisecond)
 
 		require.Equal(t, 2, tp.ProcessCallCount())
+		require.Equal(t, []string{did1, did1}, resolutionCache.getSuffixes())
 	})
 
 	t.Run("success - did and anchor", func(t *testing.T) {
@@ -877,6 +881,42 @@ func TestStartObserver(t *testing.T) {
 	})
 }
 
+func TestObserver_PauseResume(t *testing.T) {
+	errExpected := errors.New("injected read error")
+
+	providers := &Providers{
+		PubSub:      mempubsub.New(mempubsub.DefaultConfig()),
+		AnchorGraph: &errAnchorGraph{err: errExpected},
+		Metrics:     &orbmocks.MetricsProvider{},
+	}
+
+	o, err := New(serviceIRI, providers)
+	require.NoError(t, err)
+
+	require.NoError(t, o.Pause(context.Background()))
+
+	errChan := make(chan error, 1)
+
+	go func() {
+		errChan <- o.handleAnchor(&anchorinfo.AnchorInfo{Hashlink: "hl:xxx"})
+	}()
+
+	select {
+	case <-errChan:
+		t.Fatal("anchor should not be processed while the observer is paused")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	o.Resume()
+
+	select {
+	case err := <-errChan:
+		require.ErrorIs(t, err, errExpected)
+	case <-time.After(time.Second):
+		t.Fatal("anchor should be processed after the observer is resumed")
+	}
+}
+
 func TestResolveActorFromHashlink(t *testing.T) {
 	const hl = "hl:uEiBdcSP14brpoA76draKLGbh4cfxhrRfTWq7Ay3A3RVJyw:uoQ-BeEtodHRwczovL29yYi5kb21haW4yLmNvbS9jYXMvdUVpQmRjU1AxNGJycG9BNzZkcmFLTEdiaDRjZnhoclJmVFdxN0F5M0EzUlZKeXc"
 
@@ -1210,3 +1250,34 @@ const testVCInvalidCreated = `{
     "AnchorCredential"
   ]
 }`
+
+type mockResolutionCache struct {
+	mutex    sync.Mutex
+	suffixes []string
+}
+
+func (m *mockResolutionCache) Invalidate(suffixes ...string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.suffixes = append(m.suffixes, suffixes...)
+}
+
+func (m *mockResolutionCache) getSuffixes() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.suffixes
+}
+
+type errAnchorGraph struct {
+	err error
+}
+
+func (g *errAnchorGraph) Read(string) (*linkset.Linkset, error) {
+	return nil, g.err
+}
+
+func (g *errAnchorGraph) GetDidAnchors(string, string) ([]graph.Anchor, error) {
+	return nil, g.err
+}
@@ -11,7 +11,6 @@ import (
 	"encoding/json"
 	"fmt"
 
-	"github.com/ThreeDotsLabs/watermill"
 	"github.com/ThreeDotsLabs/watermill/message"
 	"go.uber.org/zap"
 
@@ -19,6 +18,7 @@ import (
 	anchorinfo "github.com/trustbloc/orb/pkg/anchor/info"
 	"github.com/trustbloc/orb/pkg/errors"
 	"github.com/trustbloc/orb/pkg/lifecycle"
+	"github.com/trustbloc/orb/pkg/pubsub/envelope"
 	"github.com/trustbloc/orb/pkg/pubsub/spi"
 )
 
@@ -27,6 +27,13 @@ const (
 	didTopic    = "orb.did"
 )
 
+// The schema version must be incremented (and an upcaster added) whenever the structure of the
+// corresponding message changes in a way that isn't backward compatible.
+var (
+	anchorInfoSchema = envelope.New("anchor-info", 1)
+	didSchema        = envelope.New("did", 1)
+)
+
 type (
 	anchorProcessor func(anchor *anchorinfo.AnchorInfo) error
 	didProcessor    func(did string) error
@@ -98,7 +105,7 @@ func (h *PubSub) PublishAnchor(anchorInfo *anchorinfo.AnchorInfo) error {
 		return fmt.Errorf("publish anchorInfo: %w", err)
 	}
 
-	msg := message.NewMessage(watermill.NewUUID(), payload)
+	msg := anchorInfoSchema.NewMessage(payload)
 
 	logger.Debug("Publishing anchors message to queue", log.WithMessageID(msg.UUID),
 		log.WithTopic(anchorTopic), log.WithData(msg.Payload))
@@ -128,7 +135,7 @@ func (h *PubSub) PublishDID(did string) error {
 		return fmt.Errorf("publish DID: %w", err)
 	}
 
-	msg := message.NewMessage(watermill.NewUUID(), payload)
+	msg := didSchema.NewMessage(payload)
 
 	logger.Debug("Publishing DIDs to queue", log.WithTopic(didTopic), log.WithDID(did))
 
@@ -176,12 +183,9 @@ func (h *PubSub) handleAnchorCredentialMessage(msg *message.Message) {
 
 	anchorInfo := &anchorinfo.AnchorInfo{}
 
-	err := h.jsonUnmarshal(msg.Payload, anchorInfo)
+	err := h.decode(anchorInfoSchema, msg, anchorInfo)
 	if err != nil {
-		logger.Error("Error unmarshalling anchor", log.WithMessageID(msg.UUID), log.WithError(err))
-
-		// Ack the message to indicate that it should not be redelivered since this is a persistent error.
-		msg.Ack()
+		h.ackNackMessage(msg, fmt.Errorf("decode anchor: %w", err))
 
 		return
 	}
@@ -193,14 +197,20 @@ func (h *PubSub) handleAnchorCredentialMessage(msg *message.Message) {
 func (h *PubSub) handleDIDMessage(msg *message.Message) {
 	logger.Debug("Handling message", log.WithMessageID(msg.UUID), log.WithData(msg.Payload))
 
+	if schema := msg.Metadata.Get(envelope.MetadataSchema); schema != "" && schema != didSchema.Name() {
+		// Other messages (e.g. resolution cache invalidations) are also published to the DID topic.
+		logger.Debug("Ignoring message on DID topic", log.WithMessageID(msg.UUID), log.WithValue(schema))
+
+		msg.Ack()
+
+		return
+	}
+
 	var did string
 
-	err := h.jsonUnmarshal(msg.Payload, &did)
+	err := h.decode(didSchema, msg, &did)
 	if err != nil {
-		logger.Error("Error unmarshalling message", log.WithMessageID(msg.UUID), log.WithError(err))
-
-		// Ack the message to indicate that it should not be redelivered since this is a persistent error.
-		msg.Ack()
+		h.ackNackMessage(msg, fmt.Errorf("decode DID: %w", err))
 
 		return
 	}
@@ -208,6 +218,22 @@ func (h *PubSub) handleDIDMessage(msg *message.Message) {
 	h.ackNackMessage(msg, h.processDID(did), log.WithDID(did))
 }
 
+// decode upcasts the payload of the given message to the current schema version and unmarshals it into v.
+func (h *PubSub) decode(schema *envelope.Schema, msg *message.Message, v interface{}) error {
+	payload, err := schema.Payload(msg)
+	if err != nil {
+		return err
+	}
+
+	if err := h.jsonUnmarshal(payload, v); err != nil {
+		logger.Error("Error unmarshalling message", log.WithMessageID(msg.UUID), log.WithError(err))
+
+		return fmt.Errorf("unmarshal message: %w", err)
+	}
+
+	return nil
+}
+
 func (h *PubSub) ackNackMessage(msg *message.Message, err error, logFields ...zap.Field) {
 	switch {
 	case err == nil:
@@ -18,6 +18,7 @@ import (
 	orberrors "github.com/trustbloc/orb/pkg/errors"
 	"github.com/trustbloc/orb/pkg/lifecycle"
 	"github.com/trustbloc/orb/pkg/mocks"
+	"github.com/trustbloc/orb/pkg/pubsub/envelope"
 	"github.com/trustbloc/orb/pkg/pubsub/mempubsub"
 )
 
@@ -65,6 +66,9 @@ func TestPubSub(t *testing.T) {
 	require.NoError(t, ps.PublishAnchor(anchorInfo))
 	require.NoError(t, ps.PublishDID(did))
 
+	// Messages of other schemas on the DID topic should be ignored.
+	require.NoError(t, p.Publish(didTopic, envelope.New("did-invalidation", 1).NewMessage([]byte(`["123456"]`))))
+
 	time.Sleep(1 * time.Second)
 
 	mutex.RLock()
@@ -12,11 +12,14 @@ package clientregistry
 import (
 	v_test "github.com/trustbloc/orb/pkg/protocolversion/versions/test/v_test/client"
 	v1_0 "github.com/trustbloc/orb/pkg/protocolversion/versions/v1_0/client"
+	v1_1 "github.com/trustbloc/orb/pkg/protocolversion/versions/v1_1/client"
 )
 
 const (
 	// V1_0 ...
 	V1_0 = "1.0"
+	// V1_1 ...
+	V1_1 = "1.1"
 
 	test = "test"
 )
@@ -24,6 +27,7 @@ const (
 func addVersions(registry *Registry) {
 	// register supported versions
 	registry.Register(V1_0, v1_0.New())
+	registry.Register(V1_1, v1_1.New())
 
 	// used for test only
 	registry.Register(test, v_test.New())
@@ -9,14 +9,20 @@ SPDX-License-Identifier: Apache-2.0
 
 package clientregistry
 
-import v1_0 "github.com/trustbloc/orb/pkg/protocolversion/versions/v1_0/client"
+import (
+	v1_0 "github.com/trustbloc/orb/pkg/protocolversion/versions/v1_0/client"
+	v1_1 "github.com/trustbloc/orb/pkg/protocolversion/versions/v1_1/client"
+)
 
 const (
 	// V1_0 ...
 	V1_0 = "1.0"
+	// V1_1 ...
+	V1_1 = "1.1"
 )
 
 func addVersions(registry *Registry) {
 	// register supported versions
 	registry.Register(V1_0, v1_0.New())
+	registry.Register(V1_1, v1_1.New())
 }
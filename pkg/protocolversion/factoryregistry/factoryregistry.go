@@ -15,8 +15,10 @@ import (
 	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
 
 	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/cas/extendedcasclient"
 	"github.com/trustbloc/orb/pkg/config"
 	ctxcommon "github.com/trustbloc/orb/pkg/context/common"
+	"github.com/trustbloc/orb/pkg/hashlink"
 	metricsProvider "github.com/trustbloc/orb/pkg/observability/metrics"
 	versioncommon "github.com/trustbloc/orb/pkg/protocolversion/common"
 )
@@ -28,6 +30,12 @@ type factory interface {
 		provider storage.Provider, sidetreeCfg *config.Sidetree, metrics metricsProvider.Metrics) (protocol.Version, error)
 }
 
+// anchorMultihashAlgorithmsProvider is implemented by factories of protocol versions that define the multihash
+// algorithms used to hash anchors.
+type anchorMultihashAlgorithmsProvider interface {
+	AnchorMultihashAlgorithms() []uint
+}
+
 // Registry implements a protocol version factory registry.
 type Registry struct {
 	factories map[string]factory
@@ -58,7 +66,74 @@ func (r *Registry) CreateProtocolVersion(version string, casClient cas.Client, c
 
 	logger.Info("Creating protocol version", log.WithVersion(version))
 
-	return v.Create(version, casClient, casResolver, opStore, provider, sidetreeCfg, metrics)
+	return v.Create(version, r.casClientForVersion(version, v, casClient), casResolver, opStore, provider,
+		sidetreeCfg, metrics)
+}
+
+// casClientForVersion returns a CAS client which hashes the content that's written by the protocol version
+// (i.e. batch files) using the first anchor multihash algorithm of the version. If the CAS client doesn't
+// support CID format options then the given client is returned.
+func (r *Registry) casClientForVersion(version string, f factory, casClient cas.Client) cas.Client {
+	formatter, ok := casClient.(extendedcasclient.CIDFormatter)
+	if !ok {
+		return casClient
+	}
+
+	code := getAnchorMultihashAlgorithms(f)[0]
+
+	logger.Info("Content written by protocol version will be hashed using the anchor multihash algorithm",
+		log.WithVersion(version), log.WithMultihashCode(code))
+
+	return formatter.WithCIDFormat(extendedcasclient.WithMultihashCode(code))
+}
+
+// GetAnchorMultihashAlgorithms returns the multihash algorithms that may be used to hash anchors (and other content
+// stored in the CAS) for the given version. The first algorithm is used to hash new content. If the protocol version
+// doesn't define the algorithms then the default algorithm (SHA2-256) is returned.
+func (r *Registry) GetAnchorMultihashAlgorithms(version string) ([]uint, error) {
+	v, err := r.resolveFactory(version)
+	if err != nil {
+		return nil, err
+	}
+
+	return getAnchorMultihashAlgorithms(v), nil
+}
+
+// AnchorMultihashProvider provides the multihash code that's used to hash new anchors of a given protocol version.
+type AnchorMultihashProvider struct {
+	registry *Registry
+	pc       protocol.Client
+}
+
+// NewAnchorMultihashProvider returns a provider which resolves protocol versions (by genesis time) using the
+// given protocol client.
+func (r *Registry) NewAnchorMultihashProvider(pc protocol.Client) *AnchorMultihashProvider {
+	return &AnchorMultihashProvider{registry: r, pc: pc}
+}
+
+// AnchorMultihashCode returns the multihash code of the algorithm that's used to hash new anchors for the
+// protocol version with the given genesis time.
+func (p *AnchorMultihashProvider) AnchorMultihashCode(genesisTime uint64) (uint, error) {
+	pv, err := p.pc.Get(genesisTime)
+	if err != nil {
+		return 0, fmt.Errorf("get protocol version for genesis time %d: %w", genesisTime, err)
+	}
+
+	algorithms, err := p.registry.GetAnchorMultihashAlgorithms(pv.Version())
+	if err != nil {
+		return 0, err
+	}
+
+	return algorithms[0], nil
+}
+
+func getAnchorMultihashAlgorithms(f factory) []uint {
+	p, ok := f.(anchorMultihashAlgorithmsProvider)
+	if !ok || len(p.AnchorMultihashAlgorithms()) == 0 {
+		return []uint{hashlink.SHA2_256}
+	}
+
+	return p.AnchorMultihashAlgorithms()
 }
 
 // Register registers a protocol factory for a given version.
@@ -7,14 +7,19 @@ SPDX-License-Identifier: Apache-2.0
 package factoryregistry
 
 import (
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
 	"github.com/stretchr/testify/require"
 	coremocks "github.com/trustbloc/sidetree-core-go/pkg/mocks"
 
 	"github.com/trustbloc/orb/pkg/config"
+	"github.com/trustbloc/orb/pkg/hashlink"
 	frmocks "github.com/trustbloc/orb/pkg/protocolversion/factoryregistry/mocks"
 	"github.com/trustbloc/orb/pkg/protocolversion/mocks"
+	"github.com/trustbloc/orb/pkg/store/cas"
 	storemocks "github.com/trustbloc/orb/pkg/store/mocks"
 )
 
@@ -48,3 +53,117 @@ func TestRegistry(t *testing.T) {
 	require.EqualError(t, err, "protocol version factory for version [99] not found")
 	require.Nil(t, pv)
 }
+
+func TestRegistry_GetAnchorMultihashAlgorithms(t *testing.T) {
+	r := New()
+
+	algorithms, err := r.GetAnchorMultihashAlgorithms(V1_0)
+	require.NoError(t, err)
+	require.Equal(t, []uint{hashlink.SHA2_256}, algorithms)
+
+	algorithms, err = r.GetAnchorMultihashAlgorithms(V1_1)
+	require.NoError(t, err)
+	require.Equal(t, hashlink.SHA3_256, algorithms[0])
+
+	// The factory doesn't define the algorithms so the default is returned.
+	r.Register("0.1", &frmocks.ProtocolFactory{})
+
+	algorithms, err = r.GetAnchorMultihashAlgorithms("0.1")
+	require.NoError(t, err)
+	require.Equal(t, []uint{hashlink.SHA2_256}, algorithms)
+
+	_, err = r.GetAnchorMultihashAlgorithms("99")
+	require.EqualError(t, err, "protocol version factory for version [99] not found")
+}
+
+func TestRegistry_CASClientForVersion(t *testing.T) {
+	const version = "0.1"
+
+	f := &protocolFactoryWithAlgorithms{
+		ProtocolFactory: &frmocks.ProtocolFactory{},
+		algorithms:      []uint{hashlink.SHA3_256},
+	}
+	f.CreateReturns(&coremocks.ProtocolVersion{}, nil)
+
+	r := New()
+	r.Register(version, f)
+
+	t.Run("content is hashed using the multihash of the version", func(t *testing.T) {
+		casClient, err := cas.New(mem.NewProvider(), "https://domain.com/cas", nil, &casMetrics{}, 0)
+		require.NoError(t, err)
+
+		_, err = r.CreateProtocolVersion(version, casClient, &mocks.CASResolver{}, &mocks.OperationStore{},
+			&storemocks.Provider{}, &config.Sidetree{}, nil)
+		require.NoError(t, err)
+
+		_, versionCASClient, _, _, _, _ := f.CreateArgsForCall(f.CreateCallCount() - 1) //nolint:dogsled
+		require.NotEqual(t, casClient, versionCASClient)
+
+		hl, err := versionCASClient.Write([]byte("content"))
+		require.NoError(t, err)
+
+		resourceHash, err := hashlink.GetResourceHashFromHashLink(hl)
+		require.NoError(t, err)
+
+		code, err := hashlink.New().GetMultihashCode(resourceHash)
+		require.NoError(t, err)
+		require.Equal(t, hashlink.SHA3_256, code)
+	})
+
+	t.Run("CAS client doesn't support CID format options", func(t *testing.T) {
+		casClient := &mocks.CasClient{}
+
+		_, err := r.CreateProtocolVersion(version, casClient, &mocks.CASResolver{}, &mocks.OperationStore{},
+			&storemocks.Provider{}, &config.Sidetree{}, nil)
+		require.NoError(t, err)
+
+		_, versionCASClient, _, _, _, _ := f.CreateArgsForCall(f.CreateCallCount() - 1) //nolint:dogsled
+		require.Equal(t, casClient, versionCASClient)
+	})
+}
+
+func TestAnchorMultihashProvider(t *testing.T) {
+	r := New()
+
+	t.Run("success", func(t *testing.T) {
+		pc := coremocks.NewMockProtocolClient()
+		pc.CurrentVersion.VersionReturns(V1_1)
+
+		code, err := r.NewAnchorMultihashProvider(pc).AnchorMultihashCode(0)
+		require.NoError(t, err)
+		require.Equal(t, hashlink.SHA3_256, code)
+	})
+
+	t.Run("protocol client error", func(t *testing.T) {
+		pc := coremocks.NewMockProtocolClient()
+		pc.Err = errors.New("injected protocol client error")
+
+		_, err := r.NewAnchorMultihashProvider(pc).AnchorMultihashCode(0)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected protocol client error")
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		pc := coremocks.NewMockProtocolClient()
+		pc.CurrentVersion.VersionReturns("99")
+
+		_, err := r.NewAnchorMultihashProvider(pc).AnchorMultihashCode(0)
+		require.EqualError(t, err, "protocol version factory for version [99] not found")
+	})
+}
+
+type protocolFactoryWithAlgorithms struct {
+	*frmocks.ProtocolFactory
+
+	algorithms []uint
+}
+
+func (f *protocolFactoryWithAlgorithms) AnchorMultihashAlgorithms() []uint {
+	return f.algorithms
+}
+
+type casMetrics struct{}
+
+func (m *casMetrics) CASIncrementCacheHitCount() {}
+
+func (m *casMetrics) CASReadTime(string, time.Duration) {}
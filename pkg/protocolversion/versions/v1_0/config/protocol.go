@@ -8,6 +8,8 @@ package config
 
 import (
 	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+
+	"github.com/trustbloc/orb/pkg/hashlink"
 )
 
 // GetProtocolConfig returns protocol config for this version.
@@ -34,3 +36,9 @@ func GetProtocolConfig() protocol.Protocol {
 
 	return p
 }
+
+// GetAnchorMultihashAlgorithms returns the multihash algorithms that may be used to hash anchors (and other content
+// stored in the CAS) for this version. The first algorithm is used to hash new content.
+func GetAnchorMultihashAlgorithms() []uint {
+	return []uint{hashlink.SHA2_256}
+}
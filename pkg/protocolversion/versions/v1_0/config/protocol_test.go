@@ -10,6 +10,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/hashlink"
 )
 
 func TestGetProtocolConfig(t *testing.T) {
@@ -23,3 +25,7 @@ func TestGetProtocolConfig(t *testing.T) {
 		require.Equal(t, []string{"Ed25519", "P-256", "P-384", "secp256k1"}, cfg.KeyAlgorithms)
 	})
 }
+
+func TestGetAnchorMultihashAlgorithms(t *testing.T) {
+	require.Equal(t, []uint{hashlink.SHA2_256}, GetAnchorMultihashAlgorithms())
+}
@@ -100,6 +100,11 @@ func (v *Factory) Create(version string, casClient cas.Client, casResolver ctxco
 	}, nil
 }
 
+// AnchorMultihashAlgorithms returns the multihash algorithms that may be used to hash anchors for this version.
+func (v *Factory) AnchorMultihashAlgorithms() []uint {
+	return protocolcfg.GetAnchorMultihashAlgorithms()
+}
+
 type casReader struct {
 	resolver ctxcommon.CASResolver
 }
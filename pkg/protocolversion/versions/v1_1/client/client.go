@@ -0,0 +1,22 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	v1_0 "github.com/trustbloc/orb/pkg/protocolversion/versions/v1_0/client"
+)
+
+// Factory implements version 1.1 of the client factory. The client processes Sidetree operations in the same
+// way as version 1.0.
+type Factory struct {
+	*v1_0.Factory
+}
+
+// New returns a version 1.1 implementation of the Sidetree protocol.
+func New() *Factory {
+	return &Factory{Factory: v1_0.New()}
+}
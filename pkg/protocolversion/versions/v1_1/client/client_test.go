@@ -0,0 +1,26 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/config"
+	"github.com/trustbloc/orb/pkg/protocolversion/mocks"
+)
+
+func TestFactory_Create(t *testing.T) {
+	f := New()
+	require.NotNil(t, f)
+
+	pv, err := f.Create("1.1", &mocks.CasClient{}, &config.Sidetree{})
+	require.NoError(t, err)
+	require.NotNil(t, pv)
+	require.Equal(t, "1.1", pv.Version())
+}
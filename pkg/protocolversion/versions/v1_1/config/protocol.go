@@ -0,0 +1,28 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package config
+
+import (
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+
+	"github.com/trustbloc/orb/pkg/hashlink"
+	v1_0 "github.com/trustbloc/orb/pkg/protocolversion/versions/v1_0/config"
+)
+
+// GetProtocolConfig returns protocol config for this version. The Sidetree protocol parameters are the same as
+// those of version 1.0. (Sidetree operations continue to use SHA2-256 since the multihash algorithms of Sidetree
+// operation commitments and suffixes are defined by Sidetree.)
+func GetProtocolConfig() protocol.Protocol {
+	return v1_0.GetProtocolConfig()
+}
+
+// GetAnchorMultihashAlgorithms returns the multihash algorithms that may be used to hash anchors (and other content
+// stored in the CAS) for this version. New content is hashed using SHA3-256. Content that was hashed using any of
+// the other algorithms (e.g. anchors created by version 1.0) may still be resolved.
+func GetAnchorMultihashAlgorithms() []uint {
+	return []uint{hashlink.SHA3_256, hashlink.BLAKE2b_256, hashlink.SHA2_256}
+}
@@ -0,0 +1,28 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/hashlink"
+)
+
+func TestGetProtocolConfig(t *testing.T) {
+	cfg := GetProtocolConfig()
+	require.Equal(t, uint(10000), cfg.MaxOperationCount)
+	require.Equal(t, []uint{18}, cfg.MultihashAlgorithms)
+}
+
+func TestGetAnchorMultihashAlgorithms(t *testing.T) {
+	algorithms := GetAnchorMultihashAlgorithms()
+	require.Equal(t, hashlink.SHA3_256, algorithms[0])
+	require.Contains(t, algorithms, hashlink.BLAKE2b_256)
+	require.Contains(t, algorithms, hashlink.SHA2_256)
+}
@@ -0,0 +1,28 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package factory
+
+import (
+	protocolcfg "github.com/trustbloc/orb/pkg/protocolversion/versions/v1_1/config"
+	v1_0 "github.com/trustbloc/orb/pkg/protocolversion/versions/v1_0/factory"
+)
+
+// Factory implements version 1.1 of the Sidetree protocol. Version 1.1 processes Sidetree operations in the same
+// way as version 1.0 but anchors are hashed using SHA3-256.
+type Factory struct {
+	*v1_0.Factory
+}
+
+// New returns a version 1.1 implementation of the Sidetree protocol.
+func New() *Factory {
+	return &Factory{Factory: v1_0.New()}
+}
+
+// AnchorMultihashAlgorithms returns the multihash algorithms that may be used to hash anchors for this version.
+func (v *Factory) AnchorMultihashAlgorithms() []uint {
+	return protocolcfg.GetAnchorMultihashAlgorithms()
+}
@@ -0,0 +1,31 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package factory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/config"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	"github.com/trustbloc/orb/pkg/protocolversion/mocks"
+	storemocks "github.com/trustbloc/orb/pkg/store/mocks"
+)
+
+func TestFactory_Create(t *testing.T) {
+	f := New()
+	require.NotNil(t, f)
+
+	pv, err := f.Create("1.1", &mocks.CasClient{}, &mocks.CASResolver{}, &mocks.OperationStore{},
+		&storemocks.Provider{}, &config.Sidetree{}, nil)
+	require.NoError(t, err)
+	require.NotNil(t, pv)
+	require.Equal(t, "1.1", pv.Version())
+
+	require.Equal(t, hashlink.SHA3_256, f.AnchorMultihashAlgorithms()[0])
+}
@@ -16,6 +16,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/ThreeDotsLabs/watermill"
 	"github.com/ThreeDotsLabs/watermill-amqp/v2/pkg/amqp"
 	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/cenkalti/backoff"
@@ -115,7 +116,9 @@ type PubSub struct {
 	amqpConfig                  amqp.Config
 	amqpRedeliveryConfig        amqp.Config
 	amqpWaitConfig              amqp.Config
+	amqpBroadcastConfig         amqp.Config
 	subscriber                  subscriber
+	broadcastSubscriber         subscriber
 	publisher                   publisher
 	redeliverySubscriber        subscriber
 	waitSubscriber              initializingSubscriber
@@ -125,6 +128,7 @@ type PubSub struct {
 	subscriberFactory           subscriberFactory
 	createPublisher             createPublisherFunc
 	redeliverySubscriberFactory subscriberFactory
+	broadcastSubscriberFactory  subscriberFactory
 	waitSubscriberFactory       subscriberFactory
 	createWaitPublisher         publisherFactory
 	redeliveryChan              <-chan *message.Message
@@ -141,6 +145,7 @@ func New(cfg Config) *PubSub {
 		amqpConfig:           newQueueConfig(cfg),
 		amqpRedeliveryConfig: newRedeliveryQueueConfig(cfg),
 		amqpWaitConfig:       newWaitQueueConfig(cfg),
+		amqpBroadcastConfig:  newBroadcastQueueConfig(cfg, watermill.NewShortUUID()),
 		createPublisher:      createPublisher,
 	}
 
@@ -152,6 +157,10 @@ func New(cfg Config) *PubSub {
 		return amqp.NewSubscriberWithConnection(p.amqpConfig, wmlogger.New(), conn.amqpConnection())
 	}
 
+	p.broadcastSubscriberFactory = func(conn connection) (initializingSubscriber, error) {
+		return amqp.NewSubscriberWithConnection(p.amqpBroadcastConfig, wmlogger.New(), conn.amqpConnection())
+	}
+
 	p.redeliverySubscriberFactory = func(conn connection) (initializingSubscriber, error) {
 		return amqp.NewSubscriberWithConnection(p.amqpRedeliveryConfig, wmlogger.New(), conn.amqpConnection())
 	}
@@ -191,6 +200,12 @@ func (p *PubSub) SubscribeWithOpts(ctx context.Context, topic string,
 
 	options := getOptions(opts)
 
+	if options.Broadcast {
+		logger.Debug("Subscribing to topic for broadcast messages", log.WithTopic(topic))
+
+		return p.broadcastSubscriber.Subscribe(ctx, topic)
+	}
+
 	if options.PoolSize <= 1 {
 		logger.Debug("Subscribing to topic", log.WithTopic(topic))
 
@@ -221,6 +236,12 @@ func (p *PubSub) Publish(topic string, messages ...*message.Message) error {
 
 	logger.Debug("Publishing messages", log.WithTopic(topic))
 
+	for _, msg := range messages {
+		// Set the queue explicitly so that the message is redelivered to the correct queue, even if it
+		// was copied from a message that was received from another queue (e.g. a requeued undeliverable message).
+		msg.Metadata.Set(metadataQueue, topic)
+	}
+
 	if err := p.publisher.Publish(topic, messages...); err != nil {
 		for _, msg := range messages {
 			logger.Error("Error publishing message", log.WithMessageID(msg.UUID), log.WithTopic(topic))
@@ -292,6 +313,10 @@ func (p *PubSub) stop() {
 		logger.Warn("Error closing subscriber", log.WithError(err))
 	}
 
+	if err := p.broadcastSubscriber.Close(); err != nil {
+		logger.Warn("Error closing broadcast subscriber", log.WithError(err))
+	}
+
 	if err := p.redeliverySubscriber.Close(); err != nil {
 		logger.Warn("Error closing redelivery subscriber", log.WithError(err))
 	}
@@ -369,6 +394,8 @@ func (p *PubSub) connect() error {
 
 	p.subscriber = newSubscriberMgr(p.connMgr, p.subscriberFactory)
 
+	p.broadcastSubscriber = newSubscriberMgr(p.connMgr, p.broadcastSubscriberFactory)
+
 	p.redeliverySubscriber = newSubscriberMgr(p.connMgr, p.redeliverySubscriberFactory)
 
 	conn, err := p.connMgr.getConnection(true)
@@ -439,11 +466,46 @@ func (p *PubSub) handleRedelivery(msg *message.Message) {
 	} else {
 		logger.Error("Message will not be redelivered since the maximum delivery attempts has been reached",
 			log.WithMessageID(msg.UUID), log.WithTopic(queue), log.WithDeliveryAttempts(redeliveryAttempts+1))
+
+		err = p.postToUndeliverable(msg, queue, redeliveryAttempts+1)
+		if err != nil {
+			logger.Error("Error posting message to the undeliverable queue. The message will be nacked and retried.",
+				log.WithMessageID(msg.UUID), log.WithError(err))
+
+			msg.Nack()
+
+			return
+		}
 	}
 
 	msg.Ack()
 }
 
+// postToUndeliverable posts the message to the undeliverable queue so that it may be inspected and requeued
+// by an operator. Messages from the undeliverable queue itself are dropped.
+func (p *PubSub) postToUndeliverable(msg *message.Message, queue string, deliveryAttempts int) error {
+	if queue == spi.UndeliverableTopic {
+		logger.Warn("Message from the undeliverable queue was not processed and will be dropped",
+			log.WithMessageID(msg.UUID))
+
+		return nil
+	}
+
+	undeliverableMsg := newMessage(msg, withQueue(spi.UndeliverableTopic))
+	delete(undeliverableMsg.Metadata, metadataRedeliveryCount)
+	undeliverableMsg.Metadata.Set(spi.MetadataOriginalTopic, queue)
+	undeliverableMsg.Metadata.Set(spi.MetadataDeliveryAttempts, strconv.Itoa(deliveryAttempts))
+
+	err := p.publisher.Publish(spi.UndeliverableTopic, undeliverableMsg)
+	if err != nil {
+		return fmt.Errorf("publish message to queue [%s]: %w", spi.UndeliverableTopic, err)
+	}
+
+	logger.Info("Message was added to the undeliverable queue", log.WithMessageID(msg.UUID), log.WithTopic(queue))
+
+	return nil
+}
+
 func (p *PubSub) redeliver(msg *message.Message, queue string, redeliveryAttempts int) error {
 	// Publish the message immediately on the first attempt and after every expiration.
 	if redeliveryAttempts == 0 || msg.Metadata[metadataFirstDeathReason] == expiredReason {
@@ -848,6 +910,27 @@ func newWaitQueueConfig(cfg Config) amqp.Config {
 	return queueConfig
 }
 
+// newBroadcastQueueConfig returns the configuration for broadcast subscriptions. Each server instance consumes from
+// its own (temporary) queue which is bound to the topic, so every instance receives all messages published to the
+// topic. Nacked messages are discarded since the queue has no dead-letter exchange.
+func newBroadcastQueueConfig(cfg Config, instanceID string) amqp.Config {
+	suffix := "." + instanceID
+
+	queueConfig := newDefaultQueueConfig(cfg)
+	queueConfig.Exchange = newAMQPExchangeConfig(exchange)
+	queueConfig.Queue = amqp.QueueConfig{
+		GenerateName: func(topic string) string {
+			return topic + suffix
+		},
+		AutoDelete: true,
+	}
+	queueConfig.QueueBind.GenerateRoutingKey = func(queue string) string {
+		return strings.TrimSuffix(queue, suffix)
+	}
+
+	return queueConfig
+}
+
 func newDefaultQueueConfig(cfg Config) amqp.Config {
 	return amqp.Config{
 		Connection: amqp.ConnectionConfig{AmqpURI: cfg.URI},
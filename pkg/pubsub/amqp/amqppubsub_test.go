@@ -158,6 +158,45 @@ func TestAMQP(t *testing.T) {
 		})
 	})
 
+	t.Run("Broadcast -> success", func(t *testing.T) {
+		const topic = "broadcast"
+
+		p1 := New(Config{URI: mqURI})
+		require.NotNil(t, p1)
+		defer func() {
+			require.NoError(t, p1.Close())
+		}()
+
+		p2 := New(Config{URI: mqURI})
+		require.NotNil(t, p2)
+		defer func() {
+			require.NoError(t, p2.Close())
+		}()
+
+		msgChan, err := p1.Subscribe(context.Background(), topic)
+		require.NoError(t, err)
+
+		broadcastChan1, err := p1.SubscribeWithOpts(context.Background(), topic, spi.WithBroadcast())
+		require.NoError(t, err)
+
+		broadcastChan2, err := p2.SubscribeWithOpts(context.Background(), topic, spi.WithBroadcast())
+		require.NoError(t, err)
+
+		msg := message.NewMessage(watermill.NewUUID(), []byte("some payload"))
+		require.NoError(t, p2.Publish(topic, msg))
+
+		for _, c := range []<-chan *message.Message{msgChan, broadcastChan1, broadcastChan2} {
+			select {
+			case m := <-c:
+				require.Equal(t, msg.UUID, m.UUID)
+
+				m.Ack()
+			case <-time.After(time.Second):
+				t.Fatal("timed out waiting for message")
+			}
+		}
+	})
+
 	t.Run("Redelivery attempts reached", func(t *testing.T) {
 		const topic = "topic_redelivery"
 
@@ -256,6 +295,7 @@ func TestAMQP_Error(t *testing.T) {
 			waitSubscriber:       &mockSubscriber{err: errSubscribe, mockClosable: &mockClosable{err: errClose}},
 			waitPublisher:        &mockPublisher{mockClosable: &mockClosable{}},
 			redeliverySubscriber: &mockSubscriber{err: errSubscribe, mockClosable: &mockClosable{err: errClose}},
+			broadcastSubscriber:  &mockSubscriber{err: errSubscribe, mockClosable: &mockClosable{err: errClose}},
 		}
 
 		p.Start()
@@ -263,6 +303,9 @@ func TestAMQP_Error(t *testing.T) {
 
 		_, err := p.Subscribe(context.Background(), topic)
 		require.EqualError(t, err, errSubscribe.Error())
+
+		_, err = p.SubscribeWithOpts(context.Background(), topic, spi.WithBroadcast())
+		require.EqualError(t, err, errSubscribe.Error())
 	})
 
 	t.Run("Publisher error", func(t *testing.T) {
@@ -277,6 +320,7 @@ func TestAMQP_Error(t *testing.T) {
 			waitSubscriber:       &mockSubscriber{mockClosable: &mockClosable{}},
 			waitPublisher:        &mockPublisher{err: errPublish, mockClosable: &mockClosable{err: errClose}},
 			redeliverySubscriber: &mockSubscriber{mockClosable: &mockClosable{}},
+			broadcastSubscriber:  &mockSubscriber{mockClosable: &mockClosable{}},
 		}
 
 		p.Start()
@@ -299,6 +343,17 @@ func TestExtractEndpoint(t *testing.T) {
 		extractEndpoint("example.com:5671/mq"))
 }
 
+func TestNewBroadcastQueueConfig(t *testing.T) {
+	cfg := newBroadcastQueueConfig(Config{}, "instance1")
+
+	queue := cfg.Queue.GenerateName("orb.did")
+	require.Equal(t, "orb.did.instance1", queue)
+	require.Equal(t, "orb.did", cfg.QueueBind.GenerateRoutingKey(queue))
+	require.Equal(t, exchange, cfg.Exchange.GenerateName("orb.did"))
+	require.False(t, cfg.Queue.Durable)
+	require.True(t, cfg.Queue.AutoDelete)
+}
+
 func TestPubSub_GetInterval(t *testing.T) {
 	p := &PubSub{
 		Config: Config{
@@ -0,0 +1,564 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package durablepubsub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/google/uuid"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/lifecycle"
+	"github.com/trustbloc/orb/pkg/pubsub/spi"
+	"github.com/trustbloc/orb/pkg/store"
+)
+
+var logger = log.New("pubsub")
+
+const (
+	storeName = "orb-pubsub"
+
+	// topicTag is used to query all persisted messages.
+	topicTag = "topic"
+
+	defaultAckTimeout                = time.Minute
+	defaultMaxRedeliveryAttempts     = 10
+	defaultRedeliveryMultiplier      = 1.5
+	defaultRedeliveryInitialInterval = 2 * time.Second
+	defaultMaxRedeliveryInterval     = 30 * time.Second
+)
+
+// Config holds the configuration for the publisher/subscriber.
+type Config struct {
+	// AckTimeout is the time that we should wait for an Ack or a Nack. If neither is received
+	// within this time then the message is redelivered.
+	AckTimeout time.Duration
+
+	MaxRedeliveryAttempts     int
+	RedeliveryMultiplier      float64
+	RedeliveryInitialInterval time.Duration
+	MaxRedeliveryInterval     time.Duration
+}
+
+// DefaultConfig returns the default configuration.
+func DefaultConfig() Config {
+	return Config{
+		AckTimeout:                defaultAckTimeout,
+		MaxRedeliveryAttempts:     defaultMaxRedeliveryAttempts,
+		RedeliveryMultiplier:      defaultRedeliveryMultiplier,
+		RedeliveryInitialInterval: defaultRedeliveryInitialInterval,
+		MaxRedeliveryInterval:     defaultMaxRedeliveryInterval,
+	}
+}
+
+// messageRecord is the persisted form of a published message.
+type messageRecord struct {
+	Topic     string            `json:"topic"`
+	MessageID string            `json:"messageId"`
+	Payload   []byte            `json:"payload,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	DeliverAt time.Time         `json:"deliverAt"`
+	Attempts  int               `json:"attempts,omitempty"`
+}
+
+type entry struct {
+	key string
+	*messageRecord
+}
+
+func (e *entry) message() *message.Message {
+	msg := message.NewMessage(e.MessageID, e.Payload)
+
+	for k, v := range e.Metadata {
+		msg.Metadata.Set(k, v)
+	}
+
+	return msg
+}
+
+// PubSub implements a publisher/subscriber for single-node deployments which persists messages in a storage
+// provider so that they survive a restart. A message is written to the store before it is delivered and is
+// removed from the store only after it has been acknowledged by a subscriber. Subscribers of the same topic
+// compete for messages, i.e. each message is delivered to only one subscriber, except for broadcast subscribers
+// which receive (a non-persisted copy of) every message published to the topic.
+type PubSub struct {
+	*lifecycle.Lifecycle
+	Config
+
+	store          storage.Store
+	mutex          sync.RWMutex
+	queues         map[string]*queue
+	broadcastChans map[string][]chan *message.Message
+	msgChans       []chan *message.Message
+	done           chan struct{}
+	wg             sync.WaitGroup
+}
+
+// New returns a new durable publisher/subscriber. Any messages which were persisted (and not acknowledged)
+// before the last shutdown are loaded and will be delivered to subscribers of the corresponding topics.
+func New(provider storage.Provider, cfg Config) (*PubSub, error) {
+	s, err := store.Open(provider, storeName, store.NewTagGroup(topicTag))
+	if err != nil {
+		return nil, fmt.Errorf("open store [%s]: %w", storeName, err)
+	}
+
+	p := &PubSub{
+		Config:         cfg,
+		store:          s,
+		queues:         make(map[string]*queue),
+		broadcastChans: make(map[string][]chan *message.Message),
+		done:           make(chan struct{}),
+	}
+
+	if err := p.load(); err != nil {
+		return nil, err
+	}
+
+	p.Lifecycle = lifecycle.New("durablepubsub", lifecycle.WithStop(p.stop))
+
+	// Start the service immediately.
+	p.Start()
+
+	return p, nil
+}
+
+// Close closes all resources.
+func (p *PubSub) Close() error {
+	p.Stop()
+
+	return nil
+}
+
+// IsConnected return true is connected.
+func (p *PubSub) IsConnected() bool {
+	return true
+}
+
+// Subscribe subscribes to a topic and returns the Go channel over which messages
+// are sent. The returned channel will be closed when Close() is called on this struct.
+func (p *PubSub) Subscribe(ctx context.Context, topic string) (<-chan *message.Message, error) {
+	return p.SubscribeWithOpts(ctx, topic)
+}
+
+// SubscribeWithOpts subscribes to a topic and returns the Go channel over which messages are sent. If a pool
+// size is specified then up to the given number of messages are delivered concurrently. The returned channel
+// will be closed when Close() is called on this struct.
+func (p *PubSub) SubscribeWithOpts(ctx context.Context, topic string,
+	opts ...spi.Option) (<-chan *message.Message, error) {
+	if p.State() != lifecycle.StateStarted {
+		return nil, lifecycle.ErrNotStarted
+	}
+
+	options := getOptions(opts)
+
+	poolSize := options.PoolSize
+	if poolSize < 1 {
+		poolSize = 1
+	}
+
+	logger.Debug("Subscribing to topic", log.WithTopic(topic), log.WithSubscriberPoolSize(poolSize))
+
+	msgChan := make(chan *message.Message, poolSize)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	select {
+	case <-p.done:
+		return nil, lifecycle.ErrNotStarted
+	default:
+	}
+
+	if options.Broadcast {
+		p.broadcastChans[topic] = append(p.broadcastChans[topic], msgChan)
+		p.msgChans = append(p.msgChans, msgChan)
+
+		return msgChan, nil
+	}
+
+	q := p.getQueue(topic)
+	q.subscribed = true
+
+	p.msgChans = append(p.msgChans, msgChan)
+
+	for i := 0; i < poolSize; i++ {
+		p.wg.Add(1)
+
+		go p.processMessages(ctx, q, msgChan)
+	}
+
+	return msgChan, nil
+}
+
+// Publish persists the given messages and then queues them for delivery to subscribers of the given topic.
+func (p *PubSub) Publish(topic string, messages ...*message.Message) error {
+	return p.publish(topic, 0, messages...)
+}
+
+// PublishWithOpts persists the given message and then queues it for delivery to subscribers of the given
+// topic. If a delivery delay is specified then the message is not delivered until the delay has elapsed.
+func (p *PubSub) PublishWithOpts(topic string, msg *message.Message, opts ...spi.Option) error {
+	return p.publish(topic, getOptions(opts).DeliveryDelay, msg)
+}
+
+func (p *PubSub) publish(topic string, delay time.Duration, messages ...*message.Message) error {
+	if p.State() != lifecycle.StateStarted {
+		return lifecycle.ErrNotStarted
+	}
+
+	deliverAt := time.Now().Add(delay)
+
+	entries := make([]*entry, len(messages))
+	operations := make([]storage.Operation, len(messages))
+
+	for i, msg := range messages {
+		e := &entry{
+			key: uuid.New().String(),
+			messageRecord: &messageRecord{
+				Topic:     topic,
+				MessageID: msg.UUID,
+				Payload:   msg.Payload,
+				Metadata:  msg.Metadata,
+				DeliverAt: deliverAt,
+			},
+		}
+
+		value, err := json.Marshal(e.messageRecord)
+		if err != nil {
+			return fmt.Errorf("marshal message [%s]: %w", msg.UUID, err)
+		}
+
+		entries[i] = e
+		operations[i] = storage.Operation{
+			Key:   e.key,
+			Value: value,
+			Tags:  []storage.Tag{{Name: topicTag, Value: topic}},
+		}
+	}
+
+	if err := p.store.Batch(operations); err != nil {
+		return errors.NewTransientf("persist messages for topic [%s]: %w", topic, err)
+	}
+
+	p.mutex.Lock()
+	q := p.getQueue(topic)
+	p.mutex.Unlock()
+
+	for _, e := range entries {
+		logger.Debug("Published message", log.WithMessageID(e.MessageID), log.WithTopic(topic),
+			log.WithDeliveryDelay(delay))
+
+		q.add(e)
+	}
+
+	p.broadcast(topic, messages)
+
+	return nil
+}
+
+// broadcast sends a copy of the given messages to the broadcast subscribers of the topic. Broadcast messages
+// aren't persisted and are not redelivered.
+func (p *PubSub) broadcast(topic string, messages []*message.Message) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	select {
+	case <-p.done:
+		return
+	default:
+	}
+
+	for _, msgChan := range p.broadcastChans[topic] {
+		for _, msg := range messages {
+			p.wg.Add(1)
+
+			go p.deliverBroadcast(msgChan, msg.Copy())
+		}
+	}
+}
+
+func (p *PubSub) deliverBroadcast(msgChan chan<- *message.Message, msg *message.Message) {
+	defer p.wg.Done()
+
+	select {
+	case msgChan <- msg:
+	case <-p.done:
+		return
+	}
+
+	timer := time.NewTimer(p.AckTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-msg.Acked():
+	case <-msg.Nacked():
+		logger.Debug("Broadcast message was nacked and will not be redelivered", log.WithMessageID(msg.UUID))
+	case <-timer.C:
+		logger.Warn("Timed out waiting for Ack/Nack of broadcast message",
+			log.WithTimeout(p.AckTimeout), log.WithMessageID(msg.UUID))
+	case <-p.done:
+	}
+}
+
+func (p *PubSub) processMessages(ctx context.Context, q *queue, msgChan chan<- *message.Message) {
+	defer p.wg.Done()
+
+	for {
+		e, ok := q.next(ctx.Done(), p.done)
+		if !ok {
+			return
+		}
+
+		p.deliver(ctx, q, msgChan, e)
+	}
+}
+
+// deliver sends the message to the subscriber and waits for an Ack or a Nack. The message is deleted
+// from the store when it is acknowledged. If the subscriber is closed before the message is acknowledged
+// then the message remains in the store and is delivered again after a restart.
+func (p *PubSub) deliver(ctx context.Context, q *queue, msgChan chan<- *message.Message, e *entry) {
+	msg := e.message()
+
+	select {
+	case msgChan <- msg:
+	case <-ctx.Done():
+		q.add(e)
+
+		return
+	case <-p.done:
+		return
+	}
+
+	timer := time.NewTimer(p.AckTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-msg.Acked():
+		logger.Debug("Message was successfully acknowledged", log.WithMessageID(msg.UUID))
+
+		if err := p.store.Delete(e.key); err != nil {
+			logger.Warn("Error deleting acknowledged message from store. The message may be redelivered after a restart.",
+				log.WithMessageID(msg.UUID), log.WithError(err))
+		}
+
+	case <-msg.Nacked():
+		logger.Debug("Message was not successfully acknowledged", log.WithMessageID(msg.UUID))
+
+		p.redeliver(q, e)
+
+	case <-timer.C:
+		logger.Warn("Timed out waiting for Ack/Nack. The message will be redelivered.",
+			log.WithTimeout(p.AckTimeout), log.WithMessageID(msg.UUID))
+
+		p.redeliver(q, e)
+
+	case <-p.done:
+	}
+}
+
+// redeliver queues the message for redelivery. The first redelivery is immediate, after which the delay
+// increases with each attempt. When the maximum number of redelivery attempts has been reached, the message
+// is posted to the undeliverable topic (if the topic has subscribers) and then removed from the store. If the
+// message could not be posted to the undeliverable topic then the message remains in the store and is
+// delivered again after the maximum redelivery interval.
+func (p *PubSub) redeliver(q *queue, e *entry) {
+	if e.Attempts >= p.MaxRedeliveryAttempts {
+		logger.Error("Message will not be redelivered since the maximum delivery attempts has been reached",
+			log.WithMessageID(e.MessageID), log.WithTopic(e.Topic), log.WithDeliveryAttempts(e.Attempts+1))
+
+		if err := p.postToUndeliverable(e); err != nil {
+			logger.Warn("Error posting message to undeliverable topic. The message will be retried.",
+				log.WithMessageID(e.MessageID), log.WithError(err))
+
+			e.DeliverAt = time.Now().Add(p.MaxRedeliveryInterval)
+
+			q.add(e)
+
+			return
+		}
+
+		if err := p.store.Delete(e.key); err != nil {
+			logger.Warn("Error deleting undeliverable message from store",
+				log.WithMessageID(e.MessageID), log.WithError(err))
+		}
+
+		return
+	}
+
+	delay := p.getRedeliveryInterval(e.Attempts)
+
+	e.Attempts++
+	e.DeliverAt = time.Now().Add(delay)
+
+	value, err := json.Marshal(e.messageRecord)
+	if err != nil {
+		logger.Error("Error marshalling message for redelivery", log.WithMessageID(e.MessageID), log.WithError(err))
+	} else if err := p.store.Put(e.key, value, storage.Tag{Name: topicTag, Value: e.Topic}); err != nil {
+		// The message is still redelivered but, after a restart, the redelivery count may be reset.
+		logger.Warn("Error updating message in store", log.WithMessageID(e.MessageID), log.WithError(err))
+	}
+
+	logger.Info("Message queued for redelivery", log.WithMessageID(e.MessageID), log.WithTopic(e.Topic),
+		log.WithDeliveryDelay(delay), log.WithDeliveryAttempts(e.Attempts))
+
+	q.add(e)
+}
+
+// postToUndeliverable publishes the message to the undeliverable topic. Nil is returned if the message is
+// dropped, i.e. if it's from the undeliverable topic or if the undeliverable topic has no subscribers.
+func (p *PubSub) postToUndeliverable(e *entry) error {
+	if e.Topic == spi.UndeliverableTopic {
+		logger.Warn("Message from the undeliverable topic was not processed and will be dropped",
+			log.WithMessageID(e.MessageID))
+
+		return nil
+	}
+
+	p.mutex.RLock()
+	q, ok := p.queues[spi.UndeliverableTopic]
+	subscribed := ok && q.subscribed
+	p.mutex.RUnlock()
+
+	// Only post to the undeliverable topic if there is a subscriber, otherwise the messages would
+	// accumulate in the store.
+	if !subscribed {
+		logger.Warn("No subscribers for undeliverable topic. Message will be dropped.",
+			log.WithMessageID(e.MessageID))
+
+		return nil
+	}
+
+	msg := e.message()
+	msg.Metadata.Set(spi.MetadataOriginalTopic, e.Topic)
+	msg.Metadata.Set(spi.MetadataDeliveryAttempts, strconv.Itoa(e.Attempts+1))
+
+	if err := p.Publish(spi.UndeliverableTopic, msg); err != nil {
+		return fmt.Errorf("publish message to topic [%s]: %w", spi.UndeliverableTopic, err)
+	}
+
+	return nil
+}
+
+// load loads all persisted messages into the topic queues.
+func (p *PubSub) load() error {
+	it, err := p.store.Query(topicTag)
+	if err != nil {
+		return fmt.Errorf("query messages: %w", err)
+	}
+
+	defer func() {
+		if errClose := it.Close(); errClose != nil {
+			log.CloseIteratorError(logger, errClose)
+		}
+	}()
+
+	var total int
+
+	for {
+		ok, err := it.Next()
+		if err != nil {
+			return fmt.Errorf("iterator next: %w", err)
+		}
+
+		if !ok {
+			break
+		}
+
+		key, err := it.Key()
+		if err != nil {
+			return fmt.Errorf("iterator key: %w", err)
+		}
+
+		value, err := it.Value()
+		if err != nil {
+			return fmt.Errorf("iterator value: %w", err)
+		}
+
+		rec := &messageRecord{}
+
+		if err := json.Unmarshal(value, rec); err != nil {
+			return fmt.Errorf("unmarshal message [%s]: %w", key, err)
+		}
+
+		p.getQueue(rec.Topic).add(&entry{key: key, messageRecord: rec})
+
+		total++
+	}
+
+	if total > 0 {
+		logger.Info("Loaded persisted messages", log.WithTotal(total))
+	}
+
+	return nil
+}
+
+func (p *PubSub) stop() {
+	logger.Info("Stopping publisher/subscriber...")
+
+	p.mutex.Lock()
+	close(p.done)
+	msgChans := p.msgChans
+	p.mutex.Unlock()
+
+	logger.Debug("... waiting for subscribers to stop...")
+
+	p.wg.Wait()
+
+	for _, msgChan := range msgChans {
+		close(msgChan)
+	}
+
+	logger.Info("... publisher/subscriber stopped.")
+}
+
+// getQueue returns the queue for the given topic, creating it if necessary. The caller must hold the lock.
+func (p *PubSub) getQueue(topic string) *queue {
+	q, ok := p.queues[topic]
+	if !ok {
+		q = newQueue()
+		p.queues[topic] = q
+	}
+
+	return q
+}
+
+func (p *PubSub) getRedeliveryInterval(attempts int) time.Duration {
+	if attempts == 0 {
+		return 0
+	}
+
+	if attempts == 1 {
+		return p.RedeliveryInitialInterval
+	}
+
+	interval := time.Duration(float64(p.RedeliveryInitialInterval) * math.Pow(p.RedeliveryMultiplier, float64(attempts-1)))
+
+	if interval > p.MaxRedeliveryInterval {
+		interval = p.MaxRedeliveryInterval
+	}
+
+	return interval
+}
+
+func getOptions(opts []spi.Option) *spi.Options {
+	options := &spi.Options{}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return options
+}
@@ -0,0 +1,497 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package durablepubsub
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mock"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/stretchr/testify/require"
+
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/lifecycle"
+	"github.com/trustbloc/orb/pkg/pubsub/spi"
+)
+
+const topic = "orb.some-topic"
+
+func TestPubSub(t *testing.T) {
+	t.Run("Ack", func(t *testing.T) {
+		sp := mem.NewProvider()
+
+		p, err := New(sp, DefaultConfig())
+		require.NoError(t, err)
+		require.True(t, p.IsConnected())
+
+		msgChan, err := p.Subscribe(context.Background(), topic)
+		require.NoError(t, err)
+
+		msg := message.NewMessage(watermill.NewUUID(), []byte("payload"))
+		msg.Metadata.Set("some-property", "some value")
+
+		require.NoError(t, p.Publish(topic, msg))
+		require.Equal(t, 1, numPersisted(t, sp))
+
+		m := receive(t, msgChan)
+		require.Equal(t, msg.UUID, m.UUID)
+		require.Equal(t, msg.Payload, m.Payload)
+		require.Equal(t, "some value", m.Metadata.Get("some-property"))
+
+		m.Ack()
+
+		require.Eventually(t, func() bool { return numPersisted(t, sp) == 0 }, time.Second, 10*time.Millisecond)
+
+		require.NoError(t, p.Close())
+
+		_, ok := <-msgChan
+		require.False(t, ok)
+
+		_, err = p.Subscribe(context.Background(), topic)
+		require.True(t, errors.Is(err, lifecycle.ErrNotStarted))
+		require.True(t, errors.Is(p.Publish(topic, msg), lifecycle.ErrNotStarted))
+	})
+
+	t.Run("Messages survive restart", func(t *testing.T) {
+		sp := mem.NewProvider()
+
+		p, err := New(sp, DefaultConfig())
+		require.NoError(t, err)
+
+		msg1 := message.NewMessage(watermill.NewUUID(), []byte("payload1"))
+		msg2 := message.NewMessage(watermill.NewUUID(), []byte("payload2"))
+
+		// Publish before anyone has subscribed.
+		require.NoError(t, p.Publish(topic, msg1))
+
+		msgChan, err := p.Subscribe(context.Background(), topic)
+		require.NoError(t, err)
+
+		// Receive the first message but don't acknowledge it before shutdown.
+		m := receive(t, msgChan)
+		require.Equal(t, msg1.UUID, m.UUID)
+
+		require.NoError(t, p.Publish(topic, msg2))
+		require.NoError(t, p.Close())
+
+		p, err = New(sp, DefaultConfig())
+		require.NoError(t, err)
+
+		defer func() { require.NoError(t, p.Close()) }()
+
+		msgChan, err = p.Subscribe(context.Background(), topic)
+		require.NoError(t, err)
+
+		received := make(map[string]bool)
+
+		for i := 0; i < 2; i++ {
+			m := receive(t, msgChan)
+			received[m.UUID] = true
+
+			m.Ack()
+		}
+
+		require.True(t, received[msg1.UUID])
+		require.True(t, received[msg2.UUID])
+
+		require.Eventually(t, func() bool { return numPersisted(t, sp) == 0 }, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Delivery delay", func(t *testing.T) {
+		const delay = 200 * time.Millisecond
+
+		p, err := New(mem.NewProvider(), DefaultConfig())
+		require.NoError(t, err)
+
+		defer func() { require.NoError(t, p.Close()) }()
+
+		msgChan, err := p.Subscribe(context.Background(), topic)
+		require.NoError(t, err)
+
+		delayedMsg := message.NewMessage(watermill.NewUUID(), []byte("delayed"))
+		msg := message.NewMessage(watermill.NewUUID(), []byte("not delayed"))
+
+		start := time.Now()
+
+		require.NoError(t, p.PublishWithOpts(topic, delayedMsg, spi.WithDeliveryDelay(delay)))
+		require.NoError(t, p.PublishWithOpts(topic, msg))
+
+		// The message without a delay should be delivered first.
+		m := receive(t, msgChan)
+		require.Equal(t, msg.UUID, m.UUID)
+		m.Ack()
+
+		m = receive(t, msgChan)
+		require.Equal(t, delayedMsg.UUID, m.UUID)
+		require.GreaterOrEqual(t, time.Since(start), delay)
+		m.Ack()
+	})
+
+	t.Run("Subscriber pool", func(t *testing.T) {
+		const poolSize = 3
+
+		p, err := New(mem.NewProvider(), DefaultConfig())
+		require.NoError(t, err)
+
+		defer func() { require.NoError(t, p.Close()) }()
+
+		msgChan, err := p.SubscribeWithOpts(context.Background(), topic, spi.WithPool(poolSize))
+		require.NoError(t, err)
+
+		for i := 0; i < poolSize; i++ {
+			require.NoError(t, p.Publish(topic, message.NewMessage(watermill.NewUUID(), []byte("payload"))))
+		}
+
+		// All messages should be delivered concurrently, i.e. without acknowledging the previous message.
+		var msgs []*message.Message
+
+		for i := 0; i < poolSize; i++ {
+			msgs = append(msgs, receive(t, msgChan))
+		}
+
+		for _, m := range msgs {
+			m.Ack()
+		}
+	})
+
+	t.Run("Broadcast", func(t *testing.T) {
+		sp := mem.NewProvider()
+
+		p, err := New(sp, DefaultConfig())
+		require.NoError(t, err)
+
+		defer func() { require.NoError(t, p.Close()) }()
+
+		msgChan, err := p.Subscribe(context.Background(), topic)
+		require.NoError(t, err)
+
+		broadcastChan1, err := p.SubscribeWithOpts(context.Background(), topic, spi.WithBroadcast())
+		require.NoError(t, err)
+
+		broadcastChan2, err := p.SubscribeWithOpts(context.Background(), topic, spi.WithBroadcast())
+		require.NoError(t, err)
+
+		msg := message.NewMessage(watermill.NewUUID(), []byte("payload"))
+
+		require.NoError(t, p.Publish(topic, msg))
+
+		m := receive(t, broadcastChan1)
+		require.Equal(t, msg.UUID, m.UUID)
+		m.Nack()
+
+		m = receive(t, broadcastChan2)
+		require.Equal(t, msg.UUID, m.UUID)
+		m.Ack()
+
+		// Only the competing subscriber's message is persisted.
+		require.Equal(t, 1, numPersisted(t, sp))
+
+		m = receive(t, msgChan)
+		require.Equal(t, msg.UUID, m.UUID)
+		m.Ack()
+
+		require.Eventually(t, func() bool { return numPersisted(t, sp) == 0 }, time.Second, 10*time.Millisecond)
+
+		// A nacked broadcast message isn't redelivered.
+		select {
+		case <-broadcastChan1:
+			t.Fatal("broadcast message should not be redelivered")
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+
+	t.Run("Nack -> redelivery and undeliverable", func(t *testing.T) {
+		const maxAttempts = 2
+
+		sp := mem.NewProvider()
+
+		cfg := DefaultConfig()
+		cfg.MaxRedeliveryAttempts = maxAttempts
+		cfg.RedeliveryInitialInterval = 50 * time.Millisecond
+
+		p, err := New(sp, cfg)
+		require.NoError(t, err)
+
+		defer func() { require.NoError(t, p.Close()) }()
+
+		msgChan, err := p.Subscribe(context.Background(), topic)
+		require.NoError(t, err)
+
+		undeliverableChan, err := p.Subscribe(context.Background(), spi.UndeliverableTopic)
+		require.NoError(t, err)
+
+		msg := message.NewMessage(watermill.NewUUID(), []byte("payload"))
+
+		require.NoError(t, p.Publish(topic, msg))
+
+		for i := 0; i <= maxAttempts; i++ {
+			m := receive(t, msgChan)
+			require.Equal(t, msg.UUID, m.UUID)
+
+			m.Nack()
+		}
+
+		m := receive(t, undeliverableChan)
+		require.Equal(t, msg.UUID, m.UUID)
+		require.Equal(t, topic, m.Metadata.Get(spi.MetadataOriginalTopic))
+		require.Equal(t, "3", m.Metadata.Get(spi.MetadataDeliveryAttempts))
+
+		// A message from the undeliverable topic is dropped after the maximum number of attempts.
+		for i := 0; i <= maxAttempts; i++ {
+			m.Nack()
+
+			if i < maxAttempts {
+				m = receive(t, undeliverableChan)
+			}
+		}
+
+		require.Eventually(t, func() bool { return numPersisted(t, sp) == 0 }, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Nack -> no undeliverable subscriber", func(t *testing.T) {
+		sp := mem.NewProvider()
+
+		cfg := DefaultConfig()
+		cfg.MaxRedeliveryAttempts = 0
+
+		p, err := New(sp, cfg)
+		require.NoError(t, err)
+
+		defer func() { require.NoError(t, p.Close()) }()
+
+		msgChan, err := p.Subscribe(context.Background(), topic)
+		require.NoError(t, err)
+
+		require.NoError(t, p.Publish(topic, message.NewMessage(watermill.NewUUID(), []byte("payload"))))
+
+		receive(t, msgChan).Nack()
+
+		require.Eventually(t, func() bool { return numPersisted(t, sp) == 0 }, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Nack -> undeliverable publish error", func(t *testing.T) {
+		sp := &failingProvider{Provider: mem.NewProvider()}
+
+		cfg := DefaultConfig()
+		cfg.MaxRedeliveryAttempts = 0
+		cfg.MaxRedeliveryInterval = 50 * time.Millisecond
+
+		p, err := New(sp, cfg)
+		require.NoError(t, err)
+
+		defer func() { require.NoError(t, p.Close()) }()
+
+		msgChan, err := p.Subscribe(context.Background(), topic)
+		require.NoError(t, err)
+
+		undeliverableChan, err := p.Subscribe(context.Background(), spi.UndeliverableTopic)
+		require.NoError(t, err)
+
+		msg := message.NewMessage(watermill.NewUUID(), []byte("payload"))
+
+		require.NoError(t, p.Publish(topic, msg))
+
+		sp.setBatchError(errors.New("injected batch error"))
+
+		receive(t, msgChan).Nack()
+
+		// The message couldn't be posted to the undeliverable topic so it's kept and delivered again.
+		m := receive(t, msgChan)
+		require.Equal(t, msg.UUID, m.UUID)
+		require.Equal(t, 1, numPersisted(t, sp.Provider))
+
+		sp.setBatchError(nil)
+
+		m.Nack()
+
+		m = receive(t, undeliverableChan)
+		require.Equal(t, msg.UUID, m.UUID)
+
+		m.Ack()
+
+		require.Eventually(t, func() bool { return numPersisted(t, sp.Provider) == 0 }, time.Second,
+			10*time.Millisecond)
+	})
+
+	t.Run("Ack timeout -> redelivery", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.AckTimeout = 50 * time.Millisecond
+
+		p, err := New(mem.NewProvider(), cfg)
+		require.NoError(t, err)
+
+		defer func() { require.NoError(t, p.Close()) }()
+
+		msgChan, err := p.Subscribe(context.Background(), topic)
+		require.NoError(t, err)
+
+		msg := message.NewMessage(watermill.NewUUID(), []byte("payload"))
+
+		require.NoError(t, p.Publish(topic, msg))
+
+		require.Equal(t, msg.UUID, receive(t, msgChan).UUID)
+
+		// Don't acknowledge. The message should be redelivered immediately after the timeout.
+		m := receive(t, msgChan)
+		require.Equal(t, msg.UUID, m.UUID)
+
+		m.Ack()
+	})
+
+	t.Run("Subscriber context cancelled", func(t *testing.T) {
+		sp := mem.NewProvider()
+
+		p, err := New(sp, DefaultConfig())
+		require.NoError(t, err)
+
+		defer func() { require.NoError(t, p.Close()) }()
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		_, err = p.Subscribe(ctx, topic)
+		require.NoError(t, err)
+
+		cancel()
+
+		msgChan, err := p.Subscribe(context.Background(), topic)
+		require.NoError(t, err)
+
+		msg := message.NewMessage(watermill.NewUUID(), []byte("payload"))
+
+		require.NoError(t, p.Publish(topic, msg))
+
+		m := receive(t, msgChan)
+		require.Equal(t, msg.UUID, m.UUID)
+
+		m.Ack()
+	})
+
+	t.Run("Open store error", func(t *testing.T) {
+		errExpected := errors.New("injected open store error")
+
+		_, err := New(&mock.Provider{ErrOpenStore: errExpected}, DefaultConfig())
+		require.True(t, errors.Is(err, errExpected))
+	})
+
+	t.Run("Query error", func(t *testing.T) {
+		errExpected := errors.New("injected query error")
+
+		_, err := New(&mock.Provider{OpenStoreReturn: &mock.Store{ErrQuery: errExpected}}, DefaultConfig())
+		require.True(t, errors.Is(err, errExpected))
+	})
+
+	t.Run("Persist error", func(t *testing.T) {
+		errExpected := errors.New("injected batch error")
+
+		p, err := New(&mock.Provider{OpenStoreReturn: &mock.Store{
+			QueryReturn: &mock.Iterator{},
+			ErrBatch:    errExpected,
+		}}, DefaultConfig())
+		require.NoError(t, err)
+
+		defer func() { require.NoError(t, p.Close()) }()
+
+		err = p.Publish(topic, message.NewMessage(watermill.NewUUID(), []byte("payload")))
+		require.True(t, errors.Is(err, errExpected))
+		require.True(t, orberrors.IsTransient(err))
+	})
+}
+
+func TestGetRedeliveryInterval(t *testing.T) {
+	p := &PubSub{Config: Config{
+		RedeliveryInitialInterval: time.Second,
+		RedeliveryMultiplier:      2,
+		MaxRedeliveryInterval:     5 * time.Second,
+	}}
+
+	require.Equal(t, time.Duration(0), p.getRedeliveryInterval(0))
+	require.Equal(t, time.Second, p.getRedeliveryInterval(1))
+	require.Equal(t, 2*time.Second, p.getRedeliveryInterval(2))
+	require.Equal(t, 4*time.Second, p.getRedeliveryInterval(3))
+	require.Equal(t, 5*time.Second, p.getRedeliveryInterval(4))
+}
+
+func receive(t *testing.T, msgChan <-chan *message.Message) *message.Message {
+	t.Helper()
+
+	select {
+	case m := <-msgChan:
+		return m
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for message")
+
+		return nil
+	}
+}
+
+func numPersisted(t *testing.T, p storage.Provider) int {
+	t.Helper()
+
+	s, err := p.OpenStore(storeName)
+	require.NoError(t, err)
+
+	it, err := s.Query(topicTag)
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, it.Close())
+	}()
+
+	n, err := it.TotalItems()
+	require.NoError(t, err)
+
+	return n
+}
+
+// failingProvider opens stores whose Batch operation fails while an error is set.
+type failingProvider struct {
+	storage.Provider
+
+	mutex    sync.RWMutex
+	batchErr error
+}
+
+func (p *failingProvider) OpenStore(name string) (storage.Store, error) {
+	s, err := p.Provider.OpenStore(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &failingStore{Store: s, p: p}, nil
+}
+
+func (p *failingProvider) setBatchError(err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.batchErr = err
+}
+
+type failingStore struct {
+	storage.Store
+
+	p *failingProvider
+}
+
+func (s *failingStore) Batch(operations []storage.Operation) error {
+	s.p.mutex.RLock()
+	err := s.p.batchErr
+	s.p.mutex.RUnlock()
+
+	if err != nil {
+		return err
+	}
+
+	return s.Store.Batch(operations)
+}
@@ -0,0 +1,130 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package durablepubsub
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// queue holds the pending messages of a topic, ordered by delivery time.
+type queue struct {
+	mutex      sync.Mutex
+	entries    []*entry
+	notify     chan struct{}
+	subscribed bool
+}
+
+func newQueue() *queue {
+	return &queue{
+		notify: make(chan struct{}, 1),
+	}
+}
+
+// add inserts the entry into the queue according to its delivery time and wakes up a waiting subscriber.
+func (q *queue) add(e *entry) {
+	q.mutex.Lock()
+
+	i := sort.Search(len(q.entries), func(i int) bool {
+		return q.entries[i].DeliverAt.After(e.DeliverAt)
+	})
+
+	q.entries = append(q.entries, nil)
+	copy(q.entries[i+1:], q.entries[i:])
+	q.entries[i] = e
+
+	q.mutex.Unlock()
+
+	q.signal()
+}
+
+// next blocks until an entry is due for delivery and removes it from the queue. False is returned
+// if either of the given channels is closed.
+func (q *queue) next(ctxDone, done <-chan struct{}) (*entry, bool) {
+	for {
+		if isClosed(ctxDone) || isClosed(done) {
+			return nil, false
+		}
+
+		e, wait := q.poll()
+		if e != nil {
+			return e, true
+		}
+
+		var timerChan <-chan time.Time
+
+		var timer *time.Timer
+
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			timerChan = timer.C
+		}
+
+		select {
+		case <-q.notify:
+		case <-timerChan:
+		case <-ctxDone:
+			stopTimer(timer)
+
+			return nil, false
+		case <-done:
+			stopTimer(timer)
+
+			return nil, false
+		}
+
+		stopTimer(timer)
+	}
+}
+
+// poll removes and returns the first entry if it's due for delivery. Otherwise the time until the first
+// entry is due is returned (or 0 if the queue is empty).
+func (q *queue) poll() (*entry, time.Duration) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if len(q.entries) == 0 {
+		return nil, 0
+	}
+
+	if wait := time.Until(q.entries[0].DeliverAt); wait > 0 {
+		return nil, wait
+	}
+
+	e := q.entries[0]
+	q.entries = q.entries[1:]
+
+	if len(q.entries) > 0 {
+		// Wake up another subscriber, if any, to process the remaining entries.
+		q.signal()
+	}
+
+	return e, 0
+}
+
+func (q *queue) signal() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+func isClosed(c <-chan struct{}) bool {
+	select {
+	case <-c:
+		return true
+	default:
+		return false
+	}
+}
+
+func stopTimer(timer *time.Timer) {
+	if timer != nil {
+		timer.Stop()
+	}
+}
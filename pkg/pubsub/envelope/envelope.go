@@ -0,0 +1,146 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package envelope
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+)
+
+const (
+	// MetadataSchema is the metadata property which holds the name of the schema of the message payload.
+	MetadataSchema = "orb-schema"
+
+	// MetadataSchemaVersion is the metadata property which holds the schema version of the message payload.
+	MetadataSchemaVersion = "orb-schema-version"
+
+	// LegacyVersion is the version assumed for messages which don't specify a schema version, i.e. messages
+	// which were published by releases that predate schema versioning.
+	LegacyVersion = 1
+)
+
+// ErrUnsupportedVersion indicates that the message was published with a newer schema version than the one
+// supported by this server (which may happen during a rolling upgrade).
+var ErrUnsupportedVersion = errors.New("unsupported schema version")
+
+// Upcaster converts a payload of a given schema version to the next version.
+type Upcaster func(payload []byte) ([]byte, error)
+
+// Option is a schema option.
+type Option func(s *Schema)
+
+// WithUpcaster registers an upcaster which converts a payload from the given version to the next version.
+func WithUpcaster(fromVersion int, upcaster Upcaster) Option {
+	return func(s *Schema) {
+		s.upcasters[fromVersion] = upcaster
+	}
+}
+
+// Schema defines the current version of a message payload along with the upcasters that convert payloads
+// of previous versions to the current version.
+//
+// The schema name and version are carried in the message metadata (i.e. the message envelope) rather than in
+// the payload so that the payload of the current version remains readable by servers running a release which
+// predates schema versioning.
+type Schema struct {
+	name      string
+	version   int
+	upcasters map[int]Upcaster
+}
+
+// New returns a new schema with the given name and current version. An upcaster must be provided for each
+// version from LegacyVersion up to (but not including) the current version, otherwise this function panics.
+func New(name string, version int, opts ...Option) *Schema {
+	s := &Schema{
+		name:      name,
+		version:   version,
+		upcasters: make(map[int]Upcaster),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	for v := LegacyVersion; v < version; v++ {
+		if _, ok := s.upcasters[v]; !ok {
+			panic(fmt.Sprintf("schema [%s]: no upcaster for version %d", name, v))
+		}
+	}
+
+	return s
+}
+
+// Name returns the name of the schema.
+func (s *Schema) Name() string {
+	return s.name
+}
+
+// Version returns the current version of the schema.
+func (s *Schema) Version() int {
+	return s.version
+}
+
+// NewMessage returns a new message with the given payload. The message metadata is populated with the
+// schema name and current version.
+func (s *Schema) NewMessage(payload []byte) *message.Message {
+	msg := message.NewMessage(watermill.NewUUID(), payload)
+	msg.Metadata.Set(MetadataSchema, s.name)
+	msg.Metadata.Set(MetadataSchemaVersion, strconv.Itoa(s.version))
+
+	return msg
+}
+
+// Payload returns the payload of the given message, upcast to the current version. If the message was
+// published with a newer version than the current version then a transient error (which wraps
+// ErrUnsupportedVersion) is returned so that the message may be redelivered to a server that supports
+// the version.
+func (s *Schema) Payload(msg *message.Message) ([]byte, error) {
+	if name := msg.Metadata.Get(MetadataSchema); name != "" && name != s.name {
+		return nil, fmt.Errorf("unexpected schema [%s] - expecting [%s]", name, s.name)
+	}
+
+	version, err := getVersion(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	if version > s.version {
+		return nil, orberrors.NewTransient(fmt.Errorf("schema [%s] version %d: %w - current version is %d",
+			s.name, version, ErrUnsupportedVersion, s.version))
+	}
+
+	payload := []byte(msg.Payload)
+
+	for v := version; v < s.version; v++ {
+		payload, err = s.upcasters[v](payload)
+		if err != nil {
+			return nil, fmt.Errorf("upcast schema [%s] from version %d: %w", s.name, v, err)
+		}
+	}
+
+	return payload, nil
+}
+
+func getVersion(msg *message.Message) (int, error) {
+	value := msg.Metadata.Get(MetadataSchemaVersion)
+	if value == "" {
+		return LegacyVersion, nil
+	}
+
+	version, err := strconv.Atoi(value)
+	if err != nil || version < LegacyVersion {
+		return 0, fmt.Errorf("invalid schema version [%s]", value)
+	}
+
+	return version, nil
+}
@@ -0,0 +1,131 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package envelope
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/stretchr/testify/require"
+
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+)
+
+const schemaName = "test-schema"
+
+type payloadV1 struct {
+	Name string `json:"name"`
+}
+
+type payloadV2 struct {
+	Names []string `json:"names"`
+}
+
+type payloadV3 struct {
+	Names []string `json:"names"`
+	Count int      `json:"count"`
+}
+
+func TestSchema(t *testing.T) {
+	schema := New(schemaName, 3,
+		WithUpcaster(1, func(payload []byte) ([]byte, error) {
+			p := &payloadV1{}
+			if err := json.Unmarshal(payload, p); err != nil {
+				return nil, err
+			}
+
+			return json.Marshal(&payloadV2{Names: []string{p.Name}})
+		}),
+		WithUpcaster(2, func(payload []byte) ([]byte, error) {
+			p := &payloadV2{}
+			if err := json.Unmarshal(payload, p); err != nil {
+				return nil, err
+			}
+
+			return json.Marshal(&payloadV3{Names: p.Names, Count: len(p.Names)})
+		}),
+	)
+
+	require.Equal(t, schemaName, schema.Name())
+	require.Equal(t, 3, schema.Version())
+
+	t.Run("Current version", func(t *testing.T) {
+		msg := schema.NewMessage([]byte(`{"names":["a","b"],"count":2}`))
+		require.NotEmpty(t, msg.UUID)
+		require.Equal(t, schemaName, msg.Metadata.Get(MetadataSchema))
+		require.Equal(t, "3", msg.Metadata.Get(MetadataSchemaVersion))
+
+		payload, err := schema.Payload(msg)
+		require.NoError(t, err)
+		require.Equal(t, []byte(msg.Payload), payload)
+	})
+
+	t.Run("Legacy (unversioned) message -> upcast", func(t *testing.T) {
+		payload, err := schema.Payload(message.NewMessage(watermill.NewUUID(), []byte(`{"name":"a"}`)))
+		require.NoError(t, err)
+
+		p := &payloadV3{}
+		require.NoError(t, json.Unmarshal(payload, p))
+		require.Equal(t, []string{"a"}, p.Names)
+		require.Equal(t, 1, p.Count)
+	})
+
+	t.Run("Version 2 -> upcast", func(t *testing.T) {
+		msg := message.NewMessage(watermill.NewUUID(), []byte(`{"names":["a","b"]}`))
+		msg.Metadata.Set(MetadataSchema, schemaName)
+		msg.Metadata.Set(MetadataSchemaVersion, "2")
+
+		payload, err := schema.Payload(msg)
+		require.NoError(t, err)
+
+		p := &payloadV3{}
+		require.NoError(t, json.Unmarshal(payload, p))
+		require.Equal(t, 2, p.Count)
+	})
+
+	t.Run("Newer version -> transient error", func(t *testing.T) {
+		msg := message.NewMessage(watermill.NewUUID(), []byte(`{}`))
+		msg.Metadata.Set(MetadataSchemaVersion, "4")
+
+		_, err := schema.Payload(msg)
+		require.True(t, errors.Is(err, ErrUnsupportedVersion))
+		require.True(t, orberrors.IsTransient(err))
+	})
+
+	t.Run("Invalid version", func(t *testing.T) {
+		for _, v := range []string{"xxx", "0"} {
+			msg := message.NewMessage(watermill.NewUUID(), []byte(`{}`))
+			msg.Metadata.Set(MetadataSchemaVersion, v)
+
+			_, err := schema.Payload(msg)
+			require.EqualError(t, err, "invalid schema version ["+v+"]")
+			require.False(t, orberrors.IsTransient(err))
+		}
+	})
+
+	t.Run("Unexpected schema", func(t *testing.T) {
+		msg := New("other-schema", 1).NewMessage([]byte(`{}`))
+
+		_, err := schema.Payload(msg)
+		require.EqualError(t, err, "unexpected schema [other-schema] - expecting [test-schema]")
+	})
+
+	t.Run("Upcast error", func(t *testing.T) {
+		_, err := schema.Payload(message.NewMessage(watermill.NewUUID(), []byte(`{`)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "upcast schema [test-schema] from version 1")
+	})
+
+	t.Run("Missing upcaster -> panic", func(t *testing.T) {
+		require.Panics(t, func() {
+			New(schemaName, 2)
+		})
+	})
+}
@@ -58,7 +58,7 @@ type PubSub struct {
 	msgChansByTopic map[string][]chan *message.Message
 	mutex           sync.RWMutex
 	publishChan     chan *entry
-	ackChan         chan *message.Message
+	ackChan         chan *pendingAck
 	doneChan        chan struct{}
 }
 
@@ -67,13 +67,18 @@ type entry struct {
 	messages []*message.Message
 }
 
+type pendingAck struct {
+	topic string
+	msg   *message.Message
+}
+
 // New returns a new publisher/subscriber.
 func New(cfg Config) *PubSub {
 	m := &PubSub{
 		Config:          cfg,
 		msgChansByTopic: make(map[string][]chan *message.Message),
 		publishChan:     make(chan *entry, cfg.BufferSize),
-		ackChan:         make(chan *message.Message, cfg.Concurrency),
+		ackChan:         make(chan *pendingAck, cfg.Concurrency),
 		doneChan:        make(chan struct{}),
 	}
 
@@ -135,6 +140,7 @@ func (p *PubSub) Subscribe(ctx context.Context, topic string) (<-chan *message.M
 
 // SubscribeWithOpts subscribes to a topic and returns the Go channel over which messages
 // are sent. The returned channel will be closed when Close() is called on this struct.
+// Options are ignored since every subscriber of a topic receives all messages published to the topic.
 func (p *PubSub) SubscribeWithOpts(_ context.Context, topic string, _ ...spi.Option) (<-chan *message.Message, error) {
 	if p.State() != lifecycle.StateStarted {
 		return nil, lifecycle.ErrNotStarted
@@ -190,8 +196,8 @@ func (p *PubSub) processMessages() {
 }
 
 func (p *PubSub) processAcks() {
-	for msg := range p.ackChan {
-		go p.check(msg)
+	for a := range p.ackChan {
+		go p.check(a.topic, a.msg)
 	}
 }
 
@@ -214,12 +220,12 @@ func (p *PubSub) publish(entry *entry) {
 			logger.Debug("Publishing message", log.WithMessageID(msg.UUID))
 
 			msgChan <- msg
-			p.ackChan <- msg
+			p.ackChan <- &pendingAck{topic: entry.topic, msg: msg}
 		}
 	}
 }
 
-func (p *PubSub) check(msg *message.Message) {
+func (p *PubSub) check(topic string, msg *message.Message) {
 	logger.Debug("Checking for Ack/Nack on message", log.WithMessageID(msg.UUID))
 
 	select {
@@ -230,17 +236,29 @@ func (p *PubSub) check(msg *message.Message) {
 		logger.Info("Message was not successfully acknowledged. Posting to undeliverable queue",
 			log.WithMessageID(msg.UUID))
 
-		p.postToUndeliverable(msg)
+		p.postToUndeliverable(topic, msg)
 
 	case <-time.After(p.Timeout):
 		logger.Warn("Timed out waiting for Ack/Nack. Posting to undeliverable queue",
 			log.WithTimeout(p.Timeout), log.WithMessageID(msg.UUID))
 
-		p.postToUndeliverable(msg)
+		p.postToUndeliverable(topic, msg)
 	}
 }
 
-func (p *PubSub) postToUndeliverable(msg *message.Message) {
+func (p *PubSub) postToUndeliverable(topic string, msg *message.Message) {
+	if topic == spi.UndeliverableTopic {
+		logger.Warn("Message from the undeliverable queue was not processed and will be dropped",
+			log.WithMessageID(msg.UUID))
+
+		return
+	}
+
+	// Post a copy of the message since the original message has already been nacked.
+	undeliverableMsg := msg.Copy()
+	undeliverableMsg.Metadata.Set(spi.MetadataOriginalTopic, topic)
+	undeliverableMsg.Metadata.Set(spi.MetadataDeliveryAttempts, "1")
+
 	p.mutex.RLock()
 	msgChans := p.msgChansByTopic[spi.UndeliverableTopic]
 	p.mutex.RUnlock()
@@ -250,7 +268,7 @@ func (p *PubSub) postToUndeliverable(msg *message.Message) {
 
 	for _, msgChan := range msgChans {
 		select {
-		case msgChan <- msg:
+		case msgChan <- undeliverableMsg:
 			logger.Info("Message was added to the undeliverable queue", log.WithMessageID(msg.UUID))
 
 		default:
@@ -108,6 +108,8 @@ func TestPubSub_Publish(t *testing.T) {
 
 		require.True(t, ok)
 		require.Equal(t, msg.UUID, m.UUID)
+		require.Equal(t, "topic1", m.Metadata.Get(spi.MetadataOriginalTopic))
+		require.Equal(t, "1", m.Metadata.Get(spi.MetadataDeliveryAttempts))
 	})
 
 	t.Run("Nack - no consumer of undeliverable channel", func(t *testing.T) {
@@ -0,0 +1,144 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package nats
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+)
+
+const (
+	clientName = "orb"
+
+	// streamNotFoundError is the description of the error returned by the server when a stream doesn't exist.
+	streamNotFoundError = "stream not found"
+
+	// reconnectForever instructs the client to keep trying to reconnect to the server.
+	reconnectForever = -1
+)
+
+func connect(url string) (connection, error) {
+	nc, err := nats.Connect(url,
+		nats.Name(clientName),
+		nats.MaxReconnects(reconnectForever),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				logger.Warn("Disconnected from NATS server", log.WithError(err))
+			}
+		}),
+		nats.ReconnectHandler(func(nc *nats.Conn) {
+			logger.Info("Reconnected to NATS server", log.WithAddress(nc.ConnectedUrl()))
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &natsConnection{nc: nc}, nil
+}
+
+type natsConnection struct {
+	nc *nats.Conn
+}
+
+func (c *natsConnection) jetStream() (jetStream, error) {
+	js, err := c.nc.JetStream()
+	if err != nil {
+		return nil, err
+	}
+
+	return &jetStreamContext{js: js, nc: c.nc}, nil
+}
+
+func (c *natsConnection) isConnected() bool {
+	return c.nc.IsConnected()
+}
+
+func (c *natsConnection) close() {
+	c.nc.Close()
+}
+
+type jetStreamContext struct {
+	js nats.JetStreamContext
+	nc *nats.Conn
+}
+
+// ensureStream creates the stream with the given name if it doesn't already exist. The stream uses the work queue
+// retention policy so that a message is removed from the stream once it has been acknowledged. (Each topic is
+// consumed by a single durable consumer.)
+func (c *jetStreamContext) ensureStream(name, subjects string) error {
+	info, err := c.js.StreamInfo(name)
+	if err == nil {
+		if info.Config.Retention != nats.WorkQueuePolicy {
+			logger.Warn("The existing JetStream stream does not use the work queue retention policy so "+
+				"acknowledged messages are not removed from the stream. The stream must be recreated in order "+
+				"to change the retention policy.", log.WithTopic(subjects))
+		}
+
+		return nil
+	}
+
+	if !strings.Contains(err.Error(), streamNotFoundError) {
+		return fmt.Errorf("get stream info: %w", err)
+	}
+
+	logger.Info("Creating JetStream stream for topics", log.WithTopic(subjects))
+
+	_, err = c.js.AddStream(&nats.StreamConfig{
+		Name:      name,
+		Subjects:  []string{subjects},
+		Storage:   nats.FileStorage,
+		Retention: nats.WorkQueuePolicy,
+	})
+	if err != nil {
+		return fmt.Errorf("add stream: %w", err)
+	}
+
+	return nil
+}
+
+func (c *jetStreamContext) publish(msg *nats.Msg) error {
+	_, err := c.js.PublishMsg(msg)
+
+	return err
+}
+
+// subscribe creates a queue subscription to the given subject. The durable name is also used as the queue group
+// so that all subscribers of the subject (across all server instances) share the same durable consumer.
+func (c *jetStreamContext) subscribe(stream, subject, durable string, handler msgHandler) (subscription, error) {
+	sub, err := c.js.QueueSubscribe(subject, durable,
+		func(msg *nats.Msg) {
+			handler(msg, msg)
+		},
+		nats.BindStream(stream),
+		nats.Durable(durable),
+		nats.ManualAck(),
+		nats.AckExplicit(),
+		nats.DeliverAll(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return sub, nil
+}
+
+// broadcastSubscribe creates a core NATS subscription to the given subject. Since the subscription isn't a
+// JetStream consumer, every subscriber (across all server instances) receives the messages published to
+// the subject, although only while it's connected.
+func (c *jetStreamContext) broadcastSubscribe(subject string, handler func(msg *nats.Msg)) (subscription, error) {
+	sub, err := c.nc.Subscribe(subject, handler)
+	if err != nil {
+		return nil, err
+	}
+
+	return sub, nil
+}
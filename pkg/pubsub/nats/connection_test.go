@@ -0,0 +1,308 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package nats
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/nats-io/nats-server/v2/server"
+	natsserver "github.com/nats-io/nats-server/v2/test"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/pubsub/spi"
+)
+
+func TestJetStream(t *testing.T) {
+	t.Run("Publish, subscribe and ack", func(t *testing.T) {
+		srv := runServer(t)
+
+		p := New(Config{URL: srv.ClientURL()})
+		defer func() { require.NoError(t, p.Close()) }()
+
+		require.True(t, p.IsConnected())
+
+		js := newJetStreamContext(t, srv)
+
+		info, err := js.StreamInfo(defaultStreamName)
+		require.NoError(t, err)
+		require.Equal(t, nats.WorkQueuePolicy, info.Config.Retention)
+		require.Equal(t, []string{streamSubjects}, info.Config.Subjects)
+
+		msgChan, err := p.Subscribe(context.Background(), topic)
+		require.NoError(t, err)
+
+		msg := message.NewMessage(watermill.NewUUID(), []byte("some payload"))
+		msg.Metadata.Set("some-property", "some value")
+
+		require.NoError(t, p.Publish(topic, msg))
+
+		m := receive(t, msgChan)
+		require.Equal(t, msg.UUID, m.UUID)
+		require.Equal(t, msg.Payload, m.Payload)
+		require.Equal(t, "some value", m.Metadata.Get("some-property"))
+
+		m.Ack()
+
+		// The acknowledged message is removed from the work queue stream.
+		require.Eventually(t, func() bool {
+			return numStreamMessages(t, js, defaultStreamName) == 0
+		}, 5*time.Second, 50*time.Millisecond)
+	})
+
+	t.Run("Queue group across instances", func(t *testing.T) {
+		const numMsgs = 20
+
+		srv := runServer(t)
+
+		p1 := New(Config{URL: srv.ClientURL()})
+		defer func() { require.NoError(t, p1.Close()) }()
+
+		p2 := New(Config{URL: srv.ClientURL()})
+		defer func() { require.NoError(t, p2.Close()) }()
+
+		msgChan1, err := p1.SubscribeWithOpts(context.Background(), topic, spi.WithPool(2))
+		require.NoError(t, err)
+
+		msgChan2, err := p2.SubscribeWithOpts(context.Background(), topic, spi.WithPool(2))
+		require.NoError(t, err)
+
+		for i := 0; i < numMsgs; i++ {
+			require.NoError(t, p1.Publish(topic, message.NewMessage(watermill.NewUUID(), []byte(fmt.Sprintf("%d", i)))))
+		}
+
+		var (
+			mutex    sync.Mutex
+			received = make(map[string]int)
+		)
+
+		consume := func(msgChan <-chan *message.Message) {
+			for m := range msgChan {
+				mutex.Lock()
+				received[m.UUID]++
+				mutex.Unlock()
+
+				m.Ack()
+			}
+		}
+
+		go consume(msgChan1)
+		go consume(msgChan2)
+
+		require.Eventually(t, func() bool {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			return len(received) == numMsgs
+		}, 5*time.Second, 50*time.Millisecond)
+
+		// Wait to ensure that no message is delivered more than once.
+		time.Sleep(500 * time.Millisecond)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		require.Len(t, received, numMsgs)
+
+		for id, n := range received {
+			require.Equalf(t, 1, n, "message %s was delivered %d times", id, n)
+		}
+	})
+
+	t.Run("In progress -> message not redelivered", func(t *testing.T) {
+		srv := runServer(t)
+
+		js := newJetStreamContext(t, srv)
+
+		_, err := js.AddStream(&nats.StreamConfig{
+			Name:      defaultStreamName,
+			Subjects:  []string{streamSubjects},
+			Storage:   nats.MemoryStorage,
+			Retention: nats.WorkQueuePolicy,
+		})
+		require.NoError(t, err)
+
+		// Create the durable consumer with a short ack wait so that the message would be redelivered
+		// if the server weren't notified that the message is in progress.
+		_, err = js.AddConsumer(defaultStreamName, &nats.ConsumerConfig{
+			Durable:        durableName(topic),
+			DeliverSubject: nats.NewInbox(),
+			DeliverPolicy:  nats.DeliverAllPolicy,
+			AckPolicy:      nats.AckExplicitPolicy,
+			AckWait:        500 * time.Millisecond,
+			FilterSubject:  topic,
+		})
+		require.NoError(t, err)
+
+		p := New(Config{URL: srv.ClientURL()})
+		defer func() { require.NoError(t, p.Close()) }()
+
+		p.progressInterval = 100 * time.Millisecond
+
+		msgChan, err := p.Subscribe(context.Background(), topic)
+		require.NoError(t, err)
+
+		require.NoError(t, p.Publish(topic, message.NewMessage(watermill.NewUUID(), []byte("some payload"))))
+
+		m := receive(t, msgChan)
+
+		// Hold on to the message for longer than the ack wait.
+		time.Sleep(1500 * time.Millisecond)
+
+		info, err := js.ConsumerInfo(defaultStreamName, durableName(topic))
+		require.NoError(t, err)
+		require.Zero(t, info.NumRedelivered)
+		require.Equal(t, 1, info.NumAckPending)
+
+		m.Ack()
+
+		require.Eventually(t, func() bool {
+			return numStreamMessages(t, js, defaultStreamName) == 0
+		}, 5*time.Second, 50*time.Millisecond)
+	})
+
+	t.Run("Nack -> redelivery", func(t *testing.T) {
+		srv := runServer(t)
+
+		p := New(Config{
+			URL:                       srv.ClientURL(),
+			MaxRedeliveryAttempts:     1,
+			RedeliveryInitialInterval: 100 * time.Millisecond,
+		})
+		defer func() { require.NoError(t, p.Close()) }()
+
+		msgChan, err := p.Subscribe(context.Background(), topic)
+		require.NoError(t, err)
+
+		undeliverableChan, err := p.Subscribe(context.Background(), spi.UndeliverableTopic)
+		require.NoError(t, err)
+
+		msg := message.NewMessage(watermill.NewUUID(), []byte("some payload"))
+
+		require.NoError(t, p.Publish(topic, msg))
+
+		receive(t, msgChan).Nack()
+
+		m := receive(t, msgChan)
+		require.Equal(t, msg.UUID, m.UUID)
+		require.Equal(t, "1", m.Metadata.Get(metadataRedeliveryCount))
+
+		m.Nack()
+
+		m = receive(t, undeliverableChan)
+		require.Equal(t, msg.UUID, m.UUID)
+		require.Equal(t, topic, m.Metadata.Get(spi.MetadataOriginalTopic))
+
+		m.Ack()
+	})
+
+	t.Run("Bind to existing stream", func(t *testing.T) {
+		const streamName = "existing"
+
+		srv := runServer(t)
+
+		js := newJetStreamContext(t, srv)
+
+		// The stream was created (e.g. by an administrator) with a different retention policy.
+		_, err := js.AddStream(&nats.StreamConfig{
+			Name:     streamName,
+			Subjects: []string{streamSubjects},
+			Storage:  nats.MemoryStorage,
+		})
+		require.NoError(t, err)
+
+		p := New(Config{URL: srv.ClientURL(), StreamName: streamName})
+		defer func() { require.NoError(t, p.Close()) }()
+
+		// The existing stream is used as is.
+		info, err := js.StreamInfo(streamName)
+		require.NoError(t, err)
+		require.Equal(t, nats.LimitsPolicy, info.Config.Retention)
+
+		_, err = js.StreamInfo(defaultStreamName)
+		require.Error(t, err)
+
+		msgChan, err := p.Subscribe(context.Background(), topic)
+		require.NoError(t, err)
+
+		msg := message.NewMessage(watermill.NewUUID(), []byte("some payload"))
+
+		require.NoError(t, p.Publish(topic, msg))
+
+		m := receive(t, msgChan)
+		require.Equal(t, msg.UUID, m.UUID)
+
+		m.Ack()
+
+		consumer, err := js.ConsumerInfo(streamName, durableName(topic))
+		require.NoError(t, err)
+		require.Equal(t, topic, consumer.Config.FilterSubject)
+		require.Eventually(t, func() bool {
+			ci, e := js.ConsumerInfo(streamName, durableName(topic))
+			require.NoError(t, e)
+
+			return ci.AckFloor.Stream == 1
+		}, 5*time.Second, 50*time.Millisecond)
+	})
+}
+
+func runServer(t *testing.T) *server.Server {
+	t.Helper()
+
+	opts := natsserver.DefaultTestOptions
+	opts.Port = server.RANDOM_PORT
+	opts.JetStream = true
+	opts.StoreDir = t.TempDir()
+
+	srv := natsserver.RunServer(&opts)
+
+	t.Cleanup(srv.Shutdown)
+
+	return srv
+}
+
+func newJetStreamContext(t *testing.T, srv *server.Server) nats.JetStreamContext {
+	t.Helper()
+
+	nc, err := nats.Connect(srv.ClientURL())
+	require.NoError(t, err)
+
+	t.Cleanup(nc.Close)
+
+	js, err := nc.JetStream()
+	require.NoError(t, err)
+
+	return js
+}
+
+func numStreamMessages(t *testing.T, js nats.JetStreamContext, stream string) uint64 {
+	t.Helper()
+
+	info, err := js.StreamInfo(stream)
+	require.NoError(t, err)
+
+	return info.State.Msgs
+}
+
+func receive(t *testing.T, msgChan <-chan *message.Message) *message.Message {
+	t.Helper()
+
+	select {
+	case m := <-msgChan:
+		return m
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for message")
+
+		return nil
+	}
+}
@@ -0,0 +1,744 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package nats
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/cenkalti/backoff"
+	"github.com/nats-io/nats.go"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/lifecycle"
+	"github.com/trustbloc/orb/pkg/pubsub/spi"
+)
+
+const loggerModule = "pubsub"
+
+var logger = log.New(loggerModule)
+
+const (
+	defaultMaxConnectRetries     = 25
+	defaultMaxConnectInterval    = 5 * time.Second
+	defaultMaxConnectElapsedTime = 3 * time.Minute
+
+	defaultMaxRedeliveryAttempts     = 10
+	defaultRedeliveryMultiplier      = 1.5
+	defaultRedeliveryInitialInterval = 2 * time.Second
+	defaultMaxRedeliveryInterval     = 30 * time.Second
+
+	defaultStreamName = "orb"
+	streamSubjects    = "orb.>"
+
+	// defaultProgressInterval is the interval at which the server is notified that a message is still being
+	// processed (or is waiting for its delivery time). This value must be less than the consumer's ack wait
+	// (which defaults to 30s), otherwise the server will redeliver the message.
+	defaultProgressInterval = 10 * time.Second
+
+	metadataMessageUUID     = "_watermill_message_uuid"
+	metadataRedeliveryCount = "orb-redelivery-count"
+	metadataDeliverAt       = "orb-deliver-at"
+
+	base10 = 10
+)
+
+// Config holds the configuration for the publisher/subscriber.
+type Config struct {
+	URL                       string
+	StreamName                string
+	MaxConnectRetries         int
+	MaxRedeliveryAttempts     int
+	RedeliveryMultiplier      float64
+	RedeliveryInitialInterval time.Duration
+	MaxRedeliveryInterval     time.Duration
+}
+
+type subscription interface {
+	Unsubscribe() error
+}
+
+// acker acknowledges a JetStream message.
+type acker interface {
+	Ack(opts ...nats.AckOpt) error
+	Nak(opts ...nats.AckOpt) error
+	InProgress(opts ...nats.AckOpt) error
+}
+
+type msgHandler = func(msg *nats.Msg, ack acker)
+
+type jetStream interface {
+	ensureStream(name, subjects string) error
+	publish(msg *nats.Msg) error
+	subscribe(stream, subject, durable string, handler msgHandler) (subscription, error)
+	broadcastSubscribe(subject string, handler func(msg *nats.Msg)) (subscription, error)
+}
+
+type connection interface {
+	jetStream() (jetStream, error)
+	isConnected() bool
+	close()
+}
+
+type connectFunc = func(url string) (connection, error)
+
+// PubSub implements a publisher/subscriber that uses NATS JetStream. All topics are persisted in a single stream
+// and each topic is consumed by a durable consumer (named after the topic) using a queue group, so that a message
+// is delivered to only one subscriber across all server instances. (Broadcast subscribers receive all messages
+// published to the topic while they're connected.)
+type PubSub struct {
+	*lifecycle.Lifecycle
+	Config
+
+	connect          connectFunc
+	conn             connection
+	js               jetStream
+	progressInterval time.Duration
+	done             chan struct{}
+	wg               sync.WaitGroup
+	mutex            sync.RWMutex
+	subscriptions    []subscription
+	msgChans         []chan *message.Message
+}
+
+// New returns a new NATS JetStream publisher/subscriber.
+func New(cfg Config) *PubSub {
+	return newPubSub(cfg, connect)
+}
+
+func newPubSub(cfg Config, connect connectFunc) *PubSub {
+	p := &PubSub{
+		Config:           initConfig(cfg),
+		connect:          connect,
+		progressInterval: defaultProgressInterval,
+		done:             make(chan struct{}),
+	}
+
+	p.Lifecycle = lifecycle.New("nats",
+		lifecycle.WithStart(p.start),
+		lifecycle.WithStop(p.stop))
+
+	// Start the service immediately.
+	p.Start()
+
+	return p
+}
+
+// Subscribe subscribes to a topic and returns the Go channel over which messages
+// are sent. The returned channel will be closed when Close() is called on this struct.
+func (p *PubSub) Subscribe(ctx context.Context, topic string) (<-chan *message.Message, error) {
+	return p.SubscribeWithOpts(ctx, topic)
+}
+
+// IsConnected return true if connected to the NATS server.
+func (p *PubSub) IsConnected() bool {
+	return p.conn != nil && p.conn.isConnected()
+}
+
+// SubscribeWithOpts subscribes to a topic using the given options, and returns the Go channel over which messages
+// are sent. The returned channel will be closed when Close() is called on this struct. If a pool size is specified
+// then the given number of subscriptions are created (all of which send messages to the returned channel) so that
+// messages are processed concurrently.
+func (p *PubSub) SubscribeWithOpts(ctx context.Context, topic string,
+	opts ...spi.Option) (<-chan *message.Message, error) {
+	if p.State() != lifecycle.StateStarted {
+		return nil, lifecycle.ErrNotStarted
+	}
+
+	options := getOptions(opts)
+
+	if options.Broadcast {
+		return p.subscribeBroadcast(ctx, topic)
+	}
+
+	poolSize := options.PoolSize
+	if poolSize < 1 {
+		poolSize = 1
+	}
+
+	logger.Debug("Subscribing to topic", log.WithTopic(topic), log.WithSubscriberPoolSize(poolSize))
+
+	msgChan := make(chan *message.Message, poolSize)
+
+	subs := make([]subscription, 0, poolSize)
+
+	for i := 0; i < poolSize; i++ {
+		sub, err := p.js.subscribe(p.StreamName, topic, durableName(topic),
+			func(m *nats.Msg, ack acker) {
+				p.handleMessage(ctx, topic, msgChan, m, ack)
+			},
+		)
+		if err != nil {
+			for _, s := range subs {
+				if errUnsub := s.Unsubscribe(); errUnsub != nil {
+					logger.Warn("Error unsubscribing", log.WithTopic(topic), log.WithError(errUnsub))
+				}
+			}
+
+			return nil, errors.NewTransientf("subscribe to topic [%s]: %w", topic, err)
+		}
+
+		subs = append(subs, sub)
+	}
+
+	p.mutex.Lock()
+	p.subscriptions = append(p.subscriptions, subs...)
+	p.msgChans = append(p.msgChans, msgChan)
+	p.mutex.Unlock()
+
+	return msgChan, nil
+}
+
+// subscribeBroadcast creates a (non-durable) subscription to the subject of the topic which receives all messages
+// published to the topic, independently of the durable consumer of the topic. Broadcast messages aren't
+// acknowledged to the server and a nacked message is not redelivered.
+func (p *PubSub) subscribeBroadcast(ctx context.Context, topic string) (<-chan *message.Message, error) {
+	logger.Debug("Subscribing to topic for broadcast messages", log.WithTopic(topic))
+
+	msgChan := make(chan *message.Message, 1)
+
+	sub, err := p.js.broadcastSubscribe(topic, func(m *nats.Msg) {
+		if !p.addInFlight() {
+			return
+		}
+
+		defer p.wg.Done()
+
+		p.deliverBroadcast(ctx, topic, msgChan, unmarshalMessage(m))
+	})
+	if err != nil {
+		return nil, errors.NewTransientf("subscribe to topic [%s]: %w", topic, err)
+	}
+
+	p.mutex.Lock()
+	p.subscriptions = append(p.subscriptions, sub)
+	p.msgChans = append(p.msgChans, msgChan)
+	p.mutex.Unlock()
+
+	return msgChan, nil
+}
+
+// Publish publishes the given messages to the given topic.
+func (p *PubSub) Publish(topic string, messages ...*message.Message) error {
+	if p.State() != lifecycle.StateStarted {
+		return lifecycle.ErrNotStarted
+	}
+
+	logger.Debug("Publishing messages", log.WithTopic(topic))
+
+	for _, msg := range messages {
+		if err := p.publish(topic, msg, 0); err != nil {
+			logger.Error("Error publishing message", log.WithMessageID(msg.UUID), log.WithTopic(topic))
+
+			return errors.NewTransientf("publish messages to topic [%s]: %w", topic, err)
+		}
+	}
+
+	return nil
+}
+
+// PublishWithOpts publishes a message to a topic using the supplied options. If a delivery delay is
+// specified then the message is not delivered to a subscriber until the delay has elapsed.
+func (p *PubSub) PublishWithOpts(topic string, msg *message.Message, opts ...spi.Option) error {
+	if p.State() != lifecycle.StateStarted {
+		return lifecycle.ErrNotStarted
+	}
+
+	delay := getOptions(opts).DeliveryDelay
+
+	logger.Debug("Publishing message", log.WithMessageID(msg.UUID),
+		log.WithTopic(topic), log.WithDeliveryDelay(delay))
+
+	if err := p.publish(topic, msg, delay); err != nil {
+		logger.Error("Error publishing message", log.WithMessageID(msg.UUID), log.WithTopic(topic),
+			log.WithError(err))
+
+		return errors.NewTransientf("publish message to topic [%s]: %w", topic, err)
+	}
+
+	return nil
+}
+
+// Close stops the publisher/subscriber.
+func (p *PubSub) Close() error {
+	p.Stop()
+
+	return nil
+}
+
+func (p *PubSub) start() {
+	logger.Info("Connecting to message queue", log.WithAddress(extractEndpoint(p.URL)))
+
+	err := backoff.RetryNotify(
+		func() error {
+			return p.connectJetStream()
+		},
+		backoff.WithMaxRetries(newConnectBackOff(), uint64(p.MaxConnectRetries)),
+		func(err error, duration time.Duration) {
+			logger.Debug("Error connecting to NATS service. Will retry with backoff...",
+				log.WithAddress(extractEndpoint(p.URL)), log.WithBackoff(duration), log.WithError(err))
+		},
+	)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to connect to message queue after %d attempts: %s", p.MaxConnectRetries, err))
+	}
+
+	logger.Info("Successfully connected to NATS service", log.WithAddress(extractEndpoint(p.URL)))
+}
+
+func (p *PubSub) connectJetStream() error {
+	conn, err := p.connect(p.URL)
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+
+	js, err := conn.jetStream()
+	if err != nil {
+		conn.close()
+
+		return fmt.Errorf("get JetStream context: %w", err)
+	}
+
+	if err := js.ensureStream(p.StreamName, streamSubjects); err != nil {
+		conn.close()
+
+		return fmt.Errorf("ensure stream [%s]: %w", p.StreamName, err)
+	}
+
+	p.conn = conn
+	p.js = js
+
+	return nil
+}
+
+func (p *PubSub) stop() {
+	logger.Debug("Closing subscriptions...")
+
+	p.mutex.Lock()
+	close(p.done)
+	subscriptions := p.subscriptions
+	msgChans := p.msgChans
+	p.mutex.Unlock()
+
+	for _, s := range subscriptions {
+		if err := s.Unsubscribe(); err != nil {
+			logger.Warn("Error unsubscribing", log.WithError(err))
+		}
+	}
+
+	// Wait for all in-flight handlers to exit before closing the message channels.
+	p.wg.Wait()
+
+	for _, msgChan := range msgChans {
+		close(msgChan)
+	}
+
+	logger.Debug("Closing connection...")
+
+	p.conn.close()
+}
+
+// handleMessage is invoked by the subscription for each message received from the server. If the message has a
+// delivery time in the future then it is handled in a separate Go routine so that other messages aren't blocked.
+func (p *PubSub) handleMessage(ctx context.Context, topic string, msgChan chan<- *message.Message,
+	m *nats.Msg, ack acker) {
+	if !p.addInFlight() {
+		// The subscriber is closing. The message will be redelivered by the server since it isn't acked.
+		return
+	}
+
+	msg := unmarshalMessage(m)
+
+	logger.Debug("Got new message", log.WithMessageID(msg.UUID), log.WithTopic(topic))
+
+	deliverAt := getDeliverAt(msg)
+
+	if time.Until(deliverAt) <= 0 {
+		defer p.wg.Done()
+
+		p.deliver(ctx, topic, msgChan, msg, ack)
+
+		return
+	}
+
+	go func() {
+		defer p.wg.Done()
+
+		if !p.waitUntil(ctx, deliverAt, ack) {
+			return
+		}
+
+		p.deliver(ctx, topic, msgChan, msg, ack)
+	}()
+}
+
+// addInFlight increments the count of in-flight message handlers. False is returned if the subscriber is closing.
+func (p *PubSub) addInFlight() bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	select {
+	case <-p.done:
+		return false
+	default:
+	}
+
+	p.wg.Add(1)
+
+	return true
+}
+
+// deliver sends the message to the subscriber and waits for the subscriber to either ack or nack the message.
+// If the message is nacked then it is scheduled for redelivery.
+func (p *PubSub) deliver(ctx context.Context, topic string, msgChan chan<- *message.Message,
+	msg *message.Message, ack acker) {
+	ticker := time.NewTicker(p.progressInterval)
+	defer ticker.Stop()
+
+	sent := false
+
+	for !sent {
+		select {
+		case msgChan <- msg:
+			sent = true
+		case <-ticker.C:
+			p.inProgress(msg, ack)
+		case <-ctx.Done():
+			return
+		case <-p.done:
+			return
+		}
+	}
+
+	for {
+		select {
+		case <-msg.Acked():
+			if err := ack.Ack(); err != nil {
+				logger.Warn("Error acknowledging message", log.WithMessageID(msg.UUID), log.WithError(err))
+			}
+
+			return
+		case <-msg.Nacked():
+			p.handleNack(topic, msg, ack)
+
+			return
+		case <-ticker.C:
+			p.inProgress(msg, ack)
+		case <-ctx.Done():
+			return
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// deliverBroadcast sends the broadcast message to the subscriber and waits for the subscriber to either ack or
+// nack the message.
+func (p *PubSub) deliverBroadcast(ctx context.Context, topic string, msgChan chan<- *message.Message,
+	msg *message.Message) {
+	select {
+	case msgChan <- msg:
+	case <-ctx.Done():
+		return
+	case <-p.done:
+		return
+	}
+
+	select {
+	case <-msg.Acked():
+	case <-msg.Nacked():
+		logger.Debug("Broadcast message was nacked and will not be redelivered", log.WithMessageID(msg.UUID),
+			log.WithTopic(topic))
+	case <-ctx.Done():
+	case <-p.done:
+	}
+}
+
+// waitUntil waits until the given time, periodically notifying the server that the message is in progress
+// so that it isn't redelivered. False is returned if the subscriber was closed while waiting.
+func (p *PubSub) waitUntil(ctx context.Context, deliverAt time.Time, ack acker) bool {
+	ticker := time.NewTicker(p.progressInterval)
+	defer ticker.Stop()
+
+	timer := time.NewTimer(time.Until(deliverAt))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			return true
+		case <-ticker.C:
+			if err := ack.InProgress(); err != nil {
+				logger.Warn("Error notifying server that delayed message is in progress", log.WithError(err))
+			}
+		case <-ctx.Done():
+			return false
+		case <-p.done:
+			return false
+		}
+	}
+}
+
+func (p *PubSub) inProgress(msg *message.Message, ack acker) {
+	if err := ack.InProgress(); err != nil {
+		logger.Warn("Error notifying server that message is in progress", log.WithMessageID(msg.UUID),
+			log.WithError(err))
+	}
+}
+
+/*
+handleNack is invoked when the subscriber nacks a message. The first time a message is nacked it is immediately
+republished to the topic. After that, the message is republished with a delivery time which increases with each
+attempt. When the maximum number of redelivery attempts has been reached, the message is posted to the
+undeliverable topic. In all cases the original message is acked. If the message could not be republished then
+the original message is nacked so that the server redelivers it.
+*/
+func (p *PubSub) handleNack(topic string, msg *message.Message, ack acker) {
+	redeliveryAttempts := getRedeliveryAttempts(msg)
+
+	var err error
+
+	if redeliveryAttempts < p.MaxRedeliveryAttempts {
+		err = p.redeliver(topic, msg, redeliveryAttempts)
+	} else {
+		logger.Error("Message will not be redelivered since the maximum delivery attempts has been reached",
+			log.WithMessageID(msg.UUID), log.WithTopic(topic), log.WithDeliveryAttempts(redeliveryAttempts+1))
+
+		err = p.postToUndeliverable(topic, msg, redeliveryAttempts+1)
+	}
+
+	if err != nil {
+		logger.Error("Error redelivering message. The message will be nacked and retried.",
+			log.WithMessageID(msg.UUID), log.WithTopic(topic), log.WithError(err))
+
+		if errNak := ack.Nak(); errNak != nil {
+			logger.Warn("Error nacking message", log.WithMessageID(msg.UUID), log.WithError(errNak))
+		}
+
+		return
+	}
+
+	if errAck := ack.Ack(); errAck != nil {
+		logger.Warn("Error acknowledging message", log.WithMessageID(msg.UUID), log.WithError(errAck))
+	}
+}
+
+func (p *PubSub) redeliver(topic string, msg *message.Message, redeliveryAttempts int) error {
+	delay := p.getRedeliveryInterval(redeliveryAttempts)
+
+	redeliveryAttempts++
+
+	newMsg := msg.Copy()
+	newMsg.Metadata.Set(metadataRedeliveryCount, strconv.FormatInt(int64(redeliveryAttempts), base10))
+
+	if err := p.publish(topic, newMsg, delay); err != nil {
+		return fmt.Errorf("publish message to topic [%s] for redelivery attempt %d: %w",
+			topic, redeliveryAttempts, err)
+	}
+
+	logger.Info("Successfully posted message for redelivery", log.WithMessageID(msg.UUID),
+		log.WithTopic(topic), log.WithDeliveryDelay(delay), log.WithDeliveryAttempts(redeliveryAttempts))
+
+	return nil
+}
+
+func (p *PubSub) postToUndeliverable(topic string, msg *message.Message, deliveryAttempts int) error {
+	if topic == spi.UndeliverableTopic {
+		logger.Warn("Message from the undeliverable topic was not processed and will be dropped",
+			log.WithMessageID(msg.UUID))
+
+		return nil
+	}
+
+	newMsg := msg.Copy()
+	newMsg.Metadata.Set(spi.MetadataOriginalTopic, topic)
+	newMsg.Metadata.Set(spi.MetadataDeliveryAttempts, strconv.Itoa(deliveryAttempts))
+	delete(newMsg.Metadata, metadataRedeliveryCount)
+
+	if err := p.publish(spi.UndeliverableTopic, newMsg, 0); err != nil {
+		return fmt.Errorf("publish message to topic [%s]: %w", spi.UndeliverableTopic, err)
+	}
+
+	logger.Info("Message was posted to the undeliverable topic", log.WithMessageID(msg.UUID),
+		log.WithTopic(topic))
+
+	return nil
+}
+
+func (p *PubSub) publish(topic string, msg *message.Message, delay time.Duration) error {
+	return p.js.publish(marshalMessage(topic, msg, delay))
+}
+
+func (p *PubSub) getRedeliveryInterval(attempts int) time.Duration {
+	if attempts == 0 {
+		return 0
+	}
+
+	if attempts == 1 {
+		return p.RedeliveryInitialInterval
+	}
+
+	interval := time.Duration(float64(p.RedeliveryInitialInterval) * math.Pow(p.RedeliveryMultiplier, float64(attempts-1)))
+
+	if interval > p.MaxRedeliveryInterval {
+		interval = p.MaxRedeliveryInterval
+	}
+
+	return interval
+}
+
+func marshalMessage(topic string, msg *message.Message, delay time.Duration) *nats.Msg {
+	m := nats.NewMsg(topic)
+	m.Data = msg.Payload
+
+	for key, value := range msg.Metadata {
+		m.Header.Set(key, value)
+	}
+
+	m.Header.Set(metadataMessageUUID, msg.UUID)
+
+	if delay > 0 {
+		m.Header.Set(metadataDeliverAt, time.Now().Add(delay).UTC().Format(time.RFC3339Nano))
+	} else {
+		m.Header.Del(metadataDeliverAt)
+	}
+
+	return m
+}
+
+func unmarshalMessage(m *nats.Msg) *message.Message {
+	msg := message.NewMessage(m.Header.Get(metadataMessageUUID), m.Data)
+
+	for key := range m.Header {
+		if key == metadataMessageUUID {
+			continue
+		}
+
+		msg.Metadata.Set(key, m.Header.Get(key))
+	}
+
+	return msg
+}
+
+func getDeliverAt(msg *message.Message) time.Time {
+	value := msg.Metadata.Get(metadataDeliverAt)
+	if value == "" {
+		return time.Time{}
+	}
+
+	// The delivery time is only used internally so remove it from the message.
+	delete(msg.Metadata, metadataDeliverAt)
+
+	deliverAt, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		logger.Warn("Message metadata property is not a valid time. The message will be delivered immediately.",
+			log.WithMessageID(msg.UUID), log.WithProperty(metadataDeliverAt))
+
+		return time.Time{}
+	}
+
+	return deliverAt
+}
+
+func getRedeliveryAttempts(msg *message.Message) int {
+	var count int
+
+	countValue, ok := msg.Metadata[metadataRedeliveryCount]
+	if ok {
+		c, err := strconv.ParseInt(countValue, base10, 0)
+		if err != nil {
+			logger.Warn("Message metadata property is not a valid int. Redelivery count will be set to 0",
+				log.WithMessageID(msg.UUID), log.WithProperty(metadataRedeliveryCount))
+		} else {
+			count = int(c)
+		}
+	}
+
+	return count
+}
+
+// durableName returns the name of the durable consumer for the given topic. Durable names may not contain dots.
+func durableName(topic string) string {
+	return strings.ReplaceAll(topic, ".", "_")
+}
+
+// extractEndpoint returns the endpoint of the NATS URL, i.e. everything after @.
+func extractEndpoint(natsURL string) string {
+	i := strings.Index(natsURL, "://")
+	if i < 0 {
+		return ""
+	}
+
+	path := natsURL[i+3:]
+
+	j := strings.Index(path, "@")
+	if j < 0 {
+		return path
+	}
+
+	return path[j+1:]
+}
+
+func newConnectBackOff() backoff.BackOff {
+	b := &backoff.ExponentialBackOff{
+		InitialInterval:     backoff.DefaultInitialInterval,
+		RandomizationFactor: backoff.DefaultRandomizationFactor,
+		Multiplier:          backoff.DefaultMultiplier,
+		MaxInterval:         defaultMaxConnectInterval,
+		MaxElapsedTime:      defaultMaxConnectElapsedTime,
+		Clock:               backoff.SystemClock,
+	}
+
+	b.Reset()
+
+	return b
+}
+
+func initConfig(cfg Config) Config {
+	if cfg.StreamName == "" {
+		cfg.StreamName = defaultStreamName
+	}
+
+	if cfg.MaxConnectRetries == 0 {
+		cfg.MaxConnectRetries = defaultMaxConnectRetries
+	}
+
+	if cfg.MaxRedeliveryAttempts == 0 {
+		cfg.MaxRedeliveryAttempts = defaultMaxRedeliveryAttempts
+	}
+
+	if cfg.RedeliveryMultiplier == 0 {
+		cfg.RedeliveryMultiplier = defaultRedeliveryMultiplier
+	}
+
+	if cfg.RedeliveryInitialInterval == 0 {
+		cfg.RedeliveryInitialInterval = defaultRedeliveryInitialInterval
+	}
+
+	if cfg.MaxRedeliveryInterval == 0 {
+		cfg.MaxRedeliveryInterval = defaultMaxRedeliveryInterval
+	}
+
+	return cfg
+}
+
+func getOptions(opts []spi.Option) *spi.Options {
+	options := &spi.Options{}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return options
+}
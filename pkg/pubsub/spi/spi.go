@@ -11,10 +11,21 @@ import "time"
 // UndeliverableTopic is the topic to which to post undeliverable messages.
 const UndeliverableTopic = "orb.undeliverable.activities"
 
+const (
+	// MetadataOriginalTopic is the metadata property of an undeliverable message which holds the topic
+	// to which the message was originally published.
+	MetadataOriginalTopic = "orb-original-topic"
+
+	// MetadataDeliveryAttempts is the metadata property of an undeliverable message which holds the
+	// number of times that delivery of the message was attempted.
+	MetadataDeliveryAttempts = "orb-delivery-attempts"
+)
+
 // Options contains publisher/subscriber options.
 type Options struct {
 	PoolSize      int
 	DeliveryDelay time.Duration
+	Broadcast     bool
 }
 
 // Option specifies a publisher/subscriber option.
@@ -27,6 +38,16 @@ func WithPool(size int) Option {
 	}
 }
 
+// WithBroadcast indicates that the subscriber (i.e. every server instance which subscribes to the topic with this
+// option) should receive all messages published to the topic, rather than competing with the other subscribers
+// of the topic for messages. Broadcast messages are not persisted or redelivered, so a message is lost if the
+// subscriber isn't connected when it is published or if the subscriber nacks the message.
+func WithBroadcast() Option {
+	return func(option *Options) {
+		option.Broadcast = true
+	}
+}
+
 // WithDeliveryDelay sets the delivery delay.
 // Note: Not all message brokers support this option.
 func WithDeliveryDelay(delay time.Duration) Option {
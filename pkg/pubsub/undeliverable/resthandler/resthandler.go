@@ -0,0 +1,234 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/pubsub/undeliverable"
+)
+
+const (
+	listEndpoint    = "/mq/undeliverable"
+	requeueEndpoint = "/mq/undeliverable/requeue"
+	dropEndpoint    = "/mq/undeliverable/drop"
+
+	topicParam = "topic"
+)
+
+const (
+	badRequestResponse          = "Bad Request."
+	internalServerErrorResponse = "Internal Server Error."
+)
+
+const loggerModule = "undeliverable-rest-handler"
+
+type undeliverableService interface {
+	List(topic string) ([]*undeliverable.Message, error)
+	Requeue(topic string, ids ...string) (*undeliverable.Result, error)
+	Drop(topic string, ids ...string) (*undeliverable.Result, error)
+}
+
+// ListHandler returns the undeliverable messages. The optional "topic" query parameter restricts the
+// results to the messages which were originally published to the given topic.
+type ListHandler struct {
+	service undeliverableService
+	logger  *log.Log
+	marshal func(interface{}) ([]byte, error)
+}
+
+// NewListHandler returns a new ListHandler.
+func NewListHandler(service undeliverableService) *ListHandler {
+	return &ListHandler{
+		service: service,
+		logger:  log.New(loggerModule, log.WithFields(log.WithServiceEndpoint(listEndpoint))),
+		marshal: json.Marshal,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the list handler.
+func (h *ListHandler) Path() string {
+	return listEndpoint
+}
+
+// Method returns the HTTP REST method for the list handler.
+func (h *ListHandler) Method() string {
+	return http.MethodGet
+}
+
+// Handler returns the HTTP REST handle for the list handler.
+func (h *ListHandler) Handler() common.HTTPRequestHandler {
+	return h.handle
+}
+
+func (h *ListHandler) handle(w http.ResponseWriter, req *http.Request) {
+	msgs, err := h.service.List(req.URL.Query().Get(topicParam))
+	if err != nil {
+		h.logger.Error("Error listing undeliverable messages", log.WithError(err))
+
+		writeResponse(h.logger, w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	writeJSON(h.logger, w, h.marshal, msgs)
+}
+
+// RequeueHandler publishes the requested undeliverable messages to their original topics.
+type RequeueHandler struct {
+	*updateHandler
+}
+
+// NewRequeueHandler returns a new RequeueHandler.
+func NewRequeueHandler(service undeliverableService) *RequeueHandler {
+	return &RequeueHandler{
+		updateHandler: newUpdateHandler(requeueEndpoint, service.Requeue),
+	}
+}
+
+// DropHandler deletes the requested undeliverable messages.
+type DropHandler struct {
+	*updateHandler
+}
+
+// NewDropHandler returns a new DropHandler.
+func NewDropHandler(service undeliverableService) *DropHandler {
+	return &DropHandler{
+		updateHandler: newUpdateHandler(dropEndpoint, service.Drop),
+	}
+}
+
+type updateFunc func(topic string, ids ...string) (*undeliverable.Result, error)
+
+type updateHandler struct {
+	path      string
+	update    updateFunc
+	logger    *log.Log
+	marshal   func(interface{}) ([]byte, error)
+	unmarshal func([]byte, interface{}) error
+}
+
+func newUpdateHandler(path string, update updateFunc) *updateHandler {
+	return &updateHandler{
+		path:      path,
+		update:    update,
+		logger:    log.New(loggerModule, log.WithFields(log.WithServiceEndpoint(path))),
+		marshal:   json.Marshal,
+		unmarshal: json.Unmarshal,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the handler.
+func (h *updateHandler) Path() string {
+	return h.path
+}
+
+// Method returns the HTTP REST method for the handler.
+func (h *updateHandler) Method() string {
+	return http.MethodPost
+}
+
+// Handler returns the HTTP REST handle for the handler.
+func (h *updateHandler) Handler() common.HTTPRequestHandler {
+	return h.handle
+}
+
+func (h *updateHandler) handle(w http.ResponseWriter, req *http.Request) {
+	reqBytes, err := io.ReadAll(req.Body)
+	if err != nil {
+		h.logger.Error("Error reading request body", log.WithError(err))
+
+		writeResponse(h.logger, w, http.StatusBadRequest, []byte(badRequestResponse))
+
+		return
+	}
+
+	request, err := h.getRequest(reqBytes)
+	if err != nil {
+		h.logger.Info("Invalid request", log.WithError(err))
+
+		writeResponse(h.logger, w, http.StatusBadRequest, []byte(badRequestResponse))
+
+		return
+	}
+
+	result, err := h.update(request.Topic, request.IDs...)
+	if err != nil {
+		h.logger.Error("Error processing undeliverable messages", log.WithError(err))
+
+		writeResponse(h.logger, w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	writeJSON(h.logger, w, h.marshal, result)
+}
+
+func (h *updateHandler) getRequest(reqBytes []byte) (*updateRequest, error) {
+	request := &updateRequest{}
+
+	if err := h.unmarshal(reqBytes, request); err != nil {
+		return nil, fmt.Errorf("unmarshal request: %w", err)
+	}
+
+	if len(request.IDs) == 0 && !request.All {
+		return nil, errors.New("either message IDs or 'all' must be specified")
+	}
+
+	if len(request.IDs) > 0 && request.All {
+		return nil, errors.New("message IDs and 'all' are mutually exclusive")
+	}
+
+	return request, nil
+}
+
+type updateRequest struct {
+	// IDs contains the IDs of the messages to process.
+	IDs []string `json:"ids,omitempty"`
+	// All indicates that all messages (of the given topic, if specified) should be processed.
+	All bool `json:"all,omitempty"`
+	// Topic restricts the request to messages which were originally published to the given topic.
+	Topic string `json:"topic,omitempty"`
+}
+
+func writeJSON(logger *log.Log, w http.ResponseWriter, marshal func(interface{}) ([]byte, error), v interface{}) {
+	respBytes, err := marshal(v)
+	if err != nil {
+		logger.Error("Marshal response error", log.WithError(err))
+
+		writeResponse(logger, w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	writeResponse(logger, w, http.StatusOK, respBytes)
+}
+
+func writeResponse(logger *log.Log, w http.ResponseWriter, status int, body []byte) {
+	if len(body) > 0 {
+		w.Header().Set("Content-Type", "text/plain")
+	}
+
+	w.WriteHeader(status)
+
+	if len(body) > 0 {
+		if _, err := w.Write(body); err != nil {
+			log.WriteResponseBodyError(logger, err)
+
+			return
+		}
+
+		log.WroteResponse(logger, body)
+	}
+}
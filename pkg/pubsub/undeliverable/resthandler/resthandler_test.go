@@ -0,0 +1,193 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/pubsub/undeliverable"
+)
+
+const topic = "orb.operation"
+
+func TestListHandler(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		s := &mockService{
+			msgs: []*undeliverable.Message{{ID: "id1", Topic: topic, FailureCount: 3}},
+		}
+
+		h := NewListHandler(s)
+		require.Equal(t, listEndpoint, h.Path())
+		require.Equal(t, http.MethodGet, h.Method())
+		require.NotNil(t, h.Handler())
+
+		rw := httptest.NewRecorder()
+
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, listEndpoint+"?topic="+topic, nil))
+
+		result := rw.Result()
+		require.NoError(t, result.Body.Close())
+		require.Equal(t, http.StatusOK, result.StatusCode)
+		require.Equal(t, topic, s.topic)
+
+		var msgs []*undeliverable.Message
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &msgs))
+		require.Len(t, msgs, 1)
+		require.Equal(t, "id1", msgs[0].ID)
+		require.Equal(t, 3, msgs[0].FailureCount)
+	})
+
+	t.Run("Service error", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		NewListHandler(&mockService{err: errors.New("injected error")}).Handler()(rw,
+			httptest.NewRequest(http.MethodGet, listEndpoint, nil))
+
+		result := rw.Result()
+		require.NoError(t, result.Body.Close())
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.Equal(t, internalServerErrorResponse, rw.Body.String())
+	})
+
+	t.Run("Marshal error", func(t *testing.T) {
+		h := NewListHandler(&mockService{})
+		h.marshal = func(interface{}) ([]byte, error) { return nil, errors.New("injected marshal error") }
+
+		rw := httptest.NewRecorder()
+
+		h.Handler()(rw, httptest.NewRequest(http.MethodGet, listEndpoint, nil))
+
+		result := rw.Result()
+		require.NoError(t, result.Body.Close())
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+	})
+}
+
+func TestRequeueHandler(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		s := &mockService{}
+
+		h := NewRequeueHandler(s)
+		require.Equal(t, requeueEndpoint, h.Path())
+		require.Equal(t, http.MethodPost, h.Method())
+		require.NotNil(t, h.Handler())
+
+		rw := httptest.NewRecorder()
+
+		h.Handler()(rw, httptest.NewRequest(http.MethodPost, requeueEndpoint,
+			strings.NewReader(`{"ids":["id1","id2"]}`)))
+
+		result := rw.Result()
+		require.NoError(t, result.Body.Close())
+		require.Equal(t, http.StatusOK, result.StatusCode)
+		require.Equal(t, "requeue", s.op)
+		require.Equal(t, []string{"id1", "id2"}, s.ids)
+
+		r := &undeliverable.Result{}
+		require.NoError(t, json.Unmarshal(rw.Body.Bytes(), r))
+		require.Equal(t, []string{"id1", "id2"}, r.IDs)
+	})
+
+	t.Run("All of topic", func(t *testing.T) {
+		s := &mockService{}
+
+		rw := httptest.NewRecorder()
+
+		NewRequeueHandler(s).Handler()(rw, httptest.NewRequest(http.MethodPost, requeueEndpoint,
+			strings.NewReader(`{"all":true,"topic":"`+topic+`"}`)))
+
+		result := rw.Result()
+		require.NoError(t, result.Body.Close())
+		require.Equal(t, http.StatusOK, result.StatusCode)
+		require.Equal(t, topic, s.topic)
+		require.Empty(t, s.ids)
+	})
+
+	t.Run("Invalid request", func(t *testing.T) {
+		for _, body := range []string{`{`, `{}`, `{"ids":["id1"],"all":true}`} {
+			rw := httptest.NewRecorder()
+
+			NewRequeueHandler(&mockService{}).Handler()(rw,
+				httptest.NewRequest(http.MethodPost, requeueEndpoint, strings.NewReader(body)))
+
+			result := rw.Result()
+			require.NoError(t, result.Body.Close())
+			require.Equal(t, http.StatusBadRequest, result.StatusCode, body)
+			require.Equal(t, badRequestResponse, rw.Body.String())
+		}
+	})
+
+	t.Run("Service error", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		NewRequeueHandler(&mockService{err: errors.New("injected error")}).Handler()(rw,
+			httptest.NewRequest(http.MethodPost, requeueEndpoint, strings.NewReader(`{"all":true}`)))
+
+		result := rw.Result()
+		require.NoError(t, result.Body.Close())
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+	})
+}
+
+func TestDropHandler(t *testing.T) {
+	s := &mockService{}
+
+	h := NewDropHandler(s)
+	require.Equal(t, dropEndpoint, h.Path())
+	require.Equal(t, http.MethodPost, h.Method())
+
+	rw := httptest.NewRecorder()
+
+	h.Handler()(rw, httptest.NewRequest(http.MethodPost, dropEndpoint, strings.NewReader(`{"ids":["id1"]}`)))
+
+	result := rw.Result()
+	require.NoError(t, result.Body.Close())
+	require.Equal(t, http.StatusOK, result.StatusCode)
+	require.Equal(t, "drop", s.op)
+	require.Equal(t, []string{"id1"}, s.ids)
+}
+
+type mockService struct {
+	msgs  []*undeliverable.Message
+	err   error
+	op    string
+	topic string
+	ids   []string
+}
+
+func (m *mockService) List(topic string) ([]*undeliverable.Message, error) {
+	m.topic = topic
+
+	return m.msgs, m.err
+}
+
+func (m *mockService) Requeue(topic string, ids ...string) (*undeliverable.Result, error) {
+	return m.update("requeue", topic, ids)
+}
+
+func (m *mockService) Drop(topic string, ids ...string) (*undeliverable.Result, error) {
+	return m.update("drop", topic, ids)
+}
+
+func (m *mockService) update(op, topic string, ids []string) (*undeliverable.Result, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	m.op = op
+	m.topic = topic
+	m.ids = ids
+
+	return &undeliverable.Result{IDs: ids}, nil
+}
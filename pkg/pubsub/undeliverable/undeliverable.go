@@ -0,0 +1,356 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package undeliverable
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/lifecycle"
+	"github.com/trustbloc/orb/pkg/pubsub/spi"
+	"github.com/trustbloc/orb/pkg/store"
+)
+
+var logger = log.New("undeliverable")
+
+const (
+	storeName = "orb-undeliverable"
+
+	// topicTag is the tag used to query messages by original topic. (The tag name must be the same as the
+	// JSON field of the message since the MongoDB store maps tags to document fields.)
+	topicTag = "topic"
+)
+
+type pubSub interface {
+	Subscribe(ctx context.Context, topic string) (<-chan *message.Message, error)
+	Publish(topic string, messages ...*message.Message) error
+}
+
+// Message contains an undeliverable message along with the topic to which it was originally published.
+type Message struct {
+	// ID is the ID of the message.
+	ID string `json:"id"`
+	// Topic is the topic to which the message was originally published.
+	Topic string `json:"topic"`
+	// Payload is the message payload.
+	Payload []byte `json:"payload,omitempty"`
+	// Metadata contains the message metadata.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// FailureCount is the total number of failed delivery attempts. If the message was requeued and failed
+	// again then the count includes the attempts from the previous failures.
+	FailureCount int `json:"failureCount"`
+	// ReceivedAt is the time that the message was (last) received from the undeliverable queue.
+	ReceivedAt time.Time `json:"receivedAt"`
+}
+
+// Result contains the result of a requeue or drop request.
+type Result struct {
+	// IDs contains the IDs of the messages which were requeued/dropped.
+	IDs []string `json:"ids"`
+	// NotFound contains the requested IDs which were not found in the store.
+	NotFound []string `json:"notFound,omitempty"`
+}
+
+// Service consumes messages from the undeliverable queue and persists them so that an operator may
+// inspect them and then either requeue them to their original topic or drop them.
+type Service struct {
+	*lifecycle.Lifecycle
+
+	store     storage.Store
+	publisher pubSub
+	msgChan   <-chan *message.Message
+}
+
+// New returns a new undeliverable message service. The service subscribes to the undeliverable queue
+// immediately but doesn't start processing messages until Start is called.
+func New(provider storage.Provider, pubSub pubSub) (*Service, error) {
+	s, err := store.Open(provider, storeName, store.NewTagGroup(topicTag))
+	if err != nil {
+		return nil, fmt.Errorf("open store [%s]: %w", storeName, err)
+	}
+
+	logger.Debug("Subscribing to topic", log.WithTopic(spi.UndeliverableTopic))
+
+	msgChan, err := pubSub.Subscribe(context.Background(), spi.UndeliverableTopic)
+	if err != nil {
+		return nil, fmt.Errorf("subscribe to topic [%s]: %w", spi.UndeliverableTopic, err)
+	}
+
+	svc := &Service{
+		store:     s,
+		publisher: pubSub,
+		msgChan:   msgChan,
+	}
+
+	svc.Lifecycle = lifecycle.New("undeliverable", lifecycle.WithStart(svc.start))
+
+	return svc, nil
+}
+
+// List returns the persisted undeliverable messages, ordered by the time they were received. If topic
+// is not empty then only the messages which were originally published to the given topic are returned.
+func (s *Service) List(topic string) ([]*Message, error) {
+	msgs, err := s.query(topic)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(msgs, func(i, j int) bool {
+		return msgs[i].ReceivedAt.Before(msgs[j].ReceivedAt)
+	})
+
+	return msgs, nil
+}
+
+// Requeue publishes the messages with the given IDs to their original topics and removes them from the
+// store. If no IDs are provided then all messages (of the given topic, if specified) are requeued.
+func (s *Service) Requeue(topic string, ids ...string) (*Result, error) {
+	return s.process(topic, ids, func(msg *Message) error {
+		if err := s.publisher.Publish(msg.Topic, msg.message()); err != nil {
+			return fmt.Errorf("publish message [%s] to topic [%s]: %w", msg.ID, msg.Topic, err)
+		}
+
+		logger.Info("Requeued undeliverable message", log.WithMessageID(msg.ID), log.WithTopic(msg.Topic))
+
+		return nil
+	})
+}
+
+// Drop removes the messages with the given IDs from the store. If no IDs are provided then all
+// messages (of the given topic, if specified) are dropped.
+func (s *Service) Drop(topic string, ids ...string) (*Result, error) {
+	return s.process(topic, ids, func(msg *Message) error {
+		logger.Info("Dropping undeliverable message", log.WithMessageID(msg.ID), log.WithTopic(msg.Topic))
+
+		return nil
+	})
+}
+
+func (s *Service) process(topic string, ids []string, handle func(msg *Message) error) (*Result, error) {
+	msgs, notFound, err := s.resolve(topic, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		IDs:      []string{},
+		NotFound: notFound,
+	}
+
+	for _, msg := range msgs {
+		if err := handle(msg); err != nil {
+			return result, err
+		}
+
+		if err := s.store.Delete(msg.ID); err != nil {
+			return result, orberrors.NewTransientf("delete message [%s]: %w", msg.ID, err)
+		}
+
+		result.IDs = append(result.IDs, msg.ID)
+	}
+
+	return result, nil
+}
+
+func (s *Service) resolve(topic string, ids []string) ([]*Message, []string, error) {
+	if len(ids) == 0 {
+		msgs, err := s.List(topic)
+
+		return msgs, nil, err
+	}
+
+	var (
+		msgs     []*Message
+		notFound []string
+	)
+
+	for _, id := range ids {
+		msg, err := s.get(id)
+		if err != nil {
+			if errors.Is(err, storage.ErrDataNotFound) {
+				notFound = append(notFound, id)
+
+				continue
+			}
+
+			return nil, nil, err
+		}
+
+		if topic != "" && msg.Topic != topic {
+			notFound = append(notFound, id)
+
+			continue
+		}
+
+		msgs = append(msgs, msg)
+	}
+
+	return msgs, notFound, nil
+}
+
+func (s *Service) start() {
+	go s.listen()
+}
+
+func (s *Service) listen() {
+	logger.Debug("Starting undeliverable message listener")
+
+	for msg := range s.msgChan {
+		s.handleMessage(msg)
+	}
+
+	logger.Debug("Undeliverable message listener stopped")
+}
+
+func (s *Service) handleMessage(msg *message.Message) {
+	topic := msg.Metadata.Get(spi.MetadataOriginalTopic)
+	if topic == "" {
+		logger.Warn("Undeliverable message does not specify the original topic and will be dropped",
+			log.WithMessageID(msg.UUID))
+
+		msg.Ack()
+
+		return
+	}
+
+	if err := s.save(msg, topic); err != nil {
+		logger.Error("Error persisting undeliverable message", log.WithMessageID(msg.UUID),
+			log.WithTopic(topic), log.WithError(err))
+
+		msg.Nack()
+
+		return
+	}
+
+	logger.Info("Persisted undeliverable message", log.WithMessageID(msg.UUID), log.WithTopic(topic))
+
+	msg.Ack()
+}
+
+func (s *Service) save(msg *message.Message, topic string) error {
+	failureCount, err := strconv.Atoi(msg.Metadata.Get(spi.MetadataDeliveryAttempts))
+	if err != nil || failureCount < 1 {
+		failureCount = 1
+	}
+
+	// If the message was requeued previously then add the previous failures.
+	existing, err := s.get(msg.UUID)
+	if err == nil {
+		failureCount += existing.FailureCount
+	} else if !errors.Is(err, storage.ErrDataNotFound) {
+		return err
+	}
+
+	metadata := make(map[string]string)
+
+	for k, v := range msg.Metadata {
+		if k == spi.MetadataOriginalTopic || k == spi.MetadataDeliveryAttempts {
+			continue
+		}
+
+		metadata[k] = v
+	}
+
+	value, err := json.Marshal(&Message{
+		ID:           msg.UUID,
+		Topic:        topic,
+		Payload:      msg.Payload,
+		Metadata:     metadata,
+		FailureCount: failureCount,
+		ReceivedAt:   time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("marshal message: %w", err)
+	}
+
+	if err := s.store.Put(msg.UUID, value, storage.Tag{Name: topicTag, Value: topic}); err != nil {
+		return orberrors.NewTransientf("store message: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) get(id string) (*Message, error) {
+	value, err := s.store.Get(id)
+	if err != nil {
+		return nil, fmt.Errorf("get message [%s]: %w", id, err)
+	}
+
+	msg := &Message{}
+
+	if err := json.Unmarshal(value, msg); err != nil {
+		return nil, fmt.Errorf("unmarshal message [%s]: %w", id, err)
+	}
+
+	return msg, nil
+}
+
+func (s *Service) query(topic string) ([]*Message, error) {
+	expression := topicTag
+	if topic != "" {
+		expression = fmt.Sprintf("%s:%s", topicTag, topic)
+	}
+
+	it, err := s.store.Query(expression)
+	if err != nil {
+		return nil, fmt.Errorf("query messages: %w", err)
+	}
+
+	defer func() {
+		if errClose := it.Close(); errClose != nil {
+			log.CloseIteratorError(logger, errClose)
+		}
+	}()
+
+	msgs := make([]*Message, 0)
+
+	for {
+		ok, err := it.Next()
+		if err != nil {
+			return nil, fmt.Errorf("iterator next: %w", err)
+		}
+
+		if !ok {
+			break
+		}
+
+		value, err := it.Value()
+		if err != nil {
+			return nil, fmt.Errorf("iterator value: %w", err)
+		}
+
+		msg := &Message{}
+
+		if err := json.Unmarshal(value, msg); err != nil {
+			return nil, fmt.Errorf("unmarshal message: %w", err)
+		}
+
+		msgs = append(msgs, msg)
+	}
+
+	return msgs, nil
+}
+
+func (m *Message) message() *message.Message {
+	msg := message.NewMessage(m.ID, m.Payload)
+
+	for k, v := range m.Metadata {
+		msg.Metadata.Set(k, v)
+	}
+
+	return msg
+}
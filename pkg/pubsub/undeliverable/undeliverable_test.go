@@ -0,0 +1,224 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package undeliverable
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mock"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/stretchr/testify/require"
+
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/pubsub/mempubsub"
+	"github.com/trustbloc/orb/pkg/pubsub/spi"
+)
+
+const (
+	topic1 = "orb.topic1"
+	topic2 = "orb.topic2"
+)
+
+func TestService(t *testing.T) {
+	t.Run("List, requeue and drop", func(t *testing.T) {
+		ps := mempubsub.New(mempubsub.DefaultConfig())
+		defer func() { require.NoError(t, ps.Close()) }()
+
+		s, err := New(mem.NewProvider(), ps)
+		require.NoError(t, err)
+
+		s.Start()
+		defer s.Stop()
+
+		msg1 := newUndeliverableMessage(topic1, "3")
+		msg1.Metadata.Set("some-property", "some value")
+		msg2 := newUndeliverableMessage(topic1, "")
+		msg3 := newUndeliverableMessage(topic2, "2")
+
+		require.NoError(t, ps.Publish(spi.UndeliverableTopic, msg1))
+		require.NoError(t, ps.Publish(spi.UndeliverableTopic, msg2))
+		require.NoError(t, ps.Publish(spi.UndeliverableTopic, msg3))
+
+		require.Eventually(t, func() bool {
+			msgs, e := s.List("")
+			require.NoError(t, e)
+
+			return len(msgs) == 3
+		}, time.Second, 10*time.Millisecond)
+
+		msgs, err := s.List(topic1)
+		require.NoError(t, err)
+		require.Len(t, msgs, 2)
+
+		m := find(msgs, msg1.UUID)
+		require.NotNil(t, m)
+		require.Equal(t, topic1, m.Topic)
+		require.Equal(t, msg1.Payload, message.Payload(m.Payload))
+		require.Equal(t, 3, m.FailureCount)
+		require.Equal(t, "some value", m.Metadata["some-property"])
+		require.Empty(t, m.Metadata[spi.MetadataOriginalTopic])
+		require.Empty(t, m.Metadata[spi.MetadataDeliveryAttempts])
+
+		m = find(msgs, msg2.UUID)
+		require.NotNil(t, m)
+		require.Equal(t, 1, m.FailureCount)
+
+		topic1Chan, err := ps.Subscribe(context.Background(), topic1)
+		require.NoError(t, err)
+
+		result, err := s.Requeue("", msg1.UUID, "unknown")
+		require.NoError(t, err)
+		require.Equal(t, []string{msg1.UUID}, result.IDs)
+		require.Equal(t, []string{"unknown"}, result.NotFound)
+
+		select {
+		case m := <-topic1Chan:
+			require.Equal(t, msg1.UUID, m.UUID)
+			require.Equal(t, msg1.Payload, m.Payload)
+			require.Equal(t, "some value", m.Metadata.Get("some-property"))
+			require.Empty(t, m.Metadata.Get(spi.MetadataOriginalTopic))
+
+			m.Ack()
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for requeued message")
+		}
+
+		// The message is on a different topic.
+		result, err = s.Drop(topic2, msg2.UUID)
+		require.NoError(t, err)
+		require.Empty(t, result.IDs)
+		require.Equal(t, []string{msg2.UUID}, result.NotFound)
+
+		result, err = s.Drop(topic2)
+		require.NoError(t, err)
+		require.Equal(t, []string{msg3.UUID}, result.IDs)
+
+		msgs, err = s.List("")
+		require.NoError(t, err)
+		require.Len(t, msgs, 1)
+		require.Equal(t, msg2.UUID, msgs[0].ID)
+	})
+
+	t.Run("Failure count is accumulated", func(t *testing.T) {
+		ps := mempubsub.New(mempubsub.DefaultConfig())
+		defer func() { require.NoError(t, ps.Close()) }()
+
+		s, err := New(mem.NewProvider(), ps)
+		require.NoError(t, err)
+
+		msg := newUndeliverableMessage(topic1, "2")
+
+		s.handleMessage(msg)
+		s.handleMessage(msg)
+
+		msgs, err := s.List(topic1)
+		require.NoError(t, err)
+		require.Len(t, msgs, 1)
+		require.Equal(t, 4, msgs[0].FailureCount)
+	})
+
+	t.Run("No original topic -> dropped", func(t *testing.T) {
+		ps := mempubsub.New(mempubsub.DefaultConfig())
+		defer func() { require.NoError(t, ps.Close()) }()
+
+		s, err := New(mem.NewProvider(), ps)
+		require.NoError(t, err)
+
+		s.handleMessage(message.NewMessage(watermill.NewUUID(), []byte("payload")))
+
+		msgs, err := s.List("")
+		require.NoError(t, err)
+		require.Empty(t, msgs)
+	})
+
+	t.Run("Open store error", func(t *testing.T) {
+		errExpected := errors.New("injected open store error")
+
+		_, err := New(&mock.Provider{ErrOpenStore: errExpected}, mempubsub.New(mempubsub.DefaultConfig()))
+		require.True(t, errors.Is(err, errExpected))
+	})
+
+	t.Run("Subscribe error", func(t *testing.T) {
+		ps := mempubsub.New(mempubsub.DefaultConfig())
+		require.NoError(t, ps.Close())
+
+		_, err := New(mem.NewProvider(), ps)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "subscribe to topic")
+	})
+
+	t.Run("Store error", func(t *testing.T) {
+		errExpected := errors.New("injected store error")
+
+		ps := mempubsub.New(mempubsub.DefaultConfig())
+		defer func() { require.NoError(t, ps.Close()) }()
+
+		s, err := New(&mock.Provider{OpenStoreReturn: &mock.Store{
+			ErrGet:   storage.ErrDataNotFound,
+			ErrPut:   errExpected,
+			ErrQuery: errExpected,
+		}}, ps)
+		require.NoError(t, err)
+
+		require.True(t, orberrors.IsTransient(s.save(newUndeliverableMessage(topic1, "1"), topic1)))
+
+		_, err = s.List("")
+		require.True(t, errors.Is(err, errExpected))
+
+		_, err = s.Requeue("")
+		require.True(t, errors.Is(err, errExpected))
+	})
+
+	t.Run("Publish error", func(t *testing.T) {
+		ps := mempubsub.New(mempubsub.DefaultConfig())
+
+		s, err := New(mem.NewProvider(), ps)
+		require.NoError(t, err)
+
+		msg := newUndeliverableMessage(topic1, "1")
+
+		s.handleMessage(msg)
+
+		require.NoError(t, ps.Close())
+
+		result, err := s.Requeue(topic1, msg.UUID)
+		require.Error(t, err)
+		require.Empty(t, result.IDs)
+
+		// The message should still be in the store.
+		msgs, err := s.List(topic1)
+		require.NoError(t, err)
+		require.Len(t, msgs, 1)
+	})
+}
+
+func newUndeliverableMessage(topic, attempts string) *message.Message {
+	msg := message.NewMessage(watermill.NewUUID(), []byte("payload for "+topic))
+	msg.Metadata.Set(spi.MetadataOriginalTopic, topic)
+
+	if attempts != "" {
+		msg.Metadata.Set(spi.MetadataDeliveryAttempts, attempts)
+	}
+
+	return msg
+}
+
+func find(msgs []*Message, id string) *Message {
+	for _, m := range msgs {
+		if m.ID == id {
+			return m
+		}
+	}
+
+	return nil
+}
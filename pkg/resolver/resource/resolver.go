@@ -17,9 +17,9 @@ import (
 	"strings"
 	"time"
 
-	"github.com/bluele/gcache"
-
 	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/cache"
+	"github.com/trustbloc/orb/pkg/cache/memory"
 	"github.com/trustbloc/orb/pkg/cas/ipfs"
 	discoveryrest "github.com/trustbloc/orb/pkg/discovery/endpoint/restapi"
 )
@@ -27,6 +27,8 @@ import (
 const (
 	defaultCacheLifetime = 300 * time.Second // five minutes
 	defaultCacheSize     = 100
+
+	hostMetaDocCacheName = "host-meta-document"
 )
 
 var logger = log.New("resource-resolver")
@@ -43,7 +45,8 @@ type Resolver struct {
 
 	cacheLifetime    time.Duration
 	cacheSize        int
-	hostMetaDocCache gcache.Cache
+	cacheProvider    cache.Provider
+	hostMetaDocCache cache.Cache
 }
 
 // New returns a new Resolver.
@@ -61,11 +64,17 @@ func New(httpClient *http.Client, ipfsReader *ipfs.Client, domainResolver domain
 		opt(resolver)
 	}
 
-	resolver.hostMetaDocCache = gcache.New(resolver.cacheSize).
-		Expiration(resolver.cacheLifetime).
-		LoaderFunc(func(key interface{}) (interface{}, error) {
-			return resolver.resolveHostMetaLink(key.(string)) //nolint:forcetypeassert
-		}).Build()
+	if resolver.cacheProvider == nil {
+		resolver.cacheProvider = memory.New()
+	}
+
+	resolver.hostMetaDocCache = resolver.cacheProvider.NewCache(hostMetaDocCacheName,
+		func(key string) (interface{}, error) {
+			return resolver.resolveHostMetaLink(key)
+		},
+		cache.WithSize(resolver.cacheSize), cache.WithExpiration(resolver.cacheLifetime),
+		cache.WithCodec(cache.NewJSONCodec(func() interface{} { return &discoveryrest.JRD{} })),
+	)
 
 	return resolver
 }
@@ -233,3 +242,11 @@ func WithCacheSize(size int) Option {
 		opts.cacheSize = size
 	}
 }
+
+// WithCacheProvider option sets the provider of the host-meta document cache. If not set then
+// an in-memory cache is used.
+func WithCacheProvider(p cache.Provider) Option {
+	return func(opts *Resolver) {
+		opts.cacheProvider = p
+	}
+}
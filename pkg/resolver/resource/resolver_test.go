@@ -17,6 +17,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/trustbloc/orb/pkg/cache/memory"
 	"github.com/trustbloc/orb/pkg/cas/ipfs"
 	discoveryrest "github.com/trustbloc/orb/pkg/discovery/endpoint/restapi"
 	orbmocks "github.com/trustbloc/orb/pkg/mocks"
@@ -32,9 +33,10 @@ func TestNew(t *testing.T) {
 	})
 	t.Run("Success - with options", func(t *testing.T) {
 		resolver := New(http.DefaultClient, nil, &orbmocks.DomainResolver{},
-			WithCacheLifetime(2*time.Second), WithCacheSize(500))
+			WithCacheLifetime(2*time.Second), WithCacheSize(500), WithCacheProvider(memory.New()))
 		require.Equal(t, resolver.cacheLifetime, 2*time.Second)
 		require.Equal(t, resolver.cacheSize, 500)
+		require.NotNil(t, resolver.cacheProvider)
 	})
 }
 
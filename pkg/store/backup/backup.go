@@ -0,0 +1,547 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/store/migrate"
+)
+
+var logger = log.New("store-backup")
+
+const (
+	// ManifestVersion is the version of the backup archive format.
+	ManifestVersion = 1
+
+	manifestFileName = "manifest.json"
+	storesDir        = "stores/"
+	storeFileExt     = ".jsonl"
+
+	defaultBatchSize = 100
+)
+
+// ErrVerificationFailed indicates that the number of entries in the target database doesn't match the number
+// of entries in the backup after a restore.
+var ErrVerificationFailed = errors.New("verification failed")
+
+// Manifest describes the contents of a backup archive. It is the first file in the archive.
+type Manifest struct {
+	// Version is the version of the archive format.
+	Version int `json:"version"`
+	// CreatedAt is the time at which the snapshot was started. This is the point in time to which a node
+	// is restored.
+	CreatedAt time.Time `json:"createdAt"`
+	// Quiesced is true if the Orb server(s) were quiesced while the snapshot was taken.
+	Quiesced bool `json:"quiesced"`
+	// Namespaces contains the stores in the archive.
+	Namespaces []*NamespaceManifest `json:"namespaces"`
+}
+
+// NamespaceManifest describes a store in the backup archive.
+type NamespaceManifest struct {
+	// Name is the name of the store.
+	Name string `json:"name"`
+	// Count is the number of entries in the store.
+	Count int `json:"count"`
+	// SHA256 is the hex-encoded SHA-256 hash of the store's file in the archive.
+	SHA256 string `json:"sha256"`
+}
+
+// NamespaceReport contains the results of the restore of a namespace.
+type NamespaceReport struct {
+	// Name is the name of the namespace.
+	Name string `json:"name"`
+	// Restored is the number of entries that were written to the target database.
+	Restored int `json:"restored"`
+	// TargetCount is the number of entries in the target database after the restore.
+	TargetCount int `json:"targetCount"`
+	// Verified is true if the target count matches the count in the manifest.
+	Verified bool `json:"verified"`
+}
+
+// Report contains the results of a restore.
+type Report struct {
+	// CreatedAt is the time at which the restored snapshot was taken.
+	CreatedAt  time.Time          `json:"createdAt"`
+	Namespaces []*NamespaceReport `json:"namespaces"`
+}
+
+// record is the format of an entry in the archive. Each store is written to the archive as a file
+// containing one JSON record per line.
+type record struct {
+	Key   string        `json:"key"`
+	Value []byte        `json:"value"`
+	Tags  []storage.Tag `json:"tags,omitempty"`
+}
+
+type options struct {
+	batchSize  int
+	namespaces []*migrate.Namespace
+	quiesced   bool
+}
+
+// Option sets a backup or restore option.
+type Option func(opts *options)
+
+// WithNamespaces sets the namespaces to back up or restore. By default, all Orb namespaces are backed up and
+// all namespaces in the archive are restored.
+func WithNamespaces(namespaces ...*migrate.Namespace) Option {
+	return func(opts *options) {
+		opts.namespaces = namespaces
+	}
+}
+
+// WithBatchSize sets the number of entries that are written to the target database in a single batch
+// during a restore.
+func WithBatchSize(size int) Option {
+	return func(opts *options) {
+		opts.batchSize = size
+	}
+}
+
+// WithQuiesced indicates (in the manifest) that the Orb server(s) were quiesced while the snapshot was taken.
+func WithQuiesced() Option {
+	return func(opts *options) {
+		opts.quiesced = true
+	}
+}
+
+func resolveOptions(opts []Option) *options {
+	options := &options{batchSize: defaultBatchSize}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if options.batchSize <= 0 {
+		options.batchSize = defaultBatchSize
+	}
+
+	return options
+}
+
+// Backup writes a snapshot of all Orb stores (including the local CAS) in the source database to the given
+// writer as a gzipped tar archive. The archive contains a manifest (which includes the number of entries and
+// a hash of each store) followed by one file per store. The Orb server should be quiesced while the backup is
+// taken so that the stores are consistent with each other.
+func Backup(source migrate.Database, w io.Writer, opts ...Option) (*Manifest, error) {
+	options := resolveOptions(opts)
+
+	namespaces := options.namespaces
+	if len(namespaces) == 0 {
+		namespaces = migrate.Namespaces()
+	}
+
+	// The stores are first written to temporary files since the size of each file must be known
+	// before it's added to the archive.
+	dir, err := os.MkdirTemp("", "orb-backup-")
+	if err != nil {
+		return nil, fmt.Errorf("create temp dir: %w", err)
+	}
+
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			logger.Warn("Error removing temp dir", log.WithError(err))
+		}
+	}()
+
+	manifest := &Manifest{
+		Version:   ManifestVersion,
+		CreatedAt: time.Now().UTC(),
+		Quiesced:  options.quiesced,
+	}
+
+	for _, ns := range namespaces {
+		nsManifest, err := snapshotNamespace(source, ns, filepath.Join(dir, ns.Name+storeFileExt))
+		if err != nil {
+			return nil, fmt.Errorf("snapshot namespace [%s]: %w", ns.Name, err)
+		}
+
+		manifest.Namespaces = append(manifest.Namespaces, nsManifest)
+	}
+
+	if err := writeArchive(w, dir, manifest); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+func snapshotNamespace(source migrate.Database, ns *migrate.Namespace, path string) (*NamespaceManifest, error) {
+	logger.Info("Backing up namespace", log.WithStoreName(ns.Name))
+
+	f, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("create file: %w", err)
+	}
+
+	defer closeFile(f)
+
+	h := sha256.New()
+
+	encoder := json.NewEncoder(io.MultiWriter(f, h))
+
+	nsManifest := &NamespaceManifest{Name: ns.Name}
+
+	err = source.Scan(ns, "", func(entry *migrate.Entry) error {
+		if err := encoder.Encode(&record{Key: entry.Key, Value: entry.Value, Tags: entry.Tags}); err != nil {
+			return fmt.Errorf("write entry [%s]: %w", entry.Key, err)
+		}
+
+		nsManifest.Count++
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan: %w", err)
+	}
+
+	nsManifest.SHA256 = hex.EncodeToString(h.Sum(nil))
+
+	logger.Info("Backed up namespace", log.WithStoreName(ns.Name), log.WithTotal(nsManifest.Count))
+
+	return nsManifest, nil
+}
+
+func writeArchive(w io.Writer, dir string, manifest *Manifest) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal manifest: %w", err)
+	}
+
+	if err := tw.WriteHeader(newHeader(manifestFileName, int64(len(manifestBytes)), manifest.CreatedAt)); err != nil {
+		return fmt.Errorf("write manifest header: %w", err)
+	}
+
+	if _, err := tw.Write(manifestBytes); err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+
+	for _, ns := range manifest.Namespaces {
+		if err := addFile(tw, filepath.Join(dir, ns.Name+storeFileExt),
+			storesDir+ns.Name+storeFileExt, manifest.CreatedAt); err != nil {
+			return fmt.Errorf("add namespace [%s] to archive: %w", ns.Name, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("close archive: %w", err)
+	}
+
+	if err := gw.Close(); err != nil {
+		return fmt.Errorf("close archive: %w", err)
+	}
+
+	return nil
+}
+
+func addFile(tw *tar.Writer, path, name string, modTime time.Time) error {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return fmt.Errorf("open file: %w", err)
+	}
+
+	defer closeFile(f)
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("stat file: %w", err)
+	}
+
+	if err := tw.WriteHeader(newHeader(name, info.Size(), modTime)); err != nil {
+		return fmt.Errorf("write header: %w", err)
+	}
+
+	if _, err := io.Copy(tw, f); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+
+	return nil
+}
+
+func newHeader(name string, size int64, modTime time.Time) *tar.Header {
+	return &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     size,
+		Mode:     0o600, //nolint:gomnd
+		ModTime:  modTime,
+	}
+}
+
+// Restore writes the stores in the given backup archive to the target database. Each store is opened in the same
+// way as Orb opens it so that the indexes for the store's tags are rebuilt. The hash and number of entries of
+// each store are checked against the manifest and, after all stores are restored, the number of entries in the
+// target database is verified. ErrVerificationFailed is returned (along with the report) if the counts don't match,
+// which is the case if the target database wasn't empty. Since the activity-sync store is also restored, anchor
+// synchronization resumes from the point at which the snapshot was taken when the Orb server is started.
+func Restore(r io.Reader, target migrate.Database, opts ...Option) (*Report, error) {
+	options := resolveOptions(opts)
+
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("open archive: %w", err)
+	}
+
+	tr := tar.NewReader(gr)
+
+	manifest, err := readManifest(tr)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Restoring backup", log.WithCreatedTime(manifest.CreatedAt), log.WithTotal(len(manifest.Namespaces)))
+
+	restored := make(map[string]*NamespaceReport)
+
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, fmt.Errorf("read archive: %w", err)
+		}
+
+		nsManifest, ns, err := getNamespace(manifest, hdr.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if !shouldRestore(ns, options.namespaces) {
+			logger.Debug("Skipping namespace", log.WithStoreName(ns.Name))
+
+			continue
+		}
+
+		nsReport, err := restoreNamespace(tr, target, ns, nsManifest, options.batchSize)
+		if err != nil {
+			return nil, fmt.Errorf("restore namespace [%s]: %w", ns.Name, err)
+		}
+
+		restored[ns.Name] = nsReport
+	}
+
+	return verify(target, manifest, restored)
+}
+
+func readManifest(tr *tar.Reader) (*Manifest, error) {
+	hdr, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+
+	if hdr.Name != manifestFileName {
+		return nil, fmt.Errorf("expecting %s as the first file in the archive but got %s", manifestFileName, hdr.Name)
+	}
+
+	manifest := &Manifest{}
+
+	if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+		return nil, fmt.Errorf("unmarshal manifest: %w", err)
+	}
+
+	if manifest.Version != ManifestVersion {
+		return nil, fmt.Errorf("unsupported backup version: %d", manifest.Version)
+	}
+
+	return manifest, nil
+}
+
+func getNamespace(manifest *Manifest, fileName string) (*NamespaceManifest, *migrate.Namespace, error) {
+	if !strings.HasPrefix(fileName, storesDir) || !strings.HasSuffix(fileName, storeFileExt) {
+		return nil, nil, fmt.Errorf("unexpected file in archive: %s", fileName)
+	}
+
+	name := strings.TrimSuffix(strings.TrimPrefix(fileName, storesDir), storeFileExt)
+
+	ns, ok := migrate.FindNamespace(name)
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown namespace in archive: %s", name)
+	}
+
+	for _, nsManifest := range manifest.Namespaces {
+		if nsManifest.Name == name {
+			return nsManifest, ns, nil
+		}
+	}
+
+	return nil, nil, fmt.Errorf("namespace [%s] is not in the manifest", name)
+}
+
+func shouldRestore(ns *migrate.Namespace, namespaces []*migrate.Namespace) bool {
+	if len(namespaces) == 0 {
+		return true
+	}
+
+	for _, n := range namespaces {
+		if n.Name == ns.Name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func restoreNamespace(r io.Reader, target migrate.Database, ns *migrate.Namespace,
+	nsManifest *NamespaceManifest, batchSize int) (*NamespaceReport, error) {
+	logger.Info("Restoring namespace", log.WithStoreName(ns.Name), log.WithTotal(nsManifest.Count))
+
+	s, err := ns.Open(target)
+	if err != nil {
+		return nil, err
+	}
+
+	h := sha256.New()
+
+	w := &batchWriter{store: s, batchSize: batchSize}
+
+	decoder := json.NewDecoder(io.TeeReader(r, h))
+
+	for {
+		rec := &record{}
+
+		if err := decoder.Decode(rec); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, fmt.Errorf("read entry: %w", err)
+		}
+
+		if err := w.add(storage.Operation{Key: rec.Key, Value: rec.Value, Tags: rec.Tags}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := w.flush(); err != nil {
+		return nil, err
+	}
+
+	if err := s.Flush(); err != nil {
+		return nil, fmt.Errorf("flush target: %w", err)
+	}
+
+	if err := checkIntegrity(nsManifest, h, w.total); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Restored namespace", log.WithStoreName(ns.Name), log.WithTotal(w.total))
+
+	return &NamespaceReport{Name: ns.Name, Restored: w.total}, nil
+}
+
+func checkIntegrity(nsManifest *NamespaceManifest, h hash.Hash, count int) error {
+	if hashStr := hex.EncodeToString(h.Sum(nil)); hashStr != nsManifest.SHA256 {
+		return fmt.Errorf("hash of archived store [%s] doesn't match the hash in the manifest [%s]",
+			hashStr, nsManifest.SHA256)
+	}
+
+	if count != nsManifest.Count {
+		return fmt.Errorf("number of archived entries [%d] doesn't match the count in the manifest [%d]",
+			count, nsManifest.Count)
+	}
+
+	return nil
+}
+
+func verify(target migrate.Database, manifest *Manifest, restored map[string]*NamespaceReport) (*Report, error) {
+	report := &Report{CreatedAt: manifest.CreatedAt}
+
+	var failed []string
+
+	for _, nsManifest := range manifest.Namespaces {
+		nsReport, ok := restored[nsManifest.Name]
+		if !ok {
+			continue
+		}
+
+		ns, _ := migrate.FindNamespace(nsManifest.Name) //nolint:errcheck
+
+		count, err := target.Count(ns)
+		if err != nil {
+			return nil, fmt.Errorf("count target entries for namespace [%s]: %w", ns.Name, err)
+		}
+
+		nsReport.TargetCount = count
+		nsReport.Verified = count == nsManifest.Count
+
+		if !nsReport.Verified {
+			logger.Warn("Entry count mismatch after restore", log.WithStoreName(ns.Name),
+				log.WithTotal(nsManifest.Count), log.WithSize(count))
+
+			failed = append(failed, ns.Name)
+		}
+
+		report.Namespaces = append(report.Namespaces, nsReport)
+	}
+
+	if len(failed) > 0 {
+		return report, fmt.Errorf("%w: entry counts don't match for namespaces %s",
+			ErrVerificationFailed, strings.Join(failed, ", "))
+	}
+
+	return report, nil
+}
+
+type batchWriter struct {
+	store     storage.Store
+	batchSize int
+	batch     []storage.Operation
+	total     int
+}
+
+func (w *batchWriter) add(op storage.Operation) error {
+	w.batch = append(w.batch, op)
+
+	if len(w.batch) < w.batchSize {
+		return nil
+	}
+
+	return w.flush()
+}
+
+func (w *batchWriter) flush() error {
+	if len(w.batch) == 0 {
+		return nil
+	}
+
+	if err := w.store.Batch(w.batch); err != nil {
+		return fmt.Errorf("write batch: %w", err)
+	}
+
+	w.total += len(w.batch)
+	w.batch = nil
+
+	return nil
+}
+
+func closeFile(f *os.File) {
+	if err := f.Close(); err != nil {
+		logger.Warn("Error closing file", log.WithError(err))
+	}
+}
@@ -0,0 +1,478 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/store/encrypted"
+	"github.com/trustbloc/orb/pkg/store/migrate"
+)
+
+func TestBackupRestore(t *testing.T) {
+	opNS, ok := migrate.FindNamespace("operation")
+	require.True(t, ok)
+
+	casNS, ok := migrate.FindNamespace("cas")
+	require.True(t, ok)
+
+	syncNS, ok := migrate.FindNamespace("activity-sync")
+	require.True(t, ok)
+
+	source := newTestDatabase()
+
+	populate(t, source, opNS, 25, "uniqueSuffix")
+	populate(t, source, casNS, 3, "")
+	populate(t, source, syncNS, 1, "")
+
+	archive := &bytes.Buffer{}
+
+	manifest, err := Backup(source, archive, WithNamespaces(opNS, casNS, syncNS), WithQuiesced())
+	require.NoError(t, err)
+	require.Equal(t, ManifestVersion, manifest.Version)
+	require.True(t, manifest.Quiesced)
+	require.Len(t, manifest.Namespaces, 3)
+	require.Equal(t, 25, manifest.Namespaces[0].Count)
+	require.Equal(t, 3, manifest.Namespaces[1].Count)
+	require.NotEmpty(t, manifest.Namespaces[0].SHA256)
+
+	t.Run("Restore all", func(t *testing.T) {
+		target := newTestDatabase()
+
+		report, err := Restore(bytes.NewReader(archive.Bytes()), target, WithBatchSize(10))
+		require.NoError(t, err)
+		require.Equal(t, manifest.CreatedAt.Unix(), report.CreatedAt.Unix())
+		require.Len(t, report.Namespaces, 3)
+		require.Equal(t, &NamespaceReport{Name: "operation", Restored: 25, TargetCount: 25, Verified: true},
+			report.Namespaces[0])
+
+		s, err := target.OpenStore(opNS.Name)
+		require.NoError(t, err)
+
+		value, err := s.Get("key-07")
+		require.NoError(t, err)
+		require.Equal(t, `{"value":7}`, string(value))
+
+		tags, err := s.GetTags("key-07")
+		require.NoError(t, err)
+		require.Equal(t, []storage.Tag{{Name: "uniqueSuffix", Value: "value-7"}}, tags)
+
+		// The indexes are rebuilt.
+		config, err := target.GetStoreConfig(opNS.Name)
+		require.NoError(t, err)
+		require.Equal(t, []string{"uniqueSuffix"}, config.TagNames)
+
+		s, err = target.OpenStore(syncNS.Name)
+		require.NoError(t, err)
+
+		_, err = s.Get("key-00")
+		require.NoError(t, err)
+	})
+
+	t.Run("Restore selected namespaces", func(t *testing.T) {
+		target := newTestDatabase()
+
+		report, err := Restore(bytes.NewReader(archive.Bytes()), target, WithNamespaces(casNS))
+		require.NoError(t, err)
+		require.Len(t, report.Namespaces, 1)
+		require.Equal(t, "cas", report.Namespaces[0].Name)
+
+		count, err := target.Count(opNS)
+		require.NoError(t, err)
+		require.Zero(t, count)
+	})
+
+	t.Run("Target not empty", func(t *testing.T) {
+		target := newTestDatabase()
+
+		populate(t, target, casNS, 5, "")
+
+		report, err := Restore(bytes.NewReader(archive.Bytes()), target)
+		require.True(t, errors.Is(err, ErrVerificationFailed))
+		require.Contains(t, err.Error(), "entry counts don't match for namespaces cas")
+		require.NotNil(t, report)
+		require.True(t, report.Namespaces[0].Verified)
+		require.False(t, report.Namespaces[1].Verified)
+		require.Equal(t, 5, report.Namespaces[1].TargetCount)
+	})
+
+	t.Run("Hash mismatch", func(t *testing.T) {
+		m := *manifest
+		m.Namespaces = []*NamespaceManifest{{Name: "cas", Count: 1, SHA256: "xxx"}}
+
+		_, err := Restore(newArchive(t, &m, map[string]string{"stores/cas.jsonl": `{"key":"k1","value":"MTIz"}`}),
+			newTestDatabase())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "restore namespace [cas]: hash of archived store")
+	})
+
+	t.Run("Count mismatch", func(t *testing.T) {
+		content := `{"key":"k1","value":"MTIz"}` + "\n"
+
+		m := *manifest
+		m.Namespaces = []*NamespaceManifest{{Name: "cas", Count: 2, SHA256: sha256Hex(content)}}
+
+		_, err := Restore(newArchive(t, &m, map[string]string{"stores/cas.jsonl": content}), newTestDatabase())
+		require.Error(t, err)
+		require.Contains(t, err.Error(),
+			"number of archived entries [1] doesn't match the count in the manifest [2]")
+	})
+}
+
+func TestBackupRestore_Encrypted(t *testing.T) {
+	configNS, ok := migrate.FindNamespace("orb-config")
+	require.True(t, ok)
+
+	keysNS, ok := migrate.FindNamespace("orb-encryption-keys")
+	require.True(t, ok)
+
+	kw := &testKeyWrapper{}
+
+	source := newTestDatabase()
+
+	ep, err := encrypted.New(&pingableDatabase{source}, kw, "kek1")
+	require.NoError(t, err)
+
+	s, err := ep.OpenStore(configNS.Name)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Put("key1", []byte("value1")))
+
+	archive := &bytes.Buffer{}
+
+	_, err = Backup(source, archive, WithNamespaces(configNS, keysNS))
+	require.NoError(t, err)
+
+	target := newTestDatabase()
+
+	_, err = Restore(bytes.NewReader(archive.Bytes()), target)
+	require.NoError(t, err)
+
+	// The value is restored encrypted.
+	us, err := target.OpenStore(configNS.Name)
+	require.NoError(t, err)
+
+	raw, err := us.Get("key1")
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), "value1")
+
+	// The data keys were restored so the value may be decrypted.
+	ep, err = encrypted.New(&pingableDatabase{target}, kw, "kek1")
+	require.NoError(t, err)
+
+	s, err = ep.OpenStore(configNS.Name)
+	require.NoError(t, err)
+
+	value, err := s.Get("key1")
+	require.NoError(t, err)
+	require.Equal(t, "value1", string(value))
+}
+
+func TestBackup_Error(t *testing.T) {
+	opNS, ok := migrate.FindNamespace("operation")
+	require.True(t, ok)
+
+	source := newTestDatabase()
+	source.scanErr = errors.New("injected scan error")
+
+	_, err := Backup(source, &bytes.Buffer{}, WithNamespaces(opNS))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "snapshot namespace [operation]: scan: injected scan error")
+}
+
+func TestRestore_Error(t *testing.T) {
+	manifest := &Manifest{
+		Version:    ManifestVersion,
+		Namespaces: []*NamespaceManifest{{Name: "cas", Count: 0, SHA256: sha256Hex("")}},
+	}
+
+	t.Run("Not an archive", func(t *testing.T) {
+		_, err := Restore(bytes.NewReader([]byte("xxx")), newTestDatabase())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "open archive")
+	})
+
+	t.Run("No manifest", func(t *testing.T) {
+		_, err := Restore(newArchive(t, nil, map[string]string{"stores/cas.jsonl": ""}), newTestDatabase())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "expecting manifest.json as the first file in the archive")
+	})
+
+	t.Run("Unsupported version", func(t *testing.T) {
+		_, err := Restore(newArchive(t, &Manifest{Version: 2}, nil), newTestDatabase())
+		require.EqualError(t, err, "unsupported backup version: 2")
+	})
+
+	t.Run("Unexpected file", func(t *testing.T) {
+		_, err := Restore(newArchive(t, manifest, map[string]string{"xxx.txt": ""}), newTestDatabase())
+		require.EqualError(t, err, "unexpected file in archive: xxx.txt")
+	})
+
+	t.Run("Unknown namespace", func(t *testing.T) {
+		_, err := Restore(newArchive(t, manifest, map[string]string{"stores/xxx.jsonl": ""}), newTestDatabase())
+		require.EqualError(t, err, "unknown namespace in archive: xxx")
+	})
+
+	t.Run("Namespace not in manifest", func(t *testing.T) {
+		_, err := Restore(newArchive(t, manifest, map[string]string{"stores/operation.jsonl": ""}),
+			newTestDatabase())
+		require.EqualError(t, err, "namespace [operation] is not in the manifest")
+	})
+
+	t.Run("Invalid entry", func(t *testing.T) {
+		_, err := Restore(newArchive(t, manifest, map[string]string{"stores/cas.jsonl": "{"}), newTestDatabase())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "restore namespace [cas]: read entry")
+	})
+
+	t.Run("Write batch error", func(t *testing.T) {
+		target := newTestDatabase()
+		target.batchErr = errors.New("injected batch error")
+
+		_, err := Restore(newArchive(t, manifest, map[string]string{"stores/cas.jsonl": `{"key":"k1"}`}), target)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "write batch: injected batch error")
+	})
+
+	t.Run("Count error", func(t *testing.T) {
+		target := newTestDatabase()
+		target.countErr = errors.New("injected count error")
+
+		_, err := Restore(newArchive(t, manifest, map[string]string{"stores/cas.jsonl": ""}), target)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "count target entries for namespace [cas]: injected count error")
+	})
+}
+
+func newArchive(t *testing.T, manifest *Manifest, files map[string]string) io.Reader {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+
+	write := func(name string, content []byte) {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg, Name: name, Size: int64(len(content)), Mode: 0o600,
+		}))
+
+		_, err := tw.Write(content)
+		require.NoError(t, err)
+	}
+
+	if manifest != nil {
+		manifestBytes, err := json.Marshal(manifest)
+		require.NoError(t, err)
+
+		write(manifestFileName, manifestBytes)
+	}
+
+	for name, content := range files {
+		write(name, []byte(content))
+	}
+
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+
+	return buf
+}
+
+func sha256Hex(content string) string {
+	h := sha256.Sum256([]byte(content))
+
+	return hex.EncodeToString(h[:])
+}
+
+func populate(t *testing.T, db *testDatabase, ns *migrate.Namespace, n int, tagName string) {
+	t.Helper()
+
+	s, err := db.OpenStore(ns.Name)
+	require.NoError(t, err)
+
+	for i := 0; i < n; i++ {
+		var tags []storage.Tag
+		if tagName != "" {
+			tags = []storage.Tag{{Name: tagName, Value: fmt.Sprintf("value-%d", i)}}
+		}
+
+		require.NoError(t, s.Put(fmt.Sprintf("key-%02d", i), []byte(fmt.Sprintf(`{"value":%d}`, i)), tags...))
+	}
+}
+
+// testDatabase is an in-memory database which keeps track of the keys in each store so that the stores may be scanned.
+type testDatabase struct {
+	storage.Provider
+
+	mutex    sync.Mutex
+	keys     map[string]map[string]struct{}
+	batchErr error
+	countErr error
+	scanErr  error
+}
+
+func newTestDatabase() *testDatabase {
+	return &testDatabase{
+		Provider: mem.NewProvider(),
+		keys:     make(map[string]map[string]struct{}),
+	}
+}
+
+func (db *testDatabase) OpenStore(name string) (storage.Store, error) {
+	s, err := db.Provider.OpenStore(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &testStore{Store: s, db: db, name: name}, nil
+}
+
+func (db *testDatabase) Scan(ns *migrate.Namespace, afterKey string, handle func(entry *migrate.Entry) error) error {
+	if db.scanErr != nil {
+		return db.scanErr
+	}
+
+	s, err := db.Provider.OpenStore(ns.Name)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range db.sortedKeys(ns.Name) {
+		if key <= afterKey {
+			continue
+		}
+
+		value, err := s.Get(key)
+		if err != nil {
+			return err
+		}
+
+		tags, err := s.GetTags(key)
+		if err != nil {
+			return err
+		}
+
+		if err := handle(&migrate.Entry{Key: key, Value: value, Tags: tags}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (db *testDatabase) Count(ns *migrate.Namespace) (int, error) {
+	if db.countErr != nil {
+		return 0, db.countErr
+	}
+
+	return len(db.sortedKeys(ns.Name)), nil
+}
+
+func (db *testDatabase) sortedKeys(name string) []string {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	var keys []string
+
+	for key := range db.keys[name] {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (db *testDatabase) addKey(name, key string) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	keys, ok := db.keys[name]
+	if !ok {
+		keys = make(map[string]struct{})
+		db.keys[name] = keys
+	}
+
+	keys[key] = struct{}{}
+}
+
+type testStore struct {
+	storage.Store
+
+	db   *testDatabase
+	name string
+}
+
+func (s *testStore) Put(key string, value []byte, tags ...storage.Tag) error {
+	if err := s.Store.Put(key, value, tags...); err != nil {
+		return err
+	}
+
+	s.db.addKey(s.name, key)
+
+	return nil
+}
+
+func (s *testStore) Batch(operations []storage.Operation) error {
+	if s.db.batchErr != nil {
+		return s.db.batchErr
+	}
+
+	if err := s.Store.Batch(operations); err != nil {
+		return err
+	}
+
+	for _, op := range operations {
+		s.db.addKey(s.name, op.Key)
+	}
+
+	return nil
+}
+
+type pingableDatabase struct {
+	*testDatabase
+}
+
+func (db *pingableDatabase) Ping() error {
+	return nil
+}
+
+// testKeyWrapper "wraps" a data key by reversing it.
+type testKeyWrapper struct{}
+
+func (kw *testKeyWrapper) WrapKey(_ string, key []byte) ([]byte, error) {
+	return reverse(key), nil
+}
+
+func (kw *testKeyWrapper) UnwrapKey(_ string, wrappedKey []byte) ([]byte, error) {
+	return reverse(wrappedKey), nil
+}
+
+func reverse(b []byte) []byte {
+	r := make([]byte, len(b))
+
+	for i := range b {
+		r[len(b)-1-i] = b[i]
+	}
+
+	return r
+}
@@ -0,0 +1,56 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/trustbloc/orb/pkg/store/migrate"
+)
+
+const operationQueueStoreName = "operation-queue"
+
+//nolint:tagliatelle
+type operationQueueTask struct {
+	TaskID      string `json:"taskID"`
+	UpdatedTime int64  `json:"updatedTime"`
+}
+
+// ActiveInstances returns the IDs of the server instances that are currently using the given database. Each
+// server instance periodically updates its operation queue task in the database, so an instance is considered
+// to be active if its task was updated within the given maximum age.
+func ActiveInstances(source migrate.Database, maxAge time.Duration) ([]string, error) {
+	ns, ok := migrate.FindNamespace(operationQueueStoreName)
+	if !ok {
+		return nil, fmt.Errorf("namespace not found: %s", operationQueueStoreName)
+	}
+
+	minUpdatedTime := time.Now().Add(-maxAge).Unix()
+
+	var instanceIDs []string
+
+	err := source.Scan(ns, "", func(entry *migrate.Entry) error {
+		task := &operationQueueTask{}
+
+		if err := json.Unmarshal(entry.Value, task); err != nil {
+			return fmt.Errorf("unmarshal operation queue task [%s]: %w", entry.Key, err)
+		}
+
+		if task.TaskID != "" && task.UpdatedTime >= minUpdatedTime {
+			instanceIDs = append(instanceIDs, task.TaskID)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan operation queue tasks: %w", err)
+	}
+
+	return instanceIDs, nil
+}
@@ -0,0 +1,65 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestActiveInstances(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		db := newTestDatabase()
+
+		s, err := db.OpenStore(operationQueueStoreName)
+		require.NoError(t, err)
+
+		for id, updated := range map[string]time.Time{
+			"instance1": time.Now(),
+			"instance2": time.Now().Add(-10 * time.Second),
+			"instance3": time.Now().Add(-time.Hour),
+		} {
+			require.NoError(t, s.Put(id, []byte(fmt.Sprintf(`{"taskID":"%s","updatedTime":%d}`, id, updated.Unix()))))
+		}
+
+		instanceIDs, err := ActiveInstances(db, time.Minute)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"instance1", "instance2"}, instanceIDs)
+	})
+
+	t.Run("No instances", func(t *testing.T) {
+		instanceIDs, err := ActiveInstances(newTestDatabase(), time.Minute)
+		require.NoError(t, err)
+		require.Empty(t, instanceIDs)
+	})
+
+	t.Run("Scan error", func(t *testing.T) {
+		db := newTestDatabase()
+		db.scanErr = errors.New("injected scan error")
+
+		_, err := ActiveInstances(db, time.Minute)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected scan error")
+	})
+
+	t.Run("Unmarshal error", func(t *testing.T) {
+		db := newTestDatabase()
+
+		s, err := db.OpenStore(operationQueueStoreName)
+		require.NoError(t, err)
+
+		require.NoError(t, s.Put("instance1", []byte("{")))
+
+		_, err = ActiveInstances(db, time.Minute)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal operation queue task")
+	})
+}
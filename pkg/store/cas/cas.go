@@ -9,10 +9,15 @@ package cas
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/bluele/gcache"
+	"github.com/hyperledger/aries-framework-go-ext/component/storage/mongodb"
 	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	mongoopts "go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/trustbloc/orb/internal/pkg/log"
 	"github.com/trustbloc/orb/pkg/cas/extendedcasclient"
@@ -26,8 +31,23 @@ var logger = log.New("cas-store")
 const (
 	defaultCacheSize = 1000
 	casType          = "local"
+	namespace        = "cas"
+
+	// createdTimeTagName is the tag that holds the time (Unix seconds) at which content was last written. It
+	// allows the content of the store to be enumerated (e.g. by the garbage collector).
+	createdTimeTagName = "createdTime"
+
+	// keysPageSize is the maximum number of keys that are passed to the handler of GetKeys at a time.
+	keysPageSize = 1000
 )
 
+// mongoDBStore is implemented by a MongoDB store. It is used to find content that was written without
+// a creation time, which may not be done using a tag query.
+type mongoDBStore interface {
+	QueryCustom(filter interface{}, options ...*mongoopts.FindOptions) (mongodb.Iterator, error)
+	BulkWrite(models []mongo.WriteModel, opts ...*mongoopts.BulkWriteOptions) error
+}
+
 type metricsProvider interface {
 	CASIncrementCacheHitCount()
 	CASReadTime(casType string, value time.Duration)
@@ -50,11 +70,16 @@ type CAS struct {
 // If no CID version is specified, then v1 will be used by default.
 func New(provider ariesstorage.Provider, casLink string, ipfsClient *ipfs.Client, metrics metricsProvider,
 	cacheSize int, opts ...extendedcasclient.CIDFormatOption) (*CAS, error) {
-	cas, err := provider.OpenStore("cas")
+	cas, err := provider.OpenStore(namespace)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open store in underlying storage provider: %w", err)
 	}
 
+	err = provider.SetStoreConfig(namespace, ariesstorage.StoreConfiguration{TagNames: []string{createdTimeTagName}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set store configuration in underlying storage provider: %w", err)
+	}
+
 	if cacheSize == 0 {
 		cacheSize = defaultCacheSize
 	}
@@ -91,6 +116,15 @@ func (p *CAS) Write(content []byte) (string, error) {
 	return p.WriteWithCIDFormat(content, p.opts...)
 }
 
+// WithCIDFormat returns a copy of this CAS whose Write method uses the given CID format options. The copy
+// shares the underlying store and cache with this CAS.
+func (p *CAS) WithCIDFormat(opts ...extendedcasclient.CIDFormatOption) extendedcasclient.Client {
+	c := *p
+	c.opts = append(append([]extendedcasclient.CIDFormatOption{}, p.opts...), opts...)
+
+	return &c
+}
+
 // WriteWithCIDFormat writes the given content to the underlying local CAS provider (and IPFS if configured) using the
 // CID format specified by opts.
 // Returns the address of the content.
@@ -101,7 +135,12 @@ func (p *CAS) WriteWithCIDFormat(content []byte, opts ...extendedcasclient.CIDFo
 		return "", errors.New("empty content")
 	}
 
-	resourceHash, err := p.hl.CreateResourceHash(content)
+	// The given options override the default options of this CAS.
+	opts = append(append([]extendedcasclient.CIDFormatOption{}, p.opts...), opts...)
+
+	resourceHash, err := hashlink.New(
+		hashlink.WithMultihashCode(extendedcasclient.MultihashCode(opts...)),
+	).CreateResourceHash(content)
 	if err != nil {
 		return "", fmt.Errorf("failed to create resource hash from content: %w", err)
 	}
@@ -109,7 +148,10 @@ func (p *CAS) WriteWithCIDFormat(content []byte, opts ...extendedcasclient.CIDFo
 	logger.Debug("Writing to CAS store. Content (base64-encoded)",
 		log.WithHash(resourceHash), log.WithCASData(content))
 
-	err = p.cas.Put(resourceHash, content)
+	err = p.cas.Put(resourceHash, content, ariesstorage.Tag{
+		Name:  createdTimeTagName,
+		Value: strconv.FormatInt(time.Now().Unix(), 10),
+	})
 	if err != nil {
 		return "", orberrors.NewTransient(fmt.Errorf("failed to put content into underlying storage provider: %w", err))
 	}
@@ -179,3 +221,181 @@ func (p *CAS) get(address string) ([]byte, error) {
 
 	return content, nil
 }
+
+// GetKeys invokes the given handler with the addresses (resource hashes) of all content that was written before
+// the given time. The addresses are passed to the handler in pages of at most 1000 addresses. Content that was
+// written without a creation time (i.e. before creation times were recorded) is first given a creation time of
+// zero so that it's treated as older than any given time. This is only supported for MongoDB, since other
+// databases have no means of finding content without a creation time.
+func (p *CAS) GetKeys(createdBefore time.Time, handle func(keys []string) error) error {
+	if err := p.backfillCreatedTime(); err != nil {
+		return err
+	}
+
+	iterator, err := p.cas.Query(createdTimeTagName, ariesstorage.WithPageSize(keysPageSize))
+	if err != nil {
+		return orberrors.NewTransient(fmt.Errorf("failed to query the local CAS provider: %w", err))
+	}
+
+	defer func() {
+		if errClose := iterator.Close(); errClose != nil {
+			log.CloseIteratorError(logger, errClose)
+		}
+	}()
+
+	var keys []string
+
+	for {
+		ok, err := iterator.Next()
+		if err != nil {
+			return orberrors.NewTransient(fmt.Errorf("failed to determine if there are more results: %w", err))
+		}
+
+		if !ok {
+			break
+		}
+
+		key, err := iterator.Key()
+		if err != nil {
+			return orberrors.NewTransient(fmt.Errorf("failed to get key: %w", err))
+		}
+
+		tags, err := iterator.Tags()
+		if err != nil {
+			return orberrors.NewTransient(fmt.Errorf("failed to get tags: %w", err))
+		}
+
+		if !isCreatedBefore(tags, createdBefore) {
+			continue
+		}
+
+		keys = append(keys, key)
+
+		if len(keys) == keysPageSize {
+			if err := handle(keys); err != nil {
+				return err
+			}
+
+			keys = nil
+		}
+	}
+
+	if len(keys) > 0 {
+		return handle(keys)
+	}
+
+	return nil
+}
+
+// backfillCreatedTime sets a creation time of zero on all content that was written without a creation time.
+func (p *CAS) backfillCreatedTime() error {
+	ms, ok := p.cas.(mongoDBStore)
+	if !ok {
+		return nil
+	}
+
+	filter := bson.D{{Key: "tags." + createdTimeTagName, Value: bson.D{{Key: "$exists", Value: false}}}}
+
+	for {
+		keys, err := p.getKeysWithoutCreatedTime(ms, filter)
+		if err != nil {
+			return err
+		}
+
+		if len(keys) == 0 {
+			return nil
+		}
+
+		models := make([]mongo.WriteModel, len(keys))
+
+		for i, key := range keys {
+			models[i] = mongo.NewUpdateOneModel().
+				SetFilter(bson.D{{Key: "_id", Value: key}}).
+				SetUpdate(bson.D{{Key: "$set", Value: bson.D{{Key: "tags." + createdTimeTagName, Value: 0}}}})
+		}
+
+		if err := ms.BulkWrite(models); err != nil {
+			return orberrors.NewTransient(fmt.Errorf("failed to set created time in the local CAS provider: %w", err))
+		}
+
+		logger.Info("Set created time on content that was written without one", log.WithTotal(len(keys)))
+	}
+}
+
+func (p *CAS) getKeysWithoutCreatedTime(ms mongoDBStore, filter bson.D) ([]string, error) {
+	iterator, err := ms.QueryCustom(filter, mongoopts.Find().SetLimit(keysPageSize).
+		SetProjection(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("failed to query the local CAS provider: %w", err))
+	}
+
+	defer func() {
+		if errClose := iterator.Close(); errClose != nil {
+			log.CloseIteratorError(logger, errClose)
+		}
+	}()
+
+	var keys []string
+
+	for {
+		ok, err := iterator.Next()
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("failed to determine if there are more results: %w", err))
+		}
+
+		if !ok {
+			return keys, nil
+		}
+
+		key, err := iterator.Key()
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("failed to get key: %w", err))
+		}
+
+		keys = append(keys, key)
+	}
+}
+
+// Delete deletes the content at the given addresses (resource hashes) from the local CAS provider.
+func (p *CAS) Delete(addresses ...string) error {
+	if len(addresses) == 0 {
+		return nil
+	}
+
+	operations := make([]ariesstorage.Operation, len(addresses))
+
+	for i, address := range addresses {
+		operations[i] = ariesstorage.Operation{Key: address}
+	}
+
+	if err := p.cas.Batch(operations); err != nil {
+		return orberrors.NewTransient(fmt.Errorf("failed to delete content from the local CAS provider: %w", err))
+	}
+
+	for _, address := range addresses {
+		p.cache.Remove(address)
+	}
+
+	logger.Debug("Deleted content from CAS store", log.WithTotal(len(addresses)))
+
+	return nil
+}
+
+func isCreatedBefore(tags []ariesstorage.Tag, t time.Time) bool {
+	for _, tag := range tags {
+		if tag.Name != createdTimeTagName {
+			continue
+		}
+
+		created, err := strconv.ParseInt(tag.Value, 10, 64)
+		if err != nil {
+			logger.Warn("Invalid value for created time tag", log.WithValue(tag.Value), log.WithError(err))
+
+			return false
+		}
+
+		return created < t.Unix()
+	}
+
+	return false
+}
@@ -8,10 +8,12 @@ package cas_test
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
+	ariesmongodbstorage "github.com/hyperledger/aries-framework-go-ext/component/storage/mongodb"
 	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
 	ariesmockstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mock"
 	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
@@ -24,6 +26,7 @@ import (
 	"github.com/trustbloc/orb/pkg/cas/ipfs"
 	orberrors "github.com/trustbloc/orb/pkg/errors"
 	"github.com/trustbloc/orb/pkg/hashlink"
+	"github.com/trustbloc/orb/pkg/internal/testutil/mongodbtestutil"
 	orbmocks "github.com/trustbloc/orb/pkg/mocks"
 	localcas "github.com/trustbloc/orb/pkg/store/cas"
 )
@@ -45,6 +48,13 @@ func TestNew(t *testing.T) {
 		require.EqualError(t, err, "failed to open store in underlying storage provider: open store error")
 		require.Nil(t, provider)
 	})
+	t.Run("Fail to set store config in underlying storage provider", func(t *testing.T) {
+		provider, err := localcas.New(&ariesmockstorage.Provider{ErrSetStoreConfig: errors.New("set config error")},
+			casLink, nil, &orbmocks.MetricsProvider{}, 0)
+
+		require.EqualError(t, err, "failed to set store configuration in underlying storage provider: set config error")
+		require.Nil(t, provider)
+	})
 }
 
 func TestProvider_Write_Read(t *testing.T) {
@@ -193,6 +203,183 @@ func TestProvider_Write_Read(t *testing.T) {
 	})
 }
 
+func TestProvider_MultihashCode(t *testing.T) {
+	content := []byte("content")
+
+	provider, err := localcas.New(ariesmemstorage.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0,
+		extendedcasclient.WithMultihashCode(hashlink.SHA3_256))
+	require.NoError(t, err)
+
+	hl, err := provider.Write(content)
+	require.NoError(t, err)
+
+	rh, err := hashlink.GetResourceHashFromHashLink(hl)
+	require.NoError(t, err)
+
+	expectedRH, err := hashlink.New(hashlink.WithMultihashCode(hashlink.SHA3_256)).CreateResourceHash(content)
+	require.NoError(t, err)
+	require.Equal(t, expectedRH, rh)
+
+	// The algorithm may be overridden for a single write (e.g. to store content that was anchored
+	// using a different algorithm).
+	hl, err = provider.WriteWithCIDFormat(content, extendedcasclient.WithMultihashCode(hashlink.SHA2_256))
+	require.NoError(t, err)
+
+	rh, err = hashlink.GetResourceHashFromHashLink(hl)
+	require.NoError(t, err)
+
+	expectedRH, err = hashlink.New().CreateResourceHash(content)
+	require.NoError(t, err)
+	require.Equal(t, expectedRH, rh)
+
+	read, err := provider.Read(rh)
+	require.NoError(t, err)
+	require.Equal(t, content, read)
+}
+
+func TestProvider_GetKeys_Delete(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		provider, err := localcas.New(ariesmemstorage.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.NoError(t, err)
+
+		hl1, err := provider.Write([]byte("content1"))
+		require.NoError(t, err)
+
+		hl2, err := provider.Write([]byte("content2"))
+		require.NoError(t, err)
+
+		rh1, err := hashlink.GetResourceHashFromHashLink(hl1)
+		require.NoError(t, err)
+
+		rh2, err := hashlink.GetResourceHashFromHashLink(hl2)
+		require.NoError(t, err)
+
+		keys, err := getKeys(provider, time.Now().Add(-time.Minute))
+		require.NoError(t, err)
+		require.Empty(t, keys)
+
+		keys, err = getKeys(provider, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{rh1, rh2}, keys)
+
+		require.NoError(t, provider.Delete(rh1))
+
+		_, err = provider.Read(rh1)
+		require.True(t, errors.Is(err, orberrors.ErrContentNotFound))
+
+		content, err := provider.Read(rh2)
+		require.NoError(t, err)
+		require.Equal(t, "content2", string(content))
+
+		require.NoError(t, provider.Delete())
+	})
+
+	t.Run("Query error", func(t *testing.T) {
+		provider, err := localcas.New(&ariesmockstorage.Provider{
+			OpenStoreReturn: &ariesmockstorage.Store{
+				ErrQuery: errors.New("query error"),
+			},
+		}, casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.NoError(t, err)
+
+		keys, err := getKeys(provider, time.Now())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "query error")
+		require.Empty(t, keys)
+	})
+
+	t.Run("Pages", func(t *testing.T) {
+		provider, err := localcas.New(ariesmemstorage.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.NoError(t, err)
+
+		for i := 0; i < 2500; i++ {
+			_, err = provider.Write([]byte(fmt.Sprintf("content%d", i)))
+			require.NoError(t, err)
+		}
+
+		var pages []int
+
+		require.NoError(t, provider.GetKeys(time.Now().Add(time.Minute), func(keys []string) error {
+			pages = append(pages, len(keys))
+
+			return nil
+		}))
+		require.Equal(t, []int{1000, 1000, 500}, pages)
+	})
+
+	t.Run("Handler error", func(t *testing.T) {
+		provider, err := localcas.New(ariesmemstorage.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.NoError(t, err)
+
+		_, err = provider.Write([]byte("content1"))
+		require.NoError(t, err)
+
+		err = provider.GetKeys(time.Now().Add(time.Minute), func(keys []string) error {
+			return errors.New("handler error")
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "handler error")
+	})
+
+	t.Run("Batch error", func(t *testing.T) {
+		provider, err := localcas.New(&ariesmockstorage.Provider{
+			OpenStoreReturn: &ariesmockstorage.Store{
+				ErrBatch: errors.New("batch error"),
+			},
+		}, casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.NoError(t, err)
+
+		err = provider.Delete("key1")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "batch error")
+	})
+}
+
+func TestProvider_GetKeys_MongoDB(t *testing.T) {
+	mongoDBConnString, stopMongo := mongodbtestutil.StartMongoDB(t)
+	defer stopMongo()
+
+	mongoDBProvider, err := ariesmongodbstorage.NewProvider(mongoDBConnString)
+	require.NoError(t, err)
+
+	provider, err := localcas.New(mongoDBProvider, casLink, nil, &orbmocks.MetricsProvider{}, 0)
+	require.NoError(t, err)
+
+	hl, err := provider.Write([]byte("content1"))
+	require.NoError(t, err)
+
+	rh1, err := hashlink.GetResourceHashFromHashLink(hl)
+	require.NoError(t, err)
+
+	// Simulate content that was written before creation times were recorded.
+	store, err := mongoDBProvider.OpenStore("cas")
+	require.NoError(t, err)
+
+	const rh2 = "uEiBB3Gh5ayKlMKzHi6KcuXwwDjbGmV0PyyXkyPvk65Ur3g"
+
+	require.NoError(t, store.Put(rh2, []byte("content2")))
+
+	keys, err := getKeys(provider, time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, []string{rh2}, keys)
+
+	keys, err = getKeys(provider, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{rh1, rh2}, keys)
+}
+
+func getKeys(provider *localcas.CAS, createdBefore time.Time) ([]string, error) {
+	var keys []string
+
+	err := provider.GetKeys(createdBefore, func(k []string) error {
+		keys = append(keys, k...)
+
+		return nil
+	})
+
+	return keys, err
+}
+
 func startIPFSDockerContainer(t *testing.T) (*dctest.Pool, *dctest.Resource) {
 	t.Helper()
 
@@ -0,0 +1,321 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package encrypted
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/store"
+)
+
+const (
+	keyStoreName = "orb-encryption-keys"
+
+	// activeKeyRecordID is the key of the record which holds the ID of the data key used for new writes.
+	activeKeyRecordID = "active"
+
+	// dataKeyTag is used to query all data key records.
+	dataKeyTag = "dataKey"
+
+	dataKeySize = 32
+)
+
+// dataKeyRecord holds a data encryption key which is wrapped by a key-encryption key from the KMS.
+// The data key itself is never persisted in plaintext.
+type dataKeyRecord struct {
+	ID         string    `json:"dataKey"`
+	KEKID      string    `json:"kekId"`
+	WrappedKey []byte    `json:"wrappedKey"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type activeKeyRecord struct {
+	ID string `json:"id"`
+}
+
+// keyRing manages the data encryption keys (DEK) which are used to encrypt values. Each DEK is wrapped with the
+// configured key-encryption key (KEK) and persisted so that values encrypted with older DEKs may still be decrypted.
+type keyRing struct {
+	store      storage.Store
+	keyWrapper KeyWrapper
+	kekID      string
+
+	mutex    sync.RWMutex
+	activeID string
+	ciphers  map[string]cipher.AEAD
+}
+
+func newKeyRing(p storage.Provider, keyWrapper KeyWrapper, kekID string) (*keyRing, error) {
+	s, err := store.Open(p, keyStoreName, store.NewTagGroup(dataKeyTag))
+	if err != nil {
+		return nil, fmt.Errorf("open key store: %w", err)
+	}
+
+	r := &keyRing{
+		store:      s,
+		keyWrapper: keyWrapper,
+		kekID:      kekID,
+		ciphers:    make(map[string]cipher.AEAD),
+	}
+
+	if err := r.init(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// init loads the active data key. If no data key exists then a new one is generated. If the active data key was
+// wrapped with a different key-encryption key (i.e. the KEK was rotated) then all data keys are re-wrapped
+// with the current KEK so that the old KEK may be retired.
+func (r *keyRing) init() error {
+	activeBytes, err := r.store.Get(activeKeyRecordID)
+	if err != nil {
+		if errors.Is(err, storage.ErrDataNotFound) {
+			logger.Info("No data encryption key found. Generating a new key.")
+
+			return r.rotate()
+		}
+
+		return fmt.Errorf("get active data key: %w", err)
+	}
+
+	active := &activeKeyRecord{}
+
+	if err := json.Unmarshal(activeBytes, active); err != nil {
+		return fmt.Errorf("unmarshal active data key record: %w", err)
+	}
+
+	rec, err := r.getRecord(active.ID)
+	if err != nil {
+		return err
+	}
+
+	if rec.KEKID != r.kekID {
+		logger.Info("Key-encryption key has changed. Re-wrapping data encryption keys.",
+			log.WithKeyID(r.kekID))
+
+		if err := r.rewrap(); err != nil {
+			return fmt.Errorf("rewrap data keys: %w", err)
+		}
+	}
+
+	if _, err := r.get(active.ID); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	r.activeID = active.ID
+	r.mutex.Unlock()
+
+	return nil
+}
+
+// active returns the ID and cipher of the data key which is used for new writes.
+func (r *keyRing) active() (string, cipher.AEAD) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.activeID, r.ciphers[r.activeID]
+}
+
+// get returns the cipher for the data key with the given ID. The key is loaded from the key store (and unwrapped)
+// if it's not already cached. (The key may have been generated by another server instance.)
+func (r *keyRing) get(id string) (cipher.AEAD, error) {
+	r.mutex.RLock()
+	c, ok := r.ciphers[id]
+	r.mutex.RUnlock()
+
+	if ok {
+		return c, nil
+	}
+
+	rec, err := r.getRecord(id)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := r.keyWrapper.UnwrapKey(rec.KEKID, rec.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("unwrap data key [%s]: %w", id, err)
+	}
+
+	c, err = newCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher for data key [%s]: %w", id, err)
+	}
+
+	r.mutex.Lock()
+	r.ciphers[id] = c
+	r.mutex.Unlock()
+
+	return c, nil
+}
+
+// rotate generates a new data key and makes it the active key. Existing data keys are retained so that
+// values encrypted with those keys may still be decrypted.
+func (r *keyRing) rotate() error {
+	key := make([]byte, dataKeySize)
+
+	if _, err := rand.Read(key); err != nil {
+		return fmt.Errorf("generate data key: %w", err)
+	}
+
+	c, err := newCipher(key)
+	if err != nil {
+		return fmt.Errorf("create cipher: %w", err)
+	}
+
+	rec := &dataKeyRecord{
+		ID:        uuid.New().String(),
+		KEKID:     r.kekID,
+		CreatedAt: time.Now(),
+	}
+
+	rec.WrappedKey, err = r.keyWrapper.WrapKey(r.kekID, key)
+	if err != nil {
+		return fmt.Errorf("wrap data key: %w", err)
+	}
+
+	// The data key must be persisted before it becomes active, otherwise values encrypted
+	// with the key could not be decrypted by other instances.
+	if err := r.putRecord(rec); err != nil {
+		return err
+	}
+
+	activeBytes, err := json.Marshal(&activeKeyRecord{ID: rec.ID})
+	if err != nil {
+		return fmt.Errorf("marshal active data key record: %w", err)
+	}
+
+	if err := r.store.Put(activeKeyRecordID, activeBytes); err != nil {
+		return fmt.Errorf("store active data key record: %w", err)
+	}
+
+	r.mutex.Lock()
+	r.ciphers[rec.ID] = c
+	r.activeID = rec.ID
+	r.mutex.Unlock()
+
+	logger.Info("Generated new data encryption key", log.WithKeyID(rec.ID))
+
+	return nil
+}
+
+// rewrap re-wraps all data keys which were wrapped with a key-encryption key other than the current one.
+func (r *keyRing) rewrap() error {
+	it, err := r.store.Query(dataKeyTag)
+	if err != nil {
+		return fmt.Errorf("query data keys: %w", err)
+	}
+
+	defer func() {
+		if errClose := it.Close(); errClose != nil {
+			log.CloseIteratorError(logger, errClose)
+		}
+	}()
+
+	var records []*dataKeyRecord
+
+	for {
+		ok, err := it.Next()
+		if err != nil {
+			return fmt.Errorf("iterator next: %w", err)
+		}
+
+		if !ok {
+			break
+		}
+
+		value, err := it.Value()
+		if err != nil {
+			return fmt.Errorf("iterator value: %w", err)
+		}
+
+		rec := &dataKeyRecord{}
+
+		if err := json.Unmarshal(value, rec); err != nil {
+			return fmt.Errorf("unmarshal data key record: %w", err)
+		}
+
+		records = append(records, rec)
+	}
+
+	for _, rec := range records {
+		if rec.KEKID == r.kekID {
+			continue
+		}
+
+		key, err := r.keyWrapper.UnwrapKey(rec.KEKID, rec.WrappedKey)
+		if err != nil {
+			return fmt.Errorf("unwrap data key [%s]: %w", rec.ID, err)
+		}
+
+		rec.WrappedKey, err = r.keyWrapper.WrapKey(r.kekID, key)
+		if err != nil {
+			return fmt.Errorf("wrap data key [%s]: %w", rec.ID, err)
+		}
+
+		rec.KEKID = r.kekID
+
+		if err := r.putRecord(rec); err != nil {
+			return err
+		}
+
+		logger.Info("Re-wrapped data encryption key", log.WithKeyID(rec.ID))
+	}
+
+	return nil
+}
+
+func (r *keyRing) getRecord(id string) (*dataKeyRecord, error) {
+	recBytes, err := r.store.Get(id)
+	if err != nil {
+		return nil, fmt.Errorf("get data key [%s]: %w", id, err)
+	}
+
+	rec := &dataKeyRecord{}
+
+	if err := json.Unmarshal(recBytes, rec); err != nil {
+		return nil, fmt.Errorf("unmarshal data key record [%s]: %w", id, err)
+	}
+
+	return rec, nil
+}
+
+func (r *keyRing) putRecord(rec *dataKeyRecord) error {
+	recBytes, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("marshal data key record: %w", err)
+	}
+
+	if err := r.store.Put(rec.ID, recBytes, storage.Tag{Name: dataKeyTag, Value: rec.ID}); err != nil {
+		return fmt.Errorf("store data key [%s]: %w", rec.ID, err)
+	}
+
+	return nil
+}
+
+func newCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
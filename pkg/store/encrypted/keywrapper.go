@@ -0,0 +1,122 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package encrypted
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awskms "github.com/aws/aws-sdk-go/service/kms"
+)
+
+type keyManager interface {
+	Get(keyID string) (interface{}, error)
+}
+
+type cryptoService interface {
+	Encrypt(msg, aad []byte, kh interface{}) ([]byte, []byte, error)
+	Decrypt(cipher, aad, nonce []byte, kh interface{}) ([]byte, error)
+}
+
+// KMSKeyWrapper wraps data encryption keys using a key-encryption key held by an Aries KMS (local or web KMS).
+type KMSKeyWrapper struct {
+	km keyManager
+	cr cryptoService
+}
+
+// NewKMSKeyWrapper returns a new key wrapper which uses the given Aries KMS and crypto service.
+func NewKMSKeyWrapper(km keyManager, cr cryptoService) *KMSKeyWrapper {
+	return &KMSKeyWrapper{km: km, cr: cr}
+}
+
+type wrappedKey struct {
+	Nonce      []byte `json:"nonce,omitempty"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// WrapKey encrypts the given key with the key-encryption key of the given ID.
+func (w *KMSKeyWrapper) WrapKey(kekID string, key []byte) ([]byte, error) {
+	kh, err := w.km.Get(kekID)
+	if err != nil {
+		return nil, fmt.Errorf("get key-encryption key [%s]: %w", kekID, err)
+	}
+
+	ct, nonce, err := w.cr.Encrypt(key, nil, kh)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt with key-encryption key [%s]: %w", kekID, err)
+	}
+
+	wrapped, err := json.Marshal(&wrappedKey{Nonce: nonce, Ciphertext: ct})
+	if err != nil {
+		return nil, fmt.Errorf("marshal wrapped key: %w", err)
+	}
+
+	return wrapped, nil
+}
+
+// UnwrapKey decrypts the given wrapped key with the key-encryption key of the given ID.
+func (w *KMSKeyWrapper) UnwrapKey(kekID string, wrapped []byte) ([]byte, error) {
+	wk := &wrappedKey{}
+
+	if err := json.Unmarshal(wrapped, wk); err != nil {
+		return nil, fmt.Errorf("unmarshal wrapped key: %w", err)
+	}
+
+	kh, err := w.km.Get(kekID)
+	if err != nil {
+		return nil, fmt.Errorf("get key-encryption key [%s]: %w", kekID, err)
+	}
+
+	key, err := w.cr.Decrypt(wk.Ciphertext, nil, wk.Nonce, kh)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt with key-encryption key [%s]: %w", kekID, err)
+	}
+
+	return key, nil
+}
+
+type awsKMSClient interface {
+	Encrypt(input *awskms.EncryptInput) (*awskms.EncryptOutput, error)
+	Decrypt(input *awskms.DecryptInput) (*awskms.DecryptOutput, error)
+}
+
+// AWSKeyWrapper wraps data encryption keys using a symmetric key held by AWS KMS.
+type AWSKeyWrapper struct {
+	client awsKMSClient
+}
+
+// NewAWSKeyWrapper returns a new key wrapper which uses the given AWS KMS client.
+func NewAWSKeyWrapper(client awsKMSClient) *AWSKeyWrapper {
+	return &AWSKeyWrapper{client: client}
+}
+
+// WrapKey encrypts the given key with the AWS KMS key of the given ID.
+func (w *AWSKeyWrapper) WrapKey(kekID string, key []byte) ([]byte, error) {
+	out, err := w.client.Encrypt(&awskms.EncryptInput{
+		KeyId:     aws.String(kekID),
+		Plaintext: key,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encrypt with AWS key [%s]: %w", kekID, err)
+	}
+
+	return out.CiphertextBlob, nil
+}
+
+// UnwrapKey decrypts the given wrapped key with the AWS KMS key of the given ID.
+func (w *AWSKeyWrapper) UnwrapKey(kekID string, wrapped []byte) ([]byte, error) {
+	out, err := w.client.Decrypt(&awskms.DecryptInput{
+		KeyId:          aws.String(kekID),
+		CiphertextBlob: wrapped,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("decrypt with AWS key [%s]: %w", kekID, err)
+	}
+
+	return out.Plaintext, nil
+}
@@ -0,0 +1,172 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package encrypted
+
+import (
+	"errors"
+	"testing"
+
+	awskms "github.com/aws/aws-sdk-go/service/kms"
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKMSKeyWrapper(t *testing.T) {
+	km := newLocalKMS(t)
+
+	cr, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	kekID, _, err := km.Create(kms.AES256GCMType)
+	require.NoError(t, err)
+
+	w := NewKMSKeyWrapper(km, cr)
+
+	key := []byte("0123456789abcdef0123456789abcdef")
+
+	t.Run("Success", func(t *testing.T) {
+		wrapped, err := w.WrapKey(kekID, key)
+		require.NoError(t, err)
+		require.NotContains(t, string(wrapped), string(key))
+
+		unwrapped, err := w.UnwrapKey(kekID, wrapped)
+		require.NoError(t, err)
+		require.Equal(t, key, unwrapped)
+	})
+
+	t.Run("Key not found", func(t *testing.T) {
+		_, err := w.WrapKey("unknown", key)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get key-encryption key [unknown]")
+
+		_, err = w.UnwrapKey("unknown", []byte(`{}`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get key-encryption key [unknown]")
+	})
+
+	t.Run("Invalid wrapped key", func(t *testing.T) {
+		_, err := w.UnwrapKey(kekID, []byte(`{`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal wrapped key")
+	})
+
+	t.Run("Wrong key-encryption key", func(t *testing.T) {
+		wrapped, err := w.WrapKey(kekID, key)
+		require.NoError(t, err)
+
+		kekID2, _, err := km.Create(kms.AES256GCMType)
+		require.NoError(t, err)
+
+		_, err = w.UnwrapKey(kekID2, wrapped)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "decrypt with key-encryption key")
+	})
+
+	t.Run("Encrypt error", func(t *testing.T) {
+		// ED25519 keys may not be used for encryption.
+		signingKeyID, _, err := km.Create(kms.ED25519Type)
+		require.NoError(t, err)
+
+		_, err = w.WrapKey(signingKeyID, key)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "encrypt with key-encryption key")
+	})
+}
+
+func TestAWSKeyWrapper(t *testing.T) {
+	const kekID = "arn:aws:kms:ca-central-1:111122223333:key/1234"
+
+	key := []byte("0123456789abcdef0123456789abcdef")
+
+	t.Run("Success", func(t *testing.T) {
+		w := NewAWSKeyWrapper(&mockAWSClient{})
+
+		wrapped, err := w.WrapKey(kekID, key)
+		require.NoError(t, err)
+		require.NotEqual(t, key, wrapped)
+
+		unwrapped, err := w.UnwrapKey(kekID, wrapped)
+		require.NoError(t, err)
+		require.Equal(t, key, unwrapped)
+	})
+
+	t.Run("Encrypt error", func(t *testing.T) {
+		w := NewAWSKeyWrapper(&mockAWSClient{err: errors.New("injected encrypt error")})
+
+		_, err := w.WrapKey(kekID, key)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected encrypt error")
+	})
+
+	t.Run("Decrypt error", func(t *testing.T) {
+		w := NewAWSKeyWrapper(&mockAWSClient{err: errors.New("injected decrypt error")})
+
+		_, err := w.UnwrapKey(kekID, key)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected decrypt error")
+	})
+}
+
+func newLocalKMS(t *testing.T) *localkms.LocalKMS {
+	t.Helper()
+
+	kmsStore, err := kms.NewAriesProviderWrapper(mem.NewProvider())
+	require.NoError(t, err)
+
+	km, err := localkms.New("local-lock://test/master/key/", &kmsProvider{store: kmsStore})
+	require.NoError(t, err)
+
+	return km
+}
+
+type kmsProvider struct {
+	store kms.Store
+}
+
+func (p *kmsProvider) StorageProvider() kms.Store {
+	return p.store
+}
+
+func (p *kmsProvider) SecretLock() secretlock.Service {
+	return &noop.NoLock{}
+}
+
+type mockAWSClient struct {
+	err error
+}
+
+// Encrypt "encrypts" by reversing the plaintext.
+func (m *mockAWSClient) Encrypt(input *awskms.EncryptInput) (*awskms.EncryptOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &awskms.EncryptOutput{CiphertextBlob: reverse(input.Plaintext), KeyId: input.KeyId}, nil
+}
+
+func (m *mockAWSClient) Decrypt(input *awskms.DecryptInput) (*awskms.DecryptOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &awskms.DecryptOutput{Plaintext: reverse(input.CiphertextBlob), KeyId: input.KeyId}, nil
+}
+
+func reverse(b []byte) []byte {
+	r := make([]byte, len(b))
+
+	for i, v := range b {
+		r[len(b)-1-i] = v
+	}
+
+	return r
+}
@@ -0,0 +1,305 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package encrypted
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/aries-framework-go-ext/component/storage/mongodb"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"go.mongodb.org/mongo-driver/mongo"
+	mongoopts "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const idField = "_id"
+
+type mongoDBStore interface {
+	PutAsJSON(key string, value interface{}) error
+	BulkWrite(models []mongo.WriteModel, opts ...*mongoopts.BulkWriteOptions) error
+	GetAsRawMap(id string) (map[string]interface{}, error)
+	GetBulkAsRawMap(ids ...string) ([]map[string]interface{}, error)
+	QueryCustom(filter interface{}, options ...*mongoopts.FindOptions) (mongodb.Iterator, error)
+	CreateMongoDBFindOptions(options []storage.QueryOption, isJSONQuery bool) *mongoopts.FindOptions
+}
+
+// MongoDBStore is an encrypting store which supports the MongoDB-specific APIs. Each document is stored with
+// the encrypted document in the encryptedValue field along with the (plaintext) indexed fields.
+type MongoDBStore struct {
+	*Store
+
+	ms            mongoDBStore
+	indexedFields func() []string
+}
+
+func newMongoDBStore(s *Store, indexedFields func() []string) *MongoDBStore {
+	ms, ok := s.Store.(mongoDBStore)
+	if !ok {
+		// If this happens then it's a bug.
+		panic(fmt.Errorf("expecting MongoDB store for [%s]", s.name))
+	}
+
+	return &MongoDBStore{
+		Store:         s,
+		ms:            ms,
+		indexedFields: indexedFields,
+	}
+}
+
+// PutAsJSON encrypts the given document and stores it.
+func (s *MongoDBStore) PutAsJSON(key string, value interface{}) error {
+	doc, err := toMap(value)
+	if err != nil {
+		return fmt.Errorf("convert value to map [%s-%s]: %w", s.name, key, err)
+	}
+
+	encDoc, err := s.encryptDoc(key, doc)
+	if err != nil {
+		return err
+	}
+
+	return s.ms.PutAsJSON(key, encDoc)
+}
+
+// GetAsRawMap returns the decrypted document for the given ID.
+func (s *MongoDBStore) GetAsRawMap(id string) (map[string]interface{}, error) {
+	doc, err := s.ms.GetAsRawMap(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.decryptDoc(id, doc)
+}
+
+// GetBulkAsRawMap returns the decrypted documents for the given IDs.
+func (s *MongoDBStore) GetBulkAsRawMap(ids ...string) ([]map[string]interface{}, error) {
+	docs, err := s.ms.GetBulkAsRawMap(ids...)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, doc := range docs {
+		if doc == nil {
+			continue
+		}
+
+		docs[i], err = s.decryptDoc(ids[i], doc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return docs, nil
+}
+
+// QueryCustom queries for documents using the given filter and returns an iterator which decrypts the documents.
+// Only the indexed fields may be used in the filter.
+func (s *MongoDBStore) QueryCustom(filter interface{}, options ...*mongoopts.FindOptions) (mongodb.Iterator, error) {
+	it, err := s.ms.QueryCustom(filter, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mongoDBIterator{Iterator: it, store: s}, nil
+}
+
+// BulkWrite encrypts the documents of the given insert and replace models and executes the
+// bulk write on the underlying store.
+func (s *MongoDBStore) BulkWrite(models []mongo.WriteModel, opts ...*mongoopts.BulkWriteOptions) error {
+	encModels := make([]mongo.WriteModel, len(models))
+
+	for i, model := range models {
+		switch m := model.(type) {
+		case *mongo.InsertOneModel:
+			encDoc, err := s.encryptModelDoc(m.Document)
+			if err != nil {
+				return err
+			}
+
+			encModels[i] = mongo.NewInsertOneModel().SetDocument(encDoc)
+		case *mongo.ReplaceOneModel:
+			encDoc, err := s.encryptModelDoc(m.Replacement)
+			if err != nil {
+				return err
+			}
+
+			encModel := *m
+			encModel.Replacement = encDoc
+
+			encModels[i] = &encModel
+		case *mongo.DeleteOneModel, *mongo.DeleteManyModel:
+			encModels[i] = m
+		default:
+			return fmt.Errorf("unsupported write model for encrypted store [%s]: %T", s.name, model)
+		}
+	}
+
+	return s.ms.BulkWrite(encModels, opts...)
+}
+
+// CreateMongoDBFindOptions converts the given storage options to MongoDB options.
+func (s *MongoDBStore) CreateMongoDBFindOptions(options []storage.QueryOption,
+	isJSONQuery bool) *mongoopts.FindOptions {
+	return s.ms.CreateMongoDBFindOptions(options, isJSONQuery)
+}
+
+func (s *MongoDBStore) encryptModelDoc(value interface{}) (map[string]interface{}, error) {
+	doc, err := toMap(value)
+	if err != nil {
+		return nil, fmt.Errorf("convert document to map [%s]: %w", s.name, err)
+	}
+
+	id, ok := doc[idField].(string)
+	if !ok {
+		return nil, fmt.Errorf("document in store [%s] does not have a string %s field", s.name, idField)
+	}
+
+	delete(doc, idField)
+
+	encDoc, err := s.encryptDoc(id, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	encDoc[idField] = id
+
+	return encDoc, nil
+}
+
+func (s *MongoDBStore) encryptDoc(key string, doc map[string]interface{}) (map[string]interface{}, error) {
+	docBytes, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("marshal document [%s-%s]: %w", s.name, key, err)
+	}
+
+	env, err := s.seal(key, docBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	envMap, err := toMap(env)
+	if err != nil {
+		return nil, fmt.Errorf("convert encrypted value to map [%s-%s]: %w", s.name, key, err)
+	}
+
+	encDoc := map[string]interface{}{
+		encryptedValueField: envMap,
+	}
+
+	for _, field := range s.indexedFields() {
+		if v, ok := doc[field]; ok {
+			encDoc[field] = v
+		}
+	}
+
+	return encDoc, nil
+}
+
+func (s *MongoDBStore) decryptDoc(key string, doc map[string]interface{}) (map[string]interface{}, error) {
+	encValue, ok := doc[encryptedValueField]
+	if !ok {
+		// The document was stored before encryption was enabled.
+		return doc, nil
+	}
+
+	envBytes, err := json.Marshal(encValue)
+	if err != nil {
+		return nil, fmt.Errorf("marshal encrypted value [%s-%s]: %w", s.name, key, err)
+	}
+
+	env := &envelope{}
+
+	if err := json.Unmarshal(envBytes, env); err != nil {
+		return nil, fmt.Errorf("unmarshal encrypted value [%s-%s]: %w", s.name, key, err)
+	}
+
+	docBytes, err := s.open(key, env)
+	if err != nil {
+		return nil, err
+	}
+
+	decDoc, err := unmarshalMap(docBytes)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal document [%s-%s]: %w", s.name, key, err)
+	}
+
+	if id, ok := doc[idField]; ok {
+		decDoc[idField] = id
+	}
+
+	return decDoc, nil
+}
+
+type mongoDBIterator struct {
+	mongodb.Iterator
+
+	store *MongoDBStore
+}
+
+// Value returns the decrypted value of the current entry.
+func (it *mongoDBIterator) Value() ([]byte, error) {
+	key, err := it.Iterator.Key()
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := it.Iterator.Value()
+	if err != nil {
+		return nil, err
+	}
+
+	return it.store.decrypt(key, value)
+}
+
+// ValueAsRawMap returns the decrypted document of the current entry.
+func (it *mongoDBIterator) ValueAsRawMap() (map[string]interface{}, error) {
+	key, err := it.Iterator.Key()
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := it.Iterator.ValueAsRawMap()
+	if err != nil {
+		return nil, err
+	}
+
+	return it.store.decryptDoc(key, doc)
+}
+
+func toMap(value interface{}) (map[string]interface{}, error) {
+	if doc, ok := value.(map[string]interface{}); ok {
+		// Copy the map so that the caller's map is not modified.
+		m := make(map[string]interface{}, len(doc))
+
+		for k, v := range doc {
+			m[k] = v
+		}
+
+		return m, nil
+	}
+
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return unmarshalMap(valueBytes)
+}
+
+func unmarshalMap(value []byte) (map[string]interface{}, error) {
+	var doc map[string]interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
@@ -0,0 +1,426 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package encrypted
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go-ext/component/storage/mongodb"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	mongoopts "go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/trustbloc/orb/pkg/store"
+	"github.com/trustbloc/orb/pkg/store/mocks"
+)
+
+const (
+	indexedField = "field1"
+	secretField  = "field2"
+)
+
+func TestMongoDBProvider(t *testing.T) {
+	kw, kekID := newKeyWrapper(t)
+
+	t.Run("Put and Get", func(t *testing.T) {
+		mp := newMockMongoDBProvider()
+
+		ep, err := NewMongoDB(mp.MongoDBProvider, kw, kekID)
+		require.NoError(t, err)
+
+		s, err := store.Open(ep, storeName, store.NewTagGroup(indexedField))
+		require.NoError(t, err)
+
+		require.NoError(t, s.Put(key1, []byte(`{"field1":"value1","field2":"secret value","field3":3}`)))
+
+		// The indexed field should be stored in plaintext and the rest of the document should be encrypted.
+		raw := mp.stores[storeName].docs[key1]
+		require.Equal(t, "value1", raw[indexedField])
+		require.NotContains(t, raw, secretField)
+		require.Contains(t, raw, encryptedValueField)
+
+		rawBytes, err := json.Marshal(raw)
+		require.NoError(t, err)
+		require.NotContains(t, string(rawBytes), "secret value")
+
+		v, err := s.Get(key1)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"field1":"value1","field2":"secret value","field3":3}`, string(v))
+
+		values, err := s.GetBulk(key1, key2)
+		require.NoError(t, err)
+		require.Len(t, values, 2)
+		require.JSONEq(t, `{"field1":"value1","field2":"secret value","field3":3}`, string(values[0]))
+		require.Nil(t, values[1])
+	})
+
+	t.Run("Plaintext document", func(t *testing.T) {
+		mp := newMockMongoDBProvider()
+
+		ep, err := NewMongoDB(mp.MongoDBProvider, kw, kekID)
+		require.NoError(t, err)
+
+		s, err := store.Open(ep, storeName)
+		require.NoError(t, err)
+
+		mp.stores[storeName].docs[key1] = map[string]interface{}{idField: key1, secretField: "value2"}
+
+		v, err := s.Get(key1)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"field2":"value2"}`, string(v))
+	})
+
+	t.Run("Query", func(t *testing.T) {
+		mp := newMockMongoDBProvider()
+
+		ep, err := NewMongoDB(mp.MongoDBProvider, kw, kekID)
+		require.NoError(t, err)
+
+		s, err := store.Open(ep, storeName, store.NewTagGroup(indexedField))
+		require.NoError(t, err)
+
+		require.NoError(t, s.Put(key1, []byte(`{"field1":"value1","field2":"secret value"}`)))
+
+		it, err := s.Query(indexedField + ":value1")
+		require.NoError(t, err)
+
+		ok, err := it.Next()
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		v, err := it.Value()
+		require.NoError(t, err)
+		require.JSONEq(t, `{"field1":"value1","field2":"secret value"}`, string(v))
+
+		ok, err = it.Next()
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		mp := newMockMongoDBProvider()
+
+		ep, err := NewMongoDB(mp.MongoDBProvider, kw, kekID)
+		require.NoError(t, err)
+
+		s, err := store.Open(ep, storeName, store.NewTagGroup(indexedField))
+		require.NoError(t, err)
+
+		require.NoError(t, s.Put(key2, []byte(`{"field1":"value2"}`)))
+
+		require.NoError(t, s.Batch([]storage.Operation{
+			{Key: key1, Value: []byte(`{"field1":"value1","field2":"secret value"}`)},
+			{
+				Key: "key3", Value: []byte(`{"field1":"value3","field2":"secret value"}`),
+				PutOptions: &storage.PutOptions{IsNewKey: true},
+			},
+			{Key: key2},
+		}))
+
+		require.NotContains(t, mp.stores[storeName].docs[key1], secretField)
+		require.NotContains(t, mp.stores[storeName].docs["key3"], secretField)
+
+		v, err := s.Get(key1)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"field1":"value1","field2":"secret value"}`, string(v))
+
+		v, err = s.Get("key3")
+		require.NoError(t, err)
+		require.JSONEq(t, `{"field1":"value3","field2":"secret value"}`, string(v))
+
+		_, err = s.Get(key2)
+		require.ErrorIs(t, err, storage.ErrDataNotFound)
+	})
+
+	t.Run("Unsupported write model", func(t *testing.T) {
+		mp := newMockMongoDBProvider()
+
+		ep, err := NewMongoDB(mp.MongoDBProvider, kw, kekID)
+		require.NoError(t, err)
+
+		s, err := ep.OpenStore(storeName)
+		require.NoError(t, err)
+
+		err = s.(*MongoDBStore).BulkWrite([]mongo.WriteModel{mongo.NewUpdateOneModel()}) //nolint:forcetypeassert
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported write model")
+	})
+
+	t.Run("Document without ID", func(t *testing.T) {
+		mp := newMockMongoDBProvider()
+
+		ep, err := NewMongoDB(mp.MongoDBProvider, kw, kekID)
+		require.NoError(t, err)
+
+		s, err := ep.OpenStore(storeName)
+		require.NoError(t, err)
+
+		err = s.(*MongoDBStore).BulkWrite([]mongo.WriteModel{ //nolint:forcetypeassert
+			mongo.NewInsertOneModel().SetDocument(map[string]interface{}{secretField: "value"}),
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "does not have a string _id field")
+	})
+
+	t.Run("Underlying store errors", func(t *testing.T) {
+		errExpected := errors.New("injected error")
+
+		mp := newMockMongoDBProvider()
+
+		ep, err := NewMongoDB(mp.MongoDBProvider, kw, kekID)
+		require.NoError(t, err)
+
+		s, err := ep.OpenStore(storeName)
+		require.NoError(t, err)
+
+		ms := mp.stores[storeName].MongoDBStore
+		ms.GetAsRawMapStub = nil
+		ms.GetAsRawMapReturns(nil, errExpected)
+		ms.GetBulkAsRawMapStub = nil
+		ms.GetBulkAsRawMapReturns(nil, errExpected)
+		ms.QueryCustomStub = nil
+		ms.QueryCustomReturns(nil, errExpected)
+
+		es := s.(*MongoDBStore) //nolint:forcetypeassert
+
+		_, err = es.GetAsRawMap(key1)
+		require.ErrorIs(t, err, errExpected)
+
+		_, err = es.GetBulkAsRawMap(key1)
+		require.ErrorIs(t, err, errExpected)
+
+		_, err = es.QueryCustom(bson.M{})
+		require.ErrorIs(t, err, errExpected)
+	})
+
+	t.Run("CreateCustomIndexes error", func(t *testing.T) {
+		errExpected := errors.New("injected CreateCustomIndexes error")
+
+		mp := newMockMongoDBProvider()
+
+		ep, err := NewMongoDB(mp.MongoDBProvider, kw, kekID)
+		require.NoError(t, err)
+
+		mp.CreateCustomIndexesReturns(errExpected)
+
+		_, err = store.Open(ep, storeName, store.NewTagGroup(indexedField))
+		require.ErrorIs(t, err, errExpected)
+	})
+
+	t.Run("Not a MongoDB store", func(t *testing.T) {
+		mp := newMockMongoDBProvider()
+
+		ep, err := NewMongoDB(mp.MongoDBProvider, kw, kekID)
+		require.NoError(t, err)
+
+		mp.OpenStoreStub = nil
+		mp.OpenStoreReturns(&mocks.Store{}, nil)
+
+		require.Panics(t, func() {
+			_, err = ep.OpenStore(storeName)
+		})
+	})
+}
+
+type mockMongoDBProvider struct {
+	*mocks.MongoDBProvider
+
+	stores map[string]*mockMongoDBStore
+}
+
+func newMockMongoDBProvider() *mockMongoDBProvider {
+	p := &mockMongoDBProvider{
+		MongoDBProvider: &mocks.MongoDBProvider{},
+		stores:          make(map[string]*mockMongoDBStore),
+	}
+
+	p.OpenStoreStub = func(name string) (storage.Store, error) {
+		s, ok := p.stores[name]
+		if !ok {
+			s = newMockMongoDBStore()
+			p.stores[name] = s
+		}
+
+		return s.MongoDBStore, nil
+	}
+
+	return p
+}
+
+// mockMongoDBStore is a simple map-backed MongoDB store which supports queries on a single field.
+type mockMongoDBStore struct {
+	*mocks.MongoDBStore
+
+	mutex sync.RWMutex
+	docs  map[string]map[string]interface{}
+}
+
+func newMockMongoDBStore() *mockMongoDBStore {
+	s := &mockMongoDBStore{
+		MongoDBStore: &mocks.MongoDBStore{},
+		docs:         make(map[string]map[string]interface{}),
+	}
+
+	s.PutAsJSONStub = func(key string, value interface{}) error {
+		doc, err := mongodb.PrepareDataForBSONStorage(value)
+		if err != nil {
+			return err
+		}
+
+		doc[idField] = key
+
+		s.put(key, doc)
+
+		return nil
+	}
+
+	s.GetAsRawMapStub = func(id string) (map[string]interface{}, error) {
+		doc, ok := s.get(id)
+		if !ok {
+			return nil, fmt.Errorf("get [%s]: %w", id, storage.ErrDataNotFound)
+		}
+
+		return doc, nil
+	}
+
+	s.GetBulkAsRawMapStub = func(ids ...string) ([]map[string]interface{}, error) {
+		docs := make([]map[string]interface{}, len(ids))
+
+		for i, id := range ids {
+			docs[i], _ = s.get(id)
+		}
+
+		return docs, nil
+	}
+
+	s.BulkWriteStub = func(models []mongo.WriteModel, _ ...*mongoopts.BulkWriteOptions) error {
+		for _, model := range models {
+			switch m := model.(type) {
+			case *mongo.InsertOneModel:
+				doc := toBSONMap(m.Document)
+				s.put(doc[idField].(string), doc) //nolint:forcetypeassert
+			case *mongo.ReplaceOneModel:
+				doc := toBSONMap(m.Replacement)
+				s.put(doc[idField].(string), doc) //nolint:forcetypeassert
+			case *mongo.DeleteOneModel:
+				s.delete(m.Filter.(bson.M)[idField].(string)) //nolint:forcetypeassert
+			}
+		}
+
+		return nil
+	}
+
+	s.QueryCustomStub = func(filter interface{}, _ ...*mongoopts.FindOptions) (mongodb.Iterator, error) {
+		return s.query(filter.(bson.D)), nil //nolint:forcetypeassert
+	}
+
+	return s
+}
+
+func (s *mockMongoDBStore) put(key string, doc map[string]interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.docs[key] = doc
+}
+
+func (s *mockMongoDBStore) get(key string) (map[string]interface{}, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	doc, ok := s.docs[key]
+	if !ok {
+		return nil, false
+	}
+
+	return toBSONMap(doc), true
+}
+
+func (s *mockMongoDBStore) delete(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.docs, key)
+}
+
+func (s *mockMongoDBStore) query(filter bson.D) *mocks.MongoDBIterator {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var docs []map[string]interface{}
+
+	for _, doc := range s.docs {
+		if matches(doc, filter) {
+			docs = append(docs, toBSONMap(doc))
+		}
+	}
+
+	it := &mocks.MongoDBIterator{}
+
+	i := -1
+
+	it.NextStub = func() (bool, error) {
+		i++
+
+		return i < len(docs), nil
+	}
+
+	it.KeyStub = func() (string, error) {
+		return docs[i][idField].(string), nil //nolint:forcetypeassert
+	}
+
+	it.ValueAsRawMapStub = func() (map[string]interface{}, error) {
+		return docs[i], nil
+	}
+
+	it.ValueStub = func() ([]byte, error) {
+		return json.Marshal(docs[i])
+	}
+
+	return it
+}
+
+func matches(doc map[string]interface{}, filter bson.D) bool {
+	for _, e := range filter {
+		v, ok := doc[e.Key]
+		if !ok {
+			return false
+		}
+
+		if m, ok := e.Value.(bson.D); ok && len(m) > 0 && m[0].Key == "$exists" {
+			continue
+		}
+
+		if fmt.Sprint(v) != fmt.Sprint(e.Value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// toBSONMap emulates the conversion of a document to/from BSON.
+func toBSONMap(doc interface{}) map[string]interface{} {
+	docBytes, err := json.Marshal(doc)
+	if err != nil {
+		panic(err)
+	}
+
+	m := make(map[string]interface{})
+
+	if err := json.Unmarshal(docBytes, &m); err != nil {
+		panic(err)
+	}
+
+	return m
+}
@@ -0,0 +1,160 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package encrypted
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+)
+
+var logger = log.New("encrypted-store")
+
+// KeyWrapper wraps (encrypts) and unwraps (decrypts) data encryption keys using a key-encryption key
+// which is held by a KMS.
+type KeyWrapper interface {
+	WrapKey(kekID string, key []byte) ([]byte, error)
+	UnwrapKey(kekID string, wrappedKey []byte) ([]byte, error)
+}
+
+type provider interface {
+	storage.Provider
+
+	Ping() error
+}
+
+type mongoDBProvider interface {
+	provider
+
+	CreateCustomIndexes(storeName string, model ...mongo.IndexModel) error
+}
+
+// Provider is a storage provider which encrypts the values of all stores that it opens. Values are encrypted
+// (using AES-GCM) with a data encryption key which is wrapped by a key-encryption key from the KMS. Tags are
+// stored in plaintext so that they may be queried.
+//
+// The key-encryption key is rotated by providing the ID of a new key. On startup, all existing data keys are
+// re-wrapped with the new key-encryption key so that the old one may be retired.
+type Provider struct {
+	provider
+
+	keys *keyRing
+}
+
+// New returns a new encrypting storage provider. The data keys are wrapped by the key-encryption key
+// with the given ID.
+func New(p provider, keyWrapper KeyWrapper, kekID string) (*Provider, error) {
+	keys, err := newKeyRing(p, keyWrapper, kekID)
+	if err != nil {
+		return nil, fmt.Errorf("create key ring: %w", err)
+	}
+
+	return &Provider{
+		provider: p,
+		keys:     keys,
+	}, nil
+}
+
+// OpenStore opens the store with the given name and returns an encrypting store.
+func (p *Provider) OpenStore(name string) (storage.Store, error) {
+	s, err := p.provider.OpenStore(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return newStore(name, s, p.keys), nil
+}
+
+// RotateDataKey generates a new data encryption key which is used for all subsequent writes. Values
+// that were encrypted with previous data keys may still be decrypted.
+func (p *Provider) RotateDataKey() error {
+	return p.keys.rotate()
+}
+
+// MongoDBProvider is an encrypting storage provider for MongoDB. The stores opened by this provider support
+// the MongoDB-specific APIs. Since documents are queried by field, the fields which are indexed (using
+// CreateCustomIndexes) are stored in plaintext alongside the encrypted document.
+type MongoDBProvider struct {
+	*Provider
+
+	mp mongoDBProvider
+
+	mutex         sync.RWMutex
+	indexedFields map[string][]string
+}
+
+// NewMongoDB returns a new encrypting storage provider for MongoDB.
+func NewMongoDB(p mongoDBProvider, keyWrapper KeyWrapper, kekID string) (*MongoDBProvider, error) {
+	ep, err := New(p, keyWrapper, kekID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MongoDBProvider{
+		Provider:      ep,
+		mp:            p,
+		indexedFields: make(map[string][]string),
+	}, nil
+}
+
+// OpenStore opens the store with the given name and returns an encrypting MongoDB store.
+func (p *MongoDBProvider) OpenStore(name string) (storage.Store, error) {
+	s, err := p.mp.OpenStore(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return newMongoDBStore(newStore(name, s, p.keys), func() []string { return p.getIndexedFields(name) }), nil
+}
+
+// CreateCustomIndexes creates MongoDB indexes. The indexed fields are stored in plaintext so that
+// documents may be queried by these fields.
+func (p *MongoDBProvider) CreateCustomIndexes(storeName string, models ...mongo.IndexModel) error {
+	if err := p.mp.CreateCustomIndexes(storeName, models...); err != nil {
+		return err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, model := range models {
+		keys, ok := model.Keys.(bson.D)
+		if !ok {
+			continue
+		}
+
+		for _, key := range keys {
+			if !contains(p.indexedFields[storeName], key.Key) {
+				p.indexedFields[storeName] = append(p.indexedFields[storeName], key.Key)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *MongoDBProvider) getIndexedFields(storeName string) []string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.indexedFields[storeName]
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,378 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package encrypted
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	storeName = "store1"
+	key1      = "key1"
+	key2      = "key2"
+	tagName   = "tag1"
+)
+
+func TestProvider(t *testing.T) {
+	kw, kekID := newKeyWrapper(t)
+
+	t.Run("Put and Get", func(t *testing.T) {
+		p := newMemProvider()
+
+		ep, err := New(p, kw, kekID)
+		require.NoError(t, err)
+
+		s, err := ep.OpenStore(storeName)
+		require.NoError(t, err)
+
+		value := []byte(`{"field1":"secret value"}`)
+
+		require.NoError(t, s.Put(key1, value, storage.Tag{Name: tagName, Value: "value1"}))
+
+		v, err := s.Get(key1)
+		require.NoError(t, err)
+		require.Equal(t, value, v)
+
+		// The value in the underlying store should be encrypted.
+		raw := getRaw(t, p, storeName, key1)
+		require.NotContains(t, string(raw), "secret value")
+		require.Contains(t, string(raw), encryptedValueField)
+
+		// Tags should not be encrypted.
+		tags, err := s.GetTags(key1)
+		require.NoError(t, err)
+		require.Equal(t, []storage.Tag{{Name: tagName, Value: "value1"}}, tags)
+
+		_, err = s.Get(key2)
+		require.ErrorIs(t, err, storage.ErrDataNotFound)
+	})
+
+	t.Run("Plaintext value", func(t *testing.T) {
+		p := newMemProvider()
+
+		ep, err := New(p, kw, kekID)
+		require.NoError(t, err)
+
+		us, err := p.OpenStore(storeName)
+		require.NoError(t, err)
+
+		// Values that were stored before encryption was enabled are returned as is.
+		require.NoError(t, us.Put(key1, []byte(`{"field1":"value1"}`)))
+		require.NoError(t, us.Put(key2, []byte(`{"encryptedValue":null}`)))
+
+		s, err := ep.OpenStore(storeName)
+		require.NoError(t, err)
+
+		v, err := s.Get(key1)
+		require.NoError(t, err)
+		require.Equal(t, `{"field1":"value1"}`, string(v))
+
+		v, err = s.Get(key2)
+		require.NoError(t, err)
+		require.Equal(t, `{"encryptedValue":null}`, string(v))
+	})
+
+	t.Run("GetBulk", func(t *testing.T) {
+		ep, err := New(newMemProvider(), kw, kekID)
+		require.NoError(t, err)
+
+		s, err := ep.OpenStore(storeName)
+		require.NoError(t, err)
+
+		require.NoError(t, s.Put(key1, []byte("value1")))
+
+		values, err := s.GetBulk(key1, key2)
+		require.NoError(t, err)
+		require.Len(t, values, 2)
+		require.Equal(t, "value1", string(values[0]))
+		require.Nil(t, values[1])
+	})
+
+	t.Run("Query", func(t *testing.T) {
+		ep, err := New(newMemProvider(), kw, kekID)
+		require.NoError(t, err)
+
+		s, err := ep.OpenStore(storeName)
+		require.NoError(t, err)
+
+		require.NoError(t, s.Put(key1, []byte("value1"), storage.Tag{Name: tagName, Value: "a"}))
+		require.NoError(t, s.Put(key2, []byte("value2"), storage.Tag{Name: tagName, Value: "b"}))
+
+		it, err := s.Query(tagName + ":b")
+		require.NoError(t, err)
+
+		ok, err := it.Next()
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		v, err := it.Value()
+		require.NoError(t, err)
+		require.Equal(t, "value2", string(v))
+
+		ok, err = it.Next()
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		require.NoError(t, it.Close())
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		p := newMemProvider()
+
+		ep, err := New(p, kw, kekID)
+		require.NoError(t, err)
+
+		s, err := ep.OpenStore(storeName)
+		require.NoError(t, err)
+
+		require.NoError(t, s.Put(key2, []byte("value2")))
+
+		require.NoError(t, s.Batch([]storage.Operation{
+			{Key: key1, Value: []byte("secret value")},
+			{Key: key2},
+		}))
+
+		v, err := s.Get(key1)
+		require.NoError(t, err)
+		require.Equal(t, "secret value", string(v))
+		require.NotContains(t, string(getRaw(t, p, storeName, key1)), "secret value")
+
+		_, err = s.Get(key2)
+		require.ErrorIs(t, err, storage.ErrDataNotFound)
+	})
+
+	t.Run("Value copied to another key", func(t *testing.T) {
+		p := newMemProvider()
+
+		ep, err := New(p, kw, kekID)
+		require.NoError(t, err)
+
+		s, err := ep.OpenStore(storeName)
+		require.NoError(t, err)
+
+		require.NoError(t, s.Put(key1, []byte("value1")))
+
+		us, err := p.OpenStore(storeName)
+		require.NoError(t, err)
+
+		require.NoError(t, us.Put(key2, getRaw(t, p, storeName, key1)))
+
+		_, err = s.Get(key2)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "decrypt value [store1-key2]")
+	})
+
+	t.Run("Another instance", func(t *testing.T) {
+		p := newMemProvider()
+
+		ep1, err := New(p, kw, kekID)
+		require.NoError(t, err)
+
+		s1, err := ep1.OpenStore(storeName)
+		require.NoError(t, err)
+
+		ep2, err := New(p, kw, kekID)
+		require.NoError(t, err)
+
+		s2, err := ep2.OpenStore(storeName)
+		require.NoError(t, err)
+
+		// Rotate the data key on the first instance. The second instance should load the new key on demand.
+		require.NoError(t, ep1.RotateDataKey())
+
+		require.NoError(t, s1.Put(key1, []byte("value1")))
+
+		v, err := s2.Get(key1)
+		require.NoError(t, err)
+		require.Equal(t, "value1", string(v))
+	})
+
+	t.Run("Rotate data key", func(t *testing.T) {
+		ep, err := New(newMemProvider(), kw, kekID)
+		require.NoError(t, err)
+
+		s, err := ep.OpenStore(storeName)
+		require.NoError(t, err)
+
+		require.NoError(t, s.Put(key1, []byte("value1")))
+
+		kid1, _ := ep.keys.active()
+
+		require.NoError(t, ep.RotateDataKey())
+
+		kid2, _ := ep.keys.active()
+		require.NotEqual(t, kid1, kid2)
+
+		require.NoError(t, s.Put(key2, []byte("value2")))
+
+		v, err := s.Get(key1)
+		require.NoError(t, err)
+		require.Equal(t, "value1", string(v))
+
+		v, err = s.Get(key2)
+		require.NoError(t, err)
+		require.Equal(t, "value2", string(v))
+	})
+
+	t.Run("Rotate key-encryption key", func(t *testing.T) {
+		p := newMemProvider()
+
+		km := newLocalKMS(t)
+
+		cr, err := tinkcrypto.New()
+		require.NoError(t, err)
+
+		kekID1, _, err := km.Create(kms.AES256GCMType)
+		require.NoError(t, err)
+
+		kekID2, _, err := km.Create(kms.AES256GCMType)
+		require.NoError(t, err)
+
+		kw := &mockKeyWrapper{KeyWrapper: NewKMSKeyWrapper(km, cr), keys: map[string]bool{kekID1: true}}
+
+		ep1, err := New(p, kw, kekID1)
+		require.NoError(t, err)
+
+		s1, err := ep1.OpenStore(storeName)
+		require.NoError(t, err)
+
+		require.NoError(t, s1.Put(key1, []byte("value1")))
+		require.NoError(t, ep1.RotateDataKey())
+		require.NoError(t, s1.Put(key2, []byte("value2")))
+
+		// Rotate the key-encryption key. All data keys should be re-wrapped with the new key.
+		kw.keys[kekID2] = true
+
+		_, err = New(p, kw, kekID2)
+		require.NoError(t, err)
+
+		// Retire the old key-encryption key.
+		delete(kw.keys, kekID1)
+
+		ep2, err := New(p, kw, kekID2)
+		require.NoError(t, err)
+
+		s2, err := ep2.OpenStore(storeName)
+		require.NoError(t, err)
+
+		v, err := s2.Get(key1)
+		require.NoError(t, err)
+		require.Equal(t, "value1", string(v))
+
+		v, err = s2.Get(key2)
+		require.NoError(t, err)
+		require.Equal(t, "value2", string(v))
+	})
+
+	t.Run("Wrap key error", func(t *testing.T) {
+		kw := &mockKeyWrapper{KeyWrapper: kw}
+
+		_, err := New(newMemProvider(), kw, kekID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "wrap data key")
+	})
+
+	t.Run("Unwrap key error", func(t *testing.T) {
+		p := newMemProvider()
+
+		_, err := New(p, kw, kekID)
+		require.NoError(t, err)
+
+		_, err = New(p, &mockKeyWrapper{KeyWrapper: kw}, kekID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unwrap data key")
+	})
+
+	t.Run("Open store error", func(t *testing.T) {
+		errExpected := errors.New("injected open store error")
+
+		_, err := New(&memProvider{Provider: mem.NewProvider(), err: errExpected}, kw, kekID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+}
+
+func newKeyWrapper(t *testing.T) (KeyWrapper, string) {
+	t.Helper()
+
+	km := newLocalKMS(t)
+
+	cr, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	kekID, _, err := km.Create(kms.AES256GCMType)
+	require.NoError(t, err)
+
+	return NewKMSKeyWrapper(km, cr), kekID
+}
+
+func getRaw(t *testing.T, p storage.Provider, name, key string) []byte {
+	t.Helper()
+
+	s, err := p.OpenStore(name)
+	require.NoError(t, err)
+
+	raw, err := s.Get(key)
+	require.NoError(t, err)
+
+	require.True(t, json.Valid(raw))
+
+	return raw
+}
+
+type memProvider struct {
+	storage.Provider
+
+	err error
+}
+
+func newMemProvider() *memProvider {
+	return &memProvider{Provider: mem.NewProvider()}
+}
+
+func (p *memProvider) OpenStore(name string) (storage.Store, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	return p.Provider.OpenStore(name)
+}
+
+func (p *memProvider) Ping() error {
+	return nil
+}
+
+// mockKeyWrapper only allows the key-encryption keys in the keys map to be used.
+type mockKeyWrapper struct {
+	KeyWrapper
+
+	keys map[string]bool
+}
+
+func (w *mockKeyWrapper) WrapKey(kekID string, key []byte) ([]byte, error) {
+	if !w.keys[kekID] {
+		return nil, errors.New("key not found")
+	}
+
+	return w.KeyWrapper.WrapKey(kekID, key)
+}
+
+func (w *mockKeyWrapper) UnwrapKey(kekID string, wrapped []byte) ([]byte, error) {
+	if !w.keys[kekID] {
+		return nil, errors.New("key not found")
+	}
+
+	return w.KeyWrapper.UnwrapKey(kekID, wrapped)
+}
@@ -0,0 +1,211 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package encrypted
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+)
+
+// encryptedValueField is the name of the field which holds the encrypted value in a stored document.
+const encryptedValueField = "encryptedValue"
+
+var encryptedValueFieldBytes = []byte(`"` + encryptedValueField + `"`)
+
+type envelope struct {
+	KeyID      string `json:"kid"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ct"`
+}
+
+type encryptedDoc struct {
+	EncryptedValue *envelope `json:"encryptedValue"`
+}
+
+// Store encrypts values before they are persisted to the underlying store and decrypts them when they are
+// retrieved. Tags are not encrypted so that they may be queried. Values that were stored in plaintext (before
+// encryption was enabled) are returned as is.
+type Store struct {
+	storage.Store
+
+	name string
+	keys *keyRing
+}
+
+func newStore(name string, s storage.Store, keys *keyRing) *Store {
+	return &Store{
+		Store: s,
+		name:  name,
+		keys:  keys,
+	}
+}
+
+// Put encrypts the given value and stores it along with the given (plaintext) tags.
+func (s *Store) Put(key string, value []byte, tags ...storage.Tag) error {
+	encValue, err := s.encrypt(key, value)
+	if err != nil {
+		return err
+	}
+
+	return s.Store.Put(key, encValue, tags...)
+}
+
+// Get returns the decrypted value for the given key.
+func (s *Store) Get(key string) ([]byte, error) {
+	value, err := s.Store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.decrypt(key, value)
+}
+
+// GetBulk returns the decrypted values for the given keys.
+func (s *Store) GetBulk(keys ...string) ([][]byte, error) {
+	values, err := s.Store.GetBulk(keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+
+		values[i], err = s.decrypt(keys[i], value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+// Query queries the underlying store using the given expression and returns an iterator
+// which decrypts the values.
+func (s *Store) Query(expression string, options ...storage.QueryOption) (storage.Iterator, error) {
+	it, err := s.Store.Query(expression, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &iterator{Iterator: it, store: s}, nil
+}
+
+// Batch encrypts the values of all put operations and executes the batch on the underlying store.
+func (s *Store) Batch(operations []storage.Operation) error {
+	encOperations := make([]storage.Operation, len(operations))
+
+	for i, op := range operations {
+		encOperations[i] = op
+
+		if op.Value == nil {
+			// Delete operation.
+			continue
+		}
+
+		encValue, err := s.encrypt(op.Key, op.Value)
+		if err != nil {
+			return err
+		}
+
+		encOperations[i].Value = encValue
+	}
+
+	return s.Store.Batch(encOperations)
+}
+
+func (s *Store) encrypt(key string, value []byte) ([]byte, error) {
+	env, err := s.seal(key, value)
+	if err != nil {
+		return nil, err
+	}
+
+	encValue, err := json.Marshal(&encryptedDoc{EncryptedValue: env})
+	if err != nil {
+		return nil, fmt.Errorf("marshal encrypted value [%s-%s]: %w", s.name, key, err)
+	}
+
+	return encValue, nil
+}
+
+func (s *Store) decrypt(key string, value []byte) ([]byte, error) {
+	if !bytes.Contains(value, encryptedValueFieldBytes) {
+		// The value was stored before encryption was enabled.
+		return value, nil
+	}
+
+	doc := &encryptedDoc{}
+
+	if err := json.Unmarshal(value, doc); err != nil || doc.EncryptedValue == nil {
+		// Not an encrypted value.
+		return value, nil //nolint:nilerr
+	}
+
+	return s.open(key, doc.EncryptedValue)
+}
+
+func (s *Store) seal(key string, value []byte) (*envelope, error) {
+	kid, c := s.keys.active()
+
+	nonce := make([]byte, c.NonceSize())
+
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+
+	return &envelope{
+		KeyID:      kid,
+		Nonce:      nonce,
+		Ciphertext: c.Seal(nil, nonce, value, s.aad(key)),
+	}, nil
+}
+
+func (s *Store) open(key string, env *envelope) ([]byte, error) {
+	c, err := s.keys.get(env.KeyID)
+	if err != nil {
+		return nil, fmt.Errorf("get data key for [%s-%s]: %w", s.name, key, err)
+	}
+
+	value, err := c.Open(nil, env.Nonce, env.Ciphertext, s.aad(key))
+	if err != nil {
+		return nil, fmt.Errorf("decrypt value [%s-%s]: %w", s.name, key, err)
+	}
+
+	return value, nil
+}
+
+// aad returns the additional authenticated data for the given key. The ciphertext is bound to the store name
+// and key so that an encrypted value may not be copied to another key.
+func (s *Store) aad(key string) []byte {
+	return []byte(s.name + "/" + key)
+}
+
+type iterator struct {
+	storage.Iterator
+
+	store *Store
+}
+
+// Value returns the decrypted value of the current entry.
+func (it *iterator) Value() ([]byte, error) {
+	key, err := it.Iterator.Key()
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := it.Iterator.Value()
+	if err != nil {
+		return nil, err
+	}
+
+	return it.store.decrypt(key, value)
+}
@@ -0,0 +1,417 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package logentry
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/vct/pkg/controller/command"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/hashlink"
+)
+
+const (
+	archiveKeyPrefix  = "archive_"
+	archiveLogTagName = "archiveLogUrl"
+
+	archiveFilePerm = 0o600
+)
+
+// Archive contains a range of log entries that were removed from the store by compaction.
+type Archive struct {
+	LogURL  string          `json:"logUrl"`
+	From    uint64          `json:"from"`
+	To      uint64          `json:"to"`
+	Created time.Time       `json:"created"`
+	Entries []*ArchiveEntry `json:"entries"`
+}
+
+// ArchiveEntry is a log entry (with its index in the log) contained in an archive.
+type ArchiveEntry struct {
+	Index     uint64            `json:"index"`
+	LeafEntry command.LeafEntry `json:"leafEntry"`
+}
+
+// ArchiveRecord describes an archive that was produced by compaction.
+type ArchiveRecord struct {
+	LogURL   string    `json:"logUrl"`
+	From     uint64    `json:"from"`
+	To       uint64    `json:"to"`
+	Count    int       `json:"count"`
+	Location string    `json:"location"`
+	Created  time.Time `json:"created"`
+}
+
+// Archiver writes archives of compacted log entries and reads them back.
+type Archiver interface {
+	// Archive writes the given archive and returns its location.
+	Archive(a *Archive) (string, error)
+	// Read reads the archive at the given location.
+	Read(location string) (*Archive, error)
+}
+
+// FileArchiver writes archives as JSON files to a local directory.
+type FileArchiver struct {
+	dir string
+}
+
+// NewFileArchiver returns a new archiver that writes archives to the given directory.
+func NewFileArchiver(dir string) (*FileArchiver, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("create archive directory [%s]: %w", dir, err)
+	}
+
+	return &FileArchiver{dir: dir}, nil
+}
+
+// Archive writes the given archive to a file and returns the path of the file.
+func (a *FileArchiver) Archive(archive *Archive) (string, error) {
+	archiveBytes, err := json.Marshal(archive)
+	if err != nil {
+		return "", fmt.Errorf("marshal archive: %w", err)
+	}
+
+	path := filepath.Join(a.dir, fmt.Sprintf("%s-%d-%d.json",
+		base64.RawURLEncoding.EncodeToString([]byte(archive.LogURL)), archive.From, archive.To))
+
+	if err := os.WriteFile(path, archiveBytes, archiveFilePerm); err != nil {
+		return "", fmt.Errorf("write archive file [%s]: %w", path, err)
+	}
+
+	return path, nil
+}
+
+// Read reads the archive from the given file.
+func (a *FileArchiver) Read(path string) (*Archive, error) {
+	archiveBytes, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("read archive file [%s]: %w", path, err)
+	}
+
+	return unmarshalArchive(archiveBytes)
+}
+
+type casClient interface {
+	Write(content []byte) (string, error)
+	Read(address string) ([]byte, error)
+}
+
+// CASArchiver writes archives to content addressable storage.
+type CASArchiver struct {
+	cas casClient
+}
+
+// NewCASArchiver returns a new archiver that writes archives to the given CAS.
+func NewCASArchiver(cas casClient) *CASArchiver {
+	return &CASArchiver{cas: cas}
+}
+
+// Archive writes the given archive to CAS and returns its CID.
+func (a *CASArchiver) Archive(archive *Archive) (string, error) {
+	archiveBytes, err := json.Marshal(archive)
+	if err != nil {
+		return "", fmt.Errorf("marshal archive: %w", err)
+	}
+
+	cid, err := a.cas.Write(archiveBytes)
+	if err != nil {
+		return "", orberrors.NewTransient(fmt.Errorf("write archive to CAS: %w", err))
+	}
+
+	return cid, nil
+}
+
+// Read reads the archive with the given CID (or hashlink) from CAS.
+func (a *CASArchiver) Read(cid string) (*Archive, error) {
+	address := cid
+
+	if strings.HasPrefix(cid, hashlink.HLPrefix) {
+		// The local CAS returns a hashlink when writing content but reads content by resource hash.
+		resourceHash, err := hashlink.GetResourceHashFromHashLink(cid)
+		if err != nil {
+			return nil, fmt.Errorf("get resource hash from hashlink [%s]: %w", cid, err)
+		}
+
+		address = resourceHash
+	}
+
+	archiveBytes, err := a.cas.Read(address)
+	if err != nil {
+		return nil, fmt.Errorf("read archive [%s] from CAS: %w", cid, err)
+	}
+
+	return unmarshalArchive(archiveBytes)
+}
+
+func unmarshalArchive(archiveBytes []byte) (*Archive, error) {
+	archive := &Archive{}
+
+	if err := json.Unmarshal(archiveBytes, archive); err != nil {
+		return nil, fmt.Errorf("unmarshal archive: %w", err)
+	}
+
+	return archive, nil
+}
+
+// GetArchives returns the records of all archives that were produced for the given log.
+func (s *Store) GetArchives(logURL string) ([]*ArchiveRecord, error) {
+	if logURL == "" {
+		return nil, errors.New("missing log URL")
+	}
+
+	query := fmt.Sprintf("%s:%s", archiveLogTagName, base64.RawURLEncoding.EncodeToString([]byte(logURL)))
+
+	iterator, err := s.store.Query(query)
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("failed to query archive records: %w", err))
+	}
+
+	defer func() {
+		if errClose := iterator.Close(); errClose != nil {
+			log.CloseIteratorError(logger, errClose)
+		}
+	}()
+
+	var records []*ArchiveRecord
+
+	for {
+		ok, err := iterator.Next()
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("failed to determine if there are more results: %w", err))
+		}
+
+		if !ok {
+			break
+		}
+
+		recordBytes, err := iterator.Value()
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("failed to get value: %w", err))
+		}
+
+		record := &ArchiveRecord{}
+
+		if err := json.Unmarshal(recordBytes, record); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal archive record: %w", err)
+		}
+
+		records = append(records, record)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].From < records[j].From
+	})
+
+	return records, nil
+}
+
+// GetArchiveLocations returns the locations of all archives that were produced for all logs.
+func (s *Store) GetArchiveLocations() ([]string, error) {
+	iterator, err := s.store.Query(archiveLogTagName)
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("failed to query archive records: %w", err))
+	}
+
+	defer func() {
+		if errClose := iterator.Close(); errClose != nil {
+			log.CloseIteratorError(logger, errClose)
+		}
+	}()
+
+	var locations []string
+
+	for {
+		ok, err := iterator.Next()
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("failed to determine if there are more results: %w", err))
+		}
+
+		if !ok {
+			return locations, nil
+		}
+
+		recordBytes, err := iterator.Value()
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("failed to get value: %w", err))
+		}
+
+		record := &ArchiveRecord{}
+
+		if err := json.Unmarshal(recordBytes, record); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal archive record: %w", err)
+		}
+
+		locations = append(locations, record.Location)
+	}
+}
+
+// ImportArchive reads the archive at the given location (using the configured archiver) and imports its entries.
+func (s *Store) ImportArchive(location string) error {
+	if s.archiver == nil {
+		return errors.New("archiver is not configured")
+	}
+
+	archive, err := s.archiver.Read(location)
+	if err != nil {
+		return fmt.Errorf("read archive: %w", err)
+	}
+
+	return s.Import(archive)
+}
+
+// Import re-imports the entries of a previously compacted archive (e.g. for audits). Imported entries are
+// stored with status 'imported' so that they are not returned by GetLogEntries and are not subject to
+// compaction. They may be retrieved using GetImportedLogEntries and removed using DeleteImportedLogEntries.
+func (s *Store) Import(archive *Archive) error {
+	if archive.LogURL == "" {
+		return errors.New("missing log URL in archive")
+	}
+
+	if len(archive.Entries) == 0 {
+		return errors.New("no entries in archive")
+	}
+
+	encodedLogURL := base64.RawURLEncoding.EncodeToString([]byte(archive.LogURL))
+
+	operations := make([]storage.Operation, len(archive.Entries))
+
+	for i, entry := range archive.Entries {
+		logEntryBytes, err := json.Marshal(&LogEntry{
+			Index:     int(entry.Index),
+			LeafEntry: entry.LeafEntry,
+			LogURL:    encodedLogURL,
+			Status:    EntryStatusImported,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal log entry: %w", err)
+		}
+
+		operations[i] = storage.Operation{
+			Key:   uuid.New().String(),
+			Value: logEntryBytes,
+			Tags: []storage.Tag{
+				{Name: logTagName, Value: encodedLogURL},
+				{Name: indexTagName, Value: strconv.FormatUint(entry.Index, 10)},
+				{Name: statusTagName, Value: string(EntryStatusImported)},
+			},
+		}
+	}
+
+	if err := s.store.Batch(operations); err != nil {
+		return orberrors.NewTransient(fmt.Errorf("failed to import entries for log: %w", err))
+	}
+
+	logger.Info("Imported archived entries for log", log.WithTotal(len(operations)),
+		log.WithLogURLString(archive.LogURL), log.WithFromIndexUint64(archive.From),
+		log.WithToIndexUint64(archive.To))
+
+	return nil
+}
+
+// GetImportedLogEntries retrieves log entries that were imported from archives.
+func (s *Store) GetImportedLogEntries(logURL string) (EntryIterator, error) {
+	if logURL == "" {
+		return nil, errors.New("missing log URL")
+	}
+
+	query := fmt.Sprintf("%s:%s&&%s:%s", logTagName, base64.RawURLEncoding.EncodeToString([]byte(logURL)),
+		statusTagName, EntryStatusImported)
+
+	return s.queryEntries(query)
+}
+
+// DeleteImportedLogEntries deletes all log entries that were imported from archives for the given log.
+func (s *Store) DeleteImportedLogEntries(logURL string) error {
+	if logURL == "" {
+		return errors.New("missing log URL")
+	}
+
+	query := fmt.Sprintf("%s:%s&&%s:%s", logTagName, base64.RawURLEncoding.EncodeToString([]byte(logURL)),
+		statusTagName, EntryStatusImported)
+
+	keys, err := s.queryKeys(query)
+	if err != nil {
+		return err
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	return s.deleteKeys(keys)
+}
+
+// archive writes the given entries to an archive (if an archiver is configured) and stores a record of the archive.
+func (s *Store) archive(logURL string, entries []*LogEntry) error {
+	if s.archiver == nil || len(entries) == 0 {
+		return nil
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Index < entries[j].Index
+	})
+
+	archive := &Archive{
+		LogURL:  logURL,
+		From:    uint64(entries[0].Index),
+		To:      uint64(entries[len(entries)-1].Index),
+		Created: time.Now(),
+		Entries: make([]*ArchiveEntry, len(entries)),
+	}
+
+	for i, entry := range entries {
+		archive.Entries[i] = &ArchiveEntry{
+			Index:     uint64(entry.Index),
+			LeafEntry: entry.LeafEntry,
+		}
+	}
+
+	location, err := s.archiver.Archive(archive)
+	if err != nil {
+		return fmt.Errorf("archive entries for log [%s]: %w", logURL, err)
+	}
+
+	record := &ArchiveRecord{
+		LogURL:   logURL,
+		From:     archive.From,
+		To:       archive.To,
+		Count:    len(archive.Entries),
+		Location: location,
+		Created:  archive.Created,
+	}
+
+	recordBytes, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshal archive record: %w", err)
+	}
+
+	encodedLogURL := base64.RawURLEncoding.EncodeToString([]byte(logURL))
+
+	err = s.store.Put(fmt.Sprintf("%s%s_%d_%d", archiveKeyPrefix, encodedLogURL, record.From, record.To),
+		recordBytes, storage.Tag{Name: archiveLogTagName, Value: encodedLogURL})
+	if err != nil {
+		return orberrors.NewTransient(fmt.Errorf("store archive record: %w", err))
+	}
+
+	logger.Info("Archived log entries", log.WithLogURLString(logURL), log.WithTotal(record.Count),
+		log.WithFromIndexUint64(record.From), log.WithToIndexUint64(record.To), log.WithURIString(location))
+
+	return nil
+}
@@ -0,0 +1,379 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package logentry
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go-ext/component/storage/mongodb"
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/vct/pkg/controller/command"
+
+	casgc "github.com/trustbloc/orb/pkg/cas/gc"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	"github.com/trustbloc/orb/pkg/internal/testutil/mongodbtestutil"
+	orbmocks "github.com/trustbloc/orb/pkg/mocks"
+	localcas "github.com/trustbloc/orb/pkg/store/cas"
+)
+
+func TestFileArchiver(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		a, err := NewFileArchiver(filepath.Join(t.TempDir(), "archives"))
+		require.NoError(t, err)
+
+		location, err := a.Archive(newTestArchive(3, 5))
+		require.NoError(t, err)
+		require.NotEmpty(t, location)
+
+		archive, err := a.Read(location)
+		require.NoError(t, err)
+		require.Equal(t, logURL, archive.LogURL)
+		require.Equal(t, uint64(3), archive.From)
+		require.Equal(t, uint64(5), archive.To)
+		require.Len(t, archive.Entries, 3)
+	})
+
+	t.Run("error - read file", func(t *testing.T) {
+		a, err := NewFileArchiver(t.TempDir())
+		require.NoError(t, err)
+
+		archive, err := a.Read(filepath.Join(t.TempDir(), "invalid.json"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "read archive file")
+		require.Nil(t, archive)
+	})
+}
+
+func TestCASArchiver(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		a := NewCASArchiver(&mockCAS{})
+
+		location, err := a.Archive(newTestArchive(0, 1))
+		require.NoError(t, err)
+		require.NotEmpty(t, location)
+
+		archive, err := a.Read(location)
+		require.NoError(t, err)
+		require.Equal(t, logURL, archive.LogURL)
+		require.Len(t, archive.Entries, 2)
+	})
+
+	t.Run("success - local CAS", func(t *testing.T) {
+		localCAS, err := localcas.New(mem.NewProvider(), "https://domain.com/cas", nil, &orbmocks.MetricsProvider{}, 0)
+		require.NoError(t, err)
+
+		a := NewCASArchiver(localCAS)
+
+		location, err := a.Archive(newTestArchive(0, 1))
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(location, "hl:"))
+
+		archive, err := a.Read(location)
+		require.NoError(t, err)
+		require.Equal(t, logURL, archive.LogURL)
+		require.Len(t, archive.Entries, 2)
+	})
+
+	t.Run("error - write to CAS", func(t *testing.T) {
+		a := NewCASArchiver(&mockCAS{writeErr: errors.New("injected write error")})
+
+		location, err := a.Archive(newTestArchive(0, 1))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected write error")
+		require.Empty(t, location)
+	})
+
+	t.Run("error - read from CAS", func(t *testing.T) {
+		a := NewCASArchiver(&mockCAS{readErr: errors.New("injected read error")})
+
+		archive, err := a.Read("cid")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected read error")
+		require.Nil(t, archive)
+	})
+
+	t.Run("error - invalid archive", func(t *testing.T) {
+		a := NewCASArchiver(&mockCAS{content: []byte("{")})
+
+		archive, err := a.Read("cid")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal archive")
+		require.Nil(t, archive)
+	})
+}
+
+func TestCASArchiver_GarbageCollection(t *testing.T) {
+	localCAS, err := localcas.New(mem.NewProvider(), "https://domain.com/cas", nil, &orbmocks.MetricsProvider{}, 0)
+	require.NoError(t, err)
+
+	s, err := New(mem.NewProvider(), WithArchiver(NewCASArchiver(localCAS)))
+	require.NoError(t, err)
+
+	require.NoError(t, s.StoreLogEntries(logURL, 0, 2, newTestEntries(3)))
+
+	keys, err := s.queryKeys(fmt.Sprintf("%s:%s", statusTagName, EntryStatusSuccess))
+	require.NoError(t, err)
+
+	require.NoError(t, s.HandleExpiredKeys(keys...))
+
+	records, err := s.GetArchives(logURL)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+
+	archiveHash, err := hashlink.GetResourceHashFromHashLink(records[0].Location)
+	require.NoError(t, err)
+
+	orphan, err := localCAS.Write([]byte("orphan"))
+	require.NoError(t, err)
+
+	orphanHash, err := hashlink.GetResourceHashFromHashLink(orphan)
+	require.NoError(t, err)
+
+	// Creation times are recorded in seconds so wait for the content to be older than the start of the run.
+	time.Sleep(time.Second)
+
+	t.Run("without archives", func(t *testing.T) {
+		c, err := casgc.New(mem.NewProvider(), localCAS, &mockReferenceProvider{}, nil, nil, 0,
+			casgc.WithGracePeriod(0))
+		require.NoError(t, err)
+
+		report, err := c.Run(true)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{archiveHash, orphanHash}, report.Garbage)
+	})
+
+	t.Run("with archives", func(t *testing.T) {
+		c, err := casgc.New(mem.NewProvider(), localCAS, &mockReferenceProvider{}, nil, nil, 0,
+			casgc.WithGracePeriod(0), casgc.WithArchives(s))
+		require.NoError(t, err)
+
+		report, err := c.Run(false)
+		require.NoError(t, err)
+		require.Equal(t, 1, report.Archived)
+		require.Equal(t, 1, report.Deleted)
+		require.Equal(t, []string{orphanHash}, report.Garbage)
+
+		archive, err := NewCASArchiver(localCAS).Read(records[0].Location)
+		require.NoError(t, err)
+		require.Len(t, archive.Entries, 3)
+	})
+}
+
+func TestStore_HandleExpiredKeys(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		provider := mem.NewProvider()
+
+		archiver, err := NewFileArchiver(t.TempDir())
+		require.NoError(t, err)
+
+		s, err := New(provider, WithArchiver(archiver))
+		require.NoError(t, err)
+
+		require.NoError(t, s.StoreLogEntries(logURL, 0, 2, newTestEntries(3)))
+
+		keys, err := s.queryKeys(fmt.Sprintf("%s:%s", statusTagName, EntryStatusSuccess))
+		require.NoError(t, err)
+		require.Len(t, keys, 3)
+
+		require.NoError(t, s.HandleExpiredKeys(keys...))
+
+		records, err := s.GetArchives(logURL)
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		require.Equal(t, uint64(0), records[0].From)
+		require.Equal(t, uint64(2), records[0].To)
+		require.Equal(t, 3, records[0].Count)
+
+		archive, err := archiver.Read(records[0].Location)
+		require.NoError(t, err)
+		require.Len(t, archive.Entries, 3)
+
+		locations, err := s.GetArchiveLocations()
+		require.NoError(t, err)
+		require.Equal(t, []string{records[0].Location}, locations)
+	})
+
+	t.Run("no archiver", func(t *testing.T) {
+		s, err := New(mem.NewProvider())
+		require.NoError(t, err)
+
+		require.NoError(t, s.HandleExpiredKeys("key1"))
+	})
+
+	t.Run("error - archive", func(t *testing.T) {
+		s, err := New(mem.NewProvider(), WithArchiver(NewCASArchiver(&mockCAS{writeErr: errors.New("injected error")})))
+		require.NoError(t, err)
+
+		require.NoError(t, s.StoreLogEntries(logURL, 0, 0, newTestEntries(1)))
+
+		keys, err := s.queryKeys(fmt.Sprintf("%s:%s", statusTagName, EntryStatusSuccess))
+		require.NoError(t, err)
+
+		err = s.HandleExpiredKeys(keys...)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected error")
+	})
+}
+
+func TestStore_ImportArchive(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mongoDBConnString, stopMongo := mongodbtestutil.StartMongoDB(t)
+		defer stopMongo()
+
+		mongoDBProvider, err := mongodb.NewProvider(mongoDBConnString)
+		require.NoError(t, err)
+
+		archiver, err := NewFileArchiver(t.TempDir())
+		require.NoError(t, err)
+
+		s, err := New(mongoDBProvider, WithArchiver(archiver))
+		require.NoError(t, err)
+
+		location, err := archiver.Archive(newTestArchive(0, 4))
+		require.NoError(t, err)
+
+		require.NoError(t, s.ImportArchive(location))
+
+		iter, err := s.GetImportedLogEntries(logURL)
+		require.NoError(t, err)
+
+		n, err := iter.TotalItems()
+		require.NoError(t, err)
+		require.Equal(t, 5, n)
+
+		require.NoError(t, iter.Close())
+
+		// Imported entries are not returned as regular log entries.
+		iter, err = s.GetLogEntries(logURL)
+		require.NoError(t, err)
+
+		n, err = iter.TotalItems()
+		require.NoError(t, err)
+		require.Equal(t, 0, n)
+
+		require.NoError(t, iter.Close())
+
+		require.NoError(t, s.DeleteImportedLogEntries(logURL))
+
+		iter, err = s.GetImportedLogEntries(logURL)
+		require.NoError(t, err)
+
+		n, err = iter.TotalItems()
+		require.NoError(t, err)
+		require.Equal(t, 0, n)
+
+		require.NoError(t, iter.Close())
+	})
+
+	t.Run("error - archiver not configured", func(t *testing.T) {
+		s, err := New(mem.NewProvider())
+		require.NoError(t, err)
+
+		err = s.ImportArchive("location")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "archiver is not configured")
+	})
+
+	t.Run("error - read archive", func(t *testing.T) {
+		s, err := New(mem.NewProvider(), WithArchiver(NewCASArchiver(&mockCAS{readErr: errors.New("injected error")})))
+		require.NoError(t, err)
+
+		err = s.ImportArchive("location")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected error")
+	})
+
+	t.Run("error - invalid archive", func(t *testing.T) {
+		s, err := New(mem.NewProvider())
+		require.NoError(t, err)
+
+		err = s.Import(&Archive{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "missing log URL")
+
+		err = s.Import(&Archive{LogURL: logURL})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "no entries in archive")
+	})
+
+	t.Run("error - missing log URL", func(t *testing.T) {
+		s, err := New(mem.NewProvider())
+		require.NoError(t, err)
+
+		_, err = s.GetImportedLogEntries("")
+		require.Error(t, err)
+
+		err = s.DeleteImportedLogEntries("")
+		require.Error(t, err)
+
+		_, err = s.GetArchives("")
+		require.Error(t, err)
+	})
+}
+
+func newTestEntries(n int) []command.LeafEntry {
+	entries := make([]command.LeafEntry, n)
+
+	for i := 0; i < n; i++ {
+		entries[i] = command.LeafEntry{LeafInput: []byte(fmt.Sprintf("leafInput%d", i))}
+	}
+
+	return entries
+}
+
+func newTestArchive(from, to uint64) *Archive {
+	archive := &Archive{
+		LogURL: logURL,
+		From:   from,
+		To:     to,
+	}
+
+	for i := from; i <= to; i++ {
+		archive.Entries = append(archive.Entries, &ArchiveEntry{
+			Index:     i,
+			LeafEntry: command.LeafEntry{LeafInput: []byte(fmt.Sprintf("leafInput%d", i))},
+		})
+	}
+
+	return archive
+}
+
+type mockCAS struct {
+	content  []byte
+	writeErr error
+	readErr  error
+}
+
+func (m *mockCAS) Write(content []byte) (string, error) {
+	if m.writeErr != nil {
+		return "", m.writeErr
+	}
+
+	m.content = content
+
+	return "cid", nil
+}
+
+func (m *mockCAS) Read(string) ([]byte, error) {
+	if m.readErr != nil {
+		return nil, m.readErr
+	}
+
+	return m.content, nil
+}
+
+type mockReferenceProvider struct{}
+
+func (m *mockReferenceProvider) GetReferences() ([]string, error) {
+	return nil, nil
+}
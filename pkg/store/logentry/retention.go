@@ -0,0 +1,435 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package logentry
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/store/expiry"
+	"github.com/trustbloc/orb/pkg/store/logmonitor"
+)
+
+const (
+	compactionTaskID = "log-entry-compaction"
+
+	expiryTimeTagName = "expiryTime"
+)
+
+// RetentionPolicy defines how long log entries are kept in the store. Entries that fall outside of the policy
+// are archived (if an archiver is configured) and removed from the store.
+type RetentionPolicy struct {
+	// MaxEntries is the maximum number of the most recent entries to keep. Zero means no limit.
+	MaxEntries int
+	// MaxAge is the maximum amount of time that an entry is kept after it is stored. Zero means no limit.
+	MaxAge time.Duration
+}
+
+type taskManager interface {
+	RegisterTask(taskType string, interval time.Duration, task func())
+}
+
+type logMonitorStore interface {
+	GetActiveLogs() ([]*logmonitor.LogMonitor, error)
+	GetInactiveLogs() ([]*logmonitor.LogMonitor, error)
+}
+
+// WithRetentionPolicy sets the retention policy for all logs that don't have a log-specific policy.
+func WithRetentionPolicy(policy RetentionPolicy) Option {
+	return func(opts *Store) {
+		opts.defaultPolicy = policy
+	}
+}
+
+// WithLogRetentionPolicy sets the retention policy for the given log.
+func WithLogRetentionPolicy(logURL string, policy RetentionPolicy) Option {
+	return func(opts *Store) {
+		opts.policies[logURL] = policy
+	}
+}
+
+// WithArchiver sets the archiver which is used to archive entries before they are removed from the store.
+func WithArchiver(archiver Archiver) Option {
+	return func(opts *Store) {
+		opts.archiver = archiver
+	}
+}
+
+// WithExpiryService sets the expiry service which removes entries older than the maximum age
+// of the retention policy.
+func WithExpiryService(expiryService *expiry.Service) Option {
+	return func(opts *Store) {
+		opts.expiryService = expiryService
+	}
+}
+
+// WithCompaction registers a task that periodically removes the oldest entries of the given logs
+// according to the maximum number of entries of the retention policy.
+func WithCompaction(taskMgr taskManager, interval time.Duration, logStore logMonitorStore) Option {
+	return func(opts *Store) {
+		opts.taskMgr = taskMgr
+		opts.compactionInterval = interval
+		opts.logStore = logStore
+	}
+}
+
+func (s *Store) getPolicy(logURL string) RetentionPolicy {
+	if policy, ok := s.policies[logURL]; ok {
+		return policy
+	}
+
+	return s.defaultPolicy
+}
+
+func (s *Store) expiryTag(logURL string) (storage.Tag, bool) {
+	policy := s.getPolicy(logURL)
+
+	if policy.MaxAge <= 0 || s.expiryService == nil {
+		return storage.Tag{}, false
+	}
+
+	return storage.Tag{
+		Name:  expiryTimeTagName,
+		Value: fmt.Sprintf("%d", time.Now().Add(policy.MaxAge).Unix()),
+	}, true
+}
+
+// HandleExpiredKeys is invoked by the expiry service before entries that are older than the maximum age
+// are deleted. The expired entries are archived.
+func (s *Store) HandleExpiredKeys(keys ...string) error {
+	if s.archiver == nil || len(keys) == 0 {
+		return nil
+	}
+
+	values, err := s.store.GetBulk(keys...)
+	if err != nil {
+		return orberrors.NewTransient(fmt.Errorf("get expired entries: %w", err))
+	}
+
+	entriesByLog := make(map[string][]*LogEntry)
+
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+
+		entry := &LogEntry{}
+
+		if err := json.Unmarshal(value, entry); err != nil {
+			return fmt.Errorf("unmarshal expired entry [%s]: %w", keys[i], err)
+		}
+
+		if entry.Status != EntryStatusSuccess {
+			continue
+		}
+
+		entriesByLog[entry.LogURL] = append(entriesByLog[entry.LogURL], entry)
+	}
+
+	for encodedLogURL, entries := range entriesByLog {
+		logURL, err := base64.RawURLEncoding.DecodeString(encodedLogURL)
+		if err != nil {
+			return fmt.Errorf("decode log URL: %w", err)
+		}
+
+		if err := s.archive(string(logURL), entries); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) compactLogs() {
+	logs, err := s.getMonitoredLogs()
+	if err != nil {
+		logger.Warn("Error getting logs for compaction", log.WithError(err))
+
+		return
+	}
+
+	for _, logURL := range logs {
+		if err := s.Compact(logURL); err != nil {
+			logger.Warn("Error compacting log entries", log.WithLogURLString(logURL), log.WithError(err))
+		}
+	}
+}
+
+func (s *Store) getMonitoredLogs() ([]string, error) {
+	active, err := s.logStore.GetActiveLogs()
+	if err != nil && !errors.Is(err, orberrors.ErrContentNotFound) {
+		return nil, fmt.Errorf("get active logs: %w", err)
+	}
+
+	inactive, err := s.logStore.GetInactiveLogs()
+	if err != nil && !errors.Is(err, orberrors.ErrContentNotFound) {
+		return nil, fmt.Errorf("get inactive logs: %w", err)
+	}
+
+	logs := make([]string, 0, len(active)+len(inactive))
+
+	for _, lm := range append(active, inactive...) {
+		logs = append(logs, lm.Log)
+	}
+
+	return logs, nil
+}
+
+// Compact removes the oldest entries of the given log which exceed the maximum number of entries
+// of the retention policy. Successful entries are archived (if an archiver is configured) before they are removed.
+// Entries are processed in chunks of (at most) the page size so that each chunk is archived separately and
+// the entries of a large log are never all loaded into memory.
+func (s *Store) Compact(logURL string) error {
+	policy := s.getPolicy(logURL)
+
+	if policy.MaxEntries <= 0 {
+		return nil
+	}
+
+	encodedLogURL := base64.RawURLEncoding.EncodeToString([]byte(logURL))
+
+	maxIndex, ok, err := s.getBoundaryIndex(encodedLogURL, storage.SortDescending)
+	if err != nil {
+		return err
+	}
+
+	threshold := maxIndex - policy.MaxEntries + 1
+
+	if !ok || threshold <= 0 {
+		logger.Debug("Nothing to compact for log", log.WithLogURLString(logURL))
+
+		return nil
+	}
+
+	total := 0
+
+	for _, status := range []EntryStatus{EntryStatusSuccess, EntryStatusFailed} {
+		n, err := s.compactEntries(logURL, encodedLogURL, threshold, status)
+		if err != nil {
+			return err
+		}
+
+		total += n
+	}
+
+	if total > 0 {
+		logger.Info("Compacted log entries", log.WithLogURLString(logURL), log.WithTotal(total),
+			log.WithIndex(threshold))
+	}
+
+	return nil
+}
+
+// compactEntries removes the entries with the given status below the given index, one chunk at a time.
+// Successful entries are archived before they are removed. The number of removed entries is returned.
+func (s *Store) compactEntries(logURL, encodedLogURL string, threshold int, status EntryStatus) (int, error) {
+	total := 0
+
+	for {
+		entries, keys, err := s.getEntriesBelow(encodedLogURL, threshold, status, s.pageSize)
+		if err != nil {
+			return total, err
+		}
+
+		if len(keys) == 0 {
+			return total, nil
+		}
+
+		if status == EntryStatusSuccess {
+			if err := s.archive(logURL, entries); err != nil {
+				return total, err
+			}
+		}
+
+		if err := s.deleteKeys(keys); err != nil {
+			return total, err
+		}
+
+		total += len(keys)
+
+		if len(keys) < s.pageSize {
+			return total, nil
+		}
+	}
+}
+
+// GetFirstIndex returns the lowest index of the successful entries of the given log which are still in the store.
+// Entries below this index were either never stored or were removed by compaction (or expiry). False is returned
+// if the store has no entries for the log.
+func (s *Store) GetFirstIndex(logURL string) (uint64, bool, error) {
+	if logURL == "" {
+		return 0, false, errors.New("missing log URL")
+	}
+
+	index, ok, err := s.getBoundaryIndex(base64.RawURLEncoding.EncodeToString([]byte(logURL)), storage.SortAscending)
+	if err != nil || !ok {
+		return 0, false, err
+	}
+
+	return uint64(index), true, nil
+}
+
+// getBoundaryIndex returns the lowest (ascending order) or highest (descending order) index of the
+// successful entries of the given log.
+func (s *Store) getBoundaryIndex(encodedLogURL string, order storage.SortOrder) (int, bool, error) {
+	query := fmt.Sprintf("%s:%s&&%s:%s", logTagName, encodedLogURL, statusTagName, EntryStatusSuccess)
+
+	iterator, err := s.store.Query(query,
+		storage.WithSortOrder(&storage.SortOptions{
+			Order:   order,
+			TagName: indexTagName,
+		}),
+		storage.WithPageSize(1))
+	if err != nil {
+		return 0, false, orberrors.NewTransient(fmt.Errorf("failed to query log entry store: %w", err))
+	}
+
+	defer func() {
+		if errClose := iterator.Close(); errClose != nil {
+			log.CloseIteratorError(logger, errClose)
+		}
+	}()
+
+	exists, err := iterator.Next()
+	if err != nil {
+		return 0, false, orberrors.NewTransient(fmt.Errorf("failed to determine if there are more results: %w", err))
+	}
+
+	if !exists {
+		return 0, false, nil
+	}
+
+	entryBytes, err := iterator.Value()
+	if err != nil {
+		return 0, false, orberrors.NewTransient(fmt.Errorf("failed to get value: %w", err))
+	}
+
+	var entry LogEntry
+
+	if err := json.Unmarshal(entryBytes, &entry); err != nil {
+		return 0, false, fmt.Errorf("failed to unmarshal entry bytes: %w", err)
+	}
+
+	return entry.Index, true, nil
+}
+
+// getEntriesBelow returns (at most) the given number of entries with the given status below the given index,
+// in ascending order of index.
+func (s *Store) getEntriesBelow(encodedLogURL string, index int, status EntryStatus,
+	limit int) ([]*LogEntry, []string, error) {
+	query := fmt.Sprintf("%s:%s&&%s<%d&&%s:%s", logTagName, encodedLogURL, indexTagName, index,
+		statusTagName, status)
+
+	iterator, err := s.store.Query(query,
+		storage.WithSortOrder(&storage.SortOptions{
+			Order:   storage.SortAscending,
+			TagName: indexTagName,
+		}),
+		storage.WithPageSize(s.pageSize))
+	if err != nil {
+		return nil, nil, orberrors.NewTransient(fmt.Errorf("failed to query log entry store: %w", err))
+	}
+
+	defer func() {
+		if errClose := iterator.Close(); errClose != nil {
+			log.CloseIteratorError(logger, errClose)
+		}
+	}()
+
+	var (
+		entries []*LogEntry
+		keys    []string
+	)
+
+	for len(keys) < limit {
+		ok, err := iterator.Next()
+		if err != nil {
+			return nil, nil, orberrors.NewTransient(fmt.Errorf("failed to determine if there are more results: %w", err))
+		}
+
+		if !ok {
+			break
+		}
+
+		key, err := iterator.Key()
+		if err != nil {
+			return nil, nil, orberrors.NewTransient(fmt.Errorf("failed to get key: %w", err))
+		}
+
+		entryBytes, err := iterator.Value()
+		if err != nil {
+			return nil, nil, orberrors.NewTransient(fmt.Errorf("failed to get value: %w", err))
+		}
+
+		entry := &LogEntry{}
+
+		if err := json.Unmarshal(entryBytes, entry); err != nil {
+			return nil, nil, fmt.Errorf("failed to unmarshal entry bytes: %w", err)
+		}
+
+		entries = append(entries, entry)
+		keys = append(keys, key)
+	}
+
+	return entries, keys, nil
+}
+
+func (s *Store) queryKeys(query string) ([]string, error) {
+	iterator, err := s.store.Query(query, storage.WithPageSize(s.pageSize))
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("failed to query log entry store: %w", err))
+	}
+
+	defer func() {
+		if errClose := iterator.Close(); errClose != nil {
+			log.CloseIteratorError(logger, errClose)
+		}
+	}()
+
+	var keys []string
+
+	for {
+		ok, err := iterator.Next()
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("failed to determine if there are more results: %w", err))
+		}
+
+		if !ok {
+			break
+		}
+
+		key, err := iterator.Key()
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("failed to get key: %w", err))
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+func (s *Store) deleteKeys(keys []string) error {
+	operations := make([]storage.Operation, len(keys))
+
+	for i, key := range keys {
+		operations[i] = storage.Operation{Key: key}
+	}
+
+	if err := s.store.Batch(operations); err != nil {
+		return orberrors.NewTransient(fmt.Errorf("failed to delete %d log entries: %w", len(operations), err))
+	}
+
+	return nil
+}
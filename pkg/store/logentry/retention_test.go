@@ -0,0 +1,211 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package logentry
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go-ext/component/storage/mongodb"
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/activitypub/service/mocks"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/internal/testutil/mongodbtestutil"
+	"github.com/trustbloc/orb/pkg/store/expiry"
+	"github.com/trustbloc/orb/pkg/store/logmonitor"
+)
+
+func TestStore_Compact(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mongoDBConnString, stopMongo := mongodbtestutil.StartMongoDB(t)
+		defer stopMongo()
+
+		mongoDBProvider, err := mongodb.NewProvider(mongoDBConnString)
+		require.NoError(t, err)
+
+		archiver, err := NewFileArchiver(t.TempDir())
+		require.NoError(t, err)
+
+		s, err := New(mongoDBProvider,
+			WithArchiver(archiver),
+			WithRetentionPolicy(RetentionPolicy{MaxEntries: 100}),
+			WithLogRetentionPolicy(logURL, RetentionPolicy{MaxEntries: 3}),
+		)
+		require.NoError(t, err)
+
+		require.NoError(t, s.StoreLogEntries(logURL, 0, 9, newTestEntries(10)))
+
+		require.NoError(t, s.Compact(logURL))
+
+		iter, err := s.GetLogEntries(logURL)
+		require.NoError(t, err)
+
+		n, err := iter.TotalItems()
+		require.NoError(t, err)
+		require.Equal(t, 3, n)
+
+		require.NoError(t, iter.Close())
+
+		records, err := s.GetArchives(logURL)
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		require.Equal(t, uint64(0), records[0].From)
+		require.Equal(t, uint64(6), records[0].To)
+
+		// Nothing more to compact.
+		require.NoError(t, s.Compact(logURL))
+
+		records, err = s.GetArchives(logURL)
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+	})
+
+	t.Run("success - compacted in chunks", func(t *testing.T) {
+		mongoDBConnString, stopMongo := mongodbtestutil.StartMongoDB(t)
+		defer stopMongo()
+
+		mongoDBProvider, err := mongodb.NewProvider(mongoDBConnString)
+		require.NoError(t, err)
+
+		archiver, err := NewFileArchiver(t.TempDir())
+		require.NoError(t, err)
+
+		s, err := New(mongoDBProvider,
+			WithArchiver(archiver),
+			WithRetentionPolicy(RetentionPolicy{MaxEntries: 3}),
+		)
+		require.NoError(t, err)
+
+		s.pageSize = 2
+
+		require.NoError(t, s.StoreLogEntries(logURL, 0, 9, newTestEntries(10)))
+
+		first, ok, err := s.GetFirstIndex(logURL)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, uint64(0), first)
+
+		require.NoError(t, s.Compact(logURL))
+
+		first, ok, err = s.GetFirstIndex(logURL)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, uint64(7), first)
+
+		records, err := s.GetArchives(logURL)
+		require.NoError(t, err)
+		require.Len(t, records, 4)
+		require.Equal(t, uint64(0), records[0].From)
+		require.Equal(t, uint64(1), records[0].To)
+		require.Equal(t, uint64(6), records[3].From)
+		require.Equal(t, uint64(6), records[3].To)
+	})
+
+	t.Run("no policy", func(t *testing.T) {
+		s, err := New(mem.NewProvider())
+		require.NoError(t, err)
+
+		require.NoError(t, s.Compact(logURL))
+	})
+}
+
+func TestStore_GetFirstIndex(t *testing.T) {
+	s, err := New(mem.NewProvider())
+	require.NoError(t, err)
+
+	_, _, err = s.GetFirstIndex("")
+	require.EqualError(t, err, "missing log URL")
+
+	// The in-memory provider doesn't support sorting.
+	_, ok, err := s.GetFirstIndex(logURL)
+	require.Error(t, err)
+	require.False(t, ok)
+}
+
+func TestStore_CompactionTask(t *testing.T) {
+	taskMgr := mocks.NewTaskManager("compaction").WithInterval(50 * time.Millisecond)
+
+	taskMgr.Start()
+	defer taskMgr.Stop()
+
+	t.Run("success", func(t *testing.T) {
+		logStore, err := logmonitor.New(mem.NewProvider())
+		require.NoError(t, err)
+
+		require.NoError(t, logStore.Activate(logURL))
+
+		s, err := New(mem.NewProvider(), WithCompaction(taskMgr, 50*time.Millisecond, logStore))
+		require.NoError(t, err)
+
+		logs, err := s.getMonitoredLogs()
+		require.NoError(t, err)
+		require.Equal(t, []string{logURL}, logs)
+
+		time.Sleep(200 * time.Millisecond)
+	})
+
+	t.Run("error - get logs", func(t *testing.T) {
+		s, err := New(mem.NewProvider(),
+			WithCompaction(taskMgr, 50*time.Millisecond, &mockLogStore{err: errors.New("injected error")}))
+		require.NoError(t, err)
+
+		_, err = s.getMonitoredLogs()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected error")
+
+		time.Sleep(200 * time.Millisecond)
+	})
+}
+
+func TestStore_Expiry(t *testing.T) {
+	mongoDBConnString, stopMongo := mongodbtestutil.StartMongoDB(t)
+	defer stopMongo()
+
+	mongoDBProvider, err := mongodb.NewProvider(mongoDBConnString)
+	require.NoError(t, err)
+
+	taskMgr := mocks.NewTaskManager("expiry").WithInterval(50 * time.Millisecond)
+
+	taskMgr.Start()
+	defer taskMgr.Stop()
+
+	expiryService := expiry.NewService(taskMgr, 50*time.Millisecond)
+
+	archiver, err := NewFileArchiver(t.TempDir())
+	require.NoError(t, err)
+
+	s, err := New(mongoDBProvider,
+		WithArchiver(archiver),
+		WithExpiryService(expiryService),
+		WithRetentionPolicy(RetentionPolicy{MaxAge: time.Second}),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, s.StoreLogEntries(logURL, 0, 4, newTestEntries(5)))
+
+	require.Eventually(t, func() bool {
+		records, e := s.GetArchives(logURL)
+		require.NoError(t, e)
+
+		return len(records) == 1 && records[0].Count == 5
+	}, 5*time.Second, 100*time.Millisecond)
+}
+
+type mockLogStore struct {
+	err error
+}
+
+func (m *mockLogStore) GetActiveLogs() ([]*logmonitor.LogMonitor, error) {
+	return nil, m.err
+}
+
+func (m *mockLogStore) GetInactiveLogs() ([]*logmonitor.LogMonitor, error) {
+	return nil, orberrors.ErrContentNotFound
+}
@@ -12,6 +12,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/hyperledger/aries-framework-go/spi/storage"
@@ -20,6 +21,7 @@ import (
 	"github.com/trustbloc/orb/internal/pkg/log"
 	orberrors "github.com/trustbloc/orb/pkg/errors"
 	"github.com/trustbloc/orb/pkg/store"
+	"github.com/trustbloc/orb/pkg/store/expiry"
 )
 
 const (
@@ -42,6 +44,9 @@ const (
 
 	// EntryStatusFailed defines "failed" status.
 	EntryStatusFailed EntryStatus = "failed"
+
+	// EntryStatusImported defines "imported" status (entries re-imported from an archive).
+	EntryStatusImported EntryStatus = "imported"
 )
 
 var logger = log.New("log-entry-store")
@@ -57,6 +62,14 @@ type Store struct {
 	store storage.Store
 
 	pageSize int
+
+	defaultPolicy      RetentionPolicy
+	policies           map[string]RetentionPolicy
+	archiver           Archiver
+	expiryService      *expiry.Service
+	taskMgr            taskManager
+	compactionInterval time.Duration
+	logStore           logMonitorStore
 }
 
 // LogEntry consists of index with log and leaf entry.
@@ -81,6 +94,8 @@ type EntryIterator interface {
 func New(provider storage.Provider, opts ...Option) (*Store, error) {
 	s, err := store.Open(provider, nameSpace,
 		store.NewTagGroup(logTagName, indexTagName, statusTagName),
+		store.NewTagGroup(expiryTimeTagName),
+		store.NewTagGroup(archiveLogTagName),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open log entry store: %w", err)
@@ -89,12 +104,23 @@ func New(provider storage.Provider, opts ...Option) (*Store, error) {
 	logEntryStore := &Store{
 		pageSize: defaultPageSize,
 		store:    s,
+		policies: make(map[string]RetentionPolicy),
 	}
 
 	for _, opt := range opts {
 		opt(logEntryStore)
 	}
 
+	if logEntryStore.expiryService != nil {
+		logEntryStore.expiryService.Register(s, expiryTimeTagName, nameSpace,
+			expiry.WithExpiryHandler(logEntryStore))
+	}
+
+	if logEntryStore.taskMgr != nil && logEntryStore.logStore != nil {
+		logEntryStore.taskMgr.RegisterTask(compactionTaskID, logEntryStore.compactionInterval,
+			logEntryStore.compactLogs)
+	}
+
 	return logEntryStore, nil
 }
 
@@ -114,6 +140,8 @@ func (s *Store) StoreLogEntries(logURL string, start, end uint64, entries []comm
 
 	operations := make([]storage.Operation, len(entries))
 
+	expiryTag, expires := s.expiryTag(logURL)
+
 	for i, entry := range entries {
 		index := int(start) + i
 
@@ -150,6 +178,10 @@ func (s *Store) StoreLogEntries(logURL string, start, end uint64, entries []comm
 			Tags:  []storage.Tag{logTag, indexTag, statusTag},
 		}
 
+		if expires {
+			op.Tags = append(op.Tags, expiryTag)
+		}
+
 		operations[i] = op
 	}
 
@@ -0,0 +1,334 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package migrate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/store"
+)
+
+var logger = log.New("store-migration")
+
+const (
+	// CheckpointStoreName is the name of the store in the target database which holds the progress
+	// of a migration so that an interrupted migration may be resumed.
+	CheckpointStoreName = "migration-checkpoint"
+
+	defaultBatchSize = 100
+)
+
+// ErrVerificationFailed indicates that the number of entries in the target database doesn't match the number
+// of entries in the source database after the migration.
+var ErrVerificationFailed = errors.New("verification failed")
+
+// Entry is a key-value pair (with tags) that is read from a store.
+type Entry struct {
+	Key   string
+	Value []byte
+	Tags  []storage.Tag
+}
+
+// Database is a storage provider whose stores may be scanned.
+type Database interface {
+	storage.Provider
+
+	// Scan invokes the given function, in ascending key order, for each entry in the given namespace
+	// whose key is greater than afterKey. If afterKey is empty then all entries are scanned. Scan should
+	// not return an error if the store doesn't exist.
+	Scan(ns *Namespace, afterKey string, handle func(entry *Entry) error) error
+
+	// Count returns the number of entries in the given namespace.
+	Count(ns *Namespace) (int, error)
+}
+
+// NamespaceReport contains the results of the migration of a namespace.
+type NamespaceReport struct {
+	// Name is the name of the namespace.
+	Name string `json:"name"`
+	// Migrated is the number of entries that were copied during this run.
+	Migrated int `json:"migrated"`
+	// Resumed is true if the migration of the namespace was resumed from a checkpoint.
+	Resumed bool `json:"resumed,omitempty"`
+	// SourceCount is the number of entries in the source database.
+	SourceCount int `json:"sourceCount"`
+	// TargetCount is the number of entries in the target database.
+	TargetCount int `json:"targetCount"`
+	// Verified is true if the source and target counts match.
+	Verified bool `json:"verified"`
+}
+
+// Report contains the results of a migration.
+type Report struct {
+	Namespaces []*NamespaceReport `json:"namespaces"`
+}
+
+type options struct {
+	batchSize  int
+	namespaces []*Namespace
+	restart    bool
+}
+
+// Option sets a migration option.
+type Option func(opts *options)
+
+// WithBatchSize sets the number of entries that are written to the target database in a single batch.
+// The checkpoint is updated after each batch.
+func WithBatchSize(size int) Option {
+	return func(opts *options) {
+		opts.batchSize = size
+	}
+}
+
+// WithNamespaces sets the namespaces to migrate. By default, all Orb namespaces are migrated.
+func WithNamespaces(namespaces ...*Namespace) Option {
+	return func(opts *options) {
+		opts.namespaces = namespaces
+	}
+}
+
+// WithRestart causes any checkpoints from a previous (possibly interrupted) migration to be ignored so that
+// all entries are copied again.
+func WithRestart() Option {
+	return func(opts *options) {
+		opts.restart = true
+	}
+}
+
+// Migrator copies all Orb stores from a source database to a target database.
+type Migrator struct {
+	source     Database
+	target     Database
+	batchSize  int
+	namespaces []*Namespace
+	restart    bool
+}
+
+// New returns a new migrator.
+func New(source, target Database, opts ...Option) *Migrator {
+	options := &options{
+		batchSize:  defaultBatchSize,
+		namespaces: Namespaces(),
+	}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if options.batchSize <= 0 {
+		options.batchSize = defaultBatchSize
+	}
+
+	return &Migrator{
+		source:     source,
+		target:     target,
+		batchSize:  options.batchSize,
+		namespaces: options.namespaces,
+		restart:    options.restart,
+	}
+}
+
+type checkpoint struct {
+	LastKey  string `json:"lastKey,omitempty"`
+	Migrated int    `json:"migrated"`
+	Complete bool   `json:"complete"`
+}
+
+// Migrate copies the entries of each namespace (along with their tags) from the source database to the target
+// database. The progress of the migration is saved to a checkpoint store in the target database after each batch
+// so that, if the migration is interrupted, it resumes from where it left off when it is run again. After all
+// namespaces are copied, the number of entries in each namespace of the target database is verified against the
+// source database. ErrVerificationFailed is returned (along with the report) if the counts don't match.
+func (m *Migrator) Migrate() (*Report, error) {
+	checkpoints, err := store.Open(m.target, CheckpointStoreName)
+	if err != nil {
+		return nil, fmt.Errorf("open checkpoint store: %w", err)
+	}
+
+	report := &Report{}
+
+	for _, ns := range m.namespaces {
+		nsReport, err := m.migrateNamespace(ns, checkpoints)
+		if err != nil {
+			return nil, fmt.Errorf("migrate namespace [%s]: %w", ns.Name, err)
+		}
+
+		report.Namespaces = append(report.Namespaces, nsReport)
+	}
+
+	var failed []string
+
+	for i, ns := range m.namespaces {
+		if err := m.verify(ns, report.Namespaces[i]); err != nil {
+			return nil, fmt.Errorf("verify namespace [%s]: %w", ns.Name, err)
+		}
+
+		if !report.Namespaces[i].Verified {
+			failed = append(failed, ns.Name)
+		}
+	}
+
+	if len(failed) > 0 {
+		return report, fmt.Errorf("%w: entry counts don't match for namespaces %s",
+			ErrVerificationFailed, strings.Join(failed, ", "))
+	}
+
+	return report, nil
+}
+
+func (m *Migrator) migrateNamespace(ns *Namespace, checkpoints storage.Store) (*NamespaceReport, error) {
+	report := &NamespaceReport{Name: ns.Name}
+
+	cp := &checkpoint{}
+
+	if !m.restart {
+		var err error
+
+		cp, err = getCheckpoint(checkpoints, ns.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if cp.Complete {
+		logger.Info("Namespace was already migrated", log.WithStoreName(ns.Name), log.WithTotal(cp.Migrated))
+
+		return report, nil
+	}
+
+	report.Resumed = cp.LastKey != ""
+
+	if report.Resumed {
+		logger.Info("Resuming migration of namespace", log.WithStoreName(ns.Name), log.WithKey(cp.LastKey))
+	} else {
+		logger.Info("Migrating namespace", log.WithStoreName(ns.Name))
+	}
+
+	target, err := ns.Open(m.target)
+	if err != nil {
+		return nil, err
+	}
+
+	var batch []storage.Operation
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := target.Batch(batch); err != nil {
+			return fmt.Errorf("write batch: %w", err)
+		}
+
+		cp.LastKey = batch[len(batch)-1].Key
+		cp.Migrated += len(batch)
+		report.Migrated += len(batch)
+
+		if err := putCheckpoint(checkpoints, ns.Name, cp); err != nil {
+			return err
+		}
+
+		logger.Debug("Migrated batch", log.WithStoreName(ns.Name), log.WithSize(len(batch)),
+			log.WithTotal(cp.Migrated))
+
+		batch = nil
+
+		return nil
+	}
+
+	err = m.source.Scan(ns, cp.LastKey, func(entry *Entry) error {
+		batch = append(batch, storage.Operation{Key: entry.Key, Value: entry.Value, Tags: entry.Tags})
+
+		if len(batch) < m.batchSize {
+			return nil
+		}
+
+		return flush()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan source: %w", err)
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	if err := target.Flush(); err != nil {
+		return nil, fmt.Errorf("flush target: %w", err)
+	}
+
+	cp.Complete = true
+
+	if err := putCheckpoint(checkpoints, ns.Name, cp); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Migrated namespace", log.WithStoreName(ns.Name), log.WithTotal(cp.Migrated))
+
+	return report, nil
+}
+
+func (m *Migrator) verify(ns *Namespace, report *NamespaceReport) error {
+	sourceCount, err := m.source.Count(ns)
+	if err != nil {
+		return fmt.Errorf("count source entries: %w", err)
+	}
+
+	targetCount, err := m.target.Count(ns)
+	if err != nil {
+		return fmt.Errorf("count target entries: %w", err)
+	}
+
+	report.SourceCount = sourceCount
+	report.TargetCount = targetCount
+	report.Verified = sourceCount == targetCount
+
+	if !report.Verified {
+		logger.Warn("Entry count mismatch after migration", log.WithStoreName(ns.Name),
+			log.WithTotal(sourceCount), log.WithSize(targetCount))
+	}
+
+	return nil
+}
+
+func getCheckpoint(s storage.Store, name string) (*checkpoint, error) {
+	cp := &checkpoint{}
+
+	cpBytes, err := s.Get(name)
+	if err != nil {
+		if errors.Is(err, storage.ErrDataNotFound) {
+			return cp, nil
+		}
+
+		return nil, fmt.Errorf("get checkpoint: %w", err)
+	}
+
+	if err := json.Unmarshal(cpBytes, cp); err != nil {
+		return nil, fmt.Errorf("unmarshal checkpoint: %w", err)
+	}
+
+	return cp, nil
+}
+
+func putCheckpoint(s storage.Store, name string, cp *checkpoint) error {
+	cpBytes, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("marshal checkpoint: %w", err)
+	}
+
+	if err := s.Put(name, cpBytes); err != nil {
+		return fmt.Errorf("store checkpoint: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,338 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/store"
+)
+
+func TestMigrator_Migrate(t *testing.T) {
+	opNS := &Namespace{Name: "operation", TagGroups: []store.TagGroup{store.NewTagGroup("uniqueSuffix")}}
+	casNS := &Namespace{Name: "cas", Native: true}
+
+	t.Run("Success", func(t *testing.T) {
+		source := newTestDatabase()
+		target := newTestDatabase()
+
+		populate(t, source, opNS, 25)
+		populate(t, source, casNS, 3)
+
+		report, err := New(source, target, WithNamespaces(opNS, casNS), WithBatchSize(10)).Migrate()
+		require.NoError(t, err)
+		require.Len(t, report.Namespaces, 2)
+
+		require.Equal(t, &NamespaceReport{
+			Name: "operation", Migrated: 25, SourceCount: 25, TargetCount: 25, Verified: true,
+		}, report.Namespaces[0])
+		require.Equal(t, &NamespaceReport{
+			Name: "cas", Migrated: 3, SourceCount: 3, TargetCount: 3, Verified: true,
+		}, report.Namespaces[1])
+
+		s, err := target.OpenStore(opNS.Name)
+		require.NoError(t, err)
+
+		value, err := s.Get("key-07")
+		require.NoError(t, err)
+		require.Equal(t, `{"value":7}`, string(value))
+
+		tags, err := s.GetTags("key-07")
+		require.NoError(t, err)
+		require.Equal(t, []storage.Tag{{Name: "uniqueSuffix", Value: "suffix-7"}}, tags)
+
+		config, err := target.GetStoreConfig(opNS.Name)
+		require.NoError(t, err)
+		require.Equal(t, []string{"uniqueSuffix"}, config.TagNames)
+
+		t.Run("Already migrated", func(t *testing.T) {
+			report, err := New(source, target, WithNamespaces(opNS, casNS)).Migrate()
+			require.NoError(t, err)
+			require.Equal(t, 0, report.Namespaces[0].Migrated)
+			require.True(t, report.Namespaces[0].Verified)
+		})
+
+		t.Run("Restart", func(t *testing.T) {
+			report, err := New(source, target, WithNamespaces(opNS, casNS), WithRestart()).Migrate()
+			require.NoError(t, err)
+			require.Equal(t, 25, report.Namespaces[0].Migrated)
+			require.Equal(t, 3, report.Namespaces[1].Migrated)
+			require.True(t, report.Namespaces[0].Verified)
+		})
+	})
+
+	t.Run("Resume after interruption", func(t *testing.T) {
+		source := newTestDatabase()
+		target := newTestDatabase()
+
+		populate(t, source, opNS, 25)
+
+		source.scanErr = errors.New("connection lost")
+		source.scanErrAfter = 12
+
+		_, err := New(source, target, WithNamespaces(opNS), WithBatchSize(5)).Migrate()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "migrate namespace [operation]: scan source: connection lost")
+
+		// Two full batches were written before the failure.
+		count, err := target.Count(opNS)
+		require.NoError(t, err)
+		require.Equal(t, 10, count)
+
+		source.scanErr = nil
+
+		report, err := New(source, target, WithNamespaces(opNS), WithBatchSize(5)).Migrate()
+		require.NoError(t, err)
+		require.Equal(t, &NamespaceReport{
+			Name: "operation", Migrated: 15, Resumed: true, SourceCount: 25, TargetCount: 25, Verified: true,
+		}, report.Namespaces[0])
+	})
+
+	t.Run("Verification failed", func(t *testing.T) {
+		source := newTestDatabase()
+		target := newTestDatabase()
+
+		populate(t, source, opNS, 5)
+		populate(t, target, casNS, 1)
+
+		report, err := New(source, target, WithNamespaces(opNS, casNS)).Migrate()
+		require.True(t, errors.Is(err, ErrVerificationFailed))
+		require.Contains(t, err.Error(), "entry counts don't match for namespaces cas")
+		require.NotNil(t, report)
+		require.True(t, report.Namespaces[0].Verified)
+		require.False(t, report.Namespaces[1].Verified)
+		require.Equal(t, 0, report.Namespaces[1].SourceCount)
+		require.Equal(t, 1, report.Namespaces[1].TargetCount)
+	})
+
+	t.Run("Open checkpoint store error", func(t *testing.T) {
+		target := newTestDatabase()
+		target.openErr = errors.New("injected open error")
+
+		_, err := New(newTestDatabase(), target).Migrate()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "open checkpoint store")
+	})
+
+	t.Run("Write batch error", func(t *testing.T) {
+		source := newTestDatabase()
+		target := newTestDatabase()
+		target.batchErr = errors.New("injected batch error")
+
+		populate(t, source, opNS, 5)
+
+		_, err := New(source, target, WithNamespaces(opNS)).Migrate()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "write batch: injected batch error")
+	})
+
+	t.Run("Count error", func(t *testing.T) {
+		source := newTestDatabase()
+		source.countErr = errors.New("injected count error")
+
+		_, err := New(source, newTestDatabase(), WithNamespaces(opNS)).Migrate()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "count source entries: injected count error")
+
+		target := newTestDatabase()
+		target.countErr = errors.New("injected count error")
+
+		_, err = New(newTestDatabase(), target, WithNamespaces(opNS)).Migrate()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "count target entries: injected count error")
+	})
+}
+
+func TestNamespaces(t *testing.T) {
+	names := make(map[string]struct{})
+
+	for _, ns := range Namespaces() {
+		_, exists := names[ns.Name]
+		require.Falsef(t, exists, "duplicate namespace [%s]", ns.Name)
+
+		names[ns.Name] = struct{}{}
+	}
+
+	_, exists := names[CheckpointStoreName]
+	require.False(t, exists)
+
+	ns := &Namespace{
+		Name: "anchor-status",
+		TagGroups: []store.TagGroup{
+			store.NewTagGroup("anchorID", "status"),
+			store.NewTagGroup("status", "statusCheckTime"),
+		},
+	}
+
+	require.Equal(t, []string{"anchorID", "status", "statusCheckTime"}, ns.TagNames())
+
+	ns, ok := FindNamespace("cas")
+	require.True(t, ok)
+	require.True(t, ns.Native)
+
+	_, ok = FindNamespace("xxx")
+	require.False(t, ok)
+}
+
+func populate(t *testing.T, db *testDatabase, ns *Namespace, n int) {
+	t.Helper()
+
+	s, err := db.OpenStore(ns.Name)
+	require.NoError(t, err)
+
+	for i := 0; i < n; i++ {
+		var tags []storage.Tag
+		if len(ns.TagGroups) > 0 {
+			tags = []storage.Tag{{Name: "uniqueSuffix", Value: fmt.Sprintf("suffix-%d", i)}}
+		}
+
+		require.NoError(t, s.Put(fmt.Sprintf("key-%02d", i), []byte(fmt.Sprintf(`{"value":%d}`, i)), tags...))
+	}
+}
+
+// testDatabase is an in-memory database which keeps track of the keys in each store so that the stores may be scanned.
+type testDatabase struct {
+	storage.Provider
+
+	mutex        sync.Mutex
+	keys         map[string]map[string]struct{}
+	openErr      error
+	batchErr     error
+	countErr     error
+	scanErr      error
+	scanErrAfter int
+}
+
+func newTestDatabase() *testDatabase {
+	return &testDatabase{
+		Provider: mem.NewProvider(),
+		keys:     make(map[string]map[string]struct{}),
+	}
+}
+
+func (db *testDatabase) OpenStore(name string) (storage.Store, error) {
+	if db.openErr != nil {
+		return nil, db.openErr
+	}
+
+	s, err := db.Provider.OpenStore(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &testStore{Store: s, db: db, name: name}, nil
+}
+
+func (db *testDatabase) Scan(ns *Namespace, afterKey string, handle func(entry *Entry) error) error {
+	s, err := db.Provider.OpenStore(ns.Name)
+	if err != nil {
+		return err
+	}
+
+	for i, key := range db.sortedKeys(ns.Name) {
+		if db.scanErr != nil && i == db.scanErrAfter {
+			return db.scanErr
+		}
+
+		if key <= afterKey {
+			continue
+		}
+
+		value, err := s.Get(key)
+		if err != nil {
+			return err
+		}
+
+		tags, err := s.GetTags(key)
+		if err != nil {
+			return err
+		}
+
+		if err := handle(&Entry{Key: key, Value: value, Tags: tags}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (db *testDatabase) Count(ns *Namespace) (int, error) {
+	if db.countErr != nil {
+		return 0, db.countErr
+	}
+
+	return len(db.sortedKeys(ns.Name)), nil
+}
+
+func (db *testDatabase) sortedKeys(name string) []string {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	var keys []string
+
+	for key := range db.keys[name] {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (db *testDatabase) addKey(name, key string) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+
+	keys, ok := db.keys[name]
+	if !ok {
+		keys = make(map[string]struct{})
+		db.keys[name] = keys
+	}
+
+	keys[key] = struct{}{}
+}
+
+type testStore struct {
+	storage.Store
+
+	db   *testDatabase
+	name string
+}
+
+func (s *testStore) Put(key string, value []byte, tags ...storage.Tag) error {
+	if err := s.Store.Put(key, value, tags...); err != nil {
+		return err
+	}
+
+	s.db.addKey(s.name, key)
+
+	return nil
+}
+
+func (s *testStore) Batch(operations []storage.Operation) error {
+	if s.db.batchErr != nil {
+		return s.db.batchErr
+	}
+
+	if err := s.Store.Batch(operations); err != nil {
+		return err
+	}
+
+	for _, op := range operations {
+		s.db.addKey(s.name, op.Key)
+	}
+
+	return nil
+}
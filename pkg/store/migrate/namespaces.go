@@ -0,0 +1,153 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package migrate
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+
+	"github.com/trustbloc/orb/pkg/store"
+)
+
+// Namespace describes a store that is migrated.
+type Namespace struct {
+	// Name is the name of the store.
+	Name string
+	// TagGroups are the groups of tags with which the store is opened.
+	TagGroups []store.TagGroup
+	// Native is true if the store is opened directly from the storage provider rather than with store.Open.
+	// The values of native stores are held in the storage provider's own format, whereas the values of other stores
+	// are held as JSON documents whose top-level fields include the tags (when a vendor-specific store is used).
+	Native bool
+}
+
+// TagNames returns the unique tag names of the namespace.
+func (ns *Namespace) TagNames() []string {
+	var names []string
+
+	for _, group := range ns.TagGroups {
+		for _, name := range group {
+			if !contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names
+}
+
+// Open opens the store in the same way as Orb does so that the values are written in the format that Orb expects
+// and the required indexes are created.
+func (ns *Namespace) Open(p storage.Provider) (storage.Store, error) {
+	if !ns.Native {
+		return store.Open(p, ns.Name, ns.TagGroups...)
+	}
+
+	s, err := p.OpenStore(ns.Name)
+	if err != nil {
+		return nil, fmt.Errorf("open store [%s]: %w", ns.Name, err)
+	}
+
+	if tagNames := ns.TagNames(); len(tagNames) > 0 {
+		if err := p.SetStoreConfig(ns.Name, storage.StoreConfiguration{TagNames: tagNames}); err != nil {
+			return nil, fmt.Errorf("set store configuration for [%s]: %w", ns.Name, err)
+		}
+	}
+
+	return s, nil
+}
+
+// Namespaces returns all of the stores that are used by Orb. This list must be updated whenever a store is added
+// (or the tags of a store are changed) so that the store is included in migrations.
+func Namespaces() []*Namespace {
+	return []*Namespace{
+		{Name: "operation", TagGroups: []store.TagGroup{store.NewTagGroup("uniqueSuffix")}},
+		{
+			Name: "unpublished-operation",
+			TagGroups: []store.TagGroup{
+				store.NewTagGroup("uniqueSuffix"),
+				store.NewTagGroup("expirationTime"),
+			},
+		},
+		{Name: "didanchor", Native: true},
+		{Name: "anchor-link"},
+		{Name: "anchor-ref", TagGroups: []store.TagGroup{store.NewTagGroup("anchorHash")}},
+		{
+			Name: "anchor-status",
+			TagGroups: []store.TagGroup{
+				store.NewTagGroup("anchorID", "status"),
+				store.NewTagGroup("expiryTime"),
+				store.NewTagGroup("status", "statusCheckTime"),
+			},
+		},
+		{
+			Name: "witness",
+			TagGroups: []store.TagGroup{
+				store.NewTagGroup("anchorID", "entryType"),
+				store.NewTagGroup("ExpiryTime"),
+			},
+		},
+		{Name: "activity"},
+		{
+			Name: "activity-ref",
+			TagGroups: []store.TagGroup{
+				store.NewTagGroup("refType", "objectIRI", "activityType"),
+				store.NewTagGroup("refType", "timeAdded"),
+			},
+		},
+		{Name: "activity-sync"},
+		{Name: "orb-config"},
+		{Name: "orb-encryption-keys", TagGroups: []store.TagGroup{store.NewTagGroup("dataKey")}},
+		{Name: "verifiable"},
+		{Name: "public-key"},
+		{
+			Name: "proof-monitor",
+			TagGroups: []store.TagGroup{
+				store.NewTagGroup("status"),
+				store.NewTagGroup("expiryTime"),
+			},
+		},
+		{Name: "log-monitor", TagGroups: []store.TagGroup{store.NewTagGroup("status")}},
+		{
+			Name: "log-entry",
+			TagGroups: []store.TagGroup{
+				store.NewTagGroup("logUrl", "index", "status"),
+				store.NewTagGroup("expiryTime"),
+				store.NewTagGroup("archiveLogUrl"),
+			},
+		},
+		{Name: "operation-queue", TagGroups: []store.TagGroup{store.NewTagGroup("taskID")}},
+		{Name: "orb-undeliverable", TagGroups: []store.TagGroup{store.NewTagGroup("topic")}},
+		{Name: "orb-pubsub", TagGroups: []store.TagGroup{store.NewTagGroup("topic")}},
+		{Name: "cas", Native: true},
+		{Name: "cas-pin", TagGroups: []store.TagGroup{store.NewTagGroup("pinned")}},
+		{Name: "ldcontexts", TagGroups: []store.TagGroup{store.NewTagGroup("record")}, Native: true},
+		{Name: "remoteproviders", TagGroups: []store.TagGroup{store.NewTagGroup("record")}, Native: true},
+	}
+}
+
+// FindNamespace returns the Orb namespace with the given name.
+func FindNamespace(name string) (*Namespace, bool) {
+	for _, ns := range Namespaces() {
+		if ns.Name == name {
+			return ns, true
+		}
+	}
+
+	return nil, false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -9,6 +9,7 @@ package operation
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -155,3 +156,144 @@ func (s *Store) Get(suffix string) ([]*operation.AnchoredOperation, error) {
 
 	return ops, nil
 }
+
+// GetBulk retrieves the document operations for the given suffixes, mapped by suffix. Suffixes for which no
+// operations exist are not included in the returned map. If the underlying database supports it then the
+// operations are retrieved with a single query.
+func (s *Store) GetBulk(suffixes []string) (map[string][]*operation.AnchoredOperation, error) {
+	querier, ok := s.store.(store.MultiValueQuerier)
+	if !ok {
+		return s.getBulkSequential(suffixes)
+	}
+
+	startTime := time.Now()
+
+	defer func() {
+		s.metrics.GetPublishedOperations(time.Since(startTime))
+	}()
+
+	iter, err := querier.QueryIn(index, suffixes)
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("failed to get operations for %d suffixes: %w",
+			len(suffixes), err))
+	}
+
+	defer func() {
+		if errClose := iter.Close(); errClose != nil {
+			log.CloseIteratorError(logger, errClose)
+		}
+	}()
+
+	opsBySuffix := make(map[string][]*operation.AnchoredOperation)
+
+	for {
+		more, err := iter.Next()
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("iterator error for bulk get: %w", err))
+		}
+
+		if !more {
+			break
+		}
+
+		value, err := iter.Value()
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("failed to get iterator value for bulk get: %w", err))
+		}
+
+		op := &operation.AnchoredOperation{}
+
+		err = json.Unmarshal(value, op)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal anchored operation from store value for bulk get: %w", err)
+		}
+
+		opsBySuffix[op.UniqueSuffix] = append(opsBySuffix[op.UniqueSuffix], op)
+	}
+
+	logger.Debug("Retrieved operations for suffixes", log.WithTotal(len(opsBySuffix)), log.WithSuffixes(suffixes...))
+
+	return opsBySuffix, nil
+}
+
+func (s *Store) getBulkSequential(suffixes []string) (map[string][]*operation.AnchoredOperation, error) {
+	opsBySuffix := make(map[string][]*operation.AnchoredOperation)
+
+	for _, suffix := range suffixes {
+		if _, ok := opsBySuffix[suffix]; ok {
+			continue
+		}
+
+		ops, err := s.Get(suffix)
+		if err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				continue
+			}
+
+			return nil, err
+		}
+
+		opsBySuffix[suffix] = ops
+	}
+
+	return opsBySuffix, nil
+}
+
+// GetReferences returns the unique canonical and equivalent anchor references of all operations in the store.
+func (s *Store) GetReferences() ([]string, error) {
+	iter, err := s.store.Query(index)
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("failed to query operations: %w", err))
+	}
+
+	defer func() {
+		if errClose := iter.Close(); errClose != nil {
+			log.CloseIteratorError(logger, errClose)
+		}
+	}()
+
+	var refs []string
+
+	added := make(map[string]struct{})
+
+	addRef := func(ref string) {
+		if _, ok := added[ref]; ok || ref == "" {
+			return
+		}
+
+		added[ref] = struct{}{}
+		refs = append(refs, ref)
+	}
+
+	for {
+		ok, err := iter.Next()
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("iterator error: %w", err))
+		}
+
+		if !ok {
+			break
+		}
+
+		value, err := iter.Value()
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("failed to get iterator value: %w", err))
+		}
+
+		var op operation.AnchoredOperation
+
+		if err := json.Unmarshal(value, &op); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal anchored operation from store value: %w", err)
+		}
+
+		addRef(op.CanonicalReference)
+
+		for _, ref := range op.EquivalentReferences {
+			addRef(ref)
+		}
+	}
+
+	logger.Debug("Retrieved operation references", log.WithTotal(len(refs)))
+
+	return refs, nil
+}
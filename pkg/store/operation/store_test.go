@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package operation
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -187,6 +188,189 @@ func TestStore_Get(t *testing.T) {
 	})
 }
 
+func TestStore_GetBulk(t *testing.T) {
+	t.Run("success - sequential", func(t *testing.T) {
+		s, err := New(mem.NewProvider(), &orbmocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		op2 := getTestOperation()
+		op2.UniqueSuffix = "suffix2"
+
+		require.NoError(t, s.Put([]*operation.AnchoredOperation{getTestOperation(), op2}))
+
+		opsBySuffix, err := s.GetBulk([]string{testSuffix, "suffix2", "suffix3", testSuffix})
+		require.NoError(t, err)
+		require.Len(t, opsBySuffix, 2)
+		require.Len(t, opsBySuffix[testSuffix], 1)
+		require.Len(t, opsBySuffix["suffix2"], 1)
+	})
+
+	t.Run("error - sequential", func(t *testing.T) {
+		store := &mocks.Store{}
+		store.QueryReturns(nil, fmt.Errorf("query error"))
+
+		provider := &mocks.Provider{}
+		provider.OpenStoreReturns(store, nil)
+
+		s, err := New(provider, &orbmocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		opsBySuffix, err := s.GetBulk([]string{testSuffix})
+		require.Error(t, err)
+		require.Nil(t, opsBySuffix)
+		require.Contains(t, err.Error(), "query error")
+	})
+
+	t.Run("success - single query", func(t *testing.T) {
+		op := getTestOperation()
+
+		opBytes, err := json.Marshal(op)
+		require.NoError(t, err)
+
+		opMap := make(map[string]interface{})
+		require.NoError(t, json.Unmarshal(opBytes, &opMap))
+
+		iterator := &mocks.MongoDBIterator{}
+		iterator.NextReturnsOnCall(0, true, nil)
+		iterator.NextReturnsOnCall(1, true, nil)
+		iterator.NextReturnsOnCall(2, false, nil)
+		iterator.ValueAsRawMapReturns(opMap, nil)
+
+		s, mongoStore := newMongoDBStore(t)
+		mongoStore.QueryCustomReturns(iterator, nil)
+
+		opsBySuffix, err := s.GetBulk([]string{testSuffix, "suffix2"})
+		require.NoError(t, err)
+		require.Len(t, opsBySuffix, 1)
+		require.Len(t, opsBySuffix[testSuffix], 2)
+		require.Equal(t, 1, mongoStore.QueryCustomCallCount())
+	})
+
+	t.Run("error - query error", func(t *testing.T) {
+		s, mongoStore := newMongoDBStore(t)
+		mongoStore.QueryCustomReturns(nil, fmt.Errorf("query custom error"))
+
+		opsBySuffix, err := s.GetBulk([]string{testSuffix})
+		require.Error(t, err)
+		require.Nil(t, opsBySuffix)
+		require.Contains(t, err.Error(), "query custom error")
+	})
+
+	t.Run("error - iterator next() error", func(t *testing.T) {
+		iterator := &mocks.MongoDBIterator{}
+		iterator.NextReturns(false, fmt.Errorf("iterator next() error"))
+
+		s, mongoStore := newMongoDBStore(t)
+		mongoStore.QueryCustomReturns(iterator, nil)
+
+		_, err := s.GetBulk([]string{testSuffix})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "iterator next() error")
+	})
+
+	t.Run("error - iterator value() error", func(t *testing.T) {
+		iterator := &mocks.MongoDBIterator{}
+		iterator.NextReturns(true, nil)
+		iterator.ValueAsRawMapReturns(nil, fmt.Errorf("iterator value() error"))
+
+		s, mongoStore := newMongoDBStore(t)
+		mongoStore.QueryCustomReturns(iterator, nil)
+
+		_, err := s.GetBulk([]string{testSuffix})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "iterator value() error")
+	})
+
+	t.Run("error - unmarshal error", func(t *testing.T) {
+		iterator := &mocks.MongoDBIterator{}
+		iterator.NextReturns(true, nil)
+		iterator.ValueAsRawMapReturns(map[string]interface{}{"type": 123}, nil)
+
+		s, mongoStore := newMongoDBStore(t)
+		mongoStore.QueryCustomReturns(iterator, nil)
+
+		_, err := s.GetBulk([]string{testSuffix})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to unmarshal anchored operation")
+	})
+}
+
+func newMongoDBStore(t *testing.T) (*Store, *mocks.MongoDBStore) {
+	t.Helper()
+
+	mongoStore := &mocks.MongoDBStore{}
+
+	provider := &mocks.MongoDBProvider{}
+	provider.OpenStoreReturns(mongoStore, nil)
+
+	s, err := New(provider, &orbmocks.MetricsProvider{})
+	require.NoError(t, err)
+
+	return s, mongoStore
+}
+
+func TestStore_GetReferences(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		s, err := New(mem.NewProvider(), &orbmocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		op1 := getTestOperation()
+		op1.CanonicalReference = "hl:anchor1"
+		op1.EquivalentReferences = []string{"hl:anchor1:metadata", "ipfs://cid1"}
+
+		op2 := getTestOperation()
+		op2.UniqueSuffix = "suffix2"
+		op2.CanonicalReference = "hl:anchor1"
+
+		op3 := getTestOperation()
+		op3.UniqueSuffix = "suffix3"
+		op3.CanonicalReference = "hl:anchor2"
+
+		require.NoError(t, s.Put([]*operation.AnchoredOperation{op1, op2, op3}))
+
+		refs, err := s.GetReferences()
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"hl:anchor1", "hl:anchor1:metadata", "ipfs://cid1", "hl:anchor2"}, refs)
+	})
+
+	t.Run("error - store error", func(t *testing.T) {
+		store := &mocks.Store{}
+		store.QueryReturns(nil, fmt.Errorf("query error"))
+
+		provider := &mocks.Provider{}
+		provider.OpenStoreReturns(store, nil)
+
+		s, err := New(provider, &orbmocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		refs, err := s.GetReferences()
+		require.Error(t, err)
+		require.Nil(t, refs)
+		require.Contains(t, err.Error(), "query error")
+	})
+
+	t.Run("error - unmarshal anchored operation error", func(t *testing.T) {
+		iterator := &mocks.Iterator{}
+
+		iterator.NextReturns(true, nil)
+		iterator.ValueReturns([]byte("not-json"), nil)
+
+		store := &mocks.Store{}
+		store.QueryReturns(iterator, nil)
+
+		provider := &mocks.Provider{}
+		provider.OpenStoreReturns(store, nil)
+
+		s, err := New(provider, &orbmocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		refs, err := s.GetReferences()
+		require.Error(t, err)
+		require.Nil(t, refs)
+		require.Contains(t, err.Error(), "failed to unmarshal anchored operation from store value")
+	})
+}
+
 func getTestOperation() *operation.AnchoredOperation {
 	return &operation.AnchoredOperation{
 		Type:         operation.TypeCreate,
@@ -8,7 +8,9 @@ package unpublished
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/hyperledger/aries-framework-go/spi/storage"
@@ -47,14 +49,16 @@ func New(provider storage.Provider, unpublishedOperationLifespan time.Duration,
 		return nil, fmt.Errorf("failed to open unpublished operation store: %w", err)
 	}
 
-	expiryService.Register(s, expiryTagName, nameSpace)
-
-	return &Store{
+	opStore := &Store{
 		store:                        s,
 		unpublishedOperationLifespan: unpublishedOperationLifespan,
 
 		metrics: metrics,
-	}, nil
+	}
+
+	expiryService.Register(s, expiryTagName, nameSpace, expiry.WithExpiryHandler(opStore))
+
+	return opStore, nil
 }
 
 // Store implements storage for unpublished operation.
@@ -63,6 +67,9 @@ type Store struct {
 	unpublishedOperationLifespan time.Duration
 
 	metrics metricsProvider
+
+	mutex          sync.RWMutex
+	expiredHandler func(suffixes ...string)
 }
 
 type metricsProvider interface {
@@ -187,6 +194,65 @@ func (s *Store) Get(suffix string) ([]*operation.AnchoredOperation, error) {
 	return ops, nil
 }
 
+// OnExpired sets a handler which is invoked with the suffixes of unpublished operations that have expired
+// (before the operations are deleted from the store).
+func (s *Store) OnExpired(handler func(suffixes ...string)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.expiredHandler = handler
+}
+
+// HandleExpiredKeys is invoked by the expiry service before the unpublished operations with the given keys are
+// deleted. The suffixes of the expired operations are passed to the handler that was set with OnExpired.
+func (s *Store) HandleExpiredKeys(keys ...string) error {
+	s.mutex.RLock()
+	handler := s.expiredHandler
+	s.mutex.RUnlock()
+
+	if handler == nil || len(keys) == 0 {
+		return nil
+	}
+
+	uniqueSuffixes := make(map[string]struct{})
+
+	var suffixes []string
+
+	for _, key := range keys {
+		opBytes, err := s.store.Get(key)
+		if err != nil {
+			if errors.Is(err, storage.ErrDataNotFound) {
+				continue
+			}
+
+			return orberrors.NewTransient(fmt.Errorf("failed to get expired unpublished operation [%s]: %w", key, err))
+		}
+
+		opw := &operationWrapper{}
+
+		err = json.Unmarshal(opBytes, opw)
+		if err != nil || opw.AnchoredOperation == nil {
+			logger.Warn("Failed to unmarshal expired unpublished operation", log.WithKey(key), log.WithError(err))
+
+			continue
+		}
+
+		if _, exists := uniqueSuffixes[opw.UniqueSuffix]; !exists {
+			uniqueSuffixes[opw.UniqueSuffix] = struct{}{}
+
+			suffixes = append(suffixes, opw.UniqueSuffix)
+		}
+	}
+
+	logger.Debug("Unpublished operations expired", log.WithTotal(len(keys)), log.WithSuffixes(suffixes...))
+
+	if len(suffixes) > 0 {
+		handler(suffixes...)
+	}
+
+	return nil
+}
+
 // Delete will delete unpublished operation for suffix.
 func (s *Store) Delete(op *operation.AnchoredOperation) error {
 	key, err := hashing.CalculateModelMultihash(op.OperationRequest, sha2_256)
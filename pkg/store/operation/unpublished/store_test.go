@@ -16,6 +16,7 @@ import (
 	"github.com/hyperledger/aries-framework-go/spi/storage"
 	"github.com/stretchr/testify/require"
 	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/hashing"
 
 	"github.com/trustbloc/orb/pkg/internal/testutil"
 	orbmocks "github.com/trustbloc/orb/pkg/mocks"
@@ -316,6 +317,69 @@ func TestStore_DeleteAll(t *testing.T) {
 	})
 }
 
+func TestStore_HandleExpiredKeys(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		s, err := New(mem.NewProvider(), time.Minute, testutil.GetExpiryService(t), &orbmocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		op := &operation.AnchoredOperation{UniqueSuffix: "suffix", OperationRequest: []byte(operationRequest)}
+
+		require.NoError(t, s.Put(op))
+
+		key, err := hashing.CalculateModelMultihash(op.OperationRequest, sha2_256)
+		require.NoError(t, err)
+
+		// No handler.
+		require.NoError(t, s.HandleExpiredKeys(key))
+
+		var expired []string
+
+		s.OnExpired(func(suffixes ...string) {
+			expired = append(expired, suffixes...)
+		})
+
+		require.NoError(t, s.HandleExpiredKeys())
+		require.Empty(t, expired)
+
+		require.NoError(t, s.HandleExpiredKeys(key, key, "unknown-key"))
+		require.Equal(t, []string{"suffix"}, expired)
+	})
+
+	t.Run("error - from store get", func(t *testing.T) {
+		storeProvider := &mockstore.Provider{OpenStoreReturn: &mockstore.Store{
+			ErrGet: fmt.Errorf("get error"),
+		}}
+
+		s, err := New(storeProvider, time.Minute, testutil.GetExpiryService(t), &orbmocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		s.OnExpired(func(suffixes ...string) {
+			require.FailNow(t, "handler shouldn't have been invoked")
+		})
+
+		err = s.HandleExpiredKeys("key")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get error")
+	})
+
+	t.Run("invalid operation -> ignored", func(t *testing.T) {
+		store := &mocks.Store{}
+		store.GetReturns([]byte("not-json"), nil)
+
+		provider := &mocks.Provider{}
+		provider.OpenStoreReturns(store, nil)
+
+		s, err := New(provider, time.Minute, testutil.GetExpiryService(t), &orbmocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		s.OnExpired(func(suffixes ...string) {
+			require.FailNow(t, "handler shouldn't have been invoked")
+		})
+
+		require.NoError(t, s.HandleExpiredKeys("key"))
+	})
+}
+
 const operationRequest = `
 {
   "delta": {
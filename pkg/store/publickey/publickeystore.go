@@ -10,13 +10,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 
-	"github.com/bluele/gcache"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
 	"github.com/hyperledger/aries-framework-go/spi/storage"
 
 	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/cache"
+	"github.com/trustbloc/orb/pkg/cache/memory"
 	"github.com/trustbloc/orb/pkg/store"
 )
 
@@ -25,12 +27,13 @@ var logger = log.New("public-key-store")
 const (
 	storeName    = "public-key"
 	maxCacheSize = 1000
+	cacheName    = "public-key"
 )
 
 // Store manages a persistent store of public keys for issuers. The store also caches
 // the public keys for better performance.
 type Store struct {
-	cache          gcache.Cache
+	cache          cache.Cache
 	store          storage.Store
 	fetchPublicKey verifiable.PublicKeyFetcher
 }
@@ -40,8 +43,48 @@ type cacheKey struct {
 	keyID    string
 }
 
+// String returns the cache key as a string. The issuer ID and key ID are separated by
+// a newline since a newline can't be part of a DID or URL.
+func (k cacheKey) String() string {
+	return k.issuerID + "\n" + k.keyID
+}
+
+func parseCacheKey(key string) cacheKey {
+	parts := strings.SplitN(key, "\n", 2) //nolint:gomnd
+
+	if len(parts) < 2 { //nolint:gomnd
+		return cacheKey{issuerID: key}
+	}
+
+	return cacheKey{issuerID: parts[0], keyID: parts[1]}
+}
+
+type options struct {
+	cacheProvider cache.Provider
+}
+
+// Option is a public key store option.
+type Option func(opts *options)
+
+// WithCacheProvider sets the provider of the public key cache. If not set then an in-memory cache is used.
+func WithCacheProvider(p cache.Provider) Option {
+	return func(opts *options) {
+		opts.cacheProvider = p
+	}
+}
+
 // New returns a new public key store.
-func New(p storage.Provider, fetchPublicKey verifiable.PublicKeyFetcher) (*Store, error) {
+func New(p storage.Provider, fetchPublicKey verifiable.PublicKeyFetcher, opts ...Option) (*Store, error) {
+	options := &options{}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if options.cacheProvider == nil {
+		options.cacheProvider = memory.New()
+	}
+
 	s, err := store.Open(p, storeName)
 	if err != nil {
 		return nil, fmt.Errorf("open store [%s]: %w", storeName, err)
@@ -52,16 +95,15 @@ func New(p storage.Provider, fetchPublicKey verifiable.PublicKeyFetcher) (*Store
 		fetchPublicKey: fetchPublicKey,
 	}
 
-	pkCache := gcache.New(maxCacheSize).ARC().
-		LoaderFunc(
-			func(k interface{}) (interface{}, error) {
-				ck := k.(cacheKey) //nolint:forcetypeassert
-
-				return pkStore.get(ck.issuerID, ck.keyID)
-			},
-		).Build()
+	pkStore.cache = options.cacheProvider.NewCache(cacheName,
+		func(key string) (interface{}, error) {
+			ck := parseCacheKey(key)
 
-	pkStore.cache = pkCache
+			return pkStore.get(ck.issuerID, ck.keyID)
+		},
+		cache.WithSize(maxCacheSize),
+		cache.WithCodec(cache.NewJSONCodec(func() interface{} { return &verifier.PublicKey{} })),
+	)
 
 	logger.Info("Created public key store", log.WithStoreName(storeName))
 
@@ -70,7 +112,7 @@ func New(p storage.Provider, fetchPublicKey verifiable.PublicKeyFetcher) (*Store
 
 // GetPublicKey returns the public key for the given issuer and key ID.
 func (c *Store) GetPublicKey(issuerID, keyID string) (*verifier.PublicKey, error) {
-	pk, err := c.cache.Get(cacheKey{issuerID, keyID})
+	pk, err := c.cache.Get(cacheKey{issuerID, keyID}.String())
 	if err != nil {
 		return nil, err
 	}
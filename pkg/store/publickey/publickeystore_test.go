@@ -15,6 +15,8 @@ import (
 	"github.com/hyperledger/aries-framework-go/spi/storage"
 	"github.com/stretchr/testify/require"
 
+	"github.com/trustbloc/orb/pkg/cache/redis"
+	"github.com/trustbloc/orb/pkg/internal/testutil/redistestutil"
 	"github.com/trustbloc/orb/pkg/store/mocks"
 )
 
@@ -148,3 +150,55 @@ func TestStore_GetPublicKey(t *testing.T) {
 		require.NotNil(t, pk)
 	})
 }
+
+func TestStore_SharedCache(t *testing.T) {
+	_, address, stop := redistestutil.StartServer("")
+	defer stop()
+
+	pk := &verifier.PublicKey{Type: "Ed25519VerificationKey2018", Value: []byte("value")}
+
+	store := &mocks.Store{}
+	store.GetReturns(nil, storage.ErrDataNotFound)
+
+	p := &mocks.Provider{}
+	p.OpenStoreReturns(store, nil)
+
+	var numFetches int
+
+	fetchPublicKey := func(issuerID, keyID string) (*verifier.PublicKey, error) {
+		numFetches++
+
+		return pk, nil
+	}
+
+	cacheProvider1, err := redis.New(address)
+	require.NoError(t, err)
+	defer cacheProvider1.Close()
+
+	cacheProvider2, err := redis.New(address)
+	require.NoError(t, err)
+	defer cacheProvider2.Close()
+
+	s1, err := New(p, fetchPublicKey, WithCacheProvider(cacheProvider1))
+	require.NoError(t, err)
+
+	s2, err := New(p, fetchPublicKey, WithCacheProvider(cacheProvider2))
+	require.NoError(t, err)
+
+	pk1, err := s1.GetPublicKey("did:web:orb.domain1.com", "key1")
+	require.NoError(t, err)
+	require.Equal(t, pk, pk1)
+
+	pk2, err := s2.GetPublicKey("did:web:orb.domain1.com", "key1")
+	require.NoError(t, err)
+	require.Equal(t, pk, pk2)
+
+	require.Equal(t, 1, numFetches)
+}
+
+func TestCacheKey(t *testing.T) {
+	k := cacheKey{issuerID: "did:web:orb.domain1.com", keyID: "key1"}
+	require.Equal(t, k, parseCacheKey(k.String()))
+
+	require.Equal(t, cacheKey{issuerID: "did:web:orb.domain1.com"}, parseCacheKey("did:web:orb.domain1.com"))
+}
@@ -29,6 +29,12 @@ const idField = "_id"
 // TagGroup defines a group of tags that may be used to create a compound index.
 type TagGroup []string
 
+// MultiValueQuerier is implemented by stores which are able to retrieve, in a single query, the values
+// for which the given tag has any one of the given values.
+type MultiValueQuerier interface {
+	QueryIn(tagName string, tagValues []string, options ...storage.QueryOption) (storage.Iterator, error)
+}
+
 // Open opens the store for the given namespace and creates the necessary indexes. As an optimization,
 // this function uses vendor-specific APIs (for supported databases) in order to optimize performance.
 func Open(provider storage.Provider, namespace string, tagGroups ...TagGroup) (storage.Store, error) {
@@ -221,6 +227,21 @@ func (s *mongoDBWrapper) Query(expression string, options ...storage.QueryOption
 	return newMongoDBIteratorWrapper(iterator), nil
 }
 
+// QueryIn searches the database for the documents whose given tag has any one of the given values and
+// returns an iterator that may be used to retrieve the values.
+func (s *mongoDBWrapper) QueryIn(tagName string, tagValues []string,
+	options ...storage.QueryOption) (storage.Iterator, error) {
+	filter := bson.D{{Key: tagName, Value: bson.D{{Key: "$in", Value: tagValues}}}}
+
+	iterator, err := s.ms.QueryCustom(filter, s.ms.CreateMongoDBFindOptions(options, true))
+	if err != nil {
+		return nil, fmt.Errorf("query MongoDB store [%s] - tag [%s] in %s: %w",
+			s.namespace, tagName, tagValues, err)
+	}
+
+	return newMongoDBIteratorWrapper(iterator), nil
+}
+
 // Batch performs multiple Put and/or Delete operations in order.
 func (s *mongoDBWrapper) Batch(operations []storage.Operation) error {
 	writeModels := make([]mongo.WriteModel, len(operations))
@@ -13,6 +13,7 @@ import (
 
 	"github.com/hyperledger/aries-framework-go/spi/storage"
 	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
 
 	"github.com/trustbloc/orb/pkg/store/mocks"
 )
@@ -305,6 +306,55 @@ func TestMongoDBGetBulk(t *testing.T) {
 }
 
 //nolint:forcetypeassert
+func TestMongoDBQueryIn(t *testing.T) {
+	store := &mocks.MongoDBStore{}
+
+	provider := &mocks.MongoDBProvider{}
+	provider.OpenStoreReturns(store, nil)
+
+	s, err := Open(provider, "store1")
+	require.NoError(t, err)
+	require.NotNil(t, s)
+
+	querier, ok := s.(MultiValueQuerier)
+	require.True(t, ok)
+
+	t.Run("success", func(t *testing.T) {
+		mit := &mocks.MongoDBIterator{}
+		mit.NextReturns(true, nil)
+		mit.ValueAsRawMapReturns(map[string]interface{}{"field1": "value1"}, nil)
+
+		store.QueryCustomReturns(mit, nil)
+
+		it, err := querier.QueryIn("field1", []string{"value1", "value2"})
+		require.NoError(t, err)
+		require.NotNil(t, it)
+
+		filter, _ := store.QueryCustomArgsForCall(store.QueryCustomCallCount() - 1)
+		require.Equal(t, bson.D{{Key: "field1", Value: bson.D{{Key: "$in", Value: []string{"value1", "value2"}}}}},
+			filter)
+
+		ok, err := it.Next()
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		value, err := it.Value()
+		require.NoError(t, err)
+		require.NotEmpty(t, value)
+	})
+
+	t.Run("QueryCustom error", func(t *testing.T) {
+		errExpected := errors.New("injected QueryCustom error")
+
+		store.QueryCustomReturns(nil, errExpected)
+
+		it, err := querier.QueryIn("field1", []string{"value1"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+		require.Nil(t, it)
+	})
+}
+
 func TestMongoDBQuery(t *testing.T) {
 	store := &mocks.MongoDBStore{}
 
@@ -59,6 +59,7 @@ type logEntryStore interface {
 	StoreLogEntries(log string, start, end uint64, entries []command.LeafEntry) error
 	GetLogEntriesFrom(logURL string, start uint64) (logentry.EntryIterator, error)
 	FailLogEntriesFrom(logURL string, start uint64) error
+	GetFirstIndex(logURL string) (uint64, bool, error)
 }
 
 type logVerifier interface {
@@ -177,12 +178,12 @@ func (c *Client) checkVCTConsistency(logMonitor *logmonitor.LogMonitor) error {
 	}
 
 	// get VCT public key and verify the STH signature
-	pubKey, err := getPublicKey(vctClient)
+	pubKey, err := GetPublicKey(vctClient)
 	if err != nil {
 		return fmt.Errorf("get public key: %w", err)
 	}
 
-	err = verifySTHSignature(sth, pubKey)
+	err = VerifySTHSignature(sth, pubKey)
 	if err != nil {
 		return fmt.Errorf("failed to verify STH signature: %w", err)
 	}
@@ -308,12 +309,19 @@ func (c *Client) getDiscrepancyIndexAndAdditionalLogEntries(logURL string,
 	vctClient *vct.Client, treeSize uint64) (int64, []*command.LeafEntry, error) {
 	var allDifferentLogEntries []*command.LeafEntry
 
+	// Entries below the first stored index may have been removed from the store by compaction, in which case
+	// the stored entries can't be compared with the log entries below that index.
+	firstStoredIndex, err := c.getFirstStoredIndex(logURL)
+	if err != nil {
+		return 0, nil, err
+	}
+
 	curEnd := int64(treeSize)
 
-	for curEnd >= 0 {
+	for curEnd >= firstStoredIndex {
 		curStart := curEnd - int64(c.maxRecoveryFetchSize) + 1
-		if curStart < 0 {
-			curStart = 0
+		if curStart < firstStoredIndex {
+			curStart = firstStoredIndex
 		}
 
 		logEntries, err := c.getLogEntries(logURL, vctClient, uint64(curStart), uint64(curEnd), false)
@@ -353,11 +361,26 @@ func (c *Client) getDiscrepancyIndexAndAdditionalLogEntries(logURL string,
 		curEnd = curStart - 1
 	}
 
-	logger.Info("There was no common log entry between store entries and log entries", log.WithLogURLString(logURL))
+	logger.Info("There was no common log entry between store entries and log entries", log.WithLogURLString(logURL),
+		log.WithIndexUint64(uint64(firstStoredIndex)))
 
-	// not found or zero index have same meaning, all current entries should be marked failed in the store and
-	// all log entries should be added to the store
-	return 0, allDifferentLogEntries, nil
+	// not found or first stored index have same meaning, all current entries should be marked failed in the store
+	// and all log entries from the first stored index should be added to the store
+	return firstStoredIndex, allDifferentLogEntries, nil
+}
+
+// getFirstStoredIndex returns the lowest index of the entries in the store (or zero if the store has no entries).
+func (c *Client) getFirstStoredIndex(logURL string) (int64, error) {
+	index, ok, err := c.entryStore.GetFirstIndex(logURL)
+	if err != nil {
+		return 0, fmt.Errorf("get first stored index: %w", err)
+	}
+
+	if !ok {
+		return 0, nil
+	}
+
+	return int64(index), nil
 }
 
 func (c *Client) getStoreEntriesFrom(logURL string, start uint64, maxCount int) ([]*command.LeafEntry, error) {
@@ -500,7 +523,8 @@ func (c *Client) verifySTHConsistency(logURL string, storedSTH, sth *command.Get
 	return nil
 }
 
-func getPublicKey(vctClient *vct.Client) ([]byte, error) {
+// GetPublicKey retrieves the public key of the VCT log from its webfinger endpoint.
+func GetPublicKey(vctClient *vct.Client) ([]byte, error) {
 	webResp, err := vctClient.Webfinger(context.Background())
 	if err != nil {
 		return nil, fmt.Errorf("webfinger: %w", err)
@@ -524,7 +548,8 @@ func getPublicKey(vctClient *vct.Client) ([]byte, error) {
 	return pubKey, nil
 }
 
-func verifySTHSignature(sth *command.GetSTHResponse, pubKey []byte) error {
+// VerifySTHSignature verifies the signature of the signed tree head using the given public key of the VCT log.
+func VerifySTHSignature(sth *command.GetSTHResponse, pubKey []byte) error {
 	var sig *command.DigitallySigned
 
 	err := json.Unmarshal(sth.TreeHeadSignature, &sig)
@@ -597,3 +622,7 @@ func (s *noopLogEntryStore) FailLogEntriesFrom(logURL string, start uint64) erro
 func (s *noopLogEntryStore) GetLogEntriesFrom(logURL string, start uint64) (logentry.EntryIterator, error) {
 	return nil, nil
 }
+
+func (s *noopLogEntryStore) GetFirstIndex(logURL string) (uint64, bool, error) {
+	return 0, false, nil
+}
@@ -1440,6 +1440,185 @@ func TestClient_checkVCTConsistency(t *testing.T) {
 		require.Contains(t, err.Error(), "get entries error")
 	})
 
+	t.Run("recovery - stored tree size is greater than log tree size (stored=5, log=4)"+
+		"error due to get first index from entry store failure", func(t *testing.T) {
+		store, err := logmonitor.New(mem.NewProvider())
+		require.NoError(t, err)
+
+		err = store.Activate(testLog)
+		require.NoError(t, err)
+
+		logMonitor, err := store.Get(testLog)
+		require.NoError(t, err)
+
+		var sthResponse command.GetSTHResponse
+		err = json.Unmarshal([]byte(sth5), &sthResponse)
+		require.NoError(t, err)
+
+		logMonitor.STH = &sthResponse
+
+		err = store.Update(logMonitor)
+		require.NoError(t, err)
+
+		client, err := New(store, httpMock(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path == sthURL {
+				return &http.Response{
+					Body:       io.NopCloser(bytes.NewBufferString(sth4)),
+					StatusCode: http.StatusOK,
+				}, nil
+			}
+
+			if req.URL.Path == webfingerURL {
+				expected := command.WebFingerResponse{
+					Subject: "https://vct.com/maple2021",
+					Properties: map[string]interface{}{
+						"https://trustbloc.dev/ns/public-key": PublicKey,
+					},
+					Links: []command.WebFingerLink{{
+						Rel:  "self",
+						Href: "https://vct.com/maple2021",
+					}},
+				}
+
+				fakeResp, innerErr := json.Marshal(expected)
+				require.NoError(t, innerErr)
+
+				return &http.Response{
+					Body:       io.NopCloser(bytes.NewBuffer(fakeResp)),
+					StatusCode: http.StatusOK,
+				}, nil
+			}
+
+			if req.URL.Path == sthConsistencyURL {
+				return &http.Response{
+					Body:       io.NopCloser(bytes.NewBufferString("{}")),
+					StatusCode: http.StatusOK,
+				}, nil
+			}
+
+			if req.URL.Path == getEntriesURL {
+				expected := command.GetEntriesResponse{
+					Entries: []command.LeafEntry{
+						{
+							LeafInput: []byte("leafInput-0"),
+						},
+					},
+				}
+
+				fakeResp, e := json.Marshal(expected)
+				require.NoError(t, e)
+
+				return &http.Response{
+					Body:       io.NopCloser(bytes.NewBuffer(fakeResp)),
+					StatusCode: http.StatusOK,
+				}, nil
+			}
+
+			return &http.Response{
+				Body:       io.NopCloser(bytes.NewBufferString(`{}`)),
+				StatusCode: http.StatusInternalServerError,
+			}, nil
+		}), map[string]string{},
+			WithLogEntriesStoreEnabled(true),
+			WithLogEntriesStore(&mockLogEntryStore{FirstIndexErr: fmt.Errorf("first index error")}))
+		require.NoError(t, err)
+
+		client.logVerifier = &mockLogVerifier{}
+
+		err = client.checkVCTConsistency(logMonitor)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get first stored index: first index error")
+	})
+
+	t.Run("recovery - stored tree size is greater than log tree size (stored=5, log=4)"+
+		"entries below first stored index were compacted", func(t *testing.T) {
+		store, err := logmonitor.New(mem.NewProvider())
+		require.NoError(t, err)
+
+		err = store.Activate(testLog)
+		require.NoError(t, err)
+
+		logMonitor, err := store.Get(testLog)
+		require.NoError(t, err)
+
+		var sthResponse command.GetSTHResponse
+		err = json.Unmarshal([]byte(sth5), &sthResponse)
+		require.NoError(t, err)
+
+		logMonitor.STH = &sthResponse
+
+		err = store.Update(logMonitor)
+		require.NoError(t, err)
+
+		client, err := New(store, httpMock(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path == sthURL {
+				return &http.Response{
+					Body:       io.NopCloser(bytes.NewBufferString(sth4)),
+					StatusCode: http.StatusOK,
+				}, nil
+			}
+
+			if req.URL.Path == webfingerURL {
+				expected := command.WebFingerResponse{
+					Subject: "https://vct.com/maple2021",
+					Properties: map[string]interface{}{
+						"https://trustbloc.dev/ns/public-key": PublicKey,
+					},
+					Links: []command.WebFingerLink{{
+						Rel:  "self",
+						Href: "https://vct.com/maple2021",
+					}},
+				}
+
+				fakeResp, innerErr := json.Marshal(expected)
+				require.NoError(t, innerErr)
+
+				return &http.Response{
+					Body:       io.NopCloser(bytes.NewBuffer(fakeResp)),
+					StatusCode: http.StatusOK,
+				}, nil
+			}
+
+			if req.URL.Path == sthConsistencyURL {
+				return &http.Response{
+					Body:       io.NopCloser(bytes.NewBufferString("{}")),
+					StatusCode: http.StatusOK,
+				}, nil
+			}
+
+			if req.URL.Path == getEntriesURL {
+				expected := command.GetEntriesResponse{
+					Entries: []command.LeafEntry{
+						{
+							LeafInput: []byte("leafInput-0"),
+						},
+					},
+				}
+
+				fakeResp, e := json.Marshal(expected)
+				require.NoError(t, e)
+
+				return &http.Response{
+					Body:       io.NopCloser(bytes.NewBuffer(fakeResp)),
+					StatusCode: http.StatusOK,
+				}, nil
+			}
+
+			return &http.Response{
+				Body:       io.NopCloser(bytes.NewBufferString(`{}`)),
+				StatusCode: http.StatusInternalServerError,
+			}, nil
+		}), map[string]string{},
+			WithLogEntriesStoreEnabled(true),
+			WithLogEntriesStore(&mockLogEntryStore{FirstIndex: 5, FirstIndexExists: true}))
+		require.NoError(t, err)
+
+		client.logVerifier = &mockLogVerifier{}
+
+		err = client.checkVCTConsistency(logMonitor)
+		require.NoError(t, err)
+	})
+
 	t.Run("recovery - stored tree size is greater than log tree size (stored=5, log=4)"+
 		"error due to fail entries in entry store failure", func(t *testing.T) {
 		store, err := logmonitor.New(mem.NewProvider())
@@ -2613,7 +2792,7 @@ func TestClient_GetPublicKey(t *testing.T) {
 			}, nil
 		})))
 
-		pubKey, err := getPublicKey(vctClient)
+		pubKey, err := GetPublicKey(vctClient)
 		require.NoError(t, err)
 		require.NotNil(t, pubKey)
 	})
@@ -2640,7 +2819,7 @@ func TestClient_GetPublicKey(t *testing.T) {
 			}, nil
 		})))
 
-		pubKey, err := getPublicKey(vctClient)
+		pubKey, err := GetPublicKey(vctClient)
 		require.Error(t, err)
 		require.Nil(t, pubKey)
 		require.Contains(t, err.Error(), "public key is not a string")
@@ -2668,7 +2847,7 @@ func TestClient_GetPublicKey(t *testing.T) {
 			}, nil
 		})))
 
-		pubKey, err := getPublicKey(vctClient)
+		pubKey, err := GetPublicKey(vctClient)
 		require.Error(t, err)
 		require.Nil(t, pubKey)
 		require.Contains(t, err.Error(), "decode public key: illegal base64 data")
@@ -2682,7 +2861,7 @@ func TestClient_GetPublicKey(t *testing.T) {
 			}, nil
 		})))
 
-		pubKey, err := getPublicKey(vctClient)
+		pubKey, err := GetPublicKey(vctClient)
 		require.Error(t, err)
 		require.Nil(t, pubKey)
 	})
@@ -2695,7 +2874,7 @@ func TestClient_GetPublicKey(t *testing.T) {
 			}, nil
 		})))
 
-		pubKey, err := getPublicKey(vctClient)
+		pubKey, err := GetPublicKey(vctClient)
 		require.Error(t, err)
 		require.Nil(t, pubKey)
 		require.Contains(t, err.Error(), "no public key")
@@ -2728,11 +2907,19 @@ func (v *mockLogVerifier) GetRootHashFromEntries(entries []*command.LeafEntry) (
 }
 
 type mockLogEntryStore struct {
-	StoreErr error
-	FailErr  error
-	GetErr   error
+	StoreErr      error
+	FailErr       error
+	GetErr        error
+	FirstIndexErr error
 
 	GetIter *mockLogEntryIterator
+
+	FirstIndex       uint64
+	FirstIndexExists bool
+}
+
+func (s *mockLogEntryStore) GetFirstIndex(logURL string) (uint64, bool, error) {
+	return s.FirstIndex, s.FirstIndexExists, s.FirstIndexErr
 }
 
 func (s *mockLogEntryStore) StoreLogEntries(log string, start, end uint64, entries []command.LeafEntry) error {
@@ -0,0 +1,272 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+	"github.com/trustbloc/vct/pkg/controller/command"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/store/logentry"
+)
+
+const (
+	archivesEndpoint        = endpoint + "/archives"
+	importedEntriesEndpoint = archivesEndpoint + "/entries"
+
+	logURLParam = "log"
+)
+
+type logEntryStore interface {
+	GetArchives(logURL string) ([]*logentry.ArchiveRecord, error)
+	ImportArchive(location string) error
+	GetImportedLogEntries(logURL string) (logentry.EntryIterator, error)
+}
+
+// ArchivesHandler retrieves the records of the archives that were produced by log entry compaction.
+type ArchivesHandler struct {
+	logEntryStore logEntryStore
+	logger        *log.Log
+	marshal       func(interface{}) ([]byte, error)
+}
+
+// Path returns the HTTP REST endpoint for the archives retriever.
+func (h *ArchivesHandler) Path() string {
+	return archivesEndpoint
+}
+
+// Method returns the HTTP REST method for the archives retriever.
+func (h *ArchivesHandler) Method() string {
+	return http.MethodGet
+}
+
+// Handler returns the HTTP REST handle for the archives retriever.
+func (h *ArchivesHandler) Handler() common.HTTPRequestHandler {
+	return h.handle
+}
+
+// NewArchivesHandler returns a new ArchivesHandler.
+func NewArchivesHandler(store logEntryStore) *ArchivesHandler {
+	return &ArchivesHandler{
+		logEntryStore: store,
+		logger:        log.New(loggerModule, log.WithFields(log.WithServiceEndpoint(archivesEndpoint))),
+		marshal:       json.Marshal,
+	}
+}
+
+func (h *ArchivesHandler) handle(w http.ResponseWriter, req *http.Request) {
+	logURL := req.URL.Query().Get(logURLParam)
+	if logURL == "" {
+		h.logger.Debug("Missing log URL in request")
+
+		writeResponse(h.logger, w, http.StatusBadRequest, []byte(badRequestResponse))
+
+		return
+	}
+
+	records, err := h.logEntryStore.GetArchives(logURL)
+	if err != nil {
+		h.logger.Error("Error retrieving archives", log.WithLogURLString(logURL), log.WithError(err))
+
+		writeResponse(h.logger, w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	if len(records) == 0 {
+		h.logger.Debug("No archives found for log", log.WithLogURLString(logURL))
+
+		writeResponse(h.logger, w, http.StatusNotFound, []byte(notFoundResponse))
+
+		return
+	}
+
+	retBytes, err := h.marshal(records)
+	if err != nil {
+		h.logger.Error("Marshal archive records error", log.WithError(err))
+
+		writeResponse(h.logger, w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	writeResponse(h.logger, w, http.StatusOK, retBytes)
+}
+
+// ImportArchiveHandler imports the entries of a previously compacted archive into the log entry store.
+type ImportArchiveHandler struct {
+	logEntryStore logEntryStore
+	logger        *log.Log
+	unmarshal     func([]byte, interface{}) error
+}
+
+// Path returns the HTTP REST endpoint for the archive importer.
+func (h *ImportArchiveHandler) Path() string {
+	return archivesEndpoint
+}
+
+// Method returns the HTTP REST method for the archive importer.
+func (h *ImportArchiveHandler) Method() string {
+	return http.MethodPost
+}
+
+// Handler returns the HTTP REST handle for the archive importer.
+func (h *ImportArchiveHandler) Handler() common.HTTPRequestHandler {
+	return h.handle
+}
+
+// NewImportArchiveHandler returns a new ImportArchiveHandler.
+func NewImportArchiveHandler(store logEntryStore) *ImportArchiveHandler {
+	return &ImportArchiveHandler{
+		logEntryStore: store,
+		logger:        log.New(loggerModule, log.WithFields(log.WithServiceEndpoint(archivesEndpoint))),
+		unmarshal:     json.Unmarshal,
+	}
+}
+
+func (h *ImportArchiveHandler) handle(w http.ResponseWriter, req *http.Request) {
+	reqBytes, err := io.ReadAll(req.Body)
+	if err != nil {
+		h.logger.Error("Error reading request body", log.WithError(err))
+
+		writeResponse(h.logger, w, http.StatusBadRequest, []byte(badRequestResponse))
+
+		return
+	}
+
+	request := &importArchiveRequest{}
+
+	err = h.unmarshal(reqBytes, request)
+	if err != nil || request.Location == "" {
+		h.logger.Info("Invalid import archive request", log.WithRequestBody(reqBytes), log.WithError(err))
+
+		writeResponse(h.logger, w, http.StatusBadRequest, []byte(badRequestResponse))
+
+		return
+	}
+
+	err = h.logEntryStore.ImportArchive(request.Location)
+	if err != nil {
+		h.logger.Error("Error importing archive", log.WithError(err))
+
+		writeResponse(h.logger, w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	writeResponse(h.logger, w, http.StatusOK, nil)
+}
+
+type importArchiveRequest struct {
+	Location string `json:"location"`
+}
+
+// ImportedEntriesHandler retrieves the log entries that were imported from archives.
+type ImportedEntriesHandler struct {
+	logEntryStore logEntryStore
+	logger        *log.Log
+	marshal       func(interface{}) ([]byte, error)
+}
+
+// Path returns the HTTP REST endpoint for the imported entries retriever.
+func (h *ImportedEntriesHandler) Path() string {
+	return importedEntriesEndpoint
+}
+
+// Method returns the HTTP REST method for the imported entries retriever.
+func (h *ImportedEntriesHandler) Method() string {
+	return http.MethodGet
+}
+
+// Handler returns the HTTP REST handle for the imported entries retriever.
+func (h *ImportedEntriesHandler) Handler() common.HTTPRequestHandler {
+	return h.handle
+}
+
+// NewImportedEntriesHandler returns a new ImportedEntriesHandler.
+func NewImportedEntriesHandler(store logEntryStore) *ImportedEntriesHandler {
+	return &ImportedEntriesHandler{
+		logEntryStore: store,
+		logger:        log.New(loggerModule, log.WithFields(log.WithServiceEndpoint(importedEntriesEndpoint))),
+		marshal:       json.Marshal,
+	}
+}
+
+func (h *ImportedEntriesHandler) handle(w http.ResponseWriter, req *http.Request) {
+	logURL := req.URL.Query().Get(logURLParam)
+	if logURL == "" {
+		h.logger.Debug("Missing log URL in request")
+
+		writeResponse(h.logger, w, http.StatusBadRequest, []byte(badRequestResponse))
+
+		return
+	}
+
+	entries, err := h.getImportedEntries(logURL)
+	if err != nil {
+		h.logger.Error("Error retrieving imported entries", log.WithLogURLString(logURL), log.WithError(err))
+
+		writeResponse(h.logger, w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	if len(entries) == 0 {
+		h.logger.Debug("No imported entries found for log", log.WithLogURLString(logURL))
+
+		writeResponse(h.logger, w, http.StatusNotFound, []byte(notFoundResponse))
+
+		return
+	}
+
+	retBytes, err := h.marshal(entries)
+	if err != nil {
+		h.logger.Error("Marshal imported entries error", log.WithError(err))
+
+		writeResponse(h.logger, w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	writeResponse(h.logger, w, http.StatusOK, retBytes)
+}
+
+func (h *ImportedEntriesHandler) getImportedEntries(logURL string) ([]*command.LeafEntry, error) {
+	iter, err := h.logEntryStore.GetImportedLogEntries(logURL)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if errClose := iter.Close(); errClose != nil {
+			log.CloseIteratorError(h.logger, errClose)
+		}
+	}()
+
+	var entries []*command.LeafEntry
+
+	for {
+		entry, err := iter.Next()
+		if err != nil {
+			if errors.Is(err, logentry.ErrDataNotFound) {
+				break
+			}
+
+			return nil, fmt.Errorf("next imported entry: %w", err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
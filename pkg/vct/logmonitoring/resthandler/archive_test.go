@@ -0,0 +1,371 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/vct/pkg/controller/command"
+
+	"github.com/trustbloc/orb/pkg/store/logentry"
+)
+
+const testLogURL = "https://vct.com/log"
+
+func TestNewArchivesHandler(t *testing.T) {
+	handler := NewArchivesHandler(&mockLogEntryStore{})
+	require.NotNil(t, handler)
+	require.Equal(t, archivesEndpoint, handler.Path())
+	require.Equal(t, http.MethodGet, handler.Method())
+	require.NotNil(t, handler.Handler())
+}
+
+func TestArchivesHandler(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		handler := NewArchivesHandler(&mockLogEntryStore{
+			Archives: []*logentry.ArchiveRecord{
+				{LogURL: testLogURL, From: 0, To: 9, Count: 10, Location: "archive-1"},
+			},
+		})
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, archivesEndpoint+"?log="+testLogURL, nil)
+
+		handler.handle(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+
+		respBytes, err := io.ReadAll(result.Body)
+		require.NoError(t, err)
+		require.NoError(t, result.Body.Close())
+
+		var records []*logentry.ArchiveRecord
+
+		require.NoError(t, json.Unmarshal(respBytes, &records))
+		require.Len(t, records, 1)
+		require.Equal(t, "archive-1", records[0].Location)
+	})
+
+	t.Run("missing log URL", func(t *testing.T) {
+		handler := NewArchivesHandler(&mockLogEntryStore{})
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, archivesEndpoint, nil)
+
+		handler.handle(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusBadRequest, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		handler := NewArchivesHandler(&mockLogEntryStore{})
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, archivesEndpoint+"?log="+testLogURL, nil)
+
+		handler.handle(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusNotFound, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		handler := NewArchivesHandler(&mockLogEntryStore{Err: errors.New("injected store error")})
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, archivesEndpoint+"?log="+testLogURL, nil)
+
+		handler.handle(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("marshal error", func(t *testing.T) {
+		handler := NewArchivesHandler(&mockLogEntryStore{
+			Archives: []*logentry.ArchiveRecord{{LogURL: testLogURL}},
+		})
+
+		handler.marshal = func(interface{}) ([]byte, error) {
+			return nil, errors.New("injected marshal error")
+		}
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, archivesEndpoint+"?log="+testLogURL, nil)
+
+		handler.handle(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+}
+
+func TestNewImportArchiveHandler(t *testing.T) {
+	handler := NewImportArchiveHandler(&mockLogEntryStore{})
+	require.NotNil(t, handler)
+	require.Equal(t, archivesEndpoint, handler.Path())
+	require.Equal(t, http.MethodPost, handler.Method())
+	require.NotNil(t, handler.Handler())
+}
+
+func TestImportArchiveHandler(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		store := &mockLogEntryStore{}
+
+		handler := NewImportArchiveHandler(store)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, archivesEndpoint,
+			bytes.NewBufferString(`{"location":"archive-1"}`))
+
+		handler.handle(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+		require.Equal(t, "archive-1", store.ImportedLocation)
+	})
+
+	t.Run("missing location", func(t *testing.T) {
+		handler := NewImportArchiveHandler(&mockLogEntryStore{})
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, archivesEndpoint, bytes.NewBufferString(`{}`))
+
+		handler.handle(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusBadRequest, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		handler := NewImportArchiveHandler(&mockLogEntryStore{})
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, archivesEndpoint, bytes.NewBufferString(`{`))
+
+		handler.handle(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusBadRequest, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("read request error", func(t *testing.T) {
+		handler := NewImportArchiveHandler(&mockLogEntryStore{})
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, archivesEndpoint, errReader(0))
+
+		handler.handle(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusBadRequest, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("import error", func(t *testing.T) {
+		handler := NewImportArchiveHandler(&mockLogEntryStore{Err: errors.New("injected import error")})
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, archivesEndpoint,
+			bytes.NewBufferString(`{"location":"archive-1"}`))
+
+		handler.handle(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+}
+
+func TestNewImportedEntriesHandler(t *testing.T) {
+	handler := NewImportedEntriesHandler(&mockLogEntryStore{})
+	require.NotNil(t, handler)
+	require.Equal(t, importedEntriesEndpoint, handler.Path())
+	require.Equal(t, http.MethodGet, handler.Method())
+	require.NotNil(t, handler.Handler())
+}
+
+func TestImportedEntriesHandler(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		handler := NewImportedEntriesHandler(&mockLogEntryStore{
+			ImportedEntries: []*command.LeafEntry{
+				{LeafInput: []byte("leafInput-0")},
+				{LeafInput: []byte("leafInput-1")},
+			},
+		})
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, importedEntriesEndpoint+"?log="+testLogURL, nil)
+
+		handler.handle(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+
+		respBytes, err := io.ReadAll(result.Body)
+		require.NoError(t, err)
+		require.NoError(t, result.Body.Close())
+
+		var entries []*command.LeafEntry
+
+		require.NoError(t, json.Unmarshal(respBytes, &entries))
+		require.Len(t, entries, 2)
+		require.Equal(t, []byte("leafInput-1"), entries[1].LeafInput)
+	})
+
+	t.Run("missing log URL", func(t *testing.T) {
+		handler := NewImportedEntriesHandler(&mockLogEntryStore{})
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, importedEntriesEndpoint, nil)
+
+		handler.handle(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusBadRequest, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		handler := NewImportedEntriesHandler(&mockLogEntryStore{})
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, importedEntriesEndpoint+"?log="+testLogURL, nil)
+
+		handler.handle(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusNotFound, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		handler := NewImportedEntriesHandler(&mockLogEntryStore{Err: errors.New("injected store error")})
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, importedEntriesEndpoint+"?log="+testLogURL, nil)
+
+		handler.handle(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("iterator error", func(t *testing.T) {
+		handler := NewImportedEntriesHandler(&mockLogEntryStore{NextErr: errors.New("injected next error")})
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, importedEntriesEndpoint+"?log="+testLogURL, nil)
+
+		handler.handle(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("marshal error", func(t *testing.T) {
+		handler := NewImportedEntriesHandler(&mockLogEntryStore{
+			ImportedEntries: []*command.LeafEntry{{LeafInput: []byte("leafInput-0")}},
+		})
+
+		handler.marshal = func(interface{}) ([]byte, error) {
+			return nil, fmt.Errorf("injected marshal error")
+		}
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, importedEntriesEndpoint+"?log="+testLogURL, nil)
+
+		handler.handle(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+}
+
+type mockLogEntryStore struct {
+	Err             error
+	NextErr         error
+	Archives        []*logentry.ArchiveRecord
+	ImportedEntries []*command.LeafEntry
+
+	ImportedLocation string
+}
+
+func (m *mockLogEntryStore) GetArchives(string) ([]*logentry.ArchiveRecord, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+
+	return m.Archives, nil
+}
+
+func (m *mockLogEntryStore) ImportArchive(location string) error {
+	if m.Err != nil {
+		return m.Err
+	}
+
+	m.ImportedLocation = location
+
+	return nil
+}
+
+func (m *mockLogEntryStore) GetImportedLogEntries(string) (logentry.EntryIterator, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+
+	return &mockEntryIterator{entries: m.ImportedEntries, nextErr: m.NextErr}, nil
+}
+
+type mockEntryIterator struct {
+	entries []*command.LeafEntry
+	nextErr error
+	current int
+}
+
+func (it *mockEntryIterator) TotalItems() (int, error) {
+	return len(it.entries), nil
+}
+
+func (it *mockEntryIterator) Next() (*command.LeafEntry, error) {
+	if it.nextErr != nil {
+		return nil, it.nextErr
+	}
+
+	if it.current >= len(it.entries) {
+		return nil, logentry.ErrDataNotFound
+	}
+
+	entry := it.entries[it.current]
+	it.current++
+
+	return entry, nil
+}
+
+func (it *mockEntryIterator) Close() error {
+	return nil
+}
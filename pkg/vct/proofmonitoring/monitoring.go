@@ -12,6 +12,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
@@ -20,7 +21,11 @@ import (
 	"github.com/trustbloc/vct/pkg/client/vct"
 
 	"github.com/trustbloc/orb/internal/pkg/log"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/observability/metrics"
+	"github.com/trustbloc/orb/pkg/observability/metrics/noop"
 	"github.com/trustbloc/orb/pkg/store"
+	"github.com/trustbloc/orb/pkg/store/expiry"
 	"github.com/trustbloc/orb/pkg/webfinger/model"
 )
 
@@ -31,12 +36,27 @@ const (
 	storeName         = "proof-monitor"
 	keyPrefix         = "queue"
 	tagStatus         = "status"
+	tagExpiryTime     = "expiryTime"
 	statusUnconfirmed = "unconfirmed"
+	statusFailed      = "failed"
 
 	vctReadTokenKey  = "vct-read"
 	vctWriteTokenKey = "vct-write"
 
 	vctV1LedgerType = "vct-v1"
+
+	defaultFailedRetentionPeriod = 24 * time.Hour
+)
+
+// Status values of a watched credential.
+const (
+	// StatusPending indicates that the credential is not yet in the ledger and the deadline has not passed.
+	StatusPending = "pending"
+	// StatusOverdue indicates that the credential is not yet in the ledger and the deadline has passed. The
+	// credential will be marked as failed on the next check.
+	StatusOverdue = "overdue"
+	// StatusFailed indicates that the credential did not appear in the ledger before the deadline.
+	StatusFailed = "failed"
 )
 
 // httpClient represents HTTP client.
@@ -55,6 +75,36 @@ type Client struct {
 	http           httpClient
 	wfClient       webfingerClient
 	requestTokens  map[string]string
+	metrics        metricsProvider
+
+	expiryService         *expiry.Service
+	failedRetentionPeriod time.Duration
+}
+
+type metricsProvider interface {
+	ProofMonitorPendingCount(value int)
+	ProofMonitorFailedCount()
+	ProofMonitorInclusionTime(value time.Duration)
+}
+
+// Option is an option for the monitoring client.
+type Option func(c *Client)
+
+// WithMetrics sets the metrics provider.
+func WithMetrics(m metrics.Metrics) Option {
+	return func(c *Client) {
+		c.metrics = m
+	}
+}
+
+// WithExpiryService sets the expiry service. If set then credentials that failed to appear in the ledger are
+// retained (and reported) for the given period of time before being deleted. Otherwise, failed credentials
+// are deleted immediately.
+func WithExpiryService(expiryService *expiry.Service, failedRetentionPeriod time.Duration) Option {
+	return func(c *Client) {
+		c.expiryService = expiryService
+		c.failedRetentionPeriod = failedRetentionPeriod
+	}
 }
 
 type taskManager interface {
@@ -64,20 +114,31 @@ type taskManager interface {
 // New returns monitoring client.
 func New(provider storage.Provider, documentLoader ld.DocumentLoader, wfClient webfingerClient,
 	httpClient httpClient, taskMgr taskManager, interval time.Duration,
-	requestTokens map[string]string) (*Client, error) {
+	requestTokens map[string]string, opts ...Option) (*Client, error) {
 	s, err := store.Open(provider, storeName,
 		store.NewTagGroup(tagStatus),
+		store.NewTagGroup(tagExpiryTime),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("open store: %w", err)
 	}
 
 	client := &Client{
-		documentLoader: documentLoader,
-		store:          s,
-		http:           httpClient,
-		wfClient:       wfClient,
-		requestTokens:  requestTokens,
+		documentLoader:        documentLoader,
+		store:                 s,
+		http:                  httpClient,
+		wfClient:              wfClient,
+		requestTokens:         requestTokens,
+		metrics:               noop.GetMetrics(),
+		failedRetentionPeriod: defaultFailedRetentionPeriod,
+	}
+
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	if client.expiryService != nil {
+		client.expiryService.Register(s, tagExpiryTime, storeName)
 	}
 
 	logger.Info("Registering task with Task Manager", log.WithTaskID(taskID), log.WithTaskMonitorInterval(interval))
@@ -101,6 +162,19 @@ type entity struct {
 	Domain         string    `json:"domain"`
 	Created        time.Time `json:"created"`
 	Status         string    `json:"status"`
+	LastChecked    time.Time `json:"lastChecked,omitempty"`
+	LastCheckError string    `json:"lastCheckError,omitempty"`
+}
+
+// WatchedCredential contains the monitoring status of a credential that is waiting to be included in a ledger.
+type WatchedCredential struct {
+	CredentialID   string    `json:"credentialId"`
+	Domain         string    `json:"domain"`
+	Created        time.Time `json:"created"`
+	ExpirationTime time.Time `json:"expirationTime"`
+	Status         string    `json:"status"`
+	LastChecked    time.Time `json:"lastChecked,omitempty"`
+	LastCheckError string    `json:"lastCheckError,omitempty"`
 }
 
 var errExpired = errors.New("expired")
@@ -177,6 +251,8 @@ func (c *Client) handleEntities() error { //nolint:cyclop
 		}
 	}()
 
+	var pending int
+
 	for Next(records) {
 		var src []byte
 
@@ -206,6 +282,8 @@ func (c *Client) handleEntities() error { //nolint:cyclop
 			logger.Info("Credential existence in the ledger is confirmed",
 				log.WithVerifiableCredentialID(vc.ID), log.WithDomain(e.Domain))
 
+			c.metrics.ProofMonitorInclusionTime(time.Since(e.Created))
+
 			// removes the entity from the store bc we confirmed that credential is in MT (log above).
 			if err = c.store.Delete(key(vc.ID)); err != nil {
 				logger.Error("Error deleting credential from queue",
@@ -219,22 +297,177 @@ func (c *Client) handleEntities() error { //nolint:cyclop
 			logger.Warn("Error determining credential existence",
 				log.WithVerifiableCredentialID(vc.ID), log.WithError(err))
 
+			pending++
+
+			if err = c.updateLastCheck(vc.ID, e, err); err != nil {
+				logger.Error("Error updating credential in queue",
+					log.WithVerifiableCredentialID(vc.ID), log.WithError(err))
+			}
+
 			continue
 		}
 
 		logger.Error("Credential existence in the ledger not confirmed.",
 			log.WithVerifiableCredentialID(vc.ID), log.WithDomain(e.Domain))
 
-		// removes entity from the store bc we failed our promise (log above).
-		if err = c.store.Delete(key(vc.ID)); err != nil {
-			logger.Error("Error deleting credential from queue",
+		c.metrics.ProofMonitorFailedCount()
+
+		// removes entity from the queue bc we failed our promise (log above).
+		if err = c.markFailed(vc.ID, e); err != nil {
+			logger.Error("Error removing credential from queue",
 				log.WithVerifiableCredentialID(vc.ID), log.WithError(err))
 		}
 	}
 
+	c.metrics.ProofMonitorPendingCount(pending)
+
 	return nil
 }
 
+func (c *Client) updateLastCheck(id string, e *entity, checkErr error) error {
+	e.LastChecked = time.Now()
+	e.LastCheckError = checkErr.Error()
+
+	src, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshal entity: %w", err)
+	}
+
+	return c.store.Put(key(id), src,
+		storage.Tag{Name: tagStatus, Value: statusUnconfirmed},
+	)
+}
+
+// markFailed retains the failed entity until it expires (if the expiry service is configured).
+// Otherwise, the entity is deleted.
+func (c *Client) markFailed(id string, e *entity) error {
+	if c.expiryService == nil {
+		return c.store.Delete(key(id))
+	}
+
+	e.Status = statusFailed
+	e.LastChecked = time.Now()
+	e.LastCheckError = errExpired.Error()
+
+	src, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshal entity: %w", err)
+	}
+
+	return c.store.Put(key(id), src,
+		storage.Tag{Name: tagStatus, Value: statusFailed},
+		storage.Tag{
+			Name:  tagExpiryTime,
+			Value: fmt.Sprintf("%d", time.Now().Add(c.failedRetentionPeriod).Unix()),
+		},
+	)
+}
+
+// GetWatched returns the credentials currently being watched with the given status (pending, overdue or failed).
+// If status is empty then credentials with any status are returned. orberrors.ErrContentNotFound is returned if
+// no credentials were found.
+func (c *Client) GetWatched(status string) ([]*WatchedCredential, error) {
+	var storeStatuses []string
+
+	switch status {
+	case "":
+		storeStatuses = []string{statusUnconfirmed, statusFailed}
+	case StatusPending, StatusOverdue:
+		storeStatuses = []string{statusUnconfirmed}
+	case StatusFailed:
+		storeStatuses = []string{statusFailed}
+	default:
+		return nil, fmt.Errorf("unsupported status: %s", status)
+	}
+
+	var watched []*WatchedCredential
+
+	for _, storeStatus := range storeStatuses {
+		credentials, err := c.queryWatched(storeStatus)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, wc := range credentials {
+			if status == "" || wc.Status == status {
+				watched = append(watched, wc)
+			}
+		}
+	}
+
+	if len(watched) == 0 {
+		return nil, orberrors.ErrContentNotFound
+	}
+
+	return watched, nil
+}
+
+func (c *Client) queryWatched(storeStatus string) ([]*WatchedCredential, error) {
+	expr := fmt.Sprintf("%s:%s", tagStatus, storeStatus)
+
+	records, err := c.store.Query(expr)
+	if err != nil {
+		return nil, fmt.Errorf("query %s: %w", expr, err)
+	}
+
+	defer func() {
+		if e := records.Close(); e != nil {
+			log.CloseIteratorError(logger, e)
+		}
+	}()
+
+	var watched []*WatchedCredential
+
+	for {
+		ok, err := records.Next()
+		if err != nil {
+			return nil, fmt.Errorf("get next entity: %w", err)
+		}
+
+		if !ok {
+			break
+		}
+
+		k, err := records.Key()
+		if err != nil {
+			return nil, fmt.Errorf("get entity key: %w", err)
+		}
+
+		src, err := records.Value()
+		if err != nil {
+			return nil, fmt.Errorf("get entity value: %w", err)
+		}
+
+		var e entity
+		if err := json.Unmarshal(src, &e); err != nil {
+			return nil, fmt.Errorf("unmarshal entity: %w", err)
+		}
+
+		watched = append(watched, &WatchedCredential{
+			CredentialID:   strings.TrimPrefix(k, keyPrefix),
+			Domain:         e.Domain,
+			Created:        e.Created,
+			ExpirationTime: e.ExpirationTime,
+			Status:         e.watchStatus(),
+			LastChecked:    e.LastChecked,
+			LastCheckError: e.LastCheckError,
+		})
+	}
+
+	return watched, nil
+}
+
+func (e *entity) watchStatus() string {
+	switch {
+	case e.Status == statusFailed:
+		return StatusFailed
+	case time.Now().After(e.ExpirationTime):
+		return StatusOverdue
+	default:
+		return StatusPending
+	}
+}
+
 // Watch starts monitoring.
 func (c *Client) Watch(vc *verifiable.Credential, endTime time.Time, domain string, created time.Time) error {
 	if domain == "" {
@@ -285,6 +518,8 @@ func (c *Client) checkExistenceInLedger(vc *verifiable.Credential, domain string
 		logger.Info("Credential existence in the ledger confirmed", log.WithVerifiableCredentialID(vc.ID),
 			log.WithDomain(e.Domain))
 
+		c.metrics.ProofMonitorInclusionTime(time.Since(created))
+
 		return nil
 	}
 
@@ -299,6 +534,9 @@ func (c *Client) checkExistenceInLedger(vc *verifiable.Credential, domain string
 	logger.Warn("Credential is not in the ledger yet. Will check again later.", log.WithVerifiableCredentialID(vc.ID),
 		log.WithDomain(e.Domain), log.WithError(err))
 
+	e.LastChecked = time.Now()
+	e.LastCheckError = err.Error()
+
 	src, err := json.Marshal(e)
 	if err != nil {
 		return fmt.Errorf("marshal entity: %w", err)
@@ -13,6 +13,7 @@ import (
 	"io"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -26,7 +27,10 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/trustbloc/orb/pkg/activitypub/service/mocks"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
 	"github.com/trustbloc/orb/pkg/internal/testutil"
+	"github.com/trustbloc/orb/pkg/observability/metrics/noop"
+	"github.com/trustbloc/orb/pkg/store/expiry"
 	. "github.com/trustbloc/orb/pkg/vct/proofmonitoring"
 	wfclient "github.com/trustbloc/orb/pkg/webfinger/client"
 )
@@ -689,6 +693,150 @@ func TestClient_Watch(t *testing.T) { //nolint:cyclop,maintidx
 	})
 }
 
+func TestClient_GetWatched(t *testing.T) {
+	wfClient := wfclient.New(wfclient.WithHTTPClient(httpMock(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			Body:       io.NopCloser(bytes.NewBufferString(webfingerPayload)),
+			StatusCode: http.StatusOK,
+		}, nil
+	})))
+
+	// An empty STH response results in a tree size of zero, so the credential escapes to the queue.
+	vctHTTPClient := httpMock(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			Body:       io.NopCloser(bytes.NewBufferString(`{}`)),
+			StatusCode: http.StatusOK,
+		}, nil
+	})
+
+	t.Run("Pending and overdue", func(t *testing.T) {
+		client, err := New(mem.NewProvider(), testutil.GetLoader(t), wfClient, vctHTTPClient,
+			mocks.NewTaskManager("vct-monitor"), time.Second, map[string]string{})
+		require.NoError(t, err)
+
+		_, err = client.GetWatched("")
+		require.ErrorIs(t, err, orberrors.ErrContentNotFound)
+
+		ID1 := "https://orb.domain.com/" + uuid.New().String()
+		ID2 := "https://orb.domain.com/" + uuid.New().String()
+
+		require.NoError(t, client.Watch(newCredential(ID1), time.Now().Add(time.Minute), "https://vct.com", time.Now()))
+		require.NoError(t, client.Watch(newCredential(ID2), time.Now().Add(50*time.Millisecond),
+			"https://vct.com", time.Now()))
+
+		time.Sleep(100 * time.Millisecond)
+
+		watched, err := client.GetWatched("")
+		require.NoError(t, err)
+		require.Len(t, watched, 2)
+
+		for _, wc := range watched {
+			require.Equal(t, "https://vct.com", wc.Domain)
+			require.Contains(t, wc.LastCheckError, "tree size is zero")
+			require.False(t, wc.LastChecked.IsZero())
+		}
+
+		watched, err = client.GetWatched(StatusPending)
+		require.NoError(t, err)
+		require.Len(t, watched, 1)
+		require.Equal(t, ID1, watched[0].CredentialID)
+		require.Equal(t, StatusPending, watched[0].Status)
+
+		watched, err = client.GetWatched(StatusOverdue)
+		require.NoError(t, err)
+		require.Len(t, watched, 1)
+		require.Equal(t, ID2, watched[0].CredentialID)
+		require.Equal(t, StatusOverdue, watched[0].Status)
+
+		_, err = client.GetWatched(StatusFailed)
+		require.ErrorIs(t, err, orberrors.ErrContentNotFound)
+	})
+
+	t.Run("Failed credentials are retained", func(t *testing.T) {
+		taskMgr := mocks.NewTaskManager("vct-monitor").WithInterval(100 * time.Millisecond)
+
+		taskMgr.Start()
+		defer taskMgr.Stop()
+
+		m := &mockMetrics{}
+
+		client, err := New(mem.NewProvider(), testutil.GetLoader(t), wfClient, vctHTTPClient,
+			taskMgr, 100*time.Millisecond, map[string]string{},
+			WithExpiryService(expiry.NewService(taskMgr, time.Hour), time.Hour),
+			WithMetrics(m),
+		)
+		require.NoError(t, err)
+
+		ID := "https://orb.domain.com/" + uuid.New().String()
+
+		require.NoError(t, client.Watch(newCredential(ID), time.Now().Add(50*time.Millisecond),
+			"https://vct.com", time.Now()))
+
+		require.NoError(t, backoff.Retry(func() error {
+			watched, err := client.GetWatched(StatusFailed)
+			if err != nil {
+				return err
+			}
+
+			if len(watched) != 1 {
+				return fmt.Errorf("expecting one failed credential but got %d", len(watched))
+			}
+
+			return nil
+		}, backoff.WithMaxRetries(backoff.NewConstantBackOff(100*time.Millisecond), 20)))
+
+		_, err = client.GetWatched(StatusPending)
+		require.ErrorIs(t, err, orberrors.ErrContentNotFound)
+
+		require.Equal(t, int32(1), atomic.LoadInt32(&m.failedCount))
+	})
+
+	t.Run("Unsupported status", func(t *testing.T) {
+		client, err := New(mem.NewProvider(), nil, nil, nil,
+			mocks.NewTaskManager("vct-monitor"), time.Second, map[string]string{})
+		require.NoError(t, err)
+
+		_, err = client.GetWatched("invalid")
+		require.EqualError(t, err, "unsupported status: invalid")
+	})
+
+	t.Run("Query error", func(t *testing.T) {
+		db := newDBMock(t)
+		db.mockStore.errQuery = func() error {
+			return errors.New("injected query error")
+		}
+
+		client, err := New(db, nil, nil, nil,
+			mocks.NewTaskManager("vct-monitor"), time.Second, map[string]string{})
+		require.NoError(t, err)
+
+		_, err = client.GetWatched(StatusPending)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected query error")
+	})
+}
+
+func newCredential(id string) *verifiable.Credential {
+	return &verifiable.Credential{
+		ID:      id,
+		Context: []string{"https://www.w3.org/2018/credentials/v1"},
+		Subject: id,
+		Issuer:  verifiable.Issuer{ID: id},
+		Issued:  &util.TimeWrapper{},
+		Types:   []string{"VerifiableCredential"},
+	}
+}
+
+type mockMetrics struct {
+	noop.NoOptMetrics
+
+	failedCount int32
+}
+
+func (m *mockMetrics) ProofMonitorFailedCount() {
+	atomic.AddInt32(&m.failedCount, 1)
+}
+
 func checkQueue(t *testing.T, db storage.Provider, expected int) {
 	t.Helper()
 
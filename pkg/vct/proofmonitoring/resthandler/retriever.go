@@ -0,0 +1,155 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/vct/proofmonitoring"
+)
+
+const (
+	endpoint = "/proof-monitor"
+
+	statusParam = "status"
+)
+
+const (
+	badRequestResponse          = "Bad Request."
+	notFoundResponse            = "Not Found"
+	internalServerErrorResponse = "Internal Server Error."
+)
+
+const loggerModule = "proof-monitor-rest-handler"
+
+type proofMonitor interface {
+	GetWatched(status string) ([]*proofmonitoring.WatchedCredential, error)
+}
+
+// RetrieveHandler retrieves the credentials that are being watched by the proof monitor.
+type RetrieveHandler struct {
+	proofMonitor proofMonitor
+	logger       *log.Log
+	marshal      func(interface{}) ([]byte, error)
+}
+
+// Path returns the HTTP REST endpoint for the proof monitor retriever.
+func (r *RetrieveHandler) Path() string {
+	return endpoint
+}
+
+// Method returns the HTTP REST method for the proof monitor retriever.
+func (r *RetrieveHandler) Method() string {
+	return http.MethodGet
+}
+
+// Handler returns the HTTP REST handle for the proof monitor retriever service.
+func (r *RetrieveHandler) Handler() common.HTTPRequestHandler {
+	return r.handle
+}
+
+// NewRetriever returns a new RetrieveHandler.
+func NewRetriever(proofMonitor proofMonitor) *RetrieveHandler {
+	return &RetrieveHandler{
+		proofMonitor: proofMonitor,
+		logger:       log.New(loggerModule, log.WithFields(log.WithServiceEndpoint(endpoint))),
+		marshal:      json.Marshal,
+	}
+}
+
+func (r *RetrieveHandler) handle(w http.ResponseWriter, req *http.Request) {
+	status := req.URL.Query().Get(statusParam)
+
+	switch status {
+	case "", proofmonitoring.StatusPending, proofmonitoring.StatusOverdue, proofmonitoring.StatusFailed:
+	default:
+		r.logger.Debug("Unsupported status", log.WithStatus(status))
+
+		writeResponse(r.logger, w, http.StatusBadRequest, []byte(badRequestResponse))
+
+		return
+	}
+
+	credentials, err := r.proofMonitor.GetWatched(status)
+	if err != nil {
+		if errors.Is(err, orberrors.ErrContentNotFound) {
+			r.logger.Debug("No watched credentials found for status.", log.WithStatus(status))
+
+			writeResponse(r.logger, w, http.StatusNotFound, []byte(notFoundResponse))
+
+			return
+		}
+
+		r.logger.Error("Error retrieving watched credentials", log.WithError(err))
+
+		writeResponse(r.logger, w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	retBytes, err := r.marshal(newWatchedResponse(credentials))
+	if err != nil {
+		r.logger.Error("Marshal watched credentials error", log.WithError(err))
+
+		writeResponse(r.logger, w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	r.logger.Debug("Retrieved watched credentials for status.", log.WithStatus(status),
+		log.WithTotal(len(credentials)))
+
+	writeResponse(r.logger, w, http.StatusOK, retBytes)
+}
+
+type watchedResponse struct {
+	Pending     int                                  `json:"pending"`
+	Overdue     int                                  `json:"overdue"`
+	Failed      int                                  `json:"failed"`
+	Credentials []*proofmonitoring.WatchedCredential `json:"credentials"`
+}
+
+func newWatchedResponse(credentials []*proofmonitoring.WatchedCredential) *watchedResponse {
+	resp := &watchedResponse{Credentials: credentials}
+
+	for _, c := range credentials {
+		switch c.Status {
+		case proofmonitoring.StatusPending:
+			resp.Pending++
+		case proofmonitoring.StatusOverdue:
+			resp.Overdue++
+		case proofmonitoring.StatusFailed:
+			resp.Failed++
+		}
+	}
+
+	return resp
+}
+
+func writeResponse(logger *log.Log, w http.ResponseWriter, status int, body []byte) {
+	if len(body) > 0 {
+		w.Header().Set("Content-Type", "text/plain")
+	}
+
+	w.WriteHeader(status)
+
+	if len(body) > 0 {
+		if _, err := w.Write(body); err != nil {
+			log.WriteResponseBodyError(logger, err)
+
+			return
+		}
+
+		log.WroteResponse(logger, body)
+	}
+}
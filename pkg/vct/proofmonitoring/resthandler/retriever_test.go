@@ -0,0 +1,181 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/vct/proofmonitoring"
+)
+
+func TestNewRetriever(t *testing.T) {
+	handler := NewRetriever(&mockProofMonitor{})
+	require.NotNil(t, handler)
+	require.Equal(t, endpoint, handler.Path())
+	require.Equal(t, http.MethodGet, handler.Method())
+	require.NotNil(t, handler.Handler())
+}
+
+func TestRetriever(t *testing.T) {
+	now := time.Now()
+
+	credentials := []*proofmonitoring.WatchedCredential{
+		{
+			CredentialID:   "https://orb.domain1.com/vc1",
+			Domain:         "https://vct.com/log",
+			Created:        now,
+			ExpirationTime: now.Add(time.Hour),
+			Status:         proofmonitoring.StatusPending,
+			LastCheckError: "not found",
+		},
+		{
+			CredentialID:   "https://orb.domain1.com/vc2",
+			Domain:         "https://vct.com/log",
+			Created:        now.Add(-2 * time.Hour),
+			ExpirationTime: now.Add(-time.Hour),
+			Status:         proofmonitoring.StatusOverdue,
+		},
+		{
+			CredentialID:   "https://orb.domain1.com/vc3",
+			Domain:         "https://vct.com/log",
+			Created:        now.Add(-2 * time.Hour),
+			ExpirationTime: now.Add(-time.Hour),
+			Status:         proofmonitoring.StatusFailed,
+		},
+	}
+
+	t.Run("success - all statuses", func(t *testing.T) {
+		pm := &mockProofMonitor{credentials: credentials}
+
+		handler := NewRetriever(pm)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, endpoint, nil)
+
+		handler.handle(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+
+		respBytes, err := io.ReadAll(result.Body)
+		require.NoError(t, err)
+		require.NoError(t, result.Body.Close())
+
+		var resp watchedResponse
+
+		require.NoError(t, json.Unmarshal(respBytes, &resp))
+		require.Equal(t, 1, resp.Pending)
+		require.Equal(t, 1, resp.Overdue)
+		require.Equal(t, 1, resp.Failed)
+		require.Len(t, resp.Credentials, 3)
+		require.Equal(t, "not found", resp.Credentials[0].LastCheckError)
+		require.Empty(t, pm.status)
+	})
+
+	t.Run("success - failed", func(t *testing.T) {
+		pm := &mockProofMonitor{credentials: credentials[2:]}
+
+		handler := NewRetriever(pm)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, endpoint+"?status=failed", nil)
+
+		handler.handle(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+
+		respBytes, err := io.ReadAll(result.Body)
+		require.NoError(t, err)
+		require.NoError(t, result.Body.Close())
+
+		var resp watchedResponse
+
+		require.NoError(t, json.Unmarshal(respBytes, &resp))
+		require.Equal(t, 1, resp.Failed)
+		require.Len(t, resp.Credentials, 1)
+		require.Equal(t, proofmonitoring.StatusFailed, pm.status)
+	})
+
+	t.Run("error - invalid status parameter", func(t *testing.T) {
+		handler := NewRetriever(&mockProofMonitor{})
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, endpoint+"?status=invalid", nil)
+
+		handler.handle(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusBadRequest, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("error - not found", func(t *testing.T) {
+		handler := NewRetriever(&mockProofMonitor{err: orberrors.ErrContentNotFound})
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, endpoint, nil)
+
+		handler.handle(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusNotFound, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("error - internal server error", func(t *testing.T) {
+		handler := NewRetriever(&mockProofMonitor{err: errors.New("injected error")})
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, endpoint, nil)
+
+		handler.handle(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("error - marshal error", func(t *testing.T) {
+		handler := NewRetriever(&mockProofMonitor{credentials: credentials})
+		handler.marshal = func(interface{}) ([]byte, error) { return nil, errors.New("injected marshal error") }
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, endpoint, nil)
+
+		handler.handle(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+}
+
+type mockProofMonitor struct {
+	credentials []*proofmonitoring.WatchedCredential
+	err         error
+	status      string
+}
+
+func (m *mockProofMonitor) GetWatched(status string) ([]*proofmonitoring.WatchedCredential, error) {
+	m.status = status
+
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return m.credentials, nil
+}
@@ -0,0 +1,196 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webcas
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+
+	casapi "github.com/trustbloc/sidetree-core-go/pkg/api/cas"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/activitypub/resthandler"
+	"github.com/trustbloc/orb/pkg/activitypub/store/spi"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+)
+
+const (
+	batchPath = "/cas"
+
+	// MaxBatchSize is the maximum number of CIDs that may be requested in a single batch request.
+	MaxBatchSize = 100
+
+	// CIDHeader is the header in each part of a batch response that contains the requested CID.
+	CIDHeader = "Content-ID"
+
+	// MultipartMixedContentType is the content type of a batch response.
+	MultipartMixedContentType = "multipart/mixed"
+
+	contentTypeHeader      = "Content-Type"
+	octetStreamContentType = "application/octet-stream"
+)
+
+// Batch implements a WebCAS batch endpoint. A client POSTs a JSON array of CIDs and receives a multipart/mixed
+// response with one part per CID that was found. Each part contains the requested CID in the Content-ID header.
+// CIDs that are not found are omitted from the response.
+type Batch struct {
+	*resthandler.AuthHandler
+
+	casClient casapi.Client
+	logger    *log.Log
+	unmarshal func([]byte, interface{}) error
+}
+
+// NewBatch returns a new WebCAS batch handler.
+func NewBatch(authCfg *resthandler.Config, s spi.Store, verifier signatureVerifier,
+	casClient casapi.Client, tm authTokenManager) *Batch {
+	h := &Batch{
+		casClient: casClient,
+		logger:    log.New(loggerModule, log.WithFields(log.WithServiceEndpoint(batchPath))),
+		unmarshal: json.Unmarshal,
+	}
+
+	h.AuthHandler = resthandler.NewAuthHandler(authCfg, batchPath, http.MethodPost, s, verifier, tm,
+		func(actorIRI *url.URL) (bool, error) {
+			// Same as the WebCAS GET endpoint: let all actors through.
+			h.logger.Debug("Authorized actor", log.WithActorIRI(actorIRI))
+
+			return true, nil
+		})
+
+	return h
+}
+
+// Path returns the HTTP REST endpoint for the WebCAS batch service.
+func (b *Batch) Path() string {
+	return batchPath
+}
+
+// Method returns the HTTP REST method for the WebCAS batch service.
+func (b *Batch) Method() string {
+	return http.MethodPost
+}
+
+// Handler returns the HTTP REST handler for the WebCAS batch service.
+func (b *Batch) Handler() common.HTTPRequestHandler {
+	return b.handler
+}
+
+type batchContent struct {
+	cid     string
+	content []byte
+}
+
+func (b *Batch) handler(rw http.ResponseWriter, req *http.Request) {
+	if !authorize(rw, req, b.AuthHandler, b.logger) {
+		return
+	}
+
+	cids, err := b.getCIDs(req)
+	if err != nil {
+		b.logger.Info("Invalid batch request", log.WithError(err))
+
+		b.writeError(rw, http.StatusBadRequest, fmt.Sprintf("invalid request: %s", err))
+
+		return
+	}
+
+	// All of the content is read before anything is written so that an error may still be returned to the client.
+	var contents []*batchContent
+
+	for _, cid := range cids {
+		content, e := b.casClient.Read(cid)
+		if e != nil {
+			if errors.Is(e, orberrors.ErrContentNotFound) {
+				b.logger.Debug("Content not found for batch request", log.WithCID(cid))
+
+				continue
+			}
+
+			b.logger.Error("Error reading content for batch request", log.WithCID(cid), log.WithError(e))
+
+			b.writeError(rw, http.StatusInternalServerError, fmt.Sprintf("failure while finding content at %s: %s", cid, e))
+
+			return
+		}
+
+		contents = append(contents, &batchContent{cid: cid, content: content})
+	}
+
+	b.logger.Debug("Returning batch response", log.WithTotal(len(contents)))
+
+	mw := multipart.NewWriter(rw)
+
+	rw.Header().Set(contentTypeHeader, fmt.Sprintf("%s; boundary=%s", MultipartMixedContentType, mw.Boundary()))
+	rw.WriteHeader(http.StatusOK)
+
+	for _, c := range contents {
+		if err := writePart(mw, c); err != nil {
+			log.WriteResponseBodyError(b.logger, err)
+
+			return
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		log.WriteResponseBodyError(b.logger, err)
+	}
+}
+
+func (b *Batch) getCIDs(req *http.Request) ([]string, error) {
+	reqBytes, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read request body: %w", err)
+	}
+
+	var cids []string
+
+	if err := b.unmarshal(reqBytes, &cids); err != nil {
+		return nil, fmt.Errorf("unmarshal CIDs: %w", err)
+	}
+
+	if len(cids) == 0 {
+		return nil, errors.New("no CIDs were provided")
+	}
+
+	if len(cids) > MaxBatchSize {
+		return nil, fmt.Errorf("the number of CIDs [%d] exceeds the maximum batch size [%d]", len(cids), MaxBatchSize)
+	}
+
+	return cids, nil
+}
+
+func (b *Batch) writeError(rw http.ResponseWriter, status int, msg string) {
+	rw.WriteHeader(status)
+
+	if _, err := rw.Write([]byte(msg)); err != nil {
+		log.WriteResponseBodyError(b.logger, err)
+	}
+}
+
+func writePart(mw *multipart.Writer, c *batchContent) error {
+	pw, err := mw.CreatePart(textproto.MIMEHeader{
+		contentTypeHeader: []string{octetStreamContentType},
+		CIDHeader:         []string{c.cid},
+	})
+	if err != nil {
+		return fmt.Errorf("create part for CID [%s]: %w", c.cid, err)
+	}
+
+	if _, err := pw.Write(c.content); err != nil {
+		return fmt.Errorf("write part for CID [%s]: %w", c.cid, err)
+	}
+
+	return nil
+}
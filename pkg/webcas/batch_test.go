@@ -0,0 +1,179 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webcas_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/stretchr/testify/require"
+
+	apmocks "github.com/trustbloc/orb/pkg/activitypub/mocks"
+	"github.com/trustbloc/orb/pkg/activitypub/resthandler"
+	"github.com/trustbloc/orb/pkg/activitypub/service/mocks"
+	"github.com/trustbloc/orb/pkg/activitypub/store/memstore"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	orbmocks "github.com/trustbloc/orb/pkg/mocks"
+	"github.com/trustbloc/orb/pkg/store/cas"
+	"github.com/trustbloc/orb/pkg/webcas"
+)
+
+func TestNewBatch(t *testing.T) {
+	casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+	require.NoError(t, err)
+
+	batch := webcas.NewBatch(&resthandler.Config{}, memstore.New(""), &mocks.SignatureVerifier{}, casClient,
+		&apmocks.AuthTokenMgr{})
+	require.NotNil(t, batch)
+	require.Equal(t, "/cas", batch.Path())
+	require.Equal(t, http.MethodPost, batch.Method())
+	require.NotNil(t, batch.Handler())
+}
+
+func TestBatch_Handler(t *testing.T) {
+	casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+	require.NoError(t, err)
+
+	hl1, err := casClient.Write([]byte(sampleAnchorCredential))
+	require.NoError(t, err)
+
+	hl2, err := casClient.Write([]byte("some other content"))
+	require.NoError(t, err)
+
+	rh1, err := hashlink.GetResourceHashFromHashLink(hl1)
+	require.NoError(t, err)
+
+	rh2, err := hashlink.GetResourceHashFromHashLink(hl2)
+	require.NoError(t, err)
+
+	const notFoundCID = "QmeKWPxUJP9M3WJgBuj8ykLtGU37iqur5gZ8cDCi49WJVG"
+
+	newServer := func(tm *apmocks.AuthTokenMgr) *httptest.Server {
+		batch := webcas.NewBatch(&resthandler.Config{}, memstore.New(""), &mocks.SignatureVerifier{}, casClient, tm)
+
+		router := mux.NewRouter()
+
+		router.HandleFunc(batch.Path(), batch.Handler()).Methods(batch.Method())
+
+		return httptest.NewServer(router)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		testServer := newServer(&apmocks.AuthTokenMgr{})
+		defer testServer.Close()
+
+		reqBytes, err := json.Marshal([]string{rh1, notFoundCID, rh2})
+		require.NoError(t, err)
+
+		response, err := http.DefaultClient.Post(testServer.URL+"/cas", "application/json", bytes.NewReader(reqBytes))
+		require.NoError(t, err)
+
+		defer func() {
+			require.NoError(t, response.Body.Close())
+		}()
+
+		require.Equal(t, http.StatusOK, response.StatusCode)
+
+		mediaType, params, err := mime.ParseMediaType(response.Header.Get("Content-Type"))
+		require.NoError(t, err)
+		require.Equal(t, webcas.MultipartMixedContentType, mediaType)
+
+		contents := make(map[string]string)
+
+		mr := multipart.NewReader(response.Body, params["boundary"])
+
+		for {
+			part, err := mr.NextPart()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			require.NoError(t, err)
+
+			content, err := io.ReadAll(part)
+			require.NoError(t, err)
+
+			contents[part.Header.Get(webcas.CIDHeader)] = string(content)
+		}
+
+		require.Len(t, contents, 2)
+		require.Equal(t, sampleAnchorCredential, contents[rh1])
+		require.Equal(t, "some other content", contents[rh2])
+	})
+
+	t.Run("Invalid request", func(t *testing.T) {
+		testServer := newServer(&apmocks.AuthTokenMgr{})
+		defer testServer.Close()
+
+		t.Run("Invalid JSON", func(t *testing.T) {
+			response, err := http.DefaultClient.Post(testServer.URL+"/cas", "application/json",
+				bytes.NewReader([]byte("{")))
+			require.NoError(t, err)
+			require.NoError(t, response.Body.Close())
+
+			require.Equal(t, http.StatusBadRequest, response.StatusCode)
+		})
+
+		t.Run("No CIDs", func(t *testing.T) {
+			response, err := http.DefaultClient.Post(testServer.URL+"/cas", "application/json",
+				bytes.NewReader([]byte("[]")))
+			require.NoError(t, err)
+
+			respBody, err := io.ReadAll(response.Body)
+			require.NoError(t, err)
+			require.NoError(t, response.Body.Close())
+
+			require.Equal(t, http.StatusBadRequest, response.StatusCode)
+			require.Contains(t, string(respBody), "no CIDs were provided")
+		})
+
+		t.Run("Too many CIDs", func(t *testing.T) {
+			cids := make([]string, webcas.MaxBatchSize+1)
+
+			for i := range cids {
+				cids[i] = rh1
+			}
+
+			reqBytes, err := json.Marshal(cids)
+			require.NoError(t, err)
+
+			response, err := http.DefaultClient.Post(testServer.URL+"/cas", "application/json", bytes.NewReader(reqBytes))
+			require.NoError(t, err)
+
+			respBody, err := io.ReadAll(response.Body)
+			require.NoError(t, err)
+			require.NoError(t, response.Body.Close())
+
+			require.Equal(t, http.StatusBadRequest, response.StatusCode)
+			require.Contains(t, string(respBody), "exceeds the maximum batch size")
+		})
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		tm := &apmocks.AuthTokenMgr{}
+		tm.RequiredAuthTokensReturns([]string{"read"}, nil)
+
+		testServer := newServer(tm)
+		defer testServer.Close()
+
+		response, err := http.DefaultClient.Post(testServer.URL+"/cas", "application/json",
+			bytes.NewReader([]byte(`["`+rh1+`"]`)))
+		require.NoError(t, err)
+		require.NoError(t, response.Body.Close())
+
+		require.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	})
+}
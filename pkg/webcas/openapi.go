@@ -26,3 +26,25 @@ type casGetResp struct { //nolint: unused
 // 200: casGetResp
 func casGetRequest() { //nolint: unused
 }
+
+// swagger:parameters casBatchReq
+type casBatchReq struct { //nolint: unused
+	// in: body
+	Body []string
+}
+
+// swagger:response casBatchResp
+type casBatchResp struct { //nolint: unused
+	Body string
+}
+
+// handleBatch swagger:route POST /cas CAS casBatchReq
+//
+// Returns the content for a list of CIDs as a multipart/mixed response. Each part contains the requested CID in its
+// Content-ID header. CIDs that aren't found are omitted from the response.
+//
+// Responses:
+//
+// 200: casBatchResp
+func casBatchRequest() { //nolint: unused
+}
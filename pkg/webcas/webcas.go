@@ -7,10 +7,12 @@ SPDX-License-Identifier: Apache-2.0
 package webcas
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/gorilla/mux"
 	casapi "github.com/trustbloc/sidetree-core-go/pkg/api/cas"
@@ -26,6 +28,8 @@ const (
 	loggerModule = "webcas"
 
 	cidPathVariable = "cid"
+
+	immutableCacheControl = "public, max-age=31536000, immutable"
 )
 
 type signatureVerifier interface {
@@ -85,33 +89,10 @@ func New(authCfg *resthandler.Config, s spi.Store, verifier signatureVerifier,
 }
 
 func (w *WebCAS) handler(rw http.ResponseWriter, req *http.Request) {
-	ok, _, err := w.Authorize(req)
-	if err != nil {
-		w.logger.Error("Error authorizing request", log.WithRequestURL(req.URL), log.WithError(err))
-
-		rw.WriteHeader(http.StatusInternalServerError)
-
-		if _, errWrite := rw.Write([]byte("Internal Server Error.\n")); errWrite != nil {
-			log.WriteResponseBodyError(w.logger, errWrite)
-		}
-
-		return
-	}
-
-	if !ok {
-		w.logger.Info("Request is unauthorized", log.WithRequestURL(req.URL))
-
-		rw.WriteHeader(http.StatusUnauthorized)
-
-		if _, errWrite := rw.Write([]byte("Unauthorized.\n")); errWrite != nil {
-			log.WriteResponseBodyError(w.logger, errWrite)
-		}
-
+	if !authorize(rw, req, w.AuthHandler, w.logger) {
 		return
 	}
 
-	w.logger.Debug("Request is authorized", log.WithRequestURL(req.URL))
-
 	cid := mux.Vars(req)[cidPathVariable]
 
 	content, err := w.casClient.Read(cid)
@@ -137,8 +118,45 @@ func (w *WebCAS) handler(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	_, err = rw.Write(content)
+	// Content is addressed by its hash and therefore never changes, so the CID is a strong validator and the
+	// content may be cached indefinitely. ServeContent handles conditional (If-None-Match) and range requests.
+	rw.Header().Set("ETag", etag(cid))
+	rw.Header().Set("Cache-Control", immutableCacheControl)
+
+	http.ServeContent(rw, req, "", time.Time{}, bytes.NewReader(content))
+}
+
+func authorize(rw http.ResponseWriter, req *http.Request, h *resthandler.AuthHandler, logger *log.Log) bool {
+	ok, _, err := h.Authorize(req)
 	if err != nil {
-		log.WriteResponseBodyError(w.logger, err)
+		logger.Error("Error authorizing request", log.WithRequestURL(req.URL), log.WithError(err))
+
+		rw.WriteHeader(http.StatusInternalServerError)
+
+		if _, errWrite := rw.Write([]byte("Internal Server Error.\n")); errWrite != nil {
+			log.WriteResponseBodyError(logger, errWrite)
+		}
+
+		return false
 	}
+
+	if !ok {
+		logger.Info("Request is unauthorized", log.WithRequestURL(req.URL))
+
+		rw.WriteHeader(http.StatusUnauthorized)
+
+		if _, errWrite := rw.Write([]byte("Unauthorized.\n")); errWrite != nil {
+			log.WriteResponseBodyError(logger, errWrite)
+		}
+
+		return false
+	}
+
+	logger.Debug("Request is authorized", log.WithRequestURL(req.URL))
+
+	return true
+}
+
+func etag(cid string) string {
+	return `"` + cid + `"`
 }
@@ -10,6 +10,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
@@ -30,7 +31,7 @@ const casLink = "https://domain.com/cas"
 type failingResponseWriter struct{}
 
 func (f *failingResponseWriter) Header() http.Header {
-	return nil
+	return make(http.Header)
 }
 
 func (f *failingResponseWriter) Write([]byte) (int, error) {
@@ -84,3 +85,23 @@ func TestWriteResponseFailures(t *testing.T) {
 		webCAS.Handler()(rw, req)
 	})
 }
+
+func TestBatchReadError(t *testing.T) {
+	casClient, err := cas.New(&mock.Provider{OpenStoreReturn: &mock.Store{
+		ErrGet: errors.New("injected get error"),
+	}}, casLink, nil, &orbmocks.MetricsProvider{}, 0)
+	require.NoError(t, err)
+
+	batch := NewBatch(&resthandler.Config{}, memstore.New(""), &mocks.SignatureVerifier{}, casClient,
+		&apmocks.AuthTokenMgr{})
+
+	rw := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/cas",
+		strings.NewReader(`["QmeKWPxUJP9M3WJgBuj8ykLtGU37iqur5gZ8cDCi49WJVG"]`))
+
+	batch.Handler()(rw, req)
+
+	result := rw.Result()
+	require.NoError(t, result.Body.Close())
+	require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+}
@@ -118,6 +118,68 @@ func TestHandler(t *testing.T) {
 		require.Equal(t, http.StatusOK, response.StatusCode)
 		require.Equal(t, sampleAnchorCredential, string(responseBody))
 	})
+	t.Run("Caching headers and conditional requests", func(t *testing.T) {
+		casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		require.NoError(t, err)
+
+		hl, err := casClient.Write([]byte(sampleAnchorCredential))
+		require.NoError(t, err)
+
+		webCAS := webcas.New(&resthandler.Config{}, memstore.New(""), &mocks.SignatureVerifier{}, casClient,
+			&apmocks.AuthTokenMgr{})
+
+		router := mux.NewRouter()
+
+		router.HandleFunc(webCAS.Path(), webCAS.Handler())
+
+		testServer := httptest.NewServer(router)
+		defer testServer.Close()
+
+		rh, err := hashlink.GetResourceHashFromHashLink(hl)
+		require.NoError(t, err)
+
+		response, err := http.DefaultClient.Get(testServer.URL + "/cas/" + rh)
+		require.NoError(t, err)
+		require.NoError(t, response.Body.Close())
+
+		require.Equal(t, http.StatusOK, response.StatusCode)
+		require.Equal(t, `"`+rh+`"`, response.Header.Get("ETag"))
+		require.Contains(t, response.Header.Get("Cache-Control"), "immutable")
+
+		t.Run("If-None-Match", func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, testServer.URL+"/cas/"+rh, nil)
+			require.NoError(t, err)
+
+			req.Header.Set("If-None-Match", response.Header.Get("ETag"))
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+
+			respBody, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			require.Equal(t, http.StatusNotModified, resp.StatusCode)
+			require.Empty(t, respBody)
+		})
+
+		t.Run("Range", func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, testServer.URL+"/cas/"+rh, nil)
+			require.NoError(t, err)
+
+			req.Header.Set("Range", "bytes=0-9")
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+
+			respBody, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			require.Equal(t, http.StatusPartialContent, resp.StatusCode)
+			require.Equal(t, sampleAnchorCredential[:10], string(respBody))
+		})
+	})
 	t.Run("Content not found", func(t *testing.T) {
 		casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0)
 		require.NoError(t, err)
@@ -14,12 +14,14 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
-	"github.com/bluele/gcache"
 	"github.com/trustbloc/vct/pkg/controller/command"
 
 	"github.com/trustbloc/orb/internal/pkg/log"
+	"github.com/trustbloc/orb/pkg/cache"
+	"github.com/trustbloc/orb/pkg/cache/memory"
 	"github.com/trustbloc/orb/pkg/discovery/endpoint/restapi"
 	"github.com/trustbloc/orb/pkg/document/util"
 	orberrors "github.com/trustbloc/orb/pkg/errors"
@@ -31,6 +33,8 @@ var logger = log.New("webfinger-client")
 const (
 	defaultCacheLifetime = 300 * time.Second // five minutes
 	defaultCacheSize     = 100
+
+	resourceCacheName = "webfinger-resource"
 )
 
 // httpClient represents HTTP client.
@@ -47,8 +51,9 @@ type Client struct {
 	cacheLifetime    time.Duration
 	cacheSize        int
 	getDomainFromDID didDomainResolver
+	cacheProvider    cache.Provider
 
-	resourceCache gcache.Cache
+	resourceCache cache.Cache
 }
 
 type cacheKey struct {
@@ -56,6 +61,22 @@ type cacheKey struct {
 	resource         string
 }
 
+// String returns the cache key as a string. The domain and resource are separated by a
+// newline since a newline can't be part of a URL.
+func (k cacheKey) String() string {
+	return k.domainWithScheme + "\n" + k.resource
+}
+
+func parseCacheKey(key string) cacheKey {
+	parts := strings.SplitN(key, "\n", 2) //nolint:gomnd
+
+	if len(parts) < 2 { //nolint:gomnd
+		return cacheKey{domainWithScheme: key}
+	}
+
+	return cacheKey{domainWithScheme: parts[0], resource: parts[1]}
+}
+
 // New creates new webfinger client.
 func New(opts ...Option) *Client {
 	client := &Client{
@@ -68,10 +89,13 @@ func New(opts ...Option) *Client {
 		opt(client)
 	}
 
-	client.resourceCache = gcache.New(client.cacheSize).
-		Expiration(client.cacheLifetime).
-		LoaderFunc(func(key interface{}) (interface{}, error) {
-			k := key.(cacheKey) //nolint:forcetypeassert
+	if client.cacheProvider == nil {
+		client.cacheProvider = memory.New()
+	}
+
+	client.resourceCache = client.cacheProvider.NewCache(resourceCacheName,
+		func(key string) (interface{}, error) {
+			k := parseCacheKey(key)
 
 			r, err := client.resolveResource(k.domainWithScheme, k.resource)
 			if err != nil {
@@ -82,7 +106,10 @@ func New(opts ...Option) *Client {
 				log.WithResource(k.resource), log.WithJRD(r))
 
 			return r, nil
-		}).Build()
+		},
+		cache.WithSize(client.cacheSize), cache.WithExpiration(client.cacheLifetime),
+		cache.WithCodec(cache.NewJSONCodec(func() interface{} { return &restapi.JRD{} })),
+	)
 
 	return client
 }
@@ -134,7 +161,7 @@ func (c *Client) ResolveWebFingerResource(domainWithScheme, resource string) (re
 	r, err := c.resourceCache.Get(cacheKey{
 		domainWithScheme: domainWithScheme,
 		resource:         resource,
-	})
+	}.String())
 	if err != nil {
 		return restapi.JRD{}, fmt.Errorf("get webfinger resource for domain [%s] and resource [%s]: %w",
 			domainWithScheme, resource, err)
@@ -317,6 +344,13 @@ func WithCacheSize(size int) Option {
 	}
 }
 
+// WithCacheProvider option sets the provider of the resource cache. If not set then an in-memory cache is used.
+func WithCacheProvider(p cache.Provider) Option {
+	return func(opts *Client) {
+		opts.cacheProvider = p
+	}
+}
+
 // WithDIDDomainResolver option sets the domain resolver.
 func WithDIDDomainResolver(resolver didDomainResolver) Option {
 	return func(opts *Client) {
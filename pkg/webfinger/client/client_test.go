@@ -21,6 +21,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/trustbloc/vct/pkg/controller/command"
 
+	"github.com/trustbloc/orb/pkg/cache/memory"
 	"github.com/trustbloc/orb/pkg/cas/resolver/mocks"
 	discoveryrest "github.com/trustbloc/orb/pkg/discovery/endpoint/restapi"
 	orberrors "github.com/trustbloc/orb/pkg/errors"
@@ -39,14 +40,23 @@ func TestNew(t *testing.T) {
 	t.Run("success - options", func(t *testing.T) {
 		c := New(WithHTTPClient(http.DefaultClient),
 			WithCacheLifetime(5*time.Second),
-			WithCacheSize(1000))
+			WithCacheSize(1000),
+			WithCacheProvider(memory.New()))
 
 		require.Equal(t, http.DefaultClient, c.httpClient)
 		require.Equal(t, 5*time.Second, c.cacheLifetime)
 		require.Equal(t, 1000, c.cacheSize)
+		require.NotNil(t, c.cacheProvider)
 	})
 }
 
+func TestCacheKey(t *testing.T) {
+	k := cacheKey{domainWithScheme: "https://orb.domain1.com", resource: "https://orb.domain1.com/vct"}
+	require.Equal(t, k, parseCacheKey(k.String()))
+
+	require.Equal(t, cacheKey{domainWithScheme: "https://orb.domain1.com"}, parseCacheKey("https://orb.domain1.com"))
+}
+
 func TestGetLedgerType(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		httpClient := httpMock(func(req *http.Request) (*http.Response, error) {